@@ -0,0 +1,69 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/email"
+)
+
+type EmailOutboxRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    // Only list messages with this status (all messages when empty).
+    Status string `json:"status"`
+}
+
+type EmailOutboxResponse struct {
+    // In the order the messages were queued.
+    // Message bodies are not included.
+    Messages []*email.OutboxMessage `json:"messages"`
+}
+
+type EmailRetryRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+}
+
+type EmailRetryResponse struct {
+    // The failed messages that were queued to be sent again.
+    // Message bodies are not included.
+    Messages []*email.OutboxMessage `json:"messages"`
+}
+
+// List the course's messages in the email outbox.
+func HandleEmailOutbox(request *EmailOutboxRequest) (*EmailOutboxResponse, *core.APIError) {
+    switch (request.Status) {
+        case "", email.OUTBOX_STATUS_PENDING, email.OUTBOX_STATUS_SENT, email.OUTBOX_STATUS_FAILED:
+        default:
+            return nil, core.NewBadCourseRequestError("-232", &request.APIRequestCourseUserContext,
+                    "Unknown email outbox status.").Add("status", request.Status);
+    }
+
+    messages, err := email.GetOutbox(request.Course.GetID(), request.Status);
+    if (err != nil) {
+        return nil, core.NewInternalError("-233", &request.APIRequestCourseUserContext,
+                "Failed to get email outbox.").Err(err);
+    }
+
+    return &EmailOutboxResponse{withoutBodies(messages)}, nil;
+}
+
+// Queue the course's failed emails to be sent again.
+func HandleEmailRetry(request *EmailRetryRequest) (*EmailRetryResponse, *core.APIError) {
+    messages, err := email.RetryFailed(request.Course.GetID());
+    if (err != nil) {
+        return nil, core.NewInternalError("-234", &request.APIRequestCourseUserContext,
+                "Failed to retry failed emails.").Err(err);
+    }
+
+    return &EmailRetryResponse{withoutBodies(messages)}, nil;
+}
+
+func withoutBodies(messages []*email.OutboxMessage) []*email.OutboxMessage {
+    results := make([]*email.OutboxMessage, 0, len(messages));
+    for _, message := range messages {
+        results = append(results, message.WithoutBody());
+    }
+
+    return results;
+}
@@ -0,0 +1,135 @@
+package admin
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestEmailOutbox(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    seedOutbox(test);
+
+    testCases := []struct{ role model.UserRole; fields map[string]any; expected []string; locator string }{
+        {model.RoleAdmin, nil, []string{"a", "b", "c"}, ""},
+        {model.RoleOwner, map[string]any{"status": email.OUTBOX_STATUS_FAILED}, []string{"b", "c"}, ""},
+        {model.RoleAdmin, map[string]any{"status": email.OUTBOX_STATUS_PENDING}, []string{}, ""},
+
+        {model.RoleAdmin, map[string]any{"status": "ZZZ"}, nil, "-232"},
+        {model.RoleGrader, nil, nil, "-020"},
+    };
+
+    for i, testCase := range testCases {
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/email/outbox`), testCase.fields, nil, testCase.role);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent EmailOutboxResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        ids := getOutboxIDs(responseContent.Messages);
+        if (util.MustToJSON(testCase.expected) != util.MustToJSON(ids)) {
+            test.Errorf("Case %d: Unexpected messages. Expected: '%v', Actual: '%v'.", i, testCase.expected, ids);
+            continue;
+        }
+
+        // Bodies are never listed.
+        for _, message := range responseContent.Messages {
+            if (message.Message.Body != "") {
+                test.Errorf("Case %d: Message '%s' has a body.", i, message.ID);
+            }
+        }
+    }
+
+    // The bodies are still in the outbox.
+    messages, err := email.GetOutbox("", email.OUTBOX_STATUS_FAILED);
+    if (err != nil) {
+        test.Fatalf("Failed to get outbox: '%v'.", err);
+    }
+
+    if ((len(messages) == 0) || (messages[0].Message.Body == "")) {
+        test.Fatalf("Unexpected outbox: '%s'.", util.MustToJSONIndent(messages));
+    }
+}
+
+func TestEmailRetry(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    seedOutbox(test);
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/email/retry`), nil, nil, model.RoleGrader);
+    if (response.Locator != "-020") {
+        test.Fatalf("Incorrect error returned. Expected '-020', found '%s'.", response.Locator);
+    }
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/email/retry`), nil, nil, model.RoleAdmin);
+    if (!response.Success) {
+        test.Fatalf("Response is not a success when it should be: '%v'.", response);
+    }
+
+    var responseContent EmailRetryResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+    // Only the failed messages for this course are retried.
+    expected := []string{"b", "c"};
+    ids := getOutboxIDs(responseContent.Messages);
+    if (util.MustToJSON(expected) != util.MustToJSON(ids)) {
+        test.Fatalf("Unexpected retried messages. Expected: '%v', Actual: '%v'.", expected, ids);
+    }
+
+    messages, err := email.GetOutbox("", email.OUTBOX_STATUS_PENDING);
+    if (err != nil) {
+        test.Fatalf("Failed to get outbox: '%v'.", err);
+    }
+
+    ids = getOutboxIDs(messages);
+    if (util.MustToJSON(expected) != util.MustToJSON(ids)) {
+        test.Fatalf("Unexpected pending messages. Expected: '%v', Actual: '%v'.", expected, ids);
+    }
+
+    for _, message := range messages {
+        if (message.Attempts != 0) {
+            test.Fatalf("Retried message did not have its attempts reset: '%s'.", util.MustToJSONIndent(message));
+        }
+    }
+}
+
+func seedOutbox(test *testing.T) {
+    courseID := db.MustGetTestCourse().GetID();
+
+    err := db.SaveOutboxMessages([]*email.OutboxMessage{
+        &email.OutboxMessage{ID: "a", Message: &email.Message{Subject: "a", Body: "a", CourseID: courseID}, Status: email.OUTBOX_STATUS_SENT, Attempts: 1},
+        &email.OutboxMessage{ID: "b", Message: &email.Message{Subject: "b", Body: "b", CourseID: courseID}, Status: email.OUTBOX_STATUS_FAILED, Attempts: 6},
+        &email.OutboxMessage{ID: "c", Message: &email.Message{Subject: "c", Body: "c", CourseID: courseID}, Status: email.OUTBOX_STATUS_FAILED, Attempts: 6},
+        &email.OutboxMessage{ID: "d", Message: &email.Message{Subject: "d", Body: "d", CourseID: "other"}, Status: email.OUTBOX_STATUS_FAILED, Attempts: 6},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save outbox messages: '%v'.", err);
+    }
+}
+
+func getOutboxIDs(messages []*email.OutboxMessage) []string {
+    ids := make([]string, 0, len(messages));
+    for _, message := range messages {
+        ids = append(ids, message.ID);
+    }
+
+    return ids;
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type ExtensionListRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    // Only list extensions for this user (all users when empty).
+    TargetUser string `json:"target-email"`
+}
+
+type ExtensionListResponse struct {
+    Extensions []*model.Extension `json:"extensions"`
+}
+
+func HandleExtensionList(request *ExtensionListRequest) (*ExtensionListResponse, *core.APIError) {
+    extensions, err := db.GetExtensions(request.Course);
+    if (err != nil) {
+        return nil, core.NewInternalError("-213", &request.APIRequestCourseUserContext,
+                "Failed to get extensions.").Err(err);
+    }
+
+    response := ExtensionListResponse{
+        Extensions: make([]*model.Extension, 0, len(extensions)),
+    };
+
+    for _, extension := range extensions {
+        if ((request.TargetUser != "") && (request.TargetUser != extension.User)) {
+            continue;
+        }
+
+        response.Extensions = append(response.Extensions, extension);
+    }
+
+    return &response, nil;
+}
@@ -0,0 +1,40 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+)
+
+type ExtensionRemoveRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    TargetUser core.TargetUser `json:"target-email"`
+    // An empty assignment ID removes the extension for all assignments.
+    AssignmentID string `json:"assignment-id"`
+}
+
+type ExtensionRemoveResponse struct {
+    FoundUser bool `json:"found-user"`
+    FoundExtension bool `json:"found-extension"`
+}
+
+func HandleExtensionRemove(request *ExtensionRemoveRequest) (*ExtensionRemoveResponse, *core.APIError) {
+    response := ExtensionRemoveResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    removed, err := db.RemoveExtension(request.Course, request.TargetUser.Email, request.AssignmentID);
+    if (err != nil) {
+        return nil, core.NewInternalError("-214", &request.APIRequestCourseUserContext,
+                "Failed to remove extension.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    response.FoundExtension = removed;
+
+    return &response, nil;
+}
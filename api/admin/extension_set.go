@@ -0,0 +1,83 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type ExtensionSetRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    TargetUser core.TargetUser `json:"target-email"`
+    // An empty assignment ID grants the extension for all assignments.
+    AssignmentID string `json:"assignment-id"`
+
+    DueDate common.Timestamp `json:"due-date"`
+    ExtraDays int `json:"extra-days"`
+    TimeMultiplier float64 `json:"time-multiplier"`
+    Reason string `json:"reason"`
+}
+
+type ExtensionSetResponse struct {
+    FoundUser bool `json:"found-user"`
+    Extension *model.Extension `json:"extension"`
+}
+
+func HandleExtensionSet(request *ExtensionSetRequest) (*ExtensionSetResponse, *core.APIError) {
+    response := ExtensionSetResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    apiErr := checkExtensionAssignment(&request.APIRequestCourseUserContext, request.AssignmentID);
+    if (apiErr != nil) {
+        return nil, apiErr;
+    }
+
+    extension := &model.Extension{
+        User: request.TargetUser.Email,
+        AssignmentID: request.AssignmentID,
+        DueDate: request.DueDate,
+        ExtraDays: request.ExtraDays,
+        TimeMultiplier: request.TimeMultiplier,
+        Reason: request.Reason,
+        GrantedBy: request.User.Email,
+        GrantedTime: common.NowTimestamp(),
+    };
+
+    err := extension.Validate();
+    if (err != nil) {
+        return nil, core.NewBadCourseRequestError("-211", &request.APIRequestCourseUserContext,
+                "Invalid extension.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    err = db.SaveExtension(request.Course, extension);
+    if (err != nil) {
+        return nil, core.NewInternalError("-212", &request.APIRequestCourseUserContext,
+                "Failed to save extension.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    response.Extension = extension;
+
+    return &response, nil;
+}
+
+// Ensure that a (non-empty) assignment ID exists in the course.
+func checkExtensionAssignment(request *core.APIRequestCourseUserContext, assignmentID string) *core.APIError {
+    if (assignmentID == "") {
+        return nil;
+    }
+
+    if (request.Course.GetAssignment(assignmentID) == nil) {
+        return core.NewBadCourseRequestError("-210", request,
+                "Could not find the extension's assignment.").Add("assignment-id", assignmentID);
+    }
+
+    return nil;
+}
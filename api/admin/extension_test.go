@@ -0,0 +1,155 @@
+package admin
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestExtensionSet(test *testing.T) {
+    defer db.ResetForTesting();
+
+    testCases := []struct{ role model.UserRole; fields map[string]any; permError bool; locator string; foundUser bool }{
+        {model.RoleAdmin, map[string]any{"target-email": "student@test.com", "extra-days": 2}, false, "", true},
+        {model.RoleOwner, map[string]any{"target-email": "student@test.com", "assignment-id": "", "time-multiplier": 1.5}, false, "", true},
+        {model.RoleAdmin, map[string]any{"target-email": "student@test.com", "due-date": "2024-01-01T00:00:00Z"}, false, "", true},
+        {model.RoleAdmin, map[string]any{"target-email": "zzz@test.com", "extra-days": 2}, false, "", false},
+
+        {model.RoleAdmin, map[string]any{"target-email": "student@test.com", "assignment-id": "zzz", "extra-days": 2}, false, "-210", true},
+        {model.RoleAdmin, map[string]any{"target-email": "student@test.com"}, false, "-211", true},
+        {model.RoleAdmin, map[string]any{"target-email": "student@test.com", "time-multiplier": 0.5}, false, "-211", true},
+        {model.RoleAdmin, map[string]any{"target-email": "student@test.com", "assignment-id": "", "due-date": "2024-01-01T00:00:00Z"}, false, "-211", true},
+
+        {model.RoleGrader, map[string]any{"target-email": "student@test.com", "extra-days": 2}, true, "-020", false},
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/extension/set`), testCase.fields, nil, testCase.role);
+        if (!response.Success) {
+            if (testCase.permError || (testCase.locator != "")) {
+                if (response.Locator != testCase.locator) {
+                    test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+                }
+            } else {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent ExtensionSetResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.FoundUser != testCase.foundUser) {
+            test.Errorf("Case %d: Unexpected found user. Expected: %v, Actual: %v.", i, testCase.foundUser, responseContent.FoundUser);
+            continue;
+        }
+
+        extensions, err := db.GetExtensions(db.MustGetTestCourse());
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get extensions: '%v'.", i, err);
+            continue;
+        }
+
+        if (!testCase.foundUser) {
+            if (len(extensions) != 0) {
+                test.Errorf("Case %d: Extension saved for missing user: '%s'.", i, util.MustToJSONIndent(extensions));
+            }
+
+            continue;
+        }
+
+        if (len(extensions) != 1) {
+            test.Errorf("Case %d: Unexpected number of extensions. Expected: 1, Actual: %d.", i, len(extensions));
+            continue;
+        }
+
+        if (util.MustToJSON(extensions[0]) != util.MustToJSON(responseContent.Extension)) {
+            test.Errorf("Case %d: Saved extension does not match response. Expected: '%s', Actual: '%s'.", i,
+                    util.MustToJSONIndent(responseContent.Extension), util.MustToJSONIndent(extensions[0]));
+            continue;
+        }
+
+        if (extensions[0].GrantedBy != (model.GetRoleString(testCase.role) + "@test.com")) {
+            test.Errorf("Case %d: Unexpected granter: '%s'.", i, extensions[0].GrantedBy);
+            continue;
+        }
+    }
+}
+
+func TestExtensionListRemove(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+
+    extensions := []*model.Extension{
+        &model.Extension{User: "student@test.com", AssignmentID: "hw0", ExtraDays: 1},
+        &model.Extension{User: "student@test.com", TimeMultiplier: 2.0},
+        &model.Extension{User: "grader@test.com", AssignmentID: "hw0", ExtraDays: 3},
+    };
+
+    for _, extension := range extensions {
+        err := db.SaveExtension(course, extension);
+        if (err != nil) {
+            test.Fatalf("Failed to save extension: '%v'.", err);
+        }
+    }
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/extension/list`),
+            map[string]any{"target-email": "student@test.com"}, nil, model.RoleAdmin);
+    if (!response.Success) {
+        test.Fatalf("List response is not a success when it should be: '%v'.", response);
+    }
+
+    var listContent ExtensionListResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &listContent);
+
+    if (len(listContent.Extensions) != 2) {
+        test.Fatalf("Unexpected number of listed extensions. Expected: 2, Actual: %d.", len(listContent.Extensions));
+    }
+
+    testCases := []struct{ fields map[string]any; foundUser bool; foundExtension bool; remaining int }{
+        {map[string]any{"target-email": "zzz@test.com"}, false, false, 3},
+        {map[string]any{"target-email": "student@test.com", "assignment-id": "hw0"}, true, true, 2},
+        {map[string]any{"target-email": "student@test.com", "assignment-id": "hw0"}, true, false, 2},
+        {map[string]any{"target-email": "student@test.com", "assignment-id": ""}, true, true, 1},
+    };
+
+    for i, testCase := range testCases {
+        response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/extension/remove`), testCase.fields, nil, model.RoleAdmin);
+        if (!response.Success) {
+            test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent ExtensionRemoveResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if ((responseContent.FoundUser != testCase.foundUser) || (responseContent.FoundExtension != testCase.foundExtension)) {
+            test.Errorf("Case %d: Unexpected response: '%s'.", i, util.MustToJSON(responseContent));
+            continue;
+        }
+
+        remaining, err := db.GetExtensions(course);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get extensions: '%v'.", i, err);
+            continue;
+        }
+
+        if (len(remaining) != testCase.remaining) {
+            test.Errorf("Case %d: Unexpected number of remaining extensions. Expected: %d, Actual: %d.", i, testCase.remaining, len(remaining));
+            continue;
+        }
+    }
+}
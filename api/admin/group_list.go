@@ -0,0 +1,42 @@
+package admin
+
+import (
+    "slices"
+    "strings"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type GroupListRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+}
+
+type GroupListResponse struct {
+    // Sorted by ID.
+    Groups []*model.Group `json:"groups"`
+}
+
+func HandleGroupList(request *GroupListRequest) (*GroupListResponse, *core.APIError) {
+    groups, err := db.GetGroups(request.Course);
+    if (err != nil) {
+        return nil, core.NewInternalError("-219", &request.APIRequestCourseUserContext,
+                "Failed to get groups.").Err(err);
+    }
+
+    response := GroupListResponse{
+        Groups: make([]*model.Group, 0, len(groups)),
+    };
+
+    for _, group := range groups {
+        response.Groups = append(response.Groups, group);
+    }
+
+    slices.SortFunc(response.Groups, func(a *model.Group, b *model.Group) int {
+        return strings.Compare(a.ID, b.ID);
+    });
+
+    return &response, nil;
+}
@@ -0,0 +1,31 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+)
+
+type GroupRemoveRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    GroupID string `json:"group-id"`
+}
+
+type GroupRemoveResponse struct {
+    FoundGroup bool `json:"found-group"`
+}
+
+func HandleGroupRemove(request *GroupRemoveRequest) (*GroupRemoveResponse, *core.APIError) {
+    removed, err := db.RemoveGroup(request.Course, request.GroupID);
+    if (err != nil) {
+        return nil, core.NewInternalError("-226", &request.APIRequestCourseUserContext,
+                "Failed to remove group.").Err(err).Add("group-id", request.GroupID);
+    }
+
+    response := GroupRemoveResponse{
+        FoundGroup: removed,
+    };
+
+    return &response, nil;
+}
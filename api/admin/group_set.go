@@ -0,0 +1,87 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type GroupSetRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    // An existing group with the same ID will be replaced.
+    GroupID string `json:"group-id"`
+    Name string `json:"name"`
+    // Member emails, every member must be a user in the course.
+    Members []string `json:"members"`
+}
+
+type GroupSetResponse struct {
+    Group *model.Group `json:"group"`
+}
+
+func HandleGroupSet(request *GroupSetRequest) (*GroupSetResponse, *core.APIError) {
+    group := &model.Group{
+        ID: request.GroupID,
+        Name: request.Name,
+        Members: request.Members,
+    };
+
+    err := group.Validate();
+    if (err != nil) {
+        return nil, core.NewBadCourseRequestError("-220", &request.APIRequestCourseUserContext,
+                "Invalid group.").Err(err).Add("group-id", request.GroupID);
+    }
+
+    users, err := db.GetUsers(request.Course);
+    if (err != nil) {
+        return nil, core.NewInternalError("-221", &request.APIRequestCourseUserContext,
+                "Failed to get users.").Err(err).Add("group-id", group.ID);
+    }
+
+    unknownMembers := make([]string, 0);
+    for _, member := range group.Members {
+        if (users[member] == nil) {
+            unknownMembers = append(unknownMembers, member);
+        }
+    }
+
+    if (len(unknownMembers) > 0) {
+        return nil, core.NewBadCourseRequestError("-222", &request.APIRequestCourseUserContext,
+                "Group has members that are not in the course.").
+                Add("group-id", group.ID).Add("unknown-members", unknownMembers);
+    }
+
+    groups, err := db.GetGroups(request.Course);
+    if (err != nil) {
+        return nil, core.NewInternalError("-223", &request.APIRequestCourseUserContext,
+                "Failed to get groups.").Err(err).Add("group-id", group.ID);
+    }
+
+    // Keep the LMS link of a group being replaced.
+    oldGroup := groups[group.ID];
+    if (oldGroup != nil) {
+        group.LMSID = oldGroup.LMSID;
+    }
+
+    groups[group.ID] = group;
+
+    err = model.ValidateGroupMembership(groups);
+    if (err != nil) {
+        return nil, core.NewBadCourseRequestError("-224", &request.APIRequestCourseUserContext,
+                "Users cannot be in more than one group.").Err(err).Add("group-id", group.ID);
+    }
+
+    err = db.SaveGroup(request.Course, group);
+    if (err != nil) {
+        return nil, core.NewInternalError("-225", &request.APIRequestCourseUserContext,
+                "Failed to save group.").Err(err).Add("group-id", group.ID);
+    }
+
+    response := GroupSetResponse{
+        Group: group,
+    };
+
+    return &response, nil;
+}
@@ -0,0 +1,134 @@
+package admin
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestGroupSet(test *testing.T) {
+    defer db.ResetForTesting();
+
+    testCases := []struct{ role model.UserRole; fields map[string]any; locator string }{
+        {model.RoleAdmin, map[string]any{"group-id": "team1", "members": []string{"student@test.com", "other@test.com"}}, ""},
+        {model.RoleOwner, map[string]any{"group-id": "Team1", "name": "Team 1", "members": []string{"student@test.com"}}, ""},
+
+        {model.RoleAdmin, map[string]any{"group-id": "", "members": []string{"student@test.com"}}, "-220"},
+        {model.RoleAdmin, map[string]any{"group-id": "team1"}, "-220"},
+        {model.RoleAdmin, map[string]any{"group-id": "team1", "members": []string{"student@test.com", "student@test.com"}}, "-220"},
+        {model.RoleAdmin, map[string]any{"group-id": "team1", "members": []string{"zzz@test.com"}}, "-222"},
+        {model.RoleAdmin, map[string]any{"group-id": "team2", "members": []string{"grader@test.com"}}, "-224"},
+
+        {model.RoleGrader, map[string]any{"group-id": "team1", "members": []string{"student@test.com"}}, "-020"},
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+
+        // An existing group for conflicts.
+        err := db.SaveGroup(db.MustGetTestCourse(), &model.Group{ID: "team0", Members: []string{"grader@test.com"}});
+        if (err != nil) {
+            test.Fatalf("Case %d: Failed to save group: '%v'.", i, err);
+        }
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/group/set`), testCase.fields, nil, testCase.role);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent GroupSetResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        groups, err := db.GetGroups(db.MustGetTestCourse());
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get groups: '%v'.", i, err);
+            continue;
+        }
+
+        if (len(groups) != 2) {
+            test.Errorf("Case %d: Unexpected number of groups. Expected: 2, Actual: %d.", i, len(groups));
+            continue;
+        }
+
+        if (util.MustToJSON(groups["team1"]) != util.MustToJSON(responseContent.Group)) {
+            test.Errorf("Case %d: Saved group does not match response. Expected: '%s', Actual: '%s'.", i,
+                    util.MustToJSONIndent(responseContent.Group), util.MustToJSONIndent(groups["team1"]));
+            continue;
+        }
+    }
+}
+
+func TestGroupListRemove(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+
+    groups := []*model.Group{
+        &model.Group{ID: "team2", Members: []string{"grader@test.com"}},
+        &model.Group{ID: "team1", Members: []string{"student@test.com", "other@test.com"}},
+    };
+
+    err := db.SaveGroups(course, groups);
+    if (err != nil) {
+        test.Fatalf("Failed to save groups: '%v'.", err);
+    }
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/group/list`), nil, nil, model.RoleAdmin);
+    if (!response.Success) {
+        test.Fatalf("List response is not a success when it should be: '%v'.", response);
+    }
+
+    var listContent GroupListResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &listContent);
+
+    if ((len(listContent.Groups) != 2) || (listContent.Groups[0].ID != "team1") || (listContent.Groups[1].ID != "team2")) {
+        test.Fatalf("Unexpected listed groups: '%s'.", util.MustToJSONIndent(listContent.Groups));
+    }
+
+    testCases := []struct{ groupID string; found bool; remaining int }{
+        {"zzz", false, 2},
+        {"team1", true, 1},
+        {"team1", false, 1},
+        {"Team2", true, 0},
+    };
+
+    for i, testCase := range testCases {
+        response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/group/remove`), map[string]any{"group-id": testCase.groupID}, nil, model.RoleAdmin);
+        if (!response.Success) {
+            test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent GroupRemoveResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.FoundGroup != testCase.found) {
+            test.Errorf("Case %d: Unexpected found group. Expected: %v, Actual: %v.", i, testCase.found, responseContent.FoundGroup);
+            continue;
+        }
+
+        remaining, err := db.GetGroups(course);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get groups: '%v'.", i, err);
+            continue;
+        }
+
+        if (len(remaining) != testCase.remaining) {
+            test.Errorf("Case %d: Unexpected number of remaining groups. Expected: %d, Actual: %d.", i, testCase.remaining, len(remaining));
+            continue;
+        }
+    }
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/scoring"
+)
+
+type LateDaysAdjustRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    TargetUser core.TargetUser `json:"target-email"`
+    // The number of late days to add (or remove, if negative).
+    Days int `json:"days"`
+    Reason string `json:"reason"`
+}
+
+type LateDaysAdjustResponse struct {
+    FoundUser bool `json:"found-user"`
+    LateDays *model.LateDaysBalance `json:"late-days"`
+}
+
+func HandleLateDaysAdjust(request *LateDaysAdjustRequest) (*LateDaysAdjustResponse, *core.APIError) {
+    response := LateDaysAdjustResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    if (request.Days == 0) {
+        return nil, core.NewBadCourseRequestError("-215", &request.APIRequestCourseUserContext,
+                "Number of days to adjust by cannot be zero.").Add("target-user", request.TargetUser.Email);
+    }
+
+    lock := scoring.GetLateDaysLock(request.Course);
+    lock.Lock();
+    defer lock.Unlock();
+
+    balance, initialEvent, err := scoring.GetUserLateDays(request.Course, request.TargetUser.Email);
+    if (err != nil) {
+        return nil, core.NewInternalError("-216", &request.APIRequestCourseUserContext,
+                "Failed to get late days.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    if (balance == nil) {
+        return nil, core.NewBadCourseRequestError("-217", &request.APIRequestCourseUserContext,
+                "User does not have late days (the course has no late days policy, or late days have not been imported from the LMS yet).").
+                Add("target-user", request.TargetUser.Email);
+    }
+
+    events := make([]*model.LateDaysEvent, 0, 2);
+    if (initialEvent != nil) {
+        events = append(events, initialEvent);
+    }
+
+    events = append(events, balance.Adjust(request.Days, model.LATE_DAYS_SOURCE_ADJUSTMENT, request.User.Email, request.Reason));
+
+    err = db.SaveLateDays(request.Course, map[string]*model.LateDaysBalance{balance.User: balance}, events);
+    if (err != nil) {
+        return nil, core.NewInternalError("-218", &request.APIRequestCourseUserContext,
+                "Failed to save late days.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    response.LateDays = balance;
+
+    return &response, nil;
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestLateDaysAdjust(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+
+    balance := model.NewLateDaysBalance("student@test.com", 0);
+    event := balance.Adjust(3, model.LATE_DAYS_SOURCE_INITIAL, "", "");
+
+    err := db.SaveLateDays(course, map[string]*model.LateDaysBalance{balance.User: balance}, []*model.LateDaysEvent{event});
+    if (err != nil) {
+        test.Fatalf("Failed to save late days: '%v'.", err);
+    }
+
+    testCases := []struct{ role model.UserRole; target string; days int; locator string; foundUser bool; expectedDays int }{
+        {model.RoleAdmin, "student@test.com", 2, "", true, 5},
+        {model.RoleOwner, "student@test.com", -1, "", true, 4},
+        {model.RoleAdmin, "zzz@test.com", 2, "", false, 4},
+
+        {model.RoleAdmin, "student@test.com", 0, "-215", true, 4},
+        {model.RoleAdmin, "grader@test.com", 1, "-217", true, 4},
+        {model.RoleGrader, "student@test.com", 1, "-020", false, 4},
+    };
+
+    for i, testCase := range testCases {
+        fields := map[string]any{
+            "target-email": testCase.target,
+            "days": testCase.days,
+            "reason": "test",
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/late-days/adjust`), fields, nil, testCase.role);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+        } else if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+        } else {
+            var responseContent LateDaysAdjustResponse;
+            util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+            if (responseContent.FoundUser != testCase.foundUser) {
+                test.Errorf("Case %d: Unexpected found user. Expected: %v, Actual: %v.", i, testCase.foundUser, responseContent.FoundUser);
+            }
+        }
+
+        current, err := db.GetUserLateDays(course, "student@test.com");
+        if (err != nil) {
+            test.Fatalf("Case %d: Failed to get late days: '%v'.", i, err);
+        }
+
+        if (current.AvailableDays != testCase.expectedDays) {
+            test.Errorf("Case %d: Unexpected available days. Expected: %d, Actual: %d.", i, testCase.expectedDays, current.AvailableDays);
+        }
+    }
+
+    history, err := db.GetLateDaysHistory(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get late days history: '%v'.", err);
+    }
+
+    // The initial event and two adjustments.
+    if (len(history) != 3) {
+        test.Fatalf("Unexpected history: '%s'.", util.MustToJSONIndent(history));
+    }
+
+    if ((history[1].Actor != "admin@test.com") || (history[1].Reason != "test") || (history[2].Change != -1)) {
+        test.Fatalf("Unexpected adjustment events: '%s'.", util.MustToJSONIndent(history));
+    }
+}
@@ -0,0 +1,64 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/grader"
+)
+
+type RegradeRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleAdmin
+
+    // Only regrade this user's submissions (all users when empty).
+    TargetUser string `json:"target-email"`
+    // Regrade all submissions instead of just the most recent ones.
+    All bool `json:"all"`
+    // Wait for all the regrades to finish before responding.
+    Wait bool `json:"wait"`
+}
+
+// Unless the request waits, regrades are queued and not waited for.
+// Then, the results only have the job IDs (which can be checked with the submission/status endpoint)
+// and not the new submissions or scores.
+type RegradeResponse struct {
+    Report *grader.RegradeReport `json:"report"`
+}
+
+func HandleRegrade(request *RegradeRequest) (*RegradeResponse, *core.APIError) {
+    targetEmail := "";
+    if (request.TargetUser != "") {
+        targetUser, err := db.GetUser(request.Course, request.TargetUser);
+        if (err != nil) {
+            return nil, core.NewInternalError("-207", &request.APIRequestCourseUserContext, "Failed to get target user.").
+                    Add("target-user", request.TargetUser).Err(err);
+        }
+
+        if (targetUser == nil) {
+            return nil, core.NewBadCourseRequestError("-208", &request.APIRequestCourseUserContext,
+                    "Could not find the target user.").Add("target-user", request.TargetUser);
+        }
+
+        targetEmail = targetUser.Email;
+    }
+
+    options := grader.RegradeOptions{
+        GradeOptions: grader.GetDefaultGradeOptions(),
+        User: targetEmail,
+        All: request.All,
+    };
+
+    report, err := grader.EnqueueRegrade(request.Assignment, options);
+    if (err != nil) {
+        return nil, core.NewInternalError("-209", &request.APIRequestCourseUserContext,
+                "Failed to queue submissions for regrading.").Err(err);
+    }
+
+    if (request.Wait) {
+        report.Wait();
+    }
+
+    return &RegradeResponse{
+        Report: report,
+    }, nil;
+}
@@ -0,0 +1,179 @@
+package admin
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/grader"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestRegrade(test *testing.T) {
+    // Leave the course in a good state after the test.
+    defer db.ResetForTesting();
+
+    assignment := db.MustGetTestAssignment();
+
+    original, err := db.GetSubmissionContents(assignment, "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get original submission: '%v'.", err);
+    }
+
+    fields := map[string]any{
+        "target-email": "student@test.com",
+    };
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/regrade`), fields, nil, model.RoleAdmin);
+    if (!response.Success) {
+        test.Fatalf("Response is not a success when it should be: '%v'.", response);
+    }
+
+    var responseContent RegradeResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+    results := responseContent.Report.Results;
+    if (len(results) != 1) {
+        test.Fatalf("Unexpected number of regrade results. Expected 1, found %d.", len(results));
+    }
+
+    result := results[0];
+    if ((result.Error != "") || (result.JobID == "")) {
+        test.Fatalf("Regrade was not queued: '%s'.", util.MustToJSON(result));
+    }
+
+    if ((result.OriginalID != original.Info.ID) || (result.OldScore != original.Info.Score)) {
+        test.Fatalf("Unexpected regrade result: '%s'.", util.MustToJSON(result));
+    }
+
+    // The regrade was not waited for, so there are no new results yet.
+    if ((result.NewID != "") || (result.NewScore != nil) || (result.MaxPoints != 0.0)) {
+        test.Fatalf("Queued regrade has new results: '%s'.", util.MustToJSON(result));
+    }
+
+    // Poll the regrade job until it is finished.
+    var info *grader.GradingJobInfo;
+    for i := 0; i < 600; i++ {
+        info = grader.GetJobInfo(result.JobID);
+        if (info == nil) {
+            test.Fatalf("Could not find regrade job '%s'.", result.JobID);
+        }
+
+        if ((info.Status != grader.JOB_STATUS_QUEUED) && (info.Status != grader.JOB_STATUS_RUNNING)) {
+            break;
+        }
+
+        time.Sleep(100 * time.Millisecond);
+    }
+
+    if ((info.Status != grader.JOB_STATUS_COMPLETE) || (info.Result == nil)) {
+        test.Fatalf("Regrade job did not complete. Status: '%s', Error: '%v'.", info.Status, info.Err);
+    }
+
+    if (info.Result.Info.Score != original.Info.Score) {
+        test.Fatalf("Regrade should not have changed the score. Expected: %f, Actual: %f.", original.Info.Score, info.Result.Info.Score);
+    }
+
+    regraded, err := db.GetSubmissionContents(assignment, "student@test.com", info.Result.Info.ShortID);
+    if (err != nil) {
+        test.Fatalf("Failed to get regraded submission: '%v'.", err);
+    }
+
+    if (regraded == nil) {
+        test.Fatalf("Could not find regraded submission '%s'.", info.Result.Info.ID);
+    }
+
+    if (regraded.Info.RegradeOf != original.Info.ID) {
+        test.Fatalf("Regraded submission is not linked to the original. Expected: '%s', Actual: '%s'.",
+                original.Info.ID, regraded.Info.RegradeOf);
+    }
+
+    if (regraded.Info.GradingStartTime != original.Info.GradingStartTime) {
+        test.Fatalf("Regraded submission did not keep the original submission time. Expected: '%s', Actual: '%s'.",
+                original.Info.GradingStartTime, regraded.Info.GradingStartTime);
+    }
+}
+
+func TestRegradeWait(test *testing.T) {
+    // Leave the course in a good state after the test.
+    defer db.ResetForTesting();
+
+    assignment := db.MustGetTestAssignment();
+
+    original, err := db.GetSubmissionContents(assignment, "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get original submission: '%v'.", err);
+    }
+
+    fields := map[string]any{
+        "target-email": "student@test.com",
+        "wait": true,
+    };
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/regrade`), fields, nil, model.RoleAdmin);
+    if (!response.Success) {
+        test.Fatalf("Response is not a success when it should be: '%v'.", response);
+    }
+
+    var responseContent RegradeResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+    results := responseContent.Report.Results;
+    if (len(results) != 1) {
+        test.Fatalf("Unexpected number of regrade results. Expected 1, found %d.", len(results));
+    }
+
+    result := results[0];
+    if ((result.Error != "") || (result.NewID == "") || (result.NewScore == nil)) {
+        test.Fatalf("Regrade was not waited for: '%s'.", util.MustToJSON(result));
+    }
+
+    if ((*result.NewScore != original.Info.Score) || (result.MaxPoints != original.Info.MaxPoints)) {
+        test.Fatalf("Unexpected regrade result: '%s'.", util.MustToJSON(result));
+    }
+}
+
+func TestRegradeNoSubmissions(test *testing.T) {
+    fields := map[string]any{
+        "target-email": "other@test.com",
+        "all": true,
+    };
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/regrade`), fields, nil, model.RoleAdmin);
+    if (!response.Success) {
+        test.Fatalf("Response is not a success when it should be: '%v'.", response);
+    }
+
+    var responseContent RegradeResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+    if (len(responseContent.Report.Results) != 0) {
+        test.Fatalf("Unexpected regrade results: '%s'.", util.MustToJSON(responseContent.Report.Results));
+    }
+}
+
+func TestRegradeBadRequest(test *testing.T) {
+    testCases := []struct{ role model.UserRole; target string; locator string }{
+        {model.RoleAdmin, "zzz@test.com", "-208"},
+        {model.RoleGrader, "", "-020"},
+        {model.RoleStudent, "student@test.com", "-020"},
+    };
+
+    for i, testCase := range testCases {
+        fields := map[string]any{
+            "target-email": testCase.target,
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/regrade`), fields, nil, testCase.role);
+        if (response.Success) {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        if (response.Locator != testCase.locator) {
+            test.Errorf("Case %d: Unexpected locator. Expected: '%s', Actual: '%s'.", i, testCase.locator, response.Locator);
+        }
+    }
+}
@@ -7,8 +7,20 @@ import (
 )
 
 var routes []*core.Route = []*core.Route{
+    core.NewAPIRoute(core.NewEndpoint(`admin/email/outbox`), HandleEmailOutbox),
+    core.NewAPIRoute(core.NewEndpoint(`admin/email/retry`), HandleEmailRetry),
+    core.NewAPIRoute(core.NewEndpoint(`admin/extension/list`), HandleExtensionList),
+    core.NewAPIRoute(core.NewEndpoint(`admin/extension/remove`), HandleExtensionRemove),
+    core.NewAPIRoute(core.NewEndpoint(`admin/extension/set`), HandleExtensionSet),
+    core.NewAPIRoute(core.NewEndpoint(`admin/group/list`), HandleGroupList),
+    core.NewAPIRoute(core.NewEndpoint(`admin/group/remove`), HandleGroupRemove),
+    core.NewAPIRoute(core.NewEndpoint(`admin/group/set`), HandleGroupSet),
+    core.NewAPIRoute(core.NewEndpoint(`admin/late-days/adjust`), HandleLateDaysAdjust),
     core.NewAPIRoute(core.NewEndpoint(`admin/logs/fetch`), HandleFetchLogs),
+    core.NewAPIRoute(core.NewEndpoint(`admin/regrade`), HandleRegrade),
+    core.NewAPIRoute(core.NewEndpoint(`admin/similarity`), HandleSimilarity),
     core.NewAPIRoute(core.NewEndpoint(`admin/update/course`), HandleUpdateCourse),
+    core.NewAPIRoute(core.NewEndpoint(`admin/webhook/deliveries`), HandleWebhookDeliveries),
 };
 
 func GetRoutes() *[]*core.Route {
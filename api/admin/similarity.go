@@ -0,0 +1,65 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/similarity"
+)
+
+type SimilarityRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleAdmin
+
+    // Only compare files of this language (all known languages when empty).
+    Language string `json:"language"`
+    // Use the defaults when zero.
+    KGramSize int `json:"kgram-size"`
+    WindowSize int `json:"window-size"`
+    MinSimilarity float64 `json:"min-similarity"`
+    // Also render the report as HTML.
+    HTML bool `json:"html"`
+}
+
+type SimilarityResponse struct {
+    Report *similarity.SimilarityReport `json:"report"`
+    HTML string `json:"html,omitempty"`
+}
+
+func HandleSimilarity(request *SimilarityRequest) (*SimilarityResponse, *core.APIError) {
+    options := similarity.GetDefaultOptions();
+    options.Language = request.Language;
+    options.MinSimilarity = request.MinSimilarity;
+
+    if (request.KGramSize != 0) {
+        options.KGramSize = request.KGramSize;
+    }
+
+    if (request.WindowSize != 0) {
+        options.WindowSize = request.WindowSize;
+    }
+
+    err := options.Validate();
+    if (err != nil) {
+        return nil, core.NewBadCourseRequestError("-227", &request.APIRequestCourseUserContext,
+                "Invalid similarity options.").Err(err);
+    }
+
+    report, err := similarity.AnalyzeAssignment(request.Assignment, options);
+    if (err != nil) {
+        return nil, core.NewInternalError("-228", &request.APIRequestCourseUserContext,
+                "Failed to compute submission similarity.").Err(err);
+    }
+
+    response := &SimilarityResponse{
+        Report: report,
+    };
+
+    if (request.HTML) {
+        response.HTML, err = report.ToHTML();
+        if (err != nil) {
+            return nil, core.NewInternalError("-229", &request.APIRequestCourseUserContext,
+                    "Failed to generate HTML similarity report.").Err(err);
+        }
+    }
+
+    return response, nil;
+}
@@ -0,0 +1,52 @@
+package admin
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestSimilarity(test *testing.T) {
+    testCases := []struct{ role model.UserRole; fields map[string]any; locator string; hasHTML bool }{
+        {model.RoleAdmin, nil, "", false},
+        {model.RoleOwner, map[string]any{"language": "python", "kgram-size": 5, "html": true}, "", true},
+
+        {model.RoleAdmin, map[string]any{"language": "zzz"}, "-227", false},
+        {model.RoleAdmin, map[string]any{"window-size": -1}, "-227", false},
+        {model.RoleAdmin, map[string]any{"min-similarity": 2.0}, "-227", false},
+
+        {model.RoleGrader, nil, "-020", false},
+    };
+
+    for i, testCase := range testCases {
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/similarity`), testCase.fields, nil, testCase.role);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent SimilarityResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        report := responseContent.Report;
+        if ((report == nil) || (report.AssignmentID != "hw0") || (report.NumberOfSubmissions != 1) || (len(report.Pairs) != 0)) {
+            test.Errorf("Case %d: Unexpected report: '%s'.", i, util.MustToJSONIndent(report));
+            continue;
+        }
+
+        if (testCase.hasHTML != (responseContent.HTML != "")) {
+            test.Errorf("Case %d: Unexpected HTML presence. Expected: %v.", i, testCase.hasHTML);
+            continue;
+        }
+    }
+}
@@ -0,0 +1,55 @@
+package admin
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type WebhookDeliveriesRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    // Only list deliveries with this status (all deliveries when empty).
+    Status string `json:"status"`
+    // Only list deliveries for this event (all events when empty).
+    Event string `json:"event"`
+}
+
+type WebhookDeliveriesResponse struct {
+    // In the order the deliveries were created.
+    Deliveries []*model.WebhookDelivery `json:"deliveries"`
+}
+
+func HandleWebhookDeliveries(request *WebhookDeliveriesRequest) (*WebhookDeliveriesResponse, *core.APIError) {
+    switch (request.Status) {
+        case "", model.WEBHOOK_DELIVERY_PENDING, model.WEBHOOK_DELIVERY_DELIVERED, model.WEBHOOK_DELIVERY_FAILED:
+        default:
+            return nil, core.NewBadCourseRequestError("-230", &request.APIRequestCourseUserContext,
+                    "Unknown webhook delivery status.").Add("status", request.Status);
+    }
+
+    deliveries, err := db.GetWebhookDeliveries(request.Course);
+    if (err != nil) {
+        return nil, core.NewInternalError("-231", &request.APIRequestCourseUserContext,
+                "Failed to get webhook deliveries.").Err(err);
+    }
+
+    response := WebhookDeliveriesResponse{
+        Deliveries: make([]*model.WebhookDelivery, 0, len(deliveries)),
+    };
+
+    for _, delivery := range deliveries {
+        if ((request.Status != "") && (request.Status != delivery.Status)) {
+            continue;
+        }
+
+        if ((request.Event != "") && (request.Event != delivery.Event)) {
+            continue;
+        }
+
+        response.Deliveries = append(response.Deliveries, delivery);
+    }
+
+    return &response, nil;
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestWebhookDeliveries(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    err := db.SaveWebhookDeliveries(db.MustGetTestCourse(), []*model.WebhookDelivery{
+        &model.WebhookDelivery{ID: "a", URL: "http://localhost/hook", Event: model.WEBHOOK_EVENT_SUBMISSION_GRADED, Payload: "{}", Status: model.WEBHOOK_DELIVERY_DELIVERED},
+        &model.WebhookDelivery{ID: "b", URL: "http://localhost/hook", Event: model.WEBHOOK_EVENT_COURSE_UPDATED, Payload: "{}", Status: model.WEBHOOK_DELIVERY_PENDING},
+        &model.WebhookDelivery{ID: "c", URL: "http://localhost/hook", Event: model.WEBHOOK_EVENT_SUBMISSION_GRADED, Payload: "{}", Status: model.WEBHOOK_DELIVERY_FAILED},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save webhook deliveries: '%v'.", err);
+    }
+
+    testCases := []struct{ role model.UserRole; fields map[string]any; expected []string; locator string }{
+        {model.RoleAdmin, nil, []string{"a", "b", "c"}, ""},
+        {model.RoleOwner, map[string]any{"status": model.WEBHOOK_DELIVERY_PENDING}, []string{"b"}, ""},
+        {model.RoleAdmin, map[string]any{"event": model.WEBHOOK_EVENT_SUBMISSION_GRADED}, []string{"a", "c"}, ""},
+        {model.RoleAdmin, map[string]any{"event": model.WEBHOOK_EVENT_SUBMISSION_GRADED, "status": model.WEBHOOK_DELIVERY_FAILED}, []string{"c"}, ""},
+        {model.RoleAdmin, map[string]any{"event": model.WEBHOOK_EVENT_LMS_SYNC}, []string{}, ""},
+
+        {model.RoleAdmin, map[string]any{"status": "ZZZ"}, nil, "-230"},
+        {model.RoleGrader, nil, nil, "-020"},
+    };
+
+    for i, testCase := range testCases {
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`admin/webhook/deliveries`), testCase.fields, nil, testCase.role);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent WebhookDeliveriesResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        ids := make([]string, 0, len(responseContent.Deliveries));
+        for _, delivery := range responseContent.Deliveries {
+            ids = append(ids, delivery.ID);
+        }
+
+        if (util.MustToJSON(testCase.expected) != util.MustToJSON(ids)) {
+            test.Errorf("Case %d: Unexpected deliveries. Expected: '%v', Actual: '%v'.", i, testCase.expected, ids);
+        }
+    }
+}
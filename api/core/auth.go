@@ -11,12 +11,30 @@ import (
 // If any error is retuturned, then the request should end and the response sent based on the error.
 // This assumes basic validation has already been done on the request.
 func (this *APIRequestCourseUserContext) Auth() (*model.User, *APIError) {
+    // Login lockouts only apply to passwords.
+    // Otherwise, anyone could lock out a user that authenticates with a token (e.g., a script) just by sending bad passwords.
+    // Tokens are random (so they cannot be guessed), and are still covered by the normal rate limits.
+    usingToken := (this.UserToken != "");
+
+    // Locked out users are rejected before their credentials are even checked.
+    // Unknown users can also be locked out (so lockouts do not reveal which users exist).
+    if (!usingToken) {
+        lockout := getLoginLockout(this.CourseID, this.UserEmail);
+        if (lockout > 0) {
+            return nil, NewRateLimitError("-043", this, lockout, "User is locked out after too many failed logins.");
+        }
+    }
+
     user, err := db.GetUser(this.Course, this.UserEmail);
     if (err != nil) {
         return nil, NewAuthBadRequestError("-012", this, "Cannot Get User").Err(err);
     }
 
     if (user == nil) {
+        if (!usingToken) {
+            recordLoginFailure(this.CourseID, this.UserEmail);
+        }
+
         return nil, NewAuthBadRequestError("-013", this, "Unknown User");
     }
 
@@ -25,9 +43,20 @@ func (this *APIRequestCourseUserContext) Auth() (*model.User, *APIError) {
         return user, nil;
     }
 
+    if (usingToken) {
+        this.Token = user.CheckToken(this.UserToken);
+        if (this.Token == nil) {
+            return nil, NewAuthBadRequestError("-041", this, "Bad Token");
+        }
+
+        return user, nil;
+    }
+
     if (!user.CheckPassword(this.UserPass)) {
+        recordLoginFailure(this.CourseID, this.UserEmail);
         return nil, NewAuthBadRequestError("-014", this, "Bad Password");
     }
 
+    recordLoginSuccess(this.CourseID, this.UserEmail);
     return user, nil;
 }
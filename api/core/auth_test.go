@@ -2,8 +2,10 @@ package core
 
 import (
     "testing"
+    "time"
 
     "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
     "github.com/edulinq/autograder/util"
 )
 
@@ -70,3 +72,70 @@ func TestAuth(test *testing.T) {
         }
     }
 }
+
+func TestAuthToken(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    type baseAPIRequest struct {
+        APIRequestCourseUserContext
+        MinRoleOther
+    }
+
+    course := db.MustGetTestCourse();
+    user, err := db.GetUser(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user: '%v'.", err);
+    }
+
+    _, secret, err := user.NewToken("", time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    err = db.SaveUser(course, user);
+    if (err != nil) {
+        test.Fatalf("Failed to save user: '%v'.", err);
+    }
+
+    testCases := []struct{email string; pass string; token string; locator string}{
+        {"student@test.com", "", secret, ""},
+        {"student@test.com", "Zstudent", secret, ""},
+
+        {"student@test.com", "", secret + "Z", "-041"},
+        {"student@test.com", "student", "Z", "-041"},
+        {"grader@test.com", "", secret, "-041"},
+        {"Zstudent@test.com", "", secret, "-013"},
+
+        {"student@test.com", "", "", "-017"},
+    };
+
+    for i, testCase := range testCases {
+        pass := "";
+        if (testCase.pass != "") {
+            pass = util.Sha256HexFromString(testCase.pass);
+        }
+
+        request := baseAPIRequest{
+            APIRequestCourseUserContext: APIRequestCourseUserContext{
+                CourseID: "course101",
+                UserEmail: testCase.email,
+                UserPass: pass,
+                UserToken: testCase.token,
+            },
+        };
+
+        apiErr := ValidateAPIRequest(nil, &request, "");
+
+        if ((apiErr == nil) && (testCase.locator != "")) {
+            test.Errorf("Case %d: Expecting error '%s', but got no error.", i, testCase.locator);
+        } else if ((apiErr != nil) && (testCase.locator == "")) {
+            test.Errorf("Case %d: Expecting no error, but got '%s': '%v'.", i, apiErr.Locator, apiErr);
+        } else if ((apiErr != nil) && (testCase.locator != "") && (apiErr.Locator != testCase.locator)) {
+            test.Errorf("Case %d: Got a different error than expected. Expected: '%s', actual: '%s' -- '%v'.",
+                    i, testCase.locator, apiErr.Locator, apiErr);
+        } else if ((apiErr == nil) && (request.Token == nil)) {
+            test.Errorf("Case %d: Token was not set on the request.", i);
+        }
+    }
+}
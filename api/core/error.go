@@ -3,7 +3,9 @@ package core
 import (
     "errors"
     "fmt"
+    "math"
     "net/http"
+    "time"
 
     "github.com/edulinq/autograder/common"
     "github.com/edulinq/autograder/log"
@@ -31,6 +33,8 @@ const (
     // The users role is not high enough for the specific operation.
     // Can happen at the validation or handling phases.
     HTTP_PERMISSIONS_ERROR = http.StatusForbidden;
+    // The client has sent too many requests (or failed to log in too many times) and should wait before trying again.
+    HTTP_STATUS_TOO_MANY_REQUESTS = http.StatusTooManyRequests;
 )
 
 // This is technically an error,
@@ -50,6 +54,9 @@ type APIError struct {
     AssignmentID string
     UserEmail string
 
+    // How long the client should wait before trying again (only for rate limit errors).
+    RetryAfter time.Duration
+
     AdditionalDetails map[string]any
 }
 
@@ -145,6 +152,7 @@ func (this *APIError) ToResponse() *APIResponse {
         Success: (this.HTTPStatus == HTTP_STATUS_GOOD),
         Message: this.ResponseText,
         Content: nil,
+        RetryAfter: getRetryAfterSecs(this.RetryAfter),
     };
 }
 
@@ -248,6 +256,48 @@ func NewInternalError(locator string, request *APIRequestCourseUserContext, inte
     return err;
 }
 
+func NewRateLimitError(locator string, request *APIRequestCourseUserContext, retryAfter time.Duration, internalMessage string) *APIError {
+    err := &APIError{
+        RequestID: request.RequestID,
+        Locator: locator,
+        Endpoint: request.Endpoint,
+        Timestamp: request.Timestamp,
+        LogLevel: log.LevelWarn,
+        HTTPStatus: HTTP_STATUS_TOO_MANY_REQUESTS,
+        InternalText: fmt.Sprintf("Rate limited: '%s'.", internalMessage),
+        ResponseText: getRateLimitMessage(retryAfter),
+        CourseID: request.CourseID,
+        UserEmail: request.UserEmail,
+        RetryAfter: retryAfter,
+    };
+
+    return err;
+}
+
+// A rate limit error before the request was even parsed.
+func NewBareRateLimitError(locator string, endpoint string, retryAfter time.Duration, internalMessage string) *APIError {
+    return &APIError{
+        RequestID: locator,
+        Locator: locator,
+        Endpoint: endpoint,
+        Timestamp: common.NowTimestamp(),
+        LogLevel: log.LevelWarn,
+        HTTPStatus: HTTP_STATUS_TOO_MANY_REQUESTS,
+        InternalText: fmt.Sprintf("Rate limited: '%s'.", internalMessage),
+        ResponseText: getRateLimitMessage(retryAfter),
+        RetryAfter: retryAfter,
+    };
+}
+
+func getRateLimitMessage(retryAfter time.Duration) string {
+    return fmt.Sprintf("Too many requests, try again in %d seconds.", getRetryAfterSecs(retryAfter));
+}
+
+// Round up so a client never retries too early.
+func getRetryAfterSecs(retryAfter time.Duration) int {
+    return int(math.Ceil(retryAfter.Seconds()));
+}
+
 // Very rare errors can occur so early that there is not even a request id.
 func NewBareInternalError(locator string, endpoint string, internalMessage string) *APIError {
     err := &APIError{
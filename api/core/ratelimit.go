@@ -0,0 +1,352 @@
+package core
+
+// Rate limiting and brute-force protection for the API.
+// Requests are limited with token buckets at three levels:
+// per client IP (checked for every route), per authenticated user, and per user on specific endpoints (e.g., submissions).
+// Separately, failed logins for a course user are counted and, after too many failures,
+// the user is locked out for a time that doubles with each additional failure.
+// All limits are kept in memory (and reset when the server restarts).
+
+import (
+    "fmt"
+    "math"
+    "net"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
+)
+
+const (
+    // The longest a user can ever be locked out for.
+    MAX_LOGIN_LOCKOUT = time.Hour;
+    // How often idle limits are removed.
+    RATE_LIMIT_SWEEP_INTERVAL = 10 * time.Minute;
+)
+
+type tokenBucket struct {
+    tokens float64
+    lastTime time.Time
+}
+
+type tokenBuckets struct {
+    lock sync.Mutex
+    buckets map[string]*tokenBucket
+    lastSweep time.Time
+}
+
+type loginFailures struct {
+    count int
+    lastFailure time.Time
+    lockedUntil time.Time
+}
+
+type loginLockouts struct {
+    lock sync.Mutex
+    failures map[string]*loginFailures
+    lastSweep time.Time
+}
+
+// A per-user limit for a specific endpoint.
+type endpointLimit struct {
+    rate int
+    burst int
+}
+
+var ipBuckets *tokenBuckets = newTokenBuckets();
+var userBuckets *tokenBuckets = newTokenBuckets();
+var endpointBuckets *tokenBuckets = newTokenBuckets();
+var lockouts *loginLockouts = newLoginLockouts();
+
+// The parsed value of config.WEB_RATE_LIMIT_ENDPOINTS (only re-parsed when the option changes).
+var endpointLimitsLock sync.Mutex;
+var endpointLimitsSource string = "";
+var endpointLimits map[string]endpointLimit = make(map[string]endpointLimit);
+
+// Check the limit for the client that sent this request.
+func checkIPRateLimit(request *http.Request) *APIError {
+    if (config.WEB_RATE_LIMIT_DISABLE.Get()) {
+        return nil;
+    }
+
+    ip := getClientIP(request);
+
+    allowed, retryAfter := ipBuckets.take(ip, config.WEB_RATE_LIMIT_IP_RATE.Get(), config.WEB_RATE_LIMIT_IP_BURST.Get());
+    if (allowed) {
+        return nil;
+    }
+
+    return NewBareRateLimitError("-042", request.URL.Path, retryAfter, "Too many requests from this address.").Add("ip", ip);
+}
+
+// Check the limits for an authenticated user (both the general user limit and any limit for this endpoint).
+func checkUserRateLimit(request *APIRequestCourseUserContext) *APIError {
+    if (config.WEB_RATE_LIMIT_DISABLE.Get()) {
+        return nil;
+    }
+
+    key := getLoginKey(request.CourseID, request.User.Email);
+
+    allowed, retryAfter := userBuckets.take(key, config.WEB_RATE_LIMIT_USER_RATE.Get(), config.WEB_RATE_LIMIT_USER_BURST.Get());
+    if (!allowed) {
+        return NewRateLimitError("-044", request, retryAfter, "Too many requests from this user.");
+    }
+
+    limit, ok := getEndpointLimits()[request.Endpoint];
+    if (!ok) {
+        return nil;
+    }
+
+    allowed, retryAfter = endpointBuckets.take(request.Endpoint + "::" + key, limit.rate, limit.burst);
+    if (!allowed) {
+        return NewRateLimitError("-045", request, retryAfter, "Too many requests to this endpoint from this user.");
+    }
+
+    return nil;
+}
+
+// Get how much longer a course user is locked out for (zero if they are not locked out).
+func getLoginLockout(courseID string, email string) time.Duration {
+    if (config.WEB_RATE_LIMIT_DISABLE.Get() || (config.WEB_LOGIN_MAX_FAILURES.Get() <= 0)) {
+        return 0;
+    }
+
+    return lockouts.remaining(getLoginKey(courseID, email));
+}
+
+func recordLoginFailure(courseID string, email string) {
+    if (config.WEB_RATE_LIMIT_DISABLE.Get() || (config.WEB_LOGIN_MAX_FAILURES.Get() <= 0)) {
+        return;
+    }
+
+    lockout := lockouts.fail(getLoginKey(courseID, email));
+    if (lockout > 0) {
+        log.Warn("User locked out after too many failed logins.", log.NewCourseAttr(courseID), log.NewUserAttr(email),
+                log.NewAttr("lockout", lockout.String()));
+    }
+}
+
+func recordLoginSuccess(courseID string, email string) {
+    lockouts.clear(getLoginKey(courseID, email));
+}
+
+// Forget all rate limits and lockouts.
+func ResetRateLimits() {
+    ipBuckets = newTokenBuckets();
+    userBuckets = newTokenBuckets();
+    endpointBuckets = newTokenBuckets();
+    lockouts = newLoginLockouts();
+}
+
+func newTokenBuckets() *tokenBuckets {
+    return &tokenBuckets{
+        buckets: make(map[string]*tokenBucket),
+        lastSweep: time.Now(),
+    };
+}
+
+// Try to take a token from the bucket with the given key.
+// |rate| is the number of tokens added per minute, and |burst| is the size of the bucket.
+// A non-positive rate or burst means there is no limit.
+// Returns whether the token was taken and (if not) how long until a token will be available.
+func (this *tokenBuckets) take(key string, rate int, burst int) (bool, time.Duration) {
+    if ((rate <= 0) || (burst <= 0)) {
+        return true, 0;
+    }
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    now := time.Now();
+    tokensPerSecond := float64(rate) / 60.0;
+
+    this.sweep(now, tokensPerSecond, burst);
+
+    bucket, ok := this.buckets[key];
+    if (!ok) {
+        bucket = &tokenBucket{tokens: float64(burst), lastTime: now};
+        this.buckets[key] = bucket;
+    }
+
+    bucket.tokens = math.Min(float64(burst), bucket.tokens + (now.Sub(bucket.lastTime).Seconds() * tokensPerSecond));
+    bucket.lastTime = now;
+
+    if (bucket.tokens >= 1.0) {
+        bucket.tokens -= 1.0;
+        return true, 0;
+    }
+
+    wait := time.Duration(((1.0 - bucket.tokens) / tokensPerSecond) * float64(time.Second));
+    return false, wait;
+}
+
+// Remove buckets that have been idle long enough to be full again (a full bucket is the same as no bucket).
+// The caller must hold the lock.
+func (this *tokenBuckets) sweep(now time.Time, tokensPerSecond float64, burst int) {
+    if (now.Sub(this.lastSweep) < RATE_LIMIT_SWEEP_INTERVAL) {
+        return;
+    }
+
+    this.lastSweep = now;
+
+    for key, bucket := range this.buckets {
+        if ((bucket.tokens + (now.Sub(bucket.lastTime).Seconds() * tokensPerSecond)) >= float64(burst)) {
+            delete(this.buckets, key);
+        }
+    }
+}
+
+func newLoginLockouts() *loginLockouts {
+    return &loginLockouts{
+        failures: make(map[string]*loginFailures),
+        lastSweep: time.Now(),
+    };
+}
+
+func (this *loginLockouts) remaining(key string) time.Duration {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    failures, ok := this.failures[key];
+    if (!ok) {
+        return 0;
+    }
+
+    return max(0, time.Until(failures.lockedUntil));
+}
+
+// Record a failure and return the new lockout (zero if the user is not locked out).
+func (this *loginLockouts) fail(key string) time.Duration {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    now := time.Now();
+    this.sweep(now);
+
+    failures, ok := this.failures[key];
+    if (!ok) {
+        failures = &loginFailures{};
+        this.failures[key] = failures;
+    }
+
+    failures.count++;
+    failures.lastFailure = now;
+
+    lockout := getLockoutDuration(failures.count);
+    if (lockout > 0) {
+        failures.lockedUntil = now.Add(lockout);
+    }
+
+    return lockout;
+}
+
+func (this *loginLockouts) clear(key string) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    delete(this.failures, key);
+}
+
+// Forget failures that are old enough that they would no longer matter.
+// The caller must hold the lock.
+func (this *loginLockouts) sweep(now time.Time) {
+    if (now.Sub(this.lastSweep) < RATE_LIMIT_SWEEP_INTERVAL) {
+        return;
+    }
+
+    this.lastSweep = now;
+
+    for key, failures := range this.failures {
+        if ((now.Sub(failures.lastFailure) > MAX_LOGIN_LOCKOUT) && now.After(failures.lockedUntil)) {
+            delete(this.failures, key);
+        }
+    }
+}
+
+// The lockout after the given number of consecutive failures.
+func getLockoutDuration(count int) time.Duration {
+    maxFailures := config.WEB_LOGIN_MAX_FAILURES.Get();
+    if ((maxFailures <= 0) || (count < maxFailures)) {
+        return 0;
+    }
+
+    lockout := time.Duration(max(0, config.WEB_LOGIN_LOCKOUT_SECS.Get())) * time.Second;
+    for i := maxFailures; (i < count) && (lockout < MAX_LOGIN_LOCKOUT); i++ {
+        lockout *= 2;
+    }
+
+    return min(lockout, MAX_LOGIN_LOCKOUT);
+}
+
+func getLoginKey(courseID string, email string) string {
+    return fmt.Sprintf("%s::%s", courseID, strings.ToLower(email));
+}
+
+// Get the per-endpoint limits (keyed by the full endpoint path).
+func getEndpointLimits() map[string]endpointLimit {
+    endpointLimitsLock.Lock();
+    defer endpointLimitsLock.Unlock();
+
+    source := config.WEB_RATE_LIMIT_ENDPOINTS.Get();
+    if (source == endpointLimitsSource) {
+        return endpointLimits;
+    }
+
+    endpointLimitsSource = source;
+    endpointLimits = parseEndpointLimits(source);
+
+    return endpointLimits;
+}
+
+// Parse a list of endpoint limits ('<endpoint>:<requests per minute>:<burst>', comma-separated).
+// Bad entries are logged and skipped.
+func parseEndpointLimits(source string) map[string]endpointLimit {
+    limits := make(map[string]endpointLimit);
+
+    for _, entry := range strings.Split(source, ",") {
+        entry = strings.TrimSpace(entry);
+        if (entry == "") {
+            continue;
+        }
+
+        parts := strings.Split(entry, ":");
+        if (len(parts) != 3) {
+            log.Warn("Skipping endpoint rate limit that is not formatted as '<endpoint>:<requests per minute>:<burst>'.", log.NewAttr("entry", entry));
+            continue;
+        }
+
+        rate, rateErr := strconv.Atoi(strings.TrimSpace(parts[1]));
+        burst, burstErr := strconv.Atoi(strings.TrimSpace(parts[2]));
+        if ((rateErr != nil) || (burstErr != nil)) {
+            log.Warn("Skipping endpoint rate limit with a bad rate or burst.", log.NewAttr("entry", entry));
+            continue;
+        }
+
+        limits[NewEndpoint(strings.TrimSpace(parts[0]))] = endpointLimit{rate, burst};
+    }
+
+    return limits;
+}
+
+// Get the address of the client that sent a request.
+func getClientIP(request *http.Request) string {
+    if (config.WEB_RATE_LIMIT_TRUST_PROXY.Get()) {
+        // The last address is the one added by our proxy (earlier addresses are from the client and cannot be trusted).
+        forwarded := strings.Split(request.Header.Get("X-Forwarded-For"), ",");
+        ip := strings.TrimSpace(forwarded[len(forwarded) - 1]);
+        if (ip != "") {
+            return ip;
+        }
+    }
+
+    host, _, err := net.SplitHostPort(request.RemoteAddr);
+    if (err != nil) {
+        return request.RemoteAddr;
+    }
+
+    return host;
+}
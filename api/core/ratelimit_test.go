@@ -0,0 +1,327 @@
+package core
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestRateLimitIP(test *testing.T) {
+    defer resetRateLimitOptions(test);
+    resetRateLimitOptions(test);
+
+    config.WEB_RATE_LIMIT_DISABLE.Set(false);
+    config.WEB_RATE_LIMIT_IP_RATE.Set(1);
+    config.WEB_RATE_LIMIT_IP_BURST.Set(2);
+
+    endpoint := NewEndpoint(`test/ratelimit/ip`);
+    routes = append(routes, NewAPIRoute(endpoint, handleRateLimitTest));
+
+    for i := 0; i < 2; i++ {
+        response := SendTestAPIRequestFull(test, endpoint, nil, nil, model.RoleStudent);
+        if (!response.Success) {
+            test.Fatalf("Request %d failed: '%v'.", i, response);
+        }
+    }
+
+    // The limit is by address, so other users are limited too.
+    response := SendTestAPIRequestFull(test, endpoint, nil, nil, model.RoleAdmin);
+    checkRateLimitResponse(test, response, "-042", time.Minute);
+}
+
+func TestRateLimitUser(test *testing.T) {
+    defer resetRateLimitOptions(test);
+    resetRateLimitOptions(test);
+
+    config.WEB_RATE_LIMIT_DISABLE.Set(false);
+    config.WEB_RATE_LIMIT_USER_RATE.Set(1);
+    config.WEB_RATE_LIMIT_USER_BURST.Set(2);
+
+    endpoint := NewEndpoint(`test/ratelimit/user`);
+    routes = append(routes, NewAPIRoute(endpoint, handleRateLimitTest));
+
+    for i := 0; i < 2; i++ {
+        response := SendTestAPIRequestFull(test, endpoint, nil, nil, model.RoleStudent);
+        if (!response.Success) {
+            test.Fatalf("Request %d failed: '%v'.", i, response);
+        }
+    }
+
+    response := SendTestAPIRequestFull(test, endpoint, nil, nil, model.RoleStudent);
+    checkRateLimitResponse(test, response, "-044", time.Minute);
+
+    // Other users are not affected.
+    response = SendTestAPIRequestFull(test, endpoint, nil, nil, model.RoleGrader);
+    if (!response.Success) {
+        test.Fatalf("Request from another user failed: '%v'.", response);
+    }
+}
+
+func TestRateLimitEndpoint(test *testing.T) {
+    defer resetRateLimitOptions(test);
+    resetRateLimitOptions(test);
+
+    config.WEB_RATE_LIMIT_DISABLE.Set(false);
+    config.WEB_RATE_LIMIT_ENDPOINTS.Set("test/ratelimit/endpoint/limited:2:1");
+
+    limitedEndpoint := NewEndpoint(`test/ratelimit/endpoint/limited`);
+    otherEndpoint := NewEndpoint(`test/ratelimit/endpoint/other`);
+
+    routes = append(routes, NewAPIRoute(limitedEndpoint, handleRateLimitTest));
+    routes = append(routes, NewAPIRoute(otherEndpoint, handleRateLimitTest));
+
+    response := SendTestAPIRequestFull(test, limitedEndpoint, nil, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("First request failed: '%v'.", response);
+    }
+
+    response = SendTestAPIRequestFull(test, limitedEndpoint, nil, nil, model.RoleStudent);
+    checkRateLimitResponse(test, response, "-045", 30 * time.Second);
+
+    // Other endpoints and other users are not affected.
+    response = SendTestAPIRequestFull(test, otherEndpoint, nil, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Request to another endpoint failed: '%v'.", response);
+    }
+
+    response = SendTestAPIRequestFull(test, limitedEndpoint, nil, nil, model.RoleGrader);
+    if (!response.Success) {
+        test.Fatalf("Request from another user failed: '%v'.", response);
+    }
+}
+
+func TestLoginLockout(test *testing.T) {
+    defer resetRateLimitOptions(test);
+    resetRateLimitOptions(test);
+
+    config.WEB_RATE_LIMIT_DISABLE.Set(false);
+    config.WEB_LOGIN_MAX_FAILURES.Set(3);
+    config.WEB_LOGIN_LOCKOUT_SECS.Set(30);
+
+    testCases := []struct{email string; pass string; locator string; lockout time.Duration}{
+        // A success resets the failures.
+        {"student@test.com", "Zstudent", "-014", 0},
+        {"student@test.com", "Zstudent", "-014", 0},
+        {"student@test.com", "student", "", 0},
+
+        {"student@test.com", "Zstudent", "-014", 0},
+        {"student@test.com", "Zstudent", "-014", 0},
+        {"student@test.com", "Zstudent", "-014", 0},
+
+        // Locked out (even with the correct password).
+        {"student@test.com", "student", "-043", 30 * time.Second},
+
+        // Other users are not affected.
+        {"grader@test.com", "grader", "", 0},
+
+        // Unknown users are locked out the same way.
+        {"ZZZ@test.com", "student", "-013", 0},
+        {"ZZZ@test.com", "student", "-013", 0},
+        {"ZZZ@test.com", "student", "-013", 0},
+        {"ZZZ@test.com", "student", "-043", 30 * time.Second},
+    };
+
+    for i, testCase := range testCases {
+        apiErr := sendAuthTestRequest(testCase.email, testCase.pass);
+
+        if (testCase.locator == "") {
+            if (apiErr != nil) {
+                test.Fatalf("Case %d: Expecting no error, but got '%s': '%v'.", i, apiErr.Locator, apiErr);
+            }
+
+            continue;
+        }
+
+        if ((apiErr == nil) || (apiErr.Locator != testCase.locator)) {
+            test.Fatalf("Case %d: Got a different error than expected. Expected: '%s', actual: '%v'.", i, testCase.locator, apiErr);
+        }
+
+        if ((testCase.lockout > 0) && ((apiErr.RetryAfter <= 0) || (apiErr.RetryAfter > testCase.lockout))) {
+            test.Fatalf("Case %d: Unexpected retry after. Expected at most: '%v', actual: '%v'.", i, testCase.lockout, apiErr.RetryAfter);
+        }
+    }
+}
+
+// Password lockouts do not affect requests that use a token (and bad tokens do not cause lockouts).
+func TestLoginLockoutToken(test *testing.T) {
+    defer resetRateLimitOptions(test);
+    resetRateLimitOptions(test);
+
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    config.WEB_RATE_LIMIT_DISABLE.Set(false);
+    config.WEB_LOGIN_MAX_FAILURES.Set(3);
+    config.WEB_LOGIN_LOCKOUT_SECS.Set(30);
+
+    course := db.MustGetTestCourse();
+    user, err := db.GetUser(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user: '%v'.", err);
+    }
+
+    _, secret, err := user.NewToken("test", 0);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    err = db.SaveUser(course, user);
+    if (err != nil) {
+        test.Fatalf("Failed to save user: '%v'.", err);
+    }
+
+    for i := 0; i < 3; i++ {
+        apiErr := sendAuthTestRequest("student@test.com", "Zstudent");
+        if ((apiErr == nil) || (apiErr.Locator != "-014")) {
+            test.Fatalf("Attempt %d: Unexpected error for a bad password: '%v'.", i, apiErr);
+        }
+    }
+
+    apiErr := sendAuthTestRequest("student@test.com", "student");
+    if ((apiErr == nil) || (apiErr.Locator != "-043")) {
+        test.Fatalf("User was not locked out: '%v'.", apiErr);
+    }
+
+    // The token still works.
+    apiErr = sendAuthTokenTestRequest("student@test.com", secret);
+    if (apiErr != nil) {
+        test.Fatalf("Token request failed while locked out: '%v'.", apiErr);
+    }
+
+    // A token login does not end the lockout.
+    apiErr = sendAuthTestRequest("student@test.com", "student");
+    if ((apiErr == nil) || (apiErr.Locator != "-043")) {
+        test.Fatalf("Token request ended the lockout: '%v'.", apiErr);
+    }
+
+    // Bad tokens never cause a lockout.
+    for i := 0; i < 5; i++ {
+        apiErr = sendAuthTokenTestRequest("grader@test.com", "ZZZ");
+        if ((apiErr == nil) || (apiErr.Locator != "-041")) {
+            test.Fatalf("Attempt %d: Unexpected error for a bad token: '%v'.", i, apiErr);
+        }
+    }
+
+    apiErr = sendAuthTestRequest("grader@test.com", "grader");
+    if (apiErr != nil) {
+        test.Fatalf("User was locked out by bad tokens: '%v'.", apiErr);
+    }
+}
+
+func TestGetLockoutDuration(test *testing.T) {
+    defer resetRateLimitOptions(test);
+    resetRateLimitOptions(test);
+
+    config.WEB_LOGIN_MAX_FAILURES.Set(3);
+    config.WEB_LOGIN_LOCKOUT_SECS.Set(30);
+
+    testCases := []struct{count int; expected time.Duration}{
+        {1, 0},
+        {2, 0},
+        {3, 30 * time.Second},
+        {4, time.Minute},
+        {5, 2 * time.Minute},
+        {100, MAX_LOGIN_LOCKOUT},
+    };
+
+    for i, testCase := range testCases {
+        actual := getLockoutDuration(testCase.count);
+        if (testCase.expected != actual) {
+            test.Errorf("Case %d: Unexpected lockout. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual);
+        }
+    }
+}
+
+func TestParseEndpointLimits(test *testing.T) {
+    testCases := []struct{source string; expected map[string]endpointLimit}{
+        {"", map[string]endpointLimit{}},
+        {"a:1:2", map[string]endpointLimit{NewEndpoint("a"): endpointLimit{1, 2}}},
+        {" a/b : 1 : 2 , /c:3:4 ,", map[string]endpointLimit{NewEndpoint("a/b"): endpointLimit{1, 2}, NewEndpoint("c"): endpointLimit{3, 4}}},
+
+        // Bad entries are skipped.
+        {"a:1:2,b:1,c:x:2,d:1:x", map[string]endpointLimit{NewEndpoint("a"): endpointLimit{1, 2}}},
+    };
+
+    for i, testCase := range testCases {
+        actual := parseEndpointLimits(testCase.source);
+        if (len(testCase.expected) != len(actual)) {
+            test.Errorf("Case %d: Unexpected limits. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual);
+            continue;
+        }
+
+        for endpoint, limit := range testCase.expected {
+            if (actual[endpoint] != limit) {
+                test.Errorf("Case %d: Unexpected limit for '%s'. Expected: '%v', Actual: '%v'.", i, endpoint, limit, actual[endpoint]);
+            }
+        }
+    }
+}
+
+func handleRateLimitTest(request *BaseTestRequest) (*any, *APIError) {
+    return nil, nil;
+}
+
+func sendAuthTestRequest(email string, pass string) *APIError {
+    request := BaseTestRequest{
+        APIRequestCourseUserContext: APIRequestCourseUserContext{
+            CourseID: "course101",
+            UserEmail: email,
+            UserPass: util.Sha256HexFromString(pass),
+        },
+    };
+
+    return ValidateAPIRequest(nil, &request, "");
+}
+
+func sendAuthTokenTestRequest(email string, token string) *APIError {
+    request := BaseTestRequest{
+        APIRequestCourseUserContext: APIRequestCourseUserContext{
+            CourseID: "course101",
+            UserEmail: email,
+            UserToken: token,
+        },
+    };
+
+    return ValidateAPIRequest(nil, &request, "");
+}
+
+func checkRateLimitResponse(test *testing.T, response *APIResponse, locator string, maxRetryAfter time.Duration) {
+    if (response.Success) {
+        test.Fatalf("Request was not rate limited: '%v'.", response);
+    }
+
+    if ((response.Locator != locator) || (response.HTTPStatus != HTTP_STATUS_TOO_MANY_REQUESTS)) {
+        test.Fatalf("Unexpected rate limit response. Expected locator '%s', actual: '%v'.", locator, response);
+    }
+
+    if ((response.RetryAfter <= 0) || (response.RetryAfter > int(maxRetryAfter.Seconds()))) {
+        test.Fatalf("Unexpected retry after. Expected at most %d seconds, actual: %d.", int(maxRetryAfter.Seconds()), response.RetryAfter);
+    }
+}
+
+// Put all the rate limit options back to how unit tests expect them (disabled) and forget all limits.
+func resetRateLimitOptions(test *testing.T) {
+    config.WEB_RATE_LIMIT_DISABLE.Set(true);
+
+    options := []*config.IntOption{
+        config.WEB_RATE_LIMIT_IP_RATE,
+        config.WEB_RATE_LIMIT_IP_BURST,
+        config.WEB_RATE_LIMIT_USER_RATE,
+        config.WEB_RATE_LIMIT_USER_BURST,
+        config.WEB_LOGIN_MAX_FAILURES,
+        config.WEB_LOGIN_LOCKOUT_SECS,
+    };
+
+    // Only the limits a test sets should apply.
+    for _, option := range options {
+        option.Set(0);
+    }
+
+    config.WEB_RATE_LIMIT_ENDPOINTS.Set("");
+
+    ResetRateLimits();
+}
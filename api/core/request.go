@@ -33,11 +33,15 @@ type APIRequestCourseUserContext struct {
     CourseID string `json:"course-id"`
     UserEmail string `json:"user-email"`
     UserPass string `json:"user-pass"`
+    // An API token (see user/login) can be used instead of a password.
+    UserToken string `json:"user-token"`
 
     // These fields are filled out as the request is parsed,
     // before being sent to the handler.
     Course *model.Course
     User *model.User
+    // The token used to authenticate this request (nil if a password was used).
+    Token *model.APIToken
 }
 
 //Context for requests that need an assignment on top of a user/course.
@@ -78,8 +82,8 @@ func (this *APIRequestCourseUserContext) Validate(request any, endpoint string)
         return NewBadRequestError("-016", &this.APIRequest, "No user email specified.");
     }
 
-    if (this.UserPass == "") {
-        return NewBadRequestError("-017", &this.APIRequest, "No user password specified.");
+    if ((this.UserPass == "") && (this.UserToken == "")) {
+        return NewBadRequestError("-017", &this.APIRequest, "No user password or token specified.");
     }
 
     var err error;
@@ -98,6 +102,11 @@ func (this *APIRequestCourseUserContext) Validate(request any, endpoint string)
         return apiErr;
     }
 
+    apiErr = checkUserRateLimit(this);
+    if (apiErr != nil) {
+        return apiErr;
+    }
+
     minRole, foundRole := getMaxRole(request);
     if (!foundRole) {
         return NewInternalError("-019", this, "No role found for request. All request structs require a minimum role.");
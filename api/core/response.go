@@ -20,6 +20,9 @@ type APIResponse struct {
 
     Message string `json:"message"`
     Content any `json:"content"`
+
+    // For rate limited requests, the number of seconds to wait before trying again.
+    RetryAfter int `json:"retry-after,omitempty"`
 }
 
 func (this *APIResponse) String() string {
@@ -9,6 +9,7 @@ import (
     "reflect"
     "regexp"
     "runtime"
+    "strconv"
     "strings"
 
     "github.com/edulinq/autograder/log"
@@ -73,6 +74,16 @@ func ServeRoutes(routes *[]*Route, response http.ResponseWriter, request *http.R
             continue;
         }
 
+        apiErr := checkIPRateLimit(request);
+        if (apiErr != nil) {
+            err := SendAPIResponse(response, nil, apiErr);
+            if (err != nil) {
+                log.Error("Failed to send rate limit response.", err, log.NewAttr("path", request.URL.Path));
+            }
+
+            return;
+        }
+
         err := route.handler(response, request);
         if (err != nil) {
             log.Error("Handler had an error.", err, log.NewAttr("path", request.URL.Path));
@@ -147,6 +158,12 @@ func handleAPIEndpoint(response http.ResponseWriter, request *http.Request, apiH
     return sendAPIResponse(apiRequest, response, apiResponse, apiErr, false);
 }
 
+// Send an API response from a route that is not a standard API endpoint (e.g. a GET route).
+// See sendAPIResponse().
+func SendAPIResponse(response http.ResponseWriter, content any, apiErr *APIError) error {
+    return sendAPIResponse(nil, response, content, apiErr, false);
+}
+
 // Send out the result from an API call.
 // If the APIError is not null, then it will be sent and no content will be sent.
 // Otherwise, send the content in the response's "content" field.
@@ -180,6 +197,10 @@ func sendAPIResponse(apiRequest ValidAPIRequest, response http.ResponseWriter,
         }
     }
 
+    if (apiResponse.RetryAfter > 0) {
+        response.Header().Set("Retry-After", strconv.Itoa(apiResponse.RetryAfter));
+    }
+
     response.WriteHeader(apiResponse.HTTPStatus);
 
     _, err = fmt.Fprint(response, payload);
@@ -0,0 +1,194 @@
+package core
+
+// Streaming API endpoints.
+// A streaming endpoint takes the same requests as a normal API endpoint,
+// but responds with a stream of Server-Sent Events (https://html.spec.whatwg.org/multipage/server-sent-events.html)
+// instead of a single JSON response.
+// Each event's data is a single line of JSON.
+// Any error that happens before the first event is sent as a normal (JSON) API response.
+// Errors after the stream has started are sent as a final STREAM_EVENT_ERROR event (holding an API response).
+
+import (
+    "fmt"
+    "net/http"
+    "reflect"
+    "regexp"
+    "strings"
+
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+const STREAM_EVENT_ERROR = "error";
+
+// A handler for streaming API endpoints.
+// Just like an APIHandler, the first argument should be an APIRequest derived type.
+// Events are sent with the EventStream.
+type APIStreamHandler func(*any, *EventStream) *APIError;
+
+type EventStream struct {
+    response http.ResponseWriter
+    request *http.Request
+    started bool
+}
+
+// A single parsed event (usually for testing).
+type StreamEvent struct {
+    Name string `json:"name"`
+    Data string `json:"data"`
+}
+
+func NewAPIStreamRoute(pattern string, apiHandler any) *Route {
+    handler := func(response http.ResponseWriter, request *http.Request) (err error) {
+        stream := &EventStream{
+            response: response,
+            request: request,
+        };
+
+        // Recover from any panic.
+        defer func() {
+            value := recover();
+            if (value == nil) {
+                return;
+            }
+
+            log.Error("Recovered from a panic when handling a streaming API endpoint.",
+                    log.NewAttr("value", value), log.NewAttr("endpoint", request.URL.Path));
+            apiErr := NewBareInternalError("-037", request.URL.Path, "Recovered from a panic when handling a streaming API endpoint.").
+                    Add("value", value);
+
+            err = stream.sendError(nil, apiErr);
+        }();
+
+        err = handleAPIStreamEndpoint(stream, apiHandler);
+
+        return err;
+    }
+
+    return &Route{"POST", regexp.MustCompile("^" + pattern + "$"), handler};
+}
+
+// Send an event to the client (starting the stream if this is the first event).
+// The data is encoded as JSON.
+func (this *EventStream) Send(name string, data any) error {
+    payload, err := util.ToJSON(data);
+    if (err != nil) {
+        return fmt.Errorf("Could not serialize stream event '%s': '%w'.", name, err);
+    }
+
+    if (!this.started) {
+        this.started = true;
+
+        this.response.Header().Set("Content-Type", "text/event-stream");
+        this.response.Header().Set("Cache-Control", "no-cache");
+        this.response.Header().Set("X-Accel-Buffering", "no");
+        this.response.WriteHeader(HTTP_STATUS_GOOD);
+    }
+
+    _, err = fmt.Fprintf(this.response, "event: %s\ndata: %s\n\n", name, payload);
+    if (err != nil) {
+        return fmt.Errorf("Could not write stream event '%s': '%w'.", name, err);
+    }
+
+    flusher, ok := this.response.(http.Flusher);
+    if (ok) {
+        flusher.Flush();
+    }
+
+    return nil;
+}
+
+// Closed when the client goes away.
+func (this *EventStream) Done() <-chan struct{} {
+    return this.request.Context().Done();
+}
+
+func (this *EventStream) sendError(apiRequest ValidAPIRequest, apiErr *APIError) error {
+    if (!this.started) {
+        return sendAPIResponse(apiRequest, this.response, nil, apiErr, false);
+    }
+
+    apiErr.Log();
+    return this.Send(STREAM_EVENT_ERROR, apiErr.ToResponse());
+}
+
+func handleAPIStreamEndpoint(stream *EventStream, apiHandler any) error {
+    endpoint := stream.request.URL.Path;
+
+    // Ensure the handler looks good.
+    validAPIHandler, apiErr := validateAPIStreamHandler(endpoint, apiHandler);
+    if (apiErr != nil) {
+        return stream.sendError(nil, apiErr);
+    }
+
+    // Get the actual request.
+    apiRequest, apiErr := createAPIRequest(stream.request, validAPIHandler);
+    if (apiErr != nil) {
+        return stream.sendError(nil, apiErr);
+    }
+    defer CleanupAPIrequest(apiRequest);
+
+    // Execute the handler.
+    input := []reflect.Value{reflect.ValueOf(apiRequest), reflect.ValueOf(stream)};
+    output := reflect.ValueOf(apiHandler).Call(input);
+
+    apiErr = output[0].Interface().(*APIError);
+    if (apiErr != nil) {
+        return stream.sendError(apiRequest, apiErr);
+    }
+
+    return nil;
+}
+
+// Reflexively ensure that the api handler is of the correct type/format (e.g. looks like APIStreamHandler).
+func validateAPIStreamHandler(endpoint string, apiHandler any) (ValidAPIHandler, *APIError) {
+    reflectValue := reflect.ValueOf(apiHandler);
+    reflectType := reflect.TypeOf(apiHandler);
+
+    if (reflectValue.Kind() != reflect.Func) {
+        return nil, NewBareInternalError("-038", endpoint, "API stream handler is not a function.").
+                Add("kind", reflectValue.Kind().String());
+    }
+
+    funcInfo := getFuncInfo(apiHandler);
+
+    if ((reflectType.NumIn() != 2) || (reflectType.In(0).Kind() != reflect.Pointer) ||
+            (reflectType.In(1) != reflect.TypeOf((*EventStream)(nil)))) {
+        return nil, NewBareInternalError("-039", endpoint, "API stream handler does not take a request pointer and an event stream.").
+                Add("function-info", funcInfo);
+    }
+
+    if ((reflectType.NumOut() != 1) || (reflectType.Out(0) != reflect.TypeOf((*APIError)(nil)))) {
+        return nil, NewBareInternalError("-040", endpoint, "API stream handler does not return exactly one *APIError.").
+                Add("function-info", funcInfo);
+    }
+
+    return ValidAPIHandler(apiHandler), nil;
+}
+
+// Parse the text of an event stream.
+func ParseEventStream(text string) []*StreamEvent {
+    events := make([]*StreamEvent, 0);
+
+    for _, block := range strings.Split(text, "\n\n") {
+        if (strings.TrimSpace(block) == "") {
+            continue;
+        }
+
+        event := &StreamEvent{};
+        dataLines := make([]string, 0, 1);
+
+        for _, line := range strings.Split(block, "\n") {
+            if (strings.HasPrefix(line, "event:")) {
+                event.Name = strings.TrimSpace(strings.TrimPrefix(line, "event:"));
+            } else if (strings.HasPrefix(line, "data:")) {
+                dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "));
+            }
+        }
+
+        event.Data = strings.Join(dataLines, "\n");
+        events = append(events, event);
+    }
+
+    return events;
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+    "fmt"
+    "testing"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestStreamBase(test *testing.T) {
+    endpoint := `/test/api/stream/base`;
+
+    handler := func(request *BaseTestRequest, stream *EventStream) *APIError {
+        for i := 0; i < 3; i++ {
+            err := stream.Send("count", map[string]int{"i": i});
+            if (err != nil) {
+                return NewInternalError("-900", &request.APIRequestCourseUserContext, "Failed to send.").Err(err);
+            }
+        }
+
+        stream.Send("text", "a\nb");
+
+        return nil;
+    };
+
+    routes = append(routes, NewAPIStreamRoute(endpoint, handler));
+
+    events, response := SendTestAPIStreamRequest(test, endpoint, nil, nil, model.RoleAdmin);
+    if (response != nil) {
+        test.Fatalf("Got a response instead of a stream: '%v'.", response);
+    }
+
+    expected := []*StreamEvent{
+        &StreamEvent{"count", `{"i":0}`},
+        &StreamEvent{"count", `{"i":1}`},
+        &StreamEvent{"count", `{"i":2}`},
+        &StreamEvent{"text", `"a\nb"`},
+    };
+
+    if (util.MustToJSON(expected) != util.MustToJSON(events)) {
+        test.Fatalf("Unexpected events. Expected: '%s', Actual: '%s'.", util.MustToJSON(expected), util.MustToJSON(events));
+    }
+}
+
+func TestStreamErrors(test *testing.T) {
+    testCases := []struct{ handler any; role model.UserRole; locator string; streamed bool }{
+        // Bad handlers.
+        {nil, model.RoleAdmin, "-038", false},
+        {func(request *BaseTestRequest) *APIError { return nil }, model.RoleAdmin, "-039", false},
+        {func(request BaseTestRequest, stream *EventStream) *APIError { return nil }, model.RoleAdmin, "-039", false},
+        {func(request *BaseTestRequest, stream *EventStream) error { return nil }, model.RoleAdmin, "-040", false},
+
+        // Bad request.
+        {func(request *BaseTestRequest, stream *EventStream) *APIError { return nil }, model.RoleOther, "-020", false},
+
+        // Errors before and after the stream starts.
+        {
+            func(request *BaseTestRequest, stream *EventStream) *APIError {
+                return NewInternalError("-901", &request.APIRequestCourseUserContext, "Test error.");
+            },
+            model.RoleAdmin, "-901", false,
+        },
+        {
+            func(request *BaseTestRequest, stream *EventStream) *APIError {
+                stream.Send("start", nil);
+                return NewInternalError("-902", &request.APIRequestCourseUserContext, "Test error.");
+            },
+            model.RoleAdmin, "-902", true,
+        },
+        {
+            func(request *BaseTestRequest, stream *EventStream) *APIError {
+                stream.Send("start", nil);
+                panic("Forced Panic!");
+            },
+            model.RoleAdmin, "-037", true,
+        },
+    };
+
+    for i, testCase := range testCases {
+        endpoint := fmt.Sprintf("/test/api/stream/error/%d", i);
+        routes = append(routes, NewAPIStreamRoute(endpoint, testCase.handler));
+
+        events, response := SendTestAPIStreamRequest(test, endpoint, nil, nil, testCase.role);
+
+        if (testCase.streamed) {
+            if (len(events) != 2) {
+                test.Errorf("Case %d: Expected 2 events, found %d: '%s'.", i, len(events), util.MustToJSON(events));
+                continue;
+            }
+
+            if (events[1].Name != STREAM_EVENT_ERROR) {
+                test.Errorf("Case %d: Last event is not an error: '%s'.", i, util.MustToJSON(events[1]));
+                continue;
+            }
+
+            response = &APIResponse{};
+            util.MustJSONFromString(events[1].Data, response);
+        } else if (response == nil) {
+            test.Errorf("Case %d: Expected an error response, got events: '%s'.", i, util.MustToJSON(events));
+            continue;
+        }
+
+        if (response.Success) {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        if (response.Locator != testCase.locator) {
+            test.Errorf("Case %d: Unexpected locator. Expected: '%s', Actual: '%s'.", i, testCase.locator, response.Locator);
+        }
+    }
+}
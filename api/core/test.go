@@ -3,6 +3,7 @@ package core
 import (
     "net/http/httptest"
     "os"
+    "strings"
     "testing"
 
     "github.com/edulinq/autograder/common"
@@ -51,6 +52,11 @@ func APITestingMain(suite *testing.M, routes *[]*Route) {
     os.Exit(code);
 }
 
+// Get the base URL of the test server (for tests that need to make requests not covered by the functions here).
+func GetTestServerURL() string {
+    return serverURL;
+}
+
 func SendTestAPIRequest(test *testing.T, endpoint string, fields map[string]any) *APIResponse {
     return SendTestAPIRequestFull(test, endpoint, fields, nil, model.RoleAdmin);
 }
@@ -60,6 +66,38 @@ func SendTestAPIRequest(test *testing.T, endpoint string, fields map[string]any)
 // Provided fields will override base fields.
 // The given role will choose the user (the test course has one user per role).
 func SendTestAPIRequestFull(test *testing.T, endpoint string, fields map[string]any, paths []string, role model.UserRole) *APIResponse {
+    responseText := sendTestAPIRequestText(test, endpoint, fields, paths, role);
+
+    var response APIResponse;
+    err := util.JSONFromString(responseText, &response);
+    if (err != nil) {
+        test.Fatalf("Could not unmarshal JSON response '%s': '%v'.", responseText, err);
+    }
+
+    return &response;
+}
+
+// Send a request to a streaming endpoint and wait for the stream to end.
+// If the request failed before streaming, then the events will be nil and the (error) response will be returned.
+// Otherwise, the events will be returned and the response will be nil.
+func SendTestAPIStreamRequest(test *testing.T, endpoint string, fields map[string]any, paths []string, role model.UserRole) (
+        []*StreamEvent, *APIResponse) {
+    responseText := sendTestAPIRequestText(test, endpoint, fields, paths, role);
+
+    if (strings.HasPrefix(responseText, "{")) {
+        var response APIResponse;
+        err := util.JSONFromString(responseText, &response);
+        if (err != nil) {
+            test.Fatalf("Could not unmarshal JSON response '%s': '%v'.", responseText, err);
+        }
+
+        return nil, &response;
+    }
+
+    return ParseEventStream(responseText), nil;
+}
+
+func sendTestAPIRequestText(test *testing.T, endpoint string, fields map[string]any, paths []string, role model.UserRole) string {
     url := serverURL + endpoint;
 
     email := model.GetRoleString(role) + "@test.com";
@@ -93,11 +131,5 @@ func SendTestAPIRequestFull(test *testing.T, endpoint string, fields map[string]
         test.Fatalf("API POST returned an error: '%v'.", err);
     }
 
-    var response APIResponse;
-    err = util.JSONFromString(responseText, &response);
-    if (err != nil) {
-        test.Fatalf("Could not unmarshal JSON response '%s': '%v'.", responseText, err);
-    }
-
-    return &response;
+    return responseText;
 }
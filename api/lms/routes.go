@@ -9,6 +9,7 @@ import (
 var routes []*core.Route = []*core.Route{
     core.NewAPIRoute(core.NewEndpoint(`lms/user/get`), HandleUserGet),
     core.NewAPIRoute(core.NewEndpoint(`lms/sync`), HandleSync),
+    core.NewAPIRoute(core.NewEndpoint(`lms/sync/groups`), HandleSyncGroups),
     core.NewAPIRoute(core.NewEndpoint(`lms/upload/scores`), HandleUploadScores),
 };
 
@@ -18,6 +18,8 @@ type SyncResponse struct {
     SyncAvailable bool `json:"sync-available"`
     Users *core.SyncUsersInfo `json:"users"`
     Assignments *model.AssignmentSyncResult `json:"assignments"`
+    // Only set when the LMS adapter has sync-groups enabled.
+    Groups *model.GroupSyncResult `json:"groups"`
 }
 
 func HandleSync(request *SyncRequest) (*SyncResponse, *core.APIError) {
@@ -41,6 +43,7 @@ func HandleSync(request *SyncRequest) (*SyncResponse, *core.APIError) {
     response.SyncAvailable = true;
     response.Users = core.NewSyncUsersInfo(result.UserSync);
     response.Assignments = result.AssignmentSync;
+    response.Groups = result.GroupSync;
 
     return &response, nil;
 }
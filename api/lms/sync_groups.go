@@ -0,0 +1,38 @@
+package lms
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/lms/lmssync"
+    "github.com/edulinq/autograder/model"
+)
+
+type SyncGroupsRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleAdmin
+
+    DryRun bool `json:"dry-run"`
+}
+
+type SyncGroupsResponse struct {
+    Groups *model.GroupSyncResult `json:"groups"`
+}
+
+// Import groups from the LMS (regardless of the LMS adapter's sync-groups setting).
+func HandleSyncGroups(request *SyncGroupsRequest) (*SyncGroupsResponse, *core.APIError) {
+    if (request.Course.GetLMSAdapter() == nil) {
+        return nil, core.NewBadRequestError("-407", &request.APIRequest, "Course is not linked to an LMS.").
+                Course(request.Course.GetID());
+    }
+
+    result, err := lmssync.SyncLMSGroups(request.Course, request.DryRun);
+    if (err != nil) {
+        return nil, core.NewInternalError("-408", &request.APIRequestCourseUserContext,
+                "Failed to sync LMS groups.").Err(err);
+    }
+
+    response := SyncGroupsResponse{
+        Groups: result,
+    };
+
+    return &response, nil;
+}
@@ -4,6 +4,7 @@ import (
     "github.com/edulinq/autograder/api/core"
     "github.com/edulinq/autograder/db"
     "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/scoring"
 )
 
 type FetchScoresRequest struct {
@@ -19,7 +20,13 @@ type FetchScoresResponse struct {
 }
 
 func HandleFetchScores(request *FetchScoresRequest) (*FetchScoresResponse, *core.APIError) {
-    submissionInfos, err := db.GetRecentSubmissionSurvey(request.Assignment, request.FilterRole);
+    dueDate, err := scoring.GetScoringDueDate(request.Assignment);
+    if (err != nil) {
+        return nil, core.NewInternalError("-621", &request.APIRequestCourseUserContext, "Failed to get assignment due date.").
+                Err(err).Assignment(request.Assignment.GetID());
+    }
+
+    submissionInfos, err := db.GetScoredSubmissionSurvey(request.Assignment, request.FilterRole, dueDate);
     if (err != nil) {
         return nil, core.NewInternalError("-602", &request.APIRequestCourseUserContext, "Failed to get submission summaries.").
                 Err(err).Assignment(request.Assignment.GetID());
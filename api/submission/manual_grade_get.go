@@ -0,0 +1,64 @@
+package submission
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type ManualGradeGetRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleStudent
+
+    TargetUser core.TargetUserSelfOrGrader `json:"target-email"`
+    // An empty submission gets the manual grade for the most recent submission.
+    TargetSubmission string `json:"target-submission"`
+}
+
+type ManualGradeGetResponse struct {
+    FoundUser bool `json:"found-user"`
+    FoundSubmission bool `json:"found-submission"`
+
+    // Nil if the submission has not been manually graded.
+    ManualGrade *model.ManualGrade `json:"manual-grade"`
+    // The score from the autograder alone.
+    AutograderScore float64 `json:"autograder-score"`
+    // The autograder score plus the manual grade's points.
+    Score float64 `json:"score"`
+}
+
+func HandleManualGradeGet(request *ManualGradeGetRequest) (*ManualGradeGetResponse, *core.APIError) {
+    response := ManualGradeGetResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    submission, err := db.GetSubmissionResult(request.Assignment, request.TargetUser.Email, request.TargetSubmission);
+    if (err != nil) {
+        return nil, core.NewInternalError("-616", &request.APIRequestCourseUserContext, "Failed to get submission.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", request.TargetSubmission);
+    }
+
+    if (submission == nil) {
+        return &response, nil;
+    }
+
+    response.FoundSubmission = true;
+
+    grade, err := db.GetSubmissionManualGrade(request.Assignment, submission);
+    if (err != nil) {
+        return nil, core.NewInternalError("-617", &request.APIRequestCourseUserContext, "Failed to get manual grade.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", submission.ID);
+    }
+
+    response.ManualGrade = grade;
+    response.AutograderScore = submission.Score;
+    response.Score = submission.Score + grade.Points();
+
+    return &response, nil;
+}
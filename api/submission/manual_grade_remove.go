@@ -0,0 +1,55 @@
+package submission
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+)
+
+type ManualGradeRemoveRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleGrader
+
+    TargetUser core.TargetUserSelfOrGrader `json:"target-email"`
+    // An empty submission removes the manual grade for the most recent submission.
+    TargetSubmission string `json:"target-submission"`
+}
+
+type ManualGradeRemoveResponse struct {
+    FoundUser bool `json:"found-user"`
+    FoundSubmission bool `json:"found-submission"`
+    FoundManualGrade bool `json:"found-manual-grade"`
+}
+
+func HandleManualGradeRemove(request *ManualGradeRemoveRequest) (*ManualGradeRemoveResponse, *core.APIError) {
+    response := ManualGradeRemoveResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    submission, err := db.GetSubmissionResult(request.Assignment, request.TargetUser.Email, request.TargetSubmission);
+    if (err != nil) {
+        return nil, core.NewInternalError("-618", &request.APIRequestCourseUserContext, "Failed to get submission.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", request.TargetSubmission);
+    }
+
+    if (submission == nil) {
+        return &response, nil;
+    }
+
+    response.FoundSubmission = true;
+
+    removed, err := db.RemoveManualGrade(request.Assignment, submission.ID);
+    if (err != nil) {
+        return nil, core.NewInternalError("-619", &request.APIRequestCourseUserContext, "Failed to remove manual grade.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", submission.ID);
+    }
+
+    response.FoundManualGrade = removed;
+
+    return &response, nil;
+}
@@ -0,0 +1,83 @@
+package submission
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type ManualGradeSetRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleGrader
+
+    TargetUser core.TargetUserSelfOrGrader `json:"target-email"`
+    // An empty submission grades the most recent submission.
+    TargetSubmission string `json:"target-submission"`
+
+    // Any existing manual grade for the submission is replaced.
+    Items []*model.ManualGradeItem `json:"items"`
+    Comment string `json:"comment"`
+}
+
+type ManualGradeSetResponse struct {
+    FoundUser bool `json:"found-user"`
+    FoundSubmission bool `json:"found-submission"`
+
+    ManualGrade *model.ManualGrade `json:"manual-grade"`
+    // The score from the autograder alone.
+    AutograderScore float64 `json:"autograder-score"`
+    // The autograder score plus the manual grade's points.
+    Score float64 `json:"score"`
+}
+
+func HandleManualGradeSet(request *ManualGradeSetRequest) (*ManualGradeSetResponse, *core.APIError) {
+    response := ManualGradeSetResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    submission, err := db.GetSubmissionResult(request.Assignment, request.TargetUser.Email, request.TargetSubmission);
+    if (err != nil) {
+        return nil, core.NewInternalError("-613", &request.APIRequestCourseUserContext, "Failed to get submission.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", request.TargetSubmission);
+    }
+
+    if (submission == nil) {
+        return &response, nil;
+    }
+
+    response.FoundSubmission = true;
+
+    grade := &model.ManualGrade{
+        SubmissionID: submission.ID,
+        Items: request.Items,
+        Comment: request.Comment,
+        GradedBy: request.User.Email,
+        GradedTime: common.NowTimestamp(),
+    };
+
+    err = grade.Validate();
+    if (err != nil) {
+        return nil, core.NewBadCourseRequestError("-614", &request.APIRequestCourseUserContext, "Invalid manual grade.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", submission.ID);
+    }
+
+    err = db.SaveManualGrade(request.Assignment, grade);
+    if (err != nil) {
+        return nil, core.NewInternalError("-615", &request.APIRequestCourseUserContext, "Failed to save manual grade.").
+                Err(err).Assignment(request.Assignment.GetID()).
+                Add("target-user", request.TargetUser.Email).Add("submission", submission.ID);
+    }
+
+    response.ManualGrade = grade;
+    response.AutograderScore = submission.Score;
+    response.Score = submission.Score + grade.Points();
+
+    return &response, nil;
+}
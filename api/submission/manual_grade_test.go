@@ -0,0 +1,114 @@
+package submission
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestManualGrade(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    items := []map[string]any{
+        map[string]any{"name": "Style", "points": 1.5},
+        map[string]any{"name": "Report", "points": -0.5, "comment": "Missing a conclusion."},
+    };
+
+    testCases := []struct{ role model.UserRole; fields map[string]any; locator string; foundSubmission bool }{
+        {model.RoleStudent, map[string]any{"target-email": "student@test.com", "items": items}, "-020", false},
+        {model.RoleGrader, map[string]any{"target-email": "student@test.com"}, "-614", false},
+        {model.RoleGrader, map[string]any{"target-email": "student@test.com", "items": []map[string]any{map[string]any{"points": 1}}}, "-614", false},
+        {model.RoleGrader, map[string]any{"target-email": "student@test.com", "target-submission": "ZZZ", "items": items}, "", false},
+
+        {model.RoleGrader, map[string]any{"target-email": "student@test.com", "items": items}, "", true},
+    };
+
+    for i, testCase := range testCases {
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/manual-grade/set`), testCase.fields, nil, testCase.role);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Unexpected error locator. Expected: '%s', Actual: '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent ManualGradeSetResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.FoundSubmission != testCase.foundSubmission) {
+            test.Errorf("Case %d: Unexpected found submission. Expected: %v, Actual: %v.", i, testCase.foundSubmission, responseContent.FoundSubmission);
+            continue;
+        }
+    }
+
+    // The student can see the manual grade on their most recent submission (which has an autograder score of 2).
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/manual-grade/get`), nil, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Get response is not a success when it should be: '%v'.", response);
+    }
+
+    var getContent ManualGradeGetResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &getContent);
+
+    if ((getContent.ManualGrade == nil) || (getContent.ManualGrade.GradedBy != "grader@test.com") ||
+            !util.IsClose(getContent.AutograderScore, 2.0) || !util.IsClose(getContent.Score, 3.0)) {
+        test.Fatalf("Unexpected manual grade: '%s'.", util.MustToJSONIndent(getContent));
+    }
+
+    // Scoring uses the autograder score plus the manual grade.
+    scoringInfos, err := db.GetScoringInfos(db.MustGetTestAssignment(), model.RoleStudent, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scoring infos: '%v'.", err);
+    }
+
+    if (!util.IsClose(scoringInfos["student@test.com"].RawScore, 3.0)) {
+        test.Fatalf("Unexpected scoring info: '%s'.", util.MustToJSONIndent(scoringInfos["student@test.com"]));
+    }
+
+    // The raw grading result is unchanged.
+    submission, err := db.GetSubmissionResult(db.MustGetTestAssignment(), "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get submission: '%v'.", err);
+    }
+
+    if (!util.IsClose(submission.Score, 2.0)) {
+        test.Fatalf("Manual grade changed the raw grading result: '%s'.", util.MustToJSONIndent(submission));
+    }
+
+    for i, expected := range []bool{true, false} {
+        response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/manual-grade/remove`),
+                map[string]any{"target-email": "student@test.com"}, nil, model.RoleGrader);
+        if (!response.Success) {
+            test.Fatalf("Remove %d: Response is not a success when it should be: '%v'.", i, response);
+        }
+
+        var removeContent ManualGradeRemoveResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &removeContent);
+
+        if (removeContent.FoundManualGrade != expected) {
+            test.Fatalf("Remove %d: Unexpected found manual grade. Expected: %v, Actual: %v.", i, expected, removeContent.FoundManualGrade);
+        }
+    }
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/manual-grade/get`), nil, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Get response (after removal) is not a success when it should be: '%v'.", response);
+    }
+
+    getContent = ManualGradeGetResponse{};
+    util.MustJSONFromString(util.MustToJSON(response.Content), &getContent);
+
+    if ((getContent.ManualGrade != nil) || !util.IsClose(getContent.Score, 2.0)) {
+        test.Fatalf("Unexpected manual grade after removal: '%s'.", util.MustToJSONIndent(getContent));
+    }
+}
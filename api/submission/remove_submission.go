@@ -3,6 +3,8 @@ package submission
 import (
     "github.com/edulinq/autograder/api/core"
     "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/notification"
 )
 
 type RemoveSubmissionRequest struct {
@@ -27,6 +29,18 @@ func HandleRemoveSubmission(request *RemoveSubmissionRequest) (*RemoveSubmission
 
     response.FoundUser = true;
 
+    // Get the submission before it is removed (for the notice to the user).
+    var info *model.GradingInfo = nil;
+    if (request.Course.GetNotifications().Removals) {
+        var err error;
+        info, err = db.GetSubmissionResult(request.Assignment, request.TargetUser.Email, request.TargetSubmission);
+        if (err != nil) {
+            return nil, core.NewInternalError("-620", &request.APIRequestCourseUserContext, "Failed to get the submission.").
+                    Err(err).Assignment(request.Assignment.GetID()).
+                    Add("target-user", request.TargetUser.Email).Add("submission", request.TargetSubmission);
+        }
+    }
+
     doesExist, err := db.RemoveSubmission(request.Assignment, request.TargetUser.Email, request.TargetSubmission);
     if (err != nil) {
         return nil, core.NewInternalError("-606", &request.APIRequestCourseUserContext, "Failed to remove the submission.").
@@ -36,5 +50,9 @@ func HandleRemoveSubmission(request *RemoveSubmissionRequest) (*RemoveSubmission
 
     response.FoundSubmission = doesExist;
 
+    if (doesExist && (info != nil)) {
+        notification.SendRemovalNotice(request.Assignment, info, request.User.Email);
+    }
+
     return &response, nil;
 }
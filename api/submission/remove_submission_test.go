@@ -1,10 +1,12 @@
 package submission
 
 import (
+    "strings"
     "testing"
 
     "github.com/edulinq/autograder/api/core"
     "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/util"
 )
@@ -89,3 +91,63 @@ func TestRemoveSubmission(test *testing.T) {
         }
     }
 }
+
+func TestRemoveSubmissionNotice(test *testing.T) {
+    defer db.ResetForTesting();
+    defer email.ClearTestMessages();
+
+    testCases := []struct{ role model.UserRole; targetEmail string; targetSubmission string; notify bool; expectedBody string }{
+        {model.RoleGrader, "student@test.com", "1697406265", true, "Your submission '1697406265'"},
+        // The most recent submission.
+        {model.RoleGrader, "student@test.com", "", true, "Your submission '1697406272'"},
+
+        // Missing submission.
+        {model.RoleGrader, "student@test.com", "ZZZ", true, ""},
+
+        // Notifications are off.
+        {model.RoleGrader, "student@test.com", "1697406265", false, ""},
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+        email.ClearTestMessages();
+
+        course := db.MustGetTestCourse();
+        course.Notifications = &model.NotificationOptions{Removals: testCase.notify};
+
+        err := db.SaveCourse(course);
+        if (err != nil) {
+            test.Fatalf("Case %d: Failed to save course: '%v'.", i, err);
+        }
+
+        fields := map[string]any{
+            "target-email": testCase.targetEmail,
+            "target-submission": testCase.targetSubmission,
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/remove`), fields, nil, testCase.role);
+        if (!response.Success) {
+            test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            continue;
+        }
+
+        messages := email.GetTestMessages();
+        if (testCase.expectedBody == "") {
+            if (len(messages) != 0) {
+                test.Errorf("Case %d: Unexpected email sent: '%v'.", i, messages);
+            }
+
+            continue;
+        }
+
+        if (len(messages) != 1) {
+            test.Errorf("Case %d: Unexpected number of emails. Expected: 1, Actual: %d.", i, len(messages));
+            continue;
+        }
+
+        if (!strings.Contains(messages[0].Body, testCase.expectedBody)) {
+            test.Errorf("Case %d: Body does not contain '%s': '%s'.", i, testCase.expectedBody, messages[0].Body);
+            continue;
+        }
+    }
+}
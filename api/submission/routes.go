@@ -13,8 +13,14 @@ var routes []*core.Route = []*core.Route{
     core.NewAPIRoute(core.NewEndpoint(`submission/fetch/scores`), HandleFetchScores),
     core.NewAPIRoute(core.NewEndpoint(`submission/fetch/submission`), HandleFetchSubmission),
     core.NewAPIRoute(core.NewEndpoint(`submission/fetch/submissions`), HandleFetchSubmissions),
+    core.NewAPIRoute(core.NewEndpoint(`submission/manual-grade/get`), HandleManualGradeGet),
+    core.NewAPIRoute(core.NewEndpoint(`submission/manual-grade/remove`), HandleManualGradeRemove),
+    core.NewAPIRoute(core.NewEndpoint(`submission/manual-grade/set`), HandleManualGradeSet),
     core.NewAPIRoute(core.NewEndpoint(`submission/submit`), HandleSubmit),
+    core.NewAPIStreamRoute(core.NewEndpoint(`submission/submit/stream`), HandleSubmitStream),
+    core.NewAPIRoute(core.NewEndpoint(`submission/status`), HandleStatus),
     core.NewAPIRoute(core.NewEndpoint(`submission/remove`), HandleRemoveSubmission),
+    core.NewAPIRoute(core.NewEndpoint(`submission/select`), HandleSelect),
 };
 
 func GetRoutes() *[]*core.Route {
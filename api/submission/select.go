@@ -0,0 +1,52 @@
+package submission
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+type SelectRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleStudent
+
+    // The submission to use for the user's score.
+    // An empty submission clears the user's selection (so the most recent submission is used).
+    TargetSubmission string `json:"target-submission"`
+}
+
+type SelectResponse struct {
+    FoundSubmission bool `json:"found-submission"`
+}
+
+func HandleSelect(request *SelectRequest) (*SelectResponse, *core.APIError) {
+    response := SelectResponse{};
+
+    if (request.Assignment.GetScoringStrategy() != model.StudentSelectedSubmissionStrategy) {
+        return nil, core.NewBadCourseRequestError("-610", &request.APIRequestCourseUserContext,
+                "Assignment does not allow students to select the submission that is scored.").
+                Assignment(request.Assignment.GetID()).Add("scoring-strategy", request.Assignment.GetScoringStrategy());
+    }
+
+    if (request.TargetSubmission != "") {
+        submission, err := db.GetSubmissionResult(request.Assignment, request.User.Email, request.TargetSubmission);
+        if (err != nil) {
+            return nil, core.NewInternalError("-611", &request.APIRequestCourseUserContext, "Failed to get submission.").
+                    Err(err).Assignment(request.Assignment.GetID()).Add("submission", request.TargetSubmission);
+        }
+
+        if (submission == nil) {
+            return &response, nil;
+        }
+    }
+
+    response.FoundSubmission = true;
+
+    err := db.SaveSelectedSubmission(request.Assignment, request.User.Email, request.TargetSubmission);
+    if (err != nil) {
+        return nil, core.NewInternalError("-612", &request.APIRequestCourseUserContext, "Failed to save selected submission.").
+                Err(err).Assignment(request.Assignment.GetID()).Add("submission", request.TargetSubmission);
+    }
+
+    return &response, nil;
+}
@@ -0,0 +1,92 @@
+package submission
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestSelect(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    // The test assignment does not use the student-selected scoring strategy.
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/select`),
+            map[string]any{"target-submission": "1697406256"}, nil, model.RoleStudent);
+    if (response.Success || (response.Locator != "-610")) {
+        test.Fatalf("Unexpected response without a student-selected scoring strategy: '%v'.", response);
+    }
+
+    course := db.MustGetTestCourse();
+    course.Assignments["hw0"].ScoringStrategy = model.StudentSelectedSubmissionStrategy;
+
+    err := db.SaveCourse(course);
+    if (err != nil) {
+        test.Fatalf("Failed to save course: '%v'.", err);
+    }
+
+    testCases := []struct{ role model.UserRole; targetSubmission string; permError bool; foundSubmission bool; expectedID string }{
+        {model.RoleStudent, "1697406256", false, true, "course101::hw0::student@test.com::1697406256"},
+        {model.RoleStudent, "course101::hw0::student@test.com::1697406265", false, true, "course101::hw0::student@test.com::1697406265"},
+
+        // Missing submissions do not change the selection.
+        {model.RoleStudent, "ZZZ", false, false, "course101::hw0::student@test.com::1697406265"},
+
+        // Clear the selection.
+        {model.RoleStudent, "", false, true, "course101::hw0::student@test.com::1697406272"},
+
+        {model.RoleOther, "1697406256", true, false, "course101::hw0::student@test.com::1697406272"},
+    };
+
+    for i, testCase := range testCases {
+        fields := map[string]any{
+            "target-submission": testCase.targetSubmission,
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/select`), fields, nil, testCase.role);
+        if (!response.Success) {
+            if (!testCase.permError) {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            } else if (response.Locator != "-020") {
+                test.Errorf("Case %d: Unexpected error locator: '%s'.", i, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.permError) {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent SelectResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.FoundSubmission != testCase.foundSubmission) {
+            test.Errorf("Case %d: Unexpected found submission. Expected: %v, Actual: %v.", i,
+                    testCase.foundSubmission, responseContent.FoundSubmission);
+            continue;
+        }
+
+        // Scores should use the selected submission.
+        response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/fetch/scores`),
+                map[string]any{"filter-role": "student"}, nil, model.RoleGrader);
+        if (!response.Success) {
+            test.Errorf("Case %d: Failed to fetch scores: '%v'.", i, response);
+            continue;
+        }
+
+        var scoresContent FetchScoresResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &scoresContent);
+
+        info := scoresContent.SubmissionInfos["student@test.com"];
+        if ((info == nil) || (info.ID != testCase.expectedID)) {
+            test.Errorf("Case %d: Unexpected scored submission. Expected: '%s', Actual: '%s'.", i,
+                    testCase.expectedID, util.MustToJSONIndent(info));
+            continue;
+        }
+    }
+}
@@ -0,0 +1,60 @@
+package submission
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/grader"
+    "github.com/edulinq/autograder/model"
+)
+
+type StatusRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleStudent
+
+    JobID core.NonEmptyString `json:"job-id"`
+}
+
+type StatusResponse struct {
+    FoundJob bool `json:"found-job"`
+    Status grader.JobStatus `json:"status"`
+    QueuePosition int `json:"queue-position"`
+
+    Rejected bool `json:"rejected"`
+    Message string `json:"message"`
+
+    GradingSucess bool `json:"grading-success"`
+    GradingInfo *model.GradingInfo `json:"result"`
+}
+
+func HandleStatus(request *StatusRequest) (*StatusResponse, *core.APIError) {
+    response := StatusResponse{};
+
+    info := grader.GetJobInfo(string(request.JobID));
+    if (info == nil) {
+        return &response, nil;
+    }
+
+    if ((info.CourseID != request.Course.GetID()) || (info.AssignmentID != request.Assignment.GetID())) {
+        return &response, nil;
+    }
+
+    // Students can only see their own jobs.
+    if ((info.User != request.User.Email) && (request.User.Role < model.RoleGrader)) {
+        return &response, nil;
+    }
+
+    response.FoundJob = true;
+    response.Status = info.Status;
+
+    switch info.Status {
+        case grader.JOB_STATUS_QUEUED:
+            response.QueuePosition = info.QueuePosition;
+        case grader.JOB_STATUS_REJECTED:
+            response.Rejected = true;
+            response.Message = info.Reject.String();
+        case grader.JOB_STATUS_COMPLETE:
+            response.GradingSucess = true;
+            response.GradingInfo = info.Result.Info;
+    }
+
+    return &response, nil;
+}
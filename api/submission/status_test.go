@@ -0,0 +1,102 @@
+package submission
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/grader"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestStatusAsyncSubmit(test *testing.T) {
+    assignment := db.MustGetTestAssignment();
+    paths := []string{filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)};
+
+    fields := map[string]any{
+        "async": true,
+    };
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/submit`), fields, paths, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Response is not a success when it should be: '%v'.", response);
+    }
+
+    var submitContent SubmitResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &submitContent);
+
+    if (submitContent.JobID == "") {
+        test.Fatalf("Async submit did not return a job ID: '%v'.", submitContent);
+    }
+
+    if (submitContent.GradingSucess || (submitContent.GradingInfo != nil)) {
+        test.Fatalf("Async submit returned a result: '%v'.", submitContent);
+    }
+
+    // Poll until the job is finished.
+    var statusContent StatusResponse;
+    for i := 0; i < 600; i++ {
+        statusContent = fetchStatus(test, submitContent.JobID, model.RoleStudent);
+        if (!statusContent.FoundJob) {
+            test.Fatalf("Could not find job '%s'.", submitContent.JobID);
+        }
+
+        if ((statusContent.Status != grader.JOB_STATUS_QUEUED) && (statusContent.Status != grader.JOB_STATUS_RUNNING)) {
+            break;
+        }
+
+        time.Sleep(100 * time.Millisecond);
+    }
+
+    if (statusContent.Status != grader.JOB_STATUS_COMPLETE) {
+        test.Fatalf("Job did not complete. Status: '%s'.", statusContent.Status);
+    }
+
+    if (!statusContent.GradingSucess || (statusContent.GradingInfo == nil)) {
+        test.Fatalf("Completed job is not a grading success: '%v'.", statusContent);
+    }
+
+    submission, err := db.GetSubmissionResult(assignment, "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get submission: '%v'.", err);
+    }
+
+    if (!statusContent.GradingInfo.Equals(*submission, true)) {
+        test.Fatalf("Job result does not match database value. Job: '%v', Database: '%v'.", statusContent.GradingInfo, submission);
+    }
+
+    // Other students cannot see the job, but graders can.
+    if (fetchStatus(test, submitContent.JobID, model.RoleOther).FoundJob) {
+        test.Fatalf("Another student can see the job.");
+    }
+
+    if (!fetchStatus(test, submitContent.JobID, model.RoleGrader).FoundJob) {
+        test.Fatalf("A grader cannot see the job.");
+    }
+}
+
+func TestStatusMissingJob(test *testing.T) {
+    statusContent := fetchStatus(test, "ZZZ", model.RoleStudent);
+    if (statusContent.FoundJob) {
+        test.Fatalf("Found a job that does not exist: '%v'.", statusContent);
+    }
+}
+
+func fetchStatus(test *testing.T, jobID string, role model.UserRole) StatusResponse {
+    fields := map[string]any{
+        "job-id": jobID,
+    };
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`submission/status`), fields, nil, role);
+    if (!response.Success) {
+        test.Fatalf("Status response is not a success when it should be: '%v'.", response);
+    }
+
+    var responseContent StatusResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+    return responseContent;
+}
@@ -13,6 +13,10 @@ type SubmitRequest struct {
     Files core.POSTFiles
 
     Message string `json:"message"`
+
+    // Return as soon as the submission is queued (instead of waiting for grading).
+    // The result can then be fetched with submission/status.
+    Async bool `json:"async"`
 }
 
 type SubmitResponse struct {
@@ -21,12 +25,40 @@ type SubmitResponse struct {
 
     GradingSucess bool `json:"grading-success"`
     GradingInfo *model.GradingInfo `json:"result"`
+
+    // Only set for async requests.
+    JobID string `json:"job-id,omitempty"`
+    QueuePosition int `json:"queue-position,omitempty"`
 }
 
 func HandleSubmit(request *SubmitRequest) (*SubmitResponse, *core.APIError) {
     response := SubmitResponse{};
 
-    result, reject, err := grader.GradeDefault(request.Assignment, request.Files.TempDir, request.User.Email, request.Message);
+    job, err := grader.EnqueueGradeDefault(request.Assignment, request.Files.TempDir, request.User.Email, request.Message);
+    if (err != nil) {
+        return nil, core.NewInternalError("-608", &request.APIRequestCourseUserContext, "Failed to queue submission for grading.").
+                Err(err).Assignment(request.Assignment.GetID());
+    }
+
+    if (request.Async) {
+        response.JobID = job.ID;
+
+        info := grader.GetJobInfo(job.ID);
+        if (info != nil) {
+            response.QueuePosition = info.QueuePosition;
+        }
+
+        return &response, nil;
+    }
+
+    result, reject, err := job.Wait();
+    fillSubmitResponse(&response, request, result, reject, err);
+
+    return &response, nil;
+}
+
+// Fill out a response with the results of a grading.
+func fillSubmitResponse(response *SubmitResponse, request *SubmitRequest, result *model.GradingResult, reject grader.RejectReason, err error) {
     if (err != nil) {
         stdout := "";
         stderr := "";
@@ -38,7 +70,7 @@ func HandleSubmit(request *SubmitRequest) (*SubmitResponse, *core.APIError) {
 
         log.Info("Submission grading failed.", err, request.Assignment, log.NewAttr("stdout", stdout), log.NewAttr("stderr", stderr), request.User);
 
-        return &response, nil;
+        return;
     }
 
     if (reject != nil) {
@@ -46,11 +78,9 @@ func HandleSubmit(request *SubmitRequest) (*SubmitResponse, *core.APIError) {
 
         response.Rejected = true;
         response.Message = reject.String();
-        return &response, nil;
+        return;
     }
 
     response.GradingSucess = true;
     response.GradingInfo = result.Info;
-
-    return &response, nil;
 }
@@ -0,0 +1,77 @@
+package submission
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/grader"
+    "github.com/edulinq/autograder/log"
+)
+
+const (
+    // Sent first, holds the job ID.
+    STREAM_EVENT_JOB = "job"
+    // Holds a grader.ProgressEvent.
+    STREAM_EVENT_PROGRESS = "progress"
+    // Sent last, holds a SubmitResponse.
+    STREAM_EVENT_RESULT = "result"
+)
+
+type SubmitStreamJobEvent struct {
+    JobID string `json:"job-id"`
+    QueuePosition int `json:"queue-position"`
+}
+
+// Submit (just like submission/submit), but stream the grading progress back as Server-Sent Events.
+// The "async" field is ignored.
+// Events (in order):
+//  - STREAM_EVENT_JOB -- once the submission is queued.
+//  - STREAM_EVENT_PROGRESS -- any number of grading phase changes and lines of grader output.
+//  - STREAM_EVENT_RESULT -- the final result.
+// If the client disconnects, grading continues and the result can still be fetched with submission/status.
+func HandleSubmitStream(request *SubmitRequest, stream *core.EventStream) *core.APIError {
+    job, err := grader.EnqueueGradeDefault(request.Assignment, request.Files.TempDir, request.User.Email, request.Message);
+    if (err != nil) {
+        return core.NewInternalError("-609", &request.APIRequestCourseUserContext, "Failed to queue submission for grading.").
+                Err(err).Assignment(request.Assignment.GetID());
+    }
+
+    jobEvent := SubmitStreamJobEvent{JobID: job.ID};
+    info := grader.GetJobInfo(job.ID);
+    if (info != nil) {
+        jobEvent.QueuePosition = info.QueuePosition;
+    }
+
+    err = stream.Send(STREAM_EVENT_JOB, jobEvent);
+    if (err != nil) {
+        log.Debug("Client stopped listening to submission stream.", err, request.Assignment, request.User, log.NewAttr("job-id", job.ID));
+        return nil;
+    }
+
+    index := 0;
+    for {
+        events := job.WaitEvents(index, stream.Done());
+        if (events == nil) {
+            log.Debug("Client disconnected from submission stream.", request.Assignment, request.User, log.NewAttr("job-id", job.ID));
+            return nil;
+        }
+
+        for _, event := range events {
+            index++;
+
+            if (event.Type == grader.PROGRESS_EVENT_DONE) {
+                response := SubmitResponse{JobID: job.ID};
+
+                result, reject, err := job.Wait();
+                fillSubmitResponse(&response, request, result, reject, err);
+
+                stream.Send(STREAM_EVENT_RESULT, response);
+                return nil;
+            }
+
+            err = stream.Send(STREAM_EVENT_PROGRESS, event);
+            if (err != nil) {
+                log.Debug("Client stopped listening to submission stream.", err, request.Assignment, request.User, log.NewAttr("job-id", job.ID));
+                return nil;
+            }
+        }
+    }
+}
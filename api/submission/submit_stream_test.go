@@ -0,0 +1,88 @@
+package submission
+
+import (
+    "path/filepath"
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/grader"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestSubmitStream(test *testing.T) {
+    // Leave the course in a good state after the test.
+    defer db.ResetForTesting();
+
+    assignment := db.MustGetTestAssignment();
+    paths := []string{filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)};
+
+    events, response := core.SendTestAPIStreamRequest(test, core.NewEndpoint(`submission/submit/stream`), nil, paths, model.RoleStudent);
+    if (response != nil) {
+        test.Fatalf("Got a response instead of a stream: '%v'.", response);
+    }
+
+    if (len(events) < 2) {
+        test.Fatalf("Too few events: '%s'.", util.MustToJSON(events));
+    }
+
+    if (events[0].Name != STREAM_EVENT_JOB) {
+        test.Fatalf("First event is not a job event: '%s'.", util.MustToJSON(events[0]));
+    }
+
+    var jobEvent SubmitStreamJobEvent;
+    util.MustJSONFromString(events[0].Data, &jobEvent);
+    if (jobEvent.JobID == "") {
+        test.Fatalf("Job event has no job ID: '%s'.", events[0].Data);
+    }
+
+    // Collect the phases (in order).
+    phases := make([]grader.GradingPhase, 0);
+    for _, event := range events[1:(len(events) - 1)] {
+        if (event.Name != STREAM_EVENT_PROGRESS) {
+            test.Fatalf("Unexpected event: '%s'.", util.MustToJSON(event));
+        }
+
+        var progress grader.ProgressEvent;
+        util.MustJSONFromString(event.Data, &progress);
+
+        if (progress.Type == grader.PROGRESS_EVENT_PHASE) {
+            phases = append(phases, progress.Phase);
+        }
+    }
+
+    if ((len(phases) < 2) || (phases[0] != grader.PHASE_QUEUED) || (phases[len(phases) - 1] != grader.PHASE_SAVED)) {
+        test.Fatalf("Unexpected phases: '%v'.", phases);
+    }
+
+    lastEvent := events[len(events) - 1];
+    if (lastEvent.Name != STREAM_EVENT_RESULT) {
+        test.Fatalf("Last event is not a result: '%s'.", util.MustToJSON(lastEvent));
+    }
+
+    var result SubmitResponse;
+    util.MustJSONFromString(lastEvent.Data, &result);
+
+    if (!result.GradingSucess || (result.GradingInfo == nil)) {
+        test.Fatalf("Submission was not graded successfully: '%s'.", lastEvent.Data);
+    }
+
+    if (result.JobID != jobEvent.JobID) {
+        test.Fatalf("Result has the wrong job ID. Expected: '%s', Actual: '%s'.", jobEvent.JobID, result.JobID);
+    }
+}
+
+func TestSubmitStreamBadPermissions(test *testing.T) {
+    assignment := db.MustGetTestAssignment();
+    paths := []string{filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH)};
+
+    events, response := core.SendTestAPIStreamRequest(test, core.NewEndpoint(`submission/submit/stream`), nil, paths, model.RoleOther);
+    if (response == nil) {
+        test.Fatalf("Got a stream instead of an error response: '%s'.", util.MustToJSON(events));
+    }
+
+    if (response.Success || (response.Locator != "-020")) {
+        test.Fatalf("Unexpected response: '%v'.", response);
+    }
+}
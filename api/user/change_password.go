@@ -19,6 +19,13 @@ type ChangePasswordResponse struct {
 }
 
 func HandleChangePassword(request *ChangePasswordRequest) (*ChangePasswordResponse, *core.APIError) {
+    // Only passwords can be used to change passwords,
+    // otherwise a stolen token could be used to take over an account.
+    if (request.Token != nil) {
+        return nil, core.NewBadCourseRequestError("-874", &request.APIRequestCourseUserContext,
+                "A password (not a token) is required to change a password.");
+    }
+
     response := ChangePasswordResponse{};
 
     if (!request.TargetUser.Found) {
@@ -53,7 +60,7 @@ func HandleChangePassword(request *ChangePasswordRequest) (*ChangePasswordRespon
     }
 
     if (pass != "") {
-        err = model.SendUserAddEmail(request.Course, request.TargetUser.User, pass, true, true, false, false);
+        err = model.SendUserAddEmail(request.Course, request.TargetUser.User, pass, true, true, false);
         if (err != nil) {
             return nil, core.NewInternalError("-808", &request.APIRequestCourseUserContext,
                     "Failed to send user email.").Err(err).Add("target-user", request.TargetUser.Email);
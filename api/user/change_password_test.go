@@ -102,5 +102,59 @@ func TestChangePassword(test *testing.T) {
                     testCase.hasEmail, hasEmail);
             continue;
         }
+
+        // Emails with a generated password must never be saved in the outbox.
+        if (hasEmail && !email.GetTestMessages()[0].Sensitive) {
+            test.Errorf("Case %d: Password email is not marked as sensitive.", i);
+            continue;
+        }
+    }
+}
+
+func TestChangePasswordToken(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/login`), nil, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Login failed: '%v'.", response);
+    }
+
+    var loginContent LoginResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &loginContent);
+
+    // A token cannot be used to change a password.
+    fields := map[string]any{
+        "user-pass": "",
+        "user-token": loginContent.Token,
+        "new-pass": "new-pass",
+    };
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/change/pass`), fields, nil, model.RoleStudent);
+    if (response.Success) {
+        test.Fatalf("Password change with a token did not fail.");
+    }
+
+    if (response.Locator != "-874") {
+        test.Fatalf("Unexpected locator. Expected: '-874', Actual: '%s'.", response.Locator);
+    }
+
+    // A password change revokes all tokens.
+    fields = map[string]any{
+        "new-pass": "new-pass",
+    };
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/change/pass`), fields, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Password change failed: '%v'.", response);
+    }
+
+    user, err := db.GetUser(db.MustGetTestCourse(), "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user: '%v'.", err);
+    }
+
+    if (len(user.Tokens) != 0) {
+        test.Fatalf("Tokens were not revoked after a password change: '%v'.", user.Tokens);
     }
 }
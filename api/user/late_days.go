@@ -0,0 +1,57 @@
+package user
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/scoring"
+)
+
+type LateDaysRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleStudent
+
+    TargetUser core.TargetUserSelfOrGrader `json:"target-email"`
+}
+
+type LateDaysResponse struct {
+    FoundUser bool `json:"found-user"`
+    // Nil if the course does not use late days (or the user's late days are not known yet).
+    LateDays *model.LateDaysBalance `json:"late-days"`
+    History []*model.LateDaysEvent `json:"history"`
+}
+
+func HandleLateDays(request *LateDaysRequest) (*LateDaysResponse, *core.APIError) {
+    response := LateDaysResponse{
+        History: make([]*model.LateDaysEvent, 0),
+    };
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    balance, event, err := scoring.GetUserLateDays(request.Course, request.TargetUser.Email);
+    if (err != nil) {
+        return nil, core.NewInternalError("-834", &request.APIRequestCourseUserContext,
+                "Failed to get late days.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    response.LateDays = balance;
+
+    history, err := db.GetLateDaysHistory(request.Course, request.TargetUser.Email);
+    if (err != nil) {
+        return nil, core.NewInternalError("-835", &request.APIRequestCourseUserContext,
+                "Failed to get late days history.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    response.History = history;
+
+    // Show the event that will create the user's balance.
+    if (event != nil) {
+        response.History = append(response.History, event);
+    }
+
+    return &response, nil;
+}
@@ -0,0 +1,100 @@
+package user
+
+import (
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/scoring"
+)
+
+type LateDaysSelectRequest struct {
+    core.APIRequestAssignmentContext
+    core.MinRoleStudent
+
+    // The number of late days to spend on this assignment.
+    Days int `json:"days"`
+}
+
+type LateDaysSelectResponse struct {
+    LateDays *model.LateDaysBalance `json:"late-days"`
+    // The time after which this selection can no longer be changed.
+    LockTime common.Timestamp `json:"lock-time"`
+}
+
+func HandleLateDaysSelect(request *LateDaysSelectRequest) (*LateDaysSelectResponse, *core.APIError) {
+    policy := request.Assignment.GetLatePolicy();
+    if ((policy.Type != model.LateDays) || !policy.StudentSelectedLateDays) {
+        return nil, core.NewBadCourseRequestError("-836", &request.APIRequestCourseUserContext,
+                "Assignment does not allow students to select late days.").Assignment(request.Assignment.GetID());
+    }
+
+    if ((request.Days < 0) || (request.Days > policy.MaxLateDays)) {
+        return nil, core.NewBadCourseRequestError("-837", &request.APIRequestCourseUserContext,
+                "Number of late days must be between zero and the assignment's max late days.").
+                Assignment(request.Assignment.GetID()).Add("days", request.Days).Add("max-late-days", policy.MaxLateDays);
+    }
+
+    lockTime, err := scoring.GetLateDaysSelectionLockTime(request.Assignment, request.User.Email);
+    if (err != nil) {
+        return nil, core.NewInternalError("-838", &request.APIRequestCourseUserContext,
+                "Failed to get late days lock time.").Err(err).Assignment(request.Assignment.GetID());
+    }
+
+    if (!time.Now().Before(lockTime)) {
+        return nil, core.NewBadCourseRequestError("-839", &request.APIRequestCourseUserContext,
+                "Late days for this assignment are locked and can no longer be changed.").
+                Assignment(request.Assignment.GetID()).Add("lock-time", common.TimestampFromTime(lockTime));
+    }
+
+    lock := scoring.GetLateDaysLock(request.Course);
+    lock.Lock();
+    defer lock.Unlock();
+
+    balance, initialEvent, err := scoring.GetUserLateDays(request.Course, request.User.Email);
+    if (err != nil) {
+        return nil, core.NewInternalError("-840", &request.APIRequestCourseUserContext,
+                "Failed to get late days.").Err(err).Assignment(request.Assignment.GetID());
+    }
+
+    if (balance == nil) {
+        return nil, core.NewBadCourseRequestError("-841", &request.APIRequestCourseUserContext,
+                "User does not have late days (late days have not been imported from the LMS yet).").
+                Assignment(request.Assignment.GetID());
+    }
+
+    // Days already allocated to this assignment can be reused.
+    availableDays := balance.AvailableDays + balance.AllocatedDays[request.Assignment.GetID()];
+    if (request.Days > availableDays) {
+        return nil, core.NewBadCourseRequestError("-842", &request.APIRequestCourseUserContext,
+                "Not enough late days available.").
+                Assignment(request.Assignment.GetID()).Add("days", request.Days).Add("available-days", availableDays);
+    }
+
+    events := make([]*model.LateDaysEvent, 0, 2);
+    if (initialEvent != nil) {
+        events = append(events, initialEvent);
+    }
+
+    event := balance.Select(request.Assignment.GetID(), request.Days, request.User.Email);
+    if (event != nil) {
+        events = append(events, event);
+    }
+
+    if (len(events) > 0) {
+        err = db.SaveLateDays(request.Course, map[string]*model.LateDaysBalance{balance.User: balance}, events);
+        if (err != nil) {
+            return nil, core.NewInternalError("-843", &request.APIRequestCourseUserContext,
+                    "Failed to save late days.").Err(err).Assignment(request.Assignment.GetID());
+        }
+    }
+
+    response := LateDaysSelectResponse{
+        LateDays: balance,
+        LockTime: common.TimestampFromTime(lockTime),
+    };
+
+    return &response, nil;
+}
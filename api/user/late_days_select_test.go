@@ -0,0 +1,123 @@
+package user
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestLateDaysSelect(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    // Without a student-selected late days policy.
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/late-days/select`), map[string]any{"days": 1}, nil, model.RoleStudent);
+    if (response.Success || (response.Locator != "-836")) {
+        test.Fatalf("Unexpected response without a late days policy: '%v'.", response);
+    }
+
+    setSelectedLateDaysPolicy(test, time.Now().Add(-time.Hour));
+
+    testCases := []struct{ role model.UserRole; days int; locator string; expectedSelected int }{
+        {model.RoleStudent, 1, "", 1},
+        {model.RoleStudent, 2, "", 2},
+        {model.RoleStudent, 0, "", 0},
+        {model.RoleGrader, 1, "", 1},
+
+        {model.RoleStudent, 3, "-837", 0},
+        {model.RoleStudent, -1, "-837", 0},
+        {model.RoleOther, 1, "-020", 0},
+    };
+
+    for i, testCase := range testCases {
+        fields := map[string]any{
+            "days": testCase.days,
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/late-days/select`), fields, nil, testCase.role);
+        if (!response.Success) {
+            if (testCase.locator != response.Locator) {
+                test.Errorf("Case %d: Unexpected error locator. Expected: '%s', Actual: '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent LateDaysSelectResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.LateDays.AvailableDays != 3) {
+            test.Errorf("Case %d: Selecting late days should not change the available days: '%s'.", i,
+                    util.MustToJSONIndent(responseContent.LateDays));
+            continue;
+        }
+
+        if (responseContent.LateDays.SelectedDays["hw0"] != testCase.expectedSelected) {
+            test.Errorf("Case %d: Unexpected selected days. Expected: %d, Actual: %d.", i,
+                    testCase.expectedSelected, responseContent.LateDays.SelectedDays["hw0"]);
+            continue;
+        }
+    }
+
+    // The initial balance and a selection for each change (the student's first three cases).
+    history, err := db.GetLateDaysHistory(db.MustGetTestCourse(), "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get late days history: '%v'.", err);
+    }
+
+    if (len(history) != 4) {
+        test.Fatalf("Unexpected history: '%s'.", util.MustToJSONIndent(history));
+    }
+
+    // Not enough late days.
+    course := db.MustGetTestCourse();
+    balance := model.NewLateDaysBalance("student@test.com", 1);
+    err = db.SaveLateDays(course, map[string]*model.LateDaysBalance{balance.User: balance}, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to save late days: '%v'.", err);
+    }
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/late-days/select`), map[string]any{"days": 2}, nil, model.RoleStudent);
+    if (response.Success || (response.Locator != "-842")) {
+        test.Fatalf("Unexpected response without enough late days: '%v'.", response);
+    }
+
+    // The reject window (two days) has closed.
+    setSelectedLateDaysPolicy(test, time.Now().Add(-3 * model.DAY));
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/late-days/select`), map[string]any{"days": 1}, nil, model.RoleStudent);
+    if (response.Success || (response.Locator != "-839")) {
+        test.Fatalf("Unexpected response after the reject window closed: '%v'.", response);
+    }
+}
+
+func setSelectedLateDaysPolicy(test *testing.T, dueDate time.Time) {
+    course := db.MustGetTestCourse();
+    assignment := course.Assignments["hw0"];
+
+    assignment.DueDate = common.TimestampFromTime(dueDate);
+    assignment.MaxPoints = 10.0;
+    assignment.LatePolicy = &model.LateGradingPolicy{
+        Type: model.LateDays,
+        Penalty: 0.1,
+        RejectAfterDays: 2,
+        MaxLateDays: 2,
+        TotalLateDays: 3,
+        StudentSelectedLateDays: true,
+    };
+
+    err := db.SaveCourse(course);
+    if (err != nil) {
+        test.Fatalf("Failed to save course: '%v'.", err);
+    }
+}
@@ -0,0 +1,82 @@
+package user
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestLateDays(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+
+    balance := model.NewLateDaysBalance("student@test.com", 0);
+    events := []*model.LateDaysEvent{
+        balance.Adjust(3, model.LATE_DAYS_SOURCE_INITIAL, "", ""),
+        balance.Allocate("hw0", 2, model.LATE_DAYS_SOURCE_SCORING, ""),
+    };
+
+    err := db.SaveLateDays(course, map[string]*model.LateDaysBalance{balance.User: balance}, events);
+    if (err != nil) {
+        test.Fatalf("Failed to save late days: '%v'.", err);
+    }
+
+    testCases := []struct{ role model.UserRole; target string; permError bool; foundUser bool; expected *model.LateDaysBalance; numEvents int }{
+        {model.RoleStudent, "", false, true, balance, 2},
+        {model.RoleStudent, "student@test.com", false, true, balance, 2},
+        {model.RoleGrader, "student@test.com", false, true, balance, 2},
+
+        // No balance and no late days policy.
+        {model.RoleGrader, "", false, true, nil, 0},
+        {model.RoleGrader, "zzz@test.com", false, false, nil, 0},
+
+        {model.RoleStudent, "grader@test.com", true, false, nil, 0},
+        {model.RoleOther, "", true, false, nil, 0},
+    };
+
+    for i, testCase := range testCases {
+        fields := map[string]any{
+            "target-email": testCase.target,
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/late-days`), fields, nil, testCase.role);
+        if (!response.Success) {
+            if (!testCase.permError) {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            } else if ((response.Locator != "-020") && (response.Locator != "-033")) {
+                test.Errorf("Case %d: Unexpected error locator: '%s'.", i, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.permError) {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        var responseContent LateDaysResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.FoundUser != testCase.foundUser) {
+            test.Errorf("Case %d: Unexpected found user. Expected: %v, Actual: %v.", i, testCase.foundUser, responseContent.FoundUser);
+            continue;
+        }
+
+        if (util.MustToJSON(testCase.expected) != util.MustToJSON(responseContent.LateDays)) {
+            test.Errorf("Case %d: Unexpected late days. Expected: '%s', Actual: '%s'.", i,
+                    util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(responseContent.LateDays));
+            continue;
+        }
+
+        if (len(responseContent.History) != testCase.numEvents) {
+            test.Errorf("Case %d: Unexpected number of events. Expected: %d, Actual: %d.", i, testCase.numEvents, len(responseContent.History));
+            continue;
+        }
+    }
+}
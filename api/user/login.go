@@ -0,0 +1,70 @@
+package user
+
+import (
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+)
+
+type LoginRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleOther
+
+    // A name to help identify the token (e.g. "ci").
+    Name string `json:"name"`
+    // The lifetime of the token (in hours), zero means the server default.
+    TTLHours int `json:"ttl-hours"`
+    // Create a token that never expires (e.g. for automation users).
+    NoExpiration bool `json:"no-expiration"`
+}
+
+type LoginResponse struct {
+    // The cleartext token, this is the only time it will be available.
+    Token string `json:"token"`
+    TokenInfo *TokenInfo `json:"token-info"`
+}
+
+func HandleLogin(request *LoginRequest) (*LoginResponse, *core.APIError) {
+    // Only passwords can be used to get new tokens,
+    // otherwise a token could be used to extend its own lifetime forever.
+    if (request.Token != nil) {
+        return nil, core.NewBadCourseRequestError("-809", &request.APIRequestCourseUserContext,
+                "A password (not a token) is required to log in.");
+    }
+
+    if (request.TTLHours < 0) {
+        return nil, core.NewBadCourseRequestError("-810", &request.APIRequestCourseUserContext,
+                "Token lifetime cannot be negative.").Add("ttl-hours", request.TTLHours);
+    }
+
+    lifetime := time.Duration(0);
+    if (!request.NoExpiration) {
+        hours := request.TTLHours;
+        if (hours == 0) {
+            hours = config.WEB_TOKEN_TTL_HOURS.Get();
+        }
+
+        lifetime = time.Duration(hours) * time.Hour;
+    }
+
+    token, secret, err := request.User.NewToken(request.Name, lifetime);
+    if (err != nil) {
+        return nil, core.NewInternalError("-811", &request.APIRequestCourseUserContext,
+                "Failed to create token.").Err(err);
+    }
+
+    err = db.SaveUser(request.Course, request.User);
+    if (err != nil) {
+        return nil, core.NewInternalError("-812", &request.APIRequestCourseUserContext,
+                "Failed to save user.").Err(err);
+    }
+
+    response := LoginResponse{
+        Token: secret,
+        TokenInfo: NewTokenInfo(token),
+    };
+
+    return &response, nil;
+}
@@ -0,0 +1,218 @@
+package user
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestLoginTokenLifecycle(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/login`), map[string]any{"name": "ci"}, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Login failed: '%v'.", response);
+    }
+
+    var loginContent LoginResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &loginContent);
+
+    if (loginContent.Token == "") {
+        test.Fatalf("Got an empty token.");
+    }
+
+    if ((loginContent.TokenInfo == nil) || (loginContent.TokenInfo.Name != "ci") || (loginContent.TokenInfo.ExpirationTime.IsZero())) {
+        test.Fatalf("Unexpected token info: '%+v'.", loginContent.TokenInfo);
+    }
+
+    tokenFields := map[string]any{
+        "user-pass": "",
+        "user-token": loginContent.Token,
+    };
+
+    // The token can be used instead of a password.
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/list`), tokenFields, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Token list with a token failed: '%v'.", response);
+    }
+
+    var listContent TokenListResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &listContent);
+
+    if (len(listContent.Tokens) != 1) {
+        test.Fatalf("Unexpected number of tokens. Expected: 1, Actual: %d.", len(listContent.Tokens));
+    }
+
+    if (listContent.Tokens[0].ID != loginContent.TokenInfo.ID) {
+        test.Fatalf("Unexpected token ID. Expected: '%s', Actual: '%s'.", loginContent.TokenInfo.ID, listContent.Tokens[0].ID);
+    }
+
+    // Tokens cannot be used to get new tokens.
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/login`), tokenFields, nil, model.RoleStudent);
+    if (response.Success) {
+        test.Fatalf("Login with a token did not fail.");
+    }
+
+    if (response.Locator != "-809") {
+        test.Fatalf("Unexpected locator. Expected: '-809', Actual: '%s'.", response.Locator);
+    }
+
+    // Tokens are tied to a user.
+    otherFields := map[string]any{
+        "user-email": "grader@test.com",
+        "user-pass": "",
+        "user-token": loginContent.Token,
+    };
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/list`), otherFields, nil, model.RoleStudent);
+    if (response.Success) {
+        test.Fatalf("Token for another user did not fail.");
+    }
+
+    if (response.HTTPStatus != core.HTTP_STATUS_AUTH_ERROR) {
+        test.Fatalf("Unexpected status. Expected: %d, Actual: %d.", core.HTTP_STATUS_AUTH_ERROR, response.HTTPStatus);
+    }
+
+    // Revoke.
+    revokeFields := map[string]any{
+        "token-id": loginContent.TokenInfo.ID,
+    };
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/revoke`), revokeFields, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Token revoke failed: '%v'.", response);
+    }
+
+    var revokeContent TokenRevokeResponse;
+    util.MustJSONFromString(util.MustToJSON(response.Content), &revokeContent);
+
+    if (!revokeContent.FoundUser || !revokeContent.FoundToken) {
+        test.Fatalf("Unexpected revoke response: '%+v'.", revokeContent);
+    }
+
+    response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/list`), tokenFields, nil, model.RoleStudent);
+    if (response.Success) {
+        test.Fatalf("Revoked token did not fail.");
+    }
+
+    if (response.HTTPStatus != core.HTTP_STATUS_AUTH_ERROR) {
+        test.Fatalf("Unexpected status. Expected: %d, Actual: %d.", core.HTTP_STATUS_AUTH_ERROR, response.HTTPStatus);
+    }
+}
+
+func TestLoginExpiration(test *testing.T) {
+    defer db.ResetForTesting();
+
+    testCases := []struct{ fields map[string]any; noExpiration bool; locator string }{
+        {map[string]any{}, false, ""},
+        {map[string]any{"ttl-hours": 1}, false, ""},
+        {map[string]any{"no-expiration": true}, true, ""},
+        {map[string]any{"ttl-hours": -1}, false, "-810"},
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/login`), testCase.fields, nil, model.RoleOther);
+        if (!response.Success) {
+            if (testCase.locator == "") {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            } else if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expcted '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be.", i);
+            continue;
+        }
+
+        var responseContent LoginResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (testCase.noExpiration != responseContent.TokenInfo.ExpirationTime.IsZero()) {
+            test.Errorf("Case %d: Unexpected expiration. Expected no expiration: '%v', actual expiration: '%s'.",
+                    i, testCase.noExpiration, responseContent.TokenInfo.ExpirationTime);
+            continue;
+        }
+
+        user, err := db.GetUser(db.MustGetTestCourse(), "other@test.com");
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get user: '%v'.", i, err);
+            continue;
+        }
+
+        if (user.CheckToken(responseContent.Token) == nil) {
+            test.Errorf("Case %d: Token was not saved.", i);
+            continue;
+        }
+    }
+}
+
+func TestTokenRevokePermissions(test *testing.T) {
+    defer db.ResetForTesting();
+
+    testCases := []struct{ role model.UserRole; target string; locator string; foundToken bool }{
+        {model.RoleStudent, "", "", true},
+        {model.RoleStudent, "student@test.com", "", true},
+        {model.RoleStudent, "grader@test.com", "-033", false},
+        {model.RoleAdmin, "student@test.com", "", true},
+        {model.RoleAdmin, "owner@test.com", "-813", false},
+        {model.RoleOwner, "admin@test.com", "", true},
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+
+        targetEmail := testCase.target;
+        if (targetEmail == "") {
+            targetEmail = model.GetRoleString(testCase.role) + "@test.com";
+        }
+
+        course := db.MustGetTestCourse();
+        user, err := db.GetUser(course, targetEmail);
+        if (err != nil) {
+            test.Fatalf("Case %d: Failed to get user: '%v'.", i, err);
+        }
+
+        token, _, err := user.NewToken("", 0);
+        if (err != nil) {
+            test.Fatalf("Case %d: Failed to create token: '%v'.", i, err);
+        }
+
+        err = db.SaveUser(course, user);
+        if (err != nil) {
+            test.Fatalf("Case %d: Failed to save user: '%v'.", i, err);
+        }
+
+        fields := map[string]any{
+            "target-email": testCase.target,
+            "token-id": token.ID,
+        };
+
+        response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/revoke`), fields, nil, testCase.role);
+        if (!response.Success) {
+            if (testCase.locator == "") {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            } else if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expcted '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        var responseContent TokenRevokeResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (testCase.foundToken != responseContent.FoundToken) {
+            test.Errorf("Case %d: Unexpected found token. Expected: '%v', actual: '%v'.", i, testCase.foundToken, responseContent.FoundToken);
+            continue;
+        }
+    }
+}
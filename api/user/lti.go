@@ -0,0 +1,193 @@
+package user
+
+// Launching the autograder from an LMS that it is registered with as an LTI 1.3 tool (see the lti package).
+// Like the OIDC endpoints, these are not API endpoints that take an API request.
+// A launch starts when the LMS sends the user's browser to user/lti/login (which redirects back to the LMS),
+// and then the LMS posts the signed launch to user/lti/launch,
+// which responds with an API token (see user/login) for the course user matching the launch's email claim.
+// The tool's public keys (used by the LMS to authorize service requests) are served at user/lti/jwks.
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/lti"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+    "github.com/edulinq/autograder/util"
+)
+
+const LTI_TOKEN_NAME = "lti";
+
+type LTILaunchResponse struct {
+    CourseID string `json:"course-id"`
+    Email string `json:"email"`
+    Token string `json:"token"`
+    TokenInfo *TokenInfo `json:"token-info"`
+}
+
+// The LMS may start a login with either a GET or POST.
+func HandleLTILogin(response http.ResponseWriter, request *http.Request) error {
+    endpoint := request.URL.Path;
+
+    launchURL := config.LTI_LAUNCH_URL.Get();
+    if (launchURL == "") {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-844", endpoint, "LTI launches are not configured on this server."));
+    }
+
+    err := request.ParseForm();
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-845", endpoint, "Failed to parse LTI login request.").Err(err));
+    }
+
+    issuer := request.Form.Get("iss");
+    loginHint := request.Form.Get("login_hint");
+
+    if ((issuer == "") || (loginHint == "")) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-846", endpoint, "LTI login request is missing an issuer or login hint."));
+    }
+
+    registration, err := lti.FindRegistration(issuer, request.Form.Get("client_id"));
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-847", endpoint, "Failed to find LTI registration.").Add("issuer", issuer).Err(err));
+    }
+
+    if (registration == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-848", endpoint, "No course is registered with this LTI platform.").
+                Add("issuer", issuer).Add("client-id", request.Form.Get("client_id")));
+    }
+
+    state, err := oidc.NewLoginState("");
+    if (errors.Is(err, oidc.ErrTooManyLogins)) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareRateLimitError("-877", endpoint, LOGIN_RETRY_AFTER, "Too many pending LTI logins.").Add("issuer", issuer));
+    }
+
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-849", endpoint, "Failed to create login state.").Err(err));
+    }
+
+    authURL := lti.AuthURL(registration, launchURL, loginHint, request.Form.Get("lti_message_hint"), state);
+
+    // The launch is posted from the LMS's site, so the cookie has to be sent cross-site.
+    oidc.SetLoginStateCookie(response, state, launchURL, true);
+
+    http.Redirect(response, request, authURL, http.StatusFound);
+    return nil;
+}
+
+func HandleLTILaunch(response http.ResponseWriter, request *http.Request) error {
+    endpoint := request.URL.Path;
+
+    err := request.ParseForm();
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-850", endpoint, "Failed to parse LTI launch.").Err(err));
+    }
+
+    if (request.PostForm.Get("error") != "") {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-851", endpoint, "LMS returned an error.").
+                Add("error", request.PostForm.Get("error")).Add("error-description", request.PostForm.Get("error_description")));
+    }
+
+    state := oidc.TakeLoginState(request.PostForm.Get("state"));
+    if (state == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-852", endpoint, "Unknown or expired launch, please try launching again."));
+    }
+
+    validCookie := oidc.CheckLoginStateCookie(request, state);
+    oidc.ClearLoginStateCookie(response, config.LTI_LAUNCH_URL.Get(), true);
+
+    if (!validCookie) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-878", endpoint, "Launch was not started from this browser, please try launching again."));
+    }
+
+    claims, course, err := lti.VerifyLaunch(request.PostForm.Get("id_token"), state.Nonce);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-853", endpoint, "Failed to verify LTI launch.").Err(err));
+    }
+
+    if (claims.Email == "") {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-854", endpoint, "LMS did not provide an email.").
+                Course(course.GetID()).Add("subject", claims.Subject));
+    }
+
+    user, err := db.GetUser(course, claims.Email);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-855", endpoint, "Unable to get user.").
+                Course(course.GetID()).User(claims.Email).Err(err));
+    }
+
+    role := lti.GetRole(claims.Roles);
+    if ((user == nil) && config.LTI_AUTO_PROVISION.Get() && (role != model.RoleUnknown)) {
+        user = model.NewUser(claims.Email, claims.Name, role);
+        log.Info("Provisioning user from an LTI launch.", course, user);
+    }
+
+    if (user == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-856", endpoint, "Could not find a matching user in the course.").
+                Course(course.GetID()).User(claims.Email));
+    }
+
+    // Scores are passed back to an LTI LMS using the launch's user ID.
+    if ((user.LMSID == "") && (course.GetLMSAdapter().Type == model.LMS_TYPE_LTI)) {
+        user.LMSID = claims.Subject;
+    }
+
+    lifetime := time.Duration(config.WEB_TOKEN_TTL_HOURS.Get()) * time.Hour;
+    token, secret, err := user.NewToken(LTI_TOKEN_NAME, lifetime);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-857", endpoint, "Failed to create token.").
+                Course(course.GetID()).User(claims.Email).Err(err));
+    }
+
+    err = db.SaveUser(course, user);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-858", endpoint, "Failed to save user.").
+                Course(course.GetID()).User(claims.Email).Err(err));
+    }
+
+    log.Info("User launched from the LMS with LTI.", course, user);
+
+    content := LTILaunchResponse{
+        CourseID: course.GetID(),
+        Email: user.Email,
+        Token: secret,
+        TokenInfo: NewTokenInfo(token),
+    };
+
+    return core.SendAPIResponse(response, &content, nil);
+}
+
+// The key set is served as a bare JWKS document (not wrapped in an API response), since that is what platforms expect.
+func HandleLTIKeys(response http.ResponseWriter, request *http.Request) error {
+    keySet, err := lti.GetToolKeySet();
+    if (err != nil) {
+        return fmt.Errorf("Failed to get LTI tool key set: '%w'.", err);
+    }
+
+    response.Header().Set("Content-Type", "application/json");
+    _, err = fmt.Fprint(response, util.MustToJSON(keySet));
+    return err;
+}
@@ -0,0 +1,304 @@
+package user
+
+import (
+    "io"
+    "net/http"
+    "net/url"
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/lti"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestLTILaunch(test *testing.T) {
+    platform := setupTestLTI(test);
+    defer cleanupTestLTI(platform);
+
+    platform.AddMember("lti-student", "student@test.com", "student", lti.ROLE_LEARNER);
+    platform.AddMember("lti-new", "new@test.com", "new", lti.ROLE_LEARNER);
+    platform.AddMember("lti-observer", "observer@test.com", "observer", "http://purl.imsglobal.org/vocab/lis/v2/institution/person#Observer");
+    platform.AddMember("lti-no-email", "", "no-email", lti.ROLE_LEARNER);
+
+    testCases := []struct{ userID string; email string; provision bool; ltiType bool; role model.UserRole; locator string }{
+        {"lti-student", "student@test.com", false, false, model.RoleStudent, ""},
+        {"lti-student", "student@test.com", false, true, model.RoleStudent, ""},
+        {"lti-new", "new@test.com", false, false, model.RoleUnknown, "-856"},
+        {"lti-new", "new@test.com", true, false, model.RoleStudent, ""},
+        {"lti-observer", "observer@test.com", true, false, model.RoleUnknown, "-856"},
+        {"lti-no-email", "", false, false, model.RoleUnknown, "-854"},
+    };
+
+    for i, testCase := range testCases {
+        setTestLTIRegistration(test, platform, testCase.ltiType);
+
+        config.LTI_AUTO_PROVISION.Set(testCase.provision);
+        platform.SetLaunchUser(testCase.userID);
+
+        response := sendTestLTILaunch(test, platform);
+        if (!response.Success) {
+            if (testCase.locator == "") {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            } else if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expcted '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be.", i);
+            continue;
+        }
+
+        var responseContent LTILaunchResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if ((responseContent.CourseID != "course101") || (responseContent.Email != testCase.email)) {
+            test.Errorf("Case %d: Unexpected user. Expected: 'course101' / '%s', Actual: '%s' / '%s'.",
+                    i, testCase.email, responseContent.CourseID, responseContent.Email);
+            continue;
+        }
+
+        user, err := db.GetUser(db.MustGetTestCourse(), testCase.email);
+        if ((err != nil) || (user == nil)) {
+            test.Errorf("Case %d: Failed to get launched user: '%v'.", i, err);
+            continue;
+        }
+
+        if (user.Role != testCase.role) {
+            test.Errorf("Case %d: Unexpected role. Expected: '%s', Actual: '%s'.", i, testCase.role.String(), user.Role.String());
+            continue;
+        }
+
+        // Only LTI courses use the LTI user ID as the LMS ID.
+        if (testCase.ltiType != (user.LMSID == testCase.userID)) {
+            test.Errorf("Case %d: Unexpected LMS ID: '%s'.", i, user.LMSID);
+            continue;
+        }
+
+        // The token should work for API requests.
+        fields := map[string]any{
+            "user-email": testCase.email,
+            "user-pass": "",
+            "user-token": responseContent.Token,
+        };
+
+        response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/list`), fields, nil, model.RoleOther);
+        if (!response.Success) {
+            test.Errorf("Case %d: Could not use LTI token: '%v'.", i, response);
+            continue;
+        }
+    }
+}
+
+func TestLTILaunchErrors(test *testing.T) {
+    platform := setupTestLTI(test);
+    defer cleanupTestLTI(platform);
+
+    platform.AddMember("lti-student", "student@test.com", "student", lti.ROLE_LEARNER);
+    platform.SetLaunchUser("lti-student");
+    setTestLTIRegistration(test, platform, false);
+
+    params := platform.GetLoginParams();
+    params.Del("login_hint");
+    response := sendTestGET(test, core.NewEndpoint(`user/lti/login`) + "?" + params.Encode());
+    if (response.Locator != "-846") {
+        test.Errorf("Unexpected locator for a missing login hint. Expected: '-846', Actual: '%s'.", response.Locator);
+    }
+
+    params = platform.GetLoginParams();
+    params.Set("iss", "http://localhost/other");
+    response = sendTestGET(test, core.NewEndpoint(`user/lti/login`) + "?" + params.Encode());
+    if (response.Locator != "-848") {
+        test.Errorf("Unexpected locator for an unknown platform. Expected: '-848', Actual: '%s'.", response.Locator);
+    }
+
+    response = sendTestPOSTForm(test, core.NewEndpoint(`user/lti/launch`), url.Values{"error": []string{"login_required"}});
+    if (response.Locator != "-851") {
+        test.Errorf("Unexpected locator for an LMS error. Expected: '-851', Actual: '%s'.", response.Locator);
+    }
+
+    response = sendTestPOSTForm(test, core.NewEndpoint(`user/lti/launch`), url.Values{"state": []string{"ZZZ"}, "id_token": []string{"ZZZ"}});
+    if (response.Locator != "-852") {
+        test.Errorf("Unexpected locator for an unknown state. Expected: '-852', Actual: '%s'.", response.Locator);
+    }
+
+    // A launch for a different login attempt (wrong nonce).
+    state, err := oidc.NewLoginState("");
+    if (err != nil) {
+        test.Fatalf("Failed to create login state: '%v'.", err);
+    }
+
+    idToken, err := platform.SignLaunch(platform.NewLaunchClaims("lti-student", "ZZZ"));
+    if (err != nil) {
+        test.Fatalf("Failed to sign launch: '%v'.", err);
+    }
+
+    form := url.Values{"state": []string{state.State}, "id_token": []string{idToken}};
+    response = sendTestPOSTFormCookie(test, core.NewEndpoint(`user/lti/launch`), form, state.State);
+    if (response.Locator != "-853") {
+        test.Errorf("Unexpected locator for a bad launch. Expected: '-853', Actual: '%s'.", response.Locator);
+    }
+
+    // A launch for a login that was started in another browser (no or the wrong login cookie).
+    for _, cookie := range []string{"", "ZZZ"} {
+        state, err = oidc.NewLoginState("");
+        if (err != nil) {
+            test.Fatalf("Failed to create login state: '%v'.", err);
+        }
+
+        idToken, err = platform.SignLaunch(platform.NewLaunchClaims("lti-student", state.Nonce));
+        if (err != nil) {
+            test.Fatalf("Failed to sign launch: '%v'.", err);
+        }
+
+        form = url.Values{"state": []string{state.State}, "id_token": []string{idToken}};
+        response = sendTestPOSTFormCookie(test, core.NewEndpoint(`user/lti/launch`), form, cookie);
+        if (response.Locator != "-878") {
+            test.Errorf("Unexpected locator for a launch from another browser (cookie: '%s'). Expected: '-878', Actual: '%s'.",
+                    cookie, response.Locator);
+        }
+    }
+
+    config.LTI_LAUNCH_URL.Set("");
+
+    response = sendTestGET(test, core.NewEndpoint(`user/lti/login`) + "?" + platform.GetLoginParams().Encode());
+    if (response.Locator != "-844") {
+        test.Errorf("Unexpected locator for no LTI. Expected: '-844', Actual: '%s'.", response.Locator);
+    }
+}
+
+func TestLTIKeys(test *testing.T) {
+    httpResponse, err := http.Get(core.GetTestServerURL() + core.NewEndpoint(`user/lti/jwks`));
+    if (err != nil) {
+        test.Fatalf("Failed to GET keys: '%v'.", err);
+    }
+    defer httpResponse.Body.Close();
+
+    body, err := io.ReadAll(httpResponse.Body);
+    if (err != nil) {
+        test.Fatalf("Failed to read response body: '%v'.", err);
+    }
+
+    var keySet oidc.JSONWebKeySet;
+    util.MustJSONFromString(string(body), &keySet);
+
+    _, keyID, err := lti.GetToolKey();
+    if (err != nil) {
+        test.Fatalf("Failed to get tool key: '%v'.", err);
+    }
+
+    if ((len(keySet.Keys) != 1) || (keySet.Keys[0].KeyID != keyID)) {
+        test.Fatalf("Unexpected key set. Expected key ID: '%s', Actual: '%s'.", keyID, string(body));
+    }
+}
+
+func setupTestLTI(test *testing.T) *lti.TestPlatform {
+    platform, err := lti.NewTestPlatform("autograder", "test-deployment", "test-context");
+    if (err != nil) {
+        test.Fatalf("Failed to start test platform: '%v'.", err);
+    }
+
+    config.LTI_LAUNCH_URL.Set(core.GetTestServerURL() + core.NewEndpoint(`user/lti/launch`));
+
+    return platform;
+}
+
+func cleanupTestLTI(platform *lti.TestPlatform) {
+    platform.Close();
+
+    config.LTI_LAUNCH_URL.Set("");
+    config.LTI_AUTO_PROVISION.Set(false);
+
+    db.ResetForTesting();
+}
+
+func setTestLTIRegistration(test *testing.T, platform *lti.TestPlatform, ltiType bool) {
+    db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+    course.LMS.LTI = platform.GetRegistration();
+
+    if (ltiType) {
+        course.LMS.Type = model.LMS_TYPE_LTI;
+    }
+
+    err := db.SaveCourse(course);
+    if (err != nil) {
+        test.Fatalf("Failed to save course: '%v'.", err);
+    }
+}
+
+// Go through the full launch flow (acting as the user's browser).
+func sendTestLTILaunch(test *testing.T, platform *lti.TestPlatform) *core.APIResponse {
+    client := newTestBrowser(test);
+    client.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+        return http.ErrUseLastResponse;
+    };
+
+    loginURL := core.GetTestServerURL() + core.NewEndpoint(`user/lti/login`);
+    httpResponse, err := client.PostForm(loginURL, platform.GetLoginParams());
+    if (err != nil) {
+        test.Fatalf("Failed to POST LTI login: '%v'.", err);
+    }
+    httpResponse.Body.Close();
+
+    if (httpResponse.StatusCode != http.StatusFound) {
+        test.Fatalf("LTI login did not redirect, got status: %d.", httpResponse.StatusCode);
+    }
+
+    action, form, err := platform.Authorize(httpResponse.Header.Get("Location"));
+    if (err != nil) {
+        test.Fatalf("Failed to authorize launch: '%v'.", err);
+    }
+
+    // The launch is posted by the same browser.
+    return sendTestPOSTFormURL(test, client, action, form);
+}
+
+func sendTestPOSTForm(test *testing.T, endpoint string, form url.Values) *core.APIResponse {
+    return sendTestPOSTFormURL(test, newTestBrowser(test), core.GetTestServerURL() + endpoint, form);
+}
+
+// Send a form from a browser that has the given login cookie (if any).
+func sendTestPOSTFormCookie(test *testing.T, endpoint string, form url.Values, cookie string) *core.APIResponse {
+    client := newTestBrowser(test);
+    uri := core.GetTestServerURL() + endpoint;
+
+    if (cookie != "") {
+        parsedURL, err := url.Parse(uri);
+        if (err != nil) {
+            test.Fatalf("Failed to parse URL '%s': '%v'.", uri, err);
+        }
+
+        client.Jar.SetCookies(parsedURL, []*http.Cookie{&http.Cookie{Name: oidc.LOGIN_STATE_COOKIE, Value: cookie}});
+    }
+
+    return sendTestPOSTFormURL(test, client, uri, form);
+}
+
+func sendTestPOSTFormURL(test *testing.T, client *http.Client, uri string, form url.Values) *core.APIResponse {
+    httpResponse, err := client.PostForm(uri, form);
+    if (err != nil) {
+        test.Fatalf("Failed to POST '%s': '%v'.", uri, err);
+    }
+    defer httpResponse.Body.Close();
+
+    body, err := io.ReadAll(httpResponse.Body);
+    if (err != nil) {
+        test.Fatalf("Failed to read response body: '%v'.", err);
+    }
+
+    var response core.APIResponse;
+    err = util.JSONFromString(string(body), &response);
+    if (err != nil) {
+        test.Fatalf("Could not unmarshal JSON response '%s': '%v'.", string(body), err);
+    }
+
+    return &response;
+}
@@ -0,0 +1,195 @@
+package user
+
+// Single sign-on with an OpenID Connect identity provider (see the oidc package).
+// Unlike other API endpoints, these are GET endpoints that a user's browser is sent to.
+// A login starts at user/oidc/login (which redirects to the identity provider),
+// and the identity provider then sends the user back to user/oidc/callback,
+// which responds with an API token (see user/login) for the course user matching the provider's email claim.
+
+import (
+    "errors"
+    "net/http"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/lms/lmssync"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/oidc"
+)
+
+const OIDC_TOKEN_NAME = "oidc";
+
+// How long to tell clients to wait when there are too many pending logins.
+const LOGIN_RETRY_AFTER = time.Minute;
+
+type OIDCLoginResponse struct {
+    CourseID string `json:"course-id"`
+    Email string `json:"email"`
+    Token string `json:"token"`
+    TokenInfo *TokenInfo `json:"token-info"`
+}
+
+func HandleOIDCLogin(response http.ResponseWriter, request *http.Request) error {
+    endpoint := request.URL.Path;
+
+    provider := oidc.GetProvider();
+    if (provider == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-815", endpoint, "OIDC login is not configured on this server."));
+    }
+
+    courseID := request.URL.Query().Get("course-id");
+    if (courseID == "") {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-816", endpoint, "No course ID specified."));
+    }
+
+    course, err := db.GetCourse(courseID);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-817", endpoint, "Unable to get course.").Course(courseID).Err(err));
+    }
+
+    if (course == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-818", endpoint, "Could not find course.").Course(courseID));
+    }
+
+    state, err := oidc.NewLoginState(course.GetID());
+    if (errors.Is(err, oidc.ErrTooManyLogins)) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareRateLimitError("-875", endpoint, LOGIN_RETRY_AFTER, "Too many pending OIDC logins.").Course(courseID));
+    }
+
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-819", endpoint, "Failed to create login state.").Course(courseID).Err(err));
+    }
+
+    authURL, err := provider.AuthURL(state);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-820", endpoint, "Failed to get identity provider URL.").Course(courseID).Err(err));
+    }
+
+    oidc.SetLoginStateCookie(response, state, config.OIDC_REDIRECT_URL.Get(), false);
+
+    http.Redirect(response, request, authURL, http.StatusFound);
+    return nil;
+}
+
+func HandleOIDCCallback(response http.ResponseWriter, request *http.Request) error {
+    endpoint := request.URL.Path;
+    query := request.URL.Query();
+
+    if (query.Get("error") != "") {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-821", endpoint, "Identity provider returned an error.").
+                Add("error", query.Get("error")).Add("error-description", query.Get("error_description")));
+    }
+
+    state := oidc.TakeLoginState(query.Get("state"));
+    if (state == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-822", endpoint, "Unknown or expired login, please try logging in again."));
+    }
+
+    validCookie := oidc.CheckLoginStateCookie(request, state);
+    oidc.ClearLoginStateCookie(response, config.OIDC_REDIRECT_URL.Get(), false);
+
+    if (!validCookie) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-876", endpoint, "Login was not started from this browser, please try logging in again.").
+                Course(state.CourseID));
+    }
+
+    provider := oidc.GetProvider();
+    if (provider == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-823", endpoint, "OIDC login is not configured on this server."));
+    }
+
+    claims, err := provider.Exchange(query.Get("code"), state);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-824", endpoint, "Failed to verify login with the identity provider.").
+                Course(state.CourseID).Err(err));
+    }
+
+    if (claims.Email == "") {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-825", endpoint, "Identity provider did not provide an email.").
+                Course(state.CourseID).Add("subject", claims.Subject));
+    }
+
+    if ((claims.EmailVerified != nil) && !*claims.EmailVerified) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-826", endpoint, "Email has not been verified by the identity provider.").
+                Course(state.CourseID).User(claims.Email));
+    }
+
+    course, err := db.GetCourse(state.CourseID);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-827", endpoint, "Unable to get course.").Course(state.CourseID).Err(err));
+    }
+
+    if (course == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-828", endpoint, "Could not find course.").Course(state.CourseID));
+    }
+
+    user, err := db.GetUser(course, claims.Email);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-829", endpoint, "Unable to get user.").
+                Course(state.CourseID).User(claims.Email).Err(err));
+    }
+
+    if ((user == nil) && config.OIDC_AUTO_PROVISION.Get()) {
+        user, err = lmssync.ProvisionLMSUser(course, claims.Email);
+        if (err != nil) {
+            return core.SendAPIResponse(response, nil,
+                    core.NewBareInternalError("-830", endpoint, "Failed to provision user from the LMS.").
+                    Course(state.CourseID).User(claims.Email).Err(err));
+        }
+
+        if (user != nil) {
+            log.Info("Provisioned user from the LMS on OIDC login.", course, user);
+        }
+    }
+
+    if (user == nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareBadRequestError("-831", endpoint, "Could not find a matching user in the course.").
+                Course(state.CourseID).User(claims.Email));
+    }
+
+    lifetime := time.Duration(config.WEB_TOKEN_TTL_HOURS.Get()) * time.Hour;
+    token, secret, err := user.NewToken(OIDC_TOKEN_NAME, lifetime);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-832", endpoint, "Failed to create token.").
+                Course(state.CourseID).User(claims.Email).Err(err));
+    }
+
+    err = db.SaveUser(course, user);
+    if (err != nil) {
+        return core.SendAPIResponse(response, nil,
+                core.NewBareInternalError("-833", endpoint, "Failed to save user.").
+                Course(state.CourseID).User(claims.Email).Err(err));
+    }
+
+    log.Info("User logged in with OIDC.", course, user);
+
+    content := OIDCLoginResponse{
+        CourseID: course.GetID(),
+        Email: user.Email,
+        Token: secret,
+        TokenInfo: NewTokenInfo(token),
+    };
+
+    return core.SendAPIResponse(response, &content, nil);
+}
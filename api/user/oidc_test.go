@@ -0,0 +1,192 @@
+package user
+
+import (
+    "io"
+    "net/http"
+    "net/http/cookiejar"
+    "net/url"
+    "testing"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    lmstest "github.com/edulinq/autograder/lms/backend/test"
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestOIDCLogin(test *testing.T) {
+    idp := setupTestOIDC(test);
+    defer cleanupTestOIDC(idp);
+
+    testCases := []struct{ email string; verified bool; provision bool; locator string }{
+        {"student@test.com", true, false, ""},
+        {"owner@test.com", true, false, ""},
+        {"student@test.com", false, false, "-826"},
+        {"", true, false, "-825"},
+        {"new@test.com", true, false, "-831"},
+        {"new@test.com", true, true, ""},
+        {"ZZZ@test.com", true, true, "-831"},
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+
+        lmstest.SetUsersModifier(func(users []*lmstypes.User) []*lmstypes.User {
+            return append(users, &lmstypes.User{ID: "lms-new", Name: "new", Email: "new@test.com", Role: model.RoleStudent});
+        });
+
+        config.OIDC_AUTO_PROVISION.Set(testCase.provision);
+        idp.SetUser(testCase.email, testCase.verified);
+
+        response := sendTestOIDCLogin(test, "course101");
+        if (!response.Success) {
+            if (testCase.locator == "") {
+                test.Errorf("Case %d: Response is not a success when it should be: '%v'.", i, response);
+            } else if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expcted '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be.", i);
+            continue;
+        }
+
+        var responseContent OIDCLoginResponse;
+        util.MustJSONFromString(util.MustToJSON(response.Content), &responseContent);
+
+        if (responseContent.Email != testCase.email) {
+            test.Errorf("Case %d: Unexpected email. Expected: '%s', Actual: '%s'.", i, testCase.email, responseContent.Email);
+            continue;
+        }
+
+        // The token should work for API requests.
+        fields := map[string]any{
+            "user-email": testCase.email,
+            "user-pass": "",
+            "user-token": responseContent.Token,
+        };
+
+        response = core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/token/list`), fields, nil, model.RoleOther);
+        if (!response.Success) {
+            test.Errorf("Case %d: Could not use OIDC token: '%v'.", i, response);
+            continue;
+        }
+    }
+}
+
+func TestOIDCLoginErrors(test *testing.T) {
+    idp := setupTestOIDC(test);
+    defer cleanupTestOIDC(idp);
+
+    idp.SetUser("student@test.com", true);
+
+    response := sendTestOIDCLogin(test, "");
+    if (response.Locator != "-816") {
+        test.Errorf("Unexpected locator for a missing course. Expected: '-816', Actual: '%s'.", response.Locator);
+    }
+
+    response = sendTestOIDCLogin(test, "ZZZ");
+    if (response.Locator != "-818") {
+        test.Errorf("Unexpected locator for an unknown course. Expected: '-818', Actual: '%s'.", response.Locator);
+    }
+
+    response = sendTestGET(test, core.NewEndpoint(`user/oidc/callback`) + "?code=ZZZ&state=ZZZ");
+    if (response.Locator != "-822") {
+        test.Errorf("Unexpected locator for an unknown state. Expected: '-822', Actual: '%s'.", response.Locator);
+    }
+
+    // A callback for a login that was started in another browser (no login cookie).
+    state, err := oidc.NewLoginState("course101");
+    if (err != nil) {
+        test.Fatalf("Failed to create login state: '%v'.", err);
+    }
+
+    response = sendTestGET(test, core.NewEndpoint(`user/oidc/callback`) + "?code=ZZZ&state=" + url.QueryEscape(state.State));
+    if (response.Locator != "-876") {
+        test.Errorf("Unexpected locator for a login from another browser. Expected: '-876', Actual: '%s'.", response.Locator);
+    }
+
+    response = sendTestGET(test, core.NewEndpoint(`user/oidc/callback`) + "?error=access_denied");
+    if (response.Locator != "-821") {
+        test.Errorf("Unexpected locator for an identity provider error. Expected: '-821', Actual: '%s'.", response.Locator);
+    }
+
+    config.OIDC_ISSUER.Set("");
+
+    response = sendTestOIDCLogin(test, "course101");
+    if (response.Locator != "-815") {
+        test.Errorf("Unexpected locator for no OIDC. Expected: '-815', Actual: '%s'.", response.Locator);
+    }
+}
+
+func setupTestOIDC(test *testing.T) *oidc.TestIdentityProvider {
+    idp, err := oidc.NewTestIdentityProvider("autograder", "secret");
+    if (err != nil) {
+        test.Fatalf("Failed to start test identity provider: '%v'.", err);
+    }
+
+    config.OIDC_ISSUER.Set(idp.URL());
+    config.OIDC_CLIENT_ID.Set(idp.ClientID);
+    config.OIDC_CLIENT_SECRET.Set(idp.ClientSecret);
+    config.OIDC_REDIRECT_URL.Set(core.GetTestServerURL() + core.NewEndpoint(`user/oidc/callback`));
+
+    return idp;
+}
+
+func cleanupTestOIDC(idp *oidc.TestIdentityProvider) {
+    idp.Close();
+
+    config.OIDC_ISSUER.Set("");
+    config.OIDC_CLIENT_ID.Set("");
+    config.OIDC_CLIENT_SECRET.Set("");
+    config.OIDC_REDIRECT_URL.Set("");
+    config.OIDC_AUTO_PROVISION.Set(false);
+
+    lmstest.ClearUsersModifier();
+    db.ResetForTesting();
+}
+
+// Go through the full login flow (following all redirects).
+func sendTestOIDCLogin(test *testing.T, courseID string) *core.APIResponse {
+    return sendTestGET(test, core.NewEndpoint(`user/oidc/login`) + "?course-id=" + url.QueryEscape(courseID));
+}
+
+// Each request is sent from a new "browser" (with its own cookies).
+func sendTestGET(test *testing.T, endpoint string) *core.APIResponse {
+    client := newTestBrowser(test);
+
+    httpResponse, err := client.Get(core.GetTestServerURL() + endpoint);
+    if (err != nil) {
+        test.Fatalf("Failed to GET '%s': '%v'.", endpoint, err);
+    }
+    defer httpResponse.Body.Close();
+
+    body, err := io.ReadAll(httpResponse.Body);
+    if (err != nil) {
+        test.Fatalf("Failed to read response body: '%v'.", err);
+    }
+
+    var response core.APIResponse;
+    err = util.JSONFromString(string(body), &response);
+    if (err != nil) {
+        test.Fatalf("Could not unmarshal JSON response '%s': '%v'.", string(body), err);
+    }
+
+    return &response;
+}
+
+// Get a client that keeps cookies (like a user's browser).
+func newTestBrowser(test *testing.T) *http.Client {
+    jar, err := cookiejar.New(nil);
+    if (err != nil) {
+        test.Fatalf("Failed to create cookie jar: '%v'.", err);
+    }
+
+    return &http.Client{Jar: jar};
+}
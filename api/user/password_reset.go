@@ -0,0 +1,231 @@
+package user
+
+// Self-service password resets (see model.PasswordResetToken).
+// Unlike most endpoints, these do not require authentication (the user has forgotten their password).
+// To avoid revealing which users exist, requesting a reset for an unknown user looks the same as a successful request,
+// and confirming a reset for an unknown user looks the same as using a bad token.
+
+import (
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+)
+
+type PasswordResetRequestRequest struct {
+    core.APIRequest
+
+    CourseID string `json:"course-id"`
+    Email string `json:"email"`
+}
+
+type PasswordResetRequestResponse struct {
+}
+
+type PasswordResetConfirmRequest struct {
+    core.APIRequest
+
+    CourseID string `json:"course-id"`
+    Email string `json:"email"`
+    // The token from the reset email.
+    Token string `json:"token"`
+    NewPass string `json:"new-pass"`
+}
+
+type PasswordResetConfirmResponse struct {
+}
+
+// Recent password reset attempts (keyed by action, course, and email).
+// Attempts outside of the window are periodically swept away.
+var resetAttemptsLock sync.Mutex;
+var resetAttempts map[string][]time.Time = make(map[string][]time.Time);
+var resetAttemptsLastSweep time.Time = time.Now();
+
+func HandlePasswordResetRequest(request *PasswordResetRequestRequest) (*PasswordResetRequestResponse, *core.APIError) {
+    course, apiErr := getPasswordResetCourse(&request.APIRequest, request.CourseID);
+    if (apiErr != nil) {
+        return nil, apiErr;
+    }
+
+    if (request.Email == "") {
+        return nil, core.NewBadRequestError("-862", &request.APIRequest, "No email specified.").Course(request.CourseID);
+    }
+
+    if (!allowPasswordResetAttempt("request", course, request.Email)) {
+        return nil, core.NewBadRequestError("-863", &request.APIRequest, "Too many password reset requests, try again later.").
+                Course(request.CourseID).User(request.Email);
+    }
+
+    user, err := db.GetUser(course, request.Email);
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-864", request.Endpoint, "Failed to get user.").
+                Course(request.CourseID).User(request.Email).Err(err);
+    }
+
+    if (user == nil) {
+        log.Debug("Password reset requested for unknown user.", course, log.NewUserAttr(request.Email));
+        return &PasswordResetRequestResponse{}, nil;
+    }
+
+    ttlMinutes := config.WEB_PASSWORD_RESET_TTL_MINS.Get();
+
+    token, err := user.NewPasswordResetToken(time.Duration(ttlMinutes) * time.Minute);
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-865", request.Endpoint, "Failed to create password reset token.").
+                Course(request.CourseID).User(request.Email).Err(err);
+    }
+
+    err = db.SaveUser(course, user);
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-866", request.Endpoint, "Failed to save user.").
+                Course(request.CourseID).User(request.Email).Err(err);
+    }
+
+    data := model.PasswordResetEmailData{
+        CourseID: course.GetID(),
+        CourseName: course.GetDisplayName(),
+        Email: user.Email,
+        Token: token,
+        TTLMinutes: ttlMinutes,
+    };
+
+    message, err := course.RenderEmail(email.TEMPLATE_PASSWORD_RESET, []string{user.Email}, &data);
+    if (err == nil) {
+        // The reset token is in the body.
+        message.Sensitive = true;
+        err = email.SendMessage(message);
+    }
+
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-867", request.Endpoint, "Failed to send password reset email.").
+                Course(request.CourseID).User(request.Email).Err(err);
+    }
+
+    log.Info("Password reset requested.", course, user);
+
+    return &PasswordResetRequestResponse{}, nil;
+}
+
+func HandlePasswordResetConfirm(request *PasswordResetConfirmRequest) (*PasswordResetConfirmResponse, *core.APIError) {
+    course, apiErr := getPasswordResetCourse(&request.APIRequest, request.CourseID);
+    if (apiErr != nil) {
+        return nil, apiErr;
+    }
+
+    if ((request.Email == "") || (request.Token == "") || (request.NewPass == "")) {
+        return nil, core.NewBadRequestError("-868", &request.APIRequest, "An email, token, and new password are required.").
+                Course(request.CourseID);
+    }
+
+    if (!allowPasswordResetAttempt("confirm", course, request.Email)) {
+        return nil, core.NewBadRequestError("-869", &request.APIRequest, "Too many password reset attempts, try again later.").
+                Course(request.CourseID).User(request.Email);
+    }
+
+    user, err := db.GetUser(course, request.Email);
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-870", request.Endpoint, "Failed to get user.").
+                Course(request.CourseID).User(request.Email).Err(err);
+    }
+
+    reset := false;
+    if (user != nil) {
+        reset, err = user.ResetPassword(request.Token, request.NewPass);
+        if (err != nil) {
+            return nil, core.NewBareInternalError("-871", request.Endpoint, "Failed to set password.").
+                    Course(request.CourseID).User(request.Email).Err(err);
+        }
+    }
+
+    if (!reset) {
+        return nil, core.NewBadRequestError("-872", &request.APIRequest, "Invalid or expired password reset token.").
+                Course(request.CourseID).User(request.Email);
+    }
+
+    err = db.SaveUser(course, user);
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-873", request.Endpoint, "Failed to save user.").
+                Course(request.CourseID).User(request.Email).Err(err);
+    }
+
+    log.Info("Password reset.", course, user);
+
+    return &PasswordResetConfirmResponse{}, nil;
+}
+
+func getPasswordResetCourse(request *core.APIRequest, courseID string) (*model.Course, *core.APIError) {
+    if (courseID == "") {
+        return nil, core.NewBadRequestError("-859", request, "No course ID specified.");
+    }
+
+    course, err := db.GetCourse(courseID);
+    if (err != nil) {
+        return nil, core.NewBareInternalError("-860", request.Endpoint, "Unable to get course.").Course(courseID).Err(err);
+    }
+
+    if (course == nil) {
+        return nil, core.NewBadRequestError("-861", request, fmt.Sprintf("Could not find course: '%s'.", courseID)).Course(courseID);
+    }
+
+    return course, nil;
+}
+
+// Record an attempt and check if it is within the limit for this email.
+// Attempts are limited the same whether or not the user exists.
+func allowPasswordResetAttempt(action string, course *model.Course, address string) bool {
+    resetAttemptsLock.Lock();
+    defer resetAttemptsLock.Unlock();
+
+    key := fmt.Sprintf("%s::%s::%s", action, course.GetID(), address);
+
+    now := time.Now();
+    windowStart := now.Add(-time.Duration(config.WEB_PASSWORD_RESET_WINDOW_MINS.Get()) * time.Minute);
+
+    sweepPasswordResetAttempts(now, windowStart);
+
+    attempts := make([]time.Time, 0, len(resetAttempts[key]) + 1);
+    for _, attempt := range resetAttempts[key] {
+        if (attempt.After(windowStart)) {
+            attempts = append(attempts, attempt);
+        }
+    }
+
+    if (len(attempts) >= config.WEB_PASSWORD_RESET_MAX_REQUESTS.Get()) {
+        resetAttempts[key] = attempts;
+        return false;
+    }
+
+    resetAttempts[key] = append(attempts, now);
+    return true;
+}
+
+// Forget attempts that are outside of the window (an email with no recent attempts is the same as no entry).
+// The caller must hold the lock.
+func sweepPasswordResetAttempts(now time.Time, windowStart time.Time) {
+    if (now.Sub(resetAttemptsLastSweep) < core.RATE_LIMIT_SWEEP_INTERVAL) {
+        return;
+    }
+
+    resetAttemptsLastSweep = now;
+
+    for key, attempts := range resetAttempts {
+        // Attempts are in order, so only the most recent one needs to be checked.
+        if ((len(attempts) == 0) || !attempts[len(attempts) - 1].After(windowStart)) {
+            delete(resetAttempts, key);
+        }
+    }
+}
+
+func clearPasswordResetAttempts() {
+    resetAttemptsLock.Lock();
+    defer resetAttemptsLock.Unlock();
+
+    resetAttempts = make(map[string][]time.Time);
+    resetAttemptsLastSweep = time.Now();
+}
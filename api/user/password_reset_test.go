@@ -0,0 +1,221 @@
+package user
+
+import (
+    "regexp"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+var resetTokenPattern *regexp.Regexp = regexp.MustCompile(`reset token is '([0-9a-f]+)'`);
+
+func TestPasswordReset(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    defer email.ClearTestMessages();
+    email.ClearTestMessages();
+
+    defer clearPasswordResetAttempts();
+    clearPasswordResetAttempts();
+
+    // Get a token that should be revoked by the reset.
+    response := core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/login`), nil, nil, model.RoleStudent);
+    if (!response.Success) {
+        test.Fatalf("Login failed: '%v'.", response);
+    }
+
+    // No credentials are needed.
+    response = core.SendTestAPIRequest(test, core.NewEndpoint(`user/password/reset/request`), map[string]any{
+        "email": "student@test.com",
+        "user-email": "",
+        "user-pass": "",
+    });
+    if (!response.Success) {
+        test.Fatalf("Reset request failed: '%v'.", response);
+    }
+
+    // The email has the token, so it must never be saved in the outbox.
+    messages := email.GetTestMessages();
+    if ((len(messages) != 1) || (messages[0].To[0] != "student@test.com") || (messages[0].CourseID != "course101") || !messages[0].Sensitive) {
+        test.Fatalf("Unexpected reset emails: '%s'.", util.MustToJSONIndent(messages));
+    }
+
+    match := resetTokenPattern.FindStringSubmatch(messages[0].Body);
+    if (match == nil) {
+        test.Fatalf("Could not find reset token in email: '%s'.", messages[0].Body);
+    }
+
+    token := match[1];
+    newPass := util.Sha256HexFromString("reset");
+
+    // A bad token does not use up the real token.
+    response = sendPasswordResetConfirm(test, "student@test.com", "ZZZ", newPass);
+    if (response.Locator != "-872") {
+        test.Fatalf("Incorrect error for a bad token. Expected '-872', found '%s'.", response.Locator);
+    }
+
+    response = sendPasswordResetConfirm(test, "student@test.com", token, newPass);
+    if (!response.Success) {
+        test.Fatalf("Reset confirm failed: '%v'.", response);
+    }
+
+    user, err := db.GetUser(db.MustGetTestCourse(), "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user: '%v'.", err);
+    }
+
+    if (!user.CheckPassword(newPass)) {
+        test.Fatalf("Password was not reset.");
+    }
+
+    if (user.PasswordReset != nil) {
+        test.Fatalf("Reset token was not removed.");
+    }
+
+    if (len(user.Tokens) != 0) {
+        test.Fatalf("API tokens were not revoked: '%s'.", util.MustToJSONIndent(user.Tokens));
+    }
+
+    // Tokens are single-use.
+    response = sendPasswordResetConfirm(test, "student@test.com", token, util.Sha256HexFromString("again"));
+    if (response.Locator != "-872") {
+        test.Fatalf("Incorrect error for a reused token. Expected '-872', found '%s'.", response.Locator);
+    }
+}
+
+func TestPasswordResetErrors(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    defer email.ClearTestMessages();
+
+    testCases := []struct{ endpoint string; fields map[string]any; locator string; sendsEmail bool }{
+        {`user/password/reset/request`, map[string]any{"email": "student@test.com"}, "", true},
+        // Unknown users look like a success (but do not get an email).
+        {`user/password/reset/request`, map[string]any{"email": "ZZZ@test.com"}, "", false},
+
+        {`user/password/reset/request`, map[string]any{"course-id": "", "email": "student@test.com"}, "-859", false},
+        {`user/password/reset/request`, map[string]any{"course-id": "ZZZ", "email": "student@test.com"}, "-861", false},
+        {`user/password/reset/request`, map[string]any{}, "-862", false},
+
+        {`user/password/reset/confirm`, map[string]any{"email": "student@test.com", "token": "abc"}, "-868", false},
+        {`user/password/reset/confirm`, map[string]any{"email": "student@test.com", "new-pass": "abc"}, "-868", false},
+        {`user/password/reset/confirm`, map[string]any{"email": "ZZZ@test.com", "token": "abc", "new-pass": "abc"}, "-872", false},
+    };
+
+    for i, testCase := range testCases {
+        email.ClearTestMessages();
+        clearPasswordResetAttempts();
+
+        response := core.SendTestAPIRequest(test, core.NewEndpoint(testCase.endpoint), testCase.fields);
+        if (!response.Success) {
+            if (response.Locator != testCase.locator) {
+                test.Errorf("Case %d: Incorrect error returned. Expected '%s', found '%s'.", i, testCase.locator, response.Locator);
+            }
+
+            continue;
+        }
+
+        if (testCase.locator != "") {
+            test.Errorf("Case %d: Response is a success when it should not be: '%v'.", i, response);
+            continue;
+        }
+
+        if (testCase.sendsEmail != (len(email.GetTestMessages()) > 0)) {
+            test.Errorf("Case %d: Unexpected emails. Expected email: %v, Actual: '%s'.", i, testCase.sendsEmail, util.MustToJSONIndent(email.GetTestMessages()));
+        }
+    }
+}
+
+func TestPasswordResetRateLimit(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    defer email.ClearTestMessages();
+    email.ClearTestMessages();
+
+    defer clearPasswordResetAttempts();
+    clearPasswordResetAttempts();
+
+    defer config.WEB_PASSWORD_RESET_MAX_REQUESTS.Set(config.WEB_PASSWORD_RESET_MAX_REQUESTS.Get());
+    config.WEB_PASSWORD_RESET_MAX_REQUESTS.Set(2);
+
+    // Unknown emails are limited the same as known ones.
+    for _, address := range []string{"student@test.com", "ZZZ@test.com"} {
+        for i := 0; i < 3; i++ {
+            response := core.SendTestAPIRequest(test, core.NewEndpoint(`user/password/reset/request`), map[string]any{"email": address});
+
+            if (i < 2) {
+                if (!response.Success) {
+                    test.Fatalf("Request %d for '%s' failed: '%v'.", i, address, response);
+                }
+            } else if (response.Locator != "-863") {
+                test.Fatalf("Request %d for '%s' was not limited. Expected '-863', found '%s'.", i, address, response.Locator);
+            }
+        }
+    }
+
+    // Other emails are not affected.
+    response := core.SendTestAPIRequest(test, core.NewEndpoint(`user/password/reset/request`), map[string]any{"email": "grader@test.com"});
+    if (!response.Success) {
+        test.Fatalf("Request for another email failed: '%v'.", response);
+    }
+
+    for i := 0; i < 3; i++ {
+        response = sendPasswordResetConfirm(test, "student@test.com", "ZZZ", "abc");
+
+        expected := "-872";
+        if (i >= 2) {
+            expected = "-869";
+        }
+
+        if (response.Locator != expected) {
+            test.Fatalf("Confirm %d: Incorrect error returned. Expected '%s', found '%s'.", i, expected, response.Locator);
+        }
+    }
+}
+
+func sendPasswordResetConfirm(test *testing.T, address string, token string, newPass string) *core.APIResponse {
+    fields := map[string]any{
+        "email": address,
+        "token": token,
+        "new-pass": newPass,
+    };
+
+    return core.SendTestAPIRequestFull(test, core.NewEndpoint(`user/password/reset/confirm`), fields, nil, model.RoleOther);
+}
+
+func TestSweepPasswordResetAttempts(test *testing.T) {
+    defer clearPasswordResetAttempts();
+    clearPasswordResetAttempts();
+
+    now := time.Now();
+    windowStart := now.Add(-time.Hour);
+
+    resetAttemptsLock.Lock();
+    defer resetAttemptsLock.Unlock();
+
+    resetAttempts["old"] = []time.Time{now.Add(-3 * time.Hour), now.Add(-2 * time.Hour)};
+    resetAttempts["recent"] = []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Minute)};
+    resetAttempts["empty"] = []time.Time{};
+
+    // Too soon since the last sweep.
+    sweepPasswordResetAttempts(now, windowStart);
+    if (len(resetAttempts) != 3) {
+        test.Fatalf("Attempts were swept too soon: '%v'.", resetAttempts);
+    }
+
+    resetAttemptsLastSweep = now.Add(-2 * core.RATE_LIMIT_SWEEP_INTERVAL);
+
+    sweepPasswordResetAttempts(now, windowStart);
+    if ((len(resetAttempts) != 1) || (resetAttempts["recent"] == nil)) {
+        test.Fatalf("Unexpected attempts after a sweep: '%v'.", resetAttempts);
+    }
+}
@@ -11,8 +11,21 @@ var routes []*core.Route = []*core.Route{
     core.NewAPIRoute(core.NewEndpoint(`user/auth`), HandleAuth),
     core.NewAPIRoute(core.NewEndpoint(`user/change/pass`), HandleChangePassword),
     core.NewAPIRoute(core.NewEndpoint(`user/get`), HandleUserGet),
+    core.NewAPIRoute(core.NewEndpoint(`user/late-days`), HandleLateDays),
+    core.NewAPIRoute(core.NewEndpoint(`user/late-days/select`), HandleLateDaysSelect),
     core.NewAPIRoute(core.NewEndpoint(`user/list`), HandleList),
+    core.NewAPIRoute(core.NewEndpoint(`user/login`), HandleLogin),
+    core.NewRoute("GET", core.NewEndpoint(`user/lti/jwks`), HandleLTIKeys),
+    core.NewRoute("POST", core.NewEndpoint(`user/lti/launch`), HandleLTILaunch),
+    core.NewRoute("GET", core.NewEndpoint(`user/lti/login`), HandleLTILogin),
+    core.NewRoute("POST", core.NewEndpoint(`user/lti/login`), HandleLTILogin),
+    core.NewRoute("GET", core.NewEndpoint(`user/oidc/callback`), HandleOIDCCallback),
+    core.NewRoute("GET", core.NewEndpoint(`user/oidc/login`), HandleOIDCLogin),
+    core.NewAPIRoute(core.NewEndpoint(`user/password/reset/confirm`), HandlePasswordResetConfirm),
+    core.NewAPIRoute(core.NewEndpoint(`user/password/reset/request`), HandlePasswordResetRequest),
     core.NewAPIRoute(core.NewEndpoint(`user/remove`), HandleRemove),
+    core.NewAPIRoute(core.NewEndpoint(`user/token/list`), HandleTokenList),
+    core.NewAPIRoute(core.NewEndpoint(`user/token/revoke`), HandleTokenRevoke),
 };
 
 func GetRoutes() *[]*core.Route {
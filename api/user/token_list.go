@@ -0,0 +1,58 @@
+package user
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
+)
+
+type TokenListRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleOther
+
+    TargetUser core.TargetUserSelfOrAdmin `json:"target-email"`
+}
+
+type TokenListResponse struct {
+    FoundUser bool `json:"found-user"`
+    Tokens []*TokenInfo `json:"tokens"`
+}
+
+// How to represent tokens in API responses (the hash is never sent).
+type TokenInfo struct {
+    ID string `json:"id"`
+    Name string `json:"name"`
+    CreationTime common.Timestamp `json:"creation-time"`
+    ExpirationTime common.Timestamp `json:"expiration-time"`
+}
+
+func NewTokenInfo(token *model.APIToken) *TokenInfo {
+    return &TokenInfo{
+        ID: token.ID,
+        Name: token.Name,
+        CreationTime: token.CreationTime,
+        ExpirationTime: token.ExpirationTime,
+    };
+}
+
+func HandleTokenList(request *TokenListRequest) (*TokenListResponse, *core.APIError) {
+    response := TokenListResponse{
+        Tokens: make([]*TokenInfo, 0),
+    };
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    for _, token := range request.TargetUser.User.Tokens {
+        if (token.IsExpired()) {
+            continue;
+        }
+
+        response.Tokens = append(response.Tokens, NewTokenInfo(token));
+    }
+
+    return &response, nil;
+}
@@ -0,0 +1,47 @@
+package user
+
+import (
+    "github.com/edulinq/autograder/api/core"
+    "github.com/edulinq/autograder/db"
+)
+
+type TokenRevokeRequest struct {
+    core.APIRequestCourseUserContext
+    core.MinRoleOther
+
+    TargetUser core.TargetUserSelfOrAdmin `json:"target-email"`
+    TokenID core.NonEmptyString `json:"token-id"`
+}
+
+type TokenRevokeResponse struct {
+    FoundUser bool `json:"found-user"`
+    FoundToken bool `json:"found-token"`
+}
+
+func HandleTokenRevoke(request *TokenRevokeRequest) (*TokenRevokeResponse, *core.APIError) {
+    response := TokenRevokeResponse{};
+
+    if (!request.TargetUser.Found) {
+        return &response, nil;
+    }
+
+    response.FoundUser = true;
+
+    if (request.TargetUser.User.Role > request.User.Role) {
+        return nil, core.NewBadPermissionsError("-813", &request.APIRequestCourseUserContext, request.TargetUser.User.Role,
+                "Cannot modify a user with a higher role.").Add("target-user", request.TargetUser.User.Email);
+    }
+
+    response.FoundToken = request.TargetUser.User.RevokeToken(string(request.TokenID));
+    if (!response.FoundToken) {
+        return &response, nil;
+    }
+
+    err := db.SaveUser(request.Course, request.TargetUser.User);
+    if (err != nil) {
+        return nil, core.NewInternalError("-814", &request.APIRequestCourseUserContext,
+                "Failed to save user.").Err(err).Add("target-user", request.TargetUser.Email);
+    }
+
+    return &response, nil;
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/alecthomas/kong"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+var args struct {
+    config.ConfigArgs
+
+    SourceType string `help:"Type of the source database (disk, sqlite, postgres). Defaults to the configured database type."`
+    Source string `help:"Location of the source database (disk: directory, sqlite: file, postgres: connection URI). Defaults to the configured location."`
+
+    TargetType string `help:"Type of the target database (disk, sqlite, postgres)." required:""`
+    Target string `help:"Location of the target database (disk: directory, sqlite: file, postgres: connection URI). Defaults to the configured location."`
+
+    ClearTarget bool `help:"Clear the target database before migrating. Without this, the target must be empty." default:"false"`
+}
+
+func main() {
+    kong.Parse(&args,
+        kong.Description("Copy all data (courses, users, submissions, task completions, and logs) from one database to another," +
+                " and verify the copy using counts and checksums."),
+    );
+
+    err := config.HandleConfigArgs(args.ConfigArgs);
+    if (err != nil) {
+        log.Fatal("Could not load config options.", err);
+    }
+
+    sourceType := args.SourceType;
+    if (sourceType == "") {
+        sourceType = config.DB_TYPE.Get();
+    }
+
+    if ((sourceType == args.TargetType) && (args.Source == args.Target)) {
+        log.Fatal("Source and target databases are the same.", log.NewAttr("type", sourceType), log.NewAttr("location", args.Source));
+    }
+
+    source, err := db.OpenBackend(sourceType, args.Source);
+    if (err != nil) {
+        log.Fatal("Failed to open source database.", err);
+    }
+    defer source.Close();
+
+    target, err := db.OpenBackend(args.TargetType, args.Target);
+    if (err != nil) {
+        log.Fatal("Failed to open target database.", err);
+    }
+    defer target.Close();
+
+    if (args.ClearTarget) {
+        err = target.Clear();
+        if (err != nil) {
+            log.Fatal("Failed to clear target database.", err);
+        }
+    } else {
+        err = checkEmpty(target);
+        if (err != nil) {
+            log.Fatal("Target database is not usable.", err);
+        }
+    }
+
+    startTime := time.Now();
+    sourceSummary, targetSummary, err := db.Migrate(source, target);
+    if (err != nil) {
+        if (sourceSummary != nil) {
+            fmt.Printf("Source Summary:\n%s\n", util.MustToJSONIndent(sourceSummary));
+        }
+
+        if (targetSummary != nil) {
+            fmt.Printf("Target Summary:\n%s\n", util.MustToJSONIndent(targetSummary));
+        }
+
+        log.Fatal("Failed to migrate database.", err);
+    }
+
+    fmt.Printf("Successfully migrated database in %s.\n", time.Since(startTime).Round(time.Millisecond));
+    fmt.Println(util.MustToJSONIndent(targetSummary));
+}
+
+func checkEmpty(target db.Backend) error {
+    courses, err := target.GetCourses();
+    if (err != nil) {
+        return err;
+    }
+
+    if (len(courses) > 0) {
+        return fmt.Errorf("Target database already has %d courses (use --clear-target to clear it).", len(courses));
+    }
+
+    records, err := target.GetLogRecords(log.LevelTrace, time.Time{}, "", "", "");
+    if (err != nil) {
+        return err;
+    }
+
+    if (len(records) > 0) {
+        return fmt.Errorf("Target database already has %d log records (use --clear-target to clear it).", len(records));
+    }
+
+    return nil;
+}
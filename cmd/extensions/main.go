@@ -0,0 +1,153 @@
+package main
+
+import (
+    "fmt"
+
+    "github.com/alecthomas/kong"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+type SetExtension struct {
+    Email string `help:"Email for the user." arg:"" required:""`
+    Assignment string `help:"ID of the assignment. Defaults to all assignments." short:"a"`
+    DueDate string `help:"New due date for the user (RFC 3339, e.g. '2024-01-01T23:59:00-08:00')." short:"d"`
+    ExtraDays int `help:"Number of days to add to the due date." short:"e" default:"0"`
+    TimeMultiplier float64 `help:"Stretch the length of a late day by this factor (e.g. 1.5 for time and a half)." short:"m" default:"0"`
+    Reason string `help:"Reason for the extension." short:"r"`
+}
+
+func (this *SetExtension) Run(course *model.Course) error {
+    user, err := db.GetUser(course, this.Email);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get user: '%w'.", err);
+    }
+
+    if (user == nil) {
+        return fmt.Errorf("User '%s' does not exist.", this.Email);
+    }
+
+    if ((this.Assignment != "") && (course.GetAssignment(this.Assignment) == nil)) {
+        return fmt.Errorf("Assignment '%s' does not exist.", this.Assignment);
+    }
+
+    dueDate := common.Timestamp("");
+    if (this.DueDate != "") {
+        dueDate, err = common.TimestampFromString(this.DueDate);
+        if (err != nil) {
+            return fmt.Errorf("Failed to parse due date: '%w'.", err);
+        }
+    }
+
+    extension := &model.Extension{
+        User: user.Email,
+        AssignmentID: this.Assignment,
+        DueDate: dueDate,
+        ExtraDays: this.ExtraDays,
+        TimeMultiplier: this.TimeMultiplier,
+        Reason: this.Reason,
+        GrantedTime: common.NowTimestamp(),
+    };
+
+    err = db.SaveExtension(course, extension);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save extension: '%w'.", err);
+    }
+
+    fmt.Println(util.MustToJSONIndent(extension));
+
+    return nil;
+}
+
+type ListExtensions struct {
+    Email string `help:"Only list extensions for this user." short:"u"`
+    Assignment string `help:"Show the effective extensions for this assignment (combining assignment and all-assignment extensions)." short:"a"`
+}
+
+func (this *ListExtensions) Run(course *model.Course) error {
+    extensions, err := db.GetExtensions(course);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get extensions: '%w'.", err);
+    }
+
+    if (this.Assignment != "") {
+        if (course.GetAssignment(this.Assignment) == nil) {
+            return fmt.Errorf("Assignment '%s' does not exist.", this.Assignment);
+        }
+
+        resolved := model.ResolveExtensions(extensions, this.Assignment);
+
+        extensions = make([]*model.Extension, 0, len(resolved));
+        for _, extension := range resolved {
+            extensions = append(extensions, extension);
+        }
+    }
+
+    results := make([]*model.Extension, 0, len(extensions));
+    for _, extension := range extensions {
+        if ((this.Email != "") && (this.Email != extension.User)) {
+            continue;
+        }
+
+        results = append(results, extension);
+    }
+
+    fmt.Println(util.MustToJSONIndent(results));
+
+    return nil;
+}
+
+type RmExtension struct {
+    Email string `help:"Email for the user." arg:"" required:""`
+    Assignment string `help:"ID of the assignment. Defaults to the extension for all assignments." short:"a"`
+}
+
+func (this *RmExtension) Run(course *model.Course) error {
+    exists, err := db.RemoveExtension(course, this.Email, this.Assignment);
+    if (err != nil) {
+        return fmt.Errorf("Failed to remove extension: '%w'.", err);
+    }
+
+    if (!exists) {
+        return fmt.Errorf("Extension does not exist for user '%s'.", this.Email);
+    }
+
+    fmt.Printf("Extension for user '%s' removed.\n", this.Email);
+
+    return nil;
+}
+
+var cli struct {
+    config.ConfigArgs
+    Course string `help:"ID of the course."`
+
+    Set SetExtension `cmd:"" help:"Grant (or replace) an extension."`
+    Ls ListExtensions `cmd:"" help:"List extensions."`
+    Rm RmExtension `cmd:"" help:"Remove an extension."`
+}
+
+func main() {
+    context := kong.Parse(&cli,
+        kong.Description("Manage per-user due date extensions."),
+    );
+
+    err := config.HandleConfigArgs(cli.ConfigArgs);
+    if (err != nil) {
+        log.Fatal("Could not load config options.", err);
+    }
+
+    db.MustOpen();
+    defer db.MustClose();
+
+    course := db.MustGetCourse(cli.Course);
+
+    err = context.Run(course);
+    if (err != nil) {
+        log.Fatal("Failed to run command.", err, course);
+    }
+}
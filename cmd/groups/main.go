@@ -0,0 +1,147 @@
+package main
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+
+    "github.com/alecthomas/kong"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/lms/lmssync"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+type SetGroup struct {
+    ID string `help:"ID of the group (an existing group with this ID will be replaced)." arg:"" required:""`
+    Members []string `help:"Emails of the group members." arg:"" required:""`
+    Name string `help:"Display name for the group." short:"n"`
+}
+
+func (this *SetGroup) Run(course *model.Course) error {
+    users, err := db.GetUsers(course);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get users: '%w'.", err);
+    }
+
+    for _, member := range this.Members {
+        if (users[member] == nil) {
+            return fmt.Errorf("User '%s' does not exist.", member);
+        }
+    }
+
+    group := &model.Group{
+        ID: this.ID,
+        Name: this.Name,
+        Members: this.Members,
+    };
+
+    err = db.SaveGroup(course, group);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save group: '%w'.", err);
+    }
+
+    fmt.Println(util.MustToJSONIndent(group));
+
+    return nil;
+}
+
+type ListGroups struct {
+    Email string `help:"Only list the group for this user." short:"u"`
+}
+
+func (this *ListGroups) Run(course *model.Course) error {
+    groups, err := db.GetGroups(course);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get groups: '%w'.", err);
+    }
+
+    results := make([]*model.Group, 0, len(groups));
+    for _, group := range groups {
+        if ((this.Email != "") && !group.HasMember(this.Email)) {
+            continue;
+        }
+
+        results = append(results, group);
+    }
+
+    slices.SortFunc(results, func(a *model.Group, b *model.Group) int {
+        return strings.Compare(a.ID, b.ID);
+    });
+
+    fmt.Println(util.MustToJSONIndent(results));
+
+    return nil;
+}
+
+type RmGroup struct {
+    ID string `help:"ID of the group." arg:"" required:""`
+}
+
+func (this *RmGroup) Run(course *model.Course) error {
+    exists, err := db.RemoveGroup(course, this.ID);
+    if (err != nil) {
+        return fmt.Errorf("Failed to remove group: '%w'.", err);
+    }
+
+    if (!exists) {
+        return fmt.Errorf("Group '%s' does not exist.", this.ID);
+    }
+
+    fmt.Printf("Group '%s' removed.\n", this.ID);
+
+    return nil;
+}
+
+type ImportGroups struct {
+    DryRun bool `help:"Do not actually save any groups, just state what would be done." default:"false"`
+}
+
+func (this *ImportGroups) Run(course *model.Course) error {
+    if (!course.HasLMSAdapter()) {
+        return fmt.Errorf("Course '%s' is not linked to an LMS.", course.GetID());
+    }
+
+    result, err := lmssync.SyncLMSGroups(course, this.DryRun);
+    if (err != nil) {
+        return fmt.Errorf("Failed to import groups: '%w'.", err);
+    }
+
+    fmt.Println(util.MustToJSONIndent(result));
+
+    return nil;
+}
+
+var cli struct {
+    config.ConfigArgs
+    Course string `help:"ID of the course."`
+
+    Set SetGroup `cmd:"" help:"Create (or replace) a group."`
+    Ls ListGroups `cmd:"" help:"List groups."`
+    Rm RmGroup `cmd:"" help:"Remove a group."`
+    Import ImportGroups `cmd:"" help:"Import groups from the course's LMS."`
+}
+
+func main() {
+    context := kong.Parse(&cli,
+        kong.Description("Manage groups of users that submit together."),
+    );
+
+    err := config.HandleConfigArgs(cli.ConfigArgs);
+    if (err != nil) {
+        log.Fatal("Could not load config options.", err);
+    }
+
+    db.MustOpen();
+    defer db.MustClose();
+
+    course := db.MustGetCourse(cli.Course);
+
+    err = context.Run(course);
+    if (err != nil) {
+        log.Fatal("Failed to run command.", err, course);
+    }
+}
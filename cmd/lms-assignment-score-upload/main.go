@@ -15,12 +15,12 @@ var args struct {
     config.ConfigArgs
     Course string `help:"ID of the course." arg:""`
     Assignment string `help:"ID of the assignment." arg:""`
-    DryRun bool `help:"Do not actually upload the grades, just state what you would do." default:"false"`
+    DryRun bool `help:"Do not actually save or upload the grades, just state what you would do." default:"false"`
 }
 
 func main() {
     kong.Parse(&args,
-        kong.Description("Perform a full assignment scoring (including late policy) and upload."),
+        kong.Description("Perform a full assignment scoring (including late policy), save the final scores, and upload them (if the course has an LMS)."),
     );
 
     err := config.HandleConfigArgs(args.ConfigArgs);
@@ -32,9 +32,6 @@ func main() {
     defer db.MustClose();
 
     assignment := db.MustGetAssignment(args.Course, args.Assignment);
-    if (assignment.GetLMSID() == "") {
-        log.Fatal("Assignment has no LMS ID.", assignment);
-    }
 
     err = scoring.FullAssignmentScoringAndUpload(assignment, args.DryRun);
     if (err != nil) {
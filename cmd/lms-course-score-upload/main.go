@@ -14,12 +14,12 @@ import (
 var args struct {
     config.ConfigArgs
     Course string `help:"ID of the course." arg:""`
-    DryRun bool `help:"Do not actually upload the grades, just state what you would do." default:"false"`
+    DryRun bool `help:"Do not actually save or upload the grades, just state what you would do." default:"false"`
 }
 
 func main() {
     kong.Parse(&args,
-        kong.Description("Perform a full course scoring (including late policy) and upload."),
+        kong.Description("Perform a full course scoring (including late policy), save the final scores, and upload them (if the course has an LMS)."),
     );
 
     err := config.HandleConfigArgs(args.ConfigArgs);
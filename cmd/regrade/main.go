@@ -0,0 +1,59 @@
+package main
+
+import (
+    "fmt"
+
+    "github.com/alecthomas/kong"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/grader"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+var args struct {
+    config.ConfigArgs
+    Course string `help:"ID of the course." arg:""`
+    Assignment string `help:"ID of the assignment." arg:""`
+    User string `help:"Only regrade submissions from this user (all users by default)."`
+    All bool `help:"Regrade all submissions instead of just the most recent submission from each user." default:"false"`
+    OutPath string `help:"Option path to output a JSON regrade report." type:"path"`
+}
+
+func main() {
+    kong.Parse(&args,
+        kong.Description("Regrade existing submissions against the current grader and report any score changes." +
+                " Each regrade is saved as a new submission that links back to the original."),
+    );
+
+    err := config.HandleConfigArgs(args.ConfigArgs);
+    if (err != nil) {
+        log.Fatal("Could not load config options.", err);
+    }
+
+    db.MustOpen();
+    defer db.MustClose();
+
+    assignment := db.MustGetAssignment(args.Course, args.Assignment);
+
+    options := grader.RegradeOptions{
+        GradeOptions: grader.GetDefaultGradeOptions(),
+        User: args.User,
+        All: args.All,
+    };
+
+    report, err := grader.Regrade(assignment, options);
+    if (err != nil) {
+        log.Fatal("Failed to regrade submissions.", assignment, err);
+    }
+
+    if (args.OutPath != "") {
+        err = util.ToJSONFileIndent(report, args.OutPath);
+        if (err != nil) {
+            log.Fatal("Failed to output JSON report.", assignment, log.NewAttr("outpath", args.OutPath), err);
+        }
+    }
+
+    fmt.Print(report.String());
+}
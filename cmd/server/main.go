@@ -8,10 +8,12 @@ import (
     "github.com/edulinq/autograder/api"
     "github.com/edulinq/autograder/config"
     "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/procedures"
     "github.com/edulinq/autograder/util"
+    "github.com/edulinq/autograder/webhook"
 )
 
 var args struct {
@@ -53,6 +55,12 @@ func main() {
         }(course);
     }
 
+    // Retry any webhook deliveries that did not finish (in the background).
+    webhook.StartRetries();
+
+    // Send emails through the outbox (in the background).
+    email.StartSender();
+
     // Cleanup any temp dirs.
     defer util.RemoveRecordedTempDirs();
 
@@ -0,0 +1,64 @@
+package main
+
+import (
+    "fmt"
+
+    "github.com/alecthomas/kong"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/similarity"
+    "github.com/edulinq/autograder/util"
+)
+
+var args struct {
+    config.ConfigArgs
+    Course string `help:"ID of the course." arg:""`
+    Assignment string `help:"ID of the assignment." arg:""`
+    Language string `help:"Only compare files of this language (all known languages by default)." default:""`
+    KGramSize int `help:"The number of consecutive tokens hashed together." default:"8"`
+    WindowSize int `help:"The number of consecutive k-grams that each fingerprint is picked from." default:"4"`
+    MinSimilarity float64 `help:"Only report pairs with at least this similarity [0, 1]." default:"0.0"`
+    HTML bool `help:"Output report as html." default:"false"`
+}
+
+func main() {
+    kong.Parse(&args,
+        kong.Description("Compare the most recent submissions of all students for an assignment and report similar pairs." +
+                " Code from the assignment's static files is ignored."),
+    );
+
+    err := config.HandleConfigArgs(args.ConfigArgs);
+    if (err != nil) {
+        log.Fatal("Could not load config options.", err);
+    }
+
+    db.MustOpen();
+    defer db.MustClose();
+
+    assignment := db.MustGetAssignment(args.Course, args.Assignment);
+
+    options := similarity.Options{
+        Language: args.Language,
+        KGramSize: args.KGramSize,
+        WindowSize: args.WindowSize,
+        MinSimilarity: args.MinSimilarity,
+    };
+
+    report, err := similarity.AnalyzeAssignment(assignment, options);
+    if (err != nil) {
+        log.Fatal("Failed to get similarity report.", assignment, err);
+    }
+
+    if (args.HTML) {
+        html, err := report.ToHTML();
+        if (err != nil) {
+            log.Fatal("Failed to generate HTML similarity report.", assignment, err);
+        }
+
+        fmt.Println(html);
+    } else {
+        fmt.Println(util.MustToJSONIndent(report));
+    }
+}
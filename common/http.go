@@ -123,6 +123,23 @@ func postPutWithHeaders(verb string, uri string, form map[string]string, headers
     return doRequest(uri, request, verb, checkResult);
 }
 
+// Post a raw body (e.g., JSON), the content type should be included in the headers.
+// Returns: (body, headers (response), error)
+func PostBodyWithHeaders(uri string, body string, headers map[string][]string) (string, map[string][]string, error) {
+    request, err := http.NewRequest("POST", uri, strings.NewReader(body));
+    if (err != nil) {
+        return "", nil, fmt.Errorf("Failed to create POST request on URL '%s': '%w'.", uri, err);
+    }
+
+    for key, values := range headers {
+        for _, value := range values {
+            request.Header.Add(key, value);
+        }
+    }
+
+    return doRequest(uri, request, "POST", true);
+}
+
 func PostFiles(uri string, form map[string]string, paths []string, checkResult bool) (string, error) {
     var buffer bytes.Buffer;
 
@@ -198,7 +215,8 @@ func doRequest(uri string, request *http.Request, verb string, checkResult bool)
         }
     }
 
-    if (checkResult && (response.StatusCode != http.StatusOK)) {
+    // Any 2xx is a success (some services respond to a POST with a 201 or 204).
+    if (checkResult && ((response.StatusCode < 200) || (response.StatusCode >= 300))) {
         log.Error("Got a non-OK status.",
                 log.NewAttr("code", response.StatusCode), log.NewAttr("body", body),
                 log.NewAttr("headers", response.Header), log.NewAttr("url", uri));
@@ -40,6 +40,7 @@ func MustEnableUnitTestingMode() {
 func EnableUnitTestingMode() error {
     TESTING_MODE.Set(true);
     NO_TASKS.Set(true);
+    WEB_RATE_LIMIT_DISABLE.Set(true);
 
     tempWorkDir, err := util.MkDirTemp("autograder-unit-testing-");
     if (err != nil) {
@@ -67,6 +68,7 @@ func EnableTestingMode() {
     NO_AUTH.Set(true);
     NO_STORE.Set(true);
     NO_TASKS.Set(true);
+    WEB_RATE_LIMIT_DISABLE.Set(true);
 
     DEBUG.Set(true);
     InitLoggingFromConfig();
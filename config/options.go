@@ -28,9 +28,35 @@ var (
     EMAIL_PASS = MustNewStringOption("email.pass", "", "SMTP password for emails sent from the autograder.");
     EMAIL_PORT = MustNewStringOption("email.port", "", "SMTP port for emails sent from the autograder.");
     EMAIL_USER = MustNewStringOption("email.user", "", "SMTP username for emails sent from the autograder.");
+    EMAIL_RATE = MustNewIntOption("email.rate", 40, "The maximum number of emails to send per minute. Zero means no limit.");
+    EMAIL_MAX_ATTEMPTS = MustNewIntOption("email.attempts", 6, "The maximum number of times to try sending a queued email.");
+    EMAIL_BACKOFF_SECS = MustNewIntOption("email.backoff", 60,
+            "The time (in seconds) to wait before retrying a queued email that failed to send. The wait doubles after each failed attempt.");
+    EMAIL_RETENTION_HOURS = MustNewIntOption("email.retention", 7 * 24, "How long (in hours) to keep sent emails in the outbox.");
+    EMAIL_SENSITIVE_ATTEMPTS = MustNewIntOption("email.sensitive.attempts", 3,
+            "The maximum number of times to try sending an email that contains a secret (these are never queued in the outbox).");
+    EMAIL_SENSITIVE_BACKOFF_MS = MustNewIntOption("email.sensitive.backoff", 1000,
+            "The time (in milliseconds) to wait before retrying an email that contains a secret. The wait doubles after each failed attempt.");
 
     // Docker
     DOCKER_DISABLE = MustNewBoolOption("docker.disable", false, "Disable the use of docker (usually for testing).");
+    DOCKER_MAX_RUNTIME_SECS = MustNewIntOption("docker.runtime.max", 10 * 60,
+            "The default maximum time (in seconds) a grading container may run before it is killed." +
+            " Assignments and courses can set their own limit. Zero means no limit.");
+    DOCKER_SANDBOX = MustNewBoolOption("docker.sandbox", false,
+            "When docker is disabled, run graders inside a Linux namespace sandbox instead of directly on the host.");
+    DOCKER_SANDBOX_MAX_FILE_SIZE_MB = MustNewIntOption("docker.sandbox.max-file-size", 256,
+            "The largest file (in MB) that a sandboxed grader can write (zero for no limit).");
+    DOCKER_SANDBOX_PATHS = MustNewStringOption("docker.sandbox.paths", "",
+            "Extra host paths (comma-separated) that are visible (read-only) to sandboxed graders, e.g. a Python install outside of /usr.");
+
+    // Grading Queue
+    GRADER_WORKERS = MustNewIntOption("grader.workers", 4, "The maximum number of submissions that can be graded at the same time on this server.");
+    GRADER_COURSE_WORKERS = MustNewIntOption("grader.course.workers", 0,
+            "The default maximum number of submissions that can be graded at the same time for a single course." +
+            " Courses may override this with their own limit. Zero means courses are only limited by grader.workers.");
+    GRADER_JOB_RETENTION_SECS = MustNewIntOption("grader.job.retention", 60 * 60,
+            "How long (in seconds) a finished grading job will be kept in memory (so its status can be fetched).");
 
     // Tasks
     NO_TASKS = MustNewBoolOption("tasks.disable", false, "Disable all scheduled tasks.");
@@ -42,8 +68,53 @@ var (
     // Server
     WEB_PORT = MustNewIntOption("web.port", 8080, "The port for the web interface to serve on.");
     WEB_MAX_FILE_SIZE_KB = MustNewIntOption("web.maxsizekb", 2 * 1024, "The maximum allowed file size (in KB) submitted via POST request. The default is 2048 KB (2 MB).");
+    WEB_TOKEN_TTL_HOURS = MustNewIntOption("web.token.ttl", 7 * 24, "The default lifetime (in hours) of API tokens issued by the login endpoint.");
+    WEB_PASSWORD_RESET_TTL_MINS = MustNewIntOption("web.password-reset.ttl", 30, "The lifetime (in minutes) of password reset tokens.");
+    WEB_PASSWORD_RESET_MAX_REQUESTS = MustNewIntOption("web.password-reset.max-requests", 3,
+            "The maximum number of password reset requests (and confirmations) allowed for an email within the password reset window.");
+    WEB_PASSWORD_RESET_WINDOW_MINS = MustNewIntOption("web.password-reset.window", 60, "The window (in minutes) used to rate limit password resets.");
+
+    // Rate Limiting
+    WEB_RATE_LIMIT_DISABLE = MustNewBoolOption("web.ratelimit.disable", false, "Disable all API rate limiting and login lockouts.");
+    WEB_RATE_LIMIT_TRUST_PROXY = MustNewBoolOption("web.ratelimit.trust-proxy", false,
+            "Identify clients by the last address in the X-Forwarded-For header (only enable when running behind a reverse proxy).");
+    WEB_RATE_LIMIT_IP_RATE = MustNewIntOption("web.ratelimit.ip.rate", 300, "The number of requests per minute allowed from a single IP address. Zero means no limit.");
+    WEB_RATE_LIMIT_IP_BURST = MustNewIntOption("web.ratelimit.ip.burst", 100, "The number of requests a single IP address can make in a burst.");
+    WEB_RATE_LIMIT_USER_RATE = MustNewIntOption("web.ratelimit.user.rate", 120, "The number of requests per minute allowed for a single (authenticated) user. Zero means no limit.");
+    WEB_RATE_LIMIT_USER_BURST = MustNewIntOption("web.ratelimit.user.burst", 60, "The number of requests a single user can make in a burst.");
+    WEB_RATE_LIMIT_ENDPOINTS = MustNewStringOption("web.ratelimit.endpoints", "submission/submit:6:5,submission/submit/stream:6:5",
+            "Additional per-user limits for specific endpoints, as a comma-separated list of '<endpoint>:<requests per minute>:<burst>'.");
+    WEB_LOGIN_MAX_FAILURES = MustNewIntOption("web.login.max-failures", 5,
+            "The number of failed logins (for a single course user) allowed before the user is locked out. Zero means no lockouts.");
+    WEB_LOGIN_LOCKOUT_SECS = MustNewIntOption("web.login.lockout", 30,
+            "The time (in seconds) a user is locked out after too many failed logins. The lockout doubles after each additional failure.");
+
+    // OIDC
+    OIDC_ISSUER = MustNewStringOption("oidc.issuer", "", "The issuer URL of an OpenID Connect identity provider. Empty disables OIDC login.");
+    OIDC_CLIENT_ID = MustNewStringOption("oidc.client.id", "", "The client ID this server is registered with at the OIDC identity provider.");
+    OIDC_CLIENT_SECRET = MustNewStringOption("oidc.client.secret", "", "The client secret this server is registered with at the OIDC identity provider.");
+    OIDC_REDIRECT_URL = MustNewStringOption("oidc.redirect", "",
+            "The full (external) URL of this server's OIDC callback endpoint, as registered with the identity provider.");
+    OIDC_AUTO_PROVISION = MustNewBoolOption("oidc.provision", false,
+            "Create users that log in with OIDC and are not in the course, but are in the course's LMS roster.");
+
+    // LTI
+    LTI_LAUNCH_URL = MustNewStringOption("lti.launch", "",
+            "The full (external) URL of this server's LTI launch endpoint, as registered with the LMS. Empty disables LTI launches.");
+    LTI_KEY_PATH = MustNewStringOption("lti.key", "",
+            "Path to the PEM-encoded RSA private key this server uses as an LTI tool. Defaults to a key generated inside BASE_DIR.");
+    LTI_AUTO_PROVISION = MustNewBoolOption("lti.provision", false,
+            "Create users that launch the autograder from the LMS and are not in the course (using the roles from the launch).");
+
+    // Webhooks
+    WEBHOOK_MAX_ATTEMPTS = MustNewIntOption("webhook.attempts", 8, "The maximum number of times to try delivering a webhook event.");
+    WEBHOOK_BACKOFF_SECS = MustNewIntOption("webhook.backoff", 30,
+            "The time (in seconds) to wait before retrying a failed webhook delivery. The wait doubles after each failed attempt.");
+    WEBHOOK_TIMEOUT_SECS = MustNewIntOption("webhook.timeout", 10, "The time (in seconds) to wait for a webhook to respond.");
+    WEBHOOK_RETENTION_HOURS = MustNewIntOption("webhook.retention", 7 * 24,
+            "How long (in hours) to keep webhook deliveries after they were delivered (or failed).");
 
     // Database
-    DB_TYPE = MustNewStringOption("db.type", "disk", "The type of database to use.");
+    DB_TYPE = MustNewStringOption("db.type", "disk", "The type of database to use (disk, sqlite, or postgres).");
     DB_PG_URI = MustNewStringOption("db.pg.uri", "", "Connection string to connect to a Postgres Databse. Empty if not using Postgres.");
 )
@@ -13,6 +13,9 @@ import (
 
     "github.com/edulinq/autograder/config"
     "github.com/edulinq/autograder/db/disk"
+    "github.com/edulinq/autograder/db/pg"
+    "github.com/edulinq/autograder/db/sqlite"
+    "github.com/edulinq/autograder/email"
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
 )
@@ -96,6 +99,10 @@ type Backend interface {
     // Get all attempts for a specific user.
     GetSubmissionAttempts(assignment *model.Assignment, email string) ([]*model.GradingResult, error);
 
+    // Get the (sorted) emails of all users that have a submission for this assignment.
+    // This includes users that are no longer in the course.
+    GetSubmissionUsers(assignment *model.Assignment) ([]string, error);
+
     // Get the scoring infos for an assignment for all users that match the given role.
     // A role of model.RoleUnknown means all users.
     // Users without a submission (but with a matching role) will be represented with a nil map value.
@@ -123,6 +130,42 @@ type Backend interface {
     // A nil map should only be returned on error.
     GetRecentSubmissionContents(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.GradingResult, error);
 
+    // Upsert the final (post late policy) scores for an assignment (keyed by email).
+    SaveFinalScores(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error;
+
+    // Get the stored final scores for an assignment (keyed by email).
+    // Users without a final score will not be in the map.
+    GetFinalScores(assignment *model.Assignment) (map[string]*model.ScoringInfo, error);
+
+    // Get the submission each user has selected to be scored for an assignment (short submission ID keyed by email).
+    // Users without a selection will not be in the map.
+    GetSelectedSubmissions(assignment *model.Assignment) (map[string]string, error);
+
+    // Set the submission a user has selected to be scored for an assignment.
+    // An empty submission ID removes the user's selection.
+    SaveSelectedSubmission(assignment *model.Assignment, email string, shortSubmissionID string) error;
+
+    // Get the manual grades for an assignment (keyed by full submission ID).
+    GetManualGrades(assignment *model.Assignment) (map[string]*model.ManualGrade, error);
+
+    // Upsert a manual grade (keyed by full submission ID).
+    SaveManualGrade(assignment *model.Assignment, grade *model.ManualGrade) error;
+
+    // Remove the manual grade for a submission (by full submission ID).
+    // Return a bool indicating whether the manual grade existed.
+    RemoveManualGrade(assignment *model.Assignment, submissionID string) (bool, error);
+
+    // Get the late day balances for a course (keyed by email).
+    // Users without a balance will not be in the map.
+    GetLateDays(course *model.Course) (map[string]*model.LateDaysBalance, error);
+
+    // Upsert the given late day balances (keyed by email) and append the given events to the late day history.
+    SaveLateDays(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error;
+
+    // Get the late day history (in the order events were saved) for a user.
+    // An empty email will get the history for all users.
+    GetLateDaysHistory(course *model.Course, email string) ([]*model.LateDaysEvent, error);
+
     // Record that a task has been completed.
     // The DB is only required to keep the most recently completed task with the given course/ID.
     LogTaskCompletion(courseID string, taskID string, instance time.Time) error;
@@ -131,9 +174,51 @@ type Backend interface {
     // Will return a zero time (time.Time{}).
     GetLastTaskCompletion(courseID string, taskID string) (time.Time, error);
 
+    // Get the last completion time of every task for a course (keyed by task ID).
+    GetTaskCompletions(courseID string) (map[string]time.Time, error);
+
+    // Get all the extensions for a course.
+    GetExtensions(course *model.Course) ([]*model.Extension, error);
+
+    // Upsert an extension.
+    // Extensions are keyed by user and assignment ID (an empty assignment ID is an extension for all assignments).
+    SaveExtension(course *model.Course, extension *model.Extension) error;
+
+    // Remove an extension.
+    // Return a bool indicating whether the extension existed.
+    RemoveExtension(course *model.Course, email string, assignmentID string) (bool, error);
+
+    // Get all the groups for a course (keyed by group ID).
+    GetGroups(course *model.Course) (map[string]*model.Group, error);
+
+    // Upsert groups (keyed by group ID).
+    SaveGroups(course *model.Course, groups []*model.Group) error;
+
+    // Remove a group.
+    // Return a bool indicating whether the group existed.
+    RemoveGroup(course *model.Course, groupID string) (bool, error);
+
+    // Get all the webhook deliveries for a course (in the order they were created).
+    GetWebhookDeliveries(course *model.Course) ([]*model.WebhookDelivery, error);
+
+    // Get the pending webhook deliveries for a course that are due at the given time (in the order they were created).
+    // See model.WebhookDelivery.IsDue().
+    GetDueWebhookDeliveries(course *model.Course, now time.Time) ([]*model.WebhookDelivery, error);
+
+    // Upsert webhook deliveries (keyed by delivery ID).
+    SaveWebhookDeliveries(course *model.Course, deliveries []*model.WebhookDelivery) error;
+
+    // Remove the finished webhook deliveries for a course that finished before the given time
+    // (see model.WebhookDelivery.FinishedUnixMicro()).
+    // Returns the number of removed deliveries.
+    RemoveFinishedWebhookDeliveries(course *model.Course, before time.Time) (int, error);
+
     // DB backends will also be used as logging storage backends.
     log.StorageBackend
 
+    // DB backends will also be used to store the email outbox.
+    email.OutboxBackend
+
     // Get any logs that that match the specific requirements.
     // Each parameter (except for the log level) can be passed with a zero value, in which case it will not be used for filtering.
     GetLogRecords(level log.LogLevel, after time.Time, courseID string, assignmentID string, userID string) ([]*log.Record, error);
@@ -147,23 +232,60 @@ func Open() error {
         return nil;
     }
 
+    newBackend, err := OpenBackend(config.DB_TYPE.Get(), "");
+    if (err != nil) {
+        return fmt.Errorf("Failed to open database: %w.", err);
+    }
+
+    backend = newBackend;
+    log.SetStorageBackend(backend);
+    email.SetOutboxBackend(backend);
+
+    return nil;
+}
+
+// Open a specific backend without making it the main database.
+// The location is interpreted according to the type:
+// a directory for disk, a file for sqlite, and a connection URI for postgres.
+// An empty location will use the same default as Open().
+func OpenBackend(dbType string, location string) (Backend, error) {
+    var newBackend Backend;
     var err error;
-    dbType := config.DB_TYPE.Get();
 
     switch dbType {
         case DB_TYPE_DISK:
-            backend, err = disk.Open();
+            if (location == "") {
+                newBackend, err = disk.Open();
+            } else {
+                newBackend, err = disk.OpenDir(location);
+            }
+        case DB_TYPE_SQLITE:
+            if (location == "") {
+                newBackend, err = sqlite.Open();
+            } else {
+                newBackend, err = sqlite.OpenPath(location);
+            }
+        case DB_TYPE_POSTGRES:
+            if (location == "") {
+                newBackend, err = pg.Open();
+            } else {
+                newBackend, err = pg.OpenURI(location);
+            }
         default:
-            err = fmt.Errorf("Unknown database type: '%s'.", dbType);
+            return nil, fmt.Errorf("Unknown database type: '%s'.", dbType);
     }
 
     if (err != nil) {
-        return fmt.Errorf("Failed to open database: %w.", err);
+        return nil, fmt.Errorf("Failed to open '%s' database: %w.", dbType, err);
     }
 
-    log.SetStorageBackend(backend);
+    err = newBackend.EnsureTables();
+    if (err != nil) {
+        newBackend.Close();
+        return nil, fmt.Errorf("Failed to ensure tables for '%s' database: %w.", dbType, err);
+    }
 
-    return backend.EnsureTables();
+    return newBackend, nil;
 }
 
 func Close() error {
@@ -174,6 +296,8 @@ func Close() error {
         return nil;
     }
 
+    email.SetOutboxBackend(nil);
+
     err := backend.Close();
     backend = nil;
 
@@ -2,6 +2,7 @@ package db
 
 import (
     "fmt"
+    "os"
     "reflect"
     "testing"
 
@@ -9,9 +10,14 @@ import (
     "github.com/edulinq/autograder/log"
 )
 
+// If set, the Postgres backend will also be tested using this connection URI.
+// The database will be cleared during testing, so don't point this at a real database.
+const TEST_PG_URI_ENV = "AUTOGRADER_TEST_PG_URI";
+
 // Backends to put through the standard tests.
 var testBackends []string = []string{
     DB_TYPE_DISK,
+    DB_TYPE_SQLITE,
 };
 
 // Methods attatched to this struct will be called for each backend in testBackends.
@@ -25,6 +31,17 @@ func TestDatabases(test *testing.T) {
     oldType := config.DB_TYPE.Get();
     defer config.DB_TYPE.Set(oldType);
 
+    backends := testBackends;
+
+    pgURI := os.Getenv(TEST_PG_URI_ENV);
+    if (pgURI != "") {
+        oldURI := config.DB_PG_URI.Get();
+        defer config.DB_PG_URI.Set(oldURI);
+
+        config.DB_PG_URI.Set(pgURI);
+        backends = append(backends, DB_TYPE_POSTGRES);
+    }
+
     var dbTests DBTests;
     testMethods, err := getDBTests(&dbTests);
     if (err != nil) {
@@ -36,7 +53,7 @@ func TestDatabases(test *testing.T) {
     // Quiet the logs.
     log.SetLevelFatal();
 
-    for _, dbType := range testBackends {
+    for _, dbType := range backends {
         config.DB_TYPE.Set(dbType);
 
         PrepForTestingMain();
@@ -23,7 +23,11 @@ type backend struct {
 }
 
 func Open() (*backend, error) {
-    baseDir := util.ShouldAbs(filepath.Join(config.GetDatabaseDir(), DB_DIRNAME));
+    return OpenDir(filepath.Join(config.GetDatabaseDir(), DB_DIRNAME));
+}
+
+func OpenDir(baseDir string) (*backend, error) {
+    baseDir = util.ShouldAbs(baseDir);
 
     err := util.MkDir(baseDir);
     if (err != nil) {
@@ -0,0 +1,94 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+    "slices"
+
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_OUTBOX_FILENAME = "outbox.json";
+
+func (this *backend) GetOutboxMessages() ([]*email.OutboxMessage, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getOutboxMessages();
+}
+
+func (this *backend) SaveOutboxMessages(messages []*email.OutboxMessage) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    allMessages, err := this.getOutboxMessages();
+    if (err != nil) {
+        return err;
+    }
+
+    indexes := make(map[string]int, len(allMessages));
+    for i, message := range allMessages {
+        indexes[message.ID] = i;
+    }
+
+    for _, message := range messages {
+        index, ok := indexes[message.ID];
+        if (ok) {
+            allMessages[index] = message;
+            continue;
+        }
+
+        indexes[message.ID] = len(allMessages);
+        allMessages = append(allMessages, message);
+    }
+
+    return this.writeOutboxMessages(allMessages);
+}
+
+func (this *backend) RemoveOutboxMessages(ids []string) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    messages, err := this.getOutboxMessages();
+    if (err != nil) {
+        return err;
+    }
+
+    messages = slices.DeleteFunc(messages, func(message *email.OutboxMessage) bool {
+        return slices.Contains(ids, message.ID);
+    });
+
+    return this.writeOutboxMessages(messages);
+}
+
+func (this *backend) getOutboxPath() string {
+    return filepath.Join(this.baseDir, DISK_DB_OUTBOX_FILENAME);
+}
+
+func (this *backend) getOutboxMessages() ([]*email.OutboxMessage, error) {
+    path := this.getOutboxPath();
+
+    messages := make([]*email.OutboxMessage, 0);
+    if (!util.PathExists(path)) {
+        return messages, nil;
+    }
+
+    err := util.JSONFromFile(path, &messages);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read outbox '%s': '%w'.", path, err);
+    }
+
+    return messages, nil;
+}
+
+func (this *backend) writeOutboxMessages(messages []*email.OutboxMessage) error {
+    path := this.getOutboxPath();
+
+    err := util.ToJSONFileIndent(messages, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write outbox '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
@@ -0,0 +1,98 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_EXTENSIONS_FILENAME = "extensions.json";
+
+func (this *backend) GetExtensions(course *model.Course) ([]*model.Extension, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getExtensions(course);
+}
+
+func (this *backend) SaveExtension(course *model.Course, extension *model.Extension) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    extensions, err := this.getExtensions(course);
+    if (err != nil) {
+        return err;
+    }
+
+    replaced := false;
+    for i, oldExtension := range extensions {
+        if ((oldExtension.User == extension.User) && (oldExtension.AssignmentID == extension.AssignmentID)) {
+            extensions[i] = extension;
+            replaced = true;
+            break;
+        }
+    }
+
+    if (!replaced) {
+        extensions = append(extensions, extension);
+    }
+
+    return this.writeExtensions(course, extensions);
+}
+
+func (this *backend) RemoveExtension(course *model.Course, email string, assignmentID string) (bool, error) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    extensions, err := this.getExtensions(course);
+    if (err != nil) {
+        return false, err;
+    }
+
+    for i, extension := range extensions {
+        if ((extension.User == email) && (extension.AssignmentID == assignmentID)) {
+            extensions = append(extensions[:i], extensions[i + 1:]...);
+            return true, this.writeExtensions(course, extensions);
+        }
+    }
+
+    return false, nil;
+}
+
+func (this *backend) getExtensionsPath(course *model.Course) string {
+    return filepath.Join(this.getCourseDir(course), DISK_DB_EXTENSIONS_FILENAME);
+}
+
+func (this *backend) getExtensions(course *model.Course) ([]*model.Extension, error) {
+    path := this.getExtensionsPath(course);
+
+    extensions := make([]*model.Extension, 0);
+    if (!util.PathExists(path)) {
+        return extensions, nil;
+    }
+
+    err := util.JSONFromFile(path, &extensions);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read extensions '%s': '%w'.", path, err);
+    }
+
+    return extensions, nil;
+}
+
+func (this *backend) writeExtensions(course *model.Course, extensions []*model.Extension) error {
+    path := this.getExtensionsPath(course);
+
+    err := util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for extensions '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(extensions, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write extensions '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
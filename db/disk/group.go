@@ -0,0 +1,89 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_GROUPS_FILENAME = "groups.json";
+
+func (this *backend) GetGroups(course *model.Course) (map[string]*model.Group, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getGroups(course);
+}
+
+func (this *backend) SaveGroups(course *model.Course, groups []*model.Group) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    allGroups, err := this.getGroups(course);
+    if (err != nil) {
+        return err;
+    }
+
+    for _, group := range groups {
+        allGroups[group.ID] = group;
+    }
+
+    return this.writeGroups(course, allGroups);
+}
+
+func (this *backend) RemoveGroup(course *model.Course, groupID string) (bool, error) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    groups, err := this.getGroups(course);
+    if (err != nil) {
+        return false, err;
+    }
+
+    _, ok := groups[groupID];
+    if (!ok) {
+        return false, nil;
+    }
+
+    delete(groups, groupID);
+
+    return true, this.writeGroups(course, groups);
+}
+
+func (this *backend) getGroupsPath(course *model.Course) string {
+    return filepath.Join(this.getCourseDir(course), DISK_DB_GROUPS_FILENAME);
+}
+
+func (this *backend) getGroups(course *model.Course) (map[string]*model.Group, error) {
+    path := this.getGroupsPath(course);
+
+    groups := make(map[string]*model.Group);
+    if (!util.PathExists(path)) {
+        return groups, nil;
+    }
+
+    err := util.JSONFromFile(path, &groups);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read groups '%s': '%w'.", path, err);
+    }
+
+    return groups, nil;
+}
+
+func (this *backend) writeGroups(course *model.Course, groups map[string]*model.Group) error {
+    path := this.getGroupsPath(course);
+
+    err := util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for groups '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(groups, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write groups '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
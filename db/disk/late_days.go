@@ -0,0 +1,113 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    DISK_DB_LATE_DAYS_FILENAME = "late_days.json";
+    DISK_DB_LATE_DAY_EVENTS_FILENAME = "late_day_events.json";
+)
+
+func (this *backend) GetLateDays(course *model.Course) (map[string]*model.LateDaysBalance, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    balances := make(map[string]*model.LateDaysBalance);
+    err := this.readCourseJSON(course, DISK_DB_LATE_DAYS_FILENAME, &balances);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return balances, nil;
+}
+
+func (this *backend) SaveLateDays(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    allBalances := make(map[string]*model.LateDaysBalance);
+    err := this.readCourseJSON(course, DISK_DB_LATE_DAYS_FILENAME, &allBalances);
+    if (err != nil) {
+        return err;
+    }
+
+    allEvents := make([]*model.LateDaysEvent, 0);
+    err = this.readCourseJSON(course, DISK_DB_LATE_DAY_EVENTS_FILENAME, &allEvents);
+    if (err != nil) {
+        return err;
+    }
+
+    for email, balance := range balances {
+        allBalances[email] = balance;
+    }
+
+    allEvents = append(allEvents, events...);
+
+    err = this.writeCourseJSON(course, DISK_DB_LATE_DAYS_FILENAME, allBalances);
+    if (err != nil) {
+        return err;
+    }
+
+    return this.writeCourseJSON(course, DISK_DB_LATE_DAY_EVENTS_FILENAME, allEvents);
+}
+
+func (this *backend) GetLateDaysHistory(course *model.Course, email string) ([]*model.LateDaysEvent, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    allEvents := make([]*model.LateDaysEvent, 0);
+    err := this.readCourseJSON(course, DISK_DB_LATE_DAY_EVENTS_FILENAME, &allEvents);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (email == "") {
+        return allEvents, nil;
+    }
+
+    events := make([]*model.LateDaysEvent, 0);
+    for _, event := range allEvents {
+        if (event.User == email) {
+            events = append(events, event);
+        }
+    }
+
+    return events, nil;
+}
+
+// Read a JSON file in the course's directory.
+// If the file does not exist, the target is left untouched.
+func (this *backend) readCourseJSON(course *model.Course, filename string, target any) error {
+    path := filepath.Join(this.getCourseDir(course), filename);
+    if (!util.PathExists(path)) {
+        return nil;
+    }
+
+    err := util.JSONFromFile(path, target);
+    if (err != nil) {
+        return fmt.Errorf("Failed to read '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) writeCourseJSON(course *model.Course, filename string, data any) error {
+    path := filepath.Join(this.getCourseDir(course), filename);
+
+    err := util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(data, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
@@ -0,0 +1,87 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_MANUAL_GRADES_FILENAME = "manual-grades.json";
+
+func (this *backend) GetManualGrades(assignment *model.Assignment) (map[string]*model.ManualGrade, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getManualGrades(assignment);
+}
+
+func (this *backend) SaveManualGrade(assignment *model.Assignment, grade *model.ManualGrade) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    grades, err := this.getManualGrades(assignment);
+    if (err != nil) {
+        return err;
+    }
+
+    grades[grade.SubmissionID] = grade;
+
+    return this.writeManualGrades(assignment, grades);
+}
+
+func (this *backend) RemoveManualGrade(assignment *model.Assignment, submissionID string) (bool, error) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    grades, err := this.getManualGrades(assignment);
+    if (err != nil) {
+        return false, err;
+    }
+
+    _, ok := grades[submissionID];
+    if (!ok) {
+        return false, nil;
+    }
+
+    delete(grades, submissionID);
+
+    return true, this.writeManualGrades(assignment, grades);
+}
+
+func (this *backend) getManualGradesPath(assignment *model.Assignment) string {
+    return filepath.Join(this.getAssignmentDir(assignment), DISK_DB_MANUAL_GRADES_FILENAME);
+}
+
+func (this *backend) getManualGrades(assignment *model.Assignment) (map[string]*model.ManualGrade, error) {
+    path := this.getManualGradesPath(assignment);
+
+    grades := make(map[string]*model.ManualGrade);
+    if (!util.PathExists(path)) {
+        return grades, nil;
+    }
+
+    err := util.JSONFromFile(path, &grades);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read manual grades '%s': '%w'.", path, err);
+    }
+
+    return grades, nil;
+}
+
+func (this *backend) writeManualGrades(assignment *model.Assignment, grades map[string]*model.ManualGrade) error {
+    path := this.getManualGradesPath(assignment);
+
+    err := util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for manual grades '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(grades, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write manual grades '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
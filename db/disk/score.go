@@ -0,0 +1,66 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_SCORES_FILENAME = "scores.json";
+
+func (this *backend) SaveFinalScores(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    allScores, err := this.getFinalScores(assignment);
+    if (err != nil) {
+        return err;
+    }
+
+    for email, score := range scores {
+        allScores[email] = score;
+    }
+
+    path := this.getFinalScoresPath(assignment);
+
+    err = util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for final scores '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(allScores, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write final scores '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) GetFinalScores(assignment *model.Assignment) (map[string]*model.ScoringInfo, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getFinalScores(assignment);
+}
+
+func (this *backend) getFinalScoresPath(assignment *model.Assignment) string {
+    return filepath.Join(this.getAssignmentDir(assignment), DISK_DB_SCORES_FILENAME);
+}
+
+func (this *backend) getFinalScores(assignment *model.Assignment) (map[string]*model.ScoringInfo, error) {
+    path := this.getFinalScoresPath(assignment);
+
+    scores := make(map[string]*model.ScoringInfo);
+    if (!util.PathExists(path)) {
+        return scores, nil;
+    }
+
+    err := util.JSONFromFile(path, &scores);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read final scores '%s': '%w'.", path, err);
+    }
+
+    return scores, nil;
+}
@@ -0,0 +1,68 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_SELECTED_SUBMISSIONS_FILENAME = "selected-submissions.json";
+
+func (this *backend) GetSelectedSubmissions(assignment *model.Assignment) (map[string]string, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getSelectedSubmissions(assignment);
+}
+
+func (this *backend) SaveSelectedSubmission(assignment *model.Assignment, email string, shortSubmissionID string) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    selections, err := this.getSelectedSubmissions(assignment);
+    if (err != nil) {
+        return err;
+    }
+
+    if (shortSubmissionID == "") {
+        delete(selections, email);
+    } else {
+        selections[email] = shortSubmissionID;
+    }
+
+    path := this.getSelectedSubmissionsPath(assignment);
+
+    err = util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for selected submissions '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(selections, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write selected submissions '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) getSelectedSubmissionsPath(assignment *model.Assignment) string {
+    return filepath.Join(this.getAssignmentDir(assignment), DISK_DB_SELECTED_SUBMISSIONS_FILENAME);
+}
+
+func (this *backend) getSelectedSubmissions(assignment *model.Assignment) (map[string]string, error) {
+    path := this.getSelectedSubmissionsPath(assignment);
+
+    selections := make(map[string]string);
+    if (!util.PathExists(path)) {
+        return selections, nil;
+    }
+
+    err := util.JSONFromFile(path, &selections);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read selected submissions '%s': '%w'.", path, err);
+    }
+
+    return selections, nil;
+}
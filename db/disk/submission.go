@@ -4,9 +4,9 @@ import (
     "fmt"
     "os"
     "path/filepath"
+    "slices"
     "time"
 
-    "github.com/edulinq/autograder/common"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/util"
 )
@@ -18,36 +18,9 @@ func (this *backend) saveSubmissionsLock(course *model.Course, submissions []*mo
     }
 
     for _, submission := range submissions {
-        baseDir := this.getSubmissionDirFromResult(submission.Info);
-        err := util.MkDir(baseDir);
+        err := model.WriteGradingResult(submission, this.getSubmissionDirFromResult(submission.Info));
         if (err != nil) {
-            return fmt.Errorf("Failed to make submission dir '%s': '%w'.", baseDir, err);
-        }
-
-        resultPath := filepath.Join(baseDir, model.SUBMISSION_RESULT_FILENAME);
-        err = util.ToJSONFileIndent(submission.Info, resultPath);
-        if (err != nil) {
-            return fmt.Errorf("Failed to write submission result '%s': '%w'.", resultPath, err);
-        }
-
-        err = util.GzipBytesToDirectory(filepath.Join(baseDir, common.GRADING_INPUT_DIRNAME), submission.InputFilesGZip);
-        if (err != nil) {
-            return fmt.Errorf("Failed to write submission input files: '%w'.", err);
-        }
-
-        err = util.GzipBytesToDirectory(filepath.Join(baseDir, common.GRADING_OUTPUT_DIRNAME), submission.OutputFilesGZip);
-        if (err != nil) {
-            return fmt.Errorf("Failed to write submission input files: '%w'.", err);
-        }
-
-        err = util.WriteFile(submission.Stdout, filepath.Join(baseDir, common.SUBMISSION_STDOUT_FILENAME));
-        if (err != nil) {
-            return fmt.Errorf("Failed to write submission stdout file: '%w'.", err);
-        }
-
-        err = util.WriteFile(submission.Stderr, filepath.Join(baseDir, common.SUBMISSION_STDERR_FILENAME));
-        if (err != nil) {
-            return fmt.Errorf("Failed to write submission stderr file: '%w'.", err);
+            return err;
         }
     }
 
@@ -276,6 +249,32 @@ func (this *backend) getUserSubmissionDir(courseID string, assignmentID string,
     return filepath.Join(this.getCourseDirFromID(courseID), model.SUBMISSIONS_DIRNAME, assignmentID, user);
 }
 
+func (this *backend) GetSubmissionUsers(assignment *model.Assignment) ([]string, error) {
+    emails := make([]string, 0);
+
+    assignmentDir := filepath.Join(this.getCourseDirFromID(assignment.GetCourse().GetID()), model.SUBMISSIONS_DIRNAME, assignment.GetID());
+    if (!util.PathExists(assignmentDir)) {
+        return emails, nil;
+    }
+
+    dirents, err := os.ReadDir(assignmentDir);
+    if (err != nil) {
+        return nil, fmt.Errorf("Unable to read assignment submissions dir '%s': '%w'.", assignmentDir, err);
+    }
+
+    for _, dirent := range dirents {
+        if (!dirent.IsDir()) {
+            continue;
+        }
+
+        emails = append(emails, dirent.Name());
+    }
+
+    slices.Sort(emails);
+
+    return emails, nil;
+}
+
 // Get the short id of the most recent submission (or empty string if there are no submissions).
 func (this *backend) getMostRecentSubmissionID(assignment *model.Assignment, email string) (string, error) {
     submissionsDir := this.getUserSubmissionDir(assignment.GetCourse().GetID(), assignment.GetID(), email);
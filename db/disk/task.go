@@ -46,6 +46,13 @@ func (this *backend) GetLastTaskCompletion(courseID string, taskID string) (time
     return instance, nil;
 }
 
+func (this *backend) GetTaskCompletions(courseID string) (map[string]time.Time, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getTaskLog(courseID);
+}
+
 func (this *backend) getTasksPathFromID(courseID string) string {
     return filepath.Join(this.getCourseDirFromID(courseID), DISK_DB_TASKS_FILENAME);
 }
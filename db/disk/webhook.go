@@ -0,0 +1,128 @@
+package disk
+
+import (
+    "fmt"
+    "path/filepath"
+    "slices"
+    "time"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const DISK_DB_WEBHOOK_DELIVERIES_FILENAME = "webhook_deliveries.json";
+
+func (this *backend) GetWebhookDeliveries(course *model.Course) ([]*model.WebhookDelivery, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    return this.getWebhookDeliveries(course);
+}
+
+func (this *backend) GetDueWebhookDeliveries(course *model.Course, now time.Time) ([]*model.WebhookDelivery, error) {
+    this.lock.RLock();
+    defer this.lock.RUnlock();
+
+    deliveries, err := this.getWebhookDeliveries(course);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    deliveries = slices.DeleteFunc(deliveries, func(delivery *model.WebhookDelivery) bool {
+        return !delivery.IsDue(now);
+    });
+
+    return deliveries, nil;
+}
+
+func (this *backend) SaveWebhookDeliveries(course *model.Course, deliveries []*model.WebhookDelivery) error {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    allDeliveries, err := this.getWebhookDeliveries(course);
+    if (err != nil) {
+        return err;
+    }
+
+    indexes := make(map[string]int, len(allDeliveries));
+    for i, delivery := range allDeliveries {
+        indexes[delivery.ID] = i;
+    }
+
+    for _, delivery := range deliveries {
+        index, ok := indexes[delivery.ID];
+        if (ok) {
+            allDeliveries[index] = delivery;
+            continue;
+        }
+
+        indexes[delivery.ID] = len(allDeliveries);
+        allDeliveries = append(allDeliveries, delivery);
+    }
+
+    return this.writeWebhookDeliveries(course, allDeliveries);
+}
+
+func (this *backend) RemoveFinishedWebhookDeliveries(course *model.Course, before time.Time) (int, error) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    deliveries, err := this.getWebhookDeliveries(course);
+    if (err != nil) {
+        return 0, err;
+    }
+
+    count := len(deliveries);
+    deliveries = slices.DeleteFunc(deliveries, func(delivery *model.WebhookDelivery) bool {
+        finished := delivery.FinishedUnixMicro();
+        return ((finished > 0) && (finished < before.UnixMicro()));
+    });
+
+    removed := count - len(deliveries);
+    if (removed == 0) {
+        return 0, nil;
+    }
+
+    err = this.writeWebhookDeliveries(course, deliveries);
+    if (err != nil) {
+        return 0, err;
+    }
+
+    return removed, nil;
+}
+
+func (this *backend) getWebhookDeliveriesPath(course *model.Course) string {
+    return filepath.Join(this.getCourseDir(course), DISK_DB_WEBHOOK_DELIVERIES_FILENAME);
+}
+
+func (this *backend) getWebhookDeliveries(course *model.Course) ([]*model.WebhookDelivery, error) {
+    path := this.getWebhookDeliveriesPath(course);
+
+    deliveries := make([]*model.WebhookDelivery, 0);
+    if (!util.PathExists(path)) {
+        return deliveries, nil;
+    }
+
+    err := util.JSONFromFile(path, &deliveries);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read webhook deliveries '%s': '%w'.", path, err);
+    }
+
+    return deliveries, nil;
+}
+
+func (this *backend) writeWebhookDeliveries(course *model.Course, deliveries []*model.WebhookDelivery) error {
+    path := this.getWebhookDeliveriesPath(course);
+
+    err := util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return fmt.Errorf("Failed to create directory for webhook deliveries '%s': '%w'.", path, err);
+    }
+
+    err = util.ToJSONFileIndent(deliveries, path);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write webhook deliveries '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
@@ -0,0 +1,40 @@
+package db
+
+// Direct access to the email outbox.
+// Most code should go through the email package (e.g., email.Enqueue() and email.GetOutbox()) instead.
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/email"
+)
+
+func GetOutboxMessages() ([]*email.OutboxMessage, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetOutboxMessages();
+}
+
+func SaveOutboxMessages(messages []*email.OutboxMessage) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    for _, message := range messages {
+        if (message.ID == "") {
+            return fmt.Errorf("Outbox message must have an ID.");
+        }
+    }
+
+    return backend.SaveOutboxMessages(messages);
+}
+
+func RemoveOutboxMessages(ids []string) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.RemoveOutboxMessages(ids);
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestEmailOutboxBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    defer email.ClearTestMessages();
+    email.ClearTestMessages();
+
+    messages, err := email.GetOutbox("", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get initial outbox: '%v'.", err);
+    }
+
+    if (len(messages) != 0) {
+        test.Fatalf("Found initial outbox messages: '%s'.", util.MustToJSONIndent(messages));
+    }
+
+    first, err := email.Enqueue(&email.Message{To: []string{"a@test.com"}, Subject: "first", Body: "first", CourseID: "course101"});
+    if (err != nil) {
+        test.Fatalf("Failed to enqueue first message: '%v'.", err);
+    }
+
+    second, err := email.Enqueue(&email.Message{To: []string{"b@test.com"}, Subject: "second", Body: "second"});
+    if (err != nil) {
+        test.Fatalf("Failed to enqueue second message: '%v'.", err);
+    }
+
+    messages, err = email.GetOutbox("course101", email.OUTBOX_STATUS_PENDING);
+    if (err != nil) {
+        test.Fatalf("Failed to get course outbox: '%v'.", err);
+    }
+
+    if ((len(messages) != 1) || (messages[0].ID != first.ID)) {
+        test.Fatalf("Unexpected course outbox: '%s'.", util.MustToJSONIndent(messages));
+    }
+
+    err = email.SendPending();
+    if (err != nil) {
+        test.Fatalf("Failed to send pending messages: '%v'.", err);
+    }
+
+    if (len(email.GetTestMessages()) != 2) {
+        test.Fatalf("Unexpected number of sent messages. Expected: 2, Actual: %d.", len(email.GetTestMessages()));
+    }
+
+    messages, err = email.GetOutbox("", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get outbox: '%v'.", err);
+    }
+
+    // Messages stay in the order they were created.
+    if ((len(messages) != 2) || (messages[0].ID != first.ID) || (messages[1].ID != second.ID)) {
+        test.Fatalf("Unexpected outbox: '%s'.", util.MustToJSONIndent(messages));
+    }
+
+    for _, message := range messages {
+        if ((message.Status != email.OUTBOX_STATUS_SENT) || (message.Attempts != 1)) {
+            test.Fatalf("Message was not marked as sent: '%s'.", util.MustToJSONIndent(message));
+        }
+    }
+
+    err = SaveOutboxMessages([]*email.OutboxMessage{&email.OutboxMessage{}});
+    if (err == nil) {
+        test.Fatalf("Did not get an error when saving a message without an ID.");
+    }
+
+    err = RemoveOutboxMessages([]string{first.ID});
+    if (err != nil) {
+        test.Fatalf("Failed to remove message: '%v'.", err);
+    }
+
+    messages, err = email.GetOutbox("", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get outbox after removal: '%v'.", err);
+    }
+
+    if ((len(messages) != 1) || (messages[0].ID != second.ID)) {
+        test.Fatalf("Unexpected outbox after removal: '%s'.", util.MustToJSONIndent(messages));
+    }
+}
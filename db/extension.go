@@ -0,0 +1,47 @@
+package db
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+)
+
+func GetExtensions(course *model.Course) ([]*model.Extension, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetExtensions(course);
+}
+
+// Get the effective extensions (keyed by email) for an assignment.
+// See model.ResolveExtensions().
+func GetAssignmentExtensions(assignment *model.Assignment) (map[string]*model.Extension, error) {
+    extensions, err := GetExtensions(assignment.Course);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return model.ResolveExtensions(extensions, assignment.GetID()), nil;
+}
+
+func SaveExtension(course *model.Course, extension *model.Extension) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    err := extension.Validate();
+    if (err != nil) {
+        return fmt.Errorf("Failed to validate extension: '%w'.", err);
+    }
+
+    return backend.SaveExtension(course, extension);
+}
+
+func RemoveExtension(course *model.Course, email string, assignmentID string) (bool, error) {
+    if (backend == nil) {
+        return false, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.RemoveExtension(course, email, assignmentID);
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestExtensionsBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+
+    extensions, err := GetExtensions(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get initial extensions: '%v'.", err);
+    }
+
+    if (len(extensions) != 0) {
+        test.Fatalf("Found initial extensions: '%s'.", util.MustToJSONIndent(extensions));
+    }
+
+    global := &model.Extension{User: "student@test.com", TimeMultiplier: 1.5};
+    specific := &model.Extension{User: "student@test.com", AssignmentID: "hw0", ExtraDays: 2};
+    other := &model.Extension{User: "other@test.com", AssignmentID: "hw0", ExtraDays: 1};
+
+    for _, extension := range []*model.Extension{global, specific, other} {
+        err = SaveExtension(course, extension);
+        if (err != nil) {
+            test.Fatalf("Failed to save extension: '%v'.", err);
+        }
+    }
+
+    // Overwrite an existing extension.
+    specific = &model.Extension{User: "student@test.com", AssignmentID: "hw0", ExtraDays: 3};
+    err = SaveExtension(course, specific);
+    if (err != nil) {
+        test.Fatalf("Failed to overwrite extension: '%v'.", err);
+    }
+
+    extensions, err = GetExtensions(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get extensions: '%v'.", err);
+    }
+
+    if (len(extensions) != 3) {
+        test.Fatalf("Unexpected number of extensions. Expected: 3, Actual: %d.", len(extensions));
+    }
+
+    resolved, err := GetAssignmentExtensions(course.Assignments["hw0"]);
+    if (err != nil) {
+        test.Fatalf("Failed to get assignment extensions: '%v'.", err);
+    }
+
+    expected := map[string]*model.Extension{
+        "student@test.com": &model.Extension{User: "student@test.com", AssignmentID: "hw0", ExtraDays: 3, TimeMultiplier: 1.5},
+        "other@test.com": other,
+    };
+
+    if (util.MustToJSON(expected) != util.MustToJSON(resolved)) {
+        test.Fatalf("Unexpected resolved extensions. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected), util.MustToJSONIndent(resolved));
+    }
+
+    removed, err := RemoveExtension(course, "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to remove extension: '%v'.", err);
+    }
+
+    if (!removed) {
+        test.Fatalf("Existing extension was not removed.");
+    }
+
+    removed, err = RemoveExtension(course, "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to remove missing extension: '%v'.", err);
+    }
+
+    if (removed) {
+        test.Fatalf("Missing extension was reported as removed.");
+    }
+
+    extensions, err = GetExtensions(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get extensions after removal: '%v'.", err);
+    }
+
+    if (len(extensions) != 2) {
+        test.Fatalf("Unexpected number of extensions after removal. Expected: 2, Actual: %d.", len(extensions));
+    }
+}
+
+func (this *DBTests) DBTestExtensionsValidate(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+
+    testCases := []*model.Extension{
+        &model.Extension{User: "student@test.com", AssignmentID: "hw0"},
+        &model.Extension{AssignmentID: "hw0", ExtraDays: 1},
+        &model.Extension{User: "student@test.com", ExtraDays: -1},
+        &model.Extension{User: "student@test.com", TimeMultiplier: 0.5},
+        &model.Extension{User: "student@test.com", DueDate: "2024-01-01T00:00:00Z"},
+    };
+
+    for i, testCase := range testCases {
+        err := SaveExtension(course, testCase);
+        if (err == nil) {
+            test.Errorf("Case %d: Did not get an error on a bad extension.", i);
+        }
+    }
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
+)
+
+func GetGroups(course *model.Course) (map[string]*model.Group, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetGroups(course);
+}
+
+// Upsert groups.
+// Groups are validated, and no user may end up in more than one group.
+func SaveGroups(course *model.Course, groups []*model.Group) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    allGroups, err := backend.GetGroups(course);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get existing groups: '%w'.", err);
+    }
+
+    for _, group := range groups {
+        err = group.Validate();
+        if (err != nil) {
+            return err;
+        }
+
+        allGroups[group.ID] = group;
+    }
+
+    err = model.ValidateGroupMembership(allGroups);
+    if (err != nil) {
+        return err;
+    }
+
+    return backend.SaveGroups(course, groups);
+}
+
+func SaveGroup(course *model.Course, group *model.Group) error {
+    return SaveGroups(course, []*model.Group{group});
+}
+
+func RemoveGroup(course *model.Course, groupID string) (bool, error) {
+    if (backend == nil) {
+        return false, fmt.Errorf("Database has not been opened.");
+    }
+
+    // An invalid ID cannot match an existing group.
+    groupID, err := common.ValidateID(groupID);
+    if (err != nil) {
+        return false, nil;
+    }
+
+    return backend.RemoveGroup(course, groupID);
+}
+
+// Get the group a user is in.
+// Returns nil if the user is not in a group.
+func GetUserGroup(course *model.Course, email string) (*model.Group, error) {
+    groups, err := GetGroups(course);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for _, group := range groups {
+        if (group.HasMember(email)) {
+            return group, nil;
+        }
+    }
+
+    return nil, nil;
+}
+
+// Get the group that a user submits with for an assignment.
+// Returns nil if the assignment does not have group submissions or the user is not in a group.
+func GetSubmissionGroup(assignment *model.Assignment, email string) (*model.Group, error) {
+    if (!assignment.GroupSubmissions) {
+        return nil, nil;
+    }
+
+    return GetUserGroup(assignment.GetCourse(), email);
+}
+
+// Get the users whose submissions are shared with the given user for an assignment
+// (always including the user themselves).
+func getSubmissionMembers(assignment *model.Assignment, email string) ([]string, error) {
+    group, err := GetSubmissionGroup(assignment, email);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get group for user '%s': '%w'.", email, err);
+    }
+
+    if (group == nil) {
+        return []string{email}, nil;
+    }
+
+    return group.Members, nil;
+}
+
+// Get the user that actually made a submission that is shared with the given user.
+// An empty submission ID refers to the most recent submission (which is also resolved to a specific ID, see getMostRecentHistoryItem()).
+// If the submission cannot be found, then the given user and ID will be returned.
+func getSubmissionOwner(assignment *model.Assignment, email string, shortSubmissionID string) (string, string, error) {
+    members, err := getSubmissionMembers(assignment, email);
+    if (err != nil) {
+        return "", "", err;
+    }
+
+    if ((len(members) == 1) && (shortSubmissionID != "")) {
+        return email, shortSubmissionID, nil;
+    }
+
+    history, err := GetSubmissionHistory(assignment, email);
+    if (err != nil) {
+        return "", "", fmt.Errorf("Failed to get submission history: '%w'.", err);
+    }
+
+    if (shortSubmissionID == "") {
+        item := getMostRecentHistoryItem(history);
+        if (item == nil) {
+            return email, "", nil;
+        }
+
+        return item.User, item.ShortID, nil;
+    }
+
+    // Prefer the user's own submission (in case group members have submissions with the same ID).
+    owner := "";
+    for _, item := range history {
+        if (item.ShortID != shortSubmissionID) {
+            continue;
+        }
+
+        if ((owner == "") || (item.User == email)) {
+            owner = item.User;
+        }
+    }
+
+    if (owner == "") {
+        return email, shortSubmissionID, nil;
+    }
+
+    return owner, shortSubmissionID, nil;
+}
+
+// For assignments with group submissions, replace the entry of every group member in results
+// with the group's most recent submission (as provided by fetch).
+// Only users already in results are touched.
+func shareGroupSubmissions[T any](assignment *model.Assignment, results map[string]*T, fetch func(email string) (*T, error)) error {
+    if (!assignment.GroupSubmissions) {
+        return nil;
+    }
+
+    groups, err := GetGroups(assignment.GetCourse());
+    if (err != nil) {
+        return fmt.Errorf("Failed to get groups: '%w'.", err);
+    }
+
+    for _, group := range groups {
+        for _, member := range group.Members {
+            _, ok := results[member];
+            if (!ok) {
+                continue;
+            }
+
+            // Fetch for each member so callers are free to modify entries independently.
+            result, err := fetch(member);
+            if (err != nil) {
+                return fmt.Errorf("Failed to get group submission for user '%s': '%w'.", member, err);
+            }
+
+            results[member] = result;
+        }
+    }
+
+    return nil;
+}
+
+// Short submission IDs are timestamps, compare them as numbers (shorter is smaller).
+func compareShortSubmissionIDs(a string, b string) int {
+    if (len(a) != len(b)) {
+        return len(a) - len(b);
+    }
+
+    return strings.Compare(a, b);
+}
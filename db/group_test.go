@@ -0,0 +1,177 @@
+package db
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestGroupsBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+
+    groups, err := GetGroups(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get initial groups: '%v'.", err);
+    }
+
+    if (len(groups) != 0) {
+        test.Fatalf("Found initial groups: '%s'.", util.MustToJSONIndent(groups));
+    }
+
+    err = SaveGroups(course, []*model.Group{
+        &model.Group{ID: "Team1", Name: "Team 1", Members: []string{"student@test.com", "other@test.com"}},
+        &model.Group{ID: "team2", Members: []string{"grader@test.com"}},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save groups: '%v'.", err);
+    }
+
+    // A user cannot be in two groups.
+    err = SaveGroup(course, &model.Group{ID: "team3", Members: []string{"student@test.com"}});
+    if (err == nil) {
+        test.Fatalf("Did not get an error when putting a user in two groups.");
+    }
+
+    // Replace an existing group.
+    err = SaveGroup(course, &model.Group{ID: "team2", Members: []string{"grader@test.com", "admin@test.com"}});
+    if (err != nil) {
+        test.Fatalf("Failed to replace group: '%v'.", err);
+    }
+
+    groups, err = GetGroups(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get groups: '%v'.", err);
+    }
+
+    expected := map[string]*model.Group{
+        "team1": &model.Group{ID: "team1", Name: "Team 1", Members: []string{"other@test.com", "student@test.com"}},
+        "team2": &model.Group{ID: "team2", Members: []string{"admin@test.com", "grader@test.com"}},
+    };
+
+    if (util.MustToJSON(expected) != util.MustToJSON(groups)) {
+        test.Fatalf("Unexpected groups. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(groups));
+    }
+
+    group, err := GetUserGroup(course, "other@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user group: '%v'.", err);
+    }
+
+    if ((group == nil) || (group.ID != "team1")) {
+        test.Fatalf("Unexpected user group: '%s'.", util.MustToJSONIndent(group));
+    }
+
+    removed, err := RemoveGroup(course, "team1");
+    if (err != nil) {
+        test.Fatalf("Failed to remove group: '%v'.", err);
+    }
+
+    if (!removed) {
+        test.Fatalf("Existing group was not removed.");
+    }
+
+    removed, err = RemoveGroup(course, "team1");
+    if (err != nil) {
+        test.Fatalf("Failed to remove missing group: '%v'.", err);
+    }
+
+    if (removed) {
+        test.Fatalf("Missing group was removed.");
+    }
+
+    group, err = GetUserGroup(course, "other@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user group after removal: '%v'.", err);
+    }
+
+    if (group != nil) {
+        test.Fatalf("User still has a group after removal: '%s'.", util.MustToJSONIndent(group));
+    }
+}
+
+// Only student@test.com has submissions, but other@test.com shares them through their group.
+func (this *DBTests) DBTestGroupSubmissions(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+    assignment := course.Assignments["hw0"];
+
+    err := SaveGroup(course, &model.Group{ID: "team1", Members: []string{"student@test.com", "other@test.com"}});
+    if (err != nil) {
+        test.Fatalf("Failed to save group: '%v'.", err);
+    }
+
+    // Without group submissions, nothing is shared.
+    history, err := GetSubmissionHistory(assignment, "other@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get history without group submissions: '%v'.", err);
+    }
+
+    if (len(history) != 0) {
+        test.Fatalf("Found shared history without group submissions: '%s'.", util.MustToJSONIndent(history));
+    }
+
+    assignment.GroupSubmissions = true;
+
+    history, err = GetSubmissionHistory(assignment, "other@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get group history: '%v'.", err);
+    }
+
+    if ((len(history) != 3) || (history[2].ShortID != "1697406272")) {
+        test.Fatalf("Unexpected group history: '%s'.", util.MustToJSONIndent(history));
+    }
+
+    result, err := GetSubmissionResult(assignment, "other@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to get group submission result: '%v'.", err);
+    }
+
+    if ((result == nil) || (result.ShortID != "1697406272") || (result.User != "student@test.com")) {
+        test.Fatalf("Unexpected group submission result: '%s'.", util.MustToJSONIndent(result));
+    }
+
+    recent, err := GetRecentSubmissions(assignment, model.RoleUnknown);
+    if (err != nil) {
+        test.Fatalf("Failed to get recent submissions: '%v'.", err);
+    }
+
+    if ((recent["other@test.com"] == nil) || (recent["other@test.com"].ShortID != "1697406272")) {
+        test.Fatalf("Group member did not get the group's recent submission: '%s'.", util.MustToJSONIndent(recent));
+    }
+
+    // Every member gets the same score.
+    scoringInfos, err := GetScoringInfos(assignment, model.RoleUnknown, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scoring infos: '%v'.", err);
+    }
+
+    if (util.MustToJSON(scoringInfos["other@test.com"]) != util.MustToJSON(scoringInfos["student@test.com"])) {
+        test.Fatalf("Group members have different scoring infos. Student: '%s', Other: '%s'.",
+                util.MustToJSONIndent(scoringInfos["student@test.com"]), util.MustToJSONIndent(scoringInfos["other@test.com"]));
+    }
+
+    // Any member can remove a group submission.
+    removed, err := RemoveSubmission(assignment, "other@test.com", "1697406265");
+    if (err != nil) {
+        test.Fatalf("Failed to remove group submission: '%v'.", err);
+    }
+
+    if (!removed) {
+        test.Fatalf("Group submission was not removed.");
+    }
+
+    history, err = GetSubmissionHistory(assignment, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get history after removal: '%v'.", err);
+    }
+
+    if (len(history) != 2) {
+        test.Fatalf("Unexpected history after removal: '%s'.", util.MustToJSONIndent(history));
+    }
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+)
+
+func GetLateDays(course *model.Course) (map[string]*model.LateDaysBalance, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetLateDays(course);
+}
+
+// Get a single user's late day balance.
+// Returns nil if the user does not have a balance.
+func GetUserLateDays(course *model.Course, email string) (*model.LateDaysBalance, error) {
+    balances, err := GetLateDays(course);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return balances[email], nil;
+}
+
+func SaveLateDays(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.SaveLateDays(course, balances, events);
+}
+
+func GetLateDaysHistory(course *model.Course, email string) ([]*model.LateDaysEvent, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetLateDaysHistory(course, email);
+}
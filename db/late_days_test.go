@@ -0,0 +1,77 @@
+package db
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestLateDaysBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+
+    balances, err := GetLateDays(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get initial late days: '%v'.", err);
+    }
+
+    if (len(balances) != 0) {
+        test.Fatalf("Found initial late days: '%s'.", util.MustToJSONIndent(balances));
+    }
+
+    student := model.NewLateDaysBalance("student@test.com", 5);
+    other := model.NewLateDaysBalance("other@test.com", 5);
+
+    events := []*model.LateDaysEvent{
+        student.Allocate("hw0", 2, model.LATE_DAYS_SOURCE_SCORING, ""),
+        other.Adjust(1, model.LATE_DAYS_SOURCE_ADJUSTMENT, "admin@test.com", "Illness"),
+        student.Allocate("hw0", 1, model.LATE_DAYS_SOURCE_SCORING, ""),
+    };
+
+    err = SaveLateDays(course, map[string]*model.LateDaysBalance{student.User: student, other.User: other}, events[0:2]);
+    if (err != nil) {
+        test.Fatalf("Failed to save late days: '%v'.", err);
+    }
+
+    err = SaveLateDays(course, map[string]*model.LateDaysBalance{student.User: student}, events[2:]);
+    if (err != nil) {
+        test.Fatalf("Failed to save more late days: '%v'.", err);
+    }
+
+    balance, err := GetUserLateDays(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user late days: '%v'.", err);
+    }
+
+    if ((balance == nil) || (balance.AvailableDays != 4) || (balance.AllocatedDays["hw0"] != 1)) {
+        test.Fatalf("Unexpected late days balance: '%s'.", util.MustToJSONIndent(balance));
+    }
+
+    history, err := GetLateDaysHistory(course, "");
+    if (err != nil) {
+        test.Fatalf("Failed to get full history: '%v'.", err);
+    }
+
+    if (util.MustToJSON(events) != util.MustToJSON(history)) {
+        test.Fatalf("Unexpected full history. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(events), util.MustToJSONIndent(history));
+    }
+
+    history, err = GetLateDaysHistory(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get user history: '%v'.", err);
+    }
+
+    expected := []*model.LateDaysEvent{events[0], events[2]};
+    if (util.MustToJSON(expected) != util.MustToJSON(history)) {
+        test.Fatalf("Unexpected user history. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected), util.MustToJSONIndent(history));
+    }
+
+    if ((history[0].Change != -2) || (history[1].Change != 1) || (history[1].AvailableDays != 4)) {
+        test.Fatalf("Unexpected event changes: '%s'.", util.MustToJSONIndent(history));
+    }
+}
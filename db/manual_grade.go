@@ -0,0 +1,121 @@
+package db
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
+)
+
+// Get the manual grades for an assignment (keyed by full submission ID).
+func GetManualGrades(assignment *model.Assignment) (map[string]*model.ManualGrade, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetManualGrades(assignment);
+}
+
+// Get the manual grade for a submission (by full submission ID).
+// Returns nil if the submission does not have a manual grade.
+func GetManualGrade(assignment *model.Assignment, submissionID string) (*model.ManualGrade, error) {
+    grades, err := GetManualGrades(assignment);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return grades[submissionID], nil;
+}
+
+// Get the manual grade that applies to a submission.
+// Regrades keep the manual grade of the submission they regraded (unless they have been manually graded themselves),
+// so a submission without its own manual grade will use the manual grade of the submission it regraded (and so on).
+// Returns nil if no manual grade applies.
+func GetSubmissionManualGrade(assignment *model.Assignment, submission *model.GradingInfo) (*model.ManualGrade, error) {
+    grades, err := GetManualGrades(assignment);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return getSubmissionManualGrade(assignment, grades, submission);
+}
+
+func getSubmissionManualGrade(assignment *model.Assignment, grades map[string]*model.ManualGrade, submission *model.GradingInfo) (*model.ManualGrade, error) {
+    seenIDs := make(map[string]bool);
+
+    for (submission != nil) {
+        grade := grades[submission.ID];
+        if (grade != nil) {
+            return grade, nil;
+        }
+
+        if ((submission.RegradeOf == "") || seenIDs[submission.RegradeOf]) {
+            return nil, nil;
+        }
+
+        seenIDs[submission.ID] = true;
+
+        parts := common.SplitFullSubmissionID(submission.RegradeOf);
+        if (len(parts) != 4) {
+            return nil, fmt.Errorf("Submission '%s' is a regrade of a malformed submission ID: '%s'.", submission.ID, submission.RegradeOf);
+        }
+
+        original, err := GetSubmissionResult(assignment, parts[2], parts[3]);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to get regraded submission '%s': '%w'.", submission.RegradeOf, err);
+        }
+
+        submission = original;
+    }
+
+    return nil, nil;
+}
+
+func SaveManualGrade(assignment *model.Assignment, grade *model.ManualGrade) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    err := grade.Validate();
+    if (err != nil) {
+        return err;
+    }
+
+    return backend.SaveManualGrade(assignment, grade);
+}
+
+func RemoveManualGrade(assignment *model.Assignment, submissionID string) (bool, error) {
+    if (backend == nil) {
+        return false, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.RemoveManualGrade(assignment, submissionID);
+}
+
+// Add the points from manual grades to the score of each submission (in place).
+// See GetSubmissionManualGrade() for how regrades are handled.
+func applyManualGrades(assignment *model.Assignment, submissions map[string]*model.GradingInfo) error {
+    grades, err := GetManualGrades(assignment);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get manual grades: '%w'.", err);
+    }
+
+    if (len(grades) == 0) {
+        return nil;
+    }
+
+    for _, submission := range submissions {
+        if (submission == nil) {
+            continue;
+        }
+
+        grade, err := getSubmissionManualGrade(assignment, grades, submission);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get manual grade for submission '%s': '%w'.", submission.ID, err);
+        }
+
+        submission.Score += grade.Points();
+    }
+
+    return nil;
+}
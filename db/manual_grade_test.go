@@ -0,0 +1,160 @@
+package db
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestManualGradesBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    assignment := MustGetTestAssignment();
+    submissionID := "course101::hw0::student@test.com::1697406272";
+
+    grades, err := GetManualGrades(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get initial manual grades: '%v'.", err);
+    }
+
+    if (len(grades) != 0) {
+        test.Fatalf("Found initial manual grades: '%s'.", util.MustToJSONIndent(grades));
+    }
+
+    // Invalid grades are not saved.
+    err = SaveManualGrade(assignment, &model.ManualGrade{SubmissionID: submissionID});
+    if (err == nil) {
+        test.Fatalf("Did not get an error when saving an empty manual grade.");
+    }
+
+    grade := &model.ManualGrade{
+        SubmissionID: submissionID,
+        Items: []*model.ManualGradeItem{
+            &model.ManualGradeItem{Name: "Style", Points: 1.5},
+            &model.ManualGradeItem{Name: "Report", Points: -0.5},
+        },
+    };
+
+    err = SaveManualGrade(assignment, grade);
+    if (err != nil) {
+        test.Fatalf("Failed to save manual grade: '%v'.", err);
+    }
+
+    savedGrade, err := GetManualGrade(assignment, submissionID);
+    if (err != nil) {
+        test.Fatalf("Failed to get manual grade: '%v'.", err);
+    }
+
+    if (util.MustToJSON(grade) != util.MustToJSON(savedGrade)) {
+        test.Fatalf("Unexpected manual grade. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(grade), util.MustToJSONIndent(savedGrade));
+    }
+
+    // The scored submission includes the manual points (the raw score is 2).
+    submissions, err := GetScoredSubmissions(assignment, model.RoleStudent, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scored submissions: '%v'.", err);
+    }
+
+    if (!util.IsClose(submissions["student@test.com"].Score, 3.0)) {
+        test.Fatalf("Unexpected scored submission: '%s'.", util.MustToJSONIndent(submissions["student@test.com"]));
+    }
+
+    removed, err := RemoveManualGrade(assignment, submissionID);
+    if (err != nil) {
+        test.Fatalf("Failed to remove manual grade: '%v'.", err);
+    }
+
+    if (!removed) {
+        test.Fatalf("Existing manual grade was not removed.");
+    }
+
+    removed, err = RemoveManualGrade(assignment, submissionID);
+    if (err != nil) {
+        test.Fatalf("Failed to remove missing manual grade: '%v'.", err);
+    }
+
+    if (removed) {
+        test.Fatalf("Missing manual grade was removed.");
+    }
+}
+
+// Regrades (and regrades of regrades) keep the manual grade of the submission they regraded.
+func (this *DBTests) DBTestManualGradesRegrade(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    assignment := MustGetTestAssignment();
+    submissionID := "course101::hw0::student@test.com::1697406272";
+
+    err := SaveManualGrade(assignment, &model.ManualGrade{
+        SubmissionID: submissionID,
+        Items: []*model.ManualGradeItem{&model.ManualGradeItem{Name: "Style", Points: 1.0}},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save manual grade: '%v'.", err);
+    }
+
+    regradeOf := submissionID;
+    for i := 0; i < 2; i++ {
+        submission, err := GetSubmissionContents(assignment, "student@test.com", regradeOf);
+        if (err != nil) {
+            test.Fatalf("Regrade %d: Failed to get submission: '%v'.", i, err);
+        }
+
+        shortID, err := GetNextSubmissionID(assignment, "student@test.com");
+        if (err != nil) {
+            test.Fatalf("Regrade %d: Failed to get next submission ID: '%v'.", i, err);
+        }
+
+        submission.Info.ShortID = shortID;
+        submission.Info.ID = common.CreateFullSubmissionID(assignment.GetCourse().GetID(), assignment.GetID(), "student@test.com", shortID);
+        submission.Info.RegradeOf = regradeOf;
+
+        err = SaveSubmission(assignment, submission);
+        if (err != nil) {
+            test.Fatalf("Regrade %d: Failed to save submission: '%v'.", i, err);
+        }
+
+        regradeOf = submission.Info.ID;
+
+        // The regrade is now the scored submission, and still includes the manual points (the raw score is 2).
+        submissions, err := GetScoredSubmissions(assignment, model.RoleStudent, nil);
+        if (err != nil) {
+            test.Fatalf("Regrade %d: Failed to get scored submissions: '%v'.", i, err);
+        }
+
+        if ((submissions["student@test.com"].ID != regradeOf) || !util.IsClose(submissions["student@test.com"].Score, 3.0)) {
+            test.Fatalf("Regrade %d: Unexpected scored submission: '%s'.", i, util.MustToJSONIndent(submissions["student@test.com"]));
+        }
+
+        grade, err := GetSubmissionManualGrade(assignment, submissions["student@test.com"]);
+        if (err != nil) {
+            test.Fatalf("Regrade %d: Failed to get manual grade: '%v'.", i, err);
+        }
+
+        if ((grade == nil) || (grade.SubmissionID != submissionID)) {
+            test.Fatalf("Regrade %d: Unexpected manual grade: '%s'.", i, util.MustToJSONIndent(grade));
+        }
+    }
+
+    // A manual grade on the regrade itself takes precedence.
+    err = SaveManualGrade(assignment, &model.ManualGrade{
+        SubmissionID: regradeOf,
+        Items: []*model.ManualGradeItem{&model.ManualGradeItem{Name: "Style", Points: 2.0}},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save regrade manual grade: '%v'.", err);
+    }
+
+    submissions, err := GetScoredSubmissions(assignment, model.RoleStudent, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scored submissions: '%v'.", err);
+    }
+
+    if (!util.IsClose(submissions["student@test.com"].Score, 4.0)) {
+        test.Fatalf("Unexpected scored submission: '%s'.", util.MustToJSONIndent(submissions["student@test.com"]));
+    }
+}
@@ -0,0 +1,591 @@
+package db
+
+// Support for moving all data between two database backends.
+// Unlike the rest of this package, these functions work on explicit backends instead of the open database.
+
+import (
+    "crypto/sha256"
+    "fmt"
+    "hash"
+    "slices"
+    "strings"
+    "time"
+
+    "golang.org/x/exp/maps"
+
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    MIGRATE_CATEGORY_COURSES = "courses"
+    MIGRATE_CATEGORY_ASSIGNMENTS = "assignments"
+    MIGRATE_CATEGORY_USERS = "users"
+    MIGRATE_CATEGORY_SUBMISSIONS = "submissions"
+    MIGRATE_CATEGORY_SCORES = "final-scores"
+    MIGRATE_CATEGORY_SELECTED_SUBMISSIONS = "selected-submissions"
+    MIGRATE_CATEGORY_MANUAL_GRADES = "manual-grades"
+    MIGRATE_CATEGORY_TASKS = "task-completions"
+    MIGRATE_CATEGORY_EXTENSIONS = "extensions"
+    MIGRATE_CATEGORY_GROUPS = "groups"
+    MIGRATE_CATEGORY_LATE_DAYS = "late-days"
+    MIGRATE_CATEGORY_LATE_DAY_EVENTS = "late-day-events"
+    MIGRATE_CATEGORY_WEBHOOK_DELIVERIES = "webhook-deliveries"
+    MIGRATE_CATEGORY_EMAIL_OUTBOX = "email-outbox"
+    MIGRATE_CATEGORY_LOGS = "log-records"
+)
+
+var migrateCategories = []string{
+    MIGRATE_CATEGORY_COURSES,
+    MIGRATE_CATEGORY_ASSIGNMENTS,
+    MIGRATE_CATEGORY_USERS,
+    MIGRATE_CATEGORY_SUBMISSIONS,
+    MIGRATE_CATEGORY_SCORES,
+    MIGRATE_CATEGORY_SELECTED_SUBMISSIONS,
+    MIGRATE_CATEGORY_MANUAL_GRADES,
+    MIGRATE_CATEGORY_TASKS,
+    MIGRATE_CATEGORY_EXTENSIONS,
+    MIGRATE_CATEGORY_GROUPS,
+    MIGRATE_CATEGORY_LATE_DAYS,
+    MIGRATE_CATEGORY_LATE_DAY_EVENTS,
+    MIGRATE_CATEGORY_WEBHOOK_DELIVERIES,
+    MIGRATE_CATEGORY_EMAIL_OUTBOX,
+    MIGRATE_CATEGORY_LOGS,
+};
+
+// The number of objects and a checksum over all the objects for each category of data in a backend.
+type BackendSummary struct {
+    Counts map[string]int `json:"counts"`
+    Checksums map[string]string `json:"checksums"`
+}
+
+// Callbacks used when walking all the data in a backend.
+// Each object is visited in a stable order.
+type backendVisitor struct {
+    Course func(course *model.Course) error
+    Users func(course *model.Course, users map[string]*model.User) error
+    Submissions func(course *model.Course, submissions []*model.GradingResult) error
+    Scores func(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error
+    SelectedSubmissions func(assignment *model.Assignment, selections map[string]string) error
+    ManualGrades func(assignment *model.Assignment, grades map[string]*model.ManualGrade) error
+    Tasks func(course *model.Course, completions map[string]int64) error
+    Extensions func(course *model.Course, extensions []*model.Extension) error
+    Groups func(course *model.Course, groups []*model.Group) error
+    LateDays func(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error
+    WebhookDeliveries func(course *model.Course, deliveries []*model.WebhookDelivery) error
+    Outbox func(messages []*email.OutboxMessage) error
+    Logs func(records []*log.Record) error
+}
+
+// Copy all data (courses, assignments, users, submissions, final scores, selected submissions, manual grades, task completions, extensions, groups, late days, webhook deliveries, email outbox, and log records) from one backend to another.
+// The target should be empty (log records are always appended).
+// After copying, both backends are summarized and an error is returned if the summaries do not match.
+func Migrate(source Backend, target Backend) (*BackendSummary, *BackendSummary, error) {
+    visitor := backendVisitor{
+        Course: func(course *model.Course) error {
+            return target.SaveCourse(course);
+        },
+        Users: func(course *model.Course, users map[string]*model.User) error {
+            return target.SaveUsers(course, users);
+        },
+        Submissions: func(course *model.Course, submissions []*model.GradingResult) error {
+            return target.SaveSubmissions(course, submissions);
+        },
+        Scores: func(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error {
+            return target.SaveFinalScores(assignment, scores);
+        },
+        SelectedSubmissions: func(assignment *model.Assignment, selections map[string]string) error {
+            emails := maps.Keys(selections);
+            slices.Sort(emails);
+
+            for _, email := range emails {
+                err := target.SaveSelectedSubmission(assignment, email, selections[email]);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        ManualGrades: func(assignment *model.Assignment, grades map[string]*model.ManualGrade) error {
+            submissionIDs := maps.Keys(grades);
+            slices.Sort(submissionIDs);
+
+            for _, submissionID := range submissionIDs {
+                err := target.SaveManualGrade(assignment, grades[submissionID]);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Tasks: func(course *model.Course, completions map[string]int64) error {
+            taskIDs := maps.Keys(completions);
+            slices.Sort(taskIDs);
+
+            for _, taskID := range taskIDs {
+                err := target.LogTaskCompletion(course.GetID(), taskID, time.Unix(0, completions[taskID]));
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Extensions: func(course *model.Course, extensions []*model.Extension) error {
+            for _, extension := range extensions {
+                err := target.SaveExtension(course, extension);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Groups: func(course *model.Course, groups []*model.Group) error {
+            return target.SaveGroups(course, groups);
+        },
+        LateDays: func(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error {
+            return target.SaveLateDays(course, balances, events);
+        },
+        WebhookDeliveries: func(course *model.Course, deliveries []*model.WebhookDelivery) error {
+            return target.SaveWebhookDeliveries(course, deliveries);
+        },
+        Outbox: func(messages []*email.OutboxMessage) error {
+            return target.SaveOutboxMessages(messages);
+        },
+        Logs: func(records []*log.Record) error {
+            for _, record := range records {
+                err := target.LogDirect(record);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+    };
+
+    err := walkBackend(source, visitor);
+    if (err != nil) {
+        return nil, nil, fmt.Errorf("Failed to copy data: '%w'.", err);
+    }
+
+    sourceSummary, err := SummarizeBackend(source);
+    if (err != nil) {
+        return nil, nil, fmt.Errorf("Failed to summarize source database: '%w'.", err);
+    }
+
+    targetSummary, err := SummarizeBackend(target);
+    if (err != nil) {
+        return sourceSummary, nil, fmt.Errorf("Failed to summarize target database: '%w'.", err);
+    }
+
+    mismatches := sourceSummary.Compare(targetSummary);
+    if (len(mismatches) > 0) {
+        return sourceSummary, targetSummary, fmt.Errorf("Source and target databases do not match on: [%s].", strings.Join(mismatches, ", "));
+    }
+
+    return sourceSummary, targetSummary, nil;
+}
+
+// Count and checksum all the data in a backend.
+func SummarizeBackend(backend Backend) (*BackendSummary, error) {
+    hashes := make(map[string]hash.Hash, len(migrateCategories));
+    summary := &BackendSummary{
+        Counts: make(map[string]int, len(migrateCategories)),
+        Checksums: make(map[string]string, len(migrateCategories)),
+    };
+
+    for _, category := range migrateCategories {
+        hashes[category] = sha256.New();
+        summary.Counts[category] = 0;
+    }
+
+    add := func(category string, object any) error {
+        text, err := util.ToJSON(object);
+        if (err != nil) {
+            return fmt.Errorf("Failed to serialize object for '%s' checksum: '%w'.", category, err);
+        }
+
+        // Separate each object so different splits of the same bytes do not collide.
+        hashes[category].Write([]byte(text));
+        hashes[category].Write([]byte{0});
+        summary.Counts[category]++;
+
+        return nil;
+    };
+
+    visitor := backendVisitor{
+        Course: func(course *model.Course) error {
+            err := add(MIGRATE_CATEGORY_COURSES, course);
+            if (err != nil) {
+                return err;
+            }
+
+            for _, assignment := range course.GetSortedAssignments() {
+                err = add(MIGRATE_CATEGORY_ASSIGNMENTS, assignment);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Users: func(course *model.Course, users map[string]*model.User) error {
+            emails := maps.Keys(users);
+            slices.Sort(emails);
+
+            for _, email := range emails {
+                err := add(MIGRATE_CATEGORY_USERS, users[email]);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Submissions: func(course *model.Course, submissions []*model.GradingResult) error {
+            for _, submission := range submissions {
+                err := add(MIGRATE_CATEGORY_SUBMISSIONS, submission);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Scores: func(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error {
+            emails := maps.Keys(scores);
+            slices.Sort(emails);
+
+            for _, email := range emails {
+                err := add(MIGRATE_CATEGORY_SCORES, []any{assignment.FullID(), email, scores[email]});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        SelectedSubmissions: func(assignment *model.Assignment, selections map[string]string) error {
+            emails := maps.Keys(selections);
+            slices.Sort(emails);
+
+            for _, email := range emails {
+                err := add(MIGRATE_CATEGORY_SELECTED_SUBMISSIONS, []any{assignment.FullID(), email, selections[email]});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        ManualGrades: func(assignment *model.Assignment, grades map[string]*model.ManualGrade) error {
+            submissionIDs := maps.Keys(grades);
+            slices.Sort(submissionIDs);
+
+            for _, submissionID := range submissionIDs {
+                err := add(MIGRATE_CATEGORY_MANUAL_GRADES, []any{assignment.FullID(), grades[submissionID]});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Tasks: func(course *model.Course, completions map[string]int64) error {
+            taskIDs := maps.Keys(completions);
+            slices.Sort(taskIDs);
+
+            for _, taskID := range taskIDs {
+                err := add(MIGRATE_CATEGORY_TASKS, []any{course.GetID(), taskID, completions[taskID]});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Extensions: func(course *model.Course, extensions []*model.Extension) error {
+            for _, extension := range extensions {
+                err := add(MIGRATE_CATEGORY_EXTENSIONS, []any{course.GetID(), extension});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Groups: func(course *model.Course, groups []*model.Group) error {
+            for _, group := range groups {
+                err := add(MIGRATE_CATEGORY_GROUPS, []any{course.GetID(), group});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        LateDays: func(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error {
+            emails := maps.Keys(balances);
+            slices.Sort(emails);
+
+            for _, email := range emails {
+                err := add(MIGRATE_CATEGORY_LATE_DAYS, []any{course.GetID(), balances[email]});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            for _, event := range events {
+                err := add(MIGRATE_CATEGORY_LATE_DAY_EVENTS, []any{course.GetID(), event});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        WebhookDeliveries: func(course *model.Course, deliveries []*model.WebhookDelivery) error {
+            for _, delivery := range deliveries {
+                err := add(MIGRATE_CATEGORY_WEBHOOK_DELIVERIES, []any{course.GetID(), delivery});
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Outbox: func(messages []*email.OutboxMessage) error {
+            for _, message := range messages {
+                err := add(MIGRATE_CATEGORY_EMAIL_OUTBOX, message);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+        Logs: func(records []*log.Record) error {
+            for _, record := range records {
+                err := add(MIGRATE_CATEGORY_LOGS, record);
+                if (err != nil) {
+                    return err;
+                }
+            }
+
+            return nil;
+        },
+    };
+
+    err := walkBackend(backend, visitor);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for category, categoryHash := range hashes {
+        summary.Checksums[category] = fmt.Sprintf("%x", categoryHash.Sum(nil));
+    }
+
+    return summary, nil;
+}
+
+// Get the categories that do not match between two summaries.
+func (this *BackendSummary) Compare(other *BackendSummary) []string {
+    mismatches := make([]string, 0);
+
+    for _, category := range migrateCategories {
+        if ((this.Counts[category] != other.Counts[category]) || (this.Checksums[category] != other.Checksums[category])) {
+            mismatches = append(mismatches, category);
+        }
+    }
+
+    return mismatches;
+}
+
+func walkBackend(backend Backend, visitor backendVisitor) error {
+    courses, err := backend.GetCourses();
+    if (err != nil) {
+        return fmt.Errorf("Failed to get courses: '%w'.", err);
+    }
+
+    courseIDs := maps.Keys(courses);
+    slices.Sort(courseIDs);
+
+    for _, courseID := range courseIDs {
+        course := courses[courseID];
+
+        err = visitor.Course(course);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle course '%s': '%w'.", courseID, err);
+        }
+
+        users, err := backend.GetUsers(course);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get users for course '%s': '%w'.", courseID, err);
+        }
+
+        err = visitor.Users(course, users);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle users for course '%s': '%w'.", courseID, err);
+        }
+
+        // Submissions are handled one user/assignment at a time to keep memory usage down.
+        // Submitters are taken from the submissions themselves, since users may have left the course.
+        for _, assignment := range course.GetSortedAssignments() {
+            emails, err := backend.GetSubmissionUsers(assignment);
+            if (err != nil) {
+                return fmt.Errorf("Failed to get submission users for '%s': '%w'.", assignment.FullID(), err);
+            }
+
+            for _, email := range emails {
+                submissions, err := backend.GetSubmissionAttempts(assignment, email);
+                if (err != nil) {
+                    return fmt.Errorf("Failed to get submissions for '%s' (%s): '%w'.", assignment.FullID(), email, err);
+                }
+
+                if (len(submissions) == 0) {
+                    continue;
+                }
+
+                err = visitor.Submissions(course, submissions);
+                if (err != nil) {
+                    return fmt.Errorf("Failed to handle submissions for '%s' (%s): '%w'.", assignment.FullID(), email, err);
+                }
+            }
+
+            selections, err := backend.GetSelectedSubmissions(assignment);
+            if (err != nil) {
+                return fmt.Errorf("Failed to get selected submissions for '%s': '%w'.", assignment.FullID(), err);
+            }
+
+            err = visitor.SelectedSubmissions(assignment, selections);
+            if (err != nil) {
+                return fmt.Errorf("Failed to handle selected submissions for '%s': '%w'.", assignment.FullID(), err);
+            }
+
+            grades, err := backend.GetManualGrades(assignment);
+            if (err != nil) {
+                return fmt.Errorf("Failed to get manual grades for '%s': '%w'.", assignment.FullID(), err);
+            }
+
+            err = visitor.ManualGrades(assignment, grades);
+            if (err != nil) {
+                return fmt.Errorf("Failed to handle manual grades for '%s': '%w'.", assignment.FullID(), err);
+            }
+
+            scores, err := backend.GetFinalScores(assignment);
+            if (err != nil) {
+                return fmt.Errorf("Failed to get final scores for '%s': '%w'.", assignment.FullID(), err);
+            }
+
+            if (len(scores) == 0) {
+                continue;
+            }
+
+            err = visitor.Scores(assignment, scores);
+            if (err != nil) {
+                return fmt.Errorf("Failed to handle final scores for '%s': '%w'.", assignment.FullID(), err);
+            }
+        }
+
+        completions, err := backend.GetTaskCompletions(courseID);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get task completions for course '%s': '%w'.", courseID, err);
+        }
+
+        // Compare task times at nanosecond precision (backends differ in how they store time zones).
+        completionNanos := make(map[string]int64, len(completions));
+        for taskID, instance := range completions {
+            completionNanos[taskID] = instance.UnixNano();
+        }
+
+        err = visitor.Tasks(course, completionNanos);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle task completions for course '%s': '%w'.", courseID, err);
+        }
+
+        extensions, err := backend.GetExtensions(course);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get extensions for course '%s': '%w'.", courseID, err);
+        }
+
+        slices.SortFunc(extensions, func(a *model.Extension, b *model.Extension) int {
+            return strings.Compare(a.User + "\x00" + a.AssignmentID, b.User + "\x00" + b.AssignmentID);
+        });
+
+        err = visitor.Extensions(course, extensions);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle extensions for course '%s': '%w'.", courseID, err);
+        }
+
+        groups, err := backend.GetGroups(course);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get groups for course '%s': '%w'.", courseID, err);
+        }
+
+        groupIDs := maps.Keys(groups);
+        slices.Sort(groupIDs);
+
+        sortedGroups := make([]*model.Group, 0, len(groups));
+        for _, groupID := range groupIDs {
+            sortedGroups = append(sortedGroups, groups[groupID]);
+        }
+
+        err = visitor.Groups(course, sortedGroups);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle groups for course '%s': '%w'.", courseID, err);
+        }
+
+        balances, err := backend.GetLateDays(course);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get late days for course '%s': '%w'.", courseID, err);
+        }
+
+        events, err := backend.GetLateDaysHistory(course, "");
+        if (err != nil) {
+            return fmt.Errorf("Failed to get late days history for course '%s': '%w'.", courseID, err);
+        }
+
+        err = visitor.LateDays(course, balances, events);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle late days for course '%s': '%w'.", courseID, err);
+        }
+
+        deliveries, err := backend.GetWebhookDeliveries(course);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get webhook deliveries for course '%s': '%w'.", courseID, err);
+        }
+
+        err = visitor.WebhookDeliveries(course, deliveries);
+        if (err != nil) {
+            return fmt.Errorf("Failed to handle webhook deliveries for course '%s': '%w'.", courseID, err);
+        }
+    }
+
+    messages, err := backend.GetOutboxMessages();
+    if (err != nil) {
+        return fmt.Errorf("Failed to get outbox messages: '%w'.", err);
+    }
+
+    err = visitor.Outbox(messages);
+    if (err != nil) {
+        return fmt.Errorf("Failed to handle outbox messages: '%w'.", err);
+    }
+
+    records, err := backend.GetLogRecords(log.LevelTrace, time.Time{}, "", "", "");
+    if (err != nil) {
+        return fmt.Errorf("Failed to get log records: '%w'.", err);
+    }
+
+    err = visitor.Logs(records);
+    if (err != nil) {
+        return fmt.Errorf("Failed to handle log records: '%w'.", err);
+    }
+
+    return nil;
+}
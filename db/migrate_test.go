@@ -0,0 +1,133 @@
+package db
+
+import (
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+// Migrate the test database into fresh backends of every embedded type.
+func (this *DBTests) DBTestMigrateBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+
+    err := LogTaskCompletion(course.GetID(), "test-task", time.Now());
+    if (err != nil) {
+        test.Fatalf("Failed to log task completion: '%v'.", err);
+    }
+
+    err = SaveFinalScores(MustGetTestAssignment(), map[string]*model.ScoringInfo{"student@test.com": &model.ScoringInfo{ID: "test", RawScore: 1.0, Score: 1.0}});
+    if (err != nil) {
+        test.Fatalf("Failed to save final scores: '%v'.", err);
+    }
+
+    err = SaveSelectedSubmission(MustGetTestAssignment(), "student@test.com", "1697406265");
+    if (err != nil) {
+        test.Fatalf("Failed to save selected submission: '%v'.", err);
+    }
+
+    err = SaveManualGrade(MustGetTestAssignment(), &model.ManualGrade{
+        SubmissionID: "course101::hw0::student@test.com::1697406265",
+        Items: []*model.ManualGradeItem{&model.ManualGradeItem{Name: "Style", Points: 1.0}},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save manual grade: '%v'.", err);
+    }
+
+    err = SaveGroup(course, &model.Group{ID: "team1", Members: []string{"student@test.com", "other@test.com"}});
+    if (err != nil) {
+        test.Fatalf("Failed to save group: '%v'.", err);
+    }
+
+    balance := model.NewLateDaysBalance("student@test.com", 3);
+    event := balance.Allocate("hw0", 1, model.LATE_DAYS_SOURCE_SCORING, "");
+    err = SaveLateDays(course, map[string]*model.LateDaysBalance{balance.User: balance}, []*model.LateDaysEvent{event});
+    if (err != nil) {
+        test.Fatalf("Failed to save late days: '%v'.", err);
+    }
+
+    err = SaveExtension(course, &model.Extension{User: "student@test.com", AssignmentID: "hw0", ExtraDays: 2});
+    if (err != nil) {
+        test.Fatalf("Failed to save extension: '%v'.", err);
+    }
+
+    err = SaveWebhookDelivery(course, &model.WebhookDelivery{
+        ID: "test-delivery",
+        URL: "http://localhost/hook",
+        Event: model.WEBHOOK_EVENT_SUBMISSION_GRADED,
+        Payload: "{}",
+        Status: model.WEBHOOK_DELIVERY_PENDING,
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save webhook delivery: '%v'.", err);
+    }
+
+    _, err = email.Enqueue(&email.Message{To: []string{"student@test.com"}, Subject: "test", Body: "test", CourseID: course.GetID()});
+    if (err != nil) {
+        test.Fatalf("Failed to enqueue email: '%v'.", err);
+    }
+
+    record := &log.Record{
+        Level: log.LevelInfo,
+        Message: "test",
+        UnixMicro: time.Now().UnixMicro(),
+        Course: course.GetID(),
+        Attributes: map[string]any{"key": "value"},
+    };
+
+    err = backend.LogDirect(record);
+    if (err != nil) {
+        test.Fatalf("Failed to write log record: '%v'.", err);
+    }
+
+    // The only submissions are from this user, so they must be found without the roster.
+    _, err = RemoveUser(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to remove user: '%v'.", err);
+    }
+
+    tempDir, err := util.MkDirTemp("migrate-test-");
+    if (err != nil) {
+        test.Fatalf("Failed to make temp dir: '%v'.", err);
+    }
+
+    targets := []struct{dbType string; location string}{
+        {DB_TYPE_DISK, filepath.Join(tempDir, "disk")},
+        {DB_TYPE_SQLITE, filepath.Join(tempDir, "sqlite.db")},
+    };
+
+    for i, target := range targets {
+        targetBackend, err := OpenBackend(target.dbType, target.location);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to open target backend: '%v'.", i, err);
+            continue;
+        }
+
+        sourceSummary, targetSummary, err := Migrate(backend, targetBackend);
+        targetBackend.Close();
+
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to migrate: '%v'.", i, err);
+            continue;
+        }
+
+        if (len(sourceSummary.Compare(targetSummary)) != 0) {
+            test.Errorf("Case %d: Summaries do not match. Source: '%s', Target: '%s'.", i,
+                    util.MustToJSONIndent(sourceSummary), util.MustToJSONIndent(targetSummary));
+            continue;
+        }
+
+        for _, category := range migrateCategories {
+            if (sourceSummary.Counts[category] == 0) {
+                test.Errorf("Case %d: No objects were migrated for '%s'.", i, category);
+            }
+        }
+    }
+}
@@ -0,0 +1,193 @@
+package pg
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) ClearCourse(course *model.Course) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for _, tableName := range []string{"courses", "assignments", "users", "submissions", "scores", "selected_submissions", "manual_grades", "tasks", "extensions", "course_groups", "late_days", "late_day_events", "webhook_deliveries"} {
+            column := "course_id";
+            if (tableName == "courses") {
+                column = "id";
+            }
+
+            query := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", tableName, column);
+            _, err := tx.Exec(context.Background(), query, course.GetID());
+            if (err != nil) {
+                return fmt.Errorf("Failed to clear '%s' for course '%s': '%w'.", tableName, course.GetID(), err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) LoadCourse(path string) (*model.Course, error) {
+    course, users, submissions, err := model.FullLoadCourseFromPath(path);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    log.Debug("Loaded postgres course.",
+            log.NewAttr("database", "postgres"), log.NewAttr("path", path),
+            log.NewAttr("id", course.GetID()), log.NewAttr("num-assignments", len(course.Assignments)));
+
+    err = this.withTransaction(func(tx pgx.Tx) error {
+        err := saveCourse(tx, course);
+        if (err != nil) {
+            return err;
+        }
+
+        err = saveUsers(tx, course, users);
+        if (err != nil) {
+            return err;
+        }
+
+        return saveSubmissions(tx, submissions);
+    });
+
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return course, nil;
+}
+
+func (this *backend) SaveCourse(course *model.Course) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        return saveCourse(tx, course);
+    });
+}
+
+// Save a course and replace all of its assignments.
+func saveCourse(conn queryer, course *model.Course) error {
+    data, err := util.ToJSON(course);
+    if (err != nil) {
+        return fmt.Errorf("Failed to serialize course '%s': '%w'.", course.GetID(), err);
+    }
+
+    _, err = conn.Exec(context.Background(),
+            `INSERT INTO courses (id, data) VALUES ($1, $2)
+            ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`,
+            course.GetID(), data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save course '%s': '%w'.", course.GetID(), err);
+    }
+
+    _, err = conn.Exec(context.Background(), `DELETE FROM assignments WHERE course_id = $1`, course.GetID());
+    if (err != nil) {
+        return fmt.Errorf("Failed to clear old assignments for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    for _, assignment := range course.Assignments {
+        err = saveAssignment(conn, assignment);
+        if (err != nil) {
+            return err;
+        }
+    }
+
+    return nil;
+}
+
+func (this *backend) DumpCourse(course *model.Course, targetDir string) error {
+    users, err := this.GetUsers(course);
+    if (err != nil) {
+        return err;
+    }
+
+    submissions, err := this.getSubmissionContents(
+            `SELECT ` + SUBMISSION_CONTENTS_COLUMNS + ` FROM submissions WHERE course_id = $1`,
+            course.GetID());
+    if (err != nil) {
+        return err;
+    }
+
+    err = model.FullWriteCourseToDir(course, users, submissions, targetDir);
+    if (err != nil) {
+        return fmt.Errorf("Failed to dump postgres course '%s' into '%s': '%w'.", course.GetID(), targetDir, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) GetCourse(courseID string) (*model.Course, error) {
+    var courseJSON string;
+    err := this.pool.QueryRow(context.Background(), `SELECT data FROM courses WHERE id = $1`, courseID).Scan(&courseJSON);
+    if (errors.Is(err, pgx.ErrNoRows)) {
+        return nil, nil;
+    }
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get course '%s': '%w'.", courseID, err);
+    }
+
+    return this.loadCourse(courseID, courseJSON);
+}
+
+func (this *backend) GetCourses() (map[string]*model.Course, error) {
+    rows, err := this.pool.Query(context.Background(), `SELECT id, data FROM courses`);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get courses: '%w'.", err);
+    }
+
+    // Collect all the rows before loading assignments, so the connection is released.
+    courseJSONs := make(map[string]string);
+    for rows.Next() {
+        var id string;
+        var data string;
+
+        err = rows.Scan(&id, &data);
+        if (err != nil) {
+            rows.Close();
+            return nil, fmt.Errorf("Failed to read course row: '%w'.", err);
+        }
+
+        courseJSONs[id] = data;
+    }
+
+    rows.Close();
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over courses: '%w'.", rows.Err());
+    }
+
+    courses := make(map[string]*model.Course, len(courseJSONs));
+    for id, data := range courseJSONs {
+        course, err := this.loadCourse(id, data);
+        if (err != nil) {
+            return nil, err;
+        }
+
+        courses[course.GetID()] = course;
+    }
+
+    return courses, nil;
+}
+
+// Build a full course from its JSON and its stored assignments.
+func (this *backend) loadCourse(courseID string, courseJSON string) (*model.Course, error) {
+    rows, err := this.pool.Query(context.Background(), `SELECT data FROM assignments WHERE course_id = $1 ORDER BY id`, courseID);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get assignments for course '%s': '%w'.", courseID, err);
+    }
+
+    assignmentsJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read assignments for course '%s': '%w'.", courseID, err);
+    }
+
+    course, err := model.LoadCourseFromJSON(courseJSON, assignmentsJSON);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to load course '%s': '%w'.", courseID, err);
+    }
+
+    return course, nil;
+}
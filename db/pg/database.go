@@ -1,33 +1,222 @@
+// A database backend that stores data in Postgres.
+// Autograder objects are stored as JSON next to the columns used to look them up,
+// so most model changes do not require schema changes.
+// Unlike the disk backend, concurrency is handled by Postgres (no global lock is held).
 package pg
 
 import (
     "context"
     "fmt"
 
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
     "github.com/jackc/pgx/v5/pgxpool"
 
     "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
 )
 
 type backend struct {
     pool *pgxpool.Pool
 }
 
+// Something that can execute queries (a pool or a transaction).
+type queryer interface {
+    Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error);
+    Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error);
+    QueryRow(ctx context.Context, sql string, args ...any) pgx.Row;
+}
+
+// All the tables used by this backend.
+// Clear() will truncate these tables.
+var tableNames = []string{
+    "courses",
+    "assignments",
+    "users",
+    "submissions",
+    "scores",
+    "selected_submissions",
+    "manual_grades",
+    "tasks",
+    "extensions",
+    "course_groups",
+    "late_days",
+    "late_day_events",
+    "webhook_deliveries",
+    "email_outbox",
+    "logs",
+};
+
+var tableDefinitions = []string{
+    `CREATE TABLE IF NOT EXISTS courses (
+        id TEXT PRIMARY KEY,
+        data TEXT NOT NULL
+    )`,
+    `CREATE TABLE IF NOT EXISTS assignments (
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS users (
+        course_id TEXT NOT NULL,
+        email TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS submissions (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        short_id TEXT NOT NULL,
+        info TEXT NOT NULL,
+        input_files TEXT NOT NULL,
+        output_files TEXT NOT NULL,
+        stdout BYTEA NOT NULL,
+        stderr BYTEA NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS scores (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, user_email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS selected_submissions (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        short_id TEXT NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, user_email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS manual_grades (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        submission_id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, submission_id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS tasks (
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        completed_unix_nano BIGINT NOT NULL,
+        PRIMARY KEY (course_id, id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS extensions (
+        course_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, user_email, assignment_id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS course_groups (
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS late_days (
+        course_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, user_email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS late_day_events (
+        id BIGSERIAL PRIMARY KEY,
+        course_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        data TEXT NOT NULL
+    )`,
+    `CREATE INDEX IF NOT EXISTS late_day_events_user_index ON late_day_events (course_id, user_email)`,
+    `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        seq BIGSERIAL PRIMARY KEY,
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        status TEXT NOT NULL,
+        next_attempt_unix_micro BIGINT NOT NULL,
+        finished_unix_micro BIGINT NOT NULL,
+        data TEXT NOT NULL,
+        UNIQUE (course_id, id)
+    )`,
+    `CREATE INDEX IF NOT EXISTS webhook_deliveries_status_index ON webhook_deliveries (course_id, status)`,
+    `CREATE TABLE IF NOT EXISTS email_outbox (
+        seq BIGSERIAL PRIMARY KEY,
+        id TEXT NOT NULL UNIQUE,
+        data TEXT NOT NULL
+    )`,
+    `CREATE TABLE IF NOT EXISTS logs (
+        id BIGSERIAL PRIMARY KEY,
+        level INTEGER NOT NULL,
+        message TEXT NOT NULL,
+        unix_micro BIGINT NOT NULL,
+        error TEXT NOT NULL,
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        attributes TEXT NOT NULL
+    )`,
+    `CREATE INDEX IF NOT EXISTS logs_unix_micro_index ON logs (unix_micro)`,
+};
+
 func Open() (*backend, error) {
-    uri := config.DB_PG_URI.Get();
+    return OpenURI(config.DB_PG_URI.Get());
+}
+
+func OpenURI(uri string) (*backend, error) {
     if (uri == "") {
         return nil, fmt.Errorf("Postgres connection URI is empty.");
     }
 
     pool, err := pgxpool.New(context.Background(), uri);
-	if (err != nil) {
-        return nil, fmt.Errorf("Failed to open connection pool to Postgres database at '%s': %w.", uri, err);
-	}
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to open connection pool to Postgres database: '%w'.", err);
+    }
+
+    err = pool.Ping(context.Background());
+    if (err != nil) {
+        pool.Close();
+        return nil, fmt.Errorf("Failed to connect to Postgres database: '%w'.", err);
+    }
+
+    log.Debug("Opened Postgres database.", log.NewAttr("database", "postgres"));
 
     return &backend{pool}, nil;
 }
 
 func (this *backend) Close() error {
-    this.pool.Close()
+    this.pool.Close();
     return nil;
 }
+
+func (this *backend) EnsureTables() error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for _, definition := range tableDefinitions {
+            _, err := tx.Exec(context.Background(), definition);
+            if (err != nil) {
+                return fmt.Errorf("Failed to create table: '%w'.", err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) Clear() error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for _, tableName := range tableNames {
+            _, err := tx.Exec(context.Background(), fmt.Sprintf("TRUNCATE TABLE %s", tableName));
+            if (err != nil) {
+                return fmt.Errorf("Failed to clear table '%s': '%w'.", tableName, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+// Run an operation inside a transaction.
+// The transaction will be committed if the operation returns nil, and rolled back otherwise.
+func (this *backend) withTransaction(operation func(pgx.Tx) error) error {
+    return pgx.BeginFunc(context.Background(), this.pool, operation);
+}
@@ -0,0 +1,70 @@
+package pg
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetOutboxMessages() ([]*email.OutboxMessage, error) {
+    rows, err := this.pool.Query(context.Background(), `SELECT data FROM email_outbox ORDER BY seq`);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get outbox messages: '%w'.", err);
+    }
+
+    messagesJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read outbox messages: '%w'.", err);
+    }
+
+    messages := make([]*email.OutboxMessage, 0, len(messagesJSON));
+    for _, messageJSON := range messagesJSON {
+        var message email.OutboxMessage;
+        err = util.JSONFromString(messageJSON, &message);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal outbox message: '%w'.", err);
+        }
+
+        messages = append(messages, &message);
+    }
+
+    return messages, nil;
+}
+
+func (this *backend) SaveOutboxMessages(messages []*email.OutboxMessage) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for _, message := range messages {
+            data, err := util.ToJSON(message);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize outbox message '%s': '%w'.", message.ID, err);
+            }
+
+            _, err = tx.Exec(context.Background(),
+                    `INSERT INTO email_outbox (id, data) VALUES ($1, $2)
+                    ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`,
+                    message.ID, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save outbox message '%s': '%w'.", message.ID, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) RemoveOutboxMessages(ids []string) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for _, id := range ids {
+            _, err := tx.Exec(context.Background(), `DELETE FROM email_outbox WHERE id = $1`, id);
+            if (err != nil) {
+                return fmt.Errorf("Failed to remove outbox message '%s': '%w'.", id, err);
+            }
+        }
+
+        return nil;
+    });
+}
@@ -0,0 +1,66 @@
+package pg
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetExtensions(course *model.Course) ([]*model.Extension, error) {
+    rows, err := this.pool.Query(context.Background(),
+            `SELECT data FROM extensions WHERE course_id = $1 ORDER BY user_email, assignment_id`,
+            course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get extensions for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    extensionsJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read extensions for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    extensions := make([]*model.Extension, 0, len(extensionsJSON));
+    for _, extensionJSON := range extensionsJSON {
+        var extension model.Extension;
+        err = util.JSONFromString(extensionJSON, &extension);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal extension for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        extensions = append(extensions, &extension);
+    }
+
+    return extensions, nil;
+}
+
+func (this *backend) SaveExtension(course *model.Course, extension *model.Extension) error {
+    data, err := util.ToJSON(extension);
+    if (err != nil) {
+        return fmt.Errorf("Failed to serialize extension for user '%s': '%w'.", extension.User, err);
+    }
+
+    _, err = this.pool.Exec(context.Background(),
+            `INSERT INTO extensions (course_id, user_email, assignment_id, data) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (course_id, user_email, assignment_id) DO UPDATE SET data = EXCLUDED.data`,
+            course.GetID(), extension.User, extension.AssignmentID, data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save extension for user '%s': '%w'.", extension.User, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) RemoveExtension(course *model.Course, email string, assignmentID string) (bool, error) {
+    tag, err := this.pool.Exec(context.Background(),
+            `DELETE FROM extensions WHERE course_id = $1 AND user_email = $2 AND assignment_id = $3`,
+            course.GetID(), email, assignmentID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove extension for user '%s': '%w'.", email, err);
+    }
+
+    return (tag.RowsAffected() > 0), nil;
+}
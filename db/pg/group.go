@@ -0,0 +1,67 @@
+package pg
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetGroups(course *model.Course) (map[string]*model.Group, error) {
+    rows, err := this.pool.Query(context.Background(), `SELECT data FROM course_groups WHERE course_id = $1`, course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get groups for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    groupsJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read groups for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    groups := make(map[string]*model.Group, len(groupsJSON));
+    for _, groupJSON := range groupsJSON {
+        var group model.Group;
+        err = util.JSONFromString(groupJSON, &group);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal group for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        groups[group.ID] = &group;
+    }
+
+    return groups, nil;
+}
+
+func (this *backend) SaveGroups(course *model.Course, groups []*model.Group) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for _, group := range groups {
+            data, err := util.ToJSON(group);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize group '%s': '%w'.", group.ID, err);
+            }
+
+            _, err = tx.Exec(context.Background(),
+                    `INSERT INTO course_groups (course_id, id, data) VALUES ($1, $2, $3)
+                    ON CONFLICT (course_id, id) DO UPDATE SET data = EXCLUDED.data`,
+                    course.GetID(), group.ID, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save group '%s': '%w'.", group.ID, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) RemoveGroup(course *model.Course, groupID string) (bool, error) {
+    result, err := this.pool.Exec(context.Background(),
+            `DELETE FROM course_groups WHERE course_id = $1 AND id = $2`, course.GetID(), groupID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove group '%s': '%w'.", groupID, err);
+    }
+
+    return (result.RowsAffected() > 0), nil;
+}
@@ -0,0 +1,108 @@
+package pg
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetLateDays(course *model.Course) (map[string]*model.LateDaysBalance, error) {
+    rows, err := this.pool.Query(context.Background(), `SELECT data FROM late_days WHERE course_id = $1`, course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get late days for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    balancesJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read late days for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    balances := make(map[string]*model.LateDaysBalance, len(balancesJSON));
+    for _, balanceJSON := range balancesJSON {
+        var balance model.LateDaysBalance;
+        err = util.JSONFromString(balanceJSON, &balance);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal late days for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        balances[balance.User] = &balance;
+    }
+
+    return balances, nil;
+}
+
+func (this *backend) SaveLateDays(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        for email, balance := range balances {
+            data, err := util.ToJSON(balance);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize late days for user '%s': '%w'.", email, err);
+            }
+
+            _, err = tx.Exec(context.Background(),
+                    `INSERT INTO late_days (course_id, user_email, data) VALUES ($1, $2, $3)
+                    ON CONFLICT (course_id, user_email) DO UPDATE SET data = EXCLUDED.data`,
+                    course.GetID(), email, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save late days for user '%s': '%w'.", email, err);
+            }
+        }
+
+        for _, event := range events {
+            data, err := util.ToJSON(event);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize late days event for user '%s': '%w'.", event.User, err);
+            }
+
+            _, err = tx.Exec(context.Background(),
+                    `INSERT INTO late_day_events (course_id, user_email, data) VALUES ($1, $2, $3)`,
+                    course.GetID(), event.User, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save late days event for user '%s': '%w'.", event.User, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) GetLateDaysHistory(course *model.Course, email string) ([]*model.LateDaysEvent, error) {
+    var rows pgx.Rows;
+    var err error;
+
+    if (email == "") {
+        rows, err = this.pool.Query(context.Background(),
+                `SELECT data FROM late_day_events WHERE course_id = $1 ORDER BY id`,
+                course.GetID());
+    } else {
+        rows, err = this.pool.Query(context.Background(),
+                `SELECT data FROM late_day_events WHERE course_id = $1 AND user_email = $2 ORDER BY id`,
+                course.GetID(), email);
+    }
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get late days history for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    eventsJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read late days history for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    events := make([]*model.LateDaysEvent, 0, len(eventsJSON));
+    for _, eventJSON := range eventsJSON {
+        var event model.LateDaysEvent;
+        err = util.JSONFromString(eventJSON, &event);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal late days event for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        events = append(events, &event);
+    }
+
+    return events, nil;
+}
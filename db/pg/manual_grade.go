@@ -0,0 +1,66 @@
+package pg
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetManualGrades(assignment *model.Assignment) (map[string]*model.ManualGrade, error) {
+    rows, err := this.pool.Query(context.Background(),
+            `SELECT data FROM manual_grades WHERE course_id = $1 AND assignment_id = $2`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get manual grades for '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    gradesJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read manual grades for '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    grades := make(map[string]*model.ManualGrade, len(gradesJSON));
+    for _, gradeJSON := range gradesJSON {
+        var grade model.ManualGrade;
+        err = util.JSONFromString(gradeJSON, &grade);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal manual grade for '%s': '%w'.", assignment.FullID(), err);
+        }
+
+        grades[grade.SubmissionID] = &grade;
+    }
+
+    return grades, nil;
+}
+
+func (this *backend) SaveManualGrade(assignment *model.Assignment, grade *model.ManualGrade) error {
+    data, err := util.ToJSON(grade);
+    if (err != nil) {
+        return fmt.Errorf("Failed to serialize manual grade for '%s': '%w'.", grade.SubmissionID, err);
+    }
+
+    _, err = this.pool.Exec(context.Background(),
+            `INSERT INTO manual_grades (course_id, assignment_id, submission_id, data) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (course_id, assignment_id, submission_id) DO UPDATE SET data = EXCLUDED.data`,
+            assignment.GetCourse().GetID(), assignment.GetID(), grade.SubmissionID, data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save manual grade for '%s': '%w'.", grade.SubmissionID, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) RemoveManualGrade(assignment *model.Assignment, submissionID string) (bool, error) {
+    result, err := this.pool.Exec(context.Background(),
+            `DELETE FROM manual_grades WHERE course_id = $1 AND assignment_id = $2 AND submission_id = $3`,
+            assignment.GetCourse().GetID(), assignment.GetID(), submissionID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove manual grade for '%s': '%w'.", submissionID, err);
+    }
+
+    return (result.RowsAffected() > 0), nil;
+}
@@ -0,0 +1,442 @@
+package pg
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const SUBMISSION_CONTENTS_COLUMNS = "info, input_files, output_files, stdout, stderr";
+
+func (this *backend) SaveSubmissions(course *model.Course, submissions []*model.GradingResult) error {
+    return this.withTransaction(func(tx pgx.Tx) error {
+        return saveSubmissions(tx, submissions);
+    });
+}
+
+func saveSubmissions(conn queryer, submissions []*model.GradingResult) error {
+    for _, submission := range submissions {
+        info, err := util.ToJSON(submission.Info);
+        if (err != nil) {
+            return fmt.Errorf("Failed to serialize submission info '%s': '%w'.", submission.Info.ID, err);
+        }
+
+        inputFiles, err := util.ToJSON(submission.InputFilesGZip);
+        if (err != nil) {
+            return fmt.Errorf("Failed to serialize submission input files '%s': '%w'.", submission.Info.ID, err);
+        }
+
+        outputFiles, err := util.ToJSON(submission.OutputFilesGZip);
+        if (err != nil) {
+            return fmt.Errorf("Failed to serialize submission output files '%s': '%w'.", submission.Info.ID, err);
+        }
+
+        _, err = conn.Exec(context.Background(),
+                `INSERT INTO submissions
+                    (course_id, assignment_id, user_email, short_id, info, input_files, output_files, stdout, stderr)
+                VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+                ON CONFLICT (course_id, assignment_id, user_email, short_id) DO UPDATE SET
+                    info = EXCLUDED.info,
+                    input_files = EXCLUDED.input_files,
+                    output_files = EXCLUDED.output_files,
+                    stdout = EXCLUDED.stdout,
+                    stderr = EXCLUDED.stderr`,
+                submission.Info.CourseID, submission.Info.AssignmentID, submission.Info.User, submission.Info.ShortID,
+                info, inputFiles, outputFiles, []byte(submission.Stdout), []byte(submission.Stderr));
+        if (err != nil) {
+            return fmt.Errorf("Failed to save submission '%s': '%w'.", submission.Info.ID, err);
+        }
+    }
+
+    return nil;
+}
+
+func (this *backend) GetNextSubmissionID(assignment *model.Assignment, email string) (string, error) {
+    submissionID := time.Now().Unix();
+
+    for ; ; {
+        var exists bool;
+        err := this.pool.QueryRow(context.Background(),
+                `SELECT EXISTS (
+                    SELECT 1 FROM submissions
+                    WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4
+                )`,
+                assignment.GetCourse().GetID(), assignment.GetID(), email, strconv.FormatInt(submissionID, 10)).Scan(&exists);
+        if (err != nil) {
+            return "", fmt.Errorf("Failed to check for existing submission: '%w'.", err);
+        }
+
+        if (!exists) {
+            break;
+        }
+
+        // This ID has been used.
+        submissionID++;
+    }
+
+    return fmt.Sprintf("%d", submissionID), nil;
+}
+
+func (this *backend) GetSubmissionResult(assignment *model.Assignment, email string, shortSubmissionID string) (*model.GradingInfo, error) {
+    var err error;
+
+    if (shortSubmissionID == "") {
+        shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err);
+        }
+    }
+
+    if (shortSubmissionID == "") {
+        return nil, nil;
+    }
+
+    var infoJSON string;
+    err = this.pool.QueryRow(context.Background(),
+            `SELECT info FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+            assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID).Scan(&infoJSON);
+    if (errors.Is(err, pgx.ErrNoRows)) {
+        return nil, nil;
+    }
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get submission '%s': '%w'.", shortSubmissionID, err);
+    }
+
+    var gradingInfo model.GradingInfo;
+    err = util.JSONFromString(infoJSON, &gradingInfo);
+    if (err != nil) {
+        return nil, fmt.Errorf("Unable to deserialize grading info '%s': '%w'.", shortSubmissionID, err);
+    }
+
+    return &gradingInfo, nil;
+}
+
+func (this *backend) GetSubmissionHistory(assignment *model.Assignment, email string) ([]*model.SubmissionHistoryItem, error) {
+    rows, err := this.pool.Query(context.Background(),
+            `SELECT info FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3
+            ORDER BY short_id`,
+            assignment.GetCourse().GetID(), assignment.GetID(), email);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get submission history for '%s': '%w'.", email, err);
+    }
+
+    infosJSON, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read submission history for '%s': '%w'.", email, err);
+    }
+
+    history := make([]*model.SubmissionHistoryItem, 0, len(infosJSON));
+    for _, infoJSON := range infosJSON {
+        var gradingInfo model.GradingInfo;
+        err = util.JSONFromString(infoJSON, &gradingInfo);
+        if (err != nil) {
+            return nil, fmt.Errorf("Unable to deserialize grading info for '%s': '%w'.", email, err);
+        }
+
+        history = append(history, gradingInfo.ToHistoryItem());
+    }
+
+    return history, nil;
+}
+
+func (this *backend) GetRecentSubmissions(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.GradingInfo, error) {
+    gradingInfos := make(map[string]*model.GradingInfo);
+
+    emails, err := this.getFilteredEmails(assignment.GetCourse(), filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    rows, err := this.pool.Query(context.Background(),
+            `SELECT DISTINCT ON (user_email) user_email, info FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2
+            ORDER BY user_email, short_id DESC`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get recent submissions: '%w'.", err);
+    }
+    defer rows.Close();
+
+    recentInfos := make(map[string]string);
+    for rows.Next() {
+        var email string;
+        var infoJSON string;
+
+        err = rows.Scan(&email, &infoJSON);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read recent submission: '%w'.", err);
+        }
+
+        recentInfos[email] = infoJSON;
+    }
+
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over recent submissions: '%w'.", rows.Err());
+    }
+
+    for _, email := range emails {
+        infoJSON, exists := recentInfos[email];
+        if (!exists) {
+            gradingInfos[email] = nil;
+            continue;
+        }
+
+        var gradingInfo model.GradingInfo;
+        err = util.JSONFromString(infoJSON, &gradingInfo);
+        if (err != nil) {
+            return nil, fmt.Errorf("Unable to deserialize grading info for '%s': '%w'.", email, err);
+        }
+
+        gradingInfos[email] = &gradingInfo;
+    }
+
+    return gradingInfos, nil;
+}
+
+func (this *backend) GetScoringInfos(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.ScoringInfo, error) {
+    scoringInfos := make(map[string]*model.ScoringInfo);
+
+    submissionResults, err := this.GetRecentSubmissions(assignment, filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for email, submissionResult := range submissionResults {
+        if (submissionResult == nil) {
+            scoringInfos[email] = nil;
+        } else {
+            scoringInfos[email] = submissionResult.ToScoringInfo();
+        }
+    }
+
+    return scoringInfos, nil;
+}
+
+func (this *backend) GetRecentSubmissionSurvey(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.SubmissionHistoryItem, error) {
+    results := make(map[string]*model.SubmissionHistoryItem);
+
+    submissionResults, err := this.GetRecentSubmissions(assignment, filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for email, submissionResult := range submissionResults {
+        if (submissionResult == nil) {
+            results[email] = nil;
+        } else {
+            results[email] = submissionResult.ToHistoryItem();
+        }
+    }
+
+    return results, nil;
+}
+
+func (this *backend) GetSubmissionContents(assignment *model.Assignment, email string, shortSubmissionID string) (*model.GradingResult, error) {
+    var err error;
+
+    if (shortSubmissionID == "") {
+        shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to get most recent submission id: '%w'.", err);
+        }
+    }
+
+    if (shortSubmissionID == "") {
+        return nil, nil;
+    }
+
+    results, err := this.getSubmissionContents(
+            `SELECT ` + SUBMISSION_CONTENTS_COLUMNS + ` FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+            assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (len(results) == 0) {
+        return nil, nil;
+    }
+
+    return results[0], nil;
+}
+
+func (this *backend) GetRecentSubmissionContents(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.GradingResult, error) {
+    results := make(map[string]*model.GradingResult);
+
+    emails, err := this.getFilteredEmails(assignment.GetCourse(), filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    submissions, err := this.getSubmissionContents(
+            `SELECT DISTINCT ON (user_email) ` + SUBMISSION_CONTENTS_COLUMNS + ` FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2
+            ORDER BY user_email, short_id DESC`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, err;
+    }
+
+    recentSubmissions := make(map[string]*model.GradingResult, len(submissions));
+    for _, submission := range submissions {
+        recentSubmissions[submission.Info.User] = submission;
+    }
+
+    for _, email := range emails {
+        results[email] = recentSubmissions[email];
+    }
+
+    return results, nil;
+}
+
+func (this *backend) RemoveSubmission(assignment *model.Assignment, email string, shortSubmissionID string) (bool, error) {
+    var err error;
+
+    if (shortSubmissionID == "") {
+        shortSubmissionID, err = this.getMostRecentSubmissionID(assignment, email);
+        if (err != nil) {
+            return false, fmt.Errorf("Failed to get most recent submission id: `%w`.", err);
+        }
+    }
+
+    if (shortSubmissionID == "") {
+        return false, nil;
+    }
+
+    tag, err := this.pool.Exec(context.Background(),
+            `DELETE FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3 AND short_id = $4`,
+            assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove submission '%s': '%w'", shortSubmissionID, err);
+    }
+
+    return (tag.RowsAffected() > 0), nil;
+}
+
+func (this *backend) GetSubmissionAttempts(assignment *model.Assignment, email string) ([]*model.GradingResult, error) {
+    return this.getSubmissionContents(
+            `SELECT ` + SUBMISSION_CONTENTS_COLUMNS + ` FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3
+            ORDER BY short_id`,
+            assignment.GetCourse().GetID(), assignment.GetID(), email);
+}
+
+func (this *backend) GetSubmissionUsers(assignment *model.Assignment) ([]string, error) {
+    rows, err := this.pool.Query(context.Background(),
+            `SELECT DISTINCT user_email FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2
+            ORDER BY user_email`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get submission users for '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    emails, err := pgx.CollectRows(rows, pgx.RowTo[string]);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read submission users for '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    return emails, nil;
+}
+
+// Get the short id of the most recent submission (or empty string if there are no submissions).
+func (this *backend) getMostRecentSubmissionID(assignment *model.Assignment, email string) (string, error) {
+    var shortID string;
+    err := this.pool.QueryRow(context.Background(),
+            `SELECT short_id FROM submissions
+            WHERE course_id = $1 AND assignment_id = $2 AND user_email = $3
+            ORDER BY short_id DESC
+            LIMIT 1`,
+            assignment.GetCourse().GetID(), assignment.GetID(), email).Scan(&shortID);
+    if (errors.Is(err, pgx.ErrNoRows)) {
+        return "", nil;
+    }
+
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to query most recent submission for '%s': '%w'.", email, err);
+    }
+
+    return shortID, nil;
+}
+
+// Get the emails of all users in a course that match the role (RoleUnknown matches all users).
+func (this *backend) getFilteredEmails(course *model.Course, filterRole model.UserRole) ([]string, error) {
+    users, err := this.GetUsers(course);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    emails := make([]string, 0, len(users));
+    for email, user := range users {
+        if ((filterRole != model.RoleUnknown) && (filterRole != user.Role)) {
+            continue;
+        }
+
+        emails = append(emails, email);
+    }
+
+    return emails, nil;
+}
+
+// Run a query that selects SUBMISSION_CONTENTS_COLUMNS and build the full grading results.
+func (this *backend) getSubmissionContents(query string, args ...any) ([]*model.GradingResult, error) {
+    rows, err := this.pool.Query(context.Background(), query, args...);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to query submission contents: '%w'.", err);
+    }
+    defer rows.Close();
+
+    results := make([]*model.GradingResult, 0);
+    for rows.Next() {
+        var infoJSON string;
+        var inputFilesJSON string;
+        var outputFilesJSON string;
+        var stdout []byte;
+        var stderr []byte;
+
+        err = rows.Scan(&infoJSON, &inputFilesJSON, &outputFilesJSON, &stdout, &stderr);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read submission contents: '%w'.", err);
+        }
+
+        result := &model.GradingResult{
+            Info: &model.GradingInfo{},
+            Stdout: string(stdout),
+            Stderr: string(stderr),
+        };
+
+        err = util.JSONFromString(infoJSON, result.Info);
+        if (err != nil) {
+            return nil, fmt.Errorf("Unable to deserialize grading info: '%w'.", err);
+        }
+
+        err = util.JSONFromString(inputFilesJSON, &result.InputFilesGZip);
+        if (err != nil) {
+            return nil, fmt.Errorf("Unable to deserialize input files for submission '%s': '%w'.", result.Info.ID, err);
+        }
+
+        err = util.JSONFromString(outputFilesJSON, &result.OutputFilesGZip);
+        if (err != nil) {
+            return nil, fmt.Errorf("Unable to deserialize output files for submission '%s': '%w'.", result.Info.ID, err);
+        }
+
+        results = append(results, result);
+    }
+
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over submission contents: '%w'.", rows.Err());
+    }
+
+    return results, nil;
+}
@@ -0,0 +1,23 @@
+package db
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+)
+
+func SaveFinalScores(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.SaveFinalScores(assignment, scores);
+}
+
+func GetFinalScores(assignment *model.Assignment) (map[string]*model.ScoringInfo, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetFinalScores(assignment);
+}
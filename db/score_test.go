@@ -0,0 +1,55 @@
+package db
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestFinalScoresBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    assignment := MustGetTestAssignment();
+
+    scores, err := GetFinalScores(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get initial final scores: '%v'.", err);
+    }
+
+    if (len(scores) != 0) {
+        test.Fatalf("Found initial final scores: '%s'.", util.MustToJSONIndent(scores));
+    }
+
+    err = SaveFinalScores(assignment, map[string]*model.ScoringInfo{
+        "student@test.com": &model.ScoringInfo{ID: "1", RawScore: 2.0, Score: 1.0, NumDaysLate: 1},
+        "other@test.com": &model.ScoringInfo{ID: "2", RawScore: 3.0, Score: 3.0},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save final scores: '%v'.", err);
+    }
+
+    // Scores are upserted.
+    err = SaveFinalScores(assignment, map[string]*model.ScoringInfo{
+        "student@test.com": &model.ScoringInfo{ID: "3", RawScore: 2.0, Score: 2.0},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to update final scores: '%v'.", err);
+    }
+
+    expected := map[string]*model.ScoringInfo{
+        "student@test.com": &model.ScoringInfo{ID: "3", RawScore: 2.0, Score: 2.0},
+        "other@test.com": &model.ScoringInfo{ID: "2", RawScore: 3.0, Score: 3.0},
+    };
+
+    scores, err = GetFinalScores(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get final scores: '%v'.", err);
+    }
+
+    if (util.MustToJSON(expected) != util.MustToJSON(scores)) {
+        test.Fatalf("Unexpected final scores. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected), util.MustToJSONIndent(scores));
+    }
+}
@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+    "database/sql"
+    "fmt"
+
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) SaveAssignment(assignment *model.Assignment) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        return saveAssignment(tx, assignment);
+    });
+}
+
+func saveAssignment(conn queryer, assignment *model.Assignment) error {
+    data, err := util.ToJSON(assignment);
+    if (err != nil) {
+        return fmt.Errorf("Failed to serialize assignment '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    _, err = conn.Exec(
+            `INSERT INTO assignments (course_id, id, data) VALUES (?, ?, ?)
+            ON CONFLICT (course_id, id) DO UPDATE SET data = EXCLUDED.data`,
+            assignment.GetCourse().GetID(), assignment.GetID(), data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save assignment '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    return nil;
+}
@@ -0,0 +1,272 @@
+// A database backend that stores data in an embedded SQLite database file.
+// Meant for single-node deployments that want transactional storage without running a database server.
+// Autograder objects are stored as JSON next to the columns used to look them up (same as the Postgres backend).
+// All access goes through a single connection, so SQLite handles all locking.
+package sqlite
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "path/filepath"
+
+    _ "github.com/mattn/go-sqlite3"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+const DB_FILENAME = "sqlite-database.db";
+
+// WAL journaling keeps the database consistent across crashes without blocking readers.
+const CONNECTION_OPTIONS = "?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=5000";
+
+type backend struct {
+    path string
+    db *sql.DB
+}
+
+// Something that can execute queries (a database or a transaction).
+type queryer interface {
+    Exec(query string, args ...any) (sql.Result, error);
+    Query(query string, args ...any) (*sql.Rows, error);
+    QueryRow(query string, args ...any) *sql.Row;
+}
+
+// All the tables used by this backend.
+// Clear() will empty these tables.
+var tableNames = []string{
+    "courses",
+    "assignments",
+    "users",
+    "submissions",
+    "scores",
+    "selected_submissions",
+    "manual_grades",
+    "tasks",
+    "extensions",
+    "course_groups",
+    "late_days",
+    "late_day_events",
+    "webhook_deliveries",
+    "email_outbox",
+    "logs",
+};
+
+var tableDefinitions = []string{
+    `CREATE TABLE IF NOT EXISTS courses (
+        id TEXT PRIMARY KEY,
+        data TEXT NOT NULL
+    )`,
+    `CREATE TABLE IF NOT EXISTS assignments (
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS users (
+        course_id TEXT NOT NULL,
+        email TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS submissions (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        short_id TEXT NOT NULL,
+        info TEXT NOT NULL,
+        input_files TEXT NOT NULL,
+        output_files TEXT NOT NULL,
+        stdout BLOB NOT NULL,
+        stderr BLOB NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, user_email, short_id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS scores (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, user_email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS selected_submissions (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        short_id TEXT NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, user_email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS manual_grades (
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        submission_id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, assignment_id, submission_id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS tasks (
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        completed_unix_nano INTEGER NOT NULL,
+        PRIMARY KEY (course_id, id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS extensions (
+        course_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, user_email, assignment_id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS course_groups (
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, id)
+    )`,
+    `CREATE TABLE IF NOT EXISTS late_days (
+        course_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        data TEXT NOT NULL,
+        PRIMARY KEY (course_id, user_email)
+    )`,
+    `CREATE TABLE IF NOT EXISTS late_day_events (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        course_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        data TEXT NOT NULL
+    )`,
+    `CREATE INDEX IF NOT EXISTS late_day_events_user_index ON late_day_events (course_id, user_email)`,
+    `CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        seq INTEGER PRIMARY KEY AUTOINCREMENT,
+        course_id TEXT NOT NULL,
+        id TEXT NOT NULL,
+        status TEXT NOT NULL,
+        next_attempt_unix_micro INTEGER NOT NULL,
+        finished_unix_micro INTEGER NOT NULL,
+        data TEXT NOT NULL,
+        UNIQUE (course_id, id)
+    )`,
+    `CREATE INDEX IF NOT EXISTS webhook_deliveries_status_index ON webhook_deliveries (course_id, status)`,
+    `CREATE TABLE IF NOT EXISTS email_outbox (
+        seq INTEGER PRIMARY KEY AUTOINCREMENT,
+        id TEXT NOT NULL UNIQUE,
+        data TEXT NOT NULL
+    )`,
+    `CREATE TABLE IF NOT EXISTS logs (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        level INTEGER NOT NULL,
+        message TEXT NOT NULL,
+        unix_micro INTEGER NOT NULL,
+        error TEXT NOT NULL,
+        course_id TEXT NOT NULL,
+        assignment_id TEXT NOT NULL,
+        user_email TEXT NOT NULL,
+        attributes TEXT NOT NULL
+    )`,
+    `CREATE INDEX IF NOT EXISTS logs_unix_micro_index ON logs (unix_micro)`,
+};
+
+func Open() (*backend, error) {
+    return OpenPath(filepath.Join(config.GetDatabaseDir(), DB_FILENAME));
+}
+
+func OpenPath(path string) (*backend, error) {
+    path = util.ShouldAbs(path);
+
+    err := util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to make dir for SQLite database '%s': '%w'.", path, err);
+    }
+
+    db, err := sql.Open("sqlite3", "file:" + path + CONNECTION_OPTIONS);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to open SQLite database '%s': '%w'.", path, err);
+    }
+
+    // SQLite only allows one writer at a time, so just use a single connection.
+    db.SetMaxOpenConns(1);
+
+    err = db.Ping();
+    if (err != nil) {
+        db.Close();
+        return nil, fmt.Errorf("Failed to connect to SQLite database '%s': '%w'.", path, err);
+    }
+
+    log.Debug("Opened SQLite database.", log.NewAttr("database", "sqlite"), log.NewAttr("path", path));
+
+    return &backend{path: path, db: db}, nil;
+}
+
+func (this *backend) Close() error {
+    return this.db.Close();
+}
+
+func (this *backend) EnsureTables() error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for _, definition := range tableDefinitions {
+            _, err := tx.Exec(definition);
+            if (err != nil) {
+                return fmt.Errorf("Failed to create table: '%w'.", err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) Clear() error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for _, tableName := range tableNames {
+            _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", tableName));
+            if (err != nil) {
+                return fmt.Errorf("Failed to clear table '%s': '%w'.", tableName, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+// Run an operation inside a transaction.
+// The transaction will be committed if the operation returns nil, and rolled back otherwise.
+func (this *backend) withTransaction(operation func(*sql.Tx) error) error {
+    tx, err := this.db.BeginTx(context.Background(), nil);
+    if (err != nil) {
+        return fmt.Errorf("Failed to begin transaction: '%w'.", err);
+    }
+
+    err = operation(tx);
+    if (err != nil) {
+        tx.Rollback();
+        return err;
+    }
+
+    err = tx.Commit();
+    if (err != nil) {
+        return fmt.Errorf("Failed to commit transaction: '%w'.", err);
+    }
+
+    return nil;
+}
+
+// Collect all the string values from a single-column query.
+func queryStrings(conn queryer, query string, args ...any) ([]string, error) {
+    rows, err := conn.Query(query, args...);
+    if (err != nil) {
+        return nil, err;
+    }
+    defer rows.Close();
+
+    values := make([]string, 0);
+    for rows.Next() {
+        var value string;
+        err = rows.Scan(&value);
+        if (err != nil) {
+            return nil, err;
+        }
+
+        values = append(values, value);
+    }
+
+    return values, rows.Err();
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetOutboxMessages() ([]*email.OutboxMessage, error) {
+    messagesJSON, err := queryStrings(this.db, `SELECT data FROM email_outbox ORDER BY seq`);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get outbox messages: '%w'.", err);
+    }
+
+    messages := make([]*email.OutboxMessage, 0, len(messagesJSON));
+    for _, messageJSON := range messagesJSON {
+        var message email.OutboxMessage;
+        err = util.JSONFromString(messageJSON, &message);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal outbox message: '%w'.", err);
+        }
+
+        messages = append(messages, &message);
+    }
+
+    return messages, nil;
+}
+
+func (this *backend) SaveOutboxMessages(messages []*email.OutboxMessage) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for _, message := range messages {
+            data, err := util.ToJSON(message);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize outbox message '%s': '%w'.", message.ID, err);
+            }
+
+            _, err = tx.Exec(
+                    `INSERT INTO email_outbox (id, data) VALUES (?, ?)
+                    ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data`,
+                    message.ID, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save outbox message '%s': '%w'.", message.ID, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) RemoveOutboxMessages(ids []string) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for _, id := range ids {
+            _, err := tx.Exec(`DELETE FROM email_outbox WHERE id = ?`, id);
+            if (err != nil) {
+                return fmt.Errorf("Failed to remove outbox message '%s': '%w'.", id, err);
+            }
+        }
+
+        return nil;
+    });
+}
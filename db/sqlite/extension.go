@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetExtensions(course *model.Course) ([]*model.Extension, error) {
+    extensionsJSON, err := queryStrings(this.db,
+            `SELECT data FROM extensions WHERE course_id = ? ORDER BY user_email, assignment_id`,
+            course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get extensions for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    extensions := make([]*model.Extension, 0, len(extensionsJSON));
+    for _, extensionJSON := range extensionsJSON {
+        var extension model.Extension;
+        err = util.JSONFromString(extensionJSON, &extension);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal extension for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        extensions = append(extensions, &extension);
+    }
+
+    return extensions, nil;
+}
+
+func (this *backend) SaveExtension(course *model.Course, extension *model.Extension) error {
+    data, err := util.ToJSON(extension);
+    if (err != nil) {
+        return fmt.Errorf("Failed to serialize extension for user '%s': '%w'.", extension.User, err);
+    }
+
+    _, err = this.db.Exec(
+            `INSERT INTO extensions (course_id, user_email, assignment_id, data) VALUES (?, ?, ?, ?)
+            ON CONFLICT (course_id, user_email, assignment_id) DO UPDATE SET data = EXCLUDED.data`,
+            course.GetID(), extension.User, extension.AssignmentID, data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save extension for user '%s': '%w'.", extension.User, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) RemoveExtension(course *model.Course, email string, assignmentID string) (bool, error) {
+    result, err := this.db.Exec(
+            `DELETE FROM extensions WHERE course_id = ? AND user_email = ? AND assignment_id = ?`,
+            course.GetID(), email, assignmentID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove extension for user '%s': '%w'.", email, err);
+    }
+
+    count, err := result.RowsAffected();
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to count removed extensions for user '%s': '%w'.", email, err);
+    }
+
+    return (count > 0), nil;
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetGroups(course *model.Course) (map[string]*model.Group, error) {
+    groupsJSON, err := queryStrings(this.db, `SELECT data FROM course_groups WHERE course_id = ?`, course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get groups for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    groups := make(map[string]*model.Group, len(groupsJSON));
+    for _, groupJSON := range groupsJSON {
+        var group model.Group;
+        err = util.JSONFromString(groupJSON, &group);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal group for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        groups[group.ID] = &group;
+    }
+
+    return groups, nil;
+}
+
+func (this *backend) SaveGroups(course *model.Course, groups []*model.Group) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for _, group := range groups {
+            data, err := util.ToJSON(group);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize group '%s': '%w'.", group.ID, err);
+            }
+
+            _, err = tx.Exec(
+                    `INSERT INTO course_groups (course_id, id, data) VALUES (?, ?, ?)
+                    ON CONFLICT (course_id, id) DO UPDATE SET data = EXCLUDED.data`,
+                    course.GetID(), group.ID, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save group '%s': '%w'.", group.ID, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) RemoveGroup(course *model.Course, groupID string) (bool, error) {
+    result, err := this.db.Exec(`DELETE FROM course_groups WHERE course_id = ? AND id = ?`, course.GetID(), groupID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove group '%s': '%w'.", groupID, err);
+    }
+
+    count, err := result.RowsAffected();
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to count removed groups for '%s': '%w'.", groupID, err);
+    }
+
+    return (count > 0), nil;
+}
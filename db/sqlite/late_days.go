@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetLateDays(course *model.Course) (map[string]*model.LateDaysBalance, error) {
+    balancesJSON, err := queryStrings(this.db, `SELECT data FROM late_days WHERE course_id = ?`, course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get late days for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    balances := make(map[string]*model.LateDaysBalance, len(balancesJSON));
+    for _, balanceJSON := range balancesJSON {
+        var balance model.LateDaysBalance;
+        err = util.JSONFromString(balanceJSON, &balance);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal late days for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        balances[balance.User] = &balance;
+    }
+
+    return balances, nil;
+}
+
+func (this *backend) SaveLateDays(course *model.Course, balances map[string]*model.LateDaysBalance, events []*model.LateDaysEvent) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for email, balance := range balances {
+            data, err := util.ToJSON(balance);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize late days for user '%s': '%w'.", email, err);
+            }
+
+            _, err = tx.Exec(
+                    `INSERT INTO late_days (course_id, user_email, data) VALUES (?, ?, ?)
+                    ON CONFLICT (course_id, user_email) DO UPDATE SET data = EXCLUDED.data`,
+                    course.GetID(), email, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save late days for user '%s': '%w'.", email, err);
+            }
+        }
+
+        for _, event := range events {
+            data, err := util.ToJSON(event);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize late days event for user '%s': '%w'.", event.User, err);
+            }
+
+            _, err = tx.Exec(
+                    `INSERT INTO late_day_events (course_id, user_email, data) VALUES (?, ?, ?)`,
+                    course.GetID(), event.User, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save late days event for user '%s': '%w'.", event.User, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) GetLateDaysHistory(course *model.Course, email string) ([]*model.LateDaysEvent, error) {
+    var eventsJSON []string;
+    var err error;
+
+    if (email == "") {
+        eventsJSON, err = queryStrings(this.db,
+                `SELECT data FROM late_day_events WHERE course_id = ? ORDER BY id`,
+                course.GetID());
+    } else {
+        eventsJSON, err = queryStrings(this.db,
+                `SELECT data FROM late_day_events WHERE course_id = ? AND user_email = ? ORDER BY id`,
+                course.GetID(), email);
+    }
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get late days history for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    events := make([]*model.LateDaysEvent, 0, len(eventsJSON));
+    for _, eventJSON := range eventsJSON {
+        var event model.LateDaysEvent;
+        err = util.JSONFromString(eventJSON, &event);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal late days event for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        events = append(events, &event);
+    }
+
+    return events, nil;
+}
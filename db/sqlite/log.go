@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) LogDirect(record *log.Record) error {
+    // Empty attributes are stored as an empty string (matching how they are omitted in JSON).
+    attributes := "";
+    if (len(record.Attributes) > 0) {
+        var err error;
+        attributes, err = util.ToJSON(record.Attributes);
+        if (err != nil) {
+            return fmt.Errorf("Failed to convert log attributes to JSON: '%w'.", err);
+        }
+    }
+
+    _, err := this.db.Exec(
+            `INSERT INTO logs (level, message, unix_micro, error, course_id, assignment_id, user_email, attributes)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+            int(record.Level), record.Message, record.UnixMicro, record.Error,
+            record.Course, record.Assignment, record.User, attributes);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write log record: '%w'.", err);
+    }
+
+    return nil;
+}
+
+func (this *backend) GetLogRecords(level log.LogLevel, after time.Time, courseID string, assignmentID string, userID string) ([]*log.Record, error) {
+    conditions := []string{"level >= ?"};
+    args := []any{int(level)};
+
+    addCondition := func(column string, value any) {
+        args = append(args, value);
+        conditions = append(conditions, fmt.Sprintf("%s = ?", column));
+    };
+
+    if (!after.IsZero()) {
+        args = append(args, after.UnixMicro());
+        conditions = append(conditions, "unix_micro > ?");
+    }
+
+    if (courseID != "") {
+        addCondition("course_id", courseID);
+    }
+
+    if (assignmentID != "") {
+        addCondition("assignment_id", assignmentID);
+    }
+
+    if (userID != "") {
+        addCondition("user_email", userID);
+    }
+
+    query := `SELECT level, message, unix_micro, error, course_id, assignment_id, user_email, attributes FROM logs
+            WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY id`;
+
+    rows, err := this.db.Query(query, args...);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to query log records: '%w'.", err);
+    }
+    defer rows.Close();
+
+    records := make([]*log.Record, 0);
+    for rows.Next() {
+        var record log.Record;
+        var recordLevel int;
+        var attributes string;
+
+        err = rows.Scan(&recordLevel, &record.Message, &record.UnixMicro, &record.Error,
+                &record.Course, &record.Assignment, &record.User, &attributes);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read log record: '%w'.", err);
+        }
+
+        record.Level = log.LogLevel(recordLevel);
+
+        if (attributes != "") {
+            err = util.JSONFromString(attributes, &record.Attributes);
+            if (err != nil) {
+                return nil, fmt.Errorf("Failed to convert log attributes from JSON: '%w'.", err);
+            }
+        }
+
+        records = append(records, &record);
+    }
+
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over log records: '%w'.", rows.Err());
+    }
+
+    return records, nil;
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetManualGrades(assignment *model.Assignment) (map[string]*model.ManualGrade, error) {
+    gradesJSON, err := queryStrings(this.db,
+            `SELECT data FROM manual_grades WHERE course_id = ? AND assignment_id = ?`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get manual grades for '%s': '%w'.", assignment.FullID(), err);
+    }
+
+    grades := make(map[string]*model.ManualGrade, len(gradesJSON));
+    for _, gradeJSON := range gradesJSON {
+        var grade model.ManualGrade;
+        err = util.JSONFromString(gradeJSON, &grade);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal manual grade for '%s': '%w'.", assignment.FullID(), err);
+        }
+
+        grades[grade.SubmissionID] = &grade;
+    }
+
+    return grades, nil;
+}
+
+func (this *backend) SaveManualGrade(assignment *model.Assignment, grade *model.ManualGrade) error {
+    data, err := util.ToJSON(grade);
+    if (err != nil) {
+        return fmt.Errorf("Failed to serialize manual grade for '%s': '%w'.", grade.SubmissionID, err);
+    }
+
+    _, err = this.db.Exec(
+            `INSERT INTO manual_grades (course_id, assignment_id, submission_id, data) VALUES (?, ?, ?, ?)
+            ON CONFLICT (course_id, assignment_id, submission_id) DO UPDATE SET data = EXCLUDED.data`,
+            assignment.GetCourse().GetID(), assignment.GetID(), grade.SubmissionID, data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save manual grade for '%s': '%w'.", grade.SubmissionID, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) RemoveManualGrade(assignment *model.Assignment, submissionID string) (bool, error) {
+    result, err := this.db.Exec(
+            `DELETE FROM manual_grades WHERE course_id = ? AND assignment_id = ? AND submission_id = ?`,
+            assignment.GetCourse().GetID(), assignment.GetID(), submissionID);
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to remove manual grade for '%s': '%w'.", submissionID, err);
+    }
+
+    count, err := result.RowsAffected();
+    if (err != nil) {
+        return false, fmt.Errorf("Failed to count removed manual grades for '%s': '%w'.", submissionID, err);
+    }
+
+    return (count > 0), nil;
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+    "database/sql"
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) SaveFinalScores(assignment *model.Assignment, scores map[string]*model.ScoringInfo) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for email, score := range scores {
+            data, err := util.ToJSON(score);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize final score for '%s' (%s): '%w'.", assignment.FullID(), email, err);
+            }
+
+            _, err = tx.Exec(
+                    `INSERT INTO scores (course_id, assignment_id, user_email, data) VALUES (?, ?, ?, ?)
+                    ON CONFLICT (course_id, assignment_id, user_email) DO UPDATE SET data = EXCLUDED.data`,
+                    assignment.GetCourse().GetID(), assignment.GetID(), email, data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save final score for '%s' (%s): '%w'.", assignment.FullID(), email, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) GetFinalScores(assignment *model.Assignment) (map[string]*model.ScoringInfo, error) {
+    rows, err := this.db.Query(
+            `SELECT user_email, data FROM scores WHERE course_id = ? AND assignment_id = ?`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get final scores for '%s': '%w'.", assignment.FullID(), err);
+    }
+    defer rows.Close();
+
+    scores := make(map[string]*model.ScoringInfo);
+    for rows.Next() {
+        var email string;
+        var data string;
+
+        err = rows.Scan(&email, &data);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read final score for '%s': '%w'.", assignment.FullID(), err);
+        }
+
+        var score model.ScoringInfo;
+        err = util.JSONFromString(data, &score);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal final score for '%s' (%s): '%w'.", assignment.FullID(), email, err);
+        }
+
+        scores[email] = &score;
+    }
+
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over final scores for '%s': '%w'.", assignment.FullID(), rows.Err());
+    }
+
+    return scores, nil;
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+)
+
+func (this *backend) GetSelectedSubmissions(assignment *model.Assignment) (map[string]string, error) {
+    rows, err := this.db.Query(
+            `SELECT user_email, short_id FROM selected_submissions WHERE course_id = ? AND assignment_id = ?`,
+            assignment.GetCourse().GetID(), assignment.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get selected submissions for '%s': '%w'.", assignment.FullID(), err);
+    }
+    defer rows.Close();
+
+    selections := make(map[string]string);
+    for rows.Next() {
+        var email string;
+        var shortSubmissionID string;
+
+        err = rows.Scan(&email, &shortSubmissionID);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read selected submission for '%s': '%w'.", assignment.FullID(), err);
+        }
+
+        selections[email] = shortSubmissionID;
+    }
+
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over selected submissions for '%s': '%w'.", assignment.FullID(), rows.Err());
+    }
+
+    return selections, nil;
+}
+
+func (this *backend) SaveSelectedSubmission(assignment *model.Assignment, email string, shortSubmissionID string) error {
+    var err error;
+
+    if (shortSubmissionID == "") {
+        _, err = this.db.Exec(
+                `DELETE FROM selected_submissions WHERE course_id = ? AND assignment_id = ? AND user_email = ?`,
+                assignment.GetCourse().GetID(), assignment.GetID(), email);
+    } else {
+        _, err = this.db.Exec(
+                `INSERT INTO selected_submissions (course_id, assignment_id, user_email, short_id) VALUES (?, ?, ?, ?)
+                ON CONFLICT (course_id, assignment_id, user_email) DO UPDATE SET short_id = EXCLUDED.short_id`,
+                assignment.GetCourse().GetID(), assignment.GetID(), email, shortSubmissionID);
+    }
+
+    if (err != nil) {
+        return fmt.Errorf("Failed to save selected submission for '%s' (%s): '%w'.", assignment.FullID(), email, err);
+    }
+
+    return nil;
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+    "time"
+)
+
+func (this *backend) LogTaskCompletion(courseID string, taskID string, instance time.Time) error {
+    _, err := this.db.Exec(
+            `INSERT INTO tasks (course_id, id, completed_unix_nano) VALUES (?, ?, ?)
+            ON CONFLICT (course_id, id) DO UPDATE SET completed_unix_nano = EXCLUDED.completed_unix_nano`,
+            courseID, taskID, instance.UnixNano());
+    if (err != nil) {
+        return fmt.Errorf("Failed to log completion of task '%s' for course '%s': '%w'.", taskID, courseID, err);
+    }
+
+    return nil;
+}
+
+func (this *backend) GetLastTaskCompletion(courseID string, taskID string) (time.Time, error) {
+    var unixNano int64;
+    err := this.db.QueryRow(
+            `SELECT completed_unix_nano FROM tasks WHERE course_id = ? AND id = ?`,
+            courseID, taskID).Scan(&unixNano);
+    if (errors.Is(err, sql.ErrNoRows)) {
+        return time.Time{}, nil;
+    }
+
+    if (err != nil) {
+        return time.Time{}, fmt.Errorf("Failed to get last completion of task '%s' for course '%s': '%w'.", taskID, courseID, err);
+    }
+
+    return time.Unix(0, unixNano), nil;
+}
+
+func (this *backend) GetTaskCompletions(courseID string) (map[string]time.Time, error) {
+    rows, err := this.db.Query(
+            `SELECT id, completed_unix_nano FROM tasks WHERE course_id = ?`,
+            courseID);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get task completions for course '%s': '%w'.", courseID, err);
+    }
+    defer rows.Close();
+
+    completions := make(map[string]time.Time);
+    for rows.Next() {
+        var taskID string;
+        var unixNano int64;
+
+        err = rows.Scan(&taskID, &unixNano);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read task completion for course '%s': '%w'.", courseID, err);
+        }
+
+        completions[taskID] = time.Unix(0, unixNano);
+    }
+
+    if (rows.Err() != nil) {
+        return nil, fmt.Errorf("Failed to iterate over task completions for course '%s': '%w'.", courseID, rows.Err());
+    }
+
+    return completions, nil;
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+    "database/sql"
+    "errors"
+    "fmt"
+
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetUsers(course *model.Course) (map[string]*model.User, error) {
+    usersJSON, err := queryStrings(this.db, `SELECT data FROM users WHERE course_id = ?`, course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get users for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    users := make(map[string]*model.User, len(usersJSON));
+    for _, userJSON := range usersJSON {
+        var user model.User;
+        err = util.JSONFromString(userJSON, &user);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal user for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        users[user.Email] = &user;
+    }
+
+    return users, nil;
+}
+
+func (this *backend) GetUser(course *model.Course, email string) (*model.User, error) {
+    var userJSON string;
+    err := this.db.QueryRow(
+            `SELECT data FROM users WHERE course_id = ? AND email = ?`,
+            course.GetID(), email).Scan(&userJSON);
+    if (errors.Is(err, sql.ErrNoRows)) {
+        return nil, nil;
+    }
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get user '%s': '%w'.", email, err);
+    }
+
+    var user model.User;
+    err = util.JSONFromString(userJSON, &user);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to unmarshal user '%s': '%w'.", email, err);
+    }
+
+    return &user, nil;
+}
+
+func (this *backend) SaveUsers(course *model.Course, users map[string]*model.User) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        return saveUsers(tx, course, users);
+    });
+}
+
+func saveUsers(conn queryer, course *model.Course, users map[string]*model.User) error {
+    for email, user := range users {
+        data, err := util.ToJSON(user);
+        if (err != nil) {
+            return fmt.Errorf("Failed to serialize user '%s': '%w'.", email, err);
+        }
+
+        _, err = conn.Exec(
+                `INSERT INTO users (course_id, email, data) VALUES (?, ?, ?)
+                ON CONFLICT (course_id, email) DO UPDATE SET data = EXCLUDED.data`,
+                course.GetID(), email, data);
+        if (err != nil) {
+            return fmt.Errorf("Failed to save user '%s': '%w'.", email, err);
+        }
+    }
+
+    return nil;
+}
+
+func (this *backend) RemoveUser(course *model.Course, email string) error {
+    _, err := this.db.Exec(
+            `DELETE FROM users WHERE course_id = ? AND email = ?`,
+            course.GetID(), email);
+    if (err != nil) {
+        return fmt.Errorf("Failed to remove user '%s': '%w'.", email, err);
+    }
+
+    return nil;
+}
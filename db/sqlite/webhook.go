@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+    "database/sql"
+    "fmt"
+    "time"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *backend) GetWebhookDeliveries(course *model.Course) ([]*model.WebhookDelivery, error) {
+    deliveriesJSON, err := queryStrings(this.db, `SELECT data FROM webhook_deliveries WHERE course_id = ? ORDER BY seq`, course.GetID());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get webhook deliveries for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    return parseWebhookDeliveries(course, deliveriesJSON);
+}
+
+func (this *backend) GetDueWebhookDeliveries(course *model.Course, now time.Time) ([]*model.WebhookDelivery, error) {
+    deliveriesJSON, err := queryStrings(this.db,
+            `SELECT data FROM webhook_deliveries
+            WHERE course_id = ? AND status = ? AND next_attempt_unix_micro <= ?
+            ORDER BY seq`,
+            course.GetID(), model.WEBHOOK_DELIVERY_PENDING, now.UnixMicro());
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get due webhook deliveries for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    return parseWebhookDeliveries(course, deliveriesJSON);
+}
+
+func parseWebhookDeliveries(course *model.Course, deliveriesJSON []string) ([]*model.WebhookDelivery, error) {
+    deliveries := make([]*model.WebhookDelivery, 0, len(deliveriesJSON));
+    for _, deliveryJSON := range deliveriesJSON {
+        var delivery model.WebhookDelivery;
+        err := util.JSONFromString(deliveryJSON, &delivery);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to unmarshal webhook delivery for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        deliveries = append(deliveries, &delivery);
+    }
+
+    return deliveries, nil;
+}
+
+func (this *backend) SaveWebhookDeliveries(course *model.Course, deliveries []*model.WebhookDelivery) error {
+    return this.withTransaction(func(tx *sql.Tx) error {
+        for _, delivery := range deliveries {
+            data, err := util.ToJSON(delivery);
+            if (err != nil) {
+                return fmt.Errorf("Failed to serialize webhook delivery '%s': '%w'.", delivery.ID, err);
+            }
+
+            _, err = tx.Exec(
+                    `INSERT INTO webhook_deliveries (course_id, id, status, next_attempt_unix_micro, finished_unix_micro, data)
+                    VALUES (?, ?, ?, ?, ?, ?)
+                    ON CONFLICT (course_id, id) DO UPDATE SET
+                        status = EXCLUDED.status,
+                        next_attempt_unix_micro = EXCLUDED.next_attempt_unix_micro,
+                        finished_unix_micro = EXCLUDED.finished_unix_micro,
+                        data = EXCLUDED.data`,
+                    course.GetID(), delivery.ID, delivery.Status, delivery.NextAttemptUnixMicro(), delivery.FinishedUnixMicro(), data);
+            if (err != nil) {
+                return fmt.Errorf("Failed to save webhook delivery '%s': '%w'.", delivery.ID, err);
+            }
+        }
+
+        return nil;
+    });
+}
+
+func (this *backend) RemoveFinishedWebhookDeliveries(course *model.Course, before time.Time) (int, error) {
+    result, err := this.db.Exec(
+            `DELETE FROM webhook_deliveries WHERE course_id = ? AND finished_unix_micro > 0 AND finished_unix_micro < ?`,
+            course.GetID(), before.UnixMicro());
+    if (err != nil) {
+        return 0, fmt.Errorf("Failed to remove finished webhook deliveries for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    count, err := result.RowsAffected();
+    if (err != nil) {
+        return 0, fmt.Errorf("Failed to count removed webhook deliveries for course '%s': '%w'.", course.GetID(), err);
+    }
+
+    return int(count), nil;
+}
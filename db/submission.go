@@ -2,6 +2,8 @@ package db
 
 import (
     "fmt"
+    "slices"
+    "time"
 
     "github.com/edulinq/autograder/common"
     "github.com/edulinq/autograder/model"
@@ -28,7 +30,25 @@ func GetNextSubmissionID(assignment *model.Assignment, email string) (string, er
         return "", fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetNextSubmissionID(assignment, email);
+    members, err := getSubmissionMembers(assignment, email);
+    if (err != nil) {
+        return "", err;
+    }
+
+    // Group members share a history, so the ID must be free for every member.
+    nextID := "";
+    for _, member := range members {
+        id, err := backend.GetNextSubmissionID(assignment, member);
+        if (err != nil) {
+            return "", err;
+        }
+
+        if (compareShortSubmissionIDs(id, nextID) > 0) {
+            nextID = id;
+        }
+    }
+
+    return nextID, nil;
 }
 
 func GetSubmissionHistory(assignment *model.Assignment, email string) ([]*model.SubmissionHistoryItem, error) {
@@ -36,7 +56,31 @@ func GetSubmissionHistory(assignment *model.Assignment, email string) ([]*model.
         return nil, fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetSubmissionHistory(assignment, email);
+    members, err := getSubmissionMembers(assignment, email);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (len(members) == 1) {
+        return backend.GetSubmissionHistory(assignment, members[0]);
+    }
+
+    // Group submissions share a history.
+    history := make([]*model.SubmissionHistoryItem, 0);
+    for _, member := range members {
+        memberHistory, err := backend.GetSubmissionHistory(assignment, member);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to get submission history for group member '%s': '%w'.", member, err);
+        }
+
+        history = append(history, memberHistory...);
+    }
+
+    slices.SortStableFunc(history, func(a *model.SubmissionHistoryItem, b *model.SubmissionHistoryItem) int {
+        return compareShortSubmissionIDs(a.ShortID, b.ShortID);
+    });
+
+    return history, nil;
 }
 
 func GetSubmissionResult(assignment *model.Assignment, email string, submissionID string) (*model.GradingInfo, error) {
@@ -45,12 +89,18 @@ func GetSubmissionResult(assignment *model.Assignment, email string, submissionI
     }
 
     shortSubmissionID := common.GetShortSubmissionID(submissionID);
-    return backend.GetSubmissionResult(assignment, email, shortSubmissionID);
+
+    owner, shortSubmissionID, err := getSubmissionOwner(assignment, email, shortSubmissionID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return backend.GetSubmissionResult(assignment, owner, shortSubmissionID);
 }
 
 // Get only non-nil scoring infos.
-func GetExistingScoringInfos(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.ScoringInfo, error) {
-    rawInfo, err := GetScoringInfos(assignment, filterRole);
+func GetExistingScoringInfos(assignment *model.Assignment, filterRole model.UserRole, dueDate *time.Time) (map[string]*model.ScoringInfo, error) {
+    rawInfo, err := GetScoringInfos(assignment, filterRole, dueDate);
     if (err != nil) {
         return nil, err;
     }
@@ -65,12 +115,27 @@ func GetExistingScoringInfos(assignment *model.Assignment, filterRole model.User
     return info, nil;
 }
 
-func GetScoringInfos(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.ScoringInfo, error) {
+// Get the scoring infos for the scored submission (see GetScoredSubmissions()) of each user.
+func GetScoringInfos(assignment *model.Assignment, filterRole model.UserRole, dueDate *time.Time) (map[string]*model.ScoringInfo, error) {
     if (backend == nil) {
         return nil, fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetScoringInfos(assignment, filterRole);
+    submissions, err := GetScoredSubmissions(assignment, filterRole, dueDate);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    scoringInfos := make(map[string]*model.ScoringInfo, len(submissions));
+    for email, submission := range submissions {
+        if (submission == nil) {
+            scoringInfos[email] = nil;
+        } else {
+            scoringInfos[email] = submission.ToScoringInfo();
+        }
+    }
+
+    return scoringInfos, nil;
 }
 
 func GetRecentSubmissions(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.GradingInfo, error) {
@@ -78,7 +143,28 @@ func GetRecentSubmissions(assignment *model.Assignment, filterRole model.UserRol
         return nil, fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetRecentSubmissions(assignment, filterRole);
+    submissions, err := backend.GetRecentSubmissions(assignment, filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    fetch := func(email string) (*model.GradingInfo, error) {
+        return GetSubmissionResult(assignment, email, "");
+    };
+
+    err = resolveRecentRegrades(submissions, func(submission *model.GradingInfo) bool {
+        return (submission.RegradeOf != "");
+    }, fetch);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    err = shareGroupSubmissions(assignment, submissions, fetch);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return submissions, nil;
 }
 
 func GetRecentSubmissionSurvey(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.SubmissionHistoryItem, error) {
@@ -86,7 +172,173 @@ func GetRecentSubmissionSurvey(assignment *model.Assignment, filterRole model.Us
         return nil, fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetRecentSubmissionSurvey(assignment, filterRole);
+    submissions, err := backend.GetRecentSubmissionSurvey(assignment, filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    fetch := func(email string) (*model.SubmissionHistoryItem, error) {
+        submission, err := GetSubmissionResult(assignment, email, "");
+        if ((err != nil) || (submission == nil)) {
+            return nil, err;
+        }
+
+        return submission.ToHistoryItem(), nil;
+    };
+
+    err = resolveRecentRegrades(submissions, func(submission *model.SubmissionHistoryItem) bool {
+        return (submission.RegradeOf != "");
+    }, fetch);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    err = shareGroupSubmissions(assignment, submissions, fetch);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return submissions, nil;
+}
+
+// Get the submission that should be scored (according to the assignment's scoring strategy) for each user of the given role.
+// The score of each submission includes the points from its manual grade (if any).
+// Like GetRecentSubmissions(), users without a submission (but with a matching role) will be represented with a nil map value.
+// The due date is only used by the best-before-deadline strategy, and should be the same due date used for scoring
+// (which may come from the LMS, see scoring.GetScoringDueDate()).
+// A nil due date means that the assignment does not have a due date.
+func GetScoredSubmissions(assignment *model.Assignment, filterRole model.UserRole, dueDate *time.Time) (map[string]*model.GradingInfo, error) {
+    submissions, err := GetRecentSubmissions(assignment, filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (assignment.GetScoringStrategy() != model.LastSubmissionStrategy) {
+        err = chooseScoredSubmissions(assignment, submissions, dueDate);
+        if (err != nil) {
+            return nil, err;
+        }
+    }
+
+    err = applyManualGrades(assignment, submissions);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return submissions, nil;
+}
+
+// Replace each user's recent submission with the one chosen by the assignment's scoring strategy (in place).
+// Submissions are chosen based on their autograder score.
+func chooseScoredSubmissions(assignment *model.Assignment, submissions map[string]*model.GradingInfo, dueDate *time.Time) error {
+    var err error;
+    strategy := assignment.GetScoringStrategy();
+
+    selections := make(map[string]string);
+    if (strategy == model.StudentSelectedSubmissionStrategy) {
+        selections, err = GetSelectedSubmissions(assignment);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get selected submissions: '%w'.", err);
+        }
+    }
+
+    if (strategy != model.BestBeforeDeadlineSubmissionStrategy) {
+        dueDate = nil;
+    }
+
+    extensions := make(map[string]*model.Extension);
+    if (dueDate != nil) {
+        extensions, err = GetAssignmentExtensions(assignment);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get extensions: '%w'.", err);
+        }
+    }
+
+    for email, submission := range submissions {
+        if (submission == nil) {
+            continue;
+        }
+
+        history, err := GetSubmissionHistory(assignment, email);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get submission history for user '%s': '%w'.", email, err);
+        }
+
+        var deadline *time.Time = nil;
+        if (dueDate != nil) {
+            userDueDate := extensions[email].GetDueDate(*dueDate);
+            deadline = &userDueDate;
+        }
+
+        chosen := model.ChooseScoredSubmission(strategy, history, deadline, selections[email]);
+        if ((chosen == nil) || (chosen.ShortID == submission.ShortID)) {
+            continue;
+        }
+
+        chosenSubmission, err := GetSubmissionResult(assignment, email, chosen.ShortID);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get submission '%s' for user '%s': '%w'.", chosen.ShortID, email, err);
+        }
+
+        if (chosenSubmission != nil) {
+            submissions[email] = chosenSubmission;
+        }
+    }
+
+    return nil;
+}
+
+// Get an overview of the scored submission (see GetScoredSubmissions()) of each user.
+func GetScoredSubmissionSurvey(assignment *model.Assignment, filterRole model.UserRole, dueDate *time.Time) (map[string]*model.SubmissionHistoryItem, error) {
+    submissions, err := GetScoredSubmissions(assignment, filterRole, dueDate);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    results := make(map[string]*model.SubmissionHistoryItem, len(submissions));
+    for email, submission := range submissions {
+        if (submission == nil) {
+            results[email] = nil;
+        } else {
+            results[email] = submission.ToHistoryItem();
+        }
+    }
+
+    return results, nil;
+}
+
+func GetSelectedSubmissions(assignment *model.Assignment) (map[string]string, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetSelectedSubmissions(assignment);
+}
+
+// Set the submission a user has selected to be scored.
+// An empty submission ID removes the user's selection.
+// For group submissions, the selection is set for every member of the user's group.
+func SaveSelectedSubmission(assignment *model.Assignment, email string, submissionID string) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    shortSubmissionID := common.GetShortSubmissionID(submissionID);
+
+    // A selection is made for the whole group.
+    members, err := getSubmissionMembers(assignment, email);
+    if (err != nil) {
+        return err;
+    }
+
+    for _, member := range members {
+        err = backend.SaveSelectedSubmission(assignment, member, shortSubmissionID);
+        if (err != nil) {
+            return fmt.Errorf("Failed to save selected submission for user '%s': '%w'.", member, err);
+        }
+    }
+
+    return nil;
 }
 
 func GetSubmissionContents(assignment *model.Assignment, email string, submissionID string) (*model.GradingResult, error) {
@@ -95,7 +347,13 @@ func GetSubmissionContents(assignment *model.Assignment, email string, submissio
     }
 
     shortSubmissionID := common.GetShortSubmissionID(submissionID);
-    return backend.GetSubmissionContents(assignment, email, shortSubmissionID);
+
+    owner, shortSubmissionID, err := getSubmissionOwner(assignment, email, shortSubmissionID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return backend.GetSubmissionContents(assignment, owner, shortSubmissionID);
 }
 
 func GetRecentSubmissionContents(assignment *model.Assignment, filterRole model.UserRole) (map[string]*model.GradingResult, error) {
@@ -103,7 +361,28 @@ func GetRecentSubmissionContents(assignment *model.Assignment, filterRole model.
         return nil, fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetRecentSubmissionContents(assignment, filterRole);
+    submissions, err := backend.GetRecentSubmissionContents(assignment, filterRole);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    fetch := func(email string) (*model.GradingResult, error) {
+        return GetSubmissionContents(assignment, email, "");
+    };
+
+    err = resolveRecentRegrades(submissions, func(submission *model.GradingResult) bool {
+        return ((submission.Info != nil) && (submission.Info.RegradeOf != ""));
+    }, fetch);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    err = shareGroupSubmissions(assignment, submissions, fetch);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return submissions, nil;
 }
 
 func RemoveSubmission(assignment *model.Assignment, email string, submissionID string) (bool, error) {
@@ -112,7 +391,13 @@ func RemoveSubmission(assignment *model.Assignment, email string, submissionID s
     }
 
     shortSubmissionID := common.GetShortSubmissionID(submissionID);
-    return backend.RemoveSubmission(assignment, email, shortSubmissionID);
+
+    owner, shortSubmissionID, err := getSubmissionOwner(assignment, email, shortSubmissionID);
+    if (err != nil) {
+        return false, err;
+    }
+
+    return backend.RemoveSubmission(assignment, owner, shortSubmissionID);
 }
 
 func GetSubmissionAttempts(assignment *model.Assignment, email string) ([]*model.GradingResult, error) {
@@ -120,5 +405,78 @@ func GetSubmissionAttempts(assignment *model.Assignment, email string) ([]*model
         return nil, fmt.Errorf("Database has not been opened.");
     }
 
-    return backend.GetSubmissionAttempts(assignment, email);
+    members, err := getSubmissionMembers(assignment, email);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (len(members) == 1) {
+        return backend.GetSubmissionAttempts(assignment, members[0]);
+    }
+
+    attempts := make([]*model.GradingResult, 0);
+    for _, member := range members {
+        memberAttempts, err := backend.GetSubmissionAttempts(assignment, member);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to get submission attempts for group member '%s': '%w'.", member, err);
+        }
+
+        attempts = append(attempts, memberAttempts...);
+    }
+
+    slices.SortStableFunc(attempts, func(a *model.GradingResult, b *model.GradingResult) int {
+        return compareShortSubmissionIDs(a.Info.ShortID, b.Info.ShortID);
+    });
+
+    return attempts, nil;
+}
+
+// Get the most recent submission from a submission history (sorted by ID).
+// Regrades are saved as new submissions, so a regrade takes the place of the submission it regraded
+// (i.e., regrading an older submission does not make it the most recent submission).
+// If a submission was regraded multiple times, then the latest regrade is used.
+func getMostRecentHistoryItem(history []*model.SubmissionHistoryItem) *model.SubmissionHistoryItem {
+    positions := make(map[string]int, len(history));
+    originalPositions := make([]int, len(history));
+
+    var mostRecent *model.SubmissionHistoryItem = nil;
+    mostRecentPosition := -1;
+
+    for i, item := range history {
+        positions[item.ID] = i;
+
+        // A regrade always comes after the submission it regraded.
+        originalPositions[i] = i;
+        position, ok := positions[item.RegradeOf];
+        if (ok && (position < i)) {
+            originalPositions[i] = originalPositions[position];
+        }
+
+        if (originalPositions[i] >= mostRecentPosition) {
+            mostRecent = item;
+            mostRecentPosition = originalPositions[i];
+        }
+    }
+
+    return mostRecent;
+}
+
+// The backend picks the most recent submission by ID, which may be a regrade of an older submission.
+// Re-fetch those users' submissions (which will pick the correct submission, see getMostRecentHistoryItem()).
+// If a user's most recent submission (by ID) is not a regrade, then it is already correct.
+func resolveRecentRegrades[T any](results map[string]*T, isRegrade func(*T) bool, fetch func(email string) (*T, error)) error {
+    for email, result := range results {
+        if ((result == nil) || !isRegrade(result)) {
+            continue;
+        }
+
+        result, err := fetch(email);
+        if (err != nil) {
+            return fmt.Errorf("Failed to get most recent submission for user '%s': '%w'.", email, err);
+        }
+
+        results[email] = result;
+    }
+
+    return nil;
 }
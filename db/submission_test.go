@@ -3,7 +3,10 @@ package db
 import (
     "reflect"
     "testing"
+    "time"
 
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/util"
 )
 
@@ -136,3 +139,164 @@ func (this *DBTests) DBTestFetchAttempts(test *testing.T) {
         test.Fatalf("Unexpected result length. Expected: '%d', Actual: '%d'.", 0, len(graderAttempts));
     }
 }
+
+func (this *DBTests) DBTestGetScoredSubmissions(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    assignment := MustGetTestAssignment();
+
+    err := SaveSelectedSubmission(assignment, "student@test.com", "course101::hw0::student@test.com::1697406256");
+    if (err != nil) {
+        test.Fatalf("Failed to save selected submission: '%v'.", err);
+    }
+
+    testCases := []struct{ strategy model.ScoringStrategy; dueDate common.Timestamp; expected string }{
+        {model.LastSubmissionStrategy, "", "1697406272"},
+        {model.BestSubmissionStrategy, "", "1697406272"},
+        {model.BestBeforeDeadlineSubmissionStrategy, "2023-10-15T21:44:30Z", "1697406265"},
+        {model.BestBeforeDeadlineSubmissionStrategy, "", "1697406272"},
+        {model.StudentSelectedSubmissionStrategy, "", "1697406256"},
+    };
+
+    for i, testCase := range testCases {
+        assignment.ScoringStrategy = testCase.strategy;
+
+        // The due date is passed in (it may not be the assignment's local due date).
+        var dueDate *time.Time = nil;
+        if (!testCase.dueDate.IsZero()) {
+            dueDateTime := testCase.dueDate.MustTime();
+            dueDate = &dueDateTime;
+        }
+
+        submissions, err := GetScoredSubmissions(assignment, model.RoleStudent, dueDate);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get scored submissions: '%v'.", i, err);
+            continue;
+        }
+
+        if ((submissions["student@test.com"] == nil) || (submissions["student@test.com"].ShortID != testCase.expected)) {
+            test.Errorf("Case %d: Unexpected scored submission. Expected: '%s', Actual: '%s'.", i,
+                    testCase.expected, util.MustToJSONIndent(submissions["student@test.com"]));
+            continue;
+        }
+
+        scoringInfos, err := GetExistingScoringInfos(assignment, model.RoleStudent, dueDate);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get scoring infos: '%v'.", i, err);
+            continue;
+        }
+
+        if (scoringInfos["student@test.com"].ID != submissions["student@test.com"].ID) {
+            test.Errorf("Case %d: Scoring info does not match the scored submission. Expected: '%s', Actual: '%s'.", i,
+                    submissions["student@test.com"].ID, scoringInfos["student@test.com"].ID);
+            continue;
+        }
+    }
+
+    // Clear the selection.
+    err = SaveSelectedSubmission(assignment, "student@test.com", "");
+    if (err != nil) {
+        test.Fatalf("Failed to clear selected submission: '%v'.", err);
+    }
+
+    selections, err := GetSelectedSubmissions(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get selected submissions: '%v'.", err);
+    }
+
+    if (len(selections) != 0) {
+        test.Fatalf("Selection was not cleared: '%s'.", util.MustToJSONIndent(selections));
+    }
+}
+
+// A regrade takes the place of the submission it regraded when finding the most recent submission.
+func (this *DBTests) DBTestRecentSubmissionRegrades(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    assignment := MustGetTestAssignment();
+    email := "student@test.com";
+
+    history, err := GetSubmissionHistory(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to get submission history: '%v'.", err);
+    }
+
+    if (len(history) < 2) {
+        test.Fatalf("Test user does not have enough submissions: '%s'.", util.MustToJSONIndent(history));
+    }
+
+    oldestID := history[0].ID;
+    newestID := history[len(history) - 1].ID;
+
+    // Regrading an older submission does not make it the most recent.
+    saveTestRegrade(test, assignment, email, oldestID);
+    checkRecentSubmission(test, "old regrade", assignment, email, newestID);
+
+    // Regrading the most recent submission replaces it.
+    regrade := saveTestRegrade(test, assignment, email, newestID);
+    checkRecentSubmission(test, "recent regrade", assignment, email, regrade.Info.ID);
+
+    // Even if an older submission is regraded again later.
+    saveTestRegrade(test, assignment, email, oldestID);
+    checkRecentSubmission(test, "second old regrade", assignment, email, regrade.Info.ID);
+}
+
+func checkRecentSubmission(test *testing.T, label string, assignment *model.Assignment, email string, expectedID string) {
+    result, err := GetSubmissionResult(assignment, email, "");
+    if (err != nil) {
+        test.Fatalf("%s: Failed to get most recent submission: '%v'.", label, err);
+    }
+
+    contents, err := GetSubmissionContents(assignment, email, "");
+    if (err != nil) {
+        test.Fatalf("%s: Failed to get most recent submission contents: '%v'.", label, err);
+    }
+
+    recent, err := GetRecentSubmissions(assignment, model.RoleStudent);
+    if (err != nil) {
+        test.Fatalf("%s: Failed to get recent submissions: '%v'.", label, err);
+    }
+
+    survey, err := GetRecentSubmissionSurvey(assignment, model.RoleStudent);
+    if (err != nil) {
+        test.Fatalf("%s: Failed to get recent submission survey: '%v'.", label, err);
+    }
+
+    recentContents, err := GetRecentSubmissionContents(assignment, model.RoleStudent);
+    if (err != nil) {
+        test.Fatalf("%s: Failed to get recent submission contents: '%v'.", label, err);
+    }
+
+    ids := []string{result.ID, contents.Info.ID, recent[email].ID, survey[email].ID, recentContents[email].Info.ID};
+    for i, id := range ids {
+        if (id != expectedID) {
+            test.Fatalf("%s: Unexpected most recent submission (%d). Expected: '%s', Actual: '%s'.", label, i, expectedID, id);
+        }
+    }
+}
+
+// Save a copy of a submission as a regrade of it.
+func saveTestRegrade(test *testing.T, assignment *model.Assignment, email string, submissionID string) *model.GradingResult {
+    submission, err := GetSubmissionContents(assignment, email, submissionID);
+    if (err != nil) {
+        test.Fatalf("Failed to get submission '%s': '%v'.", submissionID, err);
+    }
+
+    shortID, err := GetNextSubmissionID(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to get next submission ID: '%v'.", err);
+    }
+
+    submission.Info.ShortID = shortID;
+    submission.Info.ID = common.CreateFullSubmissionID(assignment.GetCourse().GetID(), assignment.GetID(), email, shortID);
+    submission.Info.RegradeOf = submissionID;
+
+    err = SaveSubmission(assignment, submission);
+    if (err != nil) {
+        test.Fatalf("Failed to save regrade: '%v'.", err);
+    }
+
+    return submission;
+}
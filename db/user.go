@@ -195,13 +195,11 @@ func SyncUsers(course *model.Course, newUsers map[string]*model.User,
     }
 
     if (sendEmails) {
-        sleep := (len(newUsers) > 1);
-
         err = nil;
 
         for _, newUser := range syncResult.Add {
             clearTextPass := syncResult.ClearTextPasswords[newUser.Email];
-            err = errors.Join(err, model.SendUserAddEmail(course, newUser, clearTextPass, (clearTextPass != ""), false, dryRun, sleep));
+            err = errors.Join(err, model.SendUserAddEmail(course, newUser, clearTextPass, (clearTextPass != ""), false, dryRun));
         }
 
         for _, newUser := range syncResult.Mod {
@@ -211,7 +209,7 @@ func SyncUsers(course *model.Course, newUsers map[string]*model.User,
                 continue;
             }
 
-            err = errors.Join(err, model.SendUserAddEmail(course, newUser, clearTextPass, true, true, dryRun, sleep));
+            err = errors.Join(err, model.SendUserAddEmail(course, newUser, clearTextPass, true, true, dryRun));
         }
 
         if (err != nil) {
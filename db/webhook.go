@@ -0,0 +1,50 @@
+package db
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/edulinq/autograder/model"
+)
+
+func GetWebhookDeliveries(course *model.Course) ([]*model.WebhookDelivery, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetWebhookDeliveries(course);
+}
+
+func GetDueWebhookDeliveries(course *model.Course, now time.Time) ([]*model.WebhookDelivery, error) {
+    if (backend == nil) {
+        return nil, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.GetDueWebhookDeliveries(course, now);
+}
+
+func SaveWebhookDeliveries(course *model.Course, deliveries []*model.WebhookDelivery) error {
+    if (backend == nil) {
+        return fmt.Errorf("Database has not been opened.");
+    }
+
+    for _, delivery := range deliveries {
+        if (delivery.ID == "") {
+            return fmt.Errorf("Webhook delivery must have an ID.");
+        }
+    }
+
+    return backend.SaveWebhookDeliveries(course, deliveries);
+}
+
+func SaveWebhookDelivery(course *model.Course, delivery *model.WebhookDelivery) error {
+    return SaveWebhookDeliveries(course, []*model.WebhookDelivery{delivery});
+}
+
+func RemoveFinishedWebhookDeliveries(course *model.Course, before time.Time) (int, error) {
+    if (backend == nil) {
+        return 0, fmt.Errorf("Database has not been opened.");
+    }
+
+    return backend.RemoveFinishedWebhookDeliveries(course, before);
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+    "slices"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *DBTests) DBTestWebhookDeliveriesBase(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+
+    deliveries, err := GetWebhookDeliveries(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get initial webhook deliveries: '%v'.", err);
+    }
+
+    if (len(deliveries) != 0) {
+        test.Fatalf("Found initial webhook deliveries: '%s'.", util.MustToJSONIndent(deliveries));
+    }
+
+    first := &model.WebhookDelivery{ID: "b", URL: "http://localhost/hook", Event: model.WEBHOOK_EVENT_SUBMISSION_GRADED, Payload: "{}", Status: model.WEBHOOK_DELIVERY_PENDING};
+    second := &model.WebhookDelivery{ID: "a", URL: "http://localhost/hook", Event: model.WEBHOOK_EVENT_COURSE_UPDATED, Payload: "{}", Status: model.WEBHOOK_DELIVERY_PENDING};
+
+    err = SaveWebhookDeliveries(course, []*model.WebhookDelivery{first, second});
+    if (err != nil) {
+        test.Fatalf("Failed to save webhook deliveries: '%v'.", err);
+    }
+
+    // Update an existing delivery.
+    first.Status = model.WEBHOOK_DELIVERY_DELIVERED;
+    first.Attempts = 1;
+    first.LastResponseCode = 200;
+
+    err = SaveWebhookDelivery(course, first);
+    if (err != nil) {
+        test.Fatalf("Failed to update webhook delivery: '%v'.", err);
+    }
+
+    err = SaveWebhookDelivery(course, &model.WebhookDelivery{});
+    if (err == nil) {
+        test.Fatalf("Did not get an error when saving a delivery without an ID.");
+    }
+
+    deliveries, err = GetWebhookDeliveries(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get webhook deliveries: '%v'.", err);
+    }
+
+    // Deliveries stay in the order they were created.
+    expected := []*model.WebhookDelivery{first, second};
+    if (util.MustToJSON(expected) != util.MustToJSON(deliveries)) {
+        test.Fatalf("Unexpected webhook deliveries. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(deliveries));
+    }
+}
+
+func (this *DBTests) DBTestWebhookDeliveriesDueAndRemove(test *testing.T) {
+    defer ResetForTesting();
+    ResetForTesting();
+
+    course := MustGetTestCourse();
+    now := common.MustTimestampFromString("2024-01-10T12:00:00Z").MustTime();
+
+    err := SaveWebhookDeliveries(course, []*model.WebhookDelivery{
+        &model.WebhookDelivery{ID: "pending-no-time", Status: model.WEBHOOK_DELIVERY_PENDING},
+        &model.WebhookDelivery{ID: "pending-due", Status: model.WEBHOOK_DELIVERY_PENDING, NextAttemptTime: "2024-01-10T11:00:00Z"},
+        &model.WebhookDelivery{ID: "pending-later", Status: model.WEBHOOK_DELIVERY_PENDING, NextAttemptTime: "2024-01-10T13:00:00Z"},
+        &model.WebhookDelivery{ID: "delivered-old", Status: model.WEBHOOK_DELIVERY_DELIVERED, LastAttemptTime: "2024-01-01T12:00:00Z"},
+        &model.WebhookDelivery{ID: "delivered-new", Status: model.WEBHOOK_DELIVERY_DELIVERED, LastAttemptTime: "2024-01-10T11:00:00Z"},
+        &model.WebhookDelivery{ID: "failed-old", Status: model.WEBHOOK_DELIVERY_FAILED, CreatedTime: "2024-01-01T12:00:00Z"},
+        &model.WebhookDelivery{ID: "failed-no-time", Status: model.WEBHOOK_DELIVERY_FAILED},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save webhook deliveries: '%v'.", err);
+    }
+
+    due, err := GetDueWebhookDeliveries(course, now);
+    if (err != nil) {
+        test.Fatalf("Failed to get due webhook deliveries: '%v'.", err);
+    }
+
+    checkWebhookDeliveryIDs(test, "due", []string{"pending-no-time", "pending-due"}, due);
+
+    count, err := RemoveFinishedWebhookDeliveries(course, now.Add(-24 * time.Hour));
+    if (err != nil) {
+        test.Fatalf("Failed to remove finished webhook deliveries: '%v'.", err);
+    }
+
+    if (count != 2) {
+        test.Fatalf("Unexpected number of removed deliveries. Expected: 2, Actual: %d.", count);
+    }
+
+    // Pending deliveries and deliveries without a known finish time are never removed.
+    deliveries, err := GetWebhookDeliveries(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get webhook deliveries: '%v'.", err);
+    }
+
+    checkWebhookDeliveryIDs(test, "remaining", []string{"pending-no-time", "pending-due", "pending-later", "delivered-new", "failed-no-time"}, deliveries);
+}
+
+func checkWebhookDeliveryIDs(test *testing.T, label string, expected []string, deliveries []*model.WebhookDelivery) {
+    actual := make([]string, 0, len(deliveries));
+    for _, delivery := range deliveries {
+        actual = append(actual, delivery.ID);
+    }
+
+    if (!slices.Equal(expected, actual)) {
+        test.Fatalf("Unexpected %s webhook deliveries. Expected: '%v', Actual: '%v'.", label, expected, actual);
+    }
+}
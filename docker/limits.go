@@ -0,0 +1,114 @@
+package docker
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/docker/docker/api/types/container"
+
+    "github.com/edulinq/autograder/config"
+)
+
+const (
+    LIMIT_KIND_TIMEOUT = "timeout"
+    LIMIT_KIND_OUT_OF_MEMORY = "out-of-memory"
+)
+
+// Resource limits for a grading container.
+// A zero value means that there is no limit
+// (except for the max runtime, which falls back to config.DOCKER_MAX_RUNTIME_SECS).
+type ResourceLimits struct {
+    // Wall-clock seconds.
+    MaxRuntimeSecs int `json:"max-runtime,omitempty"`
+    // Megabytes (swap is not allowed on top of this).
+    MemoryLimitMB int64 `json:"memory-limit,omitempty"`
+    // Number of CPUs (may be fractional).
+    CPULimit float64 `json:"cpu-limit,omitempty"`
+    // Maximum number of processes/threads.
+    PIDsLimit int64 `json:"pids-limit,omitempty"`
+}
+
+// An error returned when a container is killed for hitting a limit.
+type ResourceLimitError struct {
+    Kind string
+    Message string
+}
+
+func (this *ResourceLimitError) Error() string {
+    return this.Message;
+}
+
+func (this *ResourceLimits) Validate() error {
+    if (this.MaxRuntimeSecs < 0) {
+        return fmt.Errorf("Max runtime cannot be negative, found %d.", this.MaxRuntimeSecs);
+    }
+
+    if (this.MemoryLimitMB < 0) {
+        return fmt.Errorf("Memory limit cannot be negative, found %d.", this.MemoryLimitMB);
+    }
+
+    if (this.CPULimit < 0.0) {
+        return fmt.Errorf("CPU limit cannot be negative, found %f.", this.CPULimit);
+    }
+
+    if (this.PIDsLimit < 0) {
+        return fmt.Errorf("PIDs limit cannot be negative, found %d.", this.PIDsLimit);
+    }
+
+    return nil;
+}
+
+// Get a copy of these limits with any unset values filled in from the defaults.
+func (this ResourceLimits) WithDefaults(defaults *ResourceLimits) ResourceLimits {
+    if (defaults == nil) {
+        return this;
+    }
+
+    if (this.MaxRuntimeSecs == 0) {
+        this.MaxRuntimeSecs = defaults.MaxRuntimeSecs;
+    }
+
+    if (this.MemoryLimitMB == 0) {
+        this.MemoryLimitMB = defaults.MemoryLimitMB;
+    }
+
+    if (this.CPULimit == 0.0) {
+        this.CPULimit = defaults.CPULimit;
+    }
+
+    if (this.PIDsLimit == 0) {
+        this.PIDsLimit = defaults.PIDsLimit;
+    }
+
+    return this;
+}
+
+// Get the max runtime, or zero if there is no limit.
+func (this *ResourceLimits) GetMaxRuntime() time.Duration {
+    secs := this.MaxRuntimeSecs;
+    if (secs == 0) {
+        secs = config.DOCKER_MAX_RUNTIME_SECS.Get();
+    }
+
+    return time.Duration(max(0, secs)) * time.Second;
+}
+
+func (this *ResourceLimits) toDockerResources() container.Resources {
+    resources := container.Resources{};
+
+    if (this.MemoryLimitMB > 0) {
+        resources.Memory = this.MemoryLimitMB * 1024 * 1024;
+        resources.MemorySwap = resources.Memory;
+    }
+
+    if (this.CPULimit > 0.0) {
+        resources.NanoCPUs = int64(this.CPULimit * 1e9);
+    }
+
+    if (this.PIDsLimit > 0) {
+        pidsLimit := this.PIDsLimit;
+        resources.PidsLimit = &pidsLimit;
+    }
+
+    return resources;
+}
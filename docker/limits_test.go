@@ -0,0 +1,100 @@
+package docker
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestResourceLimitsValidate(test *testing.T) {
+    testCases := []struct{ limits ResourceLimits; valid bool }{
+        {ResourceLimits{}, true},
+        {ResourceLimits{MaxRuntimeSecs: 10, MemoryLimitMB: 256, CPULimit: 1.5, PIDsLimit: 64}, true},
+        {ResourceLimits{MaxRuntimeSecs: -1}, false},
+        {ResourceLimits{MemoryLimitMB: -1}, false},
+        {ResourceLimits{CPULimit: -0.5}, false},
+        {ResourceLimits{PIDsLimit: -1}, false},
+    };
+
+    for i, testCase := range testCases {
+        err := testCase.limits.Validate();
+        if (testCase.valid && (err != nil)) {
+            test.Errorf("Case %d: Unexpected error: '%v'.", i, err);
+        } else if (!testCase.valid && (err == nil)) {
+            test.Errorf("Case %d: Did not get an expected error.", i);
+        }
+    }
+}
+
+func TestResourceLimitsWithDefaults(test *testing.T) {
+    defaults := &ResourceLimits{MaxRuntimeSecs: 60, MemoryLimitMB: 512, CPULimit: 2.0, PIDsLimit: 128};
+
+    testCases := []struct{ limits ResourceLimits; defaults *ResourceLimits; expected ResourceLimits }{
+        {ResourceLimits{}, nil, ResourceLimits{}},
+        {ResourceLimits{}, defaults, *defaults},
+        {
+            ResourceLimits{MaxRuntimeSecs: 5, CPULimit: 0.5},
+            defaults,
+            ResourceLimits{MaxRuntimeSecs: 5, MemoryLimitMB: 512, CPULimit: 0.5, PIDsLimit: 128},
+        },
+    };
+
+    for i, testCase := range testCases {
+        actual := testCase.limits.WithDefaults(testCase.defaults);
+        if (actual != testCase.expected) {
+            test.Errorf("Case %d: Unexpected limits. Expected: '%s', Actual: '%s'.",
+                    i, util.MustToJSON(testCase.expected), util.MustToJSON(actual));
+        }
+    }
+
+    // The defaults should never be modified.
+    if (*defaults != (ResourceLimits{MaxRuntimeSecs: 60, MemoryLimitMB: 512, CPULimit: 2.0, PIDsLimit: 128})) {
+        test.Fatalf("Defaults were modified: '%s'.", util.MustToJSON(defaults));
+    }
+}
+
+func TestResourceLimitsMaxRuntime(test *testing.T) {
+    oldValue := config.DOCKER_MAX_RUNTIME_SECS.Get();
+    config.DOCKER_MAX_RUNTIME_SECS.Set(30);
+    defer config.DOCKER_MAX_RUNTIME_SECS.Set(oldValue);
+
+    limits := ResourceLimits{};
+    if (limits.GetMaxRuntime() != (30 * time.Second)) {
+        test.Fatalf("Unexpected default max runtime: '%s'.", limits.GetMaxRuntime());
+    }
+
+    limits.MaxRuntimeSecs = 5;
+    if (limits.GetMaxRuntime() != (5 * time.Second)) {
+        test.Fatalf("Unexpected max runtime: '%s'.", limits.GetMaxRuntime());
+    }
+
+    config.DOCKER_MAX_RUNTIME_SECS.Set(0);
+    limits.MaxRuntimeSecs = 0;
+    if (limits.GetMaxRuntime() != 0) {
+        test.Fatalf("Expected no max runtime, found: '%s'.", limits.GetMaxRuntime());
+    }
+}
+
+func TestResourceLimitsToDocker(test *testing.T) {
+    limits := ResourceLimits{MemoryLimitMB: 2, CPULimit: 0.5, PIDsLimit: 10};
+    resources := limits.toDockerResources();
+
+    if ((resources.Memory != (2 * 1024 * 1024)) || (resources.MemorySwap != resources.Memory)) {
+        test.Errorf("Unexpected memory limits: %d, %d.", resources.Memory, resources.MemorySwap);
+    }
+
+    if (resources.NanoCPUs != 500000000) {
+        test.Errorf("Unexpected CPU limit: %d.", resources.NanoCPUs);
+    }
+
+    if ((resources.PidsLimit == nil) || (*resources.PidsLimit != 10)) {
+        test.Errorf("Unexpected PIDs limit: %v.", resources.PidsLimit);
+    }
+
+    empty := (&ResourceLimits{}).toDockerResources();
+    if ((empty.Memory != 0) || (empty.NanoCPUs != 0) || (empty.PidsLimit != nil)) {
+        test.Errorf("Empty limits should not set any resources.");
+    }
+}
@@ -24,6 +24,8 @@ type ImageInfo struct {
 
     PostSubmissionFileOperations []common.FileOperation `json:"post-submission-files-ops,omitempty"`
 
+    ResourceLimits
+
     // Fields that are not part of the JSON and are set after deserialization.
 
     Name string `json:"-"`
@@ -115,5 +117,10 @@ func (this *ImageInfo) Validate() error {
         return fmt.Errorf("Failed to validate post-submission file operations: '%w'.", err);
     }
 
+    err = this.ResourceLimits.Validate();
+    if (err != nil) {
+        return fmt.Errorf("Failed to validate resource limits: '%w'.", err);
+    }
+
     return nil;
 }
@@ -25,6 +25,12 @@ func TestImageInfoStruct(test *testing.T) {
                 common.FileOperation([]string{"a"}),
                 common.FileOperation([]string{"b", "c"}),
             },
+            ResourceLimits: ResourceLimits{
+                MaxRuntimeSecs: 10,
+                MemoryLimitMB: 256,
+                CPULimit: 0.5,
+                PIDsLimit: 64,
+            },
             Name: "foo",
             BaseDir: "bar",
         },
@@ -1,9 +1,12 @@
 package docker
 
 import (
+    "context"
     "fmt"
+    "io"
     "regexp"
     "strings"
+    "time"
 
     "github.com/docker/docker/api/types"
     "github.com/docker/docker/api/types/container"
@@ -14,7 +17,16 @@ import (
     "github.com/edulinq/autograder/util"
 )
 
-func RunContainer(logId log.Loggable, imageName string, inputDir string, outputDir string, gradingID string) (string, string, error) {
+// Run a grading container and return its stdout and stderr.
+// If the container hits its max runtime or runs out of memory, it is killed and a *ResourceLimitError is returned
+// (along with any output the container produced).
+// If |onStdout| is not nil, it will be called with each line of stdout as the container runs.
+func RunContainer(logId log.Loggable, imageName string, inputDir string, outputDir string, gradingID string, limits *ResourceLimits,
+        onStdout func(string)) (string, string, error) {
+    if (limits == nil) {
+        limits = &ResourceLimits{};
+    }
+
     ctx, docker, err := getDockerClient();
     if (err != nil) {
         return "", "", err;
@@ -34,7 +46,9 @@ func RunContainer(logId log.Loggable, imageName string, inputDir string, outputD
             NetworkDisabled: true,
         },
         &container.HostConfig{
-            AutoRemove: true,
+            // The container is removed manually so it can be inspected after it stops.
+            AutoRemove: false,
+            Resources: limits.toDockerResources(),
             Mounts: []mount.Mount{
                 mount.Mount{
                     Type: "bind",
@@ -58,6 +72,15 @@ func RunContainer(logId log.Loggable, imageName string, inputDir string, outputD
         return "", "", fmt.Errorf("Failed to create container '%s': '%w'.", name, err);
     }
 
+    defer func() {
+        // Use a fresh context, the container may need to be cleaned up after a timeout.
+        err := docker.ContainerRemove(context.Background(), containerInstance.ID, types.ContainerRemoveOptions{Force: true});
+        if (err != nil) {
+            log.Warn("Failed to remove container.", err, logId,
+                    log.NewAttr("container-name", name), log.NewAttr("container-id", containerInstance.ID));
+        }
+    }();
+
     err = docker.ContainerStart(ctx, containerInstance.ID, types.ContainerStartOptions{});
     if (err != nil) {
         return "", "", fmt.Errorf("Failed to start container '%s' (%s): '%w'.", name, containerInstance.ID, err);
@@ -75,8 +98,42 @@ func RunContainer(logId log.Loggable, imageName string, inputDir string, outputD
                 err, logId,
                 log.NewAttr("container-name", name), log.NewAttr("container-id", containerInstance.ID));
         out = nil;
+    } else {
+        defer out.Close();
+    }
+
+    // Read the output as the container runs.
+    outBuffer := new(strings.Builder);
+    errBuffer := new(strings.Builder);
+    copyDone := make(chan struct{});
+
+    if (out != nil) {
+        go func() {
+            defer close(copyDone);
+
+            var stdoutWriter io.Writer = outBuffer;
+            if (onStdout != nil) {
+                lineWriter := util.NewLineWriter(onStdout);
+                defer lineWriter.Flush();
+
+                stdoutWriter = io.MultiWriter(outBuffer, lineWriter);
+            }
+
+            stdcopy.StdCopy(stdoutWriter, errBuffer, out);
+        }();
+    } else {
+        close(copyDone);
+    }
+
+    var timeoutChan <-chan time.Time = nil;
+    maxRuntime := limits.GetMaxRuntime();
+    if (maxRuntime > 0) {
+        timer := time.NewTimer(maxRuntime);
+        defer timer.Stop();
+        timeoutChan = timer.C;
     }
-    defer out.Close()
+
+    var limitErr *ResourceLimitError = nil;
 
     statusChan, errorChan := docker.ContainerWait(ctx, containerInstance.ID, container.WaitConditionNotRunning);
     select {
@@ -86,18 +143,42 @@ func RunContainer(logId log.Loggable, imageName string, inputDir string, outputD
             }
         case <-statusChan:
             // Waiting is complete.
+        case <-timeoutChan:
+            log.Warn("Grading container hit its max runtime, killing it.", logId,
+                    log.NewAttr("container-name", name), log.NewAttr("container-id", containerInstance.ID),
+                    log.NewAttr("max-runtime", maxRuntime.String()));
+
+            err = docker.ContainerKill(ctx, containerInstance.ID, "SIGKILL");
+            if (err != nil) {
+                return "", "", fmt.Errorf("Failed to kill container '%s' (%s) after it timed out: '%w'.", name, containerInstance.ID, err);
+            }
+
+            limitErr = &ResourceLimitError{
+                Kind: LIMIT_KIND_TIMEOUT,
+                Message: fmt.Sprintf("Grading did not finish within the max runtime (%s).", maxRuntime.String()),
+            };
+    }
+
+    if (limitErr == nil) {
+        inspect, err := docker.ContainerInspect(ctx, containerInstance.ID);
+        if (err != nil) {
+            log.Warn("Failed to inspect container after it finished.", err, logId,
+                    log.NewAttr("container-name", name), log.NewAttr("container-id", containerInstance.ID));
+        } else if ((inspect.State != nil) && inspect.State.OOMKilled) {
+            limitErr = &ResourceLimitError{
+                Kind: LIMIT_KIND_OUT_OF_MEMORY,
+                Message: fmt.Sprintf("Grading ran out of memory (limit: %d MB).", limits.MemoryLimitMB),
+            };
+        }
     }
 
     stdout := "";
     stderr := "";
 
-    // Read the output after the container is done.
-    if (out != nil) {
-        outBuffer := new(strings.Builder);
-        errBuffer := new(strings.Builder);
-
-        stdcopy.StdCopy(outBuffer, errBuffer, out);
+    // The output stream ends once the container stops.
+    <-copyDone;
 
+    if (out != nil) {
         stdout = outBuffer.String();
         stderr = errBuffer.String();
 
@@ -109,6 +190,10 @@ func RunContainer(logId log.Loggable, imageName string, inputDir string, outputD
                 log.NewAttr("stderr", stderr));
     }
 
+    if (limitErr != nil) {
+        return stdout, stderr, limitErr;
+    }
+
     return stdout, stderr, nil;
 }
 
@@ -11,6 +11,13 @@ type Message struct {
     Subject string `json:"subject"`
     Body string `json:"body"`
     HTML bool `json:"html"`
+
+    // The course this message is about (if any).
+    CourseID string `json:"course-id,omitempty"`
+
+    // The body contains a secret (e.g., a password or token).
+    // Sensitive messages are always sent right away and are never saved in the outbox.
+    Sensitive bool `json:"sensitive,omitempty"`
 }
 
 // Get the raw email formatted string (bytes).
@@ -0,0 +1,330 @@
+package email
+
+// A persistent queue of outgoing messages.
+// Once the sender has been started (see StartSender()), messages are saved to the outbox (usually the database)
+// and sent in the background, so a failure to reach the SMTP server does not lose any messages.
+// Messages that fail to send are retried (with exponential backoff) until they run out of attempts,
+// after which they stay in the outbox as failed messages (that can be retried manually).
+// Once a message is sent, its body is dropped (only the information about the message is kept).
+// Sensitive messages (see Message.Sensitive) never go through the outbox,
+// they are sent right away and only retried in memory (see sendSensitive()).
+
+import (
+    "fmt"
+    "slices"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    OUTBOX_STATUS_PENDING = "pending"
+    OUTBOX_STATUS_SENT = "sent"
+    OUTBOX_STATUS_FAILED = "failed"
+)
+
+const (
+    // How often the sender checks for messages to retry (new messages are sent right away).
+    SENDER_POLL_INTERVAL = 30 * time.Second;
+    // The longest to ever wait between attempts.
+    MAX_BACKOFF = 6 * time.Hour;
+)
+
+type OutboxMessage struct {
+    ID string `json:"id"`
+    Message *Message `json:"message"`
+
+    Status string `json:"status"`
+    Attempts int `json:"attempts"`
+
+    CreatedTime common.Timestamp `json:"created-time"`
+    LastAttemptTime common.Timestamp `json:"last-attempt-time,omitempty"`
+    // When a pending message should next be attempted.
+    NextAttemptTime common.Timestamp `json:"next-attempt-time,omitempty"`
+    LastError string `json:"last-error,omitempty"`
+}
+
+// Storage for the outbox.
+type OutboxBackend interface {
+    // Get all the messages in the outbox (in the order they were created).
+    GetOutboxMessages() ([]*OutboxMessage, error);
+
+    // Upsert messages (keyed by ID).
+    SaveOutboxMessages(messages []*OutboxMessage) error;
+
+    // Remove messages (by ID).
+    RemoveOutboxMessages(ids []string) error;
+}
+
+var outbox OutboxBackend = nil;
+
+var senderLock sync.Mutex;
+var senderRunning bool = false;
+var senderWake chan bool = make(chan bool, 1);
+
+// Only one pass over the outbox may run at a time.
+var sendPendingLock sync.Mutex;
+
+func SetOutboxBackend(backend OutboxBackend) {
+    outbox = backend;
+}
+
+// Start sending queued messages in the background.
+// After this is called (and while there is an outbox), all messages will go through the outbox.
+// Calling this more than once has no effect.
+func StartSender() {
+    senderLock.Lock();
+    defer senderLock.Unlock();
+
+    if (senderRunning) {
+        return;
+    }
+
+    senderRunning = true;
+
+    go func() {
+        for {
+            err := SendPending();
+            if (err != nil) {
+                log.Error("Failed to send queued emails.", err);
+            }
+
+            select {
+                case <-senderWake:
+                case <-time.After(SENDER_POLL_INTERVAL):
+            }
+        }
+    }();
+}
+
+// Check if messages should be queued instead of sent directly.
+func useOutbox() bool {
+    senderLock.Lock();
+    defer senderLock.Unlock();
+
+    return (senderRunning && (outbox != nil));
+}
+
+// Add a message to the outbox (it will be sent by the next pass over the outbox).
+func Enqueue(message *Message) (*OutboxMessage, error) {
+    if (outbox == nil) {
+        return nil, fmt.Errorf("There is no email outbox.");
+    }
+
+    if (message.Sensitive) {
+        return nil, fmt.Errorf("Sensitive messages cannot be saved in the outbox.");
+    }
+
+    now := common.NowTimestamp();
+    outboxMessage := &OutboxMessage{
+        ID: util.UUID(),
+        Message: message,
+        Status: OUTBOX_STATUS_PENDING,
+        CreatedTime: now,
+        NextAttemptTime: now,
+    };
+
+    err := outbox.SaveOutboxMessages([]*OutboxMessage{outboxMessage});
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to save message to outbox: '%w'.", err);
+    }
+
+    wakeSender();
+
+    return outboxMessage, nil;
+}
+
+// Make one pass over the outbox:
+// attempt all pending messages that are due and remove sent messages older than the retention period.
+func SendPending() error {
+    if (outbox == nil) {
+        return nil;
+    }
+
+    sendPendingLock.Lock();
+    defer sendPendingLock.Unlock();
+
+    messages, err := outbox.GetOutboxMessages();
+    if (err != nil) {
+        return fmt.Errorf("Failed to get outbox messages: '%w'.", err);
+    }
+
+    now := time.Now();
+    retention := time.Duration(config.EMAIL_RETENTION_HOURS.Get()) * time.Hour;
+    expiredIDs := make([]string, 0);
+
+    for _, message := range messages {
+        if (message.Status == OUTBOX_STATUS_SENT) {
+            lastAttempt, err := message.LastAttemptTime.Time();
+            if ((err == nil) && (now.Sub(lastAttempt) > retention)) {
+                expiredIDs = append(expiredIDs, message.ID);
+            }
+
+            continue;
+        }
+
+        if (!message.IsDue(now)) {
+            continue;
+        }
+
+        err = attempt(message);
+        if (err != nil) {
+            return err;
+        }
+    }
+
+    if (len(expiredIDs) > 0) {
+        err = outbox.RemoveOutboxMessages(expiredIDs);
+        if (err != nil) {
+            return fmt.Errorf("Failed to remove old outbox messages: '%w'.", err);
+        }
+    }
+
+    return nil;
+}
+
+// Get the messages in the outbox.
+// Empty arguments are not used for filtering.
+func GetOutbox(courseID string, status string) ([]*OutboxMessage, error) {
+    if (outbox == nil) {
+        return nil, fmt.Errorf("There is no email outbox.");
+    }
+
+    messages, err := outbox.GetOutboxMessages();
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get outbox messages: '%w'.", err);
+    }
+
+    messages = slices.DeleteFunc(messages, func(message *OutboxMessage) bool {
+        if ((courseID != "") && (message.Message.CourseID != courseID)) {
+            return true;
+        }
+
+        return ((status != "") && (message.Status != status));
+    });
+
+    return messages, nil;
+}
+
+// Queue failed messages to be sent again (with a fresh set of attempts).
+// Empty arguments are not used for filtering.
+// Returns the retried messages.
+func RetryFailed(courseID string) ([]*OutboxMessage, error) {
+    messages, err := GetOutbox(courseID, OUTBOX_STATUS_FAILED);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (len(messages) == 0) {
+        return messages, nil;
+    }
+
+    now := common.NowTimestamp();
+    for _, message := range messages {
+        message.Status = OUTBOX_STATUS_PENDING;
+        message.Attempts = 0;
+        message.NextAttemptTime = now;
+    }
+
+    err = outbox.SaveOutboxMessages(messages);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to save retried outbox messages: '%w'.", err);
+    }
+
+    wakeSender();
+
+    return messages, nil;
+}
+
+// Wake up the sender (if it is not already awake).
+func wakeSender() {
+    select {
+        case senderWake <- true:
+        default:
+    }
+}
+
+func (this *OutboxMessage) LogValue() []*log.Attr {
+    return []*log.Attr{
+        log.NewAttr("outbox-id", this.ID),
+        log.NewAttr("to", this.Message.To),
+        log.NewAttr("subject", this.Message.Subject),
+        log.NewCourseAttr(this.Message.CourseID),
+    };
+}
+
+// Get a copy of this message without the message body (e.g., for listing the outbox).
+func (this *OutboxMessage) WithoutBody() *OutboxMessage {
+    result := *this;
+
+    if (this.Message != nil) {
+        message := *this.Message;
+        message.Body = "";
+        result.Message = &message;
+    }
+
+    return &result;
+}
+
+// Check if a message should be attempted (again) at the given time.
+func (this *OutboxMessage) IsDue(now time.Time) bool {
+    if (this.Status != OUTBOX_STATUS_PENDING) {
+        return false;
+    }
+
+    if (this.NextAttemptTime.IsZero()) {
+        return true;
+    }
+
+    nextAttempt, err := this.NextAttemptTime.Time();
+    if (err != nil) {
+        // A bad time should not leave a message stuck.
+        return true;
+    }
+
+    return !nextAttempt.After(now);
+}
+
+// Make one attempt at sending a message and save the outcome.
+func attempt(message *OutboxMessage) error {
+    message.Attempts++;
+    message.LastAttemptTime = common.NowTimestamp();
+
+    err := sendNow(message.Message);
+    if (err == nil) {
+        message.Status = OUTBOX_STATUS_SENT;
+        message.NextAttemptTime = "";
+        message.LastError = "";
+        // The body is no longer needed.
+        message.Message.Body = "";
+
+        log.Debug("Sent queued email.", message);
+    } else if (message.Attempts >= config.EMAIL_MAX_ATTEMPTS.Get()) {
+        message.Status = OUTBOX_STATUS_FAILED;
+        message.NextAttemptTime = "";
+        message.LastError = err.Error();
+
+        log.Error("Queued email failed too many times, giving up.", err, message, log.NewAttr("attempts", message.Attempts));
+    } else {
+        message.NextAttemptTime = common.TimestampFromTime(time.Now().Add(getBackoff(message.Attempts)));
+        message.LastError = err.Error();
+
+        log.Warn("Failed to send queued email, will retry.", err, message, log.NewAttr("attempts", message.Attempts));
+    }
+
+    err = outbox.SaveOutboxMessages([]*OutboxMessage{message});
+    if (err != nil) {
+        return fmt.Errorf("Failed to save outbox message '%s': '%w'.", message.ID, err);
+    }
+
+    return nil;
+}
+
+// The time to wait after the given number of failed attempts.
+func getBackoff(attempts int) time.Duration {
+    return util.ExponentialBackoff(time.Duration(config.EMAIL_BACKOFF_SECS.Get()) * time.Second, attempts, MAX_BACKOFF);
+}
@@ -0,0 +1,241 @@
+package email
+
+import (
+    "slices"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/util"
+)
+
+// An in-memory outbox.
+type testOutbox struct {
+    messages []*OutboxMessage
+}
+
+func (this *testOutbox) GetOutboxMessages() ([]*OutboxMessage, error) {
+    messages := make([]*OutboxMessage, 0, len(this.messages));
+    for _, message := range this.messages {
+        // Copy so changes are only seen after a save.
+        messageCopy := *message;
+        messages = append(messages, &messageCopy);
+    }
+
+    return messages, nil;
+}
+
+func (this *testOutbox) SaveOutboxMessages(messages []*OutboxMessage) error {
+    for _, message := range messages {
+        messageCopy := *message;
+
+        index := slices.IndexFunc(this.messages, func(other *OutboxMessage) bool { return (other.ID == message.ID) });
+        if (index >= 0) {
+            this.messages[index] = &messageCopy;
+        } else {
+            this.messages = append(this.messages, &messageCopy);
+        }
+    }
+
+    return nil;
+}
+
+func (this *testOutbox) RemoveOutboxMessages(ids []string) error {
+    this.messages = slices.DeleteFunc(this.messages, func(message *OutboxMessage) bool {
+        return slices.Contains(ids, message.ID);
+    });
+
+    return nil;
+}
+
+func TestOutboxRetries(test *testing.T) {
+    defer SetOutboxBackend(nil);
+    SetOutboxBackend(&testOutbox{});
+
+    defer ClearTestMessages();
+    ClearTestMessages();
+
+    // Send to a port that nothing listens on so every attempt fails.
+    defer config.TESTING_MODE.Set(true);
+    config.TESTING_MODE.Set(false);
+
+    defer config.EMAIL_HOST.Set(config.EMAIL_HOST.Get());
+    config.EMAIL_HOST.Set("127.0.0.1");
+
+    defer config.EMAIL_PORT.Set(config.EMAIL_PORT.Get());
+    config.EMAIL_PORT.Set("1");
+
+    defer config.EMAIL_RATE.Set(config.EMAIL_RATE.Get());
+    config.EMAIL_RATE.Set(0);
+
+    defer config.EMAIL_BACKOFF_SECS.Set(config.EMAIL_BACKOFF_SECS.Get());
+    config.EMAIL_BACKOFF_SECS.Set(0);
+
+    defer config.EMAIL_MAX_ATTEMPTS.Set(config.EMAIL_MAX_ATTEMPTS.Get());
+    config.EMAIL_MAX_ATTEMPTS.Set(2);
+
+    queued, err := Enqueue(&Message{To: []string{"a@test.com"}, Subject: "test", Body: "test", CourseID: "course101"});
+    if (err != nil) {
+        test.Fatalf("Failed to enqueue message: '%v'.", err);
+    }
+
+    expectedStatuses := []string{OUTBOX_STATUS_PENDING, OUTBOX_STATUS_FAILED};
+    for i, expectedStatus := range expectedStatuses {
+        err = SendPending();
+        if (err != nil) {
+            test.Fatalf("Attempt %d: Failed to send pending messages: '%v'.", i, err);
+        }
+
+        messages, err := GetOutbox("course101", "");
+        if (err != nil) {
+            test.Fatalf("Attempt %d: Failed to get outbox: '%v'.", i, err);
+        }
+
+        if ((len(messages) != 1) || (messages[0].ID != queued.ID)) {
+            test.Fatalf("Attempt %d: Unexpected outbox: '%s'.", i, util.MustToJSONIndent(messages));
+        }
+
+        if ((messages[0].Status != expectedStatus) || (messages[0].Attempts != (i + 1)) || (messages[0].LastError == "")) {
+            test.Fatalf("Attempt %d: Unexpected message. Expected status: '%s', Actual: '%s'.", i, expectedStatus, util.MustToJSONIndent(messages[0]));
+        }
+    }
+
+    // Failed messages are not attempted again until they are retried.
+    config.TESTING_MODE.Set(true);
+
+    err = SendPending();
+    if (err != nil) {
+        test.Fatalf("Failed to send pending messages: '%v'.", err);
+    }
+
+    if (len(GetTestMessages()) != 0) {
+        test.Fatalf("Failed message was sent without a retry.");
+    }
+
+    retried, err := RetryFailed("course101");
+    if (err != nil) {
+        test.Fatalf("Failed to retry failed messages: '%v'.", err);
+    }
+
+    if ((len(retried) != 1) || (retried[0].ID != queued.ID) || (retried[0].Attempts != 0)) {
+        test.Fatalf("Unexpected retried messages: '%s'.", util.MustToJSONIndent(retried));
+    }
+
+    err = SendPending();
+    if (err != nil) {
+        test.Fatalf("Failed to send pending messages: '%v'.", err);
+    }
+
+    if (len(GetTestMessages()) != 1) {
+        test.Fatalf("Retried message was not sent.");
+    }
+
+    messages, err := GetOutbox("", OUTBOX_STATUS_SENT);
+    if (err != nil) {
+        test.Fatalf("Failed to get outbox: '%v'.", err);
+    }
+
+    // The body is dropped once the message is sent.
+    if ((len(messages) != 1) || (messages[0].LastError != "") || (messages[0].Message.Body != "")) {
+        test.Fatalf("Unexpected sent messages: '%s'.", util.MustToJSONIndent(messages));
+    }
+}
+
+// Sensitive messages are sent right away instead of being saved in the outbox.
+func TestOutboxSensitiveMessages(test *testing.T) {
+    backend := &testOutbox{};
+
+    defer SetOutboxBackend(nil);
+    SetOutboxBackend(backend);
+
+    defer ClearTestMessages();
+    ClearTestMessages();
+
+    // Act like the sender is running (without starting a background sender).
+    defer setSenderRunning(false);
+    setSenderRunning(true);
+
+    message := &Message{To: []string{"a@test.com"}, Subject: "test", Body: "secret", CourseID: "course101", Sensitive: true};
+
+    err := SendMessage(message);
+    if (err != nil) {
+        test.Fatalf("Failed to send message: '%v'.", err);
+    }
+
+    if ((len(GetTestMessages()) != 1) || (GetTestMessages()[0].Body != "secret")) {
+        test.Fatalf("Sensitive message was not sent: '%s'.", util.MustToJSONIndent(GetTestMessages()));
+    }
+
+    if (len(backend.messages) != 0) {
+        test.Fatalf("Sensitive message was saved to the outbox: '%s'.", util.MustToJSONIndent(backend.messages));
+    }
+
+    _, err = Enqueue(message);
+    if (err == nil) {
+        test.Fatalf("Did not get an error when queueing a sensitive message.");
+    }
+
+    if (len(backend.messages) != 0) {
+        test.Fatalf("Sensitive message was queued: '%s'.", util.MustToJSONIndent(backend.messages));
+    }
+}
+
+func TestOutboxPrunesOldMessages(test *testing.T) {
+    old := common.TimestampFromTime(time.Now().Add(-48 * time.Hour));
+    recent := common.NowTimestamp();
+
+    backend := &testOutbox{messages: []*OutboxMessage{
+        &OutboxMessage{ID: "old-sent", Message: &Message{}, Status: OUTBOX_STATUS_SENT, LastAttemptTime: old},
+        &OutboxMessage{ID: "recent-sent", Message: &Message{}, Status: OUTBOX_STATUS_SENT, LastAttemptTime: recent},
+        &OutboxMessage{ID: "old-failed", Message: &Message{}, Status: OUTBOX_STATUS_FAILED, LastAttemptTime: old},
+    }};
+
+    defer SetOutboxBackend(nil);
+    SetOutboxBackend(backend);
+
+    defer config.EMAIL_RETENTION_HOURS.Set(config.EMAIL_RETENTION_HOURS.Get());
+    config.EMAIL_RETENTION_HOURS.Set(24);
+
+    err := SendPending();
+    if (err != nil) {
+        test.Fatalf("Failed to send pending messages: '%v'.", err);
+    }
+
+    ids := make([]string, 0, len(backend.messages));
+    for _, message := range backend.messages {
+        ids = append(ids, message.ID);
+    }
+
+    // Failed messages are kept so they can be retried.
+    expected := []string{"recent-sent", "old-failed"};
+    if (!slices.Equal(expected, ids)) {
+        test.Fatalf("Unexpected messages. Expected: '%v', Actual: '%v'.", expected, ids);
+    }
+}
+
+func TestGetBackoff(test *testing.T) {
+    defer config.EMAIL_BACKOFF_SECS.Set(config.EMAIL_BACKOFF_SECS.Get());
+    config.EMAIL_BACKOFF_SECS.Set(60);
+
+    testCases := []struct{ attempts int; expected time.Duration }{
+        {1, time.Minute},
+        {2, 2 * time.Minute},
+        {3, 4 * time.Minute},
+        {100, MAX_BACKOFF},
+    };
+
+    for i, testCase := range testCases {
+        actual := getBackoff(testCase.attempts);
+        if (testCase.expected != actual) {
+            test.Errorf("Case %d: Unexpected backoff. Expected: '%v', Actual: '%v'.", i, testCase.expected, actual);
+        }
+    }
+}
+
+func setSenderRunning(value bool) {
+    senderLock.Lock();
+    defer senderLock.Unlock();
+
+    senderRunning = value;
+}
@@ -3,13 +3,25 @@ package email
 import (
     "fmt"
     "net/smtp"
+    "sync"
+    "time"
 
     "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
 )
 
+// The longest to ever wait between attempts at sending a sensitive message
+// (the sender is waiting on the result).
+const MAX_SENSITIVE_BACKOFF = 30 * time.Second;
+
 // Messages that are stored (instead of sent) in testing mode.
 var testMessages []*Message = nil;
 
+// The last time a message was sent (for rate limiting).
+var rateLock sync.Mutex;
+var lastSendTime time.Time;
+
 func Send(to []string, subject string, body string, html bool) error {
     return SendMessage(&Message{
         To: to,
@@ -19,21 +31,86 @@ func Send(to []string, subject string, body string, html bool) error {
     });
 }
 
+// Send a message.
+// If the sender is running (see StartSender()), then the message is queued in the outbox instead of sent right away.
+// Sensitive messages are never queued, see sendSensitive().
 func SendMessage(message *Message) error {
-    auth := smtp.PlainAuth("", config.EMAIL_USER.Get(), config.EMAIL_PASS.Get(), config.EMAIL_HOST.Get());
+    if (message.Sensitive) {
+        return sendSensitive(message);
+    }
 
-    serverAddress := fmt.Sprintf("%s:%s", config.EMAIL_HOST.Get(), config.EMAIL_PORT.Get());
-    content := message.ToContent();
+    if (useOutbox()) {
+        _, err := Enqueue(message);
+        return err;
+    }
+
+    return sendNow(message);
+}
+
+// Send a sensitive message right away, retrying (with backoff) a few times before giving up.
+// The message is only ever kept in memory (so it cannot be retried later),
+// and an error is returned if every attempt failed (so the caller can report it).
+func sendSensitive(message *Message) error {
+    maxAttempts := max(1, config.EMAIL_SENSITIVE_ATTEMPTS.Get());
+    baseBackoff := time.Duration(config.EMAIL_SENSITIVE_BACKOFF_MS.Get()) * time.Millisecond;
+
+    var err error = nil;
+    for attempts := 1; attempts <= maxAttempts; attempts++ {
+        err = sendNow(message);
+        if (err == nil) {
+            return nil;
+        }
+
+        if (attempts == maxAttempts) {
+            break;
+        }
+
+        log.Warn("Failed to send sensitive email, will retry.", err,
+                log.NewAttr("to", message.To), log.NewAttr("subject", message.Subject), log.NewCourseAttr(message.CourseID),
+                log.NewAttr("attempts", attempts));
+
+        time.Sleep(util.ExponentialBackoff(baseBackoff, attempts, MAX_SENSITIVE_BACKOFF));
+    }
 
+    return fmt.Errorf("Failed to send sensitive email after %d attempt(s): '%w'.", maxAttempts, err);
+}
+
+func sendNow(message *Message) error {
     // In testing mode, just store the message.
     if (config.TESTING_MODE.Get()) {
         testMessages = append(testMessages, message);
         return nil;
     }
 
+    auth := smtp.PlainAuth("", config.EMAIL_USER.Get(), config.EMAIL_PASS.Get(), config.EMAIL_HOST.Get());
+
+    serverAddress := fmt.Sprintf("%s:%s", config.EMAIL_HOST.Get(), config.EMAIL_PORT.Get());
+    content := message.ToContent();
+
+    waitForRateLimit();
+
     return smtp.SendMail(serverAddress, auth, config.EMAIL_FROM.Get(), message.To, content);
 }
 
+// Wait until another message can be sent without going over the rate limit.
+func waitForRateLimit() {
+    rate := config.EMAIL_RATE.Get();
+    if (rate <= 0) {
+        return;
+    }
+
+    rateLock.Lock();
+    defer rateLock.Unlock();
+
+    interval := time.Minute / time.Duration(rate);
+    wait := interval - time.Since(lastSendTime);
+    if (wait > 0) {
+        time.Sleep(wait);
+    }
+
+    lastSendTime = time.Now();
+}
+
 func GetTestMessages() []*Message {
     return testMessages;
 }
@@ -0,0 +1,193 @@
+package email
+
+import (
+    "fmt"
+    "net"
+    "net/textproto"
+    "slices"
+    "strings"
+    "sync"
+    "testing"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/util"
+)
+
+// A minimal SMTP server that drops the first |failures| connections.
+type testSMTPServer struct {
+    listener net.Listener
+
+    lock sync.Mutex
+    failures int
+    connections int
+    messages []string
+}
+
+// Sensitive messages are retried in memory (and never saved to the outbox).
+func TestSendSensitiveRetries(test *testing.T) {
+    testCases := []struct{ failures int; attempts int; expectSent bool }{
+        {0, 3, true},
+        {2, 3, true},
+        {3, 3, false},
+        {1, 1, false},
+        {1, 0, false},
+    };
+
+    for i, testCase := range testCases {
+        expectedAttempts := min(testCase.failures + 1, max(1, testCase.attempts));
+
+        backend, server, err := sendTestSensitiveMessage(test, testCase.failures, testCase.attempts);
+        connections, messages := server.getResults();
+
+        if (testCase.expectSent && (err != nil)) {
+            test.Errorf("Case %d: Failed to send message: '%v'.", i, err);
+            continue;
+        }
+
+        if (!testCase.expectSent && (err == nil)) {
+            test.Errorf("Case %d: Did not get an error when every attempt failed.", i);
+            continue;
+        }
+
+        if (connections != expectedAttempts) {
+            test.Errorf("Case %d: Unexpected number of attempts. Expected: %d, Actual: %d.", i, expectedAttempts, connections);
+            continue;
+        }
+
+        if (testCase.expectSent && ((len(messages) != 1) || !strings.Contains(messages[0], "secret"))) {
+            test.Errorf("Case %d: Unexpected sent messages: '%s'.", i, util.MustToJSONIndent(messages));
+            continue;
+        }
+
+        if (len(backend.messages) != 0) {
+            test.Errorf("Case %d: Sensitive message was saved to the outbox: '%s'.", i, util.MustToJSONIndent(backend.messages));
+            continue;
+        }
+    }
+}
+
+// Send a sensitive message (while the sender is running) to an SMTP server that drops the first |failures| connections.
+func sendTestSensitiveMessage(test *testing.T, failures int, attempts int) (*testOutbox, *testSMTPServer, error) {
+    server := startTestSMTPServer(test, failures);
+    defer server.listener.Close();
+
+    backend := &testOutbox{};
+    defer SetOutboxBackend(nil);
+    SetOutboxBackend(backend);
+
+    // Act like the sender is running (without starting a background sender).
+    defer setSenderRunning(false);
+    setSenderRunning(true);
+
+    defer config.TESTING_MODE.Set(true);
+    config.TESTING_MODE.Set(false);
+
+    defer config.EMAIL_HOST.Set(config.EMAIL_HOST.Get());
+    config.EMAIL_HOST.Set("127.0.0.1");
+
+    defer config.EMAIL_PORT.Set(config.EMAIL_PORT.Get());
+    config.EMAIL_PORT.Set(fmt.Sprintf("%d", server.listener.Addr().(*net.TCPAddr).Port));
+
+    defer config.EMAIL_FROM.Set(config.EMAIL_FROM.Get());
+    config.EMAIL_FROM.Set("autograder@test.com");
+
+    defer config.EMAIL_RATE.Set(config.EMAIL_RATE.Get());
+    config.EMAIL_RATE.Set(0);
+
+    defer config.EMAIL_SENSITIVE_ATTEMPTS.Set(config.EMAIL_SENSITIVE_ATTEMPTS.Get());
+    config.EMAIL_SENSITIVE_ATTEMPTS.Set(attempts);
+
+    defer config.EMAIL_SENSITIVE_BACKOFF_MS.Set(config.EMAIL_SENSITIVE_BACKOFF_MS.Get());
+    config.EMAIL_SENSITIVE_BACKOFF_MS.Set(1);
+
+    message := &Message{To: []string{"a@test.com"}, Subject: "test", Body: "secret", CourseID: "course101", Sensitive: true};
+    err := SendMessage(message);
+
+    return backend, server, err;
+}
+
+func startTestSMTPServer(test *testing.T, failures int) *testSMTPServer {
+    listener, err := net.Listen("tcp", "127.0.0.1:0");
+    if (err != nil) {
+        test.Fatalf("Failed to start test SMTP server: '%v'.", err);
+    }
+
+    server := &testSMTPServer{
+        listener: listener,
+        failures: failures,
+        messages: make([]string, 0),
+    };
+
+    go func() {
+        for {
+            conn, err := listener.Accept();
+            if (err != nil) {
+                return;
+            }
+
+            server.handle(conn);
+        }
+    }();
+
+    return server;
+}
+
+func (this *testSMTPServer) getResults() (int, []string) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    return this.connections, slices.Clone(this.messages);
+}
+
+func (this *testSMTPServer) handle(conn net.Conn) {
+    defer conn.Close();
+
+    this.lock.Lock();
+    this.connections++;
+    fail := (this.connections <= this.failures);
+    this.lock.Unlock();
+
+    if (fail) {
+        return;
+    }
+
+    text := textproto.NewConn(conn);
+    text.PrintfLine("220 localhost ESMTP");
+
+    for {
+        line, err := text.ReadLine();
+        if (err != nil) {
+            return;
+        }
+
+        command := strings.ToUpper(line);
+
+        switch {
+            case strings.HasPrefix(command, "EHLO"):
+                text.PrintfLine("250-localhost");
+                text.PrintfLine("250 AUTH PLAIN");
+            case strings.HasPrefix(command, "AUTH"):
+                text.PrintfLine("235 Authenticated");
+            case strings.HasPrefix(command, "MAIL"), strings.HasPrefix(command, "RCPT"):
+                text.PrintfLine("250 OK");
+            case strings.HasPrefix(command, "DATA"):
+                text.PrintfLine("354 Go ahead");
+
+                lines, err := text.ReadDotLines();
+                if (err != nil) {
+                    return;
+                }
+
+                this.lock.Lock();
+                this.messages = append(this.messages, strings.Join(lines, "\n"));
+                this.lock.Unlock();
+
+                text.PrintfLine("250 OK");
+            case strings.HasPrefix(command, "QUIT"):
+                text.PrintfLine("221 Bye");
+                return;
+            default:
+                text.PrintfLine("502 Not implemented");
+        }
+    }
+}
@@ -0,0 +1,163 @@
+package email
+
+// Templates for the emails the autograder sends.
+// Every template has a default, and courses can override any of them (see model.Course.EmailTemplates).
+// Subjects always use text/template, bodies use html/template for HTML emails and text/template otherwise.
+
+import (
+    "fmt"
+    htmltemplate "html/template"
+    "slices"
+    "strings"
+    texttemplate "text/template"
+
+    "golang.org/x/exp/maps"
+)
+
+const (
+    TEMPLATE_USER_ADD = "user-add"
+    TEMPLATE_USER_PASSWORD_CHANGE = "user-password-change"
+    TEMPLATE_PASSWORD_RESET = "password-reset"
+    TEMPLATE_SUBMISSION_RECEIPT = "submission-receipt"
+    TEMPLATE_SUBMISSION_REJECTION = "submission-rejection"
+    TEMPLATE_SUBMISSION_REMOVAL = "submission-removal"
+)
+
+type Template struct {
+    Subject string `json:"subject"`
+    Body string `json:"body"`
+    HTML bool `json:"html,omitempty"`
+}
+
+var defaultTemplates map[string]*Template = map[string]*Template{
+    TEMPLATE_USER_ADD: &Template{
+        Subject: "Autograder {{ .CourseID }} -- User Account Created",
+        Body: "Hello,\n" +
+            "\nAn autograder account with the username/email '{{ .Email }}' has been created for the course '{{ .CourseName }}'.\n" +
+            "Usage instructions will provided in class.\n" +
+            "{{ if .Password }}Your new password is '{{ .Password }}' (no quotes).\n{{ end }}",
+    },
+    TEMPLATE_USER_PASSWORD_CHANGE: &Template{
+        Subject: "Autograder {{ .CourseID }} -- User Password Changed",
+        Body: "Hello,\n" +
+            "\nThe password for '{{ .Email }}' has been changed for the course '{{ .CourseName }}'.\n" +
+            "{{ if .Password }}Your new password is '{{ .Password }}' (no quotes).\n{{ end }}",
+    },
+    TEMPLATE_PASSWORD_RESET: &Template{
+        Subject: "Autograder {{ .CourseID }} -- Password Reset",
+        Body: "Hello,\n" +
+            "\nA password reset was requested for '{{ .Email }}' in the course '{{ .CourseName }}'.\n" +
+            "Your password reset token is '{{ .Token }}' (no quotes).\n" +
+            "The token can only be used once and expires in {{ .TTLMinutes }} minutes.\n" +
+            "If you did not request a password reset, then you can ignore this email.\n",
+    },
+    TEMPLATE_SUBMISSION_RECEIPT: &Template{
+        Subject: "Autograder {{ .CourseID }} -- Submission Receipt for {{ .AssignmentID }}",
+        Body: "Hello,\n" +
+            "\nYour submission for '{{ .AssignmentName }}' in the course '{{ .CourseName }}' has been graded.\n\n" +
+            "Submission ID: {{ .SubmissionID }}\n" +
+            "Submitted By: {{ .User }}\n" +
+            "Submission Time: {{ .SubmissionTime }}\n" +
+            "Score: {{ .Score }} / {{ .MaxPoints }}\n" +
+            "{{ if .FailureReason }}Grading Stopped Early: {{ .FailureReason }}\n{{ end }}" +
+            "{{ if .Message }}Message: {{ .Message }}\n{{ end }}" +
+            "\nPlease keep this email as a receipt of your submission.\n",
+    },
+    TEMPLATE_SUBMISSION_REJECTION: &Template{
+        Subject: "Autograder {{ .CourseID }} -- Submission Rejected for {{ .AssignmentID }}",
+        Body: "Hello,\n" +
+            "\nYour submission for '{{ .AssignmentName }}' in the course '{{ .CourseName }}' was rejected and was not graded.\n" +
+            "Reason: {{ .Reason }}\n",
+    },
+    TEMPLATE_SUBMISSION_REMOVAL: &Template{
+        Subject: "Autograder {{ .CourseID }} -- Submission Removed for {{ .AssignmentID }}",
+        Body: "Hello,\n" +
+            "\nYour submission '{{ .SubmissionID }}' (score: {{ .Score }} / {{ .MaxPoints }}) for '{{ .AssignmentName }}'" +
+            " in the course '{{ .CourseName }}' was removed by '{{ .Remover }}'.\n" +
+            "The submission will no longer count towards your score.\n",
+    },
+};
+
+// Get the default template with the given name (or nil if there is no such template).
+func GetDefaultTemplate(name string) *Template {
+    return defaultTemplates[name];
+}
+
+// Get the names of all the templates (sorted).
+func GetTemplateNames() []string {
+    names := maps.Keys(defaultTemplates);
+    slices.Sort(names);
+
+    return names;
+}
+
+// Ensure that a template parses.
+func (this *Template) Validate() error {
+    if (strings.TrimSpace(this.Subject) == "") {
+        return fmt.Errorf("Email template must have a subject.");
+    }
+
+    _, err := texttemplate.New("subject").Option("missingkey=error").Parse(this.Subject);
+    if (err != nil) {
+        return fmt.Errorf("Failed to parse email template subject: '%w'.", err);
+    }
+
+    if (this.HTML) {
+        _, err = htmltemplate.New("body").Option("missingkey=error").Parse(this.Body);
+    } else {
+        _, err = texttemplate.New("body").Option("missingkey=error").Parse(this.Body);
+    }
+
+    if (err != nil) {
+        return fmt.Errorf("Failed to parse email template body: '%w'.", err);
+    }
+
+    return nil;
+}
+
+// Fill in a template to create a message.
+func (this *Template) Render(to []string, data any) (*Message, error) {
+    subjectTemplate, err := texttemplate.New("subject").Option("missingkey=error").Parse(this.Subject);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to parse email template subject: '%w'.", err);
+    }
+
+    var subject strings.Builder;
+    err = subjectTemplate.Execute(&subject, data);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to render email template subject: '%w'.", err);
+    }
+
+    var body strings.Builder;
+    if (this.HTML) {
+        bodyTemplate, err := htmltemplate.New("body").Option("missingkey=error").Parse(this.Body);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to parse email template body: '%w'.", err);
+        }
+
+        err = bodyTemplate.Execute(&body, data);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to render email template body: '%w'.", err);
+        }
+    } else {
+        bodyTemplate, err := texttemplate.New("body").Option("missingkey=error").Parse(this.Body);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to parse email template body: '%w'.", err);
+        }
+
+        err = bodyTemplate.Execute(&body, data);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to render email template body: '%w'.", err);
+        }
+    }
+
+    message := &Message{
+        To: to,
+        // Subjects are a single line.
+        Subject: strings.Join(strings.Fields(subject.String()), " "),
+        Body: body.String(),
+        HTML: this.HTML,
+    };
+
+    return message, nil;
+}
@@ -0,0 +1,86 @@
+package email
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/util"
+)
+
+func TestDefaultTemplatesValidate(test *testing.T) {
+    for _, name := range GetTemplateNames() {
+        err := GetDefaultTemplate(name).Validate();
+        if (err != nil) {
+            test.Errorf("Default template '%s' failed to validate: '%v'.", name, err);
+        }
+    }
+}
+
+func TestTemplateRender(test *testing.T) {
+    data := map[string]any{
+        "CourseID": "course101",
+        "Name": "<b>Alice</b>",
+    };
+
+    testCases := []struct{ template Template; expected *Message; hasError bool }{
+        {
+            Template{Subject: "Hi {{ .CourseID }}", Body: "Hello {{ .Name }}."},
+            &Message{To: []string{"a@test.com"}, Subject: "Hi course101", Body: "Hello <b>Alice</b>."},
+            false,
+        },
+        // HTML bodies are escaped.
+        {
+            Template{Subject: "Hi {{ .CourseID }}", Body: "<p>Hello {{ .Name }}.</p>", HTML: true},
+            &Message{To: []string{"a@test.com"}, Subject: "Hi course101", Body: "<p>Hello &lt;b&gt;Alice&lt;/b&gt;.</p>", HTML: true},
+            false,
+        },
+        // Subjects are collapsed to a single line.
+        {
+            Template{Subject: "Hi\n  {{ .CourseID }}\n", Body: ""},
+            &Message{To: []string{"a@test.com"}, Subject: "Hi course101", Body: ""},
+            false,
+        },
+
+        {Template{Subject: "Hi {{ .Missing }}", Body: ""}, nil, true},
+        {Template{Subject: "Hi", Body: "{{ .Missing }}"}, nil, true},
+        {Template{Subject: "Hi {{", Body: ""}, nil, true},
+    };
+
+    for i, testCase := range testCases {
+        message, err := testCase.template.Render([]string{"a@test.com"}, data);
+        if (err != nil) {
+            if (!testCase.hasError) {
+                test.Errorf("Case %d: Failed to render template: '%v'.", i, err);
+            }
+
+            continue;
+        }
+
+        if (testCase.hasError) {
+            test.Errorf("Case %d: Did not get an expected error.", i);
+            continue;
+        }
+
+        if (util.MustToJSON(testCase.expected) != util.MustToJSON(message)) {
+            test.Errorf("Case %d: Unexpected message. Expected: '%s', Actual: '%s'.", i, util.MustToJSONIndent(testCase.expected), util.MustToJSONIndent(message));
+        }
+    }
+}
+
+func TestTemplateValidate(test *testing.T) {
+    testCases := []struct{ template Template; hasError bool }{
+        {Template{Subject: "Hi", Body: "Hello."}, false},
+        {Template{Subject: "Hi", Body: "<p>{{ .Name }}</p>", HTML: true}, false},
+
+        {Template{Subject: "", Body: "Hello."}, true},
+        {Template{Subject: "{{ if }}", Body: "Hello."}, true},
+        {Template{Subject: "Hi", Body: "{{ end }}"}, true},
+        {Template{Subject: "Hi", Body: "{{ end }}", HTML: true}, true},
+    };
+
+    for i, testCase := range testCases {
+        err := testCase.template.Validate();
+        if ((err != nil) != testCase.hasError) {
+            test.Errorf("Case %d: Unexpected validation result. Expected error: %v, Actual: '%v'.", i, testCase.hasError, err);
+        }
+    }
+}
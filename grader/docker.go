@@ -36,11 +36,14 @@ func runDockerGrader(assignment *model.Assignment, submissionPath string, option
         return nil, nil, "", "", fmt.Errorf("Failed to copy over submission/input contents: '%w'.", err);
     }
 
-    stdout, stderr, err := docker.RunContainer(assignment, assignment.ImageName(), inputDir, outputDir, fullSubmissionID);
+    stdout, stderr, err := docker.RunContainer(assignment, assignment.ImageName(), inputDir, outputDir, fullSubmissionID,
+            assignment.GetResourceLimits(), options.getOutputReporter());
     if (err != nil) {
         return nil, nil, stdout, stderr, err;
     }
 
+    options.reportPhase(PHASE_PARSING_RESULTS);
+
     resultPath := filepath.Join(outputDir, common.GRADER_OUTPUT_RESULT_FILENAME);
     if (!util.PathExists(resultPath)) {
         return nil, nil, stdout, stderr,
@@ -1,6 +1,7 @@
 package grader
 
 import (
+    "errors"
     "fmt"
     "sync"
 
@@ -9,19 +10,26 @@ import (
     "github.com/edulinq/autograder/db"
     "github.com/edulinq/autograder/docker"
     "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/notification"
     "github.com/edulinq/autograder/util"
+    "github.com/edulinq/autograder/webhook"
 )
 
 var submissionLocks sync.Map;
 
 type GradeOptions struct {
     NoDocker bool
+    // Only used with NoDocker, run the grader in a sandbox (see the sandbox package).
+    Sandbox bool
     LeaveTempDir bool
+    // If not nil, called as grading progresses.
+    Progress ProgressFunc
 }
 
 func GetDefaultGradeOptions() GradeOptions {
     return GradeOptions{
         NoDocker: config.DOCKER_DISABLE.Get(),
+        Sandbox: config.DOCKER_SANDBOX.Get(),
         LeaveTempDir: config.DEBUG.Get(),
     };
 }
@@ -35,6 +43,13 @@ func GradeDefault(assignment *model.Assignment, submissionPath string, user stri
 // Grade with custom options.
 func Grade(assignment *model.Assignment, submissionPath string, user string, message string, checkRejection bool, options GradeOptions) (
         *model.GradingResult, RejectReason, error) {
+    return grade(assignment, submissionPath, user, message, checkRejection, options, nil);
+}
+
+// The full grading process.
+// If this is a regrade, then |original| is the submission being regraded (otherwise it is nil).
+func grade(assignment *model.Assignment, submissionPath string, user string, message string, checkRejection bool, options GradeOptions,
+        original *model.GradingInfo) (*model.GradingResult, RejectReason, error) {
     if (checkRejection) {
         reject, err := checkForRejection(assignment, submissionPath, user, message);
         if (err != nil) {
@@ -42,12 +57,28 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
         }
 
         if (reject != nil) {
+            // Like receipts, rejection notices are only for new submissions (not regrades).
+            if (original == nil) {
+                webhook.EmitSubmissionRejected(assignment, user, reject.String());
+                notification.SendRejectionNotice(assignment, user, reject.String());
+            }
+
             return nil, reject, nil;
         }
     }
 
     gradingKey := fmt.Sprintf("%s::%s::%s", assignment.GetCourse().GetID(), assignment.GetID(), user);
 
+    // Group members share submissions, so they also share a lock.
+    group, err := db.GetSubmissionGroup(assignment, user);
+    if (err != nil) {
+        return nil, nil, fmt.Errorf("Failed to get submission group: '%w'.", err);
+    }
+
+    if (group != nil) {
+        gradingKey = fmt.Sprintf("%s::%s::group::%s", assignment.GetCourse().GetID(), assignment.GetID(), group.ID);
+    }
+
     // Get the existing mutex, or store (and fetch) a new one.
     val, _ := submissionLocks.LoadOrStore(gradingKey, &sync.Mutex{});
     lock := val.(*sync.Mutex)
@@ -55,6 +86,10 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
     lock.Lock();
     defer lock.Unlock()
 
+    if (!options.NoDocker) {
+        options.reportPhase(PHASE_BUILDING_IMAGE);
+    }
+
     submissionID, inputFileContents, err := prepForGrading(assignment, submissionPath, user);
     if (err != nil) {
         return nil, nil, fmt.Errorf("Failed to prep for grading: '%w'.", err);
@@ -72,6 +107,8 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
 
     startTimestamp := common.NowTimestamp();
 
+    options.reportPhase(PHASE_RUNNING);
+
     if (options.NoDocker) {
         gradingInfo, outputFileContents, stdout, stderr, err = runNoDockerGrader(assignment, submissionPath, options, fullSubmissionID);
     } else {
@@ -84,6 +121,19 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
     gradingResult.Stdout = stdout;
     gradingResult.Stderr = stderr;
 
+    // Hitting a resource limit is the submission's fault, so it still gets a (zero score) result.
+    var limitErr *docker.ResourceLimitError;
+    if (errors.As(err, &limitErr)) {
+        gradingInfo = &model.GradingInfo{
+            Name: assignment.GetName(),
+            Questions: []*model.GradedQuestion{},
+            Prologue: limitErr.Message,
+            FailureReason: limitErr.Kind,
+        };
+        outputFileContents = nil;
+        err = nil;
+    }
+
     if (err != nil) {
         return &gradingResult, nil, err;
     }
@@ -96,6 +146,13 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
     gradingInfo.User = user;
     gradingInfo.Message = message;
 
+    if (original != nil) {
+        gradingInfo.RegradeOf = original.ID;
+
+        // Keep the original submission time, so things like late policies are applied the same way.
+        gradingInfo.GradingStartTime = original.GradingStartTime;
+    }
+
     if (gradingInfo.GradingStartTime.IsZero()) {
         gradingInfo.GradingStartTime = startTimestamp;
     }
@@ -106,6 +163,10 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
 
     gradingInfo.ComputePoints();
 
+    if (gradingInfo.FailureReason != "") {
+        gradingInfo.MaxPoints = assignment.MaxPoints;
+    }
+
     gradingResult.Info = gradingInfo;
     gradingResult.OutputFilesGZip = outputFileContents;
 
@@ -114,8 +175,17 @@ func Grade(assignment *model.Assignment, submissionPath string, user string, mes
         if (err != nil) {
             return &gradingResult, nil, fmt.Errorf("Failed to save grading result: '%w'.", err);
         }
+
+        options.reportPhase(PHASE_SAVED);
+
+        // Regrades are not new submissions, so they do not get a receipt.
+        if (original == nil) {
+            notification.SendSubmissionReceipt(assignment, gradingInfo);
+        }
     }
 
+    webhook.EmitSubmissionGraded(assignment, gradingInfo);
+
     return &gradingResult, nil, nil;
 }
 
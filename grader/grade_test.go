@@ -7,6 +7,7 @@ import (
     "github.com/edulinq/autograder/config"
     "github.com/edulinq/autograder/db"
     "github.com/edulinq/autograder/docker"
+    "github.com/edulinq/autograder/sandbox"
 )
 
 const BASE_TEST_USER = "student@test.com";
@@ -21,7 +22,7 @@ func TestDockerSubmissions(test *testing.T) {
         test.Fatal("Could not access docker.");
     }
 
-    runSubmissionTests(test, false, true);
+    runSubmissionTests(test, false, true, false);
 }
 
 func TestNoDockerSubmissions(test *testing.T) {
@@ -29,10 +30,23 @@ func TestNoDockerSubmissions(test *testing.T) {
     config.DOCKER_DISABLE.Set(true);
     defer config.DOCKER_DISABLE.Set(oldDockerVal);
 
-    runSubmissionTests(test, false, false);
+    runSubmissionTests(test, false, false, false);
 }
 
-func runSubmissionTests(test *testing.T, parallel bool, useDocker bool) {
+func TestSandboxSubmissions(test *testing.T) {
+    err := sandbox.Available();
+    if (err != nil) {
+        test.Skipf("Sandboxing is not available: '%v'.", err);
+    }
+
+    oldDockerVal := config.DOCKER_DISABLE.Get();
+    config.DOCKER_DISABLE.Set(true);
+    defer config.DOCKER_DISABLE.Set(oldDockerVal);
+
+    runSubmissionTests(test, false, false, true);
+}
+
+func runSubmissionTests(test *testing.T, parallel bool, useDocker bool, useSandbox bool) {
     db.ResetForTesting();
     defer db.ResetForTesting();
 
@@ -52,6 +66,7 @@ func runSubmissionTests(test *testing.T, parallel bool, useDocker bool) {
 
     gradeOptions := GradeOptions{
         NoDocker: !useDocker,
+        Sandbox: useSandbox,
     };
 
     testSubmissions, err := GetTestSubmissions(baseDir);
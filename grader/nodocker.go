@@ -3,6 +3,7 @@ package grader
 import (
     "bytes"
     "fmt"
+    "io"
     "os"
     "os/exec"
     "path/filepath"
@@ -11,6 +12,7 @@ import (
     "github.com/edulinq/autograder/common"
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/sandbox"
     "github.com/edulinq/autograder/util"
 )
 
@@ -55,12 +57,30 @@ func runNoDockerGrader(assignment *model.Assignment, submissionPath string, opti
         return nil, nil, "", "", fmt.Errorf("Failed to copy submission ssignment files: '%w'.", err);
     }
 
-    stdout, stderr, err := runCMD(cmd);
+    var stdout string;
+    var stderr string;
+
+    if (options.Sandbox) {
+        stdout, stderr, err = sandbox.Run(assignment, &sandbox.Command{
+            Args: cmd.Args,
+            BaseDir: tempDir,
+            InputDir: inputDir,
+            OutputDir: outputDir,
+            WorkDir: workDir,
+            Limits: assignment.GetResourceLimits(),
+            OnStdout: options.getOutputReporter(),
+        });
+    } else {
+        stdout, stderr, err = runCMD(cmd, options.getOutputReporter());
+    }
+
     if (err != nil) {
         return nil, nil, stdout, stderr,
                 fmt.Errorf("Failed to run non-docker grader for assignment '%s': '%w'.", assignment.FullID(), err);
     }
 
+    options.reportPhase(PHASE_PARSING_RESULTS);
+
     resultPath := filepath.Join(outputDir, common.GRADER_OUTPUT_RESULT_FILENAME);
     if (!util.PathExists(resultPath)) {
         return nil, nil, stdout, stderr, fmt.Errorf("Cannot find output file ('%s') after non-docker grading.", resultPath);
@@ -80,13 +100,22 @@ func runNoDockerGrader(assignment *model.Assignment, submissionPath string, opti
     return &gradingInfo, fileContents, stdout, stderr, nil;
 }
 
-func runCMD(cmd *exec.Cmd) (string, string, error) {
+// Run a command and return its stdout and stderr.
+// If |onStdout| is not nil, it will be called with each line of stdout as the command runs.
+func runCMD(cmd *exec.Cmd, onStdout func(string)) (string, string, error) {
     var outBuffer bytes.Buffer;
     var errBuffer bytes.Buffer;
 
     cmd.Stdout = &outBuffer;
     cmd.Stderr = &errBuffer;
 
+    if (onStdout != nil) {
+        lineWriter := util.NewLineWriter(onStdout);
+        defer lineWriter.Flush();
+
+        cmd.Stdout = io.MultiWriter(&outBuffer, lineWriter);
+    }
+
     err := cmd.Run();
 
     stdout := outBuffer.String();
@@ -0,0 +1,66 @@
+package grader
+
+// Progress reporting while a submission is being graded.
+
+import (
+    "github.com/edulinq/autograder/common"
+)
+
+type GradingPhase string;
+
+const (
+    PHASE_QUEUED GradingPhase = "queued"
+    PHASE_BUILDING_IMAGE GradingPhase = "building-image"
+    PHASE_RUNNING GradingPhase = "running"
+    PHASE_PARSING_RESULTS GradingPhase = "parsing-results"
+    PHASE_SAVED GradingPhase = "saved"
+)
+
+type ProgressEventType string;
+
+const (
+    // The grading moved to a new phase.
+    PROGRESS_EVENT_PHASE ProgressEventType = "phase"
+    // A line of stdout from the grader.
+    PROGRESS_EVENT_OUTPUT ProgressEventType = "output"
+    // The grading job is finished (only sent by the grading queue).
+    PROGRESS_EVENT_DONE ProgressEventType = "done"
+)
+
+type ProgressEvent struct {
+    Type ProgressEventType `json:"type"`
+    Phase GradingPhase `json:"phase,omitempty"`
+    Text string `json:"text,omitempty"`
+    Timestamp common.Timestamp `json:"timestamp"`
+}
+
+// Called with progress events while grading.
+// May be called from multiple goroutines (but not at the same time).
+type ProgressFunc func(*ProgressEvent);
+
+func (this GradeOptions) reportPhase(phase GradingPhase) {
+    if (this.Progress == nil) {
+        return;
+    }
+
+    this.Progress(&ProgressEvent{
+        Type: PROGRESS_EVENT_PHASE,
+        Phase: phase,
+        Timestamp: common.NowTimestamp(),
+    });
+}
+
+// Get a function that reports lines of output (or nil if progress is not being reported).
+func (this GradeOptions) getOutputReporter() func(string) {
+    if (this.Progress == nil) {
+        return nil;
+    }
+
+    return func(line string) {
+        this.Progress(&ProgressEvent{
+            Type: PROGRESS_EVENT_OUTPUT,
+            Text: line,
+            Timestamp: common.NowTimestamp(),
+        });
+    };
+}
@@ -0,0 +1,378 @@
+package grader
+
+// A queue for grading jobs.
+// Jobs are run by a bounded pool of workers (config.GRADER_WORKERS),
+// and each course can further limit how many of its jobs run at the same time (model.Course.GetGradingWorkers()).
+// Jobs are run in the order they were submitted, except that jobs for a course already at its limit are skipped over.
+// Job information only lives in memory, the final grading results are stored in the database like any other submission.
+
+import (
+    "fmt"
+    "slices"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+type JobStatus string;
+
+const (
+    JOB_STATUS_QUEUED JobStatus = "queued"
+    JOB_STATUS_RUNNING JobStatus = "running"
+    JOB_STATUS_COMPLETE JobStatus = "complete"
+    JOB_STATUS_REJECTED JobStatus = "rejected"
+    JOB_STATUS_FAILED JobStatus = "failed"
+)
+
+// Limit how many lines of output are kept for each job
+// (the full output is still available in the grading result).
+const MAX_JOB_OUTPUT_EVENTS = 1000;
+
+// The function used to actually grade a job.
+// Only replaced in testing.
+type gradeFunction func(*model.Assignment, string, string, string, bool, GradeOptions, *model.GradingInfo) (*model.GradingResult, RejectReason, error);
+
+type GradingJob struct {
+    ID string
+    CourseID string
+    AssignmentID string
+    User string
+
+    assignment *model.Assignment
+    submissionDir string
+    message string
+    options GradeOptions
+    // The submission being regraded (nil if this job is not a regrade).
+    original *model.GradingInfo
+
+    status JobStatus
+    result *model.GradingResult
+    reject RejectReason
+    err error
+
+    finishTime time.Time
+    done chan struct{}
+
+    // Progress events are guarded by their own lock (grading reports progress without the queue lock).
+    eventLock sync.Mutex
+    events []*ProgressEvent
+    numOutputEvents int
+    // Closed (and replaced) whenever a new event is added.
+    eventsChanged chan struct{}
+}
+
+// A snapshot of a job's state.
+type GradingJobInfo struct {
+    ID string
+    CourseID string
+    AssignmentID string
+    User string
+
+    Status JobStatus
+    // The number of jobs ahead of this one in the queue.
+    // Only meaningful when the status is JOB_STATUS_QUEUED.
+    QueuePosition int
+
+    Result *model.GradingResult
+    Reject RejectReason
+    Err error
+}
+
+type gradingQueue struct {
+    lock sync.Mutex
+    cond *sync.Cond
+
+    pending []*GradingJob
+    jobs map[string]*GradingJob
+    runningPerCourse map[string]int
+    numWorkers int
+
+    grade gradeFunction
+}
+
+var queue *gradingQueue = newGradingQueue(grade);
+
+func newGradingQueue(grade gradeFunction) *gradingQueue {
+    newQueue := &gradingQueue{
+        pending: make([]*GradingJob, 0),
+        jobs: make(map[string]*GradingJob),
+        runningPerCourse: make(map[string]int),
+        grade: grade,
+    };
+
+    newQueue.cond = sync.NewCond(&newQueue.lock);
+
+    return newQueue;
+}
+
+// Add a submission to the grading queue using the default grading options.
+// The submission files are copied, so the caller is free to remove submissionPath once this returns.
+func EnqueueGradeDefault(assignment *model.Assignment, submissionPath string, user string, message string) (*GradingJob, error) {
+    return queue.enqueue(assignment, submissionPath, user, message, GetDefaultGradeOptions(), nil);
+}
+
+// Grade a submission through the grading queue (using the default grading options), and wait for the result.
+func GradeQueuedDefault(assignment *model.Assignment, submissionPath string, user string, message string) (
+        *model.GradingResult, RejectReason, error) {
+    job, err := EnqueueGradeDefault(assignment, submissionPath, user, message);
+    if (err != nil) {
+        return nil, nil, err;
+    }
+
+    return job.Wait();
+}
+
+// Get information about a job.
+// Returns nil if the job does not exist (or has been forgotten).
+func GetJobInfo(jobID string) *GradingJobInfo {
+    return queue.getJobInfo(jobID);
+}
+
+// Block until the job is done and return the same values as Grade().
+func (this *GradingJob) Wait() (*model.GradingResult, RejectReason, error) {
+    <-this.done;
+    return this.result, this.reject, this.err;
+}
+
+// Get the job's progress events starting at |index|.
+// If there are no such events yet, then block until there are (or |cancel| is closed, in which case nil is returned).
+// The final event for a job will always be a PROGRESS_EVENT_DONE event.
+func (this *GradingJob) WaitEvents(index int, cancel <-chan struct{}) []*ProgressEvent {
+    for {
+        this.eventLock.Lock();
+        if (index < len(this.events)) {
+            events := slices.Clone(this.events[index:]);
+            this.eventLock.Unlock();
+            return events;
+        }
+
+        changed := this.eventsChanged;
+        this.eventLock.Unlock();
+
+        select {
+            case <-changed:
+            case <-cancel:
+                return nil;
+        }
+    }
+}
+
+func (this *GradingJob) addEvent(event *ProgressEvent) {
+    this.eventLock.Lock();
+    defer this.eventLock.Unlock();
+
+    if (event.Type == PROGRESS_EVENT_OUTPUT) {
+        this.numOutputEvents++;
+        if (this.numOutputEvents > MAX_JOB_OUTPUT_EVENTS) {
+            return;
+        }
+    }
+
+    this.events = append(this.events, event);
+
+    close(this.eventsChanged);
+    this.eventsChanged = make(chan struct{});
+}
+
+func (this *gradingQueue) enqueue(assignment *model.Assignment, submissionPath string, user string, message string, options GradeOptions,
+        original *model.GradingInfo) (*GradingJob, error) {
+    submissionDir, err := util.MkDirTemp("grading-job-");
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to create temp dir for grading job: '%w'.", err);
+    }
+
+    err = util.CopyDirContents(submissionPath, submissionDir);
+    if (err != nil) {
+        util.RemoveDirent(submissionDir);
+        return nil, fmt.Errorf("Failed to copy submission for grading job: '%w'.", err);
+    }
+
+    job := &GradingJob{
+        ID: util.UUID(),
+        CourseID: assignment.GetCourse().GetID(),
+        AssignmentID: assignment.GetID(),
+        User: user,
+        assignment: assignment,
+        submissionDir: submissionDir,
+        message: message,
+        options: options,
+        original: original,
+        status: JOB_STATUS_QUEUED,
+        done: make(chan struct{}),
+        events: make([]*ProgressEvent, 0),
+        eventsChanged: make(chan struct{}),
+    };
+
+    job.options.Progress = job.addEvent;
+    job.options.reportPhase(PHASE_QUEUED);
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    this.pruneJobs();
+
+    this.pending = append(this.pending, job);
+    this.jobs[job.ID] = job;
+
+    this.ensureWorkers();
+    this.cond.Broadcast();
+
+    log.Debug("Grading job queued.", assignment, log.NewUserAttr(user),
+            log.NewAttr("job-id", job.ID), log.NewAttr("queue-length", len(this.pending)));
+
+    return job, nil;
+}
+
+func (this *gradingQueue) getJobInfo(jobID string) *GradingJobInfo {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    job, ok := this.jobs[jobID];
+    if (!ok) {
+        return nil;
+    }
+
+    info := &GradingJobInfo{
+        ID: job.ID,
+        CourseID: job.CourseID,
+        AssignmentID: job.AssignmentID,
+        User: job.User,
+        Status: job.status,
+        QueuePosition: 0,
+        Result: job.result,
+        Reject: job.reject,
+        Err: job.err,
+    };
+
+    for i, pendingJob := range this.pending {
+        if (pendingJob == job) {
+            info.QueuePosition = i;
+            break;
+        }
+    }
+
+    return info;
+}
+
+// Start workers until there are enough.
+// The caller must hold the lock.
+func (this *gradingQueue) ensureWorkers() {
+    for (this.numWorkers < this.maxWorkers()) {
+        this.numWorkers++;
+        go this.work();
+    }
+}
+
+func (this *gradingQueue) maxWorkers() int {
+    return max(1, config.GRADER_WORKERS.Get());
+}
+
+func (this *gradingQueue) work() {
+    for {
+        job := this.next();
+        if (job == nil) {
+            return;
+        }
+
+        this.run(job);
+    }
+}
+
+// Block until there is a job that can be run, and mark it as running.
+// Returns nil if this worker should exit.
+func (this *gradingQueue) next() *GradingJob {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    for {
+        // The number of workers was lowered.
+        if (this.numWorkers > this.maxWorkers()) {
+            this.numWorkers--;
+            return nil;
+        }
+
+        for i, job := range this.pending {
+            courseLimit := job.assignment.GetCourse().GetGradingWorkers();
+            if ((courseLimit > 0) && (this.runningPerCourse[job.CourseID] >= courseLimit)) {
+                continue;
+            }
+
+            this.pending = append(this.pending[:i], this.pending[i + 1:]...);
+            this.runningPerCourse[job.CourseID]++;
+            job.status = JOB_STATUS_RUNNING;
+
+            return job;
+        }
+
+        this.cond.Wait();
+    }
+}
+
+func (this *gradingQueue) run(job *GradingJob) {
+    var result *model.GradingResult;
+    var reject RejectReason;
+    var err error;
+
+    func() {
+        defer func() {
+            value := recover();
+            if (value != nil) {
+                err = fmt.Errorf("Grading job panicked: '%v'.", value);
+            }
+        }();
+
+        // Regrades are never rejected.
+        result, reject, err = this.grade(job.assignment, job.submissionDir, job.User, job.message, (job.original == nil), job.options, job.original);
+    }();
+
+    util.RemoveDirent(job.submissionDir);
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    job.result = result;
+    job.reject = reject;
+    job.err = err;
+    job.finishTime = time.Now();
+
+    if (err != nil) {
+        job.status = JOB_STATUS_FAILED;
+    } else if (reject != nil) {
+        job.status = JOB_STATUS_REJECTED;
+    } else {
+        job.status = JOB_STATUS_COMPLETE;
+    }
+
+    this.runningPerCourse[job.CourseID]--;
+    if (this.runningPerCourse[job.CourseID] <= 0) {
+        delete(this.runningPerCourse, job.CourseID);
+    }
+
+    job.addEvent(&ProgressEvent{
+        Type: PROGRESS_EVENT_DONE,
+        Text: string(job.status),
+        Timestamp: common.NowTimestamp(),
+    });
+
+    close(job.done);
+
+    // A course may now be under its limit.
+    this.cond.Broadcast();
+}
+
+// Forget about finished jobs that are older than the retention period.
+// The caller must hold the lock.
+func (this *gradingQueue) pruneJobs() {
+    cutoff := time.Now().Add(-time.Duration(config.GRADER_JOB_RETENTION_SECS.Get()) * time.Second);
+
+    for id, job := range this.jobs {
+        if (!job.finishTime.IsZero() && job.finishTime.Before(cutoff)) {
+            delete(this.jobs, id);
+        }
+    }
+}
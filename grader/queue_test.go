@@ -0,0 +1,249 @@
+package grader
+
+import (
+    "fmt"
+    "path/filepath"
+    "slices"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+const QUEUE_TEST_WAIT = 100 * time.Millisecond;
+
+// A grading function that reports when it starts and blocks until released.
+type blockingGrader struct {
+    started chan string
+    release chan error
+}
+
+func newBlockingGrader() *blockingGrader {
+    return &blockingGrader{
+        started: make(chan string, 100),
+        release: make(chan error, 100),
+    };
+}
+
+func (this *blockingGrader) grade(assignment *model.Assignment, submissionPath string, user string, message string, checkRejection bool, options GradeOptions,
+        original *model.GradingInfo) (*model.GradingResult, RejectReason, error) {
+    this.started <- user;
+
+    options.reportPhase(PHASE_RUNNING);
+    reportOutput := options.getOutputReporter();
+    if (reportOutput != nil) {
+        reportOutput("output for " + user);
+    }
+
+    err := <-this.release;
+    if (err != nil) {
+        return nil, nil, err;
+    }
+
+    return &model.GradingResult{Info: &model.GradingInfo{User: user}}, nil, nil;
+}
+
+func TestQueueCourseLimit(test *testing.T) {
+    setQueueLimits(test, 4, 1);
+
+    grader := newBlockingGrader();
+    testQueue := newGradingQueue(grader.grade);
+
+    jobs := enqueueTestJobs(test, testQueue, 3);
+
+    // Only one job for the course can run at a time.
+    expectStarted(test, grader, 1);
+    expectNoneStarted(test, grader);
+
+    expectPositions(test, testQueue, jobs, []JobStatus{JOB_STATUS_RUNNING, JOB_STATUS_QUEUED, JOB_STATUS_QUEUED}, []int{0, 0, 1});
+
+    for i := 0; i < len(jobs); i++ {
+        grader.release <- nil;
+        _, _, err := jobs[i].Wait();
+        if (err != nil) {
+            test.Fatalf("Job %d failed: '%v'.", i, err);
+        }
+
+        if (i < (len(jobs) - 1)) {
+            expectStarted(test, grader, 1);
+        }
+    }
+
+    expectPositions(test, testQueue, jobs, []JobStatus{JOB_STATUS_COMPLETE, JOB_STATUS_COMPLETE, JOB_STATUS_COMPLETE}, []int{0, 0, 0});
+}
+
+func TestQueueServerLimit(test *testing.T) {
+    setQueueLimits(test, 2, 0);
+
+    grader := newBlockingGrader();
+    testQueue := newGradingQueue(grader.grade);
+
+    jobs := enqueueTestJobs(test, testQueue, 3);
+
+    expectStarted(test, grader, 2);
+    expectNoneStarted(test, grader);
+
+    expectPositions(test, testQueue, jobs, []JobStatus{JOB_STATUS_RUNNING, JOB_STATUS_RUNNING, JOB_STATUS_QUEUED}, []int{0, 0, 0});
+
+    grader.release <- nil;
+    expectStarted(test, grader, 1);
+
+    grader.release <- nil;
+    grader.release <- nil;
+
+    for i, job := range jobs {
+        _, _, err := job.Wait();
+        if (err != nil) {
+            test.Fatalf("Job %d failed: '%v'.", i, err);
+        }
+    }
+}
+
+func TestQueueFailure(test *testing.T) {
+    setQueueLimits(test, 1, 0);
+
+    grader := newBlockingGrader();
+    testQueue := newGradingQueue(grader.grade);
+
+    jobs := enqueueTestJobs(test, testQueue, 1);
+
+    grader.release <- fmt.Errorf("Test error.");
+
+    _, _, err := jobs[0].Wait();
+    if (err == nil) {
+        test.Fatalf("Job did not fail when it should have.");
+    }
+
+    expectPositions(test, testQueue, jobs, []JobStatus{JOB_STATUS_FAILED}, []int{0});
+}
+
+func TestQueuePanic(test *testing.T) {
+    setQueueLimits(test, 1, 0);
+
+    testQueue := newGradingQueue(func(*model.Assignment, string, string, string, bool, GradeOptions, *model.GradingInfo) (*model.GradingResult, RejectReason, error) {
+        panic("Test panic.");
+    });
+
+    jobs := enqueueTestJobs(test, testQueue, 1);
+
+    _, _, err := jobs[0].Wait();
+    if (err == nil) {
+        test.Fatalf("Job did not fail when it should have.");
+    }
+
+    expectPositions(test, testQueue, jobs, []JobStatus{JOB_STATUS_FAILED}, []int{0});
+}
+
+func TestQueueEvents(test *testing.T) {
+    setQueueLimits(test, 1, 0);
+
+    grader := newBlockingGrader();
+    testQueue := newGradingQueue(grader.grade);
+
+    jobs := enqueueTestJobs(test, testQueue, 1);
+    job := jobs[0];
+
+    expectStarted(test, grader, 1);
+
+    // Nothing new will come in until the job is released.
+    cancel := make(chan struct{});
+    close(cancel);
+    if (job.WaitEvents(3, cancel) != nil) {
+        test.Fatalf("Got events after canceling.");
+    }
+
+    grader.release <- nil;
+
+    events := make([]*ProgressEvent, 0);
+    for {
+        newEvents := job.WaitEvents(len(events), nil);
+        events = append(events, newEvents...);
+
+        if (events[len(events) - 1].Type == PROGRESS_EVENT_DONE) {
+            break;
+        }
+    }
+
+    expected := []string{
+        "phase:queued:",
+        "phase:running:",
+        "output::output for user0@test.com",
+        "done::complete",
+    };
+
+    actual := make([]string, 0, len(events));
+    for _, event := range events {
+        actual = append(actual, fmt.Sprintf("%s:%s:%s", event.Type, event.Phase, event.Text));
+    }
+
+    if (!slices.Equal(expected, actual)) {
+        test.Fatalf("Unexpected events. Expected: '%v', Actual: '%v'.", expected, actual);
+    }
+}
+
+func setQueueLimits(test *testing.T, serverWorkers int, courseWorkers int) {
+    oldServerWorkers := config.GRADER_WORKERS.Get();
+    oldCourseWorkers := config.GRADER_COURSE_WORKERS.Get();
+
+    config.GRADER_WORKERS.Set(serverWorkers);
+    config.GRADER_COURSE_WORKERS.Set(courseWorkers);
+
+    test.Cleanup(func() {
+        config.GRADER_WORKERS.Set(oldServerWorkers);
+        config.GRADER_COURSE_WORKERS.Set(oldCourseWorkers);
+    });
+}
+
+func enqueueTestJobs(test *testing.T, testQueue *gradingQueue, count int) []*GradingJob {
+    assignment := db.MustGetTestAssignment();
+    submissionPath := filepath.Join(assignment.GetSourceDir(), SUBMISSION_RELPATH);
+
+    jobs := make([]*GradingJob, 0, count);
+    for i := 0; i < count; i++ {
+        job, err := testQueue.enqueue(assignment, submissionPath, fmt.Sprintf("user%d@test.com", i), "", GradeOptions{}, nil);
+        if (err != nil) {
+            test.Fatalf("Failed to enqueue job %d: '%v'.", i, err);
+        }
+
+        jobs = append(jobs, job);
+    }
+
+    return jobs;
+}
+
+func expectStarted(test *testing.T, grader *blockingGrader, count int) {
+    for i := 0; i < count; i++ {
+        select {
+            case <-grader.started:
+            case <-time.After(5 * time.Second):
+                test.Fatalf("Timed out waiting for job %d to start.", i);
+        }
+    }
+}
+
+func expectNoneStarted(test *testing.T, grader *blockingGrader) {
+    select {
+        case user := <-grader.started:
+            test.Fatalf("Job for '%s' started when it should be waiting.", user);
+        case <-time.After(QUEUE_TEST_WAIT):
+    }
+}
+
+func expectPositions(test *testing.T, testQueue *gradingQueue, jobs []*GradingJob, statuses []JobStatus, positions []int) {
+    for i, job := range jobs {
+        info := testQueue.getJobInfo(job.ID);
+        if (info == nil) {
+            test.Fatalf("Could not find info for job %d.", i);
+        }
+
+        if (info.Status != statuses[i]) {
+            test.Fatalf("Job %d has the wrong status. Expected: '%s', Actual: '%s'.", i, statuses[i], info.Status);
+        }
+
+        if (info.QueuePosition != positions[i]) {
+            test.Fatalf("Job %d has the wrong queue position. Expected: %d, Actual: %d.", i, positions[i], info.QueuePosition);
+        }
+    }
+}
@@ -0,0 +1,278 @@
+package grader
+
+// Re-run existing submissions against the current grader.
+// Regrades are run through the grading queue (like any other submission).
+// Each regrade is saved as a new submission (with the original's input files, message, and submission time)
+// that links back to the submission it regraded.
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+
+    "golang.org/x/exp/maps"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+type RegradeOptions struct {
+    GradeOptions
+
+    // Only regrade submissions from this user (all users when empty).
+    User string
+    // Regrade every submission (except earlier regrades) instead of just the most recent submission from each user.
+    All bool
+}
+
+// The result of regrading a single submission.
+type RegradeResult struct {
+    User string `json:"user"`
+    OriginalID string `json:"original-id"`
+    // The grading job for this regrade (empty if the regrade could not be queued).
+    JobID string `json:"job-id,omitempty"`
+    OldScore float64 `json:"old-score"`
+
+    // The new submission is only known once the regrade is done (see RegradeReport.Wait()),
+    // so these are left empty until then.
+    NewID string `json:"new-id,omitempty"`
+    MaxPoints float64 `json:"max-points,omitempty"`
+    NewScore *float64 `json:"new-score,omitempty"`
+
+    Error string `json:"error,omitempty"`
+}
+
+type RegradeReport struct {
+    CourseID string `json:"course-id"`
+    AssignmentID string `json:"assignment-id"`
+    Results []*RegradeResult `json:"results"`
+
+    // The job for each result (nil if the regrade could not be queued).
+    jobs []*GradingJob
+}
+
+// Regrade submissions for an assignment and wait for all the regrades to finish.
+// Failures for individual submissions are recorded in the report instead of stopping the regrade.
+func Regrade(assignment *model.Assignment, options RegradeOptions) (*RegradeReport, error) {
+    report, err := EnqueueRegrade(assignment, options);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    report.Wait();
+
+    return report, nil;
+}
+
+// Add regrades for an assignment's submissions to the grading queue (without waiting for them).
+// Each queued regrade has a job ID in the report (which can be used to check on the job),
+// and the new scores will be filled in by RegradeReport.Wait().
+func EnqueueRegrade(assignment *model.Assignment, options RegradeOptions) (*RegradeReport, error) {
+    submissions, err := getRegradeSubmissions(assignment, options);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    report := &RegradeReport{
+        CourseID: assignment.GetCourse().GetID(),
+        AssignmentID: assignment.GetID(),
+        Results: make([]*RegradeResult, 0, len(submissions)),
+        jobs: make([]*GradingJob, 0, len(submissions)),
+    };
+
+    for _, submission := range submissions {
+        result := &RegradeResult{
+            User: submission.Info.User,
+            OriginalID: submission.Info.ID,
+            OldScore: submission.Info.Score,
+        };
+
+        job, err := enqueueRegradeSubmission(assignment, submission, options.GradeOptions);
+        if (err != nil) {
+            log.Warn("Failed to queue submission for regrading.", err, assignment,
+                    log.NewUserAttr(submission.Info.User), log.NewAttr("submission", submission.Info.ID));
+            result.Error = err.Error();
+        } else {
+            result.JobID = job.ID;
+        }
+
+        report.Results = append(report.Results, result);
+        report.jobs = append(report.jobs, job);
+    }
+
+    log.Info("Queued submissions for regrading.", assignment, log.NewAttr("count", len(report.Results)));
+
+    return report, nil;
+}
+
+// Regrade a single (stored) submission and wait for the result.
+func RegradeSubmission(assignment *model.Assignment, submission *model.GradingResult, options GradeOptions) (*model.GradingResult, error) {
+    job, err := enqueueRegradeSubmission(assignment, submission, options);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    result, _, err := job.Wait();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return result, nil;
+}
+
+func enqueueRegradeSubmission(assignment *model.Assignment, submission *model.GradingResult, options GradeOptions) (*GradingJob, error) {
+    if ((submission == nil) || (submission.Info == nil)) {
+        return nil, fmt.Errorf("Cannot regrade a submission without grading information.");
+    }
+
+    if (len(submission.InputFilesGZip) == 0) {
+        return nil, fmt.Errorf("Submission '%s' has no stored input files.", submission.Info.ID);
+    }
+
+    submissionDir, err := util.MkDirTemp("regrade-");
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to create temp dir for regrade: '%w'.", err);
+    }
+    defer util.RemoveDirent(submissionDir);
+
+    err = util.GzipBytesToDirectory(submissionDir, submission.InputFilesGZip);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to extract input files for submission '%s': '%w'.", submission.Info.ID, err);
+    }
+
+    return queue.enqueue(assignment, submissionDir, submission.Info.User, submission.Info.Message, options, submission.Info);
+}
+
+func getRegradeSubmissions(assignment *model.Assignment, options RegradeOptions) ([]*model.GradingResult, error) {
+    emails := []string{options.User};
+    if (options.User == "") {
+        users, err := db.GetUsers(assignment.GetCourse());
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to get users: '%w'.", err);
+        }
+
+        emails = maps.Keys(users);
+        slices.Sort(emails);
+    }
+
+    submissions := make([]*model.GradingResult, 0);
+    // Group members share submissions, so the same submission can be seen multiple times.
+    seenIDs := make(map[string]bool);
+
+    for _, email := range emails {
+        userSubmissions := make([]*model.GradingResult, 0);
+
+        if (options.All) {
+            attempts, err := db.GetSubmissionAttempts(assignment, email);
+            if (err != nil) {
+                return nil, fmt.Errorf("Failed to get submissions for user '%s': '%w'.", email, err);
+            }
+
+            // Only regrade the user's actual submissions (regrading an earlier regrade would just regrade the same files again).
+            for _, attempt := range attempts {
+                if (attempt.Info.RegradeOf == "") {
+                    userSubmissions = append(userSubmissions, attempt);
+                }
+            }
+        } else {
+            submission, err := db.GetSubmissionContents(assignment, email, "");
+            if (err != nil) {
+                return nil, fmt.Errorf("Failed to get most recent submission for user '%s': '%w'.", email, err);
+            }
+
+            if (submission != nil) {
+                userSubmissions = append(userSubmissions, submission);
+            }
+        }
+
+        for _, submission := range userSubmissions {
+            if (seenIDs[submission.Info.ID]) {
+                continue;
+            }
+
+            seenIDs[submission.Info.ID] = true;
+            submissions = append(submissions, submission);
+        }
+    }
+
+    return submissions, nil;
+}
+
+// Wait for all the queued regrades to finish and fill in their results.
+func (this *RegradeReport) Wait() {
+    for i, job := range this.jobs {
+        if (job == nil) {
+            continue;
+        }
+
+        result := this.Results[i];
+
+        newResult, _, err := job.Wait();
+        if (err != nil) {
+            log.Warn("Failed to regrade submission.", err, log.NewCourseAttr(this.CourseID), log.NewAssignmentAttr(this.AssignmentID),
+                    log.NewUserAttr(result.User), log.NewAttr("submission", result.OriginalID));
+            result.Error = err.Error();
+            continue;
+        }
+
+        newScore := newResult.Info.Score;
+
+        result.NewID = newResult.Info.ID;
+        result.MaxPoints = newResult.Info.MaxPoints;
+        result.NewScore = &newScore;
+    }
+
+    log.Info("Regraded submissions.", log.NewCourseAttr(this.CourseID), log.NewAssignmentAttr(this.AssignmentID),
+            log.NewAttr("count", len(this.Results)), log.NewAttr("changed", len(this.GetChanged())));
+}
+
+// Get the change in score (zero if the regrade is not done).
+func (this *RegradeResult) ScoreChange() float64 {
+    if (this.NewScore == nil) {
+        return 0.0;
+    }
+
+    return *this.NewScore - this.OldScore;
+}
+
+// Get the results where the score changed (or the regrade failed).
+func (this *RegradeReport) GetChanged() []*RegradeResult {
+    changed := make([]*RegradeResult, 0);
+    for _, result := range this.Results {
+        if ((result.Error != "") || (result.ScoreChange() != 0.0)) {
+            changed = append(changed, result);
+        }
+    }
+
+    return changed;
+}
+
+// Get a human-readable report of score changes (per user).
+// Only regrades that changed the score or failed are listed.
+func (this *RegradeReport) String() string {
+    var builder strings.Builder;
+
+    changed := this.GetChanged();
+
+    builder.WriteString(fmt.Sprintf("Regraded %d submission(s) for assignment '%s' (course '%s'), %d changed.\n",
+            len(this.Results), this.AssignmentID, this.CourseID, len(changed)));
+
+    for _, result := range changed {
+        if (result.Error != "") {
+            builder.WriteString(fmt.Sprintf("%s (%s): FAILED: %s\n",
+                    result.User, common.GetShortSubmissionID(result.OriginalID), result.Error));
+            continue;
+        }
+
+        builder.WriteString(fmt.Sprintf("%s (%s -> %s): %s -> %s (%+g) / %s\n",
+                result.User,
+                common.GetShortSubmissionID(result.OriginalID), common.GetShortSubmissionID(result.NewID),
+                util.FloatToStr(result.OldScore), util.FloatToStr(*result.NewScore), result.ScoreChange(),
+                util.FloatToStr(result.MaxPoints)));
+    }
+
+    return builder.String();
+}
@@ -0,0 +1,75 @@
+package grader
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestRegradeReport(test *testing.T) {
+    newScore := 2.0;
+
+    report := &RegradeReport{
+        CourseID: "course101",
+        AssignmentID: "hw0",
+        Results: []*RegradeResult{
+            &RegradeResult{User: "a@test.com", OriginalID: "course101::hw0::a@test.com::100", NewID: "course101::hw0::a@test.com::200",
+                    MaxPoints: 2, OldScore: 2, NewScore: &newScore},
+            &RegradeResult{User: "b@test.com", OriginalID: "course101::hw0::b@test.com::100", NewID: "course101::hw0::b@test.com::200",
+                    MaxPoints: 2, OldScore: 1, NewScore: &newScore},
+            &RegradeResult{User: "c@test.com", OriginalID: "course101::hw0::c@test.com::100",
+                    OldScore: 2, Error: "Test error."},
+        },
+    };
+
+    changed := report.GetChanged();
+    if ((len(changed) != 2) || (changed[0].User != "b@test.com") || (changed[1].User != "c@test.com")) {
+        test.Fatalf("Unexpected changed results: '%v'.", changed);
+    }
+
+    expected := "Regraded 3 submission(s) for assignment 'hw0' (course 'course101'), 2 changed.\n" +
+            "b@test.com (100 -> 200): 1 -> 2 (+1) / 2\n" +
+            "c@test.com (100): FAILED: Test error.\n";
+
+    if (report.String() != expected) {
+        test.Fatalf("Unexpected report. Expected: \n'%s', \nActual: \n'%s'.", expected, report.String());
+    }
+}
+
+// Regrading all submissions does not regrade earlier regrades.
+func TestGetRegradeSubmissionsAll(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    assignment := db.MustGetTestAssignment();
+    email := "student@test.com";
+
+    history, err := db.GetSubmissionHistory(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to get submission history: '%v'.", err);
+    }
+
+    for _, item := range history {
+        saveTestRegrade(test, assignment, email, item.ID);
+    }
+
+    submissions, err := getRegradeSubmissions(assignment, RegradeOptions{User: email, All: true});
+    if (err != nil) {
+        test.Fatalf("Failed to get regrade submissions: '%v'.", err);
+    }
+
+    ids := make([]string, 0, len(submissions));
+    for _, submission := range submissions {
+        ids = append(ids, submission.Info.ID);
+    }
+
+    expected := make([]string, 0, len(history));
+    for _, item := range history {
+        expected = append(expected, item.ID);
+    }
+
+    if (util.MustToJSON(expected) != util.MustToJSON(ids)) {
+        test.Fatalf("Unexpected submissions. Expected: '%v', Actual: '%v'.", expected, ids);
+    }
+}
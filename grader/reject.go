@@ -74,6 +74,8 @@ func checkSubmissionLimit(assignment *model.Assignment, email string) (RejectRea
         return nil, err;
     }
 
+    history = getSubmissionAttempts(history);
+
     if (*limit.Max >= 0) {
         if (len(history) >= *limit.Max) {
             return &RejectMaxAttempts{*limit.Max}, nil;
@@ -125,3 +127,17 @@ func checkSubmissionLimitWindow(window *model.SubmittionLimitWindow,
 
     return nil, nil;
 }
+
+// Get only the submissions made by the user (regrades are saved as submissions, but are not attempts).
+func getSubmissionAttempts(history []*model.SubmissionHistoryItem) []*model.SubmissionHistoryItem {
+    attempts := make([]*model.SubmissionHistoryItem, 0, len(history));
+    for _, item := range history {
+        if (item.RegradeOf != "") {
+            continue;
+        }
+
+        attempts = append(attempts, item);
+    }
+
+    return attempts;
+}
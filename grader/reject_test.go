@@ -87,6 +87,83 @@ func testMaxWindowAttemps(test *testing.T, user string, expectReject bool) {
     submitForRejection(test, assignment, user, reason);
 }
 
+// Regrades are saved as submissions, but do not count against a user's submission limits.
+func TestRejectSubmissionRegrades(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    config.TESTING_MODE.Set(false);
+    defer config.TESTING_MODE.Set(true);
+
+    assignment := db.MustGetTestAssignment();
+    email := "student@test.com";
+
+    history, err := db.GetSubmissionHistory(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to get submission history: '%v'.", err);
+    }
+
+    // One more submission is allowed.
+    maxValue := len(history) + 1;
+    assignment.SubmissionLimit = &model.SubmissionLimitInfo{
+        Max: &maxValue,
+        Window: &model.SubmittionLimitWindow{
+            AllowedAttempts: maxValue,
+            Duration: common.DurationSpec{Days: 100000},
+        },
+    };
+
+    for i := 0; i < 2; i++ {
+        saveTestRegrade(test, assignment, email, history[0].ID);
+    }
+
+    reason, err := checkSubmissionLimit(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to check submission limit: '%v'.", err);
+    }
+
+    if (reason != nil) {
+        test.Fatalf("Regrades counted against the submission limit: '%s'.", reason.String());
+    }
+
+    // A real submission still counts.
+    maxValue = len(history);
+
+    reason, err = checkSubmissionLimit(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to check submission limit: '%v'.", err);
+    }
+
+    expected := &RejectMaxAttempts{maxValue};
+    if (!reflect.DeepEqual(expected, reason)) {
+        test.Fatalf("Did not get the expected rejection. Expected: '%+v', Actual: '%+v'.", expected, reason);
+    }
+}
+
+// Save a copy of a submission as a regrade (without running a grader).
+func saveTestRegrade(test *testing.T, assignment *model.Assignment, email string, submissionID string) *model.GradingResult {
+    submission, err := db.GetSubmissionContents(assignment, email, submissionID);
+    if (err != nil) {
+        test.Fatalf("Failed to get submission '%s': '%v'.", submissionID, err);
+    }
+
+    shortID, err := db.GetNextSubmissionID(assignment, email);
+    if (err != nil) {
+        test.Fatalf("Failed to get next submission ID: '%v'.", err);
+    }
+
+    submission.Info.ShortID = shortID;
+    submission.Info.ID = common.CreateFullSubmissionID(assignment.GetCourse().GetID(), assignment.GetID(), email, shortID);
+    submission.Info.RegradeOf = submissionID;
+
+    err = db.SaveSubmission(assignment, submission);
+    if (err != nil) {
+        test.Fatalf("Failed to save regrade: '%v'.", err);
+    }
+
+    return submission;
+}
+
 func submitForRejection(test *testing.T, assignment *model.Assignment, user string, expectedRejection RejectReason) (
         *model.GradingResult, RejectReason, error) {
     // Disable testing mode to check for rejection.
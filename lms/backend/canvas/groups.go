@@ -0,0 +1,106 @@
+package canvas
+
+import (
+    "fmt"
+    "slices"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *CanvasBackend) FetchGroups() ([]*lmstypes.Group, error) {
+    return this.fetchGroups(false);
+}
+
+func (this *CanvasBackend) fetchGroups(rewriteLinks bool) ([]*lmstypes.Group, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    apiEndpoint := fmt.Sprintf("/api/v1/courses/%s/groups?per_page=%d", this.CourseID, PAGE_SIZE);
+
+    var canvasGroups []*Group;
+    err := this.fetchAllPages(this.BaseURL + apiEndpoint, rewriteLinks, func(body string) error {
+        var pageGroups []*Group;
+        err := util.JSONFromString(body, &pageGroups);
+        if (err != nil) {
+            return fmt.Errorf("Failed to unmarshal groups page: '%w'.", err);
+        }
+
+        canvasGroups = append(canvasGroups, pageGroups...);
+        return nil;
+    });
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch groups: '%w'.", err);
+    }
+
+    groups := make([]*lmstypes.Group, 0, len(canvasGroups));
+    for _, canvasGroup := range canvasGroups {
+        if (canvasGroup == nil) {
+            continue;
+        }
+
+        apiEndpoint = fmt.Sprintf("/api/v1/groups/%s/users?per_page=%d", canvasGroup.ID, PAGE_SIZE);
+
+        members := make([]string, 0);
+        err = this.fetchAllPages(this.BaseURL + apiEndpoint, rewriteLinks, func(body string) error {
+            var pageUsers []*User;
+            err := util.JSONFromString(body, &pageUsers);
+            if (err != nil) {
+                return fmt.Errorf("Failed to unmarshal group users page: '%w'.", err);
+            }
+
+            for _, user := range pageUsers {
+                if ((user != nil) && (user.Email != "")) {
+                    members = append(members, user.Email);
+                }
+            }
+
+            return nil;
+        });
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to fetch users for group '%s': '%w'.", canvasGroup.ID, err);
+        }
+
+        slices.Sort(members);
+
+        groups = append(groups, &lmstypes.Group{
+            ID: canvasGroup.ID,
+            Name: canvasGroup.Name,
+            Members: members,
+        });
+    }
+
+    return groups, nil;
+}
+
+// GET every page starting at the given URL and pass each body to the handler.
+// The caller should already hold the API lock.
+func (this *CanvasBackend) fetchAllPages(url string, rewriteLinks bool, handler func(body string) error) error {
+    headers := this.standardHeaders();
+
+    for (url != "") {
+        var err error;
+
+        if (rewriteLinks) {
+            url, err = this.rewriteLink(url);
+            if (err != nil) {
+                return err;
+            }
+        }
+
+        body, responseHeaders, err := common.GetWithHeaders(url, headers);
+        if (err != nil) {
+            return err;
+        }
+
+        err = handler(body);
+        if (err != nil) {
+            return err;
+        }
+
+        url = fetchNextCanvasLink(responseHeaders);
+    }
+
+    return nil;
+}
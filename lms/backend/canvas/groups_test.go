@@ -0,0 +1,34 @@
+package canvas
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestCanvasGroupsGetBase(test *testing.T) {
+    expected := []*lmstypes.Group{
+        &lmstypes.Group{
+            ID: "00501",
+            Name: "Team A",
+            Members: []string{"other@test.com", "student@test.com"},
+        },
+        &lmstypes.Group{
+            ID: "00502",
+            Name: "Team B",
+            Members: []string{"grader@test.com"},
+        },
+    };
+
+    groups, err := testBackend.fetchGroups(true);
+    if (err != nil) {
+        test.Fatalf("Failed to fetch groups: '%v'.", err);
+    }
+
+    if (!reflect.DeepEqual(expected, groups)) {
+        test.Fatalf("Groups not as expected. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected), util.MustToJSONIndent(groups));
+    }
+}
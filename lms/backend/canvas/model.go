@@ -36,6 +36,11 @@ type Assignment struct {
     MaxPoints float64 `json:"points_possible"`
 }
 
+type Group struct {
+    ID string `json:"id"`
+    Name string `json:"name"`
+}
+
 type Enrollment struct {
     ID string `json:"id"`
     Type string `json:"type"`
@@ -0,0 +1,84 @@
+package lti
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/log"
+    ltitool "github.com/edulinq/autograder/lti"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *LTIBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+    if (this.Registration.LineItemsURL == "") {
+        return nil, fmt.Errorf("LTI registration has no line items URL (lti.line-items-url).");
+    }
+
+    assignments := make([]*lmstypes.Assignment, 0);
+
+    err := ltitool.ServiceGet(this.Registration, this.Registration.LineItemsURL, ltitool.CONTENT_TYPE_LINE_ITEMS, func(body string) error {
+        var lineItems []*ltitool.LineItem;
+        err := util.JSONFromString(body, &lineItems);
+        if (err != nil) {
+            return fmt.Errorf("Failed to unmarshal line items: '%w'.", err);
+        }
+
+        for _, lineItem := range lineItems {
+            assignments = append(assignments, this.toLMSAssignment(lineItem));
+        }
+
+        return nil;
+    });
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch line items: '%w'.", err);
+    }
+
+    return assignments, nil;
+}
+
+func (this *LTIBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+    lineItem, err := this.fetchLineItem(assignmentID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return this.toLMSAssignment(lineItem), nil;
+}
+
+// Line items are identified by their URL.
+func (this *LTIBackend) fetchLineItem(lineItemID string) (*ltitool.LineItem, error) {
+    var lineItem ltitool.LineItem;
+
+    err := ltitool.ServiceGet(this.Registration, lineItemID, ltitool.CONTENT_TYPE_LINE_ITEM, func(body string) error {
+        return util.JSONFromString(body, &lineItem);
+    });
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch line item '%s': '%w'.", lineItemID, err);
+    }
+
+    return &lineItem, nil;
+}
+
+func (this *LTIBackend) toLMSAssignment(lineItem *ltitool.LineItem) *lmstypes.Assignment {
+    var dueDate *time.Time = nil;
+    if (lineItem.EndDateTime != "") {
+        parsed, err := time.Parse(time.RFC3339, lineItem.EndDateTime);
+        if (err != nil) {
+            log.Warn("Failed to parse line item end date.", err,
+                    log.NewAttr("line-item", lineItem.ID), log.NewAttr("end-date", lineItem.EndDateTime));
+        } else {
+            dueDate = &parsed;
+        }
+    }
+
+    return &lmstypes.Assignment{
+        ID: lineItem.ID,
+        Name: lineItem.Label,
+        LMSCourseID: this.Registration.ContextID,
+        DueDate: dueDate,
+        MaxPoints: lineItem.ScoreMaximum,
+    };
+}
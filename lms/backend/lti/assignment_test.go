@@ -0,0 +1,53 @@
+package lti
+
+import (
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestFetchAssignmentsBase(test *testing.T) {
+    dueDate, err := time.Parse(time.RFC3339, TEST_DUE_DATE);
+    if (err != nil) {
+        test.Fatalf("Failed to parse due date: '%v'.", err);
+    }
+
+    expected := []*lmstypes.Assignment{
+        &lmstypes.Assignment{
+            ID: testLineItem.ID,
+            Name: "Homework 0",
+            LMSCourseID: TEST_CONTEXT_ID,
+            DueDate: &dueDate,
+            MaxPoints: 100.0,
+        },
+        &lmstypes.Assignment{
+            ID: testPlatform.URL() + "/lineitems/2",
+            Name: "Homework 1",
+            LMSCourseID: TEST_CONTEXT_ID,
+            MaxPoints: 50.0,
+        },
+    };
+
+    assignments, err := testBackend.FetchAssignments();
+    if (err != nil) {
+        test.Fatalf("Failed to fetch assignments: '%v'.", err);
+    }
+
+    if (!reflect.DeepEqual(expected, assignments)) {
+        test.Fatalf("Assignments not as expected. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected), util.MustToJSONIndent(assignments));
+    }
+
+    assignment, err := testBackend.FetchAssignment(testLineItem.ID);
+    if (err != nil) {
+        test.Fatalf("Failed to fetch assignment: '%v'.", err);
+    }
+
+    if (!reflect.DeepEqual(expected[0], assignment)) {
+        test.Fatalf("Assignment not as expected. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected[0]), util.MustToJSONIndent(assignment));
+    }
+}
@@ -0,0 +1,31 @@
+package lti
+
+// An LMS backend that only uses the LTI services (AGS and NRPS) the LMS offers the autograder as a registered LTI tool.
+// Instead of an API token, requests are authorized with the tool's registration (see the lti package).
+// Assignments are AGS line items (identified by their URL), and users are identified by their LTI user ID ("sub").
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/model"
+)
+
+type LTIBackend struct {
+    Registration *model.LTIRegistration
+}
+
+func NewBackend(registration *model.LTIRegistration) (*LTIBackend, error) {
+    if (registration == nil) {
+        return nil, fmt.Errorf("LTI backend requires an LTI registration (lti).");
+    }
+
+    if (registration.AuthTokenURL == "") {
+        return nil, fmt.Errorf("LTI auth token URL (lti.auth-token-url) cannot be empty.");
+    }
+
+    backend := LTIBackend{
+        Registration: registration,
+    };
+
+    return &backend, nil;
+}
@@ -0,0 +1,38 @@
+package lti
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+)
+
+func (this *LTIBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+    for i, comment := range comments {
+        err := this.UpdateComment(assignmentID, comment);
+        if (err != nil) {
+            return fmt.Errorf("Failed on comment %d: '%w'.", i, err);
+        }
+    }
+
+    return nil;
+}
+
+// AGS comments are part of a user's score (the comment's author is the scored user).
+// A comment can only be sent along with a score, so the user's current score is re-sent with the new comment.
+func (this *LTIBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+    lineItem, err := this.fetchLineItem(assignmentID);
+    if (err != nil) {
+        return err;
+    }
+
+    score, err := this.FetchAssignmentScore(assignmentID, comment.Author);
+    if (err != nil) {
+        return err;
+    }
+
+    if (score == nil) {
+        return fmt.Errorf("User '%s' does not have a score to comment on.", comment.Author);
+    }
+
+    return this.postScore(lineItem, comment.Author, score.Score, comment.Text);
+}
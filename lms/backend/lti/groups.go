@@ -0,0 +1,12 @@
+package lti
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+)
+
+// LTI services do not include groups.
+func (this *LTIBackend) FetchGroups() ([]*lmstypes.Group, error) {
+    return nil, fmt.Errorf("Groups are not available through LTI services.");
+}
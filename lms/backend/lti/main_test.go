@@ -0,0 +1,52 @@
+package lti
+
+import (
+    "os"
+    "testing"
+
+    "github.com/edulinq/autograder/db"
+    ltitool "github.com/edulinq/autograder/lti"
+)
+
+const (
+    TEST_CLIENT_ID = "test-client"
+    TEST_DEPLOYMENT_ID = "test-deployment"
+    TEST_CONTEXT_ID = "test-context"
+    TEST_DUE_DATE = "2023-09-14T04:59:59Z"
+)
+
+var testPlatform *ltitool.TestPlatform;
+var testBackend *LTIBackend;
+var testLineItem *ltitool.LineItem;
+
+func TestMain(suite *testing.M) {
+    var err error;
+
+    // Run inside a func so defers will run before os.Exit().
+    code := func() int {
+        db.PrepForTestingMain();
+        defer db.CleanupTestingMain();
+
+        testPlatform, err = ltitool.NewTestPlatform(TEST_CLIENT_ID, TEST_DEPLOYMENT_ID, TEST_CONTEXT_ID);
+        if (err != nil) {
+            panic(err);
+        }
+        defer testPlatform.Close();
+
+        testPlatform.AddMember("lti-owner", "owner@test.com", "owner", ltitool.ROLE_INSTRUCTOR);
+        testPlatform.AddMember("lti-grader", "grader@test.com", "grader", ltitool.ROLE_INSTRUCTOR, ltitool.ROLE_TEACHING_ASSISTANT);
+        testPlatform.AddMember("lti-student", "student@test.com", "student", ltitool.ROLE_LEARNER);
+
+        testLineItem = testPlatform.AddLineItem("Homework 0", 100.0, TEST_DUE_DATE);
+        testPlatform.AddLineItem("Homework 1", 50.0, "");
+
+        testBackend, err = NewBackend(testPlatform.GetRegistration());
+        if (err != nil) {
+            panic(err);
+        }
+
+        return suite.Run();
+    }();
+
+    os.Exit(code);
+}
@@ -0,0 +1,153 @@
+package lti
+
+import (
+    "fmt"
+    "net/url"
+    "time"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    ltitool "github.com/edulinq/autograder/lti"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    ACTIVITY_PROGRESS_COMPLETED = "Completed";
+    GRADING_PROGRESS_FULLY_GRADED = "FullyGraded";
+)
+
+func (this *LTIBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+    return this.fetchScores(assignmentID, "");
+}
+
+func (this *LTIBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+    scores, err := this.fetchScores(assignmentID, userID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for _, score := range scores {
+        if (score.UserID == userID) {
+            return score, nil;
+        }
+    }
+
+    return nil, nil;
+}
+
+func (this *LTIBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+    lineItem, err := this.fetchLineItem(assignmentID);
+    if (err != nil) {
+        return err;
+    }
+
+    for i, score := range scores {
+        text := "";
+        if (len(score.Comments) > 0) {
+            text = score.Comments[0].Text;
+        }
+
+        err = this.postScore(lineItem, score.UserID, score.Score, text);
+        if (err != nil) {
+            return fmt.Errorf("Failed on score %d: '%w'.", i, err);
+        }
+    }
+
+    return nil;
+}
+
+// Fetch the results (current scores) of a line item (for a single user if |userID| is not empty).
+// Scores are scaled to the line item's maximum.
+// A result's comment is the only comment on a score, and is identified by the user.
+func (this *LTIBackend) fetchScores(lineItemID string, userID string) ([]*lmstypes.SubmissionScore, error) {
+    lineItem, err := this.fetchLineItem(lineItemID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    query := url.Values{};
+    if (userID != "") {
+        query.Set("user_id", userID);
+    }
+
+    resultsURL, err := ltitool.ServiceURL(lineItem.ID, "/results", query);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    scores := make([]*lmstypes.SubmissionScore, 0);
+
+    err = ltitool.ServiceGet(this.Registration, resultsURL, ltitool.CONTENT_TYPE_RESULTS, func(body string) error {
+        var results []*ltitool.Result;
+        err := util.JSONFromString(body, &results);
+        if (err != nil) {
+            return fmt.Errorf("Failed to unmarshal results: '%w'.", err);
+        }
+
+        for _, result := range results {
+            if (result.ResultScore == nil) {
+                continue;
+            }
+
+            score := &lmstypes.SubmissionScore{
+                UserID: result.UserID,
+                Score: scaleScore(*result.ResultScore, result.ResultMaximum, lineItem.ScoreMaximum),
+                Comments: make([]*lmstypes.SubmissionComment, 0, 1),
+            };
+
+            if (result.Comment != "") {
+                score.Comments = append(score.Comments, &lmstypes.SubmissionComment{
+                    ID: result.UserID,
+                    Author: result.UserID,
+                    Text: result.Comment,
+                });
+            }
+
+            scores = append(scores, score);
+        }
+
+        return nil;
+    });
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch results for line item '%s': '%w'.", lineItemID, err);
+    }
+
+    return scores, nil;
+}
+
+func (this *LTIBackend) postScore(lineItem *ltitool.LineItem, userID string, points float64, comment string) error {
+    scoresURL, err := ltitool.ServiceURL(lineItem.ID, "/scores", nil);
+    if (err != nil) {
+        return err;
+    }
+
+    score := ltitool.Score{
+        UserID: userID,
+        ScoreGiven: &points,
+        ScoreMaximum: lineItem.ScoreMaximum,
+        Comment: comment,
+        Timestamp: time.Now().Format(time.RFC3339Nano),
+        ActivityProgress: ACTIVITY_PROGRESS_COMPLETED,
+        GradingProgress: GRADING_PROGRESS_FULLY_GRADED,
+    };
+
+    _, err = ltitool.ServicePost(this.Registration, scoresURL, ltitool.CONTENT_TYPE_SCORE, score);
+    if (err != nil) {
+        return fmt.Errorf("Failed to post score for user '%s': '%w'.", userID, err);
+    }
+
+    return nil;
+}
+
+// A result with no maximum is out of 1.
+func scaleScore(score float64, resultMaximum float64, lineItemMaximum float64) float64 {
+    if (resultMaximum <= 0.0) {
+        resultMaximum = 1.0;
+    }
+
+    if ((lineItemMaximum <= 0.0) || (resultMaximum == lineItemMaximum)) {
+        return score;
+    }
+
+    return score / resultMaximum * lineItemMaximum;
+}
@@ -0,0 +1,97 @@
+package lti
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+)
+
+func TestUpdateAssignmentScoresBase(test *testing.T) {
+    scores := []*lmstypes.SubmissionScore{
+        &lmstypes.SubmissionScore{
+            UserID: "lti-student",
+            Score: 85.0,
+            Comments: []*lmstypes.SubmissionComment{
+                &lmstypes.SubmissionComment{Text: "Good job."},
+            },
+        },
+        &lmstypes.SubmissionScore{
+            UserID: "lti-grader",
+            Score: 100.0,
+        },
+    };
+
+    err := testBackend.UpdateAssignmentScores(testLineItem.ID, scores);
+    if (err != nil) {
+        test.Fatalf("Failed to update scores: '%v'.", err);
+    }
+
+    platformScore := testPlatform.GetScore(testLineItem.ID, "lti-student");
+    if (platformScore == nil) {
+        test.Fatalf("Score was not posted.");
+    }
+
+    if ((*platformScore.ScoreGiven != 85.0) || (platformScore.ScoreMaximum != 100.0) || (platformScore.Comment != "Good job.")) {
+        test.Fatalf("Unexpected posted score: '%v'.", platformScore);
+    }
+
+    fetchedScores, err := testBackend.FetchAssignmentScores(testLineItem.ID);
+    if (err != nil) {
+        test.Fatalf("Failed to fetch scores: '%v'.", err);
+    }
+
+    if (len(fetchedScores) != 2) {
+        test.Fatalf("Unexpected number of scores. Expected: 2, Actual: %d.", len(fetchedScores));
+    }
+
+    score, err := testBackend.FetchAssignmentScore(testLineItem.ID, "lti-student");
+    if (err != nil) {
+        test.Fatalf("Failed to fetch score: '%v'.", err);
+    }
+
+    if ((score == nil) || (score.Score != 85.0) || (len(score.Comments) != 1) || (score.Comments[0].Text != "Good job.")) {
+        test.Fatalf("Unexpected fetched score: '%v'.", score);
+    }
+
+    // Comments are updated by re-posting the current score.
+    comment := &lmstypes.SubmissionComment{
+        ID: score.Comments[0].ID,
+        Author: score.Comments[0].Author,
+        Text: "Great job.",
+    };
+
+    err = testBackend.UpdateComment(testLineItem.ID, comment);
+    if (err != nil) {
+        test.Fatalf("Failed to update comment: '%v'.", err);
+    }
+
+    platformScore = testPlatform.GetScore(testLineItem.ID, "lti-student");
+    if ((*platformScore.ScoreGiven != 85.0) || (platformScore.Comment != "Great job.")) {
+        test.Fatalf("Unexpected score after comment update: '%v'.", platformScore);
+    }
+
+    score, err = testBackend.FetchAssignmentScore(testLineItem.ID, "lti-owner");
+    if (err != nil) {
+        test.Fatalf("Failed to fetch missing score: '%v'.", err);
+    }
+
+    if (score != nil) {
+        test.Fatalf("Found a score that should not exist: '%v'.", score);
+    }
+}
+
+func TestScaleScore(test *testing.T) {
+    testCases := []struct{ score float64; resultMaximum float64; lineItemMaximum float64; expected float64 }{
+        {5.0, 10.0, 10.0, 5.0},
+        {5.0, 10.0, 100.0, 50.0},
+        {0.5, 0.0, 100.0, 50.0},
+        {5.0, 10.0, 0.0, 5.0},
+    };
+
+    for i, testCase := range testCases {
+        actual := scaleScore(testCase.score, testCase.resultMaximum, testCase.lineItemMaximum);
+        if (actual != testCase.expected) {
+            test.Errorf("Case %d: Unexpected score. Expected: '%f', Actual: '%f'.", i, testCase.expected, actual);
+        }
+    }
+}
@@ -0,0 +1,64 @@
+package lti
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    ltitool "github.com/edulinq/autograder/lti"
+    "github.com/edulinq/autograder/util"
+)
+
+const MEMBER_STATUS_DELETED = "Deleted";
+
+func (this *LTIBackend) FetchUsers() ([]*lmstypes.User, error) {
+    if (this.Registration.MembershipsURL == "") {
+        return nil, fmt.Errorf("LTI registration has no memberships URL (lti.memberships-url).");
+    }
+
+    users := make([]*lmstypes.User, 0);
+
+    err := ltitool.ServiceGet(this.Registration, this.Registration.MembershipsURL, ltitool.CONTENT_TYPE_MEMBERSHIPS, func(body string) error {
+        var memberships ltitool.MembershipContainer;
+        err := util.JSONFromString(body, &memberships);
+        if (err != nil) {
+            return fmt.Errorf("Failed to unmarshal memberships: '%w'.", err);
+        }
+
+        for _, member := range memberships.Members {
+            if (member.Status == MEMBER_STATUS_DELETED) {
+                continue;
+            }
+
+            users = append(users, &lmstypes.User{
+                ID: member.UserID,
+                Name: member.Name,
+                Email: member.Email,
+                Role: ltitool.GetRole(member.Roles),
+            });
+        }
+
+        return nil;
+    });
+
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch memberships: '%w'.", err);
+    }
+
+    return users, nil;
+}
+
+func (this *LTIBackend) FetchUser(email string) (*lmstypes.User, error) {
+    users, err := this.FetchUsers();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for _, user := range users {
+        if (strings.EqualFold(user.Email, email)) {
+            return user, nil;
+        }
+    }
+
+    return nil, nil;
+}
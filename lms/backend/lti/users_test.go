@@ -0,0 +1,56 @@
+package lti
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestFetchUsersBase(test *testing.T) {
+    expected := []*lmstypes.User{
+        &lmstypes.User{ID: "lti-owner", Name: "owner", Email: "owner@test.com", Role: model.RoleOwner},
+        &lmstypes.User{ID: "lti-grader", Name: "grader", Email: "grader@test.com", Role: model.RoleGrader},
+        &lmstypes.User{ID: "lti-student", Name: "student", Email: "student@test.com", Role: model.RoleStudent},
+    };
+
+    users, err := testBackend.FetchUsers();
+    if (err != nil) {
+        test.Fatalf("Failed to fetch users: '%v'.", err);
+    }
+
+    if (!reflect.DeepEqual(expected, users)) {
+        test.Fatalf("Users not as expected. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(users));
+    }
+}
+
+func TestFetchUserBase(test *testing.T) {
+    testCases := []struct{ email string; expectedID string }{
+        {"student@test.com", "lti-student"},
+        {"STUDENT@test.com", "lti-student"},
+        {"ZZZ@test.com", ""},
+    };
+
+    for i, testCase := range testCases {
+        user, err := testBackend.FetchUser(testCase.email);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to fetch user: '%v'.", i, err);
+            continue;
+        }
+
+        if (testCase.expectedID == "") {
+            if (user != nil) {
+                test.Errorf("Case %d: Found a user that should not exist: '%v'.", i, user);
+            }
+
+            continue;
+        }
+
+        if ((user == nil) || (user.ID != testCase.expectedID)) {
+            test.Errorf("Case %d: Unexpected user. Expected ID: '%s', Actual: '%v'.", i, testCase.expectedID, user);
+            continue;
+        }
+    }
+}
@@ -0,0 +1,50 @@
+package moodle
+
+import (
+    "fmt"
+    neturl "net/url"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+)
+
+func (this *MoodleBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
+    assignments, err := this.FetchAssignments();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for _, assignment := range assignments {
+        if (assignment.ID == assignmentID) {
+            return assignment, nil;
+        }
+    }
+
+    return nil, fmt.Errorf("Could not find moodle assignment '%s'.", assignmentID);
+}
+
+func (this *MoodleBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    params := neturl.Values{};
+    params.Set("courseids[0]", this.CourseID);
+
+    var response courseAssignments;
+    err := this.callFunction("mod_assign_get_assignments", params, false, &response);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch assignments: '%w'.", err);
+    }
+
+    assignments := make([]*lmstypes.Assignment, 0);
+    for _, course := range response.Courses {
+        for _, assignment := range course.Assignments {
+            if (assignment == nil) {
+                continue;
+            }
+
+            assignments = append(assignments, assignment.ToLMSType());
+        }
+    }
+
+    return assignments, nil;
+}
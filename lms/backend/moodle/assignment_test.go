@@ -0,0 +1,65 @@
+package moodle
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+var expectedAssignments []*lmstypes.Assignment = []*lmstypes.Assignment{
+    &lmstypes.Assignment{
+        ID: TEST_ASSIGNMENT_ID,
+        Name: "Assignment 0",
+        LMSCourseID: "12345",
+        DueDate: mustParseTime("2023-10-06T06:59:59Z"),
+        MaxPoints: 100.0,
+    },
+    // Graded with a scale and no due date.
+    &lmstypes.Assignment{
+        ID: "98766",
+        Name: "Participation",
+        LMSCourseID: "12345",
+        DueDate: nil,
+        MaxPoints: 0.0,
+    },
+};
+
+func TestFetchAssignmentBase(test *testing.T) {
+    assignment, err := testBackend.FetchAssignment(TEST_ASSIGNMENT_ID);
+    if (err != nil) {
+        test.Fatalf("Failed to fetch assignment: '%v'.", err);
+    }
+
+    // Can't compare directly because of time.Time.
+    // Use JSON instead.
+    expectedJSON := util.MustToJSONIndent(expectedAssignments[0]);
+    actualJSON := util.MustToJSONIndent(assignment);
+
+    if (expectedJSON != actualJSON) {
+        test.Fatalf("Assignment not as expected. Expected: '%s', Actual: '%s'.",
+                expectedJSON, actualJSON);
+    }
+
+    _, err = testBackend.FetchAssignment("11111");
+    if (err == nil) {
+        test.Fatalf("Did not get an error when fetching a missing assignment.");
+    }
+}
+
+func TestFetchAssignmentsBase(test *testing.T) {
+    assignments, err := testBackend.FetchAssignments();
+    if (err != nil) {
+        test.Fatalf("Failed to fetch assignments: '%v'.", err);
+    }
+
+    // Can't compare directly because of time.Time.
+    // Use JSON instead.
+    expectedJSON := util.MustToJSONIndent(expectedAssignments);
+    actualJSON := util.MustToJSONIndent(assignments);
+
+    if (expectedJSON != actualJSON) {
+        test.Fatalf("Assignments not as expected. Expected: '%s', Actual: '%s'.",
+                expectedJSON, actualJSON);
+    }
+}
@@ -0,0 +1,36 @@
+package moodle
+
+import (
+    "fmt"
+    "strings"
+)
+
+type MoodleBackend struct {
+    CourseID string
+    APIToken string
+    BaseURL string
+}
+
+func NewBackend(moodleCourseID string, apiToken string, baseURL string) (*MoodleBackend, error) {
+    if (moodleCourseID == "") {
+        return nil, fmt.Errorf("Moodle course ID (course-id) cannot be empty.");
+    }
+
+    if (apiToken == "") {
+        return nil, fmt.Errorf("Moodle API token (api-token) cannot be empty.");
+    }
+
+    if (baseURL == "") {
+        return nil, fmt.Errorf("Moodle base URL (base-url) cannot be empty.");
+    }
+
+    baseURL = strings.TrimSuffix(baseURL, "/");
+
+    backend := MoodleBackend{
+        CourseID: moodleCourseID,
+        APIToken: apiToken,
+        BaseURL: baseURL,
+    };
+
+    return &backend, nil;
+}
@@ -0,0 +1,66 @@
+package moodle
+
+import (
+    "fmt"
+    neturl "net/url"
+    "time"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+)
+
+func (this *MoodleBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
+    for i, comment := range comments {
+        if (i != 0) {
+            time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC));
+        }
+
+        err := this.UpdateComment(assignmentID, comment);
+        if (err != nil) {
+            return fmt.Errorf("Failed on comment %d: '%w'.", i, err);
+        }
+    }
+
+    return nil;
+}
+
+// Moodle comments are the feedback on a user's grade (the comment's author is the graded user).
+// Moodle can only set feedback along with a grade, so the user's current grade is re-saved with the new feedback.
+func (this *MoodleBackend) UpdateComment(assignmentID string, comment *lmstypes.SubmissionComment) error {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    allUserGrades, err := this.fetchGradeItems(comment.Author);
+    if (err != nil) {
+        return err;
+    }
+
+    var score *float64 = nil;
+    for _, userGrades := range allUserGrades {
+        if (idString(userGrades.UserID) != comment.Author) {
+            continue;
+        }
+
+        item := userGrades.getAssignmentItem(assignmentID);
+        if (item != nil) {
+            score = item.GradeRaw;
+        }
+    }
+
+    // Saving feedback would also save a grade (which would be a zero for an ungraded user).
+    if (score == nil) {
+        return fmt.Errorf("User '%s' does not have a score to comment on.", comment.Author);
+    }
+
+    params := neturl.Values{};
+    params.Set("assignmentid", assignmentID);
+    params.Set("applytoall", "0");
+    setGradeParams(params, "", comment.Author, *score);
+    setFeedbackParams(params, "", comment.Text);
+
+    err = this.callFunction("mod_assign_save_grade", params, true, nil);
+    if (err != nil) {
+        return fmt.Errorf("Failed to update comments: '%w'.", err);
+    }
+
+    return nil;
+}
@@ -0,0 +1,134 @@
+package moodle
+
+import (
+    "fmt"
+    "html"
+    neturl "net/url"
+    "regexp"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    REST_ENDPOINT string = "/webservice/rest/server.php"
+    POST_PAGE_SIZE int = 75
+    UPLOAD_SLEEP_TIME_SEC = int64(0.5 * float64(time.Second));
+
+    // Moodle's text format for plain text (FORMAT_PLAIN).
+    FORMAT_PLAIN string = "2"
+)
+
+// Lock for each API token being used.
+// Note that it is possible to have multiple backends with the same token.
+// {string: *sync.Mutex}.
+var apiLocks sync.Map;
+
+var htmlTagPattern *regexp.Regexp = regexp.MustCompile(`<[^>]*>`);
+
+// Moodle reports errors with a normal (200) response that has an exception body.
+type moodleException struct {
+    Exception string `json:"exception"`
+    ErrorCode string `json:"errorcode"`
+    Message string `json:"message"`
+}
+
+func (this *MoodleBackend) getAPILock() {
+    this.ensureAPILock();
+    lock, _ := apiLocks.Load(this.APIToken);
+    lock.(*sync.Mutex).Lock();
+}
+
+func (this *MoodleBackend) releaseAPILock() {
+    this.ensureAPILock();
+    lock, _ := apiLocks.Load(this.APIToken);
+    lock.(*sync.Mutex).Unlock();
+}
+
+func (this *MoodleBackend) ensureAPILock() {
+    apiLocks.LoadOrStore(this.APIToken, &sync.Mutex{});
+}
+
+func (this *MoodleBackend) standardHeaders() map[string][]string {
+    return map[string][]string{
+        "Accept": []string{"application/json"},
+    };
+}
+
+// Call a Moodle web service function and unmarshal the result into output (which may be nil).
+// All parameters are passed in the query string (which Moodle accepts for both GET and POST).
+// Functions that modify data should use POST.
+// The caller should already hold the API lock.
+func (this *MoodleBackend) callFunction(function string, params neturl.Values, post bool, output any) error {
+    query := neturl.Values{};
+    for key, values := range params {
+        query[key] = values;
+    }
+
+    query.Set("wstoken", this.APIToken);
+    query.Set("wsfunction", function);
+    query.Set("moodlewsrestformat", "json");
+
+    // Encode() sorts by key, so the same call always has the same URL.
+    url := fmt.Sprintf("%s%s?%s", this.BaseURL, REST_ENDPOINT, query.Encode());
+    headers := this.standardHeaders();
+
+    var body string;
+    var err error;
+
+    if (post) {
+        body, _, err = common.PostWithHeaders(url, nil, headers);
+    } else {
+        body, _, err = common.GetWithHeaders(url, headers);
+    }
+
+    if (err != nil) {
+        return fmt.Errorf("Failed to call Moodle function '%s': '%w'.", function, err);
+    }
+
+    // Some functions do not return anything.
+    body = strings.TrimSpace(body);
+    if ((body == "") || (body == "null")) {
+        return nil;
+    }
+
+    if (strings.HasPrefix(body, "{")) {
+        var exception moodleException;
+        err = util.JSONFromString(body, &exception);
+        if ((err == nil) && (exception.Exception != "")) {
+            return fmt.Errorf("Moodle function '%s' returned an error (%s): '%s'.", function, exception.ErrorCode, exception.Message);
+        }
+    }
+
+    if (output == nil) {
+        return nil;
+    }
+
+    err = util.JSONFromString(body, output);
+    if (err != nil) {
+        return fmt.Errorf("Failed to unmarshal response from Moodle function '%s': '%w'.", function, err);
+    }
+
+    return nil;
+}
+
+// Moodle returns user-entered text (e.g., feedback) formatted as HTML,
+// convert it back to (close to) the original text.
+func cleanFormattedText(text string) string {
+    text = strings.ReplaceAll(text, "<br />\n", "\n");
+    text = strings.ReplaceAll(text, "<br />", "\n");
+    text = htmlTagPattern.ReplaceAllString(text, "");
+    return strings.TrimSpace(html.UnescapeString(text));
+}
+
+func timeFromUnix(seconds int64) *time.Time {
+    if (seconds <= 0) {
+        return nil;
+    }
+
+    instance := time.Unix(seconds, 0).UTC();
+    return &instance;
+}
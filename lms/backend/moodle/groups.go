@@ -0,0 +1,77 @@
+package moodle
+
+import (
+    "fmt"
+    neturl "net/url"
+    "slices"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+)
+
+func (this *MoodleBackend) FetchGroups() ([]*lmstypes.Group, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    params := neturl.Values{};
+    params.Set("courseid", this.CourseID);
+
+    var moodleGroups []*Group;
+    err := this.callFunction("core_group_get_course_groups", params, false, &moodleGroups);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch groups: '%w'.", err);
+    }
+
+    groups := make([]*lmstypes.Group, 0, len(moodleGroups));
+    if (len(moodleGroups) == 0) {
+        return groups, nil;
+    }
+
+    params = neturl.Values{};
+    for i, group := range moodleGroups {
+        params.Set(fmt.Sprintf("groupids[%d]", i), idString(group.ID));
+    }
+
+    var allMembers []*GroupMembers;
+    err = this.callFunction("core_group_get_group_members", params, false, &allMembers);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch group members: '%w'.", err);
+    }
+
+    // Group members are only given by ID.
+    users, err := this.fetchEnrolledUsers();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    emails := make(map[int64]string, len(users));
+    for _, user := range users {
+        emails[user.ID] = user.Email;
+    }
+
+    members := make(map[int64][]string, len(allMembers));
+    for _, groupMembers := range allMembers {
+        for _, userID := range groupMembers.UserIDs {
+            email := emails[userID];
+            if (email != "") {
+                members[groupMembers.GroupID] = append(members[groupMembers.GroupID], email);
+            }
+        }
+    }
+
+    for _, group := range moodleGroups {
+        groupMembers := members[group.ID];
+        if (groupMembers == nil) {
+            groupMembers = make([]string, 0);
+        }
+
+        slices.Sort(groupMembers);
+
+        groups = append(groups, &lmstypes.Group{
+            ID: idString(group.ID),
+            Name: group.Name,
+            Members: groupMembers,
+        });
+    }
+
+    return groups, nil;
+}
@@ -0,0 +1,35 @@
+package moodle
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestMoodleGroupsGetBase(test *testing.T) {
+    expected := []*lmstypes.Group{
+        &lmstypes.Group{
+            ID: "501",
+            Name: "Team A",
+            Members: []string{"other@test.com", "student@test.com"},
+        },
+        // Members that are not enrolled in the course are dropped.
+        &lmstypes.Group{
+            ID: "502",
+            Name: "Team B",
+            Members: []string{"grader@test.com"},
+        },
+    };
+
+    groups, err := testBackend.FetchGroups();
+    if (err != nil) {
+        test.Fatalf("Failed to fetch groups: '%v'.", err);
+    }
+
+    if (!reflect.DeepEqual(expected, groups)) {
+        test.Fatalf("Groups not as expected. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(expected), util.MustToJSONIndent(groups));
+    }
+}
@@ -0,0 +1,155 @@
+package moodle
+
+import (
+    "strconv"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
+)
+
+type User struct {
+    ID int64 `json:"id"`
+    Name string `json:"fullname"`
+    Email string `json:"email"`
+    Roles []Role `json:"roles"`
+}
+
+type Role struct {
+    ID int64 `json:"roleid"`
+    Name string `json:"name"`
+    ShortName string `json:"shortname"`
+}
+
+type courseAssignments struct {
+    Courses []struct {
+        ID int64 `json:"id"`
+        Assignments []*Assignment `json:"assignments"`
+    } `json:"courses"`
+}
+
+type Assignment struct {
+    ID int64 `json:"id"`
+    CourseModuleID int64 `json:"cmid"`
+    CourseID int64 `json:"course"`
+    Name string `json:"name"`
+    // Unix time, zero when there is no due date.
+    DueDate int64 `json:"duedate"`
+    // Max points, negative values are scales (which do not have points).
+    Grade float64 `json:"grade"`
+}
+
+type userGradeItems struct {
+    UserGrades []*UserGrades `json:"usergrades"`
+}
+
+type UserGrades struct {
+    UserID int64 `json:"userid"`
+    GradeItems []*GradeItem `json:"gradeitems"`
+}
+
+type GradeItem struct {
+    ItemType string `json:"itemtype"`
+    ItemModule string `json:"itemmodule"`
+    ItemInstance int64 `json:"iteminstance"`
+    GradeRaw *float64 `json:"graderaw"`
+    DateSubmitted *int64 `json:"gradedatesubmitted"`
+    Feedback string `json:"feedback"`
+}
+
+type Group struct {
+    ID int64 `json:"id"`
+    Name string `json:"name"`
+}
+
+type GroupMembers struct {
+    GroupID int64 `json:"groupid"`
+    UserIDs []int64 `json:"userids"`
+}
+
+// Moodle role (short name) to autograder role.
+// Custom roles are ignored (users with only custom roles will be model.RoleOther).
+var roleMapping map[string]model.UserRole = map[string]model.UserRole{
+    "guest": model.RoleOther,
+    "user": model.RoleOther,
+    "student": model.RoleStudent,
+    "teacher": model.RoleGrader,
+    "manager": model.RoleAdmin,
+    "editingteacher": model.RoleOwner,
+};
+
+func (this *User) GetRole() model.UserRole {
+    var maxRole model.UserRole = model.RoleOther;
+    for _, role := range this.Roles {
+        maxRole = max(maxRole, roleMapping[role.ShortName]);
+    }
+
+    return maxRole;
+}
+
+func (this *User) ToLMSType() *lmstypes.User {
+    return &lmstypes.User{
+        ID: idString(this.ID),
+        Name: this.Name,
+        Email: this.Email,
+        Role: this.GetRole(),
+    };
+}
+
+func (this *Assignment) ToLMSType() *lmstypes.Assignment {
+    return &lmstypes.Assignment{
+        ID: idString(this.ID),
+        Name: this.Name,
+        LMSCourseID: idString(this.CourseID),
+        DueDate: timeFromUnix(this.DueDate),
+        MaxPoints: max(0.0, this.Grade),
+    };
+}
+
+// Get the grade item for an assignment.
+// Returns nil if the user has no grade item for the assignment.
+func (this *UserGrades) getAssignmentItem(assignmentID string) *GradeItem {
+    for _, item := range this.GradeItems {
+        if ((item.ItemType == "mod") && (item.ItemModule == "assign") && (idString(item.ItemInstance) == assignmentID)) {
+            return item;
+        }
+    }
+
+    return nil;
+}
+
+// Moodle has a single feedback comment per user and assignment,
+// so the comment is identified (and authored) by the user.
+func (this *UserGrades) ToLMSType(item *GradeItem) *lmstypes.SubmissionScore {
+    userID := idString(this.UserID);
+
+    score := &lmstypes.SubmissionScore{
+        UserID: userID,
+        Comments: make([]*lmstypes.SubmissionComment, 0, 1),
+    };
+
+    if (item.GradeRaw != nil) {
+        score.Score = *item.GradeRaw;
+    }
+
+    if (item.DateSubmitted != nil) {
+        submitted := timeFromUnix(*item.DateSubmitted);
+        if (submitted != nil) {
+            score.Time = *submitted;
+        }
+    }
+
+    feedback := cleanFormattedText(item.Feedback);
+    if (feedback != "") {
+        score.Comments = append(score.Comments, &lmstypes.SubmissionComment{
+            ID: userID,
+            Author: userID,
+            Text: feedback,
+        });
+    }
+
+    return score;
+}
+
+func idString(id int64) string {
+    return strconv.FormatInt(id, 10);
+}
@@ -0,0 +1,174 @@
+package moodle
+
+import (
+    "fmt"
+    neturl "net/url"
+    "strings"
+    "time"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+func (this *MoodleBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    allUserGrades, err := this.fetchGradeItems(userID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for _, userGrades := range allUserGrades {
+        if (idString(userGrades.UserID) != userID) {
+            continue;
+        }
+
+        item := userGrades.getAssignmentItem(assignmentID);
+        if (item != nil) {
+            return userGrades.ToLMSType(item), nil;
+        }
+    }
+
+    // The user has not been graded.
+    return &lmstypes.SubmissionScore{
+        UserID: userID,
+        Comments: make([]*lmstypes.SubmissionComment, 0),
+    }, nil;
+}
+
+// Only users that have a grade or a feedback comment are included.
+func (this *MoodleBackend) FetchAssignmentScores(assignmentID string) ([]*lmstypes.SubmissionScore, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    allUserGrades, err := this.fetchGradeItems("");
+    if (err != nil) {
+        return nil, err;
+    }
+
+    scores := make([]*lmstypes.SubmissionScore, 0, len(allUserGrades));
+    for _, userGrades := range allUserGrades {
+        item := userGrades.getAssignmentItem(assignmentID);
+        if (item == nil) {
+            continue;
+        }
+
+        score := userGrades.ToLMSType(item);
+        if ((item.GradeRaw == nil) && (len(score.Comments) == 0)) {
+            continue;
+        }
+
+        scores = append(scores, score);
+    }
+
+    return scores, nil;
+}
+
+func (this *MoodleBackend) UpdateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+    for page := 0; (page * POST_PAGE_SIZE) < len(scores); page++ {
+        startIndex := page * POST_PAGE_SIZE;
+        endIndex := min(len(scores), ((page + 1) * POST_PAGE_SIZE));
+
+        if (page != 0) {
+            time.Sleep(time.Duration(UPLOAD_SLEEP_TIME_SEC));
+        }
+
+        err := this.updateAssignmentScores(assignmentID, scores[startIndex:endIndex]);
+        if (err != nil) {
+            return fmt.Errorf("Failed on page %d: '%w'.", page, err);
+        }
+    }
+
+    return nil;
+}
+
+func (this *MoodleBackend) updateAssignmentScores(assignmentID string, scores []*lmstypes.SubmissionScore) error {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    if (len(scores) > POST_PAGE_SIZE) {
+        return fmt.Errorf("Too many score upload requests at once. Found %d, max %d.", len(scores), POST_PAGE_SIZE);
+    }
+
+    params := neturl.Values{};
+    params.Set("assignmentid", assignmentID);
+    params.Set("applytoall", "0");
+
+    for i, score := range scores {
+        if (len(score.Comments) > 1) {
+            return fmt.Errorf("Scores to upload can have at most one comment. Student '%s' for assignment '%s' has %d.", score.UserID, assignmentID, len(score.Comments));
+        }
+
+        prefix := fmt.Sprintf("grades[%d]", i);
+        setGradeParams(params, prefix, score.UserID, score.Score);
+
+        for _, comment := range score.Comments {
+            setFeedbackParams(params, prefix, comment.Text);
+        }
+    }
+
+    err := this.callFunction("mod_assign_save_grades", params, true, nil);
+    if (err != nil) {
+        return fmt.Errorf("Failed to upload scores: '%w'.", err);
+    }
+
+    return nil;
+}
+
+// Get the grade items for all the course's assignments.
+// An empty user ID will get the grade items for all users.
+// The caller should already hold the API lock.
+func (this *MoodleBackend) fetchGradeItems(userID string) ([]*UserGrades, error) {
+    if (userID == "") {
+        userID = "0";
+    }
+
+    params := neturl.Values{};
+    params.Set("courseid", this.CourseID);
+    params.Set("userid", userID);
+
+    var response userGradeItems;
+    err := this.callFunction("gradereport_user_get_grade_items", params, false, &response);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch scores: '%w'.", err);
+    }
+
+    allUserGrades := make([]*UserGrades, 0, len(response.UserGrades));
+    for _, userGrades := range response.UserGrades {
+        if (userGrades != nil) {
+            allUserGrades = append(allUserGrades, userGrades);
+        }
+    }
+
+    return allUserGrades, nil;
+}
+
+// Set the params for a single grade (latest attempt, no workflow change).
+// An empty prefix is used for functions that take the grade as top-level params.
+func setGradeParams(params neturl.Values, prefix string, userID string, score float64) {
+    params.Set(paramKey(prefix, "userid"), userID);
+    params.Set(paramKey(prefix, "grade"), util.FloatToStr(score));
+    params.Set(paramKey(prefix, "attemptnumber"), "-1");
+    params.Set(paramKey(prefix, "addattempt"), "0");
+    params.Set(paramKey(prefix, "workflowstate"), "");
+}
+
+func setFeedbackParams(params neturl.Values, prefix string, text string) {
+    params.Set(paramKey(prefix, "plugindata[assignfeedbackcomments_editor][text]"), text);
+    params.Set(paramKey(prefix, "plugindata[assignfeedbackcomments_editor][format]"), FORMAT_PLAIN);
+}
+
+func paramKey(prefix string, name string) string {
+    if (prefix == "") {
+        return name;
+    }
+
+    // Nest the name: "plugindata[a][b]" -> "<prefix>[plugindata][a][b]".
+    head, rest, _ := strings.Cut(name, "[");
+    if (rest != "") {
+        rest = "[" + rest;
+    }
+
+    return fmt.Sprintf("%s[%s]%s", prefix, head, rest);
+}
@@ -0,0 +1,142 @@
+package moodle
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/util"
+)
+
+var testStudentScore lmstypes.SubmissionScore = lmstypes.SubmissionScore{
+    UserID: "40",
+    Score: 100.0,
+    Time: *mustParseTime("2023-10-03T20:26:08Z"),
+    Comments: []*lmstypes.SubmissionComment{
+        &lmstypes.SubmissionComment{
+            ID: "40",
+            Author: "40",
+            Text: "Good job & nice \"style\".\nSee you next week.",
+            Time: "",
+        },
+    },
+};
+
+var testGraderScore lmstypes.SubmissionScore = lmstypes.SubmissionScore{
+    UserID: "30",
+    Score: 75.0,
+    Time: time.Time{},
+    Comments: []*lmstypes.SubmissionComment{},
+};
+
+func TestFetchAssignmentScoreBase(test *testing.T) {
+    testCases := []struct{userID string; expected *lmstypes.SubmissionScore}{
+        {"40", &testStudentScore},
+        // Not graded.
+        {"50", &lmstypes.SubmissionScore{UserID: "50", Comments: []*lmstypes.SubmissionComment{}}},
+    };
+
+    for i, testCase := range testCases {
+        score, err := testBackend.FetchAssignmentScore(TEST_ASSIGNMENT_ID, testCase.userID);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to fetch assignment score: '%v'.", i, err);
+            continue;
+        }
+
+        // Can't compare directly because of time.Time.
+        // Use JSON instead.
+        expectedJSON := util.MustToJSONIndent(testCase.expected);
+        actualJSON := util.MustToJSONIndent(score);
+
+        if (expectedJSON != actualJSON) {
+            test.Errorf("Case %d: Score not as expected. Expected: '%s', Actual: '%s'.", i, expectedJSON, actualJSON);
+            continue;
+        }
+    }
+}
+
+func TestFetchAssignmentScoresBase(test *testing.T) {
+    scores, err := testBackend.FetchAssignmentScores(TEST_ASSIGNMENT_ID);
+    if (err != nil) {
+        test.Fatalf("Failed to fetch assignment scores: '%v'.", err);
+    }
+
+    // Ungraded users are not included.
+    expected := []*lmstypes.SubmissionScore{
+        &testGraderScore,
+        &testStudentScore,
+    };
+
+    // Can't compare directly because of time.Time.
+    // Use JSON instead.
+    expectedJSON := util.MustToJSONIndent(expected);
+    actualJSON := util.MustToJSONIndent(scores);
+
+    if (expectedJSON != actualJSON) {
+        test.Fatalf("Scores not as expected. Expected: '%s', Actual: '%s'.",
+                expectedJSON, actualJSON);
+    }
+}
+
+func TestUpdateAssignmentScoresBase(test *testing.T) {
+    scores := []*lmstypes.SubmissionScore{
+        &lmstypes.SubmissionScore{
+            UserID: "40",
+            Score: 90.0,
+            Comments: []*lmstypes.SubmissionComment{
+                &lmstypes.SubmissionComment{
+                    Text: "Nice work.",
+                },
+            },
+        },
+        &lmstypes.SubmissionScore{
+            UserID: "30",
+            Score: 80.5,
+        },
+    };
+
+    err := testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores);
+    if (err != nil) {
+        test.Fatalf("Failed to update assignment scores: '%v'.", err);
+    }
+
+    scores[0].Comments = append(scores[0].Comments, &lmstypes.SubmissionComment{Text: "Extra comment."});
+
+    err = testBackend.UpdateAssignmentScores(TEST_ASSIGNMENT_ID, scores);
+    if (err == nil) {
+        test.Fatalf("Did not get an error when uploading multiple comments for a score.");
+    }
+}
+
+func TestUpdateCommentBase(test *testing.T) {
+    comment := &lmstypes.SubmissionComment{
+        ID: "40",
+        Author: "40",
+        Text: "Updated comment.",
+    };
+
+    // The student's current score (100) is kept.
+    err := testBackend.UpdateComment(TEST_ASSIGNMENT_ID, comment);
+    if (err != nil) {
+        test.Fatalf("Failed to update comment: '%v'.", err);
+    }
+}
+
+func TestUpdateCommentUngraded(test *testing.T) {
+    comment := &lmstypes.SubmissionComment{
+        ID: "50",
+        Author: "50",
+        Text: "Updated comment.",
+    };
+
+    // The user has no score, so there is nothing to attach the comment to (and no score should be created).
+    err := testBackend.UpdateComment(TEST_ASSIGNMENT_ID, comment);
+    if (err == nil) {
+        test.Fatalf("Did not get an error when commenting on an ungraded user.");
+    }
+
+    if (!strings.Contains(err.Error(), "does not have a score")) {
+        test.Fatalf("Unexpected error (a grade may have been sent): '%v'.", err);
+    }
+}
@@ -0,0 +1,69 @@
+package moodle
+
+import (
+    "fmt"
+    neturl "net/url"
+    "strings"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/log"
+)
+
+func (this *MoodleBackend) FetchUsers() ([]*lmstypes.User, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    moodleUsers, err := this.fetchEnrolledUsers();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    users := make([]*lmstypes.User, 0, len(moodleUsers));
+    for _, user := range moodleUsers {
+        users = append(users, user.ToLMSType());
+    }
+
+    return users, nil;
+}
+
+// Moodle cannot search a course's users by email (and still get their course roles),
+// so all the enrolled users are fetched and searched.
+func (this *MoodleBackend) FetchUser(email string) (*lmstypes.User, error) {
+    this.getAPILock();
+    defer this.releaseAPILock();
+
+    moodleUsers, err := this.fetchEnrolledUsers();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    for _, user := range moodleUsers {
+        if (strings.EqualFold(user.Email, email)) {
+            return user.ToLMSType(), nil;
+        }
+    }
+
+    log.Info("Did not find a matching user in moodle.", log.NewAttr("email", email));
+    return nil, nil;
+}
+
+// The caller should already hold the API lock.
+func (this *MoodleBackend) fetchEnrolledUsers() ([]*User, error) {
+    params := neturl.Values{};
+    params.Set("courseid", this.CourseID);
+
+    var pageUsers []*User;
+    err := this.callFunction("core_enrol_get_enrolled_users", params, false, &pageUsers);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch users: '%w'.", err);
+    }
+
+    users := make([]*User, 0, len(pageUsers));
+    for _, user := range pageUsers {
+        if (user != nil) {
+            users = append(users, user);
+        }
+    }
+
+    return users, nil;
+}
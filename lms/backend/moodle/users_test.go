@@ -0,0 +1,89 @@
+package moodle
+
+import (
+    "reflect"
+    "testing"
+
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+var testUsers []*lmstypes.User = []*lmstypes.User{
+    &lmstypes.User{
+        ID: "10",
+        Name: "owner",
+        Email: "owner@test.com",
+        Role: model.RoleOwner,
+    },
+    &lmstypes.User{
+        ID: "20",
+        Name: "admin",
+        Email: "admin@test.com",
+        Role: model.RoleAdmin,
+    },
+    &lmstypes.User{
+        ID: "30",
+        Name: "grader",
+        Email: "grader@test.com",
+        Role: model.RoleGrader,
+    },
+    &lmstypes.User{
+        ID: "40",
+        Name: "student",
+        Email: "student@test.com",
+        Role: model.RoleStudent,
+    },
+    &lmstypes.User{
+        ID: "50",
+        Name: "other",
+        Email: "other@test.com",
+        Role: model.RoleOther,
+    },
+};
+
+func TestMoodleUserGetBase(test *testing.T) {
+    testCases := []struct{email string; expected *lmstypes.User}{
+        {"owner@test.com", testUsers[0]},
+        {"admin@test.com", testUsers[1]},
+        {"STUDENT@test.com", testUsers[3]},
+        {"zzz@test.com", nil},
+    };
+
+    for i, testCase := range testCases {
+        user, err := testBackend.FetchUser(testCase.email);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to fetch user: '%v'.", i, err);
+            continue;
+        }
+
+        if (!reflect.DeepEqual(testCase.expected, user)) {
+            test.Errorf("Case %d: User not as expected. Expected: '%+v', Actual: '%+v'.", i, testCase.expected, user);
+            continue;
+        }
+    }
+}
+
+func TestMoodleUsersGetBase(test *testing.T) {
+    users, err := testBackend.FetchUsers();
+    if (err != nil) {
+        test.Fatalf("Failed to fetch users: '%v'.", err);
+    }
+
+    if (!reflect.DeepEqual(testUsers, users)) {
+        test.Fatalf("Users not as expected. Expected: '%s', Actual: '%s'.",
+                util.MustToJSONIndent(testUsers), util.MustToJSONIndent(users));
+    }
+}
+
+func TestMoodleException(test *testing.T) {
+    backend, err := NewBackend("99999", TEST_TOKEN, serverURL);
+    if (err != nil) {
+        test.Fatalf("Failed to create backend: '%v'.", err);
+    }
+
+    users, err := backend.FetchUsers();
+    if (err == nil) {
+        test.Fatalf("Did not get an error on a Moodle exception. Got users: '%s'.", util.MustToJSONIndent(users));
+    }
+}
@@ -10,6 +10,8 @@ import (
 // Settings to help in testing.
 var failUpdateAssignmentScores bool = false;
 var usersModifier FetchUsersModifier = nil;
+var groups []*lmstypes.Group = nil;
+var assignments map[string]*lmstypes.Assignment = nil;
 
 type TestLMSBackend struct {
     CourseID string
@@ -42,12 +44,34 @@ func ClearUsersModifier() {
     usersModifier = nil;
 }
 
+// Set the groups returned from FetchGroups() (nil by default).
+func SetGroups(newGroups []*lmstypes.Group) {
+    groups = newGroups;
+}
+
+func ClearGroups() {
+    groups = nil;
+}
+
+// Set an assignment returned from FetchAssignment() (nil by default).
+func SetAssignment(assignment *lmstypes.Assignment) {
+    if (assignments == nil) {
+        assignments = make(map[string]*lmstypes.Assignment);
+    }
+
+    assignments[assignment.ID] = assignment;
+}
+
+func ClearAssignments() {
+    assignments = nil;
+}
+
 func (this *TestLMSBackend) FetchAssignments() ([]*lmstypes.Assignment, error) {
     return nil, nil;
 }
 
 func (this *TestLMSBackend) FetchAssignment(assignmentID string) (*lmstypes.Assignment, error) {
-    return nil, nil;
+    return assignments[assignmentID], nil;
 }
 
 func (this *TestLMSBackend) UpdateComments(assignmentID string, comments []*lmstypes.SubmissionComment) error {
@@ -65,3 +89,7 @@ func (this *TestLMSBackend) FetchAssignmentScores(assignmentID string) ([]*lmsty
 func (this *TestLMSBackend) FetchAssignmentScore(assignmentID string, userID string) (*lmstypes.SubmissionScore, error) {
     return nil, nil;
 }
+
+func (this *TestLMSBackend) FetchGroups() ([]*lmstypes.Group, error) {
+    return groups, nil;
+}
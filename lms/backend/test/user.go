@@ -27,17 +27,18 @@ func (this *TestLMSBackend) FetchUsers() ([]*lmstypes.User, error) {
 }
 
 func (this *TestLMSBackend) FetchUser(email string) (*lmstypes.User, error) {
-    users, err := db.GetUsersFromID(this.CourseID);
+    users, err := this.FetchUsers();
     if (err != nil) {
-        return nil, fmt.Errorf("Failed to get users: '%w'.", err);
+        return nil, err;
     }
 
-    user := users[email];
-    if (user == nil) {
-        return nil, nil;
+    for _, user := range users {
+        if (user.Email == email) {
+            return user, nil;
+        }
     }
 
-    return UserFromAGUser(user), nil;
+    return nil, nil;
 }
 
 func UserFromAGUser(user *model.User) *lmstypes.User {
@@ -4,6 +4,8 @@ import (
     "fmt"
 
     "github.com/edulinq/autograder/lms/backend/canvas"
+    "github.com/edulinq/autograder/lms/backend/lti"
+    "github.com/edulinq/autograder/lms/backend/moodle"
     "github.com/edulinq/autograder/lms/backend/test"
     "github.com/edulinq/autograder/lms/lmstypes"
     "github.com/edulinq/autograder/model"
@@ -22,6 +24,8 @@ type lmsBackend interface {
 
     FetchUsers() ([]*lmstypes.User, error)
     FetchUser(email string) (*lmstypes.User, error)
+
+    FetchGroups() ([]*lmstypes.Group, error)
 }
 
 func getBackend(course *model.Course) (lmsBackend, error) {
@@ -37,6 +41,20 @@ func getBackend(course *model.Course) (lmsBackend, error) {
                 return nil, err;
             }
 
+            return backend, nil;
+        case model.LMS_TYPE_LTI:
+            backend, err := lti.NewBackend(adapter.LTI);
+            if (err != nil) {
+                return nil, err;
+            }
+
+            return backend, nil;
+        case model.LMS_TYPE_MOODLE:
+            backend, err := moodle.NewBackend(adapter.LMSCourseID, adapter.APIToken, adapter.BaseURL);
+            if (err != nil) {
+                return nil, err;
+            }
+
             return backend, nil;
         case model.LMS_TYPE_TEST:
             backend, err := test.NewBackend(course.GetID());
@@ -131,3 +149,11 @@ func FetchUser(course *model.Course, email string) (*lmstypes.User, error) {
     return backend.FetchUser(email);
 }
 
+func FetchGroups(course *model.Course) ([]*lmstypes.Group, error) {
+    backend, err := getBackend(course);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return backend.FetchGroups();
+}
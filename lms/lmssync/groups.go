@@ -0,0 +1,107 @@
+package lmssync
+
+import (
+    "fmt"
+    "regexp"
+    "slices"
+    "strings"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/lms"
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
+)
+
+// Import groups from the LMS (regardless of the LMS adapter's sync-groups setting).
+// LMS groups are matched to local groups by LMS ID, unmatched LMS groups are added as new groups.
+// Only LMS group members that are users in the course are kept.
+// Local groups that did not come from the LMS are left alone.
+func SyncLMSGroups(course *model.Course, dryRun bool) (*model.GroupSyncResult, error) {
+    lmsGroups, err := lms.FetchGroups(course);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch LMS groups: '%w'.", err);
+    }
+
+    localGroups, err := db.GetGroups(course);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get local groups: '%w'.", err);
+    }
+
+    users, err := db.GetUsers(course);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get users: '%w'.", err);
+    }
+
+    localGroupsByLMSID := make(map[string]*model.Group, len(localGroups));
+    for _, localGroup := range localGroups {
+        if (localGroup.LMSID != "") {
+            localGroupsByLMSID[localGroup.LMSID] = localGroup;
+        }
+    }
+
+    result := model.NewGroupSyncResult();
+    groups := make([]*model.Group, 0, len(lmsGroups));
+
+    for _, lmsGroup := range lmsGroups {
+        group := &model.Group{
+            ID: getLMSGroupID(lmsGroup),
+            Name: lmsGroup.Name,
+            Members: make([]string, 0, len(lmsGroup.Members)),
+            LMSID: lmsGroup.ID,
+        };
+
+        for _, member := range lmsGroup.Members {
+            if (users[member] == nil) {
+                result.UnknownMembers = append(result.UnknownMembers, member);
+                continue;
+            }
+
+            group.Members = append(group.Members, member);
+        }
+
+        slices.Sort(group.Members);
+
+        // Empty groups are not allowed.
+        if (len(group.Members) == 0) {
+            continue;
+        }
+
+        localGroup := localGroupsByLMSID[lmsGroup.ID];
+        if (localGroup == nil) {
+            result.Add = append(result.Add, group);
+        } else {
+            // Keep the local ID.
+            group.ID = localGroup.ID;
+
+            if ((localGroup.Name == group.Name) && slices.Equal(localGroup.Members, group.Members)) {
+                result.Unchanged = append(result.Unchanged, group);
+                continue;
+            }
+
+            result.Mod = append(result.Mod, group);
+        }
+
+        groups = append(groups, group);
+    }
+
+    slices.Sort(result.UnknownMembers);
+    result.UnknownMembers = slices.Compact(result.UnknownMembers);
+
+    if (!dryRun && (len(groups) > 0)) {
+        err = db.SaveGroups(course, groups);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to save groups: '%w'.", err);
+        }
+    }
+
+    return result, nil;
+}
+
+// Get a local ID for a new group from the LMS.
+func getLMSGroupID(lmsGroup *lmstypes.Group) string {
+    id := strings.ToLower(lmsGroup.ID);
+    id = regexp.MustCompile(`[^a-z0-9\._\-]+`).ReplaceAllString(id, "-");
+    id = strings.Trim(id, "._-");
+
+    return "lms-" + id;
+}
@@ -0,0 +1,82 @@
+package lmssync
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/db"
+    lmstest "github.com/edulinq/autograder/lms/backend/test"
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestSyncLMSGroups(test *testing.T) {
+    defer lmstest.ClearGroups();
+    reset();
+    defer reset();
+
+    course := db.MustGetTestCourse();
+
+    lmstest.SetGroups([]*lmstypes.Group{
+        &lmstypes.Group{ID: "1001", Name: "Team A", Members: []string{"student@test.com", "zzz@test.com"}},
+        &lmstypes.Group{ID: "1002", Name: "Team B", Members: []string{"zzz@test.com"}},
+    });
+
+    // A dry run should not save anything.
+    result, err := SyncLMSGroups(course, true);
+    if (err != nil) {
+        test.Fatalf("Failed to dry run group sync: '%v'.", err);
+    }
+
+    if ((len(result.Add) != 1) || (len(result.UnknownMembers) != 1)) {
+        test.Fatalf("Unexpected dry run result: '%s'.", util.MustToJSONIndent(result));
+    }
+
+    groups, err := db.GetGroups(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get groups: '%v'.", err);
+    }
+
+    if (len(groups) != 0) {
+        test.Fatalf("Dry run saved groups: '%s'.", util.MustToJSONIndent(groups));
+    }
+
+    result, err = SyncLMSGroups(course, false);
+    if (err != nil) {
+        test.Fatalf("Failed to sync groups: '%v'.", err);
+    }
+
+    expected := &model.Group{ID: "lms-1001", Name: "Team A", Members: []string{"student@test.com"}, LMSID: "1001"};
+
+    groups, err = db.GetGroups(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get groups: '%v'.", err);
+    }
+
+    if ((len(groups) != 1) || (util.MustToJSON(groups["lms-1001"]) != util.MustToJSON(expected))) {
+        test.Fatalf("Unexpected groups. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(groups));
+    }
+
+    // Membership changes in the LMS update the existing group.
+    lmstest.SetGroups([]*lmstypes.Group{
+        &lmstypes.Group{ID: "1001", Name: "Team A", Members: []string{"other@test.com", "student@test.com"}},
+    });
+
+    result, err = SyncLMSGroups(course, false);
+    if (err != nil) {
+        test.Fatalf("Failed to sync modified groups: '%v'.", err);
+    }
+
+    if ((len(result.Add) != 0) || (len(result.Mod) != 1) || (result.Mod[0].ID != "lms-1001")) {
+        test.Fatalf("Unexpected modified result: '%s'.", util.MustToJSONIndent(result));
+    }
+
+    groups, err = db.GetGroups(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get groups: '%v'.", err);
+    }
+
+    if ((len(groups) != 1) || (len(groups["lms-1001"].Members) != 2)) {
+        test.Fatalf("Unexpected modified groups: '%s'.", util.MustToJSONIndent(groups));
+    }
+}
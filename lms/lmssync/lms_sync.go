@@ -11,6 +11,7 @@ import (
     "github.com/edulinq/autograder/lms/lmstypes"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/util"
+    "github.com/edulinq/autograder/webhook"
 )
 
 // Sync all available aspects of the course with their LMS.
@@ -30,11 +31,24 @@ func SyncLMS(course *model.Course, dryRun bool, sendEmails bool) (*model.LMSSync
         return nil, err;
     }
 
+    var groupSync *model.GroupSyncResult = nil;
+    if (course.GetLMSAdapter().SyncGroups) {
+        groupSync, err = SyncLMSGroups(course, dryRun);
+        if (err != nil) {
+            return nil, err;
+        }
+    }
+
     result := &model.LMSSyncResult{
         UserSync: userSync,
         AssignmentSync: assignmentSync,
+        GroupSync: groupSync,
     };
 
+    if (!dryRun) {
+        webhook.EmitLMSSync(course, result);
+    }
+
     return result, nil;
 }
 
@@ -76,6 +90,48 @@ func SyncLMSUserEmails(course *model.Course, emails []string, dryRun bool, sendE
     return syncLMSUsers(course, dryRun, sendEmails, lmsUsers, emails);
 }
 
+// Add a single user from the LMS roster to the course (regardless of the LMS adapter's sync-user-adds setting).
+// The user will get a random password (which is not sent to them).
+// Returns nil (with no error) if the course has no LMS or the user is not in the LMS.
+// If the user already exists in the course, the existing user is returned.
+func ProvisionLMSUser(course *model.Course, email string) (*model.User, error) {
+    if (!course.HasLMSAdapter()) {
+        return nil, nil;
+    }
+
+    localUser, err := db.GetUser(course, email);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get local user: '%w'.", err);
+    }
+
+    if (localUser != nil) {
+        return localUser, nil;
+    }
+
+    lmsUser, err := lms.FetchUser(course, email);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch LMS user: '%w'.", err);
+    }
+
+    if (lmsUser == nil) {
+        return nil, nil;
+    }
+
+    user := &model.User{
+        Email: email,
+        Name: lmsUser.Name,
+        Role: lmsUser.Role,
+        LMSID: lmsUser.ID,
+    };
+
+    _, err = db.SyncUser(course, user, false, false, false);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to save provisioned user: '%w'.", err);
+    }
+
+    return user, nil;
+}
+
 // Sync users.
 // If |syncEmails| is not empty, then only emails in it will be checked/resolved.
 // Otherwise, all emails from local and LMS users will be checked.
@@ -117,7 +173,7 @@ func syncLMSUsers(course *model.Course, dryRun bool, sendEmails bool, lmsUsers m
 
         for _, newUser := range syncResult.Add {
             pass := syncResult.ClearTextPasswords[newUser.Email];
-            err = errors.Join(err, model.SendUserAddEmail(course, newUser, pass, true, false, dryRun, true));
+            err = errors.Join(err, model.SendUserAddEmail(course, newUser, pass, true, false, dryRun));
         }
 
         if (err != nil) {
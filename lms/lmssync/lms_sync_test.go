@@ -311,3 +311,58 @@ var addEmails []*email.Message = []*email.Message{
         HTML: false,
     },
 };
+
+func TestProvisionLMSUser(test *testing.T) {
+    reset();
+    defer reset();
+
+    lmstest.SetUsersModifier(func(users []*lmstypes.User) []*lmstypes.User {
+        return append(users, &lmstypes.User{
+            ID: "lms-new@test.com",
+            Name: "new",
+            Email: "new@test.com",
+            Role: model.RoleStudent,
+        });
+    });
+
+    course := db.MustGetTestCourse();
+
+    // Existing users are returned as-is.
+    user, err := ProvisionLMSUser(course, "student@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to provision existing user: '%v'.", err);
+    }
+
+    if ((user == nil) || (user.Email != "student@test.com")) {
+        test.Fatalf("Unexpected existing user: '%+v'.", user);
+    }
+
+    // Users not in the LMS are not added.
+    user, err = ProvisionLMSUser(course, "ZZZ@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to provision unknown user: '%v'.", err);
+    }
+
+    if (user != nil) {
+        test.Fatalf("Unknown user was provisioned: '%+v'.", user);
+    }
+
+    // Users in the LMS are added.
+    user, err = ProvisionLMSUser(course, "new@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to provision new user: '%v'.", err);
+    }
+
+    if ((user == nil) || (user.Role != model.RoleStudent) || (user.LMSID != "lms-new@test.com")) {
+        test.Fatalf("Unexpected new user: '%+v'.", user);
+    }
+
+    dbUser, err := db.GetUser(course, "new@test.com");
+    if (err != nil) {
+        test.Fatalf("Failed to get new user: '%v'.", err);
+    }
+
+    if (dbUser == nil) {
+        test.Fatalf("Provisioned user was not saved.");
+    }
+}
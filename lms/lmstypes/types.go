@@ -34,3 +34,10 @@ type Assignment struct {
     DueDate *time.Time
     MaxPoints float64
 }
+
+type Group struct {
+    ID string
+    Name string
+    // Member emails.
+    Members []string
+}
@@ -0,0 +1,152 @@
+package lti
+
+// The tool's signing key.
+// Platforms verify our service token requests with the public half of this key (served at the tool's JWKS endpoint).
+// If no key is configured (lti.key), then one is generated and saved in the config dir.
+
+import (
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/pem"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/oidc"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    TOOL_KEY_BITS = 2048;
+    TOOL_KEY_FILENAME = "lti-tool-key.pem";
+)
+
+var keyLock sync.Mutex;
+var toolKey *rsa.PrivateKey = nil;
+var toolKeyID string = "";
+var toolKeyPath string = "";
+
+// Get the tool's private key and key ID.
+// The key is loaded once (per path), and generated if it does not exist.
+func GetToolKey() (*rsa.PrivateKey, string, error) {
+    path := getKeyPath();
+
+    keyLock.Lock();
+    defer keyLock.Unlock();
+
+    if ((toolKey != nil) && (toolKeyPath == path)) {
+        return toolKey, toolKeyID, nil;
+    }
+
+    key, err := loadOrCreateKey(path);
+    if (err != nil) {
+        return nil, "", err;
+    }
+
+    toolKey = key;
+    toolKeyID = getKeyID(&key.PublicKey);
+    toolKeyPath = path;
+
+    return toolKey, toolKeyID, nil;
+}
+
+// Get the public key set that platforms use to verify the tool.
+func GetToolKeySet() (*oidc.JSONWebKeySet, error) {
+    key, keyID, err := GetToolKey();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    keySet := &oidc.JSONWebKeySet{
+        Keys: []*oidc.JSONWebKey{oidc.NewJSONWebKey(&key.PublicKey, keyID)},
+    };
+
+    return keySet, nil;
+}
+
+func getKeyPath() string {
+    path := config.LTI_KEY_PATH.Get();
+    if (path != "") {
+        return path;
+    }
+
+    return filepath.Join(config.GetConfigDir(), TOOL_KEY_FILENAME);
+}
+
+func loadOrCreateKey(path string) (*rsa.PrivateKey, error) {
+    if (util.PathExists(path)) {
+        return loadKey(path);
+    }
+
+    key, err := rsa.GenerateKey(rand.Reader, TOOL_KEY_BITS);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to generate LTI tool key: '%w'.", err);
+    }
+
+    data, err := x509.MarshalPKCS8PrivateKey(key);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to marshal LTI tool key: '%w'.", err);
+    }
+
+    err = util.MkDir(filepath.Dir(path));
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to create dir for LTI tool key '%s': '%w'.", path, err);
+    }
+
+    err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}), 0600);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to write LTI tool key '%s': '%w'.", path, err);
+    }
+
+    log.Info("Generated a new LTI tool key.", log.NewAttr("path", path));
+
+    return key, nil;
+}
+
+func loadKey(path string) (*rsa.PrivateKey, error) {
+    data, err := os.ReadFile(path);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to read LTI tool key '%s': '%w'.", path, err);
+    }
+
+    block, _ := pem.Decode(data);
+    if (block == nil) {
+        return nil, fmt.Errorf("LTI tool key '%s' is not PEM encoded.", path);
+    }
+
+    if (block.Type == "RSA PRIVATE KEY") {
+        key, err := x509.ParsePKCS1PrivateKey(block.Bytes);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to parse LTI tool key '%s': '%w'.", path, err);
+        }
+
+        return key, nil;
+    }
+
+    parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to parse LTI tool key '%s': '%w'.", path, err);
+    }
+
+    key, ok := parsedKey.(*rsa.PrivateKey);
+    if (!ok) {
+        return nil, fmt.Errorf("LTI tool key '%s' is not an RSA key.", path);
+    }
+
+    return key, nil;
+}
+
+// Use the key's JWK thumbprint (RFC 7638) as its ID.
+func getKeyID(key *rsa.PublicKey) string {
+    webKey := oidc.NewJSONWebKey(key, "");
+    thumbprint := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, webKey.E, webKey.N);
+
+    digest := sha256.Sum256([]byte(thumbprint));
+    return base64.RawURLEncoding.EncodeToString(digest[:]);
+}
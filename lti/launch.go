@@ -0,0 +1,209 @@
+package lti
+
+// Verification of launches.
+
+import (
+    "crypto/rsa"
+    "fmt"
+    "net/url"
+    "slices"
+    "strings"
+    "sync"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+)
+
+// Platform keys, keyed by key set URL and then key ID.
+var platformKeysLock sync.Mutex;
+var platformKeys map[string]map[string]*rsa.PublicKey = make(map[string]map[string]*rsa.PublicKey);
+
+// Get the URL to send a user to in order to authorize a launch (the platform will then post the launch to |redirectURL|).
+// The hints are opaque values from the platform's login initiation request that must be passed back.
+func AuthURL(registration *model.LTIRegistration, redirectURL string, loginHint string, messageHint string, state *oidc.LoginState) string {
+    params := url.Values{};
+    params.Set("scope", "openid");
+    params.Set("response_type", "id_token");
+    params.Set("response_mode", "form_post");
+    params.Set("prompt", "none");
+    params.Set("client_id", registration.ClientID);
+    params.Set("redirect_uri", redirectURL);
+    params.Set("login_hint", loginHint);
+    params.Set("state", state.State);
+    params.Set("nonce", state.Nonce);
+
+    if (messageHint != "") {
+        params.Set("lti_message_hint", messageHint);
+    }
+
+    separator := "?";
+    if (strings.Contains(registration.AuthLoginURL, "?")) {
+        separator = "&";
+    }
+
+    return registration.AuthLoginURL + separator + params.Encode();
+}
+
+// Find a registration for the given platform (and client, if not empty).
+// Returns nil if no course is registered with the platform.
+func FindRegistration(issuer string, clientID string) (*model.LTIRegistration, error) {
+    courses, err := getRegisteredCourses(issuer, clientID);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (len(courses) == 0) {
+        return nil, nil;
+    }
+
+    return courses[0].GetLMSAdapter().LTI, nil;
+}
+
+// Verify a launch (the ID token posted by the platform) and find the course it is for.
+func VerifyLaunch(rawToken string, nonce string) (*LaunchClaims, *model.Course, error) {
+    var unverifiedClaims oidc.Claims;
+    err := oidc.DecodeUnverifiedJWT(rawToken, &unverifiedClaims);
+    if (err != nil) {
+        return nil, nil, err;
+    }
+
+    var courses []*model.Course = nil;
+    for _, clientID := range unverifiedClaims.Audience {
+        courses, err = getRegisteredCourses(unverifiedClaims.Issuer, clientID);
+        if (err != nil) {
+            return nil, nil, err;
+        }
+
+        if (len(courses) > 0) {
+            break;
+        }
+    }
+
+    if (len(courses) == 0) {
+        return nil, nil, fmt.Errorf("No course is registered with LTI platform '%s'.", unverifiedClaims.Issuer);
+    }
+
+    registration := courses[0].GetLMSAdapter().LTI;
+
+    getKey := func(keyID string) (*rsa.PublicKey, error) {
+        return getPlatformKey(registration.KeySetURL, keyID);
+    };
+
+    var claims LaunchClaims;
+    err = oidc.VerifyJWT(rawToken, getKey, &claims);
+    if (err != nil) {
+        return nil, nil, err;
+    }
+
+    err = claims.Validate(registration.Issuer, registration.ClientID, nonce);
+    if (err != nil) {
+        return nil, nil, err;
+    }
+
+    if (claims.MessageType != MESSAGE_TYPE_RESOURCE_LINK) {
+        return nil, nil, fmt.Errorf("Unsupported LTI message type: '%s'.", claims.MessageType);
+    }
+
+    if (claims.Version != LTI_VERSION) {
+        return nil, nil, fmt.Errorf("Unsupported LTI version: '%s'.", claims.Version);
+    }
+
+    if (claims.DeploymentID == "") {
+        return nil, nil, fmt.Errorf("Launch does not have a deployment ID.");
+    }
+
+    course, err := matchCourse(courses, &claims);
+    if (err != nil) {
+        return nil, nil, err;
+    }
+
+    return &claims, course, nil;
+}
+
+// Pick the course for a launch from the courses registered with the launch's platform and client.
+// A course linked to the launch's context is preferred over a course that accepts any context.
+func matchCourse(courses []*model.Course, claims *LaunchClaims) (*model.Course, error) {
+    contextID := claims.GetContextID();
+    candidates := make([]*model.Course, 0);
+
+    for _, course := range courses {
+        registration := course.GetLMSAdapter().LTI;
+
+        if ((registration.DeploymentID != "") && (registration.DeploymentID != claims.DeploymentID)) {
+            continue;
+        }
+
+        if ((contextID != "") && (registration.ContextID == contextID)) {
+            return course, nil;
+        }
+
+        if (registration.ContextID == "") {
+            candidates = append(candidates, course);
+        }
+    }
+
+    if (len(candidates) == 0) {
+        return nil, fmt.Errorf("No course is linked to LTI deployment '%s' and context '%s'.", claims.DeploymentID, contextID);
+    }
+
+    if (len(candidates) > 1) {
+        return nil, fmt.Errorf("Multiple courses could match LTI deployment '%s' and context '%s', set a context ID.", claims.DeploymentID, contextID);
+    }
+
+    return candidates[0], nil;
+}
+
+// Get the courses (sorted by ID) with a matching LTI registration.
+func getRegisteredCourses(issuer string, clientID string) ([]*model.Course, error) {
+    courses, err := db.GetCourses();
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get courses: '%w'.", err);
+    }
+
+    matches := make([]*model.Course, 0);
+    for _, course := range courses {
+        adapter := course.GetLMSAdapter();
+        if ((adapter == nil) || (adapter.LTI == nil)) {
+            continue;
+        }
+
+        if ((clientID == "") && (adapter.LTI.Issuer == strings.TrimSuffix(issuer, "/"))) {
+            matches = append(matches, course);
+        } else if (adapter.LTI.Matches(issuer, clientID)) {
+            matches = append(matches, course);
+        }
+    }
+
+    slices.SortFunc(matches, func(a *model.Course, b *model.Course) int {
+        return strings.Compare(a.GetID(), b.GetID());
+    });
+
+    return matches, nil;
+}
+
+// Get a platform's key.
+// Keys are cached, but will be refetched if an unknown key is requested (in case the platform rotated its keys).
+func getPlatformKey(keySetURL string, keyID string) (*rsa.PublicKey, error) {
+    platformKeysLock.Lock();
+    defer platformKeysLock.Unlock();
+
+    key := platformKeys[keySetURL][keyID];
+    if (key != nil) {
+        return key, nil;
+    }
+
+    keys, err := oidc.FetchKeySet(keySetURL);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get LTI platform keys: '%w'.", err);
+    }
+
+    platformKeys[keySetURL] = keys;
+
+    key = keys[keyID];
+    if (key == nil) {
+        return nil, fmt.Errorf("Unknown LTI platform key: '%s'.", keyID);
+    }
+
+    return key, nil;
+}
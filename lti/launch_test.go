@@ -0,0 +1,138 @@
+package lti
+
+import (
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+)
+
+const (
+    TEST_CLIENT_ID = "test-client";
+    TEST_DEPLOYMENT_ID = "test-deployment";
+    TEST_CONTEXT_ID = "test-context";
+    TEST_NONCE = "test-nonce";
+    TEST_USER_ID = "lti-student";
+)
+
+func TestVerifyLaunch(test *testing.T) {
+    platform := setupTestPlatform(test);
+    defer cleanupTestPlatform(platform);
+
+    otherPlatform, err := NewTestPlatform(TEST_CLIENT_ID, TEST_DEPLOYMENT_ID, TEST_CONTEXT_ID);
+    if (err != nil) {
+        test.Fatalf("Failed to start other test platform: '%v'.", err);
+    }
+    defer otherPlatform.Close();
+
+    testCases := []struct{ modify func(*LaunchClaims); signer *TestPlatform; errorSubstring string }{
+        {nil, platform, ""},
+        {func(claims *LaunchClaims) { claims.Nonce = "ZZZ" }, platform, "wrong nonce"},
+        {func(claims *LaunchClaims) { claims.Expiration = time.Now().Add(-time.Hour).Unix() }, platform, "expired"},
+        {func(claims *LaunchClaims) { claims.Issuer = "http://localhost/other" }, platform, "No course is registered"},
+        {func(claims *LaunchClaims) { claims.Audience[0] = "other" }, platform, "No course is registered"},
+        {func(claims *LaunchClaims) { claims.MessageType = "LtiDeepLinkingRequest" }, platform, "Unsupported LTI message type"},
+        {func(claims *LaunchClaims) { claims.Version = "1.1" }, platform, "Unsupported LTI version"},
+        {func(claims *LaunchClaims) { claims.DeploymentID = "" }, platform, "deployment ID"},
+        {func(claims *LaunchClaims) { claims.DeploymentID = "other" }, platform, "No course is linked"},
+        {func(claims *LaunchClaims) { claims.Context.ID = "other" }, platform, "No course is linked"},
+        {nil, otherPlatform, "bad signature"},
+    };
+
+    for i, testCase := range testCases {
+        claims := platform.NewLaunchClaims(TEST_USER_ID, TEST_NONCE);
+        if (testCase.modify != nil) {
+            testCase.modify(claims);
+        }
+
+        token, err := testCase.signer.SignLaunch(claims);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to sign launch: '%v'.", i, err);
+            continue;
+        }
+
+        verifiedClaims, course, err := VerifyLaunch(token, TEST_NONCE);
+        if (testCase.errorSubstring != "") {
+            if (err == nil) {
+                test.Errorf("Case %d: Did not get an expected error.", i);
+            } else if (!strings.Contains(err.Error(), testCase.errorSubstring)) {
+                test.Errorf("Case %d: Unexpected error. Expected substring: '%s', Actual: '%v'.", i, testCase.errorSubstring, err);
+            }
+
+            continue;
+        }
+
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to verify launch: '%v'.", i, err);
+            continue;
+        }
+
+        if (course.GetID() != "course101") {
+            test.Errorf("Case %d: Unexpected course. Expected: 'course101', Actual: '%s'.", i, course.GetID());
+            continue;
+        }
+
+        if ((verifiedClaims.Subject != TEST_USER_ID) || (verifiedClaims.Email != "student@test.com")) {
+            test.Errorf("Case %d: Unexpected user. Expected: '%s' ('student@test.com'), Actual: '%s' ('%s').",
+                    i, TEST_USER_ID, verifiedClaims.Subject, verifiedClaims.Email);
+            continue;
+        }
+
+        if (GetRole(verifiedClaims.Roles) != model.RoleStudent) {
+            test.Errorf("Case %d: Unexpected role. Expected: 'student', Actual: '%s'.", i, GetRole(verifiedClaims.Roles).String());
+            continue;
+        }
+    }
+}
+
+func TestFindRegistration(test *testing.T) {
+    platform := setupTestPlatform(test);
+    defer cleanupTestPlatform(platform);
+
+    testCases := []struct{ issuer string; clientID string; found bool }{
+        {platform.URL(), TEST_CLIENT_ID, true},
+        {platform.URL() + "/", TEST_CLIENT_ID, true},
+        {platform.URL(), "", true},
+        {platform.URL(), "ZZZ", false},
+        {"http://localhost/other", TEST_CLIENT_ID, false},
+    };
+
+    for i, testCase := range testCases {
+        registration, err := FindRegistration(testCase.issuer, testCase.clientID);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to find registration: '%v'.", i, err);
+            continue;
+        }
+
+        if (testCase.found != (registration != nil)) {
+            test.Errorf("Case %d: Unexpected result. Expected found: '%v', Actual: '%v'.", i, testCase.found, registration);
+            continue;
+        }
+    }
+}
+
+func setupTestPlatform(test *testing.T) *TestPlatform {
+    platform, err := NewTestPlatform(TEST_CLIENT_ID, TEST_DEPLOYMENT_ID, TEST_CONTEXT_ID);
+    if (err != nil) {
+        test.Fatalf("Failed to start test platform: '%v'.", err);
+    }
+
+    platform.AddMember(TEST_USER_ID, "student@test.com", "student", ROLE_LEARNER);
+
+    course := db.MustGetTestCourse();
+    course.LMS.LTI = platform.GetRegistration();
+
+    err = db.SaveCourse(course);
+    if (err != nil) {
+        test.Fatalf("Failed to save course: '%v'.", err);
+    }
+
+    return platform;
+}
+
+func cleanupTestPlatform(platform *TestPlatform) {
+    platform.Close();
+    db.ResetForTesting();
+}
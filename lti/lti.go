@@ -0,0 +1,120 @@
+package lti
+
+// Support for running as an LTI 1.3 tool (https://www.imsglobal.org/spec/lti/v1p3).
+// A launch is an OIDC third-party initiated login:
+// the LMS (the "platform") sends the user's browser to our login endpoint,
+// we send them back to the platform's auth endpoint,
+// and the platform posts a signed ID token (the launch) to our launch endpoint.
+// A course is linked to a platform with the LTI registration in its LMS adapter (see model.LTIRegistration).
+// The registration can also be used to call the platform's services (AGS and NRPS) instead of a vendor API (see the lti LMS backend).
+
+import (
+    "slices"
+    "strings"
+
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+)
+
+const (
+    LTI_VERSION = "1.3.0";
+    MESSAGE_TYPE_RESOURCE_LINK = "LtiResourceLinkRequest";
+
+    CLAIM_PREFIX = "https://purl.imsglobal.org/spec/lti/claim/";
+    CLAIM_AGS_ENDPOINT = "https://purl.imsglobal.org/spec/lti-ags/claim/endpoint";
+    CLAIM_NRPS = "https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice";
+
+    ROLE_MEMBERSHIP_PREFIX = "http://purl.imsglobal.org/vocab/lis/v2/membership#";
+    ROLE_ADMINISTRATOR = ROLE_MEMBERSHIP_PREFIX + "Administrator";
+    ROLE_CONTENT_DEVELOPER = ROLE_MEMBERSHIP_PREFIX + "ContentDeveloper";
+    ROLE_INSTRUCTOR = ROLE_MEMBERSHIP_PREFIX + "Instructor";
+    ROLE_LEARNER = ROLE_MEMBERSHIP_PREFIX + "Learner";
+    ROLE_MENTOR = ROLE_MEMBERSHIP_PREFIX + "Mentor";
+    ROLE_TEACHING_ASSISTANT = "http://purl.imsglobal.org/vocab/lis/v2/membership/Instructor#TeachingAssistant";
+)
+
+// The claims of a launch (an ID token with LTI claims).
+type LaunchClaims struct {
+    oidc.Claims
+
+    MessageType string `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+    Version string `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+    DeploymentID string `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+    TargetLinkURI string `json:"https://purl.imsglobal.org/spec/lti/claim/target_link_uri,omitempty"`
+    Roles []string `json:"https://purl.imsglobal.org/spec/lti/claim/roles"`
+
+    Context *ContextClaim `json:"https://purl.imsglobal.org/spec/lti/claim/context,omitempty"`
+    ResourceLink *ResourceLinkClaim `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link,omitempty"`
+
+    Endpoint *EndpointClaim `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint,omitempty"`
+    NamesRoleService *NamesRoleServiceClaim `json:"https://purl.imsglobal.org/spec/lti-nrps/claim/namesroleservice,omitempty"`
+}
+
+type ContextClaim struct {
+    ID string `json:"id"`
+    Label string `json:"label,omitempty"`
+    Title string `json:"title,omitempty"`
+}
+
+type ResourceLinkClaim struct {
+    ID string `json:"id"`
+    Title string `json:"title,omitempty"`
+}
+
+// Assignment and Grade Services (AGS) endpoints available to the tool.
+type EndpointClaim struct {
+    Scope []string `json:"scope"`
+    LineItems string `json:"lineitems,omitempty"`
+    LineItem string `json:"lineitem,omitempty"`
+}
+
+// Names and Role Provisioning Services (NRPS) endpoint available to the tool.
+type NamesRoleServiceClaim struct {
+    ContextMembershipsURL string `json:"context_memberships_url"`
+    ServiceVersions []string `json:"service_versions,omitempty"`
+}
+
+// Context (course) roles to autograder roles.
+// Roles may also be sent in their short form (without the vocabulary prefix).
+var roleMapping map[string]model.UserRole = map[string]model.UserRole{
+    ROLE_ADMINISTRATOR: model.RoleAdmin,
+    ROLE_CONTENT_DEVELOPER: model.RoleOther,
+    ROLE_INSTRUCTOR: model.RoleOwner,
+    ROLE_LEARNER: model.RoleStudent,
+    ROLE_MENTOR: model.RoleOther,
+    ROLE_TEACHING_ASSISTANT: model.RoleGrader,
+};
+
+// Get the autograder role for a set of LTI roles.
+// Non-context (system and institution) roles are ignored,
+// and the highest matching context role is used.
+// Returns model.RoleUnknown if no role matches.
+func GetRole(roles []string) model.UserRole {
+    // A TA also has the (more general) instructor role.
+    if (slices.Contains(roles, ROLE_TEACHING_ASSISTANT)) {
+        return model.RoleGrader;
+    }
+
+    role := model.RoleUnknown;
+    for _, ltiRole := range roles {
+        if (!strings.Contains(ltiRole, "/")) {
+            ltiRole = ROLE_MEMBERSHIP_PREFIX + ltiRole;
+        }
+
+        mappedRole, ok := roleMapping[ltiRole];
+        if (ok && (mappedRole > role)) {
+            role = mappedRole;
+        }
+    }
+
+    return role;
+}
+
+// Get the context ID of a launch (empty if there is no context).
+func (this *LaunchClaims) GetContextID() string {
+    if (this.Context == nil) {
+        return "";
+    }
+
+    return this.Context.ID;
+}
@@ -0,0 +1,29 @@
+package lti
+
+import (
+    "testing"
+
+    "github.com/edulinq/autograder/model"
+)
+
+func TestGetRole(test *testing.T) {
+    testCases := []struct{ roles []string; expected model.UserRole }{
+        {[]string{}, model.RoleUnknown},
+        {[]string{"http://purl.imsglobal.org/vocab/lis/v2/system/person#Administrator"}, model.RoleUnknown},
+        {[]string{ROLE_LEARNER}, model.RoleStudent},
+        {[]string{"Learner"}, model.RoleStudent},
+        {[]string{ROLE_MENTOR}, model.RoleOther},
+        {[]string{ROLE_INSTRUCTOR}, model.RoleOwner},
+        {[]string{ROLE_INSTRUCTOR, ROLE_TEACHING_ASSISTANT}, model.RoleGrader},
+        {[]string{ROLE_ADMINISTRATOR}, model.RoleAdmin},
+        {[]string{ROLE_LEARNER, ROLE_INSTRUCTOR}, model.RoleOwner},
+        {[]string{"http://purl.imsglobal.org/vocab/lis/v2/institution/person#Instructor", ROLE_LEARNER}, model.RoleStudent},
+    };
+
+    for i, testCase := range testCases {
+        role := GetRole(testCase.roles);
+        if (role != testCase.expected) {
+            test.Errorf("Case %d: Unexpected role. Expected: '%s', Actual: '%s'.", i, testCase.expected.String(), role.String());
+        }
+    }
+}
@@ -0,0 +1,293 @@
+package lti
+
+// Calling a platform's services:
+// Assignment and Grade Services (AGS, https://www.imsglobal.org/spec/lti-ags/v2p0)
+// and Names and Role Provisioning Services (NRPS, https://www.imsglobal.org/spec/lti-nrps/v2p0).
+// Requests are authorized with an access token from the platform's token endpoint (an OAuth 2 client credentials grant),
+// where the tool authenticates with a JWT signed by its key.
+
+import (
+    "fmt"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    SCOPE_LINE_ITEM = "https://purl.imsglobal.org/spec/lti-ags/scope/lineitem";
+    SCOPE_RESULT_READONLY = "https://purl.imsglobal.org/spec/lti-ags/scope/result.readonly";
+    SCOPE_SCORE = "https://purl.imsglobal.org/spec/lti-ags/scope/score";
+    SCOPE_MEMBERSHIPS_READONLY = "https://purl.imsglobal.org/spec/lti-nrps/scope/contextmembership.readonly";
+
+    CONTENT_TYPE_LINE_ITEM = "application/vnd.ims.lis.v2.lineitem+json";
+    CONTENT_TYPE_LINE_ITEMS = "application/vnd.ims.lis.v2.lineitemcontainer+json";
+    CONTENT_TYPE_RESULTS = "application/vnd.ims.lis.v2.resultcontainer+json";
+    CONTENT_TYPE_SCORE = "application/vnd.ims.lis.v1.score+json";
+    CONTENT_TYPE_MEMBERSHIPS = "application/vnd.ims.lti-nrps.v2.membershipcontainer+json";
+
+    CLIENT_ASSERTION_TYPE = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer";
+    CLIENT_ASSERTION_TTL = 5 * time.Minute;
+
+    // Refresh access tokens a bit before they actually expire.
+    ACCESS_TOKEN_MARGIN = time.Minute;
+    DEFAULT_ACCESS_TOKEN_TTL_SECS = 3600;
+
+    HEADER_LINK = "Link";
+)
+
+var SERVICE_SCOPES []string = []string{SCOPE_LINE_ITEM, SCOPE_RESULT_READONLY, SCOPE_SCORE, SCOPE_MEMBERSHIPS_READONLY};
+
+// An AGS line item (a gradebook column).
+type LineItem struct {
+    ID string `json:"id,omitempty"`
+    Label string `json:"label"`
+    ScoreMaximum float64 `json:"scoreMaximum"`
+    ResourceID string `json:"resourceId,omitempty"`
+    ResourceLinkID string `json:"resourceLinkId,omitempty"`
+    Tag string `json:"tag,omitempty"`
+    StartDateTime string `json:"startDateTime,omitempty"`
+    EndDateTime string `json:"endDateTime,omitempty"`
+}
+
+// An AGS score (what the tool sends to the platform).
+type Score struct {
+    UserID string `json:"userId"`
+    ScoreGiven *float64 `json:"scoreGiven,omitempty"`
+    ScoreMaximum float64 `json:"scoreMaximum,omitempty"`
+    Comment string `json:"comment,omitempty"`
+    Timestamp string `json:"timestamp"`
+    ActivityProgress string `json:"activityProgress"`
+    GradingProgress string `json:"gradingProgress"`
+}
+
+// An AGS result (the score the platform currently has).
+type Result struct {
+    ID string `json:"id,omitempty"`
+    ScoreOf string `json:"scoreOf,omitempty"`
+    UserID string `json:"userId"`
+    ResultScore *float64 `json:"resultScore,omitempty"`
+    ResultMaximum float64 `json:"resultMaximum,omitempty"`
+    Comment string `json:"comment,omitempty"`
+}
+
+type MembershipContainer struct {
+    ID string `json:"id"`
+    Context *ContextClaim `json:"context,omitempty"`
+    Members []*Member `json:"members"`
+}
+
+// An NRPS member of a context.
+type Member struct {
+    UserID string `json:"user_id"`
+    Status string `json:"status,omitempty"`
+    Name string `json:"name,omitempty"`
+    Email string `json:"email,omitempty"`
+    Roles []string `json:"roles"`
+}
+
+type clientAssertion struct {
+    Issuer string `json:"iss"`
+    Subject string `json:"sub"`
+    Audience string `json:"aud"`
+    IssuedAt int64 `json:"iat"`
+    Expiration int64 `json:"exp"`
+    JWTID string `json:"jti"`
+}
+
+type tokenResponse struct {
+    AccessToken string `json:"access_token"`
+    TokenType string `json:"token_type"`
+    ExpiresIn int64 `json:"expires_in"`
+    Scope string `json:"scope"`
+}
+
+type accessToken struct {
+    token string
+    expiration time.Time
+}
+
+// Access tokens, keyed by token URL and client ID.
+var accessTokensLock sync.Mutex;
+var accessTokens map[string]*accessToken = make(map[string]*accessToken);
+
+// Get an access token for all of a platform's services.
+// Tokens are cached until they expire.
+func GetAccessToken(registration *model.LTIRegistration) (string, error) {
+    if (registration.AuthTokenURL == "") {
+        return "", fmt.Errorf("LTI registration has no auth token URL (auth-token-url).");
+    }
+
+    cacheKey := registration.AuthTokenURL + "|" + registration.ClientID;
+
+    accessTokensLock.Lock();
+    defer accessTokensLock.Unlock();
+
+    cachedToken := accessTokens[cacheKey];
+    if ((cachedToken != nil) && time.Now().Before(cachedToken.expiration)) {
+        return cachedToken.token, nil;
+    }
+
+    assertion, err := signClientAssertion(registration);
+    if (err != nil) {
+        return "", err;
+    }
+
+    form := map[string]string{
+        "grant_type": "client_credentials",
+        "client_assertion_type": CLIENT_ASSERTION_TYPE,
+        "client_assertion": assertion,
+        "scope": strings.Join(SERVICE_SCOPES, " "),
+    };
+
+    body, err := common.Post(registration.AuthTokenURL, form);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to request LTI access token: '%w'.", err);
+    }
+
+    var response tokenResponse;
+    err = util.JSONFromString(body, &response);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to parse LTI access token response: '%w'.", err);
+    }
+
+    if (response.AccessToken == "") {
+        return "", fmt.Errorf("LTI access token response does not contain a token.");
+    }
+
+    ttl := response.ExpiresIn;
+    if (ttl <= 0) {
+        ttl = DEFAULT_ACCESS_TOKEN_TTL_SECS;
+    }
+
+    accessTokens[cacheKey] = &accessToken{
+        token: response.AccessToken,
+        expiration: time.Now().Add(time.Duration(ttl) * time.Second).Add(-ACCESS_TOKEN_MARGIN),
+    };
+
+    return response.AccessToken, nil;
+}
+
+// GET a service resource, following any next links (the results of each page are passed to |handlePage|).
+func ServiceGet(registration *model.LTIRegistration, uri string, accept string, handlePage func(body string) error) error {
+    for (uri != "") {
+        headers, err := getServiceHeaders(registration, accept);
+        if (err != nil) {
+            return err;
+        }
+
+        body, responseHeaders, err := common.GetWithHeaders(uri, headers);
+        if (err != nil) {
+            return fmt.Errorf("Failed to GET LTI service resource: '%w'.", err);
+        }
+
+        err = handlePage(body);
+        if (err != nil) {
+            return err;
+        }
+
+        uri = fetchNextLink(responseHeaders);
+    }
+
+    return nil;
+}
+
+// POST a JSON resource to a service.
+func ServicePost(registration *model.LTIRegistration, uri string, contentType string, data any) (string, error) {
+    headers, err := getServiceHeaders(registration, "application/json");
+    if (err != nil) {
+        return "", err;
+    }
+
+    headers["Content-Type"] = []string{contentType};
+
+    body, _, err := common.PostBodyWithHeaders(uri, util.MustToJSON(data), headers);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to POST LTI service resource: '%w'.", err);
+    }
+
+    return body, nil;
+}
+
+// Get the URL for a service resource under |base|, e.g. the scores of a line item.
+// The base URL may already have a query (which is kept).
+func ServiceURL(base string, suffix string, query url.Values) (string, error) {
+    parsed, err := url.Parse(base);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to parse LTI service URL '%s': '%w'.", base, err);
+    }
+
+    parsed.Path = strings.TrimSuffix(parsed.Path, "/") + suffix;
+
+    params := parsed.Query();
+    for key, values := range query {
+        for _, value := range values {
+            params.Add(key, value);
+        }
+    }
+    parsed.RawQuery = params.Encode();
+
+    return parsed.String(), nil;
+}
+
+func getServiceHeaders(registration *model.LTIRegistration, accept string) (map[string][]string, error) {
+    token, err := GetAccessToken(registration);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    headers := map[string][]string{
+        "Authorization": []string{fmt.Sprintf("Bearer %s", token)},
+        "Accept": []string{accept},
+    };
+
+    return headers, nil;
+}
+
+func signClientAssertion(registration *model.LTIRegistration) (string, error) {
+    key, keyID, err := GetToolKey();
+    if (err != nil) {
+        return "", err;
+    }
+
+    now := time.Now();
+    claims := clientAssertion{
+        Issuer: registration.ClientID,
+        Subject: registration.ClientID,
+        Audience: registration.AuthTokenURL,
+        IssuedAt: now.Unix(),
+        Expiration: now.Add(CLIENT_ASSERTION_TTL).Unix(),
+        JWTID: util.UUID(),
+    };
+
+    assertion, err := oidc.SignJWT(key, keyID, claims);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to sign LTI client assertion: '%w'.", err);
+    }
+
+    return assertion, nil;
+}
+
+// See if the response headers have a next link.
+// Returns the link or an empty string.
+func fetchNextLink(headers map[string][]string) string {
+    for _, value := range headers[HEADER_LINK] {
+        for _, link := range strings.Split(value, ",") {
+            parts := strings.Split(link, ";");
+            if (len(parts) < 2) {
+                continue;
+            }
+
+            if (strings.TrimSpace(parts[1]) == `rel="next"`) {
+                return strings.Trim(strings.TrimSpace(parts[0]), "<>");
+            }
+        }
+    }
+
+    return "";
+}
@@ -0,0 +1,509 @@
+package lti
+
+// A local (mock) LTI platform for testing.
+// The platform does not ask for credentials,
+// every launch is authorized as whatever member was last set with SetLaunchUser().
+// Launches are answered (like a real platform) with an auto-submitting HTML form,
+// which can be read with Authorize().
+
+import (
+    "crypto/rand"
+    "crypto/rsa"
+    "fmt"
+    "html"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "regexp"
+    "slices"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/oidc"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    TEST_PLATFORM_KEY_ID = "test-platform-key";
+    TEST_PLATFORM_KEY_BITS = 2048;
+)
+
+var testFormInputPattern *regexp.Regexp = regexp.MustCompile(`<input type="hidden" name="([^"]*)" value="([^"]*)"\s*/>`);
+var testFormActionPattern *regexp.Regexp = regexp.MustCompile(`<form method="post" action="([^"]*)"`);
+
+type TestPlatform struct {
+    ClientID string
+    DeploymentID string
+    ContextID string
+
+    server *httptest.Server
+    key *rsa.PrivateKey
+
+    lock sync.Mutex
+    launchUserID string
+    members []*Member
+    lineItems []*LineItem
+    // {lineItemID: {userID: score}}.
+    scores map[string]map[string]*Score
+    accessTokens map[string]bool
+}
+
+func NewTestPlatform(clientID string, deploymentID string, contextID string) (*TestPlatform, error) {
+    key, err := rsa.GenerateKey(rand.Reader, TEST_PLATFORM_KEY_BITS);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to generate test platform key: '%w'.", err);
+    }
+
+    platform := &TestPlatform{
+        ClientID: clientID,
+        DeploymentID: deploymentID,
+        ContextID: contextID,
+        key: key,
+        members: make([]*Member, 0),
+        lineItems: make([]*LineItem, 0),
+        scores: make(map[string]map[string]*Score),
+        accessTokens: make(map[string]bool),
+    };
+
+    mux := http.NewServeMux();
+    mux.HandleFunc("/auth", platform.handleAuth);
+    mux.HandleFunc("/token", platform.handleToken);
+    mux.HandleFunc("/jwks", platform.handleKeys);
+    mux.HandleFunc("/memberships", platform.handleMemberships);
+    mux.HandleFunc("/lineitems", platform.handleLineItems);
+    mux.HandleFunc("/lineitems/", platform.handleLineItem);
+
+    platform.server = httptest.NewServer(mux);
+
+    return platform, nil;
+}
+
+func (this *TestPlatform) URL() string {
+    return this.server.URL;
+}
+
+func (this *TestPlatform) Close() {
+    this.server.Close();
+}
+
+// Get the registration a course would use for this platform.
+func (this *TestPlatform) GetRegistration() *model.LTIRegistration {
+    return &model.LTIRegistration{
+        Issuer: this.URL(),
+        ClientID: this.ClientID,
+        DeploymentID: this.DeploymentID,
+        AuthLoginURL: this.URL() + "/auth",
+        AuthTokenURL: this.URL() + "/token",
+        KeySetURL: this.URL() + "/jwks",
+        ContextID: this.ContextID,
+        LineItemsURL: this.URL() + "/lineitems",
+        MembershipsURL: this.URL() + "/memberships",
+    };
+}
+
+func (this *TestPlatform) AddMember(userID string, email string, name string, roles ...string) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    this.members = append(this.members, &Member{
+        UserID: userID,
+        Status: "Active",
+        Name: name,
+        Email: email,
+        Roles: roles,
+    });
+}
+
+func (this *TestPlatform) AddLineItem(label string, scoreMaximum float64, endDateTime string) *LineItem {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    lineItem := &LineItem{
+        ID: fmt.Sprintf("%s/lineitems/%d", this.URL(), len(this.lineItems) + 1),
+        Label: label,
+        ScoreMaximum: scoreMaximum,
+        EndDateTime: endDateTime,
+    };
+
+    this.lineItems = append(this.lineItems, lineItem);
+    this.scores[lineItem.ID] = make(map[string]*Score);
+
+    return lineItem;
+}
+
+// Get the latest score posted for a user (nil if there is none).
+func (this *TestPlatform) GetScore(lineItemID string, userID string) *Score {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    return this.scores[lineItemID][userID];
+}
+
+// Set the member that the next launches will be authorized as.
+func (this *TestPlatform) SetLaunchUser(userID string) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    this.launchUserID = userID;
+}
+
+// Get the parameters the platform sends to the tool's login endpoint to start a launch.
+func (this *TestPlatform) GetLoginParams() url.Values {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    params := url.Values{};
+    params.Set("iss", this.URL());
+    params.Set("login_hint", this.launchUserID);
+    params.Set("lti_message_hint", "test-message-hint");
+    params.Set("client_id", this.ClientID);
+    params.Set("lti_deployment_id", this.DeploymentID);
+
+    return params;
+}
+
+// Get the claims of a launch for a member (nil if the member does not exist).
+func (this *TestPlatform) NewLaunchClaims(userID string, nonce string) *LaunchClaims {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    var member *Member = nil;
+    for _, testMember := range this.members {
+        if (testMember.UserID == userID) {
+            member = testMember;
+        }
+    }
+
+    if (member == nil) {
+        return nil;
+    }
+
+    now := time.Now();
+
+    claims := &LaunchClaims{
+        Claims: oidc.Claims{
+            Issuer: this.URL(),
+            Subject: member.UserID,
+            Audience: oidc.Audience{this.ClientID},
+            Expiration: now.Add(time.Hour).Unix(),
+            IssuedAt: now.Unix(),
+            Nonce: nonce,
+            Email: member.Email,
+            Name: member.Name,
+        },
+        MessageType: MESSAGE_TYPE_RESOURCE_LINK,
+        Version: LTI_VERSION,
+        DeploymentID: this.DeploymentID,
+        Roles: member.Roles,
+        Context: &ContextClaim{ID: this.ContextID, Label: "TEST", Title: "Test Course"},
+        ResourceLink: &ResourceLinkClaim{ID: "test-resource-link", Title: "Autograder"},
+        Endpoint: &EndpointClaim{
+            Scope: []string{SCOPE_LINE_ITEM, SCOPE_RESULT_READONLY, SCOPE_SCORE},
+            LineItems: this.URL() + "/lineitems",
+        },
+        NamesRoleService: &NamesRoleServiceClaim{
+            ContextMembershipsURL: this.URL() + "/memberships",
+            ServiceVersions: []string{"2.0"},
+        },
+    };
+
+    return claims;
+}
+
+// Sign a launch with this platform's key.
+func (this *TestPlatform) SignLaunch(claims *LaunchClaims) (string, error) {
+    return oidc.SignJWT(this.key, TEST_PLATFORM_KEY_ID, claims);
+}
+
+// Act like a browser that was sent to the platform's auth URL:
+// get the launch form and return the form's target and values.
+func (this *TestPlatform) Authorize(authURL string) (string, url.Values, error) {
+    response, err := http.Get(authURL);
+    if (err != nil) {
+        return "", nil, fmt.Errorf("Failed to GET auth URL: '%w'.", err);
+    }
+    defer response.Body.Close();
+
+    body, err := io.ReadAll(response.Body);
+    if (err != nil) {
+        return "", nil, fmt.Errorf("Failed to read auth response: '%w'.", err);
+    }
+
+    if (response.StatusCode != http.StatusOK) {
+        return "", nil, fmt.Errorf("Auth request was rejected (%d): '%s'.", response.StatusCode, string(body));
+    }
+
+    match := testFormActionPattern.FindStringSubmatch(string(body));
+    if (match == nil) {
+        return "", nil, fmt.Errorf("Auth response does not have a form: '%s'.", string(body));
+    }
+
+    action := html.UnescapeString(match[1]);
+
+    form := url.Values{};
+    for _, input := range testFormInputPattern.FindAllStringSubmatch(string(body), -1) {
+        form.Set(html.UnescapeString(input[1]), html.UnescapeString(input[2]));
+    }
+
+    return action, form, nil;
+}
+
+func (this *TestPlatform) handleAuth(response http.ResponseWriter, request *http.Request) {
+    err := request.ParseForm();
+    if (err != nil) {
+        http.Error(response, "Bad form.", http.StatusBadRequest);
+        return;
+    }
+
+    params := request.Form;
+
+    if ((params.Get("scope") != "openid") || (params.Get("response_type") != "id_token") ||
+            (params.Get("response_mode") != "form_post") || (params.Get("prompt") != "none") ||
+            (params.Get("client_id") != this.ClientID) || (params.Get("nonce") == "")) {
+        http.Error(response, "Bad authorization request.", http.StatusBadRequest);
+        return;
+    }
+
+    this.lock.Lock();
+    launchUserID := this.launchUserID;
+    this.lock.Unlock();
+
+    if (params.Get("login_hint") != launchUserID) {
+        http.Error(response, "Bad login hint.", http.StatusBadRequest);
+        return;
+    }
+
+    claims := this.NewLaunchClaims(launchUserID, params.Get("nonce"));
+    if (claims == nil) {
+        http.Error(response, "Unknown user.", http.StatusBadRequest);
+        return;
+    }
+
+    idToken, err := this.SignLaunch(claims);
+    if (err != nil) {
+        log.Error("Test platform failed to sign launch.", err);
+        http.Error(response, "Failed to sign launch.", http.StatusInternalServerError);
+        return;
+    }
+
+    response.Header().Set("Content-Type", "text/html");
+    fmt.Fprintf(response, `<html><body onload="document.forms[0].submit()">`);
+    fmt.Fprintf(response, `<form method="post" action="%s">`, html.EscapeString(params.Get("redirect_uri")));
+    fmt.Fprintf(response, `<input type="hidden" name="id_token" value="%s" />`, html.EscapeString(idToken));
+    fmt.Fprintf(response, `<input type="hidden" name="state" value="%s" />`, html.EscapeString(params.Get("state")));
+    fmt.Fprintf(response, `</form></body></html>`);
+}
+
+func (this *TestPlatform) handleToken(response http.ResponseWriter, request *http.Request) {
+    err := request.ParseForm();
+    if (err != nil) {
+        http.Error(response, "Bad form.", http.StatusBadRequest);
+        return;
+    }
+
+    if ((request.PostForm.Get("grant_type") != "client_credentials") ||
+            (request.PostForm.Get("client_assertion_type") != CLIENT_ASSERTION_TYPE)) {
+        http.Error(response, "Bad grant.", http.StatusBadRequest);
+        return;
+    }
+
+    var assertion clientAssertion;
+    err = oidc.VerifyJWT(request.PostForm.Get("client_assertion"), this.getToolKey, &assertion);
+    if (err != nil) {
+        http.Error(response, "Bad client assertion.", http.StatusUnauthorized);
+        return;
+    }
+
+    if ((assertion.Issuer != this.ClientID) || (assertion.Subject != this.ClientID) ||
+            (assertion.Audience != (this.URL() + "/token")) || time.Now().After(time.Unix(assertion.Expiration, 0))) {
+        http.Error(response, "Bad client assertion claims.", http.StatusUnauthorized);
+        return;
+    }
+
+    token := util.UUID();
+
+    this.lock.Lock();
+    this.accessTokens[token] = true;
+    this.lock.Unlock();
+
+    tokens := tokenResponse{
+        AccessToken: token,
+        TokenType: "Bearer",
+        ExpiresIn: 3600,
+        Scope: request.PostForm.Get("scope"),
+    };
+
+    writeTestJSON(response, "application/json", tokens);
+}
+
+func (this *TestPlatform) handleKeys(response http.ResponseWriter, request *http.Request) {
+    keySet := oidc.JSONWebKeySet{
+        Keys: []*oidc.JSONWebKey{oidc.NewJSONWebKey(&this.key.PublicKey, TEST_PLATFORM_KEY_ID)},
+    };
+
+    writeTestJSON(response, "application/json", keySet);
+}
+
+func (this *TestPlatform) handleMemberships(response http.ResponseWriter, request *http.Request) {
+    if (!this.checkAccess(response, request)) {
+        return;
+    }
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    memberships := MembershipContainer{
+        ID: this.URL() + "/memberships",
+        Context: &ContextClaim{ID: this.ContextID},
+        Members: this.members,
+    };
+
+    writeTestJSON(response, CONTENT_TYPE_MEMBERSHIPS, memberships);
+}
+
+func (this *TestPlatform) handleLineItems(response http.ResponseWriter, request *http.Request) {
+    if (!this.checkAccess(response, request)) {
+        return;
+    }
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    writeTestJSON(response, CONTENT_TYPE_LINE_ITEMS, this.lineItems);
+}
+
+// Handles a single line item, and its results and scores.
+func (this *TestPlatform) handleLineItem(response http.ResponseWriter, request *http.Request) {
+    if (!this.checkAccess(response, request)) {
+        return;
+    }
+
+    path := request.URL.Path;
+    suffix := "";
+    for _, resource := range []string{"/results", "/scores"} {
+        if (strings.HasSuffix(path, resource)) {
+            suffix = resource;
+            path = strings.TrimSuffix(path, resource);
+        }
+    }
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    lineItemID := this.URL() + path;
+    index := slices.IndexFunc(this.lineItems, func(lineItem *LineItem) bool {
+        return (lineItem.ID == lineItemID);
+    });
+
+    if (index < 0) {
+        http.NotFound(response, request);
+        return;
+    }
+
+    lineItem := this.lineItems[index];
+
+    if ((suffix == "") && (request.Method == "GET")) {
+        writeTestJSON(response, CONTENT_TYPE_LINE_ITEM, lineItem);
+    } else if ((suffix == "/results") && (request.Method == "GET")) {
+        this.writeTestResults(response, request, lineItem);
+    } else if ((suffix == "/scores") && (request.Method == "POST")) {
+        this.saveTestScore(response, request, lineItem);
+    } else {
+        http.Error(response, "Unsupported method.", http.StatusMethodNotAllowed);
+    }
+}
+
+func (this *TestPlatform) writeTestResults(response http.ResponseWriter, request *http.Request, lineItem *LineItem) {
+    userID := request.URL.Query().Get("user_id");
+
+    userIDs := make([]string, 0, len(this.scores[lineItem.ID]));
+    for scoreUserID := range this.scores[lineItem.ID] {
+        if ((userID == "") || (userID == scoreUserID)) {
+            userIDs = append(userIDs, scoreUserID);
+        }
+    }
+    slices.Sort(userIDs);
+
+    results := make([]*Result, 0, len(userIDs));
+    for _, scoreUserID := range userIDs {
+        score := this.scores[lineItem.ID][scoreUserID];
+
+        results = append(results, &Result{
+            ID: lineItem.ID + "/results/" + scoreUserID,
+            ScoreOf: lineItem.ID,
+            UserID: scoreUserID,
+            ResultScore: score.ScoreGiven,
+            ResultMaximum: score.ScoreMaximum,
+            Comment: score.Comment,
+        });
+    }
+
+    writeTestJSON(response, CONTENT_TYPE_RESULTS, results);
+}
+
+func (this *TestPlatform) saveTestScore(response http.ResponseWriter, request *http.Request, lineItem *LineItem) {
+    if (request.Header.Get("Content-Type") != CONTENT_TYPE_SCORE) {
+        http.Error(response, "Bad content type.", http.StatusUnsupportedMediaType);
+        return;
+    }
+
+    body, err := io.ReadAll(request.Body);
+    if (err != nil) {
+        http.Error(response, "Failed to read body.", http.StatusBadRequest);
+        return;
+    }
+
+    var score Score;
+    err = util.JSONFromString(string(body), &score);
+    if ((err != nil) || (score.UserID == "") || (score.Timestamp == "")) {
+        http.Error(response, "Bad score.", http.StatusBadRequest);
+        return;
+    }
+
+    if ((score.ScoreGiven != nil) && (score.ScoreMaximum <= 0)) {
+        http.Error(response, "Score is missing a maximum.", http.StatusBadRequest);
+        return;
+    }
+
+    this.scores[lineItem.ID][score.UserID] = &score;
+
+    response.WriteHeader(http.StatusNoContent);
+}
+
+func (this *TestPlatform) checkAccess(response http.ResponseWriter, request *http.Request) bool {
+    token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ");
+
+    this.lock.Lock();
+    ok := this.accessTokens[token];
+    this.lock.Unlock();
+
+    if (!ok) {
+        http.Error(response, "Bad access token.", http.StatusUnauthorized);
+        return false;
+    }
+
+    return true;
+}
+
+// The platform is local, so it can use the tool's key directly (instead of fetching the tool's JWKS).
+func (this *TestPlatform) getToolKey(keyID string) (*rsa.PublicKey, error) {
+    key, toolKeyID, err := GetToolKey();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    if (keyID != toolKeyID) {
+        return nil, fmt.Errorf("Unknown tool key: '%s'.", keyID);
+    }
+
+    return &key.PublicKey, nil;
+}
+
+func writeTestJSON(response http.ResponseWriter, contentType string, data any) {
+    response.Header().Set("Content-Type", contentType);
+    fmt.Fprint(response, util.MustToJSON(data));
+}
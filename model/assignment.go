@@ -27,6 +27,10 @@ type Assignment struct {
 
     LMSID string `json:"lms-id,omitempty"`
     LatePolicy *LateGradingPolicy `json:"late-policy,omitempty"`
+    // Which submission is scored (defaults to the most recent submission).
+    ScoringStrategy ScoringStrategy `json:"scoring-strategy,omitempty"`
+    // If set, submissions are shared by all the members of a group (see Group).
+    GroupSubmissions bool `json:"group-submissions,omitempty"`
 
     SubmissionLimit *SubmissionLimitInfo `json:"submission-limit,omitempty"`
 
@@ -74,6 +78,14 @@ func (this *Assignment) GetLatePolicy() LateGradingPolicy {
     return *this.LatePolicy;
 }
 
+func (this *Assignment) GetScoringStrategy() ScoringStrategy {
+    if (this.ScoringStrategy == "") {
+        return LastSubmissionStrategy;
+    }
+
+    return this.ScoringStrategy;
+}
+
 func (this *Assignment) GetSubmissionLimit() *SubmissionLimitInfo {
     return this.SubmissionLimit;
 }
@@ -86,6 +98,12 @@ func (this *Assignment) GetImageInfo() *docker.ImageInfo {
     return &this.ImageInfo;
 }
 
+// Get the effective grading container limits (the assignment's limits with unset values taken from the course).
+func (this *Assignment) GetResourceLimits() *docker.ResourceLimits {
+    limits := this.ImageInfo.ResourceLimits.WithDefaults(this.Course.ResourceLimits);
+    return &limits;
+}
+
 func (this *Assignment) GetSourceDir() string {
     return filepath.Join(this.Course.GetBaseSourceDir(), this.RelSourceDir);
 }
@@ -140,6 +158,11 @@ func (this *Assignment) Validate() error {
         return fmt.Errorf("Failed to validate late policy: '%w'.", err);
     }
 
+    this.ScoringStrategy, err = this.ScoringStrategy.Validate();
+    if (err != nil) {
+        return err;
+    }
+
     if (this.RelSourceDir == "") {
         return fmt.Errorf("Relative source dir must not be empty.")
     }
@@ -4,10 +4,12 @@ import (
     "fmt"
     "path/filepath"
     "slices"
+    "strings"
 
     "github.com/edulinq/autograder/common"
     "github.com/edulinq/autograder/config"
     "github.com/edulinq/autograder/docker"
+    "github.com/edulinq/autograder/email"
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model/tasks"
 )
@@ -29,12 +31,26 @@ type Course struct {
     // A common submission limit that assignments can inherit.
     SubmissionLimit *SubmissionLimitInfo `json:"submission-limit,omitempty"`
 
+    // The maximum number of submissions for this course that can be graded at the same time.
+    // Zero means to use the server default (grader.course.workers).
+    GradingWorkers int `json:"grading-workers,omitempty"`
+
+    // Default grading container limits that assignments can override field by field.
+    ResourceLimits *docker.ResourceLimits `json:"resource-limits,omitempty"`
+
+    // Emails to send users about their submissions.
+    Notifications *NotificationOptions `json:"notifications,omitempty"`
+    // Overrides for the default email templates (keyed by template name).
+    EmailTemplates map[string]*email.Template `json:"email-templates,omitempty"`
+
     Backup []*tasks.BackupTask `json:"backup,omitempty"`
     CourseUpdate []*tasks.CourseUpdateTask `json:"course-update,omitempty"`
     Report []*tasks.ReportTask `json:"report,omitempty"`
     ScoringUpload []*tasks.ScoringUploadTask `json:"scoring-upload,omitempty"`
     EmailLogs []*tasks.EmailLogsTask `json:"email-logs,omitempty"`
 
+    Webhooks []*Webhook `json:"webhooks,omitempty"`
+
     // Internal fields the autograder will set.
     Assignments map[string]*Assignment `json:"-"`
     scheduledTasks []tasks.ScheduledTask `json:"-"`
@@ -60,6 +76,16 @@ func (this *Course) GetDisplayName() string {
     return this.ID;
 }
 
+// Get the maximum number of submissions for this course that can be graded at the same time.
+// Zero means there is no course-specific limit.
+func (this *Course) GetGradingWorkers() int {
+    if (this.GradingWorkers > 0) {
+        return this.GradingWorkers;
+    }
+
+    return max(0, config.GRADER_COURSE_WORKERS.Get());
+}
+
 func (this *Course) GetSource() *common.FileSpec {
     return this.Source;
 }
@@ -121,6 +147,43 @@ func (this *Course) Validate() error {
         }
     }
 
+    if (this.GradingWorkers < 0) {
+        return fmt.Errorf("Number of grading workers must be non-negative, found %d.", this.GradingWorkers);
+    }
+
+    if (this.ResourceLimits != nil) {
+        err = this.ResourceLimits.Validate();
+        if (err != nil) {
+            return fmt.Errorf("Failed to validate resource limits: '%w'.", err);
+        }
+    }
+
+    for i, webhook := range this.Webhooks {
+        if (webhook == nil) {
+            return fmt.Errorf("Webhook at index %d is empty.", i);
+        }
+
+        err = webhook.Validate();
+        if (err != nil) {
+            return fmt.Errorf("Failed to validate webhook: '%w'.", err);
+        }
+    }
+
+    for name, template := range this.EmailTemplates {
+        if (email.GetDefaultTemplate(name) == nil) {
+            return fmt.Errorf("Unknown email template '%s'. Known templates: [%s].", name, strings.Join(email.GetTemplateNames(), ", "));
+        }
+
+        if (template == nil) {
+            return fmt.Errorf("Email template '%s' is empty.", name);
+        }
+
+        err = template.Validate();
+        if (err != nil) {
+            return fmt.Errorf("Failed to validate email template '%s': '%w'.", name, err);
+        }
+    }
+
     // Register tasks.
     this.scheduledTasks = make([]tasks.ScheduledTask, 0);
 
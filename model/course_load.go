@@ -8,6 +8,7 @@ import (
 )
 
 const COURSE_CONFIG_FILENAME = "course.json"
+const ASSIGNMENTS_DIRNAME = "assignments"
 
 func LoadCourseFromPath(path string) (*Course, error) {
     course, err := ReadCourseConfig(path);
@@ -51,6 +52,89 @@ func FullLoadCourseFromPath(path string) (*Course, map[string]*User, []*GradingR
     return course, users, submissions, nil;
 }
 
+// Load a course (and its assignments) from already serialized JSON (e.g. from a database).
+// The assignments must have already been loaded once from disk (so they have a relative source dir).
+func LoadCourseFromJSON(courseJSON string, assignmentsJSON []string) (*Course, error) {
+    var course Course;
+    err := util.JSONFromString(courseJSON, &course);
+    if (err != nil) {
+        return nil, fmt.Errorf("Could not unmarshal course: '%w'.", err);
+    }
+
+    course.Assignments = make(map[string]*Assignment);
+
+    err = course.Validate();
+    if (err != nil) {
+        return nil, fmt.Errorf("Could not validate course '%s': '%w'.", course.GetID(), err);
+    }
+
+    for _, assignmentJSON := range assignmentsJSON {
+        var assignment Assignment;
+        err = util.JSONFromString(assignmentJSON, &assignment);
+        if (err != nil) {
+            return nil, fmt.Errorf("Could not unmarshal assignment for course '%s': '%w'.", course.GetID(), err);
+        }
+
+        assignment.Course = &course;
+
+        err = assignment.Validate();
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to validate assignment '%s': '%w'.", assignment.GetID(), err);
+        }
+
+        err = course.AddAssignment(&assignment);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to add assignment '%s' to course: '%w'.", assignment.GetID(), err);
+        }
+    }
+
+    return &course, nil;
+}
+
+// Write a course (with its assignments, users, and submissions) into the standard directory layout
+// (which can be read back with FullLoadCourseFromPath()).
+func FullWriteCourseToDir(course *Course, users map[string]*User, submissions []*GradingResult, baseDir string) error {
+    err := util.MkDir(baseDir);
+    if (err != nil) {
+        return fmt.Errorf("Failed to make course dir '%s': '%w'.", baseDir, err);
+    }
+
+    err = util.ToJSONFileIndent(course, filepath.Join(baseDir, COURSE_CONFIG_FILENAME));
+    if (err != nil) {
+        return fmt.Errorf("Failed to write course config: '%w'.", err);
+    }
+
+    for _, assignment := range course.Assignments {
+        assignmentDir := filepath.Join(baseDir, ASSIGNMENTS_DIRNAME, assignment.GetID());
+        err = util.MkDir(assignmentDir);
+        if (err != nil) {
+            return fmt.Errorf("Failed to make assignment dir '%s': '%w'.", assignmentDir, err);
+        }
+
+        err = util.ToJSONFileIndent(assignment, filepath.Join(assignmentDir, ASSIGNMENT_CONFIG_FILENAME));
+        if (err != nil) {
+            return fmt.Errorf("Failed to write assignment config '%s': '%w'.", assignment.FullID(), err);
+        }
+    }
+
+    err = util.ToJSONFileIndent(users, filepath.Join(baseDir, USERS_FILENAME));
+    if (err != nil) {
+        return fmt.Errorf("Failed to write users: '%w'.", err);
+    }
+
+    for _, submission := range submissions {
+        info := submission.Info;
+        submissionDir := filepath.Join(baseDir, SUBMISSIONS_DIRNAME, info.AssignmentID, info.User, info.ShortID);
+
+        err = WriteGradingResult(submission, submissionDir);
+        if (err != nil) {
+            return fmt.Errorf("Failed to write submission '%s': '%w'.", info.ID, err);
+        }
+    }
+
+    return nil;
+}
+
 // Load just the course config (and validate).
 // Do not load any assignments or other resources.
 func ReadCourseConfig(path string) (*Course, error) {
@@ -0,0 +1,59 @@
+package model
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/email"
+)
+
+// The data available to the user account templates
+// (email.TEMPLATE_USER_ADD and email.TEMPLATE_USER_PASSWORD_CHANGE).
+type UserEmailData struct {
+    CourseID string
+    CourseName string
+    Email string
+    // Empty unless the password was generated by the autograder.
+    Password string
+}
+
+// The data available to the password reset template (email.TEMPLATE_PASSWORD_RESET).
+type PasswordResetEmailData struct {
+    CourseID string
+    CourseName string
+    Email string
+    // The cleartext reset token.
+    Token string
+    TTLMinutes int
+}
+
+// Get the template with the given name, preferring the course's own template over the default.
+func (this *Course) GetEmailTemplate(name string) (*email.Template, error) {
+    template := this.EmailTemplates[name];
+    if (template != nil) {
+        return template, nil;
+    }
+
+    template = email.GetDefaultTemplate(name);
+    if (template == nil) {
+        return nil, fmt.Errorf("Unknown email template '%s'.", name);
+    }
+
+    return template, nil;
+}
+
+// Fill in one of this course's email templates.
+func (this *Course) RenderEmail(name string, to []string, data any) (*email.Message, error) {
+    template, err := this.GetEmailTemplate(name);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    message, err := template.Render(to, data);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to render email template '%s' for course '%s': '%w'.", name, this.GetID(), err);
+    }
+
+    message.CourseID = this.GetID();
+
+    return message, nil;
+}
@@ -0,0 +1,158 @@
+package model
+
+// Per-user due date extensions (e.g. for accommodations or illness).
+// An extension is either for a specific assignment, or for all assignments (an empty assignment ID).
+// When both exist for a user, the assignment-specific extension takes precedence for any field it sets.
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+const DAY = 24 * time.Hour;
+
+type Extension struct {
+    User string `json:"user"`
+    // An empty assignment ID means that the extension applies to all of the user's assignments.
+    AssignmentID string `json:"assignment-id,omitempty"`
+
+    // Replace the assignment's due date.
+    DueDate common.Timestamp `json:"due-date,omitempty"`
+    // Add this many days to the (possibly replaced) due date.
+    ExtraDays int `json:"extra-days,omitempty"`
+    // Stretch the length of a day when counting how late a submission is.
+    // E.g., with a multiplier of 1.5 a submission needs to be more than 36 hours late to be one day late.
+    // Zero means no multiplier.
+    TimeMultiplier float64 `json:"time-multiplier,omitempty"`
+
+    Reason string `json:"reason,omitempty"`
+    GrantedBy string `json:"granted-by,omitempty"`
+    GrantedTime common.Timestamp `json:"granted-time,omitempty"`
+}
+
+func (this *Extension) LogValue() []*log.Attr {
+    return []*log.Attr{
+        log.NewUserAttr(this.User),
+        log.NewAssignmentAttr(this.AssignmentID),
+    };
+}
+
+func (this *Extension) Validate() error {
+    if (this.User == "") {
+        return fmt.Errorf("Extension must have a user.");
+    }
+
+    if (this.AssignmentID != "") {
+        id, err := common.ValidateID(this.AssignmentID);
+        if (err != nil) {
+            return fmt.Errorf("Extension has an invalid assignment ID ('%s'): '%w'.", this.AssignmentID, err);
+        }
+
+        this.AssignmentID = id;
+    }
+
+    err := this.DueDate.Validate();
+    if (err != nil) {
+        return fmt.Errorf("Extension has an invalid due date: '%w'.", err);
+    }
+
+    if (!this.DueDate.IsZero() && (this.AssignmentID == "")) {
+        return fmt.Errorf("Extensions for all assignments cannot set a due date.");
+    }
+
+    if (this.ExtraDays < 0) {
+        return fmt.Errorf("Extension extra days cannot be negative, found %d.", this.ExtraDays);
+    }
+
+    if ((this.TimeMultiplier != 0.0) && (this.TimeMultiplier < 1.0)) {
+        return fmt.Errorf("Extension time multiplier must be at least 1.0 (or zero for no multiplier), found '%s'.",
+                util.FloatToStr(this.TimeMultiplier));
+    }
+
+    if (this.DueDate.IsZero() && (this.ExtraDays == 0) && (this.TimeMultiplier == 0.0)) {
+        return fmt.Errorf("Extension must set at least one of: due date, extra days, or time multiplier.");
+    }
+
+    return nil;
+}
+
+// Get the due date for a user with this extension.
+// A nil extension does not change the due date.
+func (this *Extension) GetDueDate(dueDate time.Time) time.Time {
+    if (this == nil) {
+        return dueDate;
+    }
+
+    if (!this.DueDate.IsZero()) {
+        extendedDueDate, err := this.DueDate.Time();
+        if (err != nil) {
+            log.Warn("Failed to parse extension due date, using original due date.", err, this);
+        } else {
+            dueDate = extendedDueDate;
+        }
+    }
+
+    return dueDate.Add(time.Duration(this.ExtraDays) * DAY);
+}
+
+// Get the length of a day (for counting late days) for a user with this extension.
+// A nil extension uses a normal day.
+func (this *Extension) GetDayLength() time.Duration {
+    if ((this == nil) || (this.TimeMultiplier == 0.0)) {
+        return DAY;
+    }
+
+    return time.Duration(float64(DAY) * this.TimeMultiplier);
+}
+
+// Get the effective extension for each user for an assignment.
+// Extensions for all assignments are combined with assignment-specific extensions,
+// where fields set in the assignment-specific extension take precedence.
+// Users without any extensions will not be in the result.
+func ResolveExtensions(extensions []*Extension, assignmentID string) map[string]*Extension {
+    global := make(map[string]*Extension);
+    specific := make(map[string]*Extension);
+
+    for _, extension := range extensions {
+        if (extension.AssignmentID == "") {
+            global[extension.User] = extension;
+        } else if (extension.AssignmentID == assignmentID) {
+            specific[extension.User] = extension;
+        }
+    }
+
+    result := make(map[string]*Extension, len(global) + len(specific));
+
+    for email, extension := range global {
+        resolved := *extension;
+        resolved.AssignmentID = assignmentID;
+        result[email] = &resolved;
+    }
+
+    for email, extension := range specific {
+        resolved, ok := result[email];
+        if (!ok) {
+            result[email] = extension;
+            continue;
+        }
+
+        resolved.DueDate = extension.DueDate;
+        resolved.Reason = extension.Reason;
+        resolved.GrantedBy = extension.GrantedBy;
+        resolved.GrantedTime = extension.GrantedTime;
+
+        if (extension.ExtraDays != 0) {
+            resolved.ExtraDays = extension.ExtraDays;
+        }
+
+        if (extension.TimeMultiplier != 0.0) {
+            resolved.TimeMultiplier = extension.TimeMultiplier;
+        }
+    }
+
+    return result;
+}
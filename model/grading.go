@@ -24,6 +24,8 @@ type GradingInfo struct {
     AssignmentID string `json:"assignment-id"`
     User string `json:"user"`
     Message string `json:"message"`
+    // If this submission is a regrade, then the full ID of the submission that was regraded.
+    RegradeOf string `json:"regrade-of,omitempty"`
     MaxPoints float64 `json:"max_points"`
     Score float64 `json:"score"`
 
@@ -35,6 +37,10 @@ type GradingInfo struct {
     Prologue string `json:"prologue,omitempty"`
     Epilogue string `json:"epilogue,omitempty"`
 
+    // Set when grading was stopped early because the grader hit a resource limit
+    // (see docker.LIMIT_KIND_* for the possible values).
+    FailureReason string `json:"failure-reason,omitempty"`
+
     // Additional pass-through information that the grader can use.
     AdditionalInfo map[string]any `json:"additional-info"`
 }
@@ -101,6 +107,10 @@ func (this GradingInfo) Report() string {
     builder.WriteString(fmt.Sprintf("Autograder transcript for assignment: %s.\n", this.Name));
     builder.WriteString(fmt.Sprintf("Grading started at %s and ended at %s.\n", this.GradingStartTime, this.GradingEndTime));
 
+    if (this.FailureReason != "") {
+        builder.WriteString(fmt.Sprintf("Grading failed (%s): %s\n", this.FailureReason, this.Prologue));
+    }
+
     totalScore := 0.0;
     maxScore := 0.0;
 
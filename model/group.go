@@ -0,0 +1,83 @@
+package model
+
+// Groups (teams) of users that submit together.
+// On assignments with group submissions enabled (see Assignment.GroupSubmissions),
+// a submission from any member counts for every member of their group:
+// the submission history (and therefore submission limits and scores) is shared by the whole group.
+// A user can be in at most one group in a course.
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/log"
+)
+
+type Group struct {
+    ID string `json:"id"`
+    Name string `json:"name,omitempty"`
+    // Member emails (sorted).
+    Members []string `json:"members"`
+
+    // If this group was imported from the LMS, the LMS ID of the group.
+    LMSID string `json:"lms-id,omitempty"`
+}
+
+func (this *Group) LogValue() []*log.Attr {
+    return []*log.Attr{
+        log.NewAttr("group", this.ID),
+    };
+}
+
+func (this *Group) Validate() error {
+    id, err := common.ValidateID(this.ID);
+    if (err != nil) {
+        return fmt.Errorf("Group has an invalid ID ('%s'): '%w'.", this.ID, err);
+    }
+
+    this.ID = id;
+
+    if (len(this.Members) == 0) {
+        return fmt.Errorf("Group '%s' must have at least one member.", this.ID);
+    }
+
+    for i, member := range this.Members {
+        member = strings.TrimSpace(member);
+        if (member == "") {
+            return fmt.Errorf("Group '%s' has an empty member.", this.ID);
+        }
+
+        this.Members[i] = member;
+    }
+
+    slices.Sort(this.Members);
+    if (len(slices.Compact(slices.Clone(this.Members))) != len(this.Members)) {
+        return fmt.Errorf("Group '%s' has duplicate members.", this.ID);
+    }
+
+    return nil;
+}
+
+func (this *Group) HasMember(email string) bool {
+    return slices.Contains(this.Members, email);
+}
+
+// Ensure that no user is in more than one of the given groups.
+func ValidateGroupMembership(groups map[string]*Group) error {
+    memberships := make(map[string]string);
+
+    for _, group := range groups {
+        for _, member := range group.Members {
+            otherGroup, ok := memberships[member];
+            if (ok) {
+                return fmt.Errorf("User '%s' is in multiple groups ('%s' and '%s').", member, otherGroup, group.ID);
+            }
+
+            memberships[member] = group.ID;
+        }
+    }
+
+    return nil;
+}
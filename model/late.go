@@ -25,7 +25,13 @@ type LateGradingPolicy struct {
     RejectAfterDays int `json:"reject-after-days,omitempty"`
 
     MaxLateDays int `json:"max-late-days,omitempty"`
+    // The number of late days each student starts with.
+    TotalLateDays int `json:"total-late-days,omitempty"`
+    // If set, late day balances will be imported from (for students without a balance) and mirrored to this LMS assignment.
     LateDaysLMSID string `json:"late-days-lms-id,omitempty"`
+    // If set, students choose how many late days to spend on each assignment (see LateDaysBalance.Select)
+    // instead of late days being used automatically.
+    StudentSelectedLateDays bool `json:"student-selected-late-days,omitempty"`
 }
 
 func (this *LateGradingPolicy) Validate() error {
@@ -55,8 +61,12 @@ func (this *LateGradingPolicy) Validate() error {
                 return fmt.Errorf("Policy '%s': max late days must be in [1, <reject days>(%d)], found '%d'.", this.Type, this.RejectAfterDays, this.MaxLateDays);
             }
 
-            if (this.LateDaysLMSID == "") {
-                return fmt.Errorf("Policy '%s': LMS ID for late days assignment cannot be empty.", this.Type);
+            if (this.TotalLateDays < 0) {
+                return fmt.Errorf("Policy '%s': total late days cannot be negative, found '%d'.", this.Type, this.TotalLateDays);
+            }
+
+            if ((this.TotalLateDays == 0) && (this.LateDaysLMSID == "")) {
+                return fmt.Errorf("Policy '%s': either total late days or an LMS ID for a late days assignment must be set.", this.Type);
             }
         default:
             return fmt.Errorf("Unknown late policy type: '%s'.", this.Type);
@@ -0,0 +1,148 @@
+package model
+
+// Late day balances.
+// Each user (in a course with a late days policy) has a balance of available late days
+// and a record of how many days have been allocated to each assignment.
+// Every change to a balance is recorded as an event, so the full history of a balance can be audited.
+
+import (
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/log"
+)
+
+const (
+    // The balance was created from the course's late days policy.
+    LATE_DAYS_SOURCE_INITIAL = "initial"
+    // The balance was imported from the LMS (see LateGradingPolicy.LateDaysLMSID).
+    LATE_DAYS_SOURCE_LMS = "lms"
+    // Late days were allocated (or reclaimed) while scoring an assignment.
+    LATE_DAYS_SOURCE_SCORING = "scoring"
+    // The balance was manually adjusted.
+    LATE_DAYS_SOURCE_ADJUSTMENT = "adjustment"
+    // The user selected how many late days to spend on an assignment.
+    LATE_DAYS_SOURCE_SELECTION = "selection"
+)
+
+type LateDaysBalance struct {
+    User string `json:"user"`
+    AvailableDays int `json:"available-days"`
+    // The number of late days used on each assignment (keyed by assignment ID).
+    AllocatedDays map[string]int `json:"allocated-days"`
+    // The number of late days the user has chosen to spend on each assignment (keyed by assignment ID).
+    // Only used when the late policy has student-selected late days.
+    SelectedDays map[string]int `json:"selected-days,omitempty"`
+    UpdateTime common.Timestamp `json:"update-time"`
+}
+
+type LateDaysEvent struct {
+    User string `json:"user"`
+    Time common.Timestamp `json:"time"`
+    Source string `json:"source"`
+    // Empty for events that are not about a specific assignment.
+    AssignmentID string `json:"assignment-id,omitempty"`
+
+    // The change in available days (negative when days are used).
+    Change int `json:"change"`
+    // The available days after this event.
+    AvailableDays int `json:"available-days"`
+    // The days allocated to the assignment after this event.
+    AllocatedDays int `json:"allocated-days,omitempty"`
+    // The days selected for the assignment after this event.
+    SelectedDays int `json:"selected-days,omitempty"`
+
+    // The user that caused this event (empty for automatic events).
+    Actor string `json:"actor,omitempty"`
+    Reason string `json:"reason,omitempty"`
+}
+
+func NewLateDaysBalance(email string, availableDays int) *LateDaysBalance {
+    return &LateDaysBalance{
+        User: email,
+        AvailableDays: availableDays,
+        AllocatedDays: make(map[string]int),
+        UpdateTime: common.NowTimestamp(),
+    };
+}
+
+func (this *LateDaysBalance) LogValue() []*log.Attr {
+    return []*log.Attr{
+        log.NewUserAttr(this.User),
+        log.NewAttr("available-days", this.AvailableDays),
+    };
+}
+
+// Set the number of days allocated to an assignment (adjusting the available days)
+// and return the event that describes the change.
+// Returns nil if nothing changed.
+func (this *LateDaysBalance) Allocate(assignmentID string, days int, source string, actor string) *LateDaysEvent {
+    oldDays := this.AllocatedDays[assignmentID];
+    if (oldDays == days) {
+        return nil;
+    }
+
+    change := oldDays - days;
+    this.AvailableDays += change;
+
+    if (days == 0) {
+        delete(this.AllocatedDays, assignmentID);
+    } else {
+        this.AllocatedDays[assignmentID] = days;
+    }
+
+    this.UpdateTime = common.NowTimestamp();
+
+    return &LateDaysEvent{
+        User: this.User,
+        Time: this.UpdateTime,
+        Source: source,
+        AssignmentID: assignmentID,
+        Change: change,
+        AvailableDays: this.AvailableDays,
+        AllocatedDays: days,
+        Actor: actor,
+    };
+}
+
+// Add (or remove, if negative) available days and return the event that describes the change.
+func (this *LateDaysBalance) Adjust(change int, source string, actor string, reason string) *LateDaysEvent {
+    this.AvailableDays += change;
+    this.UpdateTime = common.NowTimestamp();
+
+    return &LateDaysEvent{
+        User: this.User,
+        Time: this.UpdateTime,
+        Source: source,
+        Change: change,
+        AvailableDays: this.AvailableDays,
+        Actor: actor,
+        Reason: reason,
+    };
+}
+
+// Set the number of days the user has chosen to spend on an assignment and return the event that describes the change.
+// Selecting days does not change the available days, days are only allocated when the assignment is scored.
+// Returns nil if nothing changed.
+func (this *LateDaysBalance) Select(assignmentID string, days int, actor string) *LateDaysEvent {
+    if (this.SelectedDays == nil) {
+        this.SelectedDays = make(map[string]int);
+    }
+
+    oldDays, ok := this.SelectedDays[assignmentID];
+    if (ok && (oldDays == days)) {
+        return nil;
+    }
+
+    this.SelectedDays[assignmentID] = days;
+    this.UpdateTime = common.NowTimestamp();
+
+    return &LateDaysEvent{
+        User: this.User,
+        Time: this.UpdateTime,
+        Source: LATE_DAYS_SOURCE_SELECTION,
+        AssignmentID: assignmentID,
+        AvailableDays: this.AvailableDays,
+        AllocatedDays: this.AllocatedDays[assignmentID],
+        SelectedDays: days,
+        Actor: actor,
+    };
+}
@@ -7,6 +7,8 @@ import (
 
 const (
     LMS_TYPE_CANVAS = "canvas"
+    LMS_TYPE_LTI = "lti"
+    LMS_TYPE_MOODLE = "moodle"
     LMS_TYPE_TEST = "test"
 )
 
@@ -18,6 +20,11 @@ type LMSAdapter struct {
     APIToken string `json:"api-token,omitempty"`
     BaseURL string `json:"base-url,omitempty"`
 
+    // Set when this server is registered as an LTI tool with the LMS.
+    // This enables LTI launches for any LMS type,
+    // and is required for the "lti" type (which uses LTI services instead of an API token).
+    LTI *LTIRegistration `json:"lti,omitempty"`
+
     // Behavior options.
 
     SyncUserAttributes bool `json:"sync-user-attributes,omitempty"`
@@ -25,6 +32,7 @@ type LMSAdapter struct {
     SyncUserRemoves bool `json:"sync-user-removes,omitempty"`
 
     SyncAssignments bool `json:"sync-assignments,omitempty"`
+    SyncGroups bool `json:"sync-groups,omitempty"`
 }
 
 func (this *LMSAdapter) Validate() error {
@@ -33,5 +41,16 @@ func (this *LMSAdapter) Validate() error {
     }
     this.Type = strings.ToLower(this.Type);
 
+    if ((this.Type == LMS_TYPE_LTI) && (this.LTI == nil)) {
+        return fmt.Errorf("LMS type '%s' requires an LTI registration (lti).", LMS_TYPE_LTI);
+    }
+
+    if (this.LTI != nil) {
+        err := this.LTI.Validate();
+        if (err != nil) {
+            return fmt.Errorf("Invalid LTI registration: '%w'.", err);
+        }
+    }
+
     return nil;
 }
@@ -0,0 +1,60 @@
+package model
+
+import (
+    "fmt"
+    "strings"
+)
+
+// The registration of this server as an LTI 1.3 tool with an LMS (the LTI "platform").
+// All the URLs are provided by the platform when the tool is registered.
+type LTIRegistration struct {
+    // The platform's issuer ("iss" claim of launches).
+    Issuer string `json:"issuer"`
+    // The client ID the platform assigned to this tool.
+    ClientID string `json:"client-id"`
+    // The deployment of the tool this course uses.
+    // When empty, launches from any deployment are accepted.
+    DeploymentID string `json:"deployment-id,omitempty"`
+
+    // Where launches (OIDC logins) are sent to be authorized.
+    AuthLoginURL string `json:"auth-login-url"`
+    // Where service (AGS/NRPS) access tokens are requested.
+    AuthTokenURL string `json:"auth-token-url,omitempty"`
+    // The platform's public keys.
+    KeySetURL string `json:"key-set-url"`
+
+    // The LMS context (course) this course is linked to.
+    // When empty, launches from any context of the deployment are accepted.
+    ContextID string `json:"context-id,omitempty"`
+
+    // Assignment and Grade Services (AGS) line items (gradebook columns) for the context.
+    LineItemsURL string `json:"line-items-url,omitempty"`
+    // Names and Role Provisioning Services (NRPS) memberships for the context.
+    MembershipsURL string `json:"memberships-url,omitempty"`
+}
+
+func (this *LTIRegistration) Validate() error {
+    this.Issuer = strings.TrimSuffix(strings.TrimSpace(this.Issuer), "/");
+    if (this.Issuer == "") {
+        return fmt.Errorf("LTI issuer cannot be empty.");
+    }
+
+    if (this.ClientID == "") {
+        return fmt.Errorf("LTI client ID cannot be empty.");
+    }
+
+    if (this.AuthLoginURL == "") {
+        return fmt.Errorf("LTI auth login URL cannot be empty.");
+    }
+
+    if (this.KeySetURL == "") {
+        return fmt.Errorf("LTI key set URL cannot be empty.");
+    }
+
+    return nil;
+}
+
+// Does this registration use the given platform and client.
+func (this *LTIRegistration) Matches(issuer string, clientID string) bool {
+    return ((this.Issuer == strings.TrimSuffix(issuer, "/")) && (this.ClientID == clientID));
+}
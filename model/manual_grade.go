@@ -0,0 +1,73 @@
+package model
+
+// Manual (hand) grading that is layered on top of the autograder's results for a submission,
+// e.g., for code style or a written report.
+// Manual grades are stored separately from the raw grading results,
+// and the score of a submission is the autograder score plus the points from its manual grade.
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/edulinq/autograder/common"
+)
+
+type ManualGradeItem struct {
+    // The rubric item (or a short description of the adjustment).
+    Name string `json:"name"`
+    // Points added to the autograder score (negative points are a deduction).
+    Points float64 `json:"points"`
+    Comment string `json:"comment,omitempty"`
+}
+
+type ManualGrade struct {
+    // The full ID of the submission this grade is for.
+    SubmissionID string `json:"submission-id"`
+    Items []*ManualGradeItem `json:"items"`
+    Comment string `json:"comment,omitempty"`
+
+    GradedBy string `json:"graded-by,omitempty"`
+    GradedTime common.Timestamp `json:"graded-time"`
+}
+
+func (this *ManualGrade) Validate() error {
+    if (this.SubmissionID == "") {
+        return fmt.Errorf("Manual grade must have a submission ID.");
+    }
+
+    if (this.Items == nil) {
+        this.Items = make([]*ManualGradeItem, 0);
+    }
+
+    for i, item := range this.Items {
+        if (item == nil) {
+            return fmt.Errorf("Manual grade item %d is empty.", i);
+        }
+
+        item.Name = strings.TrimSpace(item.Name);
+        if (item.Name == "") {
+            return fmt.Errorf("Manual grade item %d must have a name.", i);
+        }
+    }
+
+    if ((len(this.Items) == 0) && (strings.TrimSpace(this.Comment) == "")) {
+        return fmt.Errorf("Manual grade must have at least one item or a comment.");
+    }
+
+    return nil;
+}
+
+// The total points from all items.
+// A nil grade has no points.
+func (this *ManualGrade) Points() float64 {
+    if (this == nil) {
+        return 0.0;
+    }
+
+    points := 0.0;
+    for _, item := range this.Items {
+        points += item.Points;
+    }
+
+    return points;
+}
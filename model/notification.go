@@ -0,0 +1,22 @@
+package model
+
+// Emails that are sent to users about their own submissions.
+// All notifications are off unless a course turns them on.
+
+type NotificationOptions struct {
+    // Send a receipt (with the submission ID and score) after a submission is graded.
+    SubmissionReceipts bool `json:"submission-receipts,omitempty"`
+    // Send a notice (with the reason) when a submission is rejected.
+    Rejections bool `json:"rejections,omitempty"`
+    // Send a notice when someone else removes a submission.
+    Removals bool `json:"removals,omitempty"`
+}
+
+// Get the notification options for this course (all off when the course does not have any).
+func (this *Course) GetNotifications() NotificationOptions {
+    if (this.Notifications == nil) {
+        return NotificationOptions{};
+    }
+
+    return *this.Notifications;
+}
@@ -0,0 +1,86 @@
+package model
+
+// Password resets let a user that forgot their password set a new one.
+// A reset token is emailed to the user, and the token can then be used (once) to set a new password.
+// Like API tokens, only a hash of the reset token is stored.
+
+import (
+    "crypto/subtle"
+    "fmt"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/util"
+)
+
+const PASSWORD_RESET_TOKEN_LEN = 32;
+
+type PasswordResetToken struct {
+    Hash string `json:"hash"`
+    CreationTime common.Timestamp `json:"creation-time"`
+    ExpirationTime common.Timestamp `json:"expiration-time"`
+}
+
+// Create a new reset token for this user and return the cleartext token.
+// Any existing reset token is replaced (so only the most recent token can be used).
+// The caller is responsible for saving the user.
+func (this *User) NewPasswordResetToken(lifetime time.Duration) (string, error) {
+    secret, err := util.RandHex(PASSWORD_RESET_TOKEN_LEN);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to generate password reset token: '%w'.", err);
+    }
+
+    now := time.Now();
+
+    this.PasswordReset = &PasswordResetToken{
+        Hash: util.Sha256HexFromString(secret),
+        CreationTime: common.TimestampFromTime(now),
+        ExpirationTime: common.TimestampFromTime(now.Add(lifetime)),
+    };
+
+    return secret, nil;
+}
+
+// Check if the given cleartext token matches this user's (unexpired) reset token.
+func (this *User) CheckPasswordResetToken(secret string) bool {
+    if ((secret == "") || (this.PasswordReset == nil)) {
+        return false;
+    }
+
+    hash := util.Sha256HexFromString(secret);
+    if (subtle.ConstantTimeCompare([]byte(hash), []byte(this.PasswordReset.Hash)) != 1) {
+        return false;
+    }
+
+    return !this.PasswordReset.IsExpired();
+}
+
+// Set a new password using a reset token.
+// A valid token is used up, so each token can only be used once.
+// Returns false if the token was not valid (in which case nothing is changed).
+// The caller is responsible for saving the user.
+func (this *User) ResetPassword(secret string, hashPass string) (bool, error) {
+    valid := this.CheckPasswordResetToken(secret);
+    if (!valid) {
+        return false, nil;
+    }
+
+    this.PasswordReset = nil;
+
+    err := this.SetPassword(hashPass);
+    if (err != nil) {
+        return false, err;
+    }
+
+    return true, nil;
+}
+
+func (this *PasswordResetToken) IsExpired() bool {
+    expiration, err := this.ExpirationTime.Time();
+    if (err != nil) {
+        // A token with a bad expiration time should never be accepted.
+        return true;
+    }
+
+    return !time.Now().Before(expiration);
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestUserPasswordReset(test *testing.T) {
+    user := &User{};
+
+    oldSecret, err := user.NewPasswordResetToken(time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create reset token: '%v'.", err);
+    }
+
+    secret, err := user.NewPasswordResetToken(time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create reset token: '%v'.", err);
+    }
+
+    if (user.PasswordReset.Hash == secret) {
+        test.Fatalf("Reset token is stored in cleartext.");
+    }
+
+    // Only the newest token can be used.
+    for _, bad := range []string{"", "Z", oldSecret, secret + "Z", user.PasswordReset.Hash} {
+        reset, err := user.ResetPassword(bad, util.Sha256HexFromString("new"));
+        if (err != nil) {
+            test.Fatalf("Failed to reset password with bad token '%s': '%v'.", bad, err);
+        }
+
+        if (reset) {
+            test.Fatalf("Bad token '%s' reset the password.", bad);
+        }
+    }
+
+    reset, err := user.ResetPassword(secret, util.Sha256HexFromString("new"));
+    if (err != nil) {
+        test.Fatalf("Failed to reset password: '%v'.", err);
+    }
+
+    if (!reset) {
+        test.Fatalf("Valid token did not reset the password.");
+    }
+
+    if (!user.CheckPassword(util.Sha256HexFromString("new"))) {
+        test.Fatalf("Password was not changed.");
+    }
+
+    // Tokens are single-use.
+    reset, err = user.ResetPassword(secret, util.Sha256HexFromString("other"));
+    if (err != nil) {
+        test.Fatalf("Failed to reuse reset token: '%v'.", err);
+    }
+
+    if (reset) {
+        test.Fatalf("Reset token was used twice.");
+    }
+
+    // Expired tokens do not work.
+    secret, err = user.NewPasswordResetToken(time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create reset token: '%v'.", err);
+    }
+
+    user.PasswordReset.ExpirationTime = common.TimestampFromTime(time.Now().Add(-time.Minute));
+
+    if (user.CheckPasswordResetToken(secret)) {
+        test.Fatalf("Expired reset token checks.");
+    }
+}
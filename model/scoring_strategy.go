@@ -0,0 +1,117 @@
+package model
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/edulinq/autograder/log"
+)
+
+// How to choose which of a user's submissions is used for their score.
+type ScoringStrategy string;
+
+const (
+    // Use the most recent submission.
+    LastSubmissionStrategy               ScoringStrategy = "last"
+    // Use the submission with the highest score.
+    BestSubmissionStrategy               ScoringStrategy = "best"
+    // Use the submission with the highest score that was made before the user's due date.
+    // If there are no submissions before the due date, then the highest scoring submission is used.
+    BestBeforeDeadlineSubmissionStrategy ScoringStrategy = "best-before-deadline"
+    // Use the submission the user selected (falling back to the most recent submission).
+    StudentSelectedSubmissionStrategy    ScoringStrategy = "student-selected"
+)
+
+func (this ScoringStrategy) Validate() (ScoringStrategy, error) {
+    strategy := ScoringStrategy(strings.ToLower(string(this)));
+
+    switch strategy {
+        case "", LastSubmissionStrategy, BestSubmissionStrategy, BestBeforeDeadlineSubmissionStrategy, StudentSelectedSubmissionStrategy:
+            return strategy, nil;
+        default:
+            return strategy, fmt.Errorf("Unknown scoring strategy: '%s'.", this);
+    }
+}
+
+// Choose the submission (from a user's submission history) that should be scored.
+// The deadline is only used for BestBeforeDeadlineSubmissionStrategy (nil means there is no deadline),
+// and the selected (short) submission ID is only used for StudentSelectedSubmissionStrategy.
+// Ties go to the more recent submission.
+// Returns nil if there are no submissions.
+func ChooseScoredSubmission(strategy ScoringStrategy, history []*SubmissionHistoryItem, deadline *time.Time, selectedID string) *SubmissionHistoryItem {
+    var last *SubmissionHistoryItem = nil;
+    var best *SubmissionHistoryItem = nil;
+    var bestBeforeDeadline *SubmissionHistoryItem = nil;
+    var selected *SubmissionHistoryItem = nil;
+
+    for _, item := range history {
+        if ((selectedID != "") && (item.ShortID == selectedID)) {
+            selected = item;
+        }
+
+        if ((last == nil) || isSameOrLaterSubmission(item, last)) {
+            last = item;
+        }
+
+        if (isBetterSubmission(item, best)) {
+            best = item;
+        }
+
+        if ((deadline != nil) && isBeforeDeadline(item, *deadline) && isBetterSubmission(item, bestBeforeDeadline)) {
+            bestBeforeDeadline = item;
+        }
+    }
+
+    switch strategy {
+        case BestSubmissionStrategy:
+            return best;
+        case BestBeforeDeadlineSubmissionStrategy:
+            if (bestBeforeDeadline != nil) {
+                return bestBeforeDeadline;
+            }
+
+            return best;
+        case StudentSelectedSubmissionStrategy:
+            if (selected != nil) {
+                return selected;
+            }
+
+            return last;
+        default:
+            return last;
+    }
+}
+
+func isBetterSubmission(item *SubmissionHistoryItem, current *SubmissionHistoryItem) bool {
+    if (current == nil) {
+        return true;
+    }
+
+    if (item.Score != current.Score) {
+        return (item.Score > current.Score);
+    }
+
+    return isSameOrLaterSubmission(item, current);
+}
+
+func isSameOrLaterSubmission(item *SubmissionHistoryItem, other *SubmissionHistoryItem) bool {
+    itemTime, itemErr := item.GradingStartTime.Time();
+    otherTime, otherErr := other.GradingStartTime.Time();
+    if ((itemErr != nil) || (otherErr != nil)) {
+        return (item.GradingStartTime >= other.GradingStartTime);
+    }
+
+    return !itemTime.Before(otherTime);
+}
+
+func isBeforeDeadline(item *SubmissionHistoryItem, deadline time.Time) bool {
+    submissionTime, err := item.GradingStartTime.Time();
+    if (err != nil) {
+        log.Warn("Failed to parse submission time, submission will be treated as late.", err,
+                log.NewUserAttr(item.User), log.NewAttr("submission", item.ShortID));
+        return false;
+    }
+
+    return !submissionTime.After(deadline);
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+)
+
+func TestChooseScoredSubmission(test *testing.T) {
+    // Submissions are (not in order): an early good submission, a late best submission, and a broken last submission.
+    history := []*SubmissionHistoryItem{
+        &SubmissionHistoryItem{ShortID: "2", Score: 3.0, GradingStartTime: common.MustTimestampFromString("2024-01-02T00:00:00Z")},
+        &SubmissionHistoryItem{ShortID: "1", Score: 2.0, GradingStartTime: common.MustTimestampFromString("2024-01-01T00:00:00Z")},
+        &SubmissionHistoryItem{ShortID: "3", Score: 0.0, GradingStartTime: common.MustTimestampFromString("2024-01-03T00:00:00Z")},
+    };
+
+    deadline := common.MustTimestampFromString("2024-01-01T12:00:00Z").MustTime();
+    earlyDeadline := deadline.Add(-2 * DAY);
+
+    testCases := []struct{ strategy ScoringStrategy; deadline *time.Time; selectedID string; expected string }{
+        {"", nil, "", "3"},
+        {LastSubmissionStrategy, &deadline, "1", "3"},
+
+        {BestSubmissionStrategy, nil, "", "2"},
+
+        {BestBeforeDeadlineSubmissionStrategy, &deadline, "", "1"},
+        {BestBeforeDeadlineSubmissionStrategy, nil, "", "2"},
+        // No submissions before the deadline.
+        {BestBeforeDeadlineSubmissionStrategy, &earlyDeadline, "", "2"},
+
+        {StudentSelectedSubmissionStrategy, nil, "1", "1"},
+        {StudentSelectedSubmissionStrategy, nil, "", "3"},
+        {StudentSelectedSubmissionStrategy, nil, "ZZZ", "3"},
+    };
+
+    for i, testCase := range testCases {
+        result := ChooseScoredSubmission(testCase.strategy, history, testCase.deadline, testCase.selectedID);
+        if (result == nil) {
+            test.Errorf("Case %d: Got a nil submission.", i);
+            continue;
+        }
+
+        if (result.ShortID != testCase.expected) {
+            test.Errorf("Case %d: Unexpected submission. Expected: '%s', Actual: '%s'.", i, testCase.expected, result.ShortID);
+            continue;
+        }
+    }
+
+    if (ChooseScoredSubmission(BestSubmissionStrategy, nil, nil, "") != nil) {
+        test.Errorf("Got a submission from an empty history.");
+    }
+}
+
+func TestScoringStrategyValidate(test *testing.T) {
+    testCases := []struct{ strategy ScoringStrategy; expected ScoringStrategy; isError bool }{
+        {"", "", false},
+        {"last", LastSubmissionStrategy, false},
+        {"BEST", BestSubmissionStrategy, false},
+        {"best-before-deadline", BestBeforeDeadlineSubmissionStrategy, false},
+        {"student-selected", StudentSelectedSubmissionStrategy, false},
+        {"zzz", "", true},
+    };
+
+    for i, testCase := range testCases {
+        result, err := testCase.strategy.Validate();
+        if (testCase.isError) {
+            if (err == nil) {
+                test.Errorf("Case %d: Did not get an expected error.", i);
+            }
+
+            continue;
+        }
+
+        if (err != nil) {
+            test.Errorf("Case %d: Got an unexpected error: '%v'.", i, err);
+            continue;
+        }
+
+        if (result != testCase.expected) {
+            test.Errorf("Case %d: Unexpected strategy. Expected: '%s', Actual: '%s'.", i, testCase.expected, result);
+            continue;
+        }
+    }
+}
@@ -19,6 +19,8 @@ type SubmissionHistoryItem struct {
     MaxPoints float64 `json:"max_points"`
     Score float64 `json:"score"`
     GradingStartTime common.Timestamp `json:"grading_start_time"`
+    // The ID of the submission this submission regraded (empty if this is not a regrade).
+    RegradeOf string `json:"regrade-of,omitempty"`
 }
 
 func (this GradingInfo) ToHistoryItem() *SubmissionHistoryItem {
@@ -32,5 +34,6 @@ func (this GradingInfo) ToHistoryItem() *SubmissionHistoryItem {
         MaxPoints: this.MaxPoints,
         Score: this.Score,
         GradingStartTime: this.GradingStartTime,
+        RegradeOf: this.RegradeOf,
     };
 }
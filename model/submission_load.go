@@ -70,13 +70,83 @@ func LoadGradingResult(resultPath string) (*GradingResult, error) {
         return nil, fmt.Errorf("Unable to gzip files in submission output dir '%s': '%w'.", submissionOutputDir, err);
     }
 
+    // Stdout and stderr are optional (older submissions may not have them).
+    stdout, err := readOptionalFile(filepath.Join(baseSubmissionDir, common.SUBMISSION_STDOUT_FILENAME));
+    if (err != nil) {
+        return nil, fmt.Errorf("Unable to read submission stdout: '%w'.", err);
+    }
+
+    stderr, err := readOptionalFile(filepath.Join(baseSubmissionDir, common.SUBMISSION_STDERR_FILENAME));
+    if (err != nil) {
+        return nil, fmt.Errorf("Unable to read submission stderr: '%w'.", err);
+    }
+
     return &GradingResult{
         Info: &gradingInfo,
         InputFilesGZip: inputFileContents,
         OutputFilesGZip: outputFileContents,
+        Stdout: stdout,
+        Stderr: stderr,
     }, nil;
 }
 
+func readOptionalFile(path string) (string, error) {
+    if (!util.PathExists(path)) {
+        return "", nil;
+    }
+
+    return util.ReadFile(path);
+}
+
+// Write a full standard grading result into a submission dir.
+// Complements LoadGradingResult().
+func WriteGradingResult(result *GradingResult, baseSubmissionDir string) error {
+    err := util.MkDir(baseSubmissionDir);
+    if (err != nil) {
+        return fmt.Errorf("Failed to make submission dir '%s': '%w'.", baseSubmissionDir, err);
+    }
+
+    resultPath := filepath.Join(baseSubmissionDir, SUBMISSION_RESULT_FILENAME);
+    err = util.ToJSONFileIndent(result.Info, resultPath);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write submission result '%s': '%w'.", resultPath, err);
+    }
+
+    submissionInputDir := filepath.Join(baseSubmissionDir, common.GRADING_INPUT_DIRNAME);
+    err = util.MkDir(submissionInputDir);
+    if (err != nil) {
+        return fmt.Errorf("Failed to make submission input dir '%s': '%w'.", submissionInputDir, err);
+    }
+
+    err = util.GzipBytesToDirectory(submissionInputDir, result.InputFilesGZip);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write submission input files: '%w'.", err);
+    }
+
+    submissionOutputDir := filepath.Join(baseSubmissionDir, common.GRADING_OUTPUT_DIRNAME);
+    err = util.MkDir(submissionOutputDir);
+    if (err != nil) {
+        return fmt.Errorf("Failed to make submission output dir '%s': '%w'.", submissionOutputDir, err);
+    }
+
+    err = util.GzipBytesToDirectory(submissionOutputDir, result.OutputFilesGZip);
+    if (err != nil) {
+        return fmt.Errorf("Failed to write submission output files: '%w'.", err);
+    }
+
+    err = util.WriteFile(result.Stdout, filepath.Join(baseSubmissionDir, common.SUBMISSION_STDOUT_FILENAME));
+    if (err != nil) {
+        return fmt.Errorf("Failed to write submission stdout file: '%w'.", err);
+    }
+
+    err = util.WriteFile(result.Stderr, filepath.Join(baseSubmissionDir, common.SUBMISSION_STDERR_FILENAME));
+    if (err != nil) {
+        return fmt.Errorf("Failed to write submission stderr file: '%w'.", err);
+    }
+
+    return nil;
+}
+
 func MustLoadGradingResult(resultPath string) *GradingResult {
     result, err := LoadGradingResult(resultPath);
     if (err != nil) {
@@ -3,6 +3,7 @@ package model
 type LMSSyncResult struct {
     UserSync *UserSyncResult `json:"user-sync"`
     AssignmentSync *AssignmentSyncResult `json:"assignment-sync"`
+    GroupSync *GroupSyncResult `json:"group-sync"`
 }
 
 type AssignmentSyncResult struct {
@@ -26,6 +27,24 @@ func NewAssignmentSyncResult() *AssignmentSyncResult {
     };
 }
 
+type GroupSyncResult struct {
+    Add []*Group `json:"add"`
+    Mod []*Group `json:"mod"`
+    Unchanged []*Group `json:"unchanged"`
+
+    // LMS group members that are not users in the course (and were therefore left out of their group).
+    UnknownMembers []string `json:"unknown-members"`
+}
+
+func NewGroupSyncResult() *GroupSyncResult {
+    return &GroupSyncResult{
+        Add: make([]*Group, 0),
+        Mod: make([]*Group, 0),
+        Unchanged: make([]*Group, 0),
+        UnknownMembers: make([]string, 0),
+    };
+}
+
 type UserSyncResult struct {
     Add []*User
     Mod []*User
@@ -1,8 +1,6 @@
 package tasks
 
 import (
-    "fmt"
-
     "github.com/edulinq/autograder/log"
 )
 
@@ -20,14 +18,15 @@ func (this *ScoringUploadTask) Validate(course TaskCourse) error {
         return err;
     }
 
+    // Without an LMS, scores are only stored locally.
     if (!course.HasLMSAdapter()) {
-        return fmt.Errorf("Score and Upload task course must have an LMS adapter.");
+        return nil;
     }
 
     lmsIDs, assignmentIDs := course.GetAssignmentLMSIDs();
     for i, _ := range lmsIDs {
         if (lmsIDs[i] == "") {
-            log.Warn("Score and Upload course has an assignment with a missing LMS ID.",
+            log.Warn("Score and Upload course has an assignment with a missing LMS ID, its scores will not be uploaded.",
                     log.NewCourseAttr(course.GetID()), log.NewAssignmentAttr(assignmentIDs[i]));
         }
     }
@@ -0,0 +1,131 @@
+package model
+
+// API tokens are an alternative to sending a password with every request.
+// Only a hash of a token is stored, the cleartext is only returned to the user when the token is created.
+// Since tokens are long random strings (unlike passwords), a single (fast) sha256 is sufficient.
+
+import (
+    "crypto/subtle"
+    "fmt"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    TOKEN_ID_LEN = 16;
+    TOKEN_SECRET_LEN = 64;
+)
+
+type APIToken struct {
+    ID string `json:"id"`
+    Name string `json:"name,omitempty"`
+    Hash string `json:"hash"`
+    CreationTime common.Timestamp `json:"creation-time"`
+    // An empty expiration time means that the token never expires.
+    ExpirationTime common.Timestamp `json:"expiration-time,omitempty"`
+}
+
+// Create a new token for this user and return the cleartext token.
+// A zero (or negative) lifetime means that the token will never expire.
+// Expired tokens are removed from the user.
+// The caller is responsible for saving the user.
+func (this *User) NewToken(name string, lifetime time.Duration) (*APIToken, string, error) {
+    id, err := util.RandHex(TOKEN_ID_LEN);
+    if (err != nil) {
+        return nil, "", fmt.Errorf("Failed to generate token ID: '%w'.", err);
+    }
+
+    secret, err := util.RandHex(TOKEN_SECRET_LEN);
+    if (err != nil) {
+        return nil, "", fmt.Errorf("Failed to generate token: '%w'.", err);
+    }
+
+    now := time.Now();
+
+    token := &APIToken{
+        ID: id,
+        Name: name,
+        Hash: util.Sha256HexFromString(secret),
+        CreationTime: common.TimestampFromTime(now),
+    };
+
+    if (lifetime > 0) {
+        token.ExpirationTime = common.TimestampFromTime(now.Add(lifetime));
+    }
+
+    this.RemoveExpiredTokens();
+    this.Tokens = append(this.Tokens, token);
+
+    return token, secret, nil;
+}
+
+// Get the (unexpired) token that matches the given cleartext token.
+// Returns nil if no token matches.
+func (this *User) CheckToken(secret string) *APIToken {
+    if (secret == "") {
+        return nil;
+    }
+
+    hash := util.Sha256HexFromString(secret);
+
+    for _, token := range this.Tokens {
+        if (subtle.ConstantTimeCompare([]byte(hash), []byte(token.Hash)) != 1) {
+            continue;
+        }
+
+        if (token.IsExpired()) {
+            return nil;
+        }
+
+        return token;
+    }
+
+    return nil;
+}
+
+// Remove the token with the given ID.
+// Returns true if a token was removed.
+// The caller is responsible for saving the user.
+func (this *User) RevokeToken(id string) bool {
+    for i, token := range this.Tokens {
+        if (token.ID == id) {
+            this.Tokens = append(this.Tokens[:i], this.Tokens[i + 1:]...);
+            return true;
+        }
+    }
+
+    return false;
+}
+
+// Remove all of the user's tokens.
+// The caller is responsible for saving the user.
+func (this *User) RevokeAllTokens() {
+    this.Tokens = nil;
+}
+
+func (this *User) RemoveExpiredTokens() {
+    tokens := make([]*APIToken, 0, len(this.Tokens));
+    for _, token := range this.Tokens {
+        if (!token.IsExpired()) {
+            tokens = append(tokens, token);
+        }
+    }
+
+    this.Tokens = tokens;
+}
+
+func (this *APIToken) IsExpired() bool {
+    if (this.ExpirationTime.IsZero()) {
+        return false;
+    }
+
+    expiration, err := this.ExpirationTime.Time();
+    if (err != nil) {
+        // A token with a bad expiration time should never be accepted.
+        return true;
+    }
+
+    return !time.Now().Before(expiration);
+}
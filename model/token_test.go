@@ -0,0 +1,103 @@
+package model
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+)
+
+func TestUserTokens(test *testing.T) {
+    user := &User{};
+
+    token, secret, err := user.NewToken("test", time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    if (token.Hash == secret) {
+        test.Fatalf("Token is stored in cleartext.");
+    }
+
+    if (user.CheckToken(secret) != token) {
+        test.Fatalf("Token does not check.");
+    }
+
+    for _, bad := range []string{"", "Z", secret + "Z", token.Hash, token.ID} {
+        if (user.CheckToken(bad) != nil) {
+            test.Errorf("Bad token '%s' checks.", bad);
+        }
+    }
+
+    noExpirationToken, noExpirationSecret, err := user.NewToken("", 0);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    if (!noExpirationToken.ExpirationTime.IsZero()) {
+        test.Fatalf("Token without a lifetime has an expiration: '%s'.", noExpirationToken.ExpirationTime);
+    }
+
+    if (user.CheckToken(noExpirationSecret) != noExpirationToken) {
+        test.Fatalf("Token without expiration does not check.");
+    }
+
+    if (!user.RevokeToken(token.ID)) {
+        test.Fatalf("Failed to revoke token.");
+    }
+
+    if (user.RevokeToken(token.ID)) {
+        test.Fatalf("Revoked the same token twice.");
+    }
+
+    if (user.CheckToken(secret) != nil) {
+        test.Fatalf("Revoked token checks.");
+    }
+
+    if (user.CheckToken(noExpirationSecret) == nil) {
+        test.Fatalf("Revoking one token affected another.");
+    }
+}
+
+func TestUserTokensExpired(test *testing.T) {
+    user := &User{};
+
+    token, secret, err := user.NewToken("", time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    token.ExpirationTime = common.TimestampFromTime(time.Now().Add(-time.Minute));
+
+    if (user.CheckToken(secret) != nil) {
+        test.Fatalf("Expired token checks.");
+    }
+
+    _, _, err = user.NewToken("", time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    // Creating a new token clears out expired ones.
+    if (len(user.Tokens) != 1) {
+        test.Fatalf("Unexpected number of tokens. Expected: 1, Actual: %d.", len(user.Tokens));
+    }
+}
+
+func TestUserTokensRevokedOnPasswordChange(test *testing.T) {
+    user := &User{};
+
+    _, secret, err := user.NewToken("test", time.Hour);
+    if (err != nil) {
+        test.Fatalf("Failed to create token: '%v'.", err);
+    }
+
+    err = user.SetPassword("new-pass");
+    if (err != nil) {
+        test.Fatalf("Failed to set password: '%v'.", err);
+    }
+
+    if ((len(user.Tokens) != 0) || (user.CheckToken(secret) != nil)) {
+        test.Fatalf("Token was not revoked on a password change.");
+    }
+}
@@ -10,11 +10,9 @@ import (
     "fmt"
     "slices"
     "strings"
-    "time"
 
     "golang.org/x/crypto/argon2"
 
-    "github.com/edulinq/autograder/config"
     "github.com/edulinq/autograder/email"
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/util"
@@ -28,8 +26,6 @@ const (
     ARGON2_MEM_KB = 64 * 1024;
     ARGON2_THREADS = 4;
     ARGON2_TIME = 1;
-
-    EMAIL_SLEEP_TIME = int64(1.5 * float64(time.Second));
 )
 
 type User struct {
@@ -40,6 +36,10 @@ type User struct {
     Salt string `json:"salt"`
 
     LMSID string `json:"lms-id"`
+
+    Tokens []*APIToken `json:"tokens,omitempty"`
+    // The outstanding password reset (if any).
+    PasswordReset *PasswordResetToken `json:"password-reset,omitempty"`
 }
 
 func NewUser(email string, name string, role UserRole) *User {
@@ -56,6 +56,7 @@ func (this *User) LogValue() []*log.Attr {
 
 // Sets the password and generates a new salt.
 // The passed in passowrd should actually be a hash of the cleartext password.
+// All of the user's tokens are revoked.
 func (this *User) SetPassword(hashPass string) error {
     salt, err := util.RandBytes(SALT_LENGTH_BYTES);
     if (err != nil) {
@@ -67,6 +68,9 @@ func (this *User) SetPassword(hashPass string) error {
     this.Salt = hex.EncodeToString(salt);
     this.Pass = hex.EncodeToString(pass);
 
+    // Tokens may have been gotten with the old password.
+    this.RevokeAllTokens();
+
     return nil;
 }
 
@@ -140,17 +144,22 @@ func generateHash(hashPass string, salt []byte) []byte {
     return argon2.IDKey([]byte(hashPass), salt, ARGON2_TIME, ARGON2_MEM_KB, ARGON2_THREADS, ARGON2_KEY_LEN_BYTES);
 }
 
-func SendUserAddEmail(course *Course, user *User, pass string, generatedPass bool, userExists bool, dryRun bool, sleep bool) error {
-    subject, body := composeUserAddEmail(course, user.Email, pass, generatedPass, userExists);
+func SendUserAddEmail(course *Course, user *User, pass string, generatedPass bool, userExists bool, dryRun bool) error {
+    message, err := composeUserAddEmail(course, user.Email, pass, generatedPass, userExists);
+    if (err != nil) {
+        log.Error("Failed to compose email.", err, course, log.NewUserAttr(user.Email));
+        return err;
+    }
 
     if (dryRun) {
         log.Info("Doing a dry run, user will not be emailed.", course, log.NewUserAttr(user.Email));
+        // The body may contain the user's password.
         log.Debug("Email not sent because of dry run.", course,
-                log.NewAttr("address", user.Email), log.NewAttr("subject", subject), log.NewAttr("body", body));
+                log.NewAttr("address", user.Email), log.NewAttr("subject", message.Subject));
         return nil;
     }
 
-    err := email.Send([]string{user.Email}, subject, body, false);
+    err = email.SendMessage(message);
     if (err != nil) {
         log.Error("Failed to send email.", err, course, log.NewUserAttr(user.Email));
         return err;
@@ -158,36 +167,34 @@ func SendUserAddEmail(course *Course, user *User, pass string, generatedPass boo
 
     log.Info("Registration email sent.", course, log.NewUserAttr(user.Email));
 
-    // Skip sleeping in testing mode.
-    if (sleep && !config.TESTING_MODE.Get()) {
-        time.Sleep(time.Duration(EMAIL_SLEEP_TIME));
-    }
-
     return nil;
 }
 
-func composeUserAddEmail(course *Course, address string, pass string, generatedPass bool, userExists bool) (string, string) {
-    var subject string;
-    var body string;
+func composeUserAddEmail(course *Course, address string, pass string, generatedPass bool, userExists bool) (*email.Message, error) {
+    data := UserEmailData{
+        CourseID: course.GetID(),
+        CourseName: course.GetDisplayName(),
+        Email: address,
+    };
+
+    if (generatedPass) {
+        data.Password = pass;
+    }
 
+    templateName := email.TEMPLATE_USER_ADD;
     if (userExists) {
-        subject = fmt.Sprintf("Autograder %s -- User Password Changed", course.GetID());;
-        body =
-            "Hello,\n" +
-            fmt.Sprintf("\nThe password for '%s' has been changed for the course '%s'.\n", address, course.GetDisplayName());
-    } else {
-        subject = fmt.Sprintf("Autograder %s -- User Account Created", course.GetID());
-        body =
-            "Hello,\n" +
-            fmt.Sprintf("\nAn autograder account with the username/email '%s' has been created for the course '%s'.\n", address, course.GetDisplayName()) +
-            "Usage instructions will provided in class.\n";
+        templateName = email.TEMPLATE_USER_PASSWORD_CHANGE;
     }
 
-    if (generatedPass) {
-        body += fmt.Sprintf("Your new password is '%s' (no quotes).\n", pass);
+    message, err := course.RenderEmail(templateName, []string{address}, &data);
+    if (err != nil) {
+        return nil, err;
     }
 
-    return subject, body;
+    // The generated password is in the body.
+    message.Sensitive = generatedPass;
+
+    return message, nil;
 }
 
 func ToRowHeaader(delim string) string {
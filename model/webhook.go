@@ -0,0 +1,181 @@
+package model
+
+// Outgoing webhooks.
+// A course can have webhooks that are sent (POSTed) a signed JSON event whenever something happens in the course
+// (e.g., a submission is graded).
+// Every event sent to a webhook is recorded as a delivery, so failed deliveries can be retried and audited.
+
+import (
+    "fmt"
+    "net/url"
+    "slices"
+    "strings"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/log"
+)
+
+const (
+    WEBHOOK_EVENT_SUBMISSION_GRADED = "submission.graded"
+    WEBHOOK_EVENT_SUBMISSION_REJECTED = "submission.rejected"
+    WEBHOOK_EVENT_COURSE_UPDATED = "course.updated"
+    WEBHOOK_EVENT_LMS_SYNC = "lms.sync"
+    WEBHOOK_EVENT_SCORING_UPLOAD = "scoring.upload"
+)
+
+var WEBHOOK_EVENTS []string = []string{
+    WEBHOOK_EVENT_SUBMISSION_GRADED,
+    WEBHOOK_EVENT_SUBMISSION_REJECTED,
+    WEBHOOK_EVENT_COURSE_UPDATED,
+    WEBHOOK_EVENT_LMS_SYNC,
+    WEBHOOK_EVENT_SCORING_UPLOAD,
+};
+
+const (
+    // The delivery has not succeeded yet, but will be tried again.
+    WEBHOOK_DELIVERY_PENDING = "pending"
+    // The webhook accepted the event.
+    WEBHOOK_DELIVERY_DELIVERED = "delivered"
+    // The delivery ran out of attempts.
+    WEBHOOK_DELIVERY_FAILED = "failed"
+)
+
+type Webhook struct {
+    URL string `json:"url"`
+    // The key used to sign events (HMAC-SHA256 over the request body).
+    Secret string `json:"secret"`
+    // The events to send to this webhook.
+    // Empty means all events.
+    Events []string `json:"events,omitempty"`
+}
+
+type WebhookDelivery struct {
+    ID string `json:"id"`
+    URL string `json:"url"`
+    Event string `json:"event"`
+    // The JSON body that is sent.
+    Payload string `json:"payload"`
+
+    Status string `json:"status"`
+    Attempts int `json:"attempts"`
+
+    CreatedTime common.Timestamp `json:"created-time"`
+    LastAttemptTime common.Timestamp `json:"last-attempt-time,omitempty"`
+    // When a pending delivery should next be attempted.
+    NextAttemptTime common.Timestamp `json:"next-attempt-time,omitempty"`
+
+    // The HTTP status of the last attempt (zero if there was no response).
+    LastResponseCode int `json:"last-response-code,omitempty"`
+    LastError string `json:"last-error,omitempty"`
+}
+
+func (this *Webhook) LogValue() []*log.Attr {
+    return []*log.Attr{
+        log.NewAttr("webhook", this.URL),
+    };
+}
+
+func (this *Webhook) Validate() error {
+    this.URL = strings.TrimSpace(this.URL);
+    if (this.URL == "") {
+        return fmt.Errorf("Webhook must have a URL.");
+    }
+
+    parsed, err := url.Parse(this.URL);
+    if (err != nil) {
+        return fmt.Errorf("Webhook has an invalid URL ('%s'): '%w'.", this.URL, err);
+    }
+
+    if ((parsed.Scheme != "http") && (parsed.Scheme != "https")) {
+        return fmt.Errorf("Webhook URL must be http or https, found '%s'.", this.URL);
+    }
+
+    if (this.Secret == "") {
+        return fmt.Errorf("Webhook '%s' must have a secret.", this.URL);
+    }
+
+    for i, event := range this.Events {
+        event = strings.ToLower(strings.TrimSpace(event));
+        if (!slices.Contains(WEBHOOK_EVENTS, event)) {
+            return fmt.Errorf("Webhook '%s' has an unknown event '%s'. Known events: [%s].", this.URL, event, strings.Join(WEBHOOK_EVENTS, ", "));
+        }
+
+        this.Events[i] = event;
+    }
+
+    return nil;
+}
+
+// Check if this webhook should be sent the given event.
+func (this *Webhook) Accepts(event string) bool {
+    return ((len(this.Events) == 0) || slices.Contains(this.Events, event));
+}
+
+func (this *WebhookDelivery) LogValue() []*log.Attr {
+    return []*log.Attr{
+        log.NewAttr("delivery", this.ID),
+        log.NewAttr("webhook", this.URL),
+        log.NewAttr("event", this.Event),
+    };
+}
+
+func (this *WebhookDelivery) IsPending() bool {
+    return (this.Status == WEBHOOK_DELIVERY_PENDING);
+}
+
+// Check if a delivery should be attempted (again) at the given time.
+func (this *WebhookDelivery) IsDue(now time.Time) bool {
+    if (!this.IsPending()) {
+        return false;
+    }
+
+    if (this.NextAttemptTime.IsZero()) {
+        return true;
+    }
+
+    nextAttempt, err := this.NextAttemptTime.Time();
+    if (err != nil) {
+        // A bad time should not leave a delivery stuck.
+        return true;
+    }
+
+    return !nextAttempt.After(now);
+}
+
+// When a pending delivery should next be attempted (in Unix microseconds).
+// Zero means that the delivery should be attempted right away (see IsDue()).
+func (this *WebhookDelivery) NextAttemptUnixMicro() int64 {
+    if (this.NextAttemptTime.IsZero()) {
+        return 0;
+    }
+
+    nextAttempt, err := this.NextAttemptTime.Time();
+    if (err != nil) {
+        return 0;
+    }
+
+    return nextAttempt.UnixMicro();
+}
+
+// When a delivery was finished (delivered or failed) in Unix microseconds,
+// which is the time of the last attempt (or the time the delivery was created if it was never attempted).
+// Zero if the delivery is still pending or the time is not known.
+func (this *WebhookDelivery) FinishedUnixMicro() int64 {
+    if (this.IsPending()) {
+        return 0;
+    }
+
+    for _, timestamp := range []common.Timestamp{this.LastAttemptTime, this.CreatedTime} {
+        if (timestamp.IsZero()) {
+            continue;
+        }
+
+        instance, err := timestamp.Time();
+        if (err == nil) {
+            return instance.UnixMicro();
+        }
+    }
+
+    return 0;
+}
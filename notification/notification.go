@@ -0,0 +1,146 @@
+// Emails sent to users about their submissions (see model.NotificationOptions).
+// Notifications go to every user that shares the submission (i.e., all members of a submission group).
+// Failing to send a notification never fails the operation that triggered it, errors are only logged.
+package notification
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+)
+
+// The data available to the submission templates
+// (email.TEMPLATE_SUBMISSION_RECEIPT, email.TEMPLATE_SUBMISSION_REJECTION, and email.TEMPLATE_SUBMISSION_REMOVAL).
+// Fields that do not apply to a notification are left empty.
+type SubmissionEmailData struct {
+    CourseID string
+    CourseName string
+    AssignmentID string
+    AssignmentName string
+    User string
+
+    SubmissionID string
+    SubmissionTime string
+    Score string
+    MaxPoints string
+    FailureReason string
+    Message string
+
+    // Rejections only.
+    Reason string
+
+    // Removals only.
+    Remover string
+}
+
+// Send a receipt for a graded submission.
+func SendSubmissionReceipt(assignment *model.Assignment, info *model.GradingInfo) {
+    if (!assignment.GetCourse().GetNotifications().SubmissionReceipts) {
+        return;
+    }
+
+    data := newSubmissionData(assignment, info.User);
+    addGradingInfo(data, info);
+
+    send(assignment, info.User, email.TEMPLATE_SUBMISSION_RECEIPT, data);
+}
+
+// Send a notice that a submission was rejected (and therefore not graded).
+func SendRejectionNotice(assignment *model.Assignment, user string, reason string) {
+    if (!assignment.GetCourse().GetNotifications().Rejections) {
+        return;
+    }
+
+    data := newSubmissionData(assignment, user);
+    data.Reason = reason;
+
+    send(assignment, user, email.TEMPLATE_SUBMISSION_REJECTION, data);
+}
+
+// Send a notice that a submission was removed by |remover|.
+// Removing your own submission does not send a notice.
+func SendRemovalNotice(assignment *model.Assignment, info *model.GradingInfo, remover string) {
+    if (!assignment.GetCourse().GetNotifications().Removals) {
+        return;
+    }
+
+    if (info.User == remover) {
+        return;
+    }
+
+    data := newSubmissionData(assignment, info.User);
+    addGradingInfo(data, info);
+    data.Remover = remover;
+
+    send(assignment, info.User, email.TEMPLATE_SUBMISSION_REMOVAL, data);
+}
+
+func send(assignment *model.Assignment, user string, templateName string, data *SubmissionEmailData) {
+    recipients, err := getRecipients(assignment, user);
+    if (err != nil) {
+        log.Error("Failed to get notification recipients.", err, assignment, log.NewUserAttr(user));
+        return;
+    }
+
+    message, err := assignment.GetCourse().RenderEmail(templateName, recipients, data);
+    if (err != nil) {
+        log.Error("Failed to compose notification email.", err, assignment, log.NewUserAttr(user), log.NewAttr("template", templateName));
+        return;
+    }
+
+    err = email.SendMessage(message);
+    if (err != nil) {
+        log.Error("Failed to send notification email.", err, assignment, log.NewUserAttr(user), log.NewAttr("subject", message.Subject));
+        return;
+    }
+
+    log.Debug("Notification email sent.", assignment, log.NewUserAttr(user), log.NewAttr("subject", message.Subject));
+}
+
+func getRecipients(assignment *model.Assignment, user string) ([]string, error) {
+    group, err := db.GetSubmissionGroup(assignment, user);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get submission group: '%w'.", err);
+    }
+
+    if (group == nil) {
+        return []string{user}, nil;
+    }
+
+    return group.Members, nil;
+}
+
+func newSubmissionData(assignment *model.Assignment, user string) *SubmissionEmailData {
+    return &SubmissionEmailData{
+        CourseID: assignment.GetCourse().GetID(),
+        CourseName: assignment.GetCourse().GetDisplayName(),
+        AssignmentID: assignment.GetID(),
+        AssignmentName: getAssignmentName(assignment),
+        User: user,
+    };
+}
+
+func addGradingInfo(data *SubmissionEmailData, info *model.GradingInfo) {
+    data.SubmissionID = info.ShortID;
+    data.SubmissionTime = info.GradingStartTime.ShouldPrettyString();
+    data.Score = formatPoints(info.Score);
+    data.MaxPoints = formatPoints(info.MaxPoints);
+    data.FailureReason = info.FailureReason;
+    data.Message = info.Message;
+}
+
+func getAssignmentName(assignment *model.Assignment) string {
+    if (assignment.GetName() != "") {
+        return assignment.GetName();
+    }
+
+    return assignment.GetID();
+}
+
+func formatPoints(points float64) string {
+    return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", points), "0"), ".");
+}
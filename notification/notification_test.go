@@ -0,0 +1,173 @@
+package notification
+
+import (
+    "slices"
+    "strings"
+    "testing"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/email"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestSendNotifications(test *testing.T) {
+    defer db.ResetForTesting();
+    defer email.ClearTestMessages();
+
+    info := &model.GradingInfo{
+        ShortID: "1697406272",
+        User: "student@test.com",
+        Score: 1.5,
+        MaxPoints: 2,
+        GradingStartTime: "2023-10-15T21:44:32Z",
+    };
+
+    testCases := []struct{ options *model.NotificationOptions; group bool; send func(*model.Assignment); expectedTo []string; expectedSubject string; expectedBody string }{
+        // Off by default.
+        {nil, false, func(assignment *model.Assignment) { SendSubmissionReceipt(assignment, info) }, nil, "", ""},
+        {nil, false, func(assignment *model.Assignment) { SendRejectionNotice(assignment, "student@test.com", "Too many.") }, nil, "", ""},
+        {nil, false, func(assignment *model.Assignment) { SendRemovalNotice(assignment, info, "grader@test.com") }, nil, "", ""},
+
+        // Only the enabled notification is sent.
+        {&model.NotificationOptions{Rejections: true}, false, func(assignment *model.Assignment) { SendSubmissionReceipt(assignment, info) }, nil, "", ""},
+
+        {
+            &model.NotificationOptions{SubmissionReceipts: true}, false,
+            func(assignment *model.Assignment) { SendSubmissionReceipt(assignment, info) },
+            []string{"student@test.com"}, "Autograder course101 -- Submission Receipt for hw0", "Score: 1.5 / 2\n",
+        },
+        {
+            &model.NotificationOptions{Rejections: true}, false,
+            func(assignment *model.Assignment) { SendRejectionNotice(assignment, "student@test.com", "Too many.") },
+            []string{"student@test.com"}, "Autograder course101 -- Submission Rejected for hw0", "Reason: Too many.\n",
+        },
+        {
+            &model.NotificationOptions{Removals: true}, false,
+            func(assignment *model.Assignment) { SendRemovalNotice(assignment, info, "grader@test.com") },
+            []string{"student@test.com"}, "Autograder course101 -- Submission Removed for hw0", "was removed by 'grader@test.com'",
+        },
+
+        // Removing your own submission does not send a notice.
+        {&model.NotificationOptions{Removals: true}, false, func(assignment *model.Assignment) { SendRemovalNotice(assignment, info, "student@test.com") }, nil, "", ""},
+
+        // Group members all get notified.
+        {
+            &model.NotificationOptions{SubmissionReceipts: true}, true,
+            func(assignment *model.Assignment) { SendSubmissionReceipt(assignment, info) },
+            []string{"other@test.com", "student@test.com"}, "Autograder course101 -- Submission Receipt for hw0", "Submitted By: student@test.com\n",
+        },
+    };
+
+    for i, testCase := range testCases {
+        db.ResetForTesting();
+        email.ClearTestMessages();
+
+        course := db.MustGetTestCourse();
+        course.Notifications = testCase.options;
+
+        assignment := db.MustGetTestAssignment();
+        assignment.Course = course;
+
+        if (testCase.group) {
+            assignment.GroupSubmissions = true;
+
+            err := db.SaveGroup(course, &model.Group{ID: "team1", Members: []string{"student@test.com", "other@test.com"}});
+            if (err != nil) {
+                test.Fatalf("Case %d: Failed to save group: '%v'.", i, err);
+            }
+        }
+
+        testCase.send(assignment);
+
+        messages := email.GetTestMessages();
+        if (testCase.expectedTo == nil) {
+            if (len(messages) != 0) {
+                test.Errorf("Case %d: Unexpected email sent: '%v'.", i, messages);
+            }
+
+            continue;
+        }
+
+        if (len(messages) != 1) {
+            test.Errorf("Case %d: Unexpected number of emails. Expected: 1, Actual: %d.", i, len(messages));
+            continue;
+        }
+
+        message := messages[0];
+
+        if (!slices.Equal(testCase.expectedTo, message.To)) {
+            test.Errorf("Case %d: Unexpected recipients. Expected: '%v', Actual: '%v'.", i, testCase.expectedTo, message.To);
+            continue;
+        }
+
+        if (testCase.expectedSubject != message.Subject) {
+            test.Errorf("Case %d: Unexpected subject. Expected: '%s', Actual: '%s'.", i, testCase.expectedSubject, message.Subject);
+            continue;
+        }
+
+        if (!strings.Contains(message.Body, testCase.expectedBody)) {
+            test.Errorf("Case %d: Body does not contain '%s': '%s'.", i, testCase.expectedBody, message.Body);
+            continue;
+        }
+    }
+}
+
+func TestFormatPoints(test *testing.T) {
+    testCases := []struct{ points float64; expected string }{
+        {0, "0"},
+        {2, "2"},
+        {10, "10"},
+        {1.5, "1.5"},
+        {1.25, "1.25"},
+        {1.005, "1"},
+        {2.0 / 3.0, "0.67"},
+    };
+
+    for i, testCase := range testCases {
+        actual := formatPoints(testCase.points);
+        if (testCase.expected != actual) {
+            test.Errorf("Case %d: Unexpected points. Expected: '%s', Actual: '%s'.", i, testCase.expected, actual);
+        }
+    }
+}
+
+func TestSendNotificationCourseTemplate(test *testing.T) {
+    defer db.ResetForTesting();
+    defer email.ClearTestMessages();
+
+    db.ResetForTesting();
+    email.ClearTestMessages();
+
+    course := db.MustGetTestCourse();
+    course.Notifications = &model.NotificationOptions{Rejections: true};
+    course.EmailTemplates = map[string]*email.Template{
+        email.TEMPLATE_SUBMISSION_REJECTION: &email.Template{
+            Subject: "{{ .CourseID }}: {{ .AssignmentID }} was rejected",
+            Body: "<p>{{ .User }}: {{ .Reason }}</p>",
+            HTML: true,
+        },
+    };
+
+    assignment := db.MustGetTestAssignment();
+    assignment.Course = course;
+
+    SendRejectionNotice(assignment, "student@test.com", "<Too many.>");
+
+    messages := email.GetTestMessages();
+    if (len(messages) != 1) {
+        test.Fatalf("Unexpected number of emails. Expected: 1, Actual: %d.", len(messages));
+    }
+
+    expected := &email.Message{
+        To: []string{"student@test.com"},
+        Subject: "course101: hw0 was rejected",
+        Body: "<p>student@test.com: &lt;Too many.&gt;</p>",
+        HTML: true,
+        CourseID: "course101",
+    };
+
+    if (util.MustToJSON(expected) != util.MustToJSON(messages[0])) {
+        test.Fatalf("Unexpected email. Expected: '%s', Actual: '%s'.", util.MustToJSONIndent(expected), util.MustToJSONIndent(messages[0]));
+    }
+}
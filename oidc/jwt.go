@@ -0,0 +1,197 @@
+package oidc
+
+// Signing and verification of RS256 JSON Web Tokens (JWTs) and JSON Web Keys (JWKs).
+// These are shared by OIDC ID tokens and other JWT-based protocols (e.g., LTI).
+
+import (
+    "crypto"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "math/big"
+    "strings"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/util"
+)
+
+const JWT_ALGORITHM = "RS256";
+
+type jwtHeader struct {
+    Algorithm string `json:"alg"`
+    KeyID string `json:"kid"`
+    Type string `json:"typ,omitempty"`
+}
+
+type JSONWebKeySet struct {
+    Keys []*JSONWebKey `json:"keys"`
+}
+
+type JSONWebKey struct {
+    KeyType string `json:"kty"`
+    KeyID string `json:"kid"`
+    Use string `json:"use,omitempty"`
+    Algorithm string `json:"alg,omitempty"`
+    N string `json:"n"`
+    E string `json:"e"`
+}
+
+// Get the public JWK for an RSA signing key.
+func NewJSONWebKey(key *rsa.PublicKey, keyID string) *JSONWebKey {
+    return &JSONWebKey{
+        KeyType: "RSA",
+        KeyID: keyID,
+        Use: "sig",
+        Algorithm: JWT_ALGORITHM,
+        N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+        E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+    };
+}
+
+// Fetch a key set and get all the RSA signing keys in it (keyed by key ID).
+func FetchKeySet(uri string) (map[string]*rsa.PublicKey, error) {
+    body, err := common.Get(uri);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch keys: '%w'.", err);
+    }
+
+    var keySet JSONWebKeySet;
+    err = util.JSONFromString(body, &keySet);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to parse keys: '%w'.", err);
+    }
+
+    keys := make(map[string]*rsa.PublicKey, len(keySet.Keys));
+    for _, webKey := range keySet.Keys {
+        if ((webKey.KeyType != "RSA") || ((webKey.Use != "") && (webKey.Use != "sig"))) {
+            continue;
+        }
+
+        publicKey, err := webKey.ToPublicKey();
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to parse key '%s': '%w'.", webKey.KeyID, err);
+        }
+
+        keys[webKey.KeyID] = publicKey;
+    }
+
+    return keys, nil;
+}
+
+// Sign claims (anything that marshals to a JSON object) into a JWT.
+func SignJWT(key *rsa.PrivateKey, keyID string, claims any) (string, error) {
+    header, err := json.Marshal(jwtHeader{Algorithm: JWT_ALGORITHM, KeyID: keyID, Type: "JWT"});
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to marshal JWT header: '%w'.", err);
+    }
+
+    payload, err := json.Marshal(claims);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to marshal JWT claims: '%w'.", err);
+    }
+
+    content := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload);
+    digest := sha256.Sum256([]byte(content));
+
+    signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to sign JWT: '%w'.", err);
+    }
+
+    return content + "." + base64.RawURLEncoding.EncodeToString(signature), nil;
+}
+
+// Verify a JWT's signature and decode its claims into |claims|.
+// |getKey| is called with the key ID from the token's header.
+// Only the signature is checked, the caller is responsible for validating the claims.
+func VerifyJWT(rawToken string, getKey func(keyID string) (*rsa.PublicKey, error), claims any) error {
+    parts := strings.Split(rawToken, ".");
+    if (len(parts) != 3) {
+        return fmt.Errorf("Token is not a JWT.");
+    }
+
+    var header jwtHeader;
+    err := decodeSegment(parts[0], &header);
+    if (err != nil) {
+        return fmt.Errorf("Failed to decode token header: '%w'.", err);
+    }
+
+    if (header.Algorithm != JWT_ALGORITHM) {
+        return fmt.Errorf("Unsupported token signing algorithm: '%s'.", header.Algorithm);
+    }
+
+    key, err := getKey(header.KeyID);
+    if (err != nil) {
+        return err;
+    }
+
+    signature, err := base64.RawURLEncoding.DecodeString(parts[2]);
+    if (err != nil) {
+        return fmt.Errorf("Failed to decode token signature: '%w'.", err);
+    }
+
+    digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]));
+    err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature);
+    if (err != nil) {
+        return fmt.Errorf("Token has a bad signature: '%w'.", err);
+    }
+
+    err = decodeSegment(parts[1], claims);
+    if (err != nil) {
+        return fmt.Errorf("Failed to decode token claims: '%w'.", err);
+    }
+
+    return nil;
+}
+
+// Decode a JWT's claims without verifying it.
+// Unverified claims should only be used to decide how to verify the token (e.g., which issuer's keys to use).
+func DecodeUnverifiedJWT(rawToken string, claims any) error {
+    parts := strings.Split(rawToken, ".");
+    if (len(parts) != 3) {
+        return fmt.Errorf("Token is not a JWT.");
+    }
+
+    err := decodeSegment(parts[1], claims);
+    if (err != nil) {
+        return fmt.Errorf("Failed to decode token claims: '%w'.", err);
+    }
+
+    return nil;
+}
+
+func (this *JSONWebKey) ToPublicKey() (*rsa.PublicKey, error) {
+    n, err := base64.RawURLEncoding.DecodeString(this.N);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to decode modulus: '%w'.", err);
+    }
+
+    e, err := base64.RawURLEncoding.DecodeString(this.E);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to decode exponent: '%w'.", err);
+    }
+
+    exponent := new(big.Int).SetBytes(e);
+    if (!exponent.IsInt64() || (exponent.Int64() > (1 << 31))) {
+        return nil, fmt.Errorf("Exponent is too large.");
+    }
+
+    publicKey := &rsa.PublicKey{
+        N: new(big.Int).SetBytes(n),
+        E: int(exponent.Int64()),
+    };
+
+    return publicKey, nil;
+}
+
+func decodeSegment(segment string, target any) error {
+    data, err := base64.RawURLEncoding.DecodeString(segment);
+    if (err != nil) {
+        return err;
+    }
+
+    return json.Unmarshal(data, target);
+}
@@ -0,0 +1,177 @@
+package oidc
+
+// A minimal OpenID Connect client (https://openid.net/specs/openid-connect-core-1_0.html).
+// Only the authorization code flow (with PKCE) and RS256 signed ID tokens are supported.
+// The provider is configured with the oidc.* config options.
+
+import (
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "fmt"
+    "net/url"
+    "strings"
+    "sync"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    DISCOVERY_PATH = "/.well-known/openid-configuration";
+    SCOPES = "openid email profile";
+)
+
+type Provider struct {
+    Issuer string
+    ClientID string
+    ClientSecret string
+    RedirectURL string
+
+    lock sync.Mutex
+    discovery *discoveryDocument
+    keys map[string]*rsa.PublicKey
+}
+
+type discoveryDocument struct {
+    Issuer string `json:"issuer"`
+    AuthorizationEndpoint string `json:"authorization_endpoint"`
+    TokenEndpoint string `json:"token_endpoint"`
+    JWKSURI string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+    AccessToken string `json:"access_token"`
+    TokenType string `json:"token_type"`
+    IDToken string `json:"id_token"`
+}
+
+var providerLock sync.Mutex;
+var provider *Provider = nil;
+
+func NewProvider(issuer string, clientID string, clientSecret string, redirectURL string) *Provider {
+    return &Provider{
+        Issuer: strings.TrimSuffix(issuer, "/"),
+        ClientID: clientID,
+        ClientSecret: clientSecret,
+        RedirectURL: redirectURL,
+    };
+}
+
+// Get the provider described by the config.
+// Returns nil if OIDC is not configured.
+// The provider is cached (along with the discovery document and keys) until the config changes.
+func GetProvider() *Provider {
+    issuer := strings.TrimSuffix(config.OIDC_ISSUER.Get(), "/");
+    clientID := config.OIDC_CLIENT_ID.Get();
+    clientSecret := config.OIDC_CLIENT_SECRET.Get();
+    redirectURL := config.OIDC_REDIRECT_URL.Get();
+
+    if ((issuer == "") || (clientID == "") || (redirectURL == "")) {
+        return nil;
+    }
+
+    providerLock.Lock();
+    defer providerLock.Unlock();
+
+    if ((provider == nil) || (provider.Issuer != issuer) || (provider.ClientID != clientID) ||
+            (provider.ClientSecret != clientSecret) || (provider.RedirectURL != redirectURL)) {
+        provider = NewProvider(issuer, clientID, clientSecret, redirectURL);
+    }
+
+    return provider;
+}
+
+// Get the URL to send a user to in order to log in with the identity provider.
+func (this *Provider) AuthURL(state *LoginState) (string, error) {
+    discovery, err := this.getDiscovery();
+    if (err != nil) {
+        return "", err;
+    }
+
+    challenge := sha256.Sum256([]byte(state.Verifier));
+
+    params := url.Values{};
+    params.Set("response_type", "code");
+    params.Set("client_id", this.ClientID);
+    params.Set("redirect_uri", this.RedirectURL);
+    params.Set("scope", SCOPES);
+    params.Set("state", state.State);
+    params.Set("nonce", state.Nonce);
+    params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]));
+    params.Set("code_challenge_method", "S256");
+
+    separator := "?";
+    if (strings.Contains(discovery.AuthorizationEndpoint, "?")) {
+        separator = "&";
+    }
+
+    return discovery.AuthorizationEndpoint + separator + params.Encode(), nil;
+}
+
+// Exchange an authorization code for verified ID token claims.
+func (this *Provider) Exchange(code string, state *LoginState) (*Claims, error) {
+    discovery, err := this.getDiscovery();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    form := map[string]string{
+        "grant_type": "authorization_code",
+        "code": code,
+        "redirect_uri": this.RedirectURL,
+        "client_id": this.ClientID,
+        "client_secret": this.ClientSecret,
+        "code_verifier": state.Verifier,
+    };
+
+    body, err := common.Post(discovery.TokenEndpoint, form);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to exchange authorization code: '%w'.", err);
+    }
+
+    var response tokenResponse;
+    err = util.JSONFromString(body, &response);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to parse token response: '%w'.", err);
+    }
+
+    if (response.IDToken == "") {
+        return nil, fmt.Errorf("Token response does not contain an ID token.");
+    }
+
+    return this.VerifyIDToken(response.IDToken, state.Nonce);
+}
+
+func (this *Provider) getDiscovery() (*discoveryDocument, error) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    if (this.discovery != nil) {
+        return this.discovery, nil;
+    }
+
+    body, err := common.Get(this.Issuer + DISCOVERY_PATH);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to fetch OIDC discovery document: '%w'.", err);
+    }
+
+    var discovery discoveryDocument;
+    err = util.JSONFromString(body, &discovery);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to parse OIDC discovery document: '%w'.", err);
+    }
+
+    if (strings.TrimSuffix(discovery.Issuer, "/") != this.Issuer) {
+        return nil, fmt.Errorf("OIDC discovery document issuer ('%s') does not match the configured issuer ('%s').",
+                discovery.Issuer, this.Issuer);
+    }
+
+    if ((discovery.AuthorizationEndpoint == "") || (discovery.TokenEndpoint == "") || (discovery.JWKSURI == "")) {
+        return nil, fmt.Errorf("OIDC discovery document is missing required endpoints.");
+    }
+
+    this.discovery = &discovery;
+    return this.discovery, nil;
+}
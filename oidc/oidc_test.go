@@ -0,0 +1,167 @@
+package oidc
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+)
+
+const (
+    TEST_CLIENT_ID = "test-client";
+    TEST_CLIENT_SECRET = "test-secret";
+    TEST_NONCE = "test-nonce";
+)
+
+func TestVerifyIDToken(test *testing.T) {
+    idp, err := NewTestIdentityProvider(TEST_CLIENT_ID, TEST_CLIENT_SECRET);
+    if (err != nil) {
+        test.Fatalf("Failed to start test identity provider: '%v'.", err);
+    }
+    defer idp.Close();
+
+    otherIdP, err := NewTestIdentityProvider(TEST_CLIENT_ID, TEST_CLIENT_SECRET);
+    if (err != nil) {
+        test.Fatalf("Failed to start other test identity provider: '%v'.", err);
+    }
+    defer otherIdP.Close();
+
+    provider := NewProvider(idp.URL(), TEST_CLIENT_ID, TEST_CLIENT_SECRET, "http://localhost/callback");
+
+    now := time.Now();
+
+    testCases := []struct{ modify func(*Claims); signer *TestIdentityProvider; tamper bool; errorSubstring string }{
+        {nil, idp, false, ""},
+        {func(claims *Claims) { claims.Audience = Audience{"other", TEST_CLIENT_ID} }, idp, false, ""},
+        {func(claims *Claims) { claims.Expiration = now.Add(-time.Minute).Unix() }, idp, false, ""},
+
+        {func(claims *Claims) { claims.Issuer = "http://localhost/other" }, idp, false, "wrong issuer"},
+        {func(claims *Claims) { claims.Audience = Audience{"other"} }, idp, false, "not issued for this client"},
+        {func(claims *Claims) { claims.Expiration = now.Add(-time.Hour).Unix() }, idp, false, "expired"},
+        {func(claims *Claims) { claims.IssuedAt = now.Add(time.Hour).Unix() }, idp, false, "in the future"},
+        {func(claims *Claims) { claims.Nonce = "ZZZ" }, idp, false, "wrong nonce"},
+        {nil, otherIdP, false, "bad signature"},
+        {nil, idp, true, "bad signature"},
+    };
+
+    for i, testCase := range testCases {
+        claims := &Claims{
+            Issuer: idp.URL(),
+            Subject: "sub",
+            Audience: Audience{TEST_CLIENT_ID},
+            Expiration: now.Add(time.Hour).Unix(),
+            IssuedAt: now.Unix(),
+            Nonce: TEST_NONCE,
+            Email: "student@test.com",
+        };
+
+        if (testCase.modify != nil) {
+            testCase.modify(claims);
+        }
+
+        token, err := testCase.signer.SignClaims(claims);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to sign claims: '%v'.", i, err);
+            continue;
+        }
+
+        if (testCase.tamper) {
+            parts := strings.Split(token, ".");
+            claims.Email = "admin@test.com";
+            tampered, err := idp.SignClaims(claims);
+            if (err != nil) {
+                test.Errorf("Case %d: Failed to sign tampered claims: '%v'.", i, err);
+                continue;
+            }
+
+            token = parts[0] + "." + strings.Split(tampered, ".")[1] + "." + parts[2];
+        }
+
+        verifiedClaims, err := provider.VerifyIDToken(token, TEST_NONCE);
+        if (testCase.errorSubstring != "") {
+            if (err == nil) {
+                test.Errorf("Case %d: Did not get an expected error.", i);
+            } else if (!strings.Contains(err.Error(), testCase.errorSubstring)) {
+                test.Errorf("Case %d: Unexpected error. Expected substring: '%s', Actual: '%v'.", i, testCase.errorSubstring, err);
+            }
+
+            continue;
+        }
+
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to verify token: '%v'.", i, err);
+            continue;
+        }
+
+        if (verifiedClaims.Email != claims.Email) {
+            test.Errorf("Case %d: Unexpected email. Expected: '%s', Actual: '%s'.", i, claims.Email, verifiedClaims.Email);
+            continue;
+        }
+    }
+}
+
+func TestLoginState(test *testing.T) {
+    state, err := NewLoginState("course101");
+    if (err != nil) {
+        test.Fatalf("Failed to create login state: '%v'.", err);
+    }
+
+    if (TakeLoginState("ZZZ") != nil) {
+        test.Fatalf("Got an unknown login state.");
+    }
+
+    if (TakeLoginState(state.State) != state) {
+        test.Fatalf("Did not get login state.");
+    }
+
+    if (TakeLoginState(state.State) != nil) {
+        test.Fatalf("Login state can be used twice.");
+    }
+
+    state, err = NewLoginState("course101");
+    if (err != nil) {
+        test.Fatalf("Failed to create login state: '%v'.", err);
+    }
+
+    state.Expiration = time.Now().Add(-time.Second);
+
+    if (TakeLoginState(state.State) != nil) {
+        test.Fatalf("Got an expired login state.");
+    }
+}
+
+func TestLoginStateMax(test *testing.T) {
+    loginStatesLock.Lock();
+    for i := 0; i < MAX_LOGIN_STATES; i++ {
+        state := fmt.Sprintf("test-%d", i);
+        loginStates[state] = &LoginState{State: state, Expiration: time.Now().Add(LOGIN_STATE_TTL)};
+    }
+    loginStatesLock.Unlock();
+
+    defer func() {
+        loginStatesLock.Lock();
+        defer loginStatesLock.Unlock();
+
+        loginStates = make(map[string]*LoginState);
+    }();
+
+    _, err := NewLoginState("course101");
+    if (!errors.Is(err, ErrTooManyLogins)) {
+        test.Fatalf("Did not get the expected error when there are too many logins: '%v'.", err);
+    }
+
+    // Expired logins do not count against the limit.
+    loginStatesLock.Lock();
+    loginStates["test-0"].Expiration = time.Now().Add(-time.Second);
+    loginStatesLock.Unlock();
+
+    state, err := NewLoginState("course101");
+    if (err != nil) {
+        test.Fatalf("Failed to create login state after one expired: '%v'.", err);
+    }
+
+    if (TakeLoginState(state.State) != state) {
+        test.Fatalf("Did not get login state.");
+    }
+}
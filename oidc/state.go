@@ -0,0 +1,165 @@
+package oidc
+
+// Pending logins.
+// Each login attempt gets a random state (to tie the callback to the attempt),
+// a nonce (to tie the ID token to the attempt), and a PKCE verifier (to tie the code exchange to the attempt).
+// Pending logins are only kept in memory, so a login will fail if the server restarts in the middle of it.
+// Only a limited number of logins can be pending at once (so unfinished logins cannot use up the server's memory).
+// Each login is also bound to the browser that started it with a short-lived cookie,
+// so a callback that carries another browser's login (login CSRF) is rejected.
+
+import (
+    "crypto/subtle"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    LOGIN_STATE_TTL = 10 * time.Minute;
+    LOGIN_STATE_LEN = 32;
+    LOGIN_VERIFIER_LEN = 64;
+    MAX_LOGIN_STATES = 10000;
+    LOGIN_STATE_COOKIE = "autograder-login-state";
+)
+
+var ErrTooManyLogins error = errors.New("Too many pending logins.");
+
+type LoginState struct {
+    State string
+    Nonce string
+    Verifier string
+
+    CourseID string
+    Expiration time.Time
+}
+
+var loginStatesLock sync.Mutex;
+var loginStates map[string]*LoginState = make(map[string]*LoginState);
+
+// Create (and remember) the state for a new login attempt.
+// Returns ErrTooManyLogins if there are already too many pending logins.
+func NewLoginState(courseID string) (*LoginState, error) {
+    state, err := util.RandHex(LOGIN_STATE_LEN);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to generate login state: '%w'.", err);
+    }
+
+    nonce, err := util.RandHex(LOGIN_STATE_LEN);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to generate login nonce: '%w'.", err);
+    }
+
+    verifier, err := util.RandHex(LOGIN_VERIFIER_LEN);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to generate login verifier: '%w'.", err);
+    }
+
+    loginState := &LoginState{
+        State: state,
+        Nonce: nonce,
+        Verifier: verifier,
+        CourseID: courseID,
+        Expiration: time.Now().Add(LOGIN_STATE_TTL),
+    };
+
+    loginStatesLock.Lock();
+    defer loginStatesLock.Unlock();
+
+    removeExpiredLoginStates();
+    if (len(loginStates) >= MAX_LOGIN_STATES) {
+        return nil, ErrTooManyLogins;
+    }
+
+    loginStates[state] = loginState;
+
+    return loginState, nil;
+}
+
+// Get and forget the pending login with the given state.
+// Each state can only be used once.
+// Returns nil if there is no matching (unexpired) login.
+func TakeLoginState(state string) *LoginState {
+    loginStatesLock.Lock();
+    defer loginStatesLock.Unlock();
+
+    loginState := loginStates[state];
+    if (loginState == nil) {
+        return nil;
+    }
+
+    delete(loginStates, state);
+
+    if (time.Now().After(loginState.Expiration)) {
+        return nil;
+    }
+
+    return loginState;
+}
+
+func removeExpiredLoginStates() {
+    now := time.Now();
+    for state, loginState := range loginStates {
+        if (now.After(loginState.Expiration)) {
+            delete(loginStates, state);
+        }
+    }
+}
+
+// Bind a login to the user's browser.
+// The cookie is only sent to the callback URL (and is only marked secure when the callback is over HTTPS).
+// A cross-site cookie is needed when the callback is a POST from another site (e.g., an LTI launch),
+// which browsers only allow for secure cookies.
+func SetLoginStateCookie(response http.ResponseWriter, state *LoginState, callbackURL string, crossSite bool) {
+    cookie := newLoginStateCookie(callbackURL, crossSite);
+    cookie.Value = state.State;
+    cookie.MaxAge = int(LOGIN_STATE_TTL.Seconds());
+
+    http.SetCookie(response, cookie);
+}
+
+// Check that a login was started by the browser that sent this request.
+func CheckLoginStateCookie(request *http.Request, state *LoginState) bool {
+    cookie, err := request.Cookie(LOGIN_STATE_COOKIE);
+    if (err != nil) {
+        return false;
+    }
+
+    return (subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state.State)) == 1);
+}
+
+func ClearLoginStateCookie(response http.ResponseWriter, callbackURL string, crossSite bool) {
+    cookie := newLoginStateCookie(callbackURL, crossSite);
+    cookie.MaxAge = -1;
+
+    http.SetCookie(response, cookie);
+}
+
+func newLoginStateCookie(callbackURL string, crossSite bool) *http.Cookie {
+    path := "/";
+    parsedURL, err := url.Parse(callbackURL);
+    if ((err == nil) && (parsedURL.Path != "")) {
+        path = parsedURL.Path;
+    }
+
+    secure := strings.HasPrefix(strings.ToLower(callbackURL), "https://");
+
+    sameSite := http.SameSiteLaxMode;
+    if (crossSite && secure) {
+        sameSite = http.SameSiteNoneMode;
+    }
+
+    return &http.Cookie{
+        Name: LOGIN_STATE_COOKIE,
+        Path: path,
+        HttpOnly: true,
+        Secure: secure,
+        SameSite: sameSite,
+    };
+}
@@ -0,0 +1,222 @@
+package oidc
+
+// A local (mock) identity provider for testing.
+// The provider does not ask for credentials,
+// every authorization request is approved as whatever user was last set with SetUser().
+
+import (
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    TEST_KEY_ID = "test-key";
+    TEST_KEY_BITS = 2048;
+)
+
+type TestIdentityProvider struct {
+    ClientID string
+    ClientSecret string
+
+    server *httptest.Server
+    key *rsa.PrivateKey
+
+    lock sync.Mutex
+    email string
+    emailVerified bool
+    codes map[string]*testAuthorization
+}
+
+type testAuthorization struct {
+    email string
+    emailVerified bool
+    nonce string
+    challenge string
+    redirectURL string
+}
+
+func NewTestIdentityProvider(clientID string, clientSecret string) (*TestIdentityProvider, error) {
+    key, err := rsa.GenerateKey(rand.Reader, TEST_KEY_BITS);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to generate test key: '%w'.", err);
+    }
+
+    idp := &TestIdentityProvider{
+        ClientID: clientID,
+        ClientSecret: clientSecret,
+        key: key,
+        emailVerified: true,
+        codes: make(map[string]*testAuthorization),
+    };
+
+    mux := http.NewServeMux();
+    mux.HandleFunc(DISCOVERY_PATH, idp.handleDiscovery);
+    mux.HandleFunc("/authorize", idp.handleAuthorize);
+    mux.HandleFunc("/token", idp.handleToken);
+    mux.HandleFunc("/jwks", idp.handleKeys);
+
+    idp.server = httptest.NewServer(mux);
+
+    return idp, nil;
+}
+
+func (this *TestIdentityProvider) URL() string {
+    return this.server.URL;
+}
+
+func (this *TestIdentityProvider) Close() {
+    this.server.Close();
+}
+
+// Set the user that the next authorization requests will be approved as.
+func (this *TestIdentityProvider) SetUser(email string, emailVerified bool) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    this.email = email;
+    this.emailVerified = emailVerified;
+}
+
+// Sign arbitrary claims with this provider's key.
+func (this *TestIdentityProvider) SignClaims(claims *Claims) (string, error) {
+    return SignJWT(this.key, TEST_KEY_ID, claims);
+}
+
+func (this *TestIdentityProvider) handleDiscovery(response http.ResponseWriter, request *http.Request) {
+    discovery := discoveryDocument{
+        Issuer: this.URL(),
+        AuthorizationEndpoint: this.URL() + "/authorize",
+        TokenEndpoint: this.URL() + "/token",
+        JWKSURI: this.URL() + "/jwks",
+    };
+
+    writeTestJSON(response, discovery);
+}
+
+func (this *TestIdentityProvider) handleKeys(response http.ResponseWriter, request *http.Request) {
+    keySet := JSONWebKeySet{
+        Keys: []*JSONWebKey{NewJSONWebKey(&this.key.PublicKey, TEST_KEY_ID)},
+    };
+
+    writeTestJSON(response, keySet);
+}
+
+func (this *TestIdentityProvider) handleAuthorize(response http.ResponseWriter, request *http.Request) {
+    query := request.URL.Query();
+
+    if ((query.Get("response_type") != "code") || (query.Get("client_id") != this.ClientID) ||
+            (query.Get("code_challenge_method") != "S256")) {
+        http.Error(response, "Bad authorization request.", http.StatusBadRequest);
+        return;
+    }
+
+    redirectURL, err := url.Parse(query.Get("redirect_uri"));
+    if ((err != nil) || (redirectURL.String() == "")) {
+        http.Error(response, "Bad redirect URL.", http.StatusBadRequest);
+        return;
+    }
+
+    code := util.UUID();
+
+    this.lock.Lock();
+    this.codes[code] = &testAuthorization{
+        email: this.email,
+        emailVerified: this.emailVerified,
+        nonce: query.Get("nonce"),
+        challenge: query.Get("code_challenge"),
+        redirectURL: redirectURL.String(),
+    };
+    this.lock.Unlock();
+
+    params := redirectURL.Query();
+    params.Set("code", code);
+    params.Set("state", query.Get("state"));
+    redirectURL.RawQuery = params.Encode();
+
+    http.Redirect(response, request, redirectURL.String(), http.StatusFound);
+}
+
+func (this *TestIdentityProvider) handleToken(response http.ResponseWriter, request *http.Request) {
+    err := request.ParseForm();
+    if (err != nil) {
+        http.Error(response, "Bad form.", http.StatusBadRequest);
+        return;
+    }
+
+    if ((request.PostForm.Get("client_id") != this.ClientID) || (request.PostForm.Get("client_secret") != this.ClientSecret)) {
+        http.Error(response, "Bad client.", http.StatusUnauthorized);
+        return;
+    }
+
+    if (request.PostForm.Get("grant_type") != "authorization_code") {
+        http.Error(response, "Bad grant type.", http.StatusBadRequest);
+        return;
+    }
+
+    this.lock.Lock();
+    code := request.PostForm.Get("code");
+    authorization := this.codes[code];
+    delete(this.codes, code);
+    this.lock.Unlock();
+
+    if (authorization == nil) {
+        http.Error(response, "Unknown code.", http.StatusBadRequest);
+        return;
+    }
+
+    if (authorization.redirectURL != request.PostForm.Get("redirect_uri")) {
+        http.Error(response, "Mismatched redirect URL.", http.StatusBadRequest);
+        return;
+    }
+
+    challenge := sha256.Sum256([]byte(request.PostForm.Get("code_verifier")));
+    if (base64.RawURLEncoding.EncodeToString(challenge[:]) != authorization.challenge) {
+        http.Error(response, "Bad code verifier.", http.StatusBadRequest);
+        return;
+    }
+
+    now := time.Now();
+    emailVerified := authorization.emailVerified;
+
+    claims := &Claims{
+        Issuer: this.URL(),
+        Subject: "sub-" + authorization.email,
+        Audience: Audience{this.ClientID},
+        Expiration: now.Add(time.Hour).Unix(),
+        IssuedAt: now.Unix(),
+        Nonce: authorization.nonce,
+        Email: authorization.email,
+        EmailVerified: &emailVerified,
+    };
+
+    idToken, err := this.SignClaims(claims);
+    if (err != nil) {
+        log.Error("Test identity provider failed to sign token.", err);
+        http.Error(response, "Failed to sign token.", http.StatusInternalServerError);
+        return;
+    }
+
+    tokens := tokenResponse{
+        AccessToken: util.UUID(),
+        TokenType: "Bearer",
+        IDToken: idToken,
+    };
+
+    writeTestJSON(response, tokens);
+}
+
+func writeTestJSON(response http.ResponseWriter, data any) {
+    response.Header().Set("Content-Type", "application/json");
+    fmt.Fprint(response, util.MustToJSON(data));
+}
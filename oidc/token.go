@@ -0,0 +1,124 @@
+package oidc
+
+// Verification of ID tokens (signed JWTs).
+
+import (
+    "crypto/rsa"
+    "encoding/json"
+    "fmt"
+    "slices"
+    "strings"
+    "time"
+)
+
+// Allowed difference between our clock and the identity provider's.
+const CLOCK_SKEW = 2 * time.Minute;
+
+type Claims struct {
+    Issuer string `json:"iss"`
+    Subject string `json:"sub"`
+    Audience Audience `json:"aud"`
+    Expiration int64 `json:"exp"`
+    IssuedAt int64 `json:"iat"`
+    Nonce string `json:"nonce,omitempty"`
+
+    Email string `json:"email,omitempty"`
+    // Not all providers send this claim.
+    EmailVerified *bool `json:"email_verified,omitempty"`
+    Name string `json:"name,omitempty"`
+}
+
+// The "aud" claim can be either a single string or a list of strings.
+type Audience []string;
+
+func (this *Audience) UnmarshalJSON(data []byte) error {
+    var single string;
+    err := json.Unmarshal(data, &single);
+    if (err == nil) {
+        *this = Audience{single};
+        return nil;
+    }
+
+    var multiple []string;
+    err = json.Unmarshal(data, &multiple);
+    if (err != nil) {
+        return fmt.Errorf("Audience is not a string or list of strings: '%w'.", err);
+    }
+
+    *this = Audience(multiple);
+    return nil;
+}
+
+// Verify an ID token's signature and standard claims (issuer, audience, expiration, and nonce).
+func (this *Provider) VerifyIDToken(rawToken string, nonce string) (*Claims, error) {
+    var claims Claims;
+    err := VerifyJWT(rawToken, this.getKey, &claims);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    err = claims.Validate(this.Issuer, this.ClientID, nonce);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return &claims, nil;
+}
+
+// Validate the standard claims of an (already verified) ID token.
+func (this *Claims) Validate(issuer string, clientID string, nonce string) error {
+    if (strings.TrimSuffix(this.Issuer, "/") != issuer) {
+        return fmt.Errorf("ID token has the wrong issuer. Expected: '%s', Actual: '%s'.", issuer, this.Issuer);
+    }
+
+    if (!slices.Contains(this.Audience, clientID)) {
+        return fmt.Errorf("ID token was not issued for this client ('%s').", clientID);
+    }
+
+    now := time.Now();
+
+    if (now.Add(-CLOCK_SKEW).After(time.Unix(this.Expiration, 0))) {
+        return fmt.Errorf("ID token is expired.");
+    }
+
+    if ((this.IssuedAt != 0) && now.Add(CLOCK_SKEW).Before(time.Unix(this.IssuedAt, 0))) {
+        return fmt.Errorf("ID token was issued in the future.");
+    }
+
+    if (this.Nonce != nonce) {
+        return fmt.Errorf("ID token has the wrong nonce.");
+    }
+
+    return nil;
+}
+
+// Get the provider's key with the given ID.
+// Keys are cached, but will be refetched if an unknown key is requested (in case the provider rotated its keys).
+func (this *Provider) getKey(keyID string) (*rsa.PublicKey, error) {
+    discovery, err := this.getDiscovery();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    key := this.keys[keyID];
+    if (key != nil) {
+        return key, nil;
+    }
+
+    keys, err := FetchKeySet(discovery.JWKSURI);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get OIDC keys: '%w'.", err);
+    }
+
+    this.keys = keys;
+
+    key = this.keys[keyID];
+    if (key == nil) {
+        return nil, fmt.Errorf("Unknown OIDC key: '%s'.", keyID);
+    }
+
+    return key, nil;
+}
@@ -10,6 +10,7 @@ import (
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/task"
+    "github.com/edulinq/autograder/webhook"
 )
 
 // Update a live course.
@@ -29,6 +30,10 @@ func UpdateCourse(course *model.Course, startTasks bool) (bool, error) {
     } else {
         // On success, use the new course.
         course = newCourse;
+
+        if (updated) {
+            webhook.EmitCourseUpdated(course);
+        }
     }
 
     // Sync the course.
@@ -9,6 +9,7 @@ import (
     "github.com/edulinq/autograder/common"
     "github.com/edulinq/autograder/db"
     "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/scoring"
     "github.com/edulinq/autograder/util"
 )
 
@@ -85,9 +86,14 @@ func GetAssignmentScoringReport(assignment *model.Assignment) (*AssignmentScorin
 }
 
 func fetchScores(assignment *model.Assignment) ([]string, map[string][]float64, time.Time, error) {
-    results, err := db.GetRecentSubmissions(assignment, model.RoleStudent);
+    dueDate, err := scoring.GetScoringDueDate(assignment);
     if (err != nil) {
-        return nil, nil, time.Time{}, fmt.Errorf("Failed to get recent submission results: '%w'.", err);
+        return nil, nil, time.Time{}, fmt.Errorf("Failed to get scoring due date: '%w'.", err);
+    }
+
+    results, err := db.GetScoredSubmissions(assignment, model.RoleStudent, dueDate);
+    if (err != nil) {
+        return nil, nil, time.Time{}, fmt.Errorf("Failed to get scored submission results: '%w'.", err);
     }
 
     questionNames := make([]string, 0);
@@ -0,0 +1,433 @@
+package sandbox
+
+// The child side of the sandbox.
+// When this binary is re-executed with SANDBOX_INIT_ENV set, it is already inside the new namespaces
+// (as root in its own user namespace).
+// It sets up the filesystem, drops privileges, applies limits, and then replaces itself with the requested command.
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "syscall"
+
+    "golang.org/x/sys/unix"
+)
+
+// Host paths that are visible (read-only) inside the sandbox.
+// Everything else on the host (e.g. the autograder's own dirs, home dirs, and most of /etc) is hidden.
+var hostPaths = []string{
+    "/bin",
+    "/lib",
+    "/lib32",
+    "/lib64",
+    "/libx32",
+    "/sbin",
+    "/usr",
+
+    "/etc/alternatives",
+    "/etc/group",
+    "/etc/hosts",
+    "/etc/ld.so.cache",
+    "/etc/ld.so.conf",
+    "/etc/ld.so.conf.d",
+    "/etc/localtime",
+    "/etc/nsswitch.conf",
+    "/etc/passwd",
+};
+
+// The only devices available inside the sandbox.
+var hostDevices = []string{
+    "/dev/full",
+    "/dev/null",
+    "/dev/random",
+    "/dev/urandom",
+    "/dev/zero",
+};
+
+func init() {
+    specJSON, ok := os.LookupEnv(SANDBOX_INIT_ENV);
+    if (!ok) {
+        return;
+    }
+
+    // Privileges and seccomp filters are per-thread, so stay on the thread that will exec.
+    runtime.LockOSThread();
+
+    err := runInit(specJSON);
+
+    // runInit() only returns on failure.
+    fmt.Fprintf(os.Stderr, "%v\n", err);
+    os.Exit(SANDBOX_SETUP_EXIT_CODE);
+}
+
+func runInit(specJSON string) error {
+    var spec Command;
+    err := json.Unmarshal([]byte(specJSON), &spec);
+    if (err != nil) {
+        return fmt.Errorf("Failed to parse sandbox spec: '%w'.", err);
+    }
+
+    // Resolve the command before the filesystem changes.
+    path, err := exec.LookPath(spec.Args[0]);
+    if (err != nil) {
+        return fmt.Errorf("Failed to find command '%s': '%w'.", spec.Args[0], err);
+    }
+
+    err = setupFilesystem(&spec);
+    if (err != nil) {
+        return err;
+    }
+
+    err = os.Chdir(spec.WorkDir);
+    if (err != nil) {
+        return fmt.Errorf("Failed to change to work dir '%s': '%w'.", spec.WorkDir, err);
+    }
+
+    err = setRlimits(&spec);
+    if (err != nil) {
+        return err;
+    }
+
+    err = dropCapabilities();
+    if (err != nil) {
+        return err;
+    }
+
+    err = unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0);
+    if (err != nil) {
+        return fmt.Errorf("Failed to set no new privileges: '%w'.", err);
+    }
+
+    // Must be last, since the filter blocks the calls used for setup.
+    err = installSeccompFilter();
+    if (err != nil) {
+        return err;
+    }
+
+    env := make([]string, 0, len(os.Environ()));
+    for _, value := range os.Environ() {
+        if (strings.HasPrefix(value, SANDBOX_INIT_ENV + "=")) {
+            continue;
+        }
+
+        env = append(env, value);
+    }
+    env = append(env, fmt.Sprintf("HOME=%s", spec.WorkDir));
+    env = append(env, fmt.Sprintf("TMPDIR=%s", spec.WorkDir));
+
+    err = syscall.Exec(path, spec.Args, env);
+    return fmt.Errorf("Failed to exec command '%s': '%w'.", path, err);
+}
+
+func setupFilesystem(spec *Command) error {
+    // Keep all mount changes inside this namespace.
+    err := unix.Mount("", "/", "", unix.MS_REC | unix.MS_PRIVATE, "");
+    if (err != nil) {
+        return fmt.Errorf("Failed to make mounts private: '%w'.", err);
+    }
+
+    // Build a new (empty) root and only put in the parts of the host that are needed.
+    // The new root is mounted over the base dir (so it needs to be cloned first).
+    baseTree, err := unix.OpenTree(unix.AT_FDCWD, spec.BaseDir, unix.OPEN_TREE_CLONE | unix.AT_RECURSIVE);
+    if (err != nil) {
+        return fmt.Errorf("Failed to clone base dir '%s': '%w'.", spec.BaseDir, err);
+    }
+    defer unix.Close(baseTree);
+
+    newRoot := spec.BaseDir;
+
+    err = unix.Mount("tmpfs", newRoot, "tmpfs", unix.MS_NOSUID | unix.MS_NODEV, "mode=0755");
+    if (err != nil) {
+        return fmt.Errorf("Failed to mount new root: '%w'.", err);
+    }
+
+    visiblePaths := append(append([]string{}, hostPaths...), getExtraPaths(spec)...);
+    for _, path := range visiblePaths {
+        err = bindHostPath(newRoot, path);
+        if (err != nil) {
+            return err;
+        }
+    }
+
+    err = setupDev(newRoot);
+    if (err != nil) {
+        return err;
+    }
+
+    baseDir := filepath.Join(newRoot, spec.BaseDir);
+    err = os.MkdirAll(baseDir, 0755);
+    if (err != nil) {
+        return fmt.Errorf("Failed to create base dir mount point: '%w'.", err);
+    }
+
+    err = unix.MoveMount(baseTree, "", unix.AT_FDCWD, baseDir, unix.MOVE_MOUNT_F_EMPTY_PATH);
+    if (err != nil) {
+        return fmt.Errorf("Failed to attach base dir: '%w'.", err);
+    }
+
+    // A proc that only shows this PID namespace.
+    procDir := filepath.Join(newRoot, "proc");
+    err = os.Mkdir(procDir, 0755);
+    if (err != nil) {
+        return fmt.Errorf("Failed to create proc mount point: '%w'.", err);
+    }
+
+    err = unix.Mount("proc", procDir, "proc", unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC, "");
+    if (err != nil) {
+        return fmt.Errorf("Failed to mount proc: '%w'.", err);
+    }
+
+    // Switch to the new root and drop the old one (see pivot_root(2) for using the same dir for both arguments).
+    err = os.Chdir(newRoot);
+    if (err != nil) {
+        return fmt.Errorf("Failed to change to new root: '%w'.", err);
+    }
+
+    err = unix.PivotRoot(".", ".");
+    if (err != nil) {
+        return fmt.Errorf("Failed to pivot to new root: '%w'.", err);
+    }
+
+    err = unix.Unmount(".", unix.MNT_DETACH);
+    if (err != nil) {
+        return fmt.Errorf("Failed to unmount old root: '%w'.", err);
+    }
+
+    err = os.Chdir("/");
+    if (err != nil) {
+        return fmt.Errorf("Failed to change to root: '%w'.", err);
+    }
+
+    // Make everything read-only.
+    err = unix.MountSetattr(unix.AT_FDCWD, "/", unix.AT_RECURSIVE, &unix.MountAttr{Attr_set: unix.MOUNT_ATTR_RDONLY});
+    if (err != nil) {
+        return fmt.Errorf("Failed to make filesystem read-only: '%w'.", err);
+    }
+
+    // Then open up the writable dirs (the input dir stays read-only).
+    for _, dir := range []string{spec.OutputDir, spec.WorkDir} {
+        if ((dir == spec.InputDir) || (dir == spec.BaseDir)) {
+            continue;
+        }
+
+        err = unix.Mount(dir, dir, "", unix.MS_BIND | unix.MS_REC, "");
+        if (err != nil) {
+            return fmt.Errorf("Failed to bind writable dir '%s': '%w'.", dir, err);
+        }
+
+        err = unix.MountSetattr(unix.AT_FDCWD, dir, unix.AT_RECURSIVE, &unix.MountAttr{Attr_clr: unix.MOUNT_ATTR_RDONLY});
+        if (err != nil) {
+            return fmt.Errorf("Failed to make dir '%s' writable: '%w'.", dir, err);
+        }
+    }
+
+    return nil;
+}
+
+// Make a host path visible at the same location in the new root.
+// Missing paths are skipped, and symlinks (e.g. /bin on merged-usr systems) are copied instead of mounted.
+func bindHostPath(newRoot string, path string) error {
+    info, err := os.Lstat(path);
+    if (errors.Is(err, os.ErrNotExist)) {
+        return nil;
+    }
+
+    if (err != nil) {
+        return fmt.Errorf("Failed to stat host path '%s': '%w'.", path, err);
+    }
+
+    target := filepath.Join(newRoot, path);
+
+    err = os.MkdirAll(filepath.Dir(target), 0755);
+    if (err != nil) {
+        return fmt.Errorf("Failed to create parent dir for host path '%s': '%w'.", path, err);
+    }
+
+    if ((info.Mode() & os.ModeSymlink) != 0) {
+        link, err := os.Readlink(path);
+        if (err != nil) {
+            return fmt.Errorf("Failed to read host symlink '%s': '%w'.", path, err);
+        }
+
+        err = os.Symlink(link, target);
+        if (err != nil) {
+            return fmt.Errorf("Failed to copy host symlink '%s': '%w'.", path, err);
+        }
+
+        return nil;
+    }
+
+    err = createMountPoint(target, info.IsDir());
+    if (err != nil) {
+        return fmt.Errorf("Failed to create mount point for host path '%s': '%w'.", path, err);
+    }
+
+    err = unix.Mount(path, target, "", unix.MS_BIND | unix.MS_REC, "");
+    if (err != nil) {
+        return fmt.Errorf("Failed to bind host path '%s': '%w'.", path, err);
+    }
+
+    return nil;
+}
+
+// Create a minimal /dev with only a few harmless devices.
+func setupDev(newRoot string) error {
+    devDir := filepath.Join(newRoot, "dev");
+    err := os.Mkdir(devDir, 0755);
+    if (err != nil) {
+        return fmt.Errorf("Failed to create dev dir: '%w'.", err);
+    }
+
+    err = unix.Mount("tmpfs", devDir, "tmpfs", unix.MS_NOSUID | unix.MS_NOEXEC, "mode=0755");
+    if (err != nil) {
+        return fmt.Errorf("Failed to mount dev: '%w'.", err);
+    }
+
+    for _, device := range hostDevices {
+        target := filepath.Join(newRoot, device);
+
+        err = createMountPoint(target, false);
+        if (err != nil) {
+            return fmt.Errorf("Failed to create mount point for device '%s': '%w'.", device, err);
+        }
+
+        err = unix.Mount(device, target, "", unix.MS_BIND, "");
+        if (err != nil) {
+            return fmt.Errorf("Failed to bind device '%s': '%w'.", device, err);
+        }
+    }
+
+    links := map[string]string{
+        "fd": "/proc/self/fd",
+        "stdin": "/proc/self/fd/0",
+        "stdout": "/proc/self/fd/1",
+        "stderr": "/proc/self/fd/2",
+    };
+
+    for name, link := range links {
+        err = os.Symlink(link, filepath.Join(devDir, name));
+        if (err != nil) {
+            return fmt.Errorf("Failed to create dev link '%s': '%w'.", name, err);
+        }
+    }
+
+    return nil;
+}
+
+func createMountPoint(path string, isDir bool) error {
+    if (isDir) {
+        return os.Mkdir(path, 0755);
+    }
+
+    file, err := os.OpenFile(path, os.O_CREATE | os.O_EXCL | os.O_WRONLY, 0644);
+    if (err != nil) {
+        return err;
+    }
+
+    return file.Close();
+}
+
+// Get the extra paths that the grader may need to run:
+// the configured paths and the Python environment (a virtualenv and any extra module dirs).
+// Paths that are already visible (or that are inside the base dir) are skipped.
+func getExtraPaths(spec *Command) []string {
+    candidates := append([]string{}, spec.ExtraPaths...);
+
+    venv := os.Getenv("VIRTUAL_ENV");
+    if (venv != "") {
+        candidates = append(candidates, venv);
+    }
+
+    for _, path := range filepath.SplitList(os.Getenv("PYTHONPATH")) {
+        candidates = append(candidates, path);
+    }
+
+    paths := make([]string, 0, len(candidates));
+    for _, path := range candidates {
+        if (!filepath.IsAbs(path)) {
+            continue;
+        }
+
+        path = filepath.Clean(path);
+
+        visible := isWithinDir(path, spec.BaseDir);
+        for _, visiblePath := range append(append([]string{}, hostPaths...), paths...) {
+            visible = (visible || isWithinDir(path, visiblePath));
+        }
+
+        if (!visible) {
+            paths = append(paths, path);
+        }
+    }
+
+    return paths;
+}
+
+// Check if a path is the same as or inside a dir (both must be clean absolute paths).
+func isWithinDir(path string, dir string) bool {
+    rel, err := filepath.Rel(dir, path);
+    if (err != nil) {
+        return false;
+    }
+
+    return ((rel != "..") && !strings.HasPrefix(rel, "../"));
+}
+
+func setRlimits(spec *Command) error {
+    limits := map[int]uint64{
+        unix.RLIMIT_CORE: 0,
+    };
+
+    if (spec.Limits != nil) {
+        if (spec.Limits.MemoryLimitMB > 0) {
+            limits[unix.RLIMIT_AS] = uint64(spec.Limits.MemoryLimitMB) * 1024 * 1024;
+        }
+
+        // A CPU time backstop (the wall-clock limit is enforced by the parent).
+        maxRuntime := spec.Limits.GetMaxRuntime();
+        if (maxRuntime > 0) {
+            limits[unix.RLIMIT_CPU] = uint64(maxRuntime.Seconds()) + 1;
+        }
+
+        // Processes are counted per user namespace, so this only counts the processes in this sandbox.
+        if (spec.Limits.PIDsLimit > 0) {
+            limits[unix.RLIMIT_NPROC] = uint64(spec.Limits.PIDsLimit);
+        }
+    }
+
+    if (spec.MaxFileSizeMB > 0) {
+        limits[unix.RLIMIT_FSIZE] = uint64(spec.MaxFileSizeMB) * 1024 * 1024;
+    }
+
+    for resource, value := range limits {
+        err := unix.Setrlimit(resource, &unix.Rlimit{Cur: value, Max: value});
+        if (err != nil) {
+            return fmt.Errorf("Failed to set rlimit %d: '%w'.", resource, err);
+        }
+    }
+
+    return nil;
+}
+
+// Clear the capability bounding set, so the command gets no capabilities when it is exec'ed as (namespaced) root.
+func dropCapabilities() error {
+    for capability := 0; capability <= unix.CAP_LAST_CAP; capability++ {
+        err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(capability), 0, 0, 0);
+        if (errors.Is(err, unix.EINVAL)) {
+            // The running kernel does not know about this (or any later) capability.
+            break;
+        }
+
+        if (err != nil) {
+            return fmt.Errorf("Failed to drop capability %d: '%w'.", capability, err);
+        }
+    }
+
+    return nil;
+}
@@ -0,0 +1,212 @@
+package sandbox
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "os/exec"
+    "runtime"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/docker"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/util"
+)
+
+var availableOnce sync.Once;
+var availableErr error;
+
+// Run a command inside the sandbox and return its stdout and stderr.
+// If the command hits its max runtime, it is killed and a *docker.ResourceLimitError is returned.
+func Run(logId log.Loggable, command *Command) (string, string, error) {
+    if (len(command.Args) == 0) {
+        return "", "", fmt.Errorf("No command given to run in the sandbox.");
+    }
+
+    limits := command.Limits;
+    if (limits == nil) {
+        limits = &docker.ResourceLimits{};
+    }
+
+    for _, path := range []string{command.BaseDir, command.InputDir, command.OutputDir, command.WorkDir} {
+        if (!util.IsDir(path)) {
+            return "", "", fmt.Errorf("Sandbox dir does not exist: '%s'.", path);
+        }
+    }
+
+    // The kernel never applies RLIMIT_NPROC to (host) root, so a PIDs limit cannot be enforced.
+    if ((limits.PIDsLimit > 0) && (os.Geteuid() == 0)) {
+        return "", "", fmt.Errorf("A PIDs limit (%d) cannot be enforced in the sandbox when the server is running as root.", limits.PIDsLimit);
+    }
+
+    spec := *command;
+    spec.BaseDir = util.ShouldAbs(spec.BaseDir);
+    spec.InputDir = util.ShouldAbs(spec.InputDir);
+    spec.OutputDir = util.ShouldAbs(spec.OutputDir);
+    spec.WorkDir = util.ShouldAbs(spec.WorkDir);
+    spec.Limits = limits;
+    spec.MaxFileSizeMB = config.DOCKER_SANDBOX_MAX_FILE_SIZE_MB.Get();
+    spec.ExtraPaths = getConfigPaths();
+
+    // Only the base dir is visible inside the sandbox.
+    for _, path := range []string{spec.InputDir, spec.OutputDir, spec.WorkDir} {
+        if (!isWithinDir(path, spec.BaseDir)) {
+            return "", "", fmt.Errorf("Sandbox dir '%s' is not inside the base dir '%s'.", path, spec.BaseDir);
+        }
+    }
+
+    specJSON, err := util.ToJSON(spec);
+    if (err != nil) {
+        return "", "", fmt.Errorf("Failed to serialize sandbox spec: '%w'.", err);
+    }
+
+    // Re-execute this binary, which will set up the sandbox (see init_linux.go) and then exec the command.
+    cmd := exec.Command("/proc/self/exe");
+    cmd.Args = []string{"autograder-sandbox"};
+    cmd.Env = append(getEnv(), fmt.Sprintf("%s=%s", SANDBOX_INIT_ENV, specJSON));
+    cmd.Dir = spec.WorkDir;
+    cmd.SysProcAttr = &syscall.SysProcAttr{
+        Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+                syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+        UidMappings: []syscall.SysProcIDMap{
+            syscall.SysProcIDMap{ContainerID: 0, HostID: os.Getuid(), Size: 1},
+        },
+        GidMappings: []syscall.SysProcIDMap{
+            syscall.SysProcIDMap{ContainerID: 0, HostID: os.Getgid(), Size: 1},
+        },
+        GidMappingsEnableSetgroups: false,
+        Pdeathsig: syscall.SIGKILL,
+    };
+
+    var outBuffer bytes.Buffer;
+    var errBuffer bytes.Buffer;
+    cmd.Stdout = &outBuffer;
+    cmd.Stderr = &errBuffer;
+
+    if (command.OnStdout != nil) {
+        lineWriter := util.NewLineWriter(command.OnStdout);
+        defer lineWriter.Flush();
+
+        cmd.Stdout = io.MultiWriter(&outBuffer, lineWriter);
+    }
+
+    // Pdeathsig is tied to the thread that started the process, so keep this goroutine on one thread until the process is done.
+    runtime.LockOSThread();
+    defer runtime.UnlockOSThread();
+
+    err = cmd.Start();
+    if (err != nil) {
+        return "", "", fmt.Errorf("Failed to start sandbox: '%w'.", err);
+    }
+
+    waitChan := make(chan error, 1);
+    go func() {
+        waitChan <- cmd.Wait();
+    }();
+
+    var timeoutChan <-chan time.Time = nil;
+    maxRuntime := limits.GetMaxRuntime();
+    if (maxRuntime > 0) {
+        timer := time.NewTimer(maxRuntime);
+        defer timer.Stop();
+        timeoutChan = timer.C;
+    }
+
+    var limitErr *docker.ResourceLimitError = nil;
+
+    select {
+        case err = <-waitChan:
+        case <-timeoutChan:
+            log.Warn("Sandboxed grader hit its max runtime, killing it.", logId,
+                    log.NewAttr("max-runtime", maxRuntime.String()));
+
+            // The command is PID 1 in its namespace, so killing it takes down everything in the sandbox.
+            cmd.Process.Kill();
+            <-waitChan;
+
+            limitErr = &docker.ResourceLimitError{
+                Kind: docker.LIMIT_KIND_TIMEOUT,
+                Message: fmt.Sprintf("Grading did not finish within the max runtime (%s).", maxRuntime.String()),
+            };
+    }
+
+    stdout := outBuffer.String();
+    stderr := errBuffer.String();
+
+    log.Debug("Sandbox output.", logId, log.NewAttr("stdout", stdout), log.NewAttr("stderr", stderr));
+
+    if (limitErr != nil) {
+        return stdout, stderr, limitErr;
+    }
+
+    if (err != nil) {
+        var exitErr *exec.ExitError;
+        if (errors.As(err, &exitErr) && (exitErr.ExitCode() == SANDBOX_SETUP_EXIT_CODE)) {
+            return stdout, stderr, fmt.Errorf("Failed to set up sandbox: '%s'.", strings.TrimSpace(stderr));
+        }
+
+        return stdout, stderr, fmt.Errorf("Sandboxed command failed: '%w'.", err);
+    }
+
+    return stdout, stderr, nil;
+}
+
+// Check if sandboxing works on this machine (e.g. unprivileged user namespaces may be disabled).
+// The check is only run once.
+func Available() error {
+    availableOnce.Do(func() {
+        availableErr = checkAvailable();
+    });
+
+    return availableErr;
+}
+
+func checkAvailable() error {
+    tempDir, err := util.MkDirTemp("sandbox-check-");
+    if (err != nil) {
+        return fmt.Errorf("Failed to create temp dir: '%w'.", err);
+    }
+    defer util.RemoveDirent(tempDir);
+
+    _, _, err = Run(nil, &Command{
+        Args: []string{"true"},
+        BaseDir: tempDir,
+        InputDir: tempDir,
+        OutputDir: tempDir,
+        WorkDir: tempDir,
+    });
+
+    return err;
+}
+
+func getConfigPaths() []string {
+    paths := make([]string, 0);
+    for _, path := range strings.Split(config.DOCKER_SANDBOX_PATHS.Get(), ",") {
+        path = strings.TrimSpace(path);
+        if (path == "") {
+            continue;
+        }
+
+        paths = append(paths, util.ShouldAbs(path));
+    }
+
+    return paths;
+}
+
+func getEnv() []string {
+    env := make([]string, 0, len(passthroughEnv));
+    for _, name := range passthroughEnv {
+        value, ok := os.LookupEnv(name);
+        if (ok) {
+            env = append(env, fmt.Sprintf("%s=%s", name, value));
+        }
+    }
+
+    return env;
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package sandbox
+
+import (
+    "fmt"
+
+    "github.com/edulinq/autograder/log"
+)
+
+func Run(logId log.Loggable, command *Command) (string, string, error) {
+    return "", "", Available();
+}
+
+func Available() error {
+    return fmt.Errorf("Sandboxed grading is only supported on Linux.");
+}
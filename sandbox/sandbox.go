@@ -0,0 +1,55 @@
+package sandbox
+
+// Run graders without Docker, but still isolated from the host.
+// On Linux, the command is run inside new user, mount, PID, network, IPC, and UTS namespaces.
+// Inside the sandbox:
+//  - The root is a new (empty) filesystem that only has the base dir, system dirs (e.g. /usr), and a minimal /etc and /dev.
+//  - The entire filesystem is read-only, except for the output and work dirs.
+//  - There is no network access (not even loopback).
+//  - The process has no capabilities, and dangerous syscalls are blocked with seccomp.
+//  - Resource limits (including the number of processes and the size of files) are enforced with rlimits and a wall-clock timer.
+
+import (
+    "github.com/edulinq/autograder/docker"
+)
+
+// The environment variable used to pass the sandbox spec to the re-executed binary.
+const SANDBOX_INIT_ENV = "AUTOGRADER_SANDBOX_INIT";
+
+// The exit code used when the sandbox itself (not the command) fails to set up.
+const SANDBOX_SETUP_EXIT_CODE = 125;
+
+// Environment variables passed through to the sandboxed command.
+// All other variables (which may contain secrets) are dropped.
+var passthroughEnv = []string{
+    "PATH",
+    "LANG",
+    "LC_ALL",
+    "PYTHONPATH",
+    "VIRTUAL_ENV",
+};
+
+type Command struct {
+    // The command (and arguments) to run.
+    Args []string `json:"args"`
+
+    // The dir that holds the input, output, and work dirs.
+    // It stays visible (read-only), but the rest of the host (other than some system dirs) is hidden.
+    BaseDir string `json:"base-dir"`
+    // Read-only.
+    InputDir string `json:"input-dir"`
+    // Writable.
+    OutputDir string `json:"output-dir"`
+    // Writable, and the command's working directory.
+    WorkDir string `json:"work-dir"`
+
+    // The max runtime, memory limit, and PIDs limit are enforced (the CPU limit is not).
+    Limits *docker.ResourceLimits `json:"limits,omitempty"`
+    // The largest file that can be written (set from config.DOCKER_SANDBOX_MAX_FILE_SIZE_MB when run).
+    MaxFileSizeMB int `json:"max-file-size,omitempty"`
+    // Extra host paths that are visible (read-only) in the sandbox (set from config.DOCKER_SANDBOX_PATHS when run).
+    ExtraPaths []string `json:"extra-paths,omitempty"`
+
+    // If not nil, called with each line of stdout as the command runs.
+    OnStdout func(string) `json:"-"`
+}
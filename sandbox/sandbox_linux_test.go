@@ -0,0 +1,255 @@
+package sandbox
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/docker"
+    "github.com/edulinq/autograder/util"
+)
+
+func TestSandboxFilesystem(test *testing.T) {
+    command := makeTestCommand(test, `
+        echo "out" > "${OUTPUT_DIR}/result.txt" || exit 1
+        echo "work" > "${WORK_DIR}/work.txt" || exit 2
+        touch "${INPUT_DIR}/input.txt" 2> /dev/null && exit 3
+        touch "${BASE_DIR}/base.txt" 2> /dev/null && exit 4
+        touch /sandbox-root.txt 2> /dev/null && exit 5
+        test -e "${SIBLING_DIR}" && exit 6
+        cat "${INPUT_DIR}/submission.txt"
+    `);
+
+    stdout, stderr, err := Run(nil, command);
+    if (err != nil) {
+        test.Fatalf("Failed to run sandbox: '%v'. Stderr: '%s'.", err, stderr);
+    }
+
+    if (strings.TrimSpace(stdout) != "submission") {
+        test.Fatalf("Unexpected stdout: '%s'.", stdout);
+    }
+
+    text, err := util.ReadFile(filepath.Join(command.OutputDir, "result.txt"));
+    if (err != nil) {
+        test.Fatalf("Failed to read output file: '%v'.", err);
+    }
+
+    if (strings.TrimSpace(text) != "out") {
+        test.Fatalf("Unexpected output file contents: '%s'.", text);
+    }
+}
+
+// Only the base dir and system dirs are visible, not the server's own files (e.g. its database and course configs).
+func TestSandboxHiddenHost(test *testing.T) {
+    secretDir, err := util.MkDirTemp("sandbox-secret-");
+    if (err != nil) {
+        test.Fatalf("Failed to create temp dir: '%v'.", err);
+    }
+    defer util.RemoveDirent(secretDir);
+
+    secretPath := filepath.Join(secretDir, "users.json");
+    err = util.WriteFile("secret\n", secretPath);
+    if (err != nil) {
+        test.Fatalf("Failed to write secret: '%v'.", err);
+    }
+
+    command := makeTestCommand(test, `
+        cat "${SECRET_PATH}" 2> /dev/null && exit 1
+        test -e "${SECRET_DIR}" && exit 2
+        test -e "${ROOT_DIR}" && exit 3
+        test -e /etc/shadow && exit 4
+        test -e /root && exit 5
+        test -e /home && exit 6
+        ls /dev | grep -q -v -E '^(full|null|random|urandom|zero|fd|stdin|stdout|stderr)$' && exit 7
+        test "${HOME}" = "${WORK_DIR}" || exit 8
+        ls /usr/bin > /dev/null || exit 9
+        exit 0
+    `);
+
+    replacer := strings.NewReplacer(
+        "${SECRET_PATH}", secretPath,
+        "${SECRET_DIR}", secretDir,
+        "${ROOT_DIR}", util.RootDirForTesting(),
+    );
+    command.Args[2] = replacer.Replace(command.Args[2]);
+
+    stdout, stderr, err := Run(nil, command);
+    if (err != nil) {
+        test.Fatalf("Host was not hidden: '%v'. Stdout: '%s', Stderr: '%s'.", err, stdout, stderr);
+    }
+}
+
+func TestSandboxExtraPaths(test *testing.T) {
+    extraDir, err := util.MkDirTemp("sandbox-extra-");
+    if (err != nil) {
+        test.Fatalf("Failed to create temp dir: '%v'.", err);
+    }
+    defer util.RemoveDirent(extraDir);
+
+    err = util.WriteFile("extra\n", filepath.Join(extraDir, "extra.txt"));
+    if (err != nil) {
+        test.Fatalf("Failed to write extra file: '%v'.", err);
+    }
+
+    defer config.DOCKER_SANDBOX_PATHS.Set(config.DOCKER_SANDBOX_PATHS.Get());
+    config.DOCKER_SANDBOX_PATHS.Set(" /ZZZ/missing , " + extraDir);
+
+    command := makeTestCommand(test, `
+        touch "${EXTRA_DIR}/new.txt" 2> /dev/null && exit 1
+        cat "${EXTRA_DIR}/extra.txt"
+    `);
+    command.Args[2] = strings.ReplaceAll(command.Args[2], "${EXTRA_DIR}", extraDir);
+
+    stdout, stderr, err := Run(nil, command);
+    if (err != nil) {
+        test.Fatalf("Failed to run sandbox: '%v'. Stderr: '%s'.", err, stderr);
+    }
+
+    if (strings.TrimSpace(stdout) != "extra") {
+        test.Fatalf("Unexpected stdout: '%s'.", stdout);
+    }
+}
+
+func TestSandboxIsolation(test *testing.T) {
+    command := makeTestCommand(test, `
+        test "$$" = "1" || exit 1
+        test "$(ls /proc | grep -c '^[0-9]')" -lt 10 || exit 2
+        grep -v -q -E '^ *(lo|Inter|face)' /proc/net/dev && exit 3
+        test "$(grep '^CapEff' /proc/self/status | awk '{print $2}')" = "0000000000000000" || exit 4
+        test -z "${AUTOGRADER_SECRET}" || exit 5
+        unshare -U true 2> /dev/null && exit 6
+        exit 0
+    `);
+
+    os.Setenv("AUTOGRADER_SECRET", "secret");
+    defer os.Unsetenv("AUTOGRADER_SECRET");
+
+    _, stderr, err := Run(nil, command);
+    if (err != nil) {
+        test.Fatalf("Sandbox was not isolated: '%v'. Stderr: '%s'.", err, stderr);
+    }
+}
+
+func TestSandboxTimeout(test *testing.T) {
+    command := makeTestCommand(test, "sleep 30");
+    command.Limits = &docker.ResourceLimits{MaxRuntimeSecs: 1};
+
+    _, _, err := Run(nil, command);
+
+    var limitErr *docker.ResourceLimitError;
+    if (!errors.As(err, &limitErr)) {
+        test.Fatalf("Did not get a resource limit error, got: '%v'.", err);
+    }
+
+    if (limitErr.Kind != docker.LIMIT_KIND_TIMEOUT) {
+        test.Fatalf("Unexpected limit kind: '%s'.", limitErr.Kind);
+    }
+}
+
+func TestSandboxMaxFileSize(test *testing.T) {
+    defer config.DOCKER_SANDBOX_MAX_FILE_SIZE_MB.Set(config.DOCKER_SANDBOX_MAX_FILE_SIZE_MB.Get());
+    config.DOCKER_SANDBOX_MAX_FILE_SIZE_MB.Set(1);
+
+    command := makeTestCommand(test, `
+        head -c 2097152 /dev/zero > "${WORK_DIR}/big.bin" && exit 1
+        test "$(wc -c < "${WORK_DIR}/big.bin")" -le 1048576 || exit 2
+        exit 0
+    `);
+
+    _, stderr, err := Run(nil, command);
+    if (err != nil) {
+        test.Fatalf("File size was not limited: '%v'. Stderr: '%s'.", err, stderr);
+    }
+}
+
+func TestSandboxPIDsLimit(test *testing.T) {
+    command := makeTestCommand(test, `
+        for i in 1 2 3 4 5 6 7 8 9 10; do
+            sleep 5 &
+        done
+        exit 0
+    `);
+    command.Limits = &docker.ResourceLimits{PIDsLimit: 5};
+
+    _, stderr, err := Run(nil, command);
+
+    // The limit cannot be enforced for root, so it must be rejected.
+    if (os.Geteuid() == 0) {
+        if ((err == nil) || !strings.Contains(err.Error(), "running as root")) {
+            test.Fatalf("PIDs limit was not rejected for root: '%v'.", err);
+        }
+
+        return;
+    }
+
+    if (err == nil) {
+        test.Fatalf("PIDs limit was not enforced.");
+    }
+
+    if (!strings.Contains(strings.ToLower(stderr), "fork")) {
+        test.Fatalf("Command did not fail to fork: '%v'. Stderr: '%s'.", err, stderr);
+    }
+}
+
+func TestSandboxCommandFailure(test *testing.T) {
+    command := makeTestCommand(test, "exit 3");
+
+    _, _, err := Run(nil, command);
+    if (err == nil) {
+        test.Fatalf("Did not get an error for a failed command.");
+    }
+}
+
+// Make a command that runs a shell script in a standard grading dir (next to a sibling grading dir).
+func makeTestCommand(test *testing.T, script string) *Command {
+    err := Available();
+    if (err != nil) {
+        test.Skipf("Sandboxing is not available: '%v'.", err);
+    }
+
+    parentDir, err := util.MkDirTemp("sandbox-test-");
+    if (err != nil) {
+        test.Fatalf("Failed to create temp dir: '%v'.", err);
+    }
+    test.Cleanup(func() { util.RemoveDirent(parentDir) });
+
+    baseDir := filepath.Join(parentDir, "grading");
+    siblingDir := filepath.Join(parentDir, "sibling");
+
+    for _, dir := range []string{baseDir, siblingDir} {
+        err = util.MkDir(dir);
+        if (err != nil) {
+            test.Fatalf("Failed to create dir: '%v'.", err);
+        }
+    }
+
+    inputDir, outputDir, workDir, err := common.CreateStandardGradingDirs(baseDir);
+    if (err != nil) {
+        test.Fatalf("Failed to create grading dirs: '%v'.", err);
+    }
+
+    err = util.WriteFile("submission\n", filepath.Join(inputDir, "submission.txt"));
+    if (err != nil) {
+        test.Fatalf("Failed to write submission: '%v'.", err);
+    }
+
+    replacer := strings.NewReplacer(
+        "${BASE_DIR}", baseDir,
+        "${INPUT_DIR}", inputDir,
+        "${OUTPUT_DIR}", outputDir,
+        "${WORK_DIR}", workDir,
+        "${SIBLING_DIR}", siblingDir,
+    );
+
+    return &Command{
+        Args: []string{"sh", "-c", replacer.Replace(script)},
+        BaseDir: baseDir,
+        InputDir: inputDir,
+        OutputDir: outputDir,
+        WorkDir: workDir,
+    };
+}
@@ -0,0 +1,142 @@
+package sandbox
+
+import (
+    "fmt"
+    "unsafe"
+
+    "golang.org/x/sys/unix"
+)
+
+// Seccomp constants that are not in x/sys/unix.
+const (
+    SECCOMP_RET_ALLOW = 0x7fff0000
+    SECCOMP_RET_ERRNO = 0x00050000
+    SECCOMP_RET_KILL_PROCESS = 0x80000000
+
+    // Offsets into struct seccomp_data.
+    seccompDataNR = 0
+    seccompDataArch = 4
+    seccompDataArg0 = 16
+)
+
+// Syscalls that could be used to escape or poke at the host.
+// Blocked syscalls fail with EPERM.
+var commonBlockedSyscalls = []uintptr{
+    unix.SYS_ACCT,
+    unix.SYS_ADD_KEY,
+    unix.SYS_BPF,
+    unix.SYS_CHROOT,
+    unix.SYS_CLOCK_SETTIME,
+    unix.SYS_DELETE_MODULE,
+    unix.SYS_FINIT_MODULE,
+    unix.SYS_FSCONFIG,
+    unix.SYS_FSMOUNT,
+    unix.SYS_FSOPEN,
+    unix.SYS_FSPICK,
+    unix.SYS_INIT_MODULE,
+    unix.SYS_KEXEC_FILE_LOAD,
+    unix.SYS_KEXEC_LOAD,
+    unix.SYS_KEYCTL,
+    unix.SYS_MOUNT,
+    unix.SYS_MOUNT_SETATTR,
+    unix.SYS_MOVE_MOUNT,
+    unix.SYS_OPEN_BY_HANDLE_AT,
+    unix.SYS_OPEN_TREE,
+    unix.SYS_PERF_EVENT_OPEN,
+    unix.SYS_PIVOT_ROOT,
+    unix.SYS_PROCESS_VM_READV,
+    unix.SYS_PROCESS_VM_WRITEV,
+    unix.SYS_PTRACE,
+    unix.SYS_REBOOT,
+    unix.SYS_REQUEST_KEY,
+    unix.SYS_SETNS,
+    unix.SYS_SETTIMEOFDAY,
+    unix.SYS_SWAPOFF,
+    unix.SYS_SWAPON,
+    unix.SYS_SYSLOG,
+    unix.SYS_UMOUNT2,
+    unix.SYS_UNSHARE,
+    unix.SYS_USERFAULTFD,
+};
+
+// Namespace flags that may not be passed to clone().
+const blockedCloneFlags = unix.CLONE_NEWUSER | unix.CLONE_NEWNS | unix.CLONE_NEWPID | unix.CLONE_NEWNET |
+        unix.CLONE_NEWIPC | unix.CLONE_NEWUTS | unix.CLONE_NEWCGROUP;
+
+func installSeccompFilter() error {
+    filter, err := buildSeccompFilter();
+    if (err != nil) {
+        return err;
+    }
+
+    program := unix.SockFprog{
+        Len: uint16(len(filter)),
+        Filter: &filter[0],
+    };
+
+    err = unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&program)), 0, 0);
+    if (err != nil) {
+        return fmt.Errorf("Failed to install seccomp filter: '%w'.", err);
+    }
+
+    return nil;
+}
+
+func buildSeccompFilter() ([]unix.SockFilter, error) {
+    if (seccompAuditArch == 0) {
+        return nil, fmt.Errorf("Seccomp filtering is not supported on this architecture.");
+    }
+
+    blocked := append(commonBlockedSyscalls, archBlockedSyscalls...);
+    deny := uint32(SECCOMP_RET_ERRNO | uint32(unix.EPERM));
+
+    filter := []unix.SockFilter{
+        // Kill anything using a different syscall ABI (where the numbers below would not make sense).
+        bpfStmt(unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, seccompDataArch),
+        bpfJump(unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, seccompAuditArch, 1, 0),
+        bpfStmt(unix.BPF_RET | unix.BPF_K, SECCOMP_RET_KILL_PROCESS),
+
+        bpfStmt(unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, seccompDataNR),
+    };
+
+    // Syscall numbers at or above this are from a different ABI (e.g. x32).
+    if (seccompMaxSyscall > 0) {
+        filter = append(filter,
+            bpfJump(unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K, seccompMaxSyscall, 0, 1),
+            bpfStmt(unix.BPF_RET | unix.BPF_K, deny),
+        );
+    }
+
+    for _, number := range blocked {
+        filter = append(filter,
+            bpfJump(unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, uint32(number), 0, 1),
+            bpfStmt(unix.BPF_RET | unix.BPF_K, deny),
+        );
+    }
+
+    // clone3() passes its flags in memory (which cannot be inspected), so force libc to fall back to clone().
+    filter = append(filter,
+        bpfJump(unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, uint32(unix.SYS_CLONE3), 0, 1),
+        bpfStmt(unix.BPF_RET | unix.BPF_K, uint32(SECCOMP_RET_ERRNO | uint32(unix.ENOSYS))),
+    );
+
+    // Block clone() calls that create namespaces.
+    filter = append(filter,
+        bpfJump(unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, uint32(unix.SYS_CLONE), 0, 3),
+        bpfStmt(unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, seccompDataArg0),
+        bpfJump(unix.BPF_JMP | unix.BPF_JSET | unix.BPF_K, uint32(blockedCloneFlags), 0, 1),
+        bpfStmt(unix.BPF_RET | unix.BPF_K, deny),
+    );
+
+    filter = append(filter, bpfStmt(unix.BPF_RET | unix.BPF_K, SECCOMP_RET_ALLOW));
+
+    return filter, nil;
+}
+
+func bpfStmt(code uint16, k uint32) unix.SockFilter {
+    return unix.SockFilter{Code: code, K: k};
+}
+
+func bpfJump(code uint16, k uint32, jumpTrue uint8, jumpFalse uint8) unix.SockFilter {
+    return unix.SockFilter{Code: code, Jt: jumpTrue, Jf: jumpFalse, K: k};
+}
@@ -0,0 +1,15 @@
+package sandbox
+
+import (
+    "golang.org/x/sys/unix"
+)
+
+const seccompAuditArch = unix.AUDIT_ARCH_X86_64;
+
+// x32 syscalls have this bit set.
+const seccompMaxSyscall = 0x40000000;
+
+var archBlockedSyscalls = []uintptr{
+    unix.SYS_IOPERM,
+    unix.SYS_IOPL,
+};
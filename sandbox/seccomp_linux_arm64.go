@@ -0,0 +1,11 @@
+package sandbox
+
+import (
+    "golang.org/x/sys/unix"
+)
+
+const seccompAuditArch = unix.AUDIT_ARCH_AARCH64;
+
+const seccompMaxSyscall = 0;
+
+var archBlockedSyscalls = []uintptr{};
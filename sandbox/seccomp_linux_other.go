@@ -0,0 +1,10 @@
+//go:build linux && !amd64 && !arm64
+
+package sandbox
+
+// Seccomp filters are only built for amd64 and arm64.
+const seccompAuditArch = 0;
+
+const seccompMaxSyscall = 0;
+
+var archBlockedSyscalls = []uintptr{};
@@ -12,26 +12,26 @@ import (
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/util"
+    "github.com/edulinq/autograder/webhook"
 )
 
 const LOCK_COMMENT string = "__lock__";
 
+// Compute the final scores for an assignment (applying the late policy) and store them in the database.
+// If the assignment is in an LMS, then the final scores are also uploaded to the LMS.
+// On a dry run, nothing is stored or uploaded.
 func FullAssignmentScoringAndUpload(assignment *model.Assignment, dryRun bool) error {
-    if (assignment.GetCourse().GetLMSAdapter() == nil) {
-        return fmt.Errorf("Assignment's course has no LMS info associated with it.");
-    }
-
     users, err := db.GetUsers(assignment.GetCourse());
     if (err != nil) {
         return fmt.Errorf("Failed to fetch autograder users: '%w'.", err);
     }
 
-    lmsScores, err := lms.FetchAssignmentScores(assignment.GetCourse(), assignment.GetLMSID());
+    dueDate, err := GetScoringDueDate(assignment);
     if (err != nil) {
-        return fmt.Errorf("Could not fetch LMS grades: '%w'.", err);
+        return fmt.Errorf("Failed to get scoring due date: '%w'.", err);
     }
 
-    scoringInfos, err := db.GetExistingScoringInfos(assignment, model.RoleStudent);
+    scoringInfos, err := db.GetExistingScoringInfos(assignment, model.RoleStudent, dueDate);
     if (err != nil) {
         return fmt.Errorf("Failed to get scoring information: '%w'.", err);
     }
@@ -41,9 +41,32 @@ func FullAssignmentScoringAndUpload(assignment *model.Assignment, dryRun bool) e
         return fmt.Errorf("Failed to apply late policy: '%w'.", err);
     }
 
+    if (dryRun) {
+        log.Info("Dry Run: Skipping saving of final scores.", assignment, log.NewAttr("scores", scoringInfos));
+    } else {
+        err = db.SaveFinalScores(assignment, scoringInfos);
+        if (err != nil) {
+            return fmt.Errorf("Failed to save final scores: '%w'.", err);
+        }
+    }
+
+    if ((assignment.GetCourse().GetLMSAdapter() == nil) || (assignment.GetLMSID() == "")) {
+        log.Debug("Assignment is not in an LMS, skipping upload of final scores.", assignment);
+        return nil;
+    }
+
+    lmsScores, err := lms.FetchAssignmentScores(assignment.GetCourse(), assignment.GetLMSID());
+    if (err != nil) {
+        return fmt.Errorf("Could not fetch LMS grades: '%w'.", err);
+    }
+
     err = computeFinalScores(assignment, users, scoringInfos, lmsScores, dryRun);
     if (err != nil) {
-        return fmt.Errorf("Failed to apply late policy: '%w'.", err);
+        return fmt.Errorf("Failed to upload final scores: '%w'.", err);
+    }
+
+    if (!dryRun) {
+        webhook.EmitScoringUpload(assignment, scoringInfos);
     }
 
     return nil;
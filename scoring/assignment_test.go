@@ -0,0 +1,274 @@
+package scoring
+
+import (
+    "testing"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+// Score an assignment in a course without an LMS using the assignment's own due date and max points.
+func TestAssignmentScoringNoLMS(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+    course.LMS = nil;
+
+    assignment := course.Assignments["hw0"];
+
+    scoringInfos, err := db.GetExistingScoringInfos(assignment, model.RoleStudent, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scoring infos: '%v'.", err);
+    }
+
+    if (len(scoringInfos) == 0) {
+        test.Fatalf("Test assignment has no student submissions.");
+    }
+
+    // Make every submission one day late.
+    var dueDate time.Time;
+    for _, scoringInfo := range scoringInfos {
+        submissionTime := scoringInfo.SubmissionTime.MustTime();
+        if (dueDate.IsZero() || submissionTime.Before(dueDate)) {
+            dueDate = submissionTime;
+        }
+    }
+
+    assignment.DueDate = common.TimestampFromTime(dueDate.Add(-time.Hour));
+    assignment.MaxPoints = 10.0;
+    assignment.LatePolicy = &model.LateGradingPolicy{Type: model.PercentagePenalty, Penalty: 0.1};
+
+    err = FullAssignmentScoringAndUpload(assignment, true);
+    if (err != nil) {
+        test.Fatalf("Assignment scoring (dryrun) failed: '%v'.", err);
+    }
+
+    finalScores, err := db.GetFinalScores(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get final scores after dry run: '%v'.", err);
+    }
+
+    if (len(finalScores) != 0) {
+        test.Fatalf("Dry run saved final scores: '%s'.", util.MustToJSONIndent(finalScores));
+    }
+
+    err = FullAssignmentScoringAndUpload(assignment, false);
+    if (err != nil) {
+        test.Fatalf("Assignment scoring failed: '%v'.", err);
+    }
+
+    finalScores, err = db.GetFinalScores(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get final scores: '%v'.", err);
+    }
+
+    if (len(finalScores) != len(scoringInfos)) {
+        test.Fatalf("Unexpected number of final scores. Expected: %d, Actual: %d.", len(scoringInfos), len(finalScores));
+    }
+
+    for email, finalScore := range finalScores {
+        if (finalScore.NumDaysLate < 1) {
+            test.Errorf("User '%s' is not late: '%s'.", email, util.MustToJSONIndent(finalScore));
+            continue;
+        }
+
+        expected := max(0.0, finalScore.RawScore - float64(finalScore.NumDaysLate));
+        if (!util.IsClose(expected, finalScore.Score)) {
+            test.Errorf("User '%s' has an unexpected score. Expected: %f, Actual: %f.", email, expected, finalScore.Score);
+            continue;
+        }
+    }
+}
+
+func TestAssignmentScoringNoDueDate(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+    course.LMS = nil;
+
+    assignment := course.Assignments["hw0"];
+    assignment.LatePolicy = &model.LateGradingPolicy{Type: model.BaselinePolicy};
+
+    err := FullAssignmentScoringAndUpload(assignment, false);
+    if (err == nil) {
+        test.Fatalf("Did not get an error when scoring an assignment without a due date.");
+    }
+}
+
+// Apply a late days policy in a course without an LMS (late days are only kept in the database).
+func TestAssignmentScoringLateDaysNoLMS(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+    course.LMS = nil;
+
+    assignment := course.Assignments["hw0"];
+
+    scoringInfos, err := db.GetExistingScoringInfos(assignment, model.RoleStudent, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scoring infos: '%v'.", err);
+    }
+
+    // Make every submission one day late.
+    var dueDate time.Time;
+    for _, scoringInfo := range scoringInfos {
+        submissionTime := scoringInfo.SubmissionTime.MustTime();
+        if (dueDate.IsZero() || submissionTime.Before(dueDate)) {
+            dueDate = submissionTime;
+        }
+    }
+
+    assignment.DueDate = common.TimestampFromTime(dueDate.Add(-time.Hour));
+    assignment.MaxPoints = 10.0;
+    assignment.LatePolicy = &model.LateGradingPolicy{
+        Type: model.LateDays,
+        Penalty: 0.1,
+        RejectAfterDays: 5,
+        MaxLateDays: 2,
+        TotalLateDays: 3,
+    };
+
+    // Scoring twice should not use any more late days.
+    for i := 0; i < 2; i++ {
+        err = FullAssignmentScoringAndUpload(assignment, false);
+        if (err != nil) {
+            test.Fatalf("Assignment scoring failed: '%v'.", err);
+        }
+    }
+
+    balances, err := db.GetLateDays(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get late days: '%v'.", err);
+    }
+
+    if (len(balances) != len(scoringInfos)) {
+        test.Fatalf("Unexpected number of late day balances. Expected: %d, Actual: %d.", len(scoringInfos), len(balances));
+    }
+
+    for email, balance := range balances {
+        used := balance.AllocatedDays["hw0"];
+        if ((used < 1) || ((used + balance.AvailableDays) != 3)) {
+            test.Errorf("User '%s' has an unexpected balance: '%s'.", email, util.MustToJSONIndent(balance));
+        }
+
+        history, err := db.GetLateDaysHistory(course, email);
+        if (err != nil) {
+            test.Fatalf("Failed to get late days history: '%v'.", err);
+        }
+
+        // The initial balance and one allocation.
+        if (len(history) != 2) {
+            test.Errorf("User '%s' has an unexpected history: '%s'.", email, util.MustToJSONIndent(history));
+        }
+    }
+
+    // Move the due date so that no submissions are late, the late days should be reclaimed.
+    assignment.DueDate = common.TimestampFromTime(time.Now().Add(time.Hour));
+
+    err = FullAssignmentScoringAndUpload(assignment, false);
+    if (err != nil) {
+        test.Fatalf("Assignment scoring (after due date change) failed: '%v'.", err);
+    }
+
+    balances, err = db.GetLateDays(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get late days (after due date change): '%v'.", err);
+    }
+
+    for email, balance := range balances {
+        if ((balance.AvailableDays != 3) || (len(balance.AllocatedDays) != 0)) {
+            test.Errorf("User '%s' did not get late days back: '%s'.", email, util.MustToJSONIndent(balance));
+        }
+    }
+}
+
+// When students select their own late days, only the selected days (capped by how late the submission is) are used.
+func TestAssignmentScoringLateDaysSelected(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+    course.LMS = nil;
+
+    assignment := course.Assignments["hw0"];
+
+    scoringInfos, err := db.GetExistingScoringInfos(assignment, model.RoleStudent, nil);
+    if (err != nil) {
+        test.Fatalf("Failed to get scoring infos: '%v'.", err);
+    }
+
+    // Make every submission at least one day late.
+    var dueDate time.Time;
+    for _, scoringInfo := range scoringInfos {
+        submissionTime := scoringInfo.SubmissionTime.MustTime();
+        if (dueDate.IsZero() || submissionTime.Before(dueDate)) {
+            dueDate = submissionTime;
+        }
+    }
+
+    assignment.DueDate = common.TimestampFromTime(dueDate.Add(-time.Hour));
+    assignment.MaxPoints = 10.0;
+    assignment.LatePolicy = &model.LateGradingPolicy{
+        Type: model.LateDays,
+        Penalty: 0.1,
+        RejectAfterDays: 5,
+        MaxLateDays: 2,
+        TotalLateDays: 3,
+        StudentSelectedLateDays: true,
+    };
+
+    // Only the student selects late days, everyone else saves theirs.
+    balance := model.NewLateDaysBalance("student@test.com", 0);
+    events := []*model.LateDaysEvent{
+        balance.Adjust(3, model.LATE_DAYS_SOURCE_INITIAL, "", ""),
+        balance.Select("hw0", 1, "student@test.com"),
+    };
+
+    err = db.SaveLateDays(course, map[string]*model.LateDaysBalance{balance.User: balance}, events);
+    if (err != nil) {
+        test.Fatalf("Failed to save late days: '%v'.", err);
+    }
+
+    err = FullAssignmentScoringAndUpload(assignment, false);
+    if (err != nil) {
+        test.Fatalf("Assignment scoring failed: '%v'.", err);
+    }
+
+    balances, err := db.GetLateDays(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get late days: '%v'.", err);
+    }
+
+    for email, balance := range balances {
+        expectedUsed := 0;
+        if (email == "student@test.com") {
+            expectedUsed = 1;
+        }
+
+        if ((balance.AllocatedDays["hw0"] != expectedUsed) || (balance.AvailableDays != (3 - expectedUsed))) {
+            test.Errorf("User '%s' has an unexpected balance: '%s'.", email, util.MustToJSONIndent(balance));
+        }
+    }
+
+    scores, err := db.GetFinalScores(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get final scores: '%v'.", err);
+    }
+
+    for email, score := range scores {
+        expectedUsed := 0;
+        if (email == "student@test.com") {
+            expectedUsed = 1;
+        }
+
+        if (score.LateDayUsage != expectedUsed) {
+            test.Errorf("User '%s' has unexpected late day usage. Expected: %d, Actual: %d.", email, expectedUsed, score.LateDayUsage);
+        }
+    }
+}
@@ -1,34 +1,45 @@
 package scoring
 
 import (
+    "errors"
     "fmt"
 
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
 )
 
+// Score (and upload) every assignment in a course.
+// A failure for one assignment does not stop the other assignments from being scored,
+// all the failures are returned together.
+// Assignments that are not linked to the LMS are still scored (and stored locally),
+// but they are not part of the upload so their failures are only logged as warnings.
 func FullCourseScoringAndUpload(course *model.Course, dryRun bool) error {
     assignments := course.GetSortedAssignments();
 
     log.Debug("Beginning full scoring for course.", course, log.NewAttr("dry-run", dryRun));
 
-    for i, assignment := range assignments {
-        if (assignment.GetLMSID() == "") {
-            log.Warn("Assignment has no LMS id, skipping scoring.", course, assignment);
-            continue;
-        }
+    var errs error = nil;
 
+    for i, assignment := range assignments {
         log.Debug("Scoring course assignment.", course, assignment,
                 log.NewAttr("index", i),
                 log.NewAttr("dry-run", dryRun));
 
         err := FullAssignmentScoringAndUpload(assignment, dryRun);
-        if (err != nil) {
-            return fmt.Errorf("Failed to grade assignment '%s' for course '%s': '%w'.", course.GetID(), assignment.GetID(), err);
+        if (err == nil) {
+            continue;
         }
+
+        if ((course.GetLMSAdapter() == nil) || (assignment.GetLMSID() == "")) {
+            log.Warn("Failed to score assignment that is not in the LMS, skipping.", err, course, assignment, log.NewAttr("dry-run", dryRun));
+            continue;
+        }
+
+        log.Error("Failed to score assignment, skipping.", err, course, assignment, log.NewAttr("dry-run", dryRun));
+        errs = errors.Join(errs, fmt.Errorf("Failed to grade assignment '%s' for course '%s': '%w'.", assignment.GetID(), course.GetID(), err));
     }
 
     log.Debug("Finished full scoring for course.", course, log.NewAttr("dry-run", dryRun));
 
-    return nil;
+    return errs;
 }
@@ -59,3 +59,68 @@ func TestCourseScoringEmptyStudent(test *testing.T) {
         test.Fatalf("Course score upload failed: '%v'.", err);
     }
 }
+
+// A failure for one assignment should not stop the other assignments from being scored.
+func TestCourseScoringAssignmentFailure(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+
+    // A late policy without a due date cannot be applied (the LMS does not know about this assignment).
+    // Sorted before all the other assignments.
+    badAssignment := &model.Assignment{
+        ID: "aaa-bad",
+        SortID: "0",
+        LMSID: "ZZZ",
+        LatePolicy: &model.LateGradingPolicy{Type: model.BaselinePolicy},
+        Course: course,
+    };
+
+    course.Assignments[badAssignment.ID] = badAssignment;
+    defer delete(course.Assignments, badAssignment.ID);
+
+    err := FullCourseScoringAndUpload(course, false);
+    if (err == nil) {
+        test.Fatalf("Course scoring did not return an error for the bad assignment.");
+    }
+
+    checkCourseScoringFinalScores(test);
+}
+
+// Failures for assignments that are not in the LMS do not fail the course upload.
+func TestCourseScoringUnlinkedAssignmentFailure(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+
+    course := db.MustGetTestCourse();
+
+    // Sorted before all the other assignments.
+    unlinkedAssignment := &model.Assignment{
+        ID: "aaa-unlinked",
+        SortID: "0",
+        LatePolicy: &model.LateGradingPolicy{Type: model.BaselinePolicy},
+        Course: course,
+    };
+
+    course.Assignments[unlinkedAssignment.ID] = unlinkedAssignment;
+    defer delete(course.Assignments, unlinkedAssignment.ID);
+
+    err := FullCourseScoringAndUpload(course, false);
+    if (err != nil) {
+        test.Fatalf("Course scoring failed because of an assignment that is not in the LMS: '%v'.", err);
+    }
+
+    checkCourseScoringFinalScores(test);
+}
+
+func checkCourseScoringFinalScores(test *testing.T) {
+    scores, err := db.GetFinalScores(db.MustGetTestAssignment());
+    if (err != nil) {
+        test.Fatalf("Failed to get final scores: '%v'.", err);
+    }
+
+    if (len(scores) == 0) {
+        test.Fatalf("Assignment after the bad assignment was not scored.");
+    }
+}
@@ -4,9 +4,11 @@ import (
     "fmt"
     "math"
     "strings"
+    "sync"
     "time"
 
     "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
     "github.com/edulinq/autograder/lms"
     "github.com/edulinq/autograder/lms/lmstypes"
     "github.com/edulinq/autograder/log"
@@ -16,6 +18,9 @@ import (
 
 const LATE_DAYS_STRUCT_VERSION = "1.0.0"
 
+// Late day balance locks keyed by course ID (see GetLateDaysLock()).
+var lateDaysLocks sync.Map;
+
 type LateDaysInfo struct {
     AvailableDays int `json:"available-days"`
     UploadTime common.Timestamp `json:"upload-time"`
@@ -29,7 +34,8 @@ type LateDaysInfo struct {
     LMSCommentAuthorID string `json:"-"`
 }
 
-// This assumes that all assignments are in the LMS.
+// The due date and max points come from the LMS (when the assignment is in an LMS),
+// falling back to the assignment's own config.
 func ApplyLatePolicy(
         assignment *model.Assignment,
         users map[string]*model.User,
@@ -47,16 +53,25 @@ func ApplyLatePolicy(
         return nil;
     }
 
-    lmsAssignment, err := lms.FetchAssignment(assignment.GetCourse(), assignment.GetLMSID());
+    dueDate, maxPoints, err := getDueDateAndMaxPoints(assignment);
     if (err != nil) {
         return err;
     }
 
-    if (lmsAssignment.DueDate == nil) {
-        return fmt.Errorf("Assignment does not have a due date.");
+    if (dueDate == nil) {
+        return fmt.Errorf("Assignment does not have a due date (in the LMS or the assignment config).");
+    }
+
+    if ((maxPoints <= 0.0) && ((policy.Type == model.PercentagePenalty) || (policy.Type == model.LateDays))) {
+        return fmt.Errorf("Late policy '%s' requires the assignment to have max points (in the LMS or the assignment config).", policy.Type);
+    }
+
+    extensions, err := db.GetAssignmentExtensions(assignment);
+    if (err != nil) {
+        return fmt.Errorf("Failed to get extensions: '%w'.", err);
     }
 
-    applyBaselinePolicy(assignment, policy, users, scores, *lmsAssignment.DueDate);
+    applyBaselinePolicy(assignment, policy, users, scores, *dueDate, extensions);
 
     // Baseline policy is complete.
     if (policy.Type == model.BaselinePolicy) {
@@ -66,7 +81,7 @@ func ApplyLatePolicy(
     if ((policy.Type == model.ConstantPenalty) || (policy.Type == model.PercentagePenalty)) {
         penalty := policy.Penalty;
         if (policy.Type == model.PercentagePenalty) {
-            penalty = lmsAssignment.MaxPoints * policy.Penalty;
+            penalty = maxPoints * policy.Penalty;
         }
 
         applyConstantPolicy(policy, scores, penalty);
@@ -74,7 +89,7 @@ func ApplyLatePolicy(
     }
 
     if (policy.Type == model.LateDays) {
-        penalty := lmsAssignment.MaxPoints * policy.Penalty;
+        penalty := maxPoints * policy.Penalty;
         err = applyLateDaysPolicy(policy, assignment, users, scores, penalty, dryRun);
         if (err != nil) {
             return fmt.Errorf("Failed to apply late days policy: '%w'.", err);
@@ -86,8 +101,70 @@ func ApplyLatePolicy(
     return fmt.Errorf("Unknown late policy type: '%s'.", policy.Type);
 }
 
+// Get the due date that should be used when choosing which submission is scored
+// (nil when the assignment's scoring strategy does not use a due date, or the assignment has no due date).
+// This is the same due date that the late policy uses (so it may come from the LMS).
+func GetScoringDueDate(assignment *model.Assignment) (*time.Time, error) {
+    if (assignment.GetScoringStrategy() != model.BestBeforeDeadlineSubmissionStrategy) {
+        return nil, nil;
+    }
+
+    dueDate, _, err := getDueDateAndMaxPoints(assignment);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    return dueDate, nil;
+}
+
+// Get the due date and max points for an assignment.
+// Values from the LMS take precedence, but the assignment's config is used for any value the LMS does not have
+// (or when the assignment is not in an LMS).
+// A nil due date means that the assignment does not have a due date.
+func getDueDateAndMaxPoints(assignment *model.Assignment) (*time.Time, float64, error) {
+    var dueDate *time.Time = nil;
+    maxPoints := assignment.MaxPoints;
+
+    if (!assignment.DueDate.IsZero()) {
+        localDueDate, err := assignment.DueDate.Time();
+        if (err != nil) {
+            return nil, 0.0, fmt.Errorf("Failed to parse assignment due date: '%w'.", err);
+        }
+
+        dueDate = &localDueDate;
+    }
+
+    if ((assignment.GetCourse().GetLMSAdapter() == nil) || (assignment.GetLMSID() == "")) {
+        return dueDate, maxPoints, nil;
+    }
+
+    lmsAssignment, err := lms.FetchAssignment(assignment.GetCourse(), assignment.GetLMSID());
+    if (err != nil) {
+        return nil, 0.0, fmt.Errorf("Failed to fetch LMS assignment: '%w'.", err);
+    }
+
+    if (lmsAssignment == nil) {
+        return dueDate, maxPoints, nil;
+    }
+
+    if (lmsAssignment.DueDate != nil) {
+        dueDate = lmsAssignment.DueDate;
+    }
+
+    if (lmsAssignment.MaxPoints > 0.0) {
+        maxPoints = lmsAssignment.MaxPoints;
+    }
+
+    return dueDate, maxPoints, nil;
+}
+
 // Apply a common policy.
-func applyBaselinePolicy(assignment *model.Assignment, policy model.LateGradingPolicy, users map[string]*model.User, scores map[string]*model.ScoringInfo, dueDate time.Time) {
+// Users with an extension (keyed by email) will have their late days computed against their own due date and day length.
+// Since the other policies only look at the number of days late, they will also honor extensions.
+func applyBaselinePolicy(
+        assignment *model.Assignment, policy model.LateGradingPolicy,
+        users map[string]*model.User, scores map[string]*model.ScoringInfo,
+        dueDate time.Time, extensions map[string]*model.Extension) {
     for email, score := range scores {
         scoreTime, err := score.SubmissionTime.Time();
         if (err != nil) {
@@ -96,7 +173,8 @@ func applyBaselinePolicy(assignment *model.Assignment, policy model.LateGradingP
             continue;
         }
 
-        score.NumDaysLate = computeLateDays(dueDate, scoreTime);
+        extension := extensions[email];
+        score.NumDaysLate = computeLateDays(extension.GetDueDate(dueDate), scoreTime, extension.GetDayLength());
 
         _, ok := users[email];
         if (!ok) {
@@ -123,84 +201,238 @@ func applyConstantPolicy(policy model.LateGradingPolicy, scores map[string]*mode
     }
 }
 
+// Late day balances are stored in the database.
+// If the policy has a late days LMS assignment (and the course has an LMS),
+// then balances are imported from the LMS for students that do not have a balance yet,
+// and any changed balances are mirrored back to the LMS.
 func applyLateDaysPolicy(
         policy model.LateGradingPolicy,
         assignment *model.Assignment, users map[string]*model.User,
         scores map[string]*model.ScoringInfo, penalty float64,
         dryRun bool) error {
-    allLateDays, err := fetchLateDays(policy, assignment);
+    course := assignment.GetCourse();
+
+    mirror := ((policy.LateDaysLMSID != "") && (course.GetLMSAdapter() != nil));
+    if ((policy.LateDaysLMSID != "") && !mirror) {
+        log.Warn("Late days policy has an LMS assignment, but the course has no LMS. Late days will not be mirrored.", assignment);
+    }
+
+    var lmsLateDays map[string]*LateDaysInfo = nil;
+    var err error;
+    if (mirror) {
+        lmsLateDays, err = fetchLateDays(policy, assignment);
+        if (err != nil) {
+            return err;
+        }
+    }
+
+    changedBalances, err := allocateLateDays(policy, assignment, users, scores, penalty, lmsLateDays, dryRun);
     if (err != nil) {
         return err;
     }
 
+    if (!mirror) {
+        return nil;
+    }
+
     lateDaysToUpdate := make(map[string]*LateDaysInfo);
+    for email, balance := range changedBalances {
+        lmsID := users[email].LMSID;
+        if (lmsID == "") {
+            log.Warn("User does not have an LMS ID, cannot mirror late days.", assignment, users[email]);
+            continue;
+        }
+
+        info := lmsLateDays[lmsID];
+        if (info == nil) {
+            info = &LateDaysInfo{AutograderStructVersion: LATE_DAYS_STRUCT_VERSION};
+        }
+
+        info.AvailableDays = balance.AvailableDays;
+        info.AllocatedDays = balance.AllocatedDays;
+        info.UploadTime = common.NowTimestamp();
+
+        lateDaysToUpdate[lmsID] = info;
+    }
+
+    return updateLateDays(policy, assignment, lateDaysToUpdate, dryRun);
+}
+
+// Use late days for the scores and save the changed balances (under the course's late days lock).
+// Returns the changed balances (keyed by email).
+func allocateLateDays(
+        policy model.LateGradingPolicy,
+        assignment *model.Assignment, users map[string]*model.User,
+        scores map[string]*model.ScoringInfo, penalty float64,
+        lmsLateDays map[string]*LateDaysInfo, dryRun bool) (map[string]*model.LateDaysBalance, error) {
+    course := assignment.GetCourse();
+
+    lock := GetLateDaysLock(course);
+    lock.Lock();
+    defer lock.Unlock();
+
+    balances, err := db.GetLateDays(course);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get late days: '%w'.", err);
+    }
+
+    changedBalances := make(map[string]*model.LateDaysBalance);
+    events := make([]*model.LateDaysEvent, 0);
 
     for email, scoringInfo := range scores {
         if (scoringInfo.Reject) {
             continue;
         }
 
-        studentLMSID := users[email].LMSID;
-        if (studentLMSID == "") {
-            log.Warn("User does not have am LMS ID, cannot appply late days policy. Rejecting submission.",
-                    assignment, users[email]);
-            scoringInfo.Reject = true;
-            continue;
-        }
+        balance := balances[email];
+        if (balance == nil) {
+            var event *model.LateDaysEvent;
+            balance, event = newLateDaysBalance(policy, users[email], lmsLateDays);
+            if (balance == nil) {
+                log.Warn("Cannot find user late days, cannot apply late days policy. Rejecting submission.",
+                        assignment, users[email]);
+                scoringInfo.Reject = true;
+                continue;
+            }
 
-        lateDays := allLateDays[studentLMSID];
-        if (lateDays == nil) {
-            log.Warn("Cannot find user late days, cannot appply late days policy. Rejecting submission.",
-                    assignment, users[email], log.NewAttr("lms-id", studentLMSID));
-            scoringInfo.Reject = true;
-            continue;
+            changedBalances[email] = balance;
+            events = append(events, event);
         }
 
         // Compute how many late days can be used.
         // To do this, we will reclaim any late days that have already been used in addition to free days.
-        lateDaysAvailable := lateDays.AvailableDays;
-
-        allocatedDays, hasAllocatedLateDays := lateDays.AllocatedDays[assignment.GetID()];
-        if (hasAllocatedLateDays) {
-            delete(lateDays.AllocatedDays, assignment.GetID());
-            lateDaysAvailable += allocatedDays;
-        }
+        allocatedDays := balance.AllocatedDays[assignment.GetID()];
+        lateDaysAvailable := balance.AvailableDays + allocatedDays;
 
         // Assignment is not late and there are no records of allocating late days for this assignment, skip.
         // Late days could have been allocated if a future submission has been deleted.
-        // We specifically did this after the earlier checks because we want to warn about the user and reject the submission.
-        if ((scoringInfo.NumDaysLate <= 0) && !hasAllocatedLateDays) {
+        if ((scoringInfo.NumDaysLate <= 0) && (allocatedDays == 0)) {
             continue;
         }
 
+        // When students select their own late days, they will not use more than they selected
+        // (and will not use any if they have not made a selection).
+        maxLateDays := policy.MaxLateDays;
+        if (policy.StudentSelectedLateDays) {
+            maxLateDays = min(maxLateDays, balance.SelectedDays[assignment.GetID()]);
+        }
+
         // We will use late days limited by:
         // - The number of late days the user has to use.
-        // - The maximum number of late days that can be used on this assignment.
+        // - The maximum number of late days that can be used on this assignment (or that the user selected).
         // - The number of days late the submission actually is.
-        lateDaysToUse := min(lateDaysAvailable, policy.MaxLateDays, scoringInfo.NumDaysLate);
+        lateDaysToUse := max(0, min(lateDaysAvailable, maxLateDays, scoringInfo.NumDaysLate));
         scoringInfo.LateDayUsage = lateDaysToUse;
 
         // Enforce a penalty for any remaining late days.
         remainingDaysLate := scoringInfo.NumDaysLate - lateDaysToUse;
         scoringInfo.Score = math.Max(0.0, scoringInfo.RawScore - (penalty * float64(remainingDaysLate)));
 
-        // Check if the number of allocated late days has changed.
-        // If so, we need to update the late days in the LMS.
-        if (allocatedDays != lateDaysToUse) {
-            lateDays.AvailableDays = lateDaysAvailable - lateDaysToUse;
-            lateDays.AllocatedDays[assignment.GetID()] = lateDaysToUse;
-            lateDays.UploadTime = common.NowTimestamp();
+        event := balance.Allocate(assignment.GetID(), lateDaysToUse, model.LATE_DAYS_SOURCE_SCORING, "");
+        if (event != nil) {
+            changedBalances[email] = balance;
+            events = append(events, event);
+        }
+    }
 
-            lateDaysToUpdate[studentLMSID] = lateDays;
+    if (dryRun) {
+        log.Info("Dry Run: Skipping saving of late days.", assignment, log.NewAttr("late-days", changedBalances));
+    } else {
+        err = db.SaveLateDays(course, changedBalances, events);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to save late days: '%w'.", err);
         }
     }
 
-    err = updateLateDays(policy, assignment, lateDaysToUpdate, dryRun);
+    return changedBalances, nil;
+}
+
+// Get the lock for a course's late day balances.
+// Balances are read, changed, and saved by scoring, student selections, and admin adjustments,
+// so the lock must be held from reading a balance until the changed balance is saved (otherwise updates can be lost).
+// Scoring changes many balances at once, so there is a single lock for the whole course.
+func GetLateDaysLock(course *model.Course) *sync.Mutex {
+    val, _ := lateDaysLocks.LoadOrStore(course.GetID(), &sync.Mutex{});
+    return val.(*sync.Mutex);
+}
+
+// Get a user's late days balance.
+// If the user does not have a balance yet, then a new balance (and the event that created it) will be returned
+// based on the course's late days policy (the new balance is not saved).
+// Returns nil if the user does not have a balance and one cannot be created.
+func GetUserLateDays(course *model.Course, email string) (*model.LateDaysBalance, *model.LateDaysEvent, error) {
+    balance, err := db.GetUserLateDays(course, email);
     if (err != nil) {
-        return err;
+        return nil, nil, err;
     }
 
-    return nil;
+    if (balance != nil) {
+        return balance, nil, nil;
+    }
+
+    for _, assignment := range course.GetSortedAssignments() {
+        policy := assignment.GetLatePolicy();
+        if ((policy.Type != model.LateDays) || (policy.TotalLateDays <= 0)) {
+            continue;
+        }
+
+        balance, event := newLateDaysBalance(policy, &model.User{Email: email}, nil);
+        return balance, event, nil;
+    }
+
+    return nil, nil, nil;
+}
+
+// Get the time that a user's late day selection for an assignment is locked (no longer able to be changed).
+// Selections lock when the assignment's reject window closes (taking the user's extension into account),
+// since no more submissions can be made.
+func GetLateDaysSelectionLockTime(assignment *model.Assignment, email string) (time.Time, error) {
+    policy := assignment.GetLatePolicy();
+
+    dueDate, _, err := getDueDateAndMaxPoints(assignment);
+    if (err != nil) {
+        return time.Time{}, err;
+    }
+
+    if (dueDate == nil) {
+        return time.Time{}, fmt.Errorf("Assignment does not have a due date (in the LMS or the assignment config).");
+    }
+
+    extensions, err := db.GetAssignmentExtensions(assignment);
+    if (err != nil) {
+        return time.Time{}, fmt.Errorf("Failed to get extensions: '%w'.", err);
+    }
+
+    extension := extensions[email];
+    rejectWindow := time.Duration(policy.RejectAfterDays) * extension.GetDayLength();
+
+    return extension.GetDueDate(*dueDate).Add(rejectWindow), nil;
+}
+
+// Create a late days balance for a user that does not have one.
+// Balances are imported from the LMS when possible, and otherwise start with the policy's total late days.
+// Returns nil if no balance can be created.
+func newLateDaysBalance(policy model.LateGradingPolicy, user *model.User, lmsLateDays map[string]*LateDaysInfo) (*model.LateDaysBalance, *model.LateDaysEvent) {
+    if ((lmsLateDays != nil) && (user.LMSID != "") && (lmsLateDays[user.LMSID] != nil)) {
+        info := lmsLateDays[user.LMSID];
+
+        balance := model.NewLateDaysBalance(user.Email, 0);
+        event := balance.Adjust(info.AvailableDays, model.LATE_DAYS_SOURCE_LMS, "", "Imported from the LMS.");
+
+        for assignmentID, days := range info.AllocatedDays {
+            balance.AllocatedDays[assignmentID] = days;
+        }
+
+        return balance, event;
+    }
+
+    if (policy.TotalLateDays > 0) {
+        balance := model.NewLateDaysBalance(user.Email, 0);
+        event := balance.Adjust(policy.TotalLateDays, model.LATE_DAYS_SOURCE_INITIAL, "", "");
+        return balance, event;
+    }
+
+    return nil, nil;
 }
 
 func updateLateDays(policy model.LateGradingPolicy, assignment *model.Assignment, lateDaysToUpdate map[string]*LateDaysInfo, dryRun bool) error {
@@ -326,10 +558,10 @@ func fetchLateDays(policy model.LateGradingPolicy, assignment *model.Assignment)
     return lateDays, nil;
 }
 
-func computeLateDays(dueDate time.Time, submissionTime time.Time) int {
+func computeLateDays(dueDate time.Time, submissionTime time.Time, dayLength time.Duration) int {
     if (dueDate.After(submissionTime)) {
         return 0;
     }
 
-    return int(math.Ceil(submissionTime.Sub(dueDate).Hours() / 24.0));
+    return int(math.Ceil(float64(submissionTime.Sub(dueDate)) / float64(dayLength)));
 }
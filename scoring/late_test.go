@@ -5,6 +5,10 @@ import (
     "testing"
 
     "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    lmstest "github.com/edulinq/autograder/lms/backend/test"
+    "github.com/edulinq/autograder/lms/lmstypes"
+    "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/util"
 )
 
@@ -31,3 +35,112 @@ func TestLateDaysInfoJSONContainsAutograderKey(test *testing.T) {
                 common.AUTOGRADER_COMMENT_IDENTITY_KEY, content);
     }
 }
+
+func TestApplyBaselinePolicyExtensions(test *testing.T) {
+    dueDate := common.MustTimestampFromString("2024-01-10T12:00:00Z").MustTime();
+    // Two days and one hour late.
+    submissionTime := common.MustTimestampFromString("2024-01-12T13:00:00Z");
+
+    testCases := []struct{extension *model.Extension; expectedDaysLate int; expectedScore float64; expectedReject bool}{
+        {nil, 3, 7.0, true},
+        {&model.Extension{ExtraDays: 1}, 2, 8.0, false},
+        {&model.Extension{ExtraDays: 5}, 0, 10.0, false},
+        {&model.Extension{DueDate: common.MustTimestampFromString("2024-01-12T12:00:00Z")}, 1, 9.0, false},
+        {&model.Extension{DueDate: common.MustTimestampFromString("2024-01-11T12:00:00Z"), ExtraDays: 1}, 1, 9.0, false},
+        {&model.Extension{TimeMultiplier: 1.5}, 2, 8.0, false},
+        {&model.Extension{TimeMultiplier: 3.0}, 1, 9.0, false},
+    };
+
+    assignment := &model.Assignment{ID: "hw0"};
+    policy := model.LateGradingPolicy{Type: model.ConstantPenalty, Penalty: 1.0, RejectAfterDays: 2};
+    users := map[string]*model.User{"student@test.com": &model.User{Email: "student@test.com"}};
+
+    for i, testCase := range testCases {
+        scores := map[string]*model.ScoringInfo{
+            "student@test.com": &model.ScoringInfo{RawScore: 10.0, Score: 10.0, SubmissionTime: submissionTime},
+        };
+
+        extensions := make(map[string]*model.Extension);
+        if (testCase.extension != nil) {
+            extensions["student@test.com"] = testCase.extension;
+        }
+
+        applyBaselinePolicy(assignment, policy, users, scores, dueDate, extensions);
+        applyConstantPolicy(policy, scores, policy.Penalty);
+
+        score := scores["student@test.com"];
+
+        if (score.NumDaysLate != testCase.expectedDaysLate) {
+            test.Errorf("Case %d: Unexpected days late. Expected: %d, Actual: %d.", i, testCase.expectedDaysLate, score.NumDaysLate);
+            continue;
+        }
+
+        if (!util.IsClose(score.Score, testCase.expectedScore)) {
+            test.Errorf("Case %d: Unexpected score. Expected: %f, Actual: %f.", i, testCase.expectedScore, score.Score);
+            continue;
+        }
+
+        if (score.Reject != testCase.expectedReject) {
+            test.Errorf("Case %d: Unexpected rejection. Expected: %v, Actual: %v.", i, testCase.expectedReject, score.Reject);
+            continue;
+        }
+    }
+}
+
+// The due date used to choose the scored submission is the same one used for the late policy (which may come from the LMS).
+func TestGetScoringDueDate(test *testing.T) {
+    db.ResetForTesting();
+    defer db.ResetForTesting();
+    defer lmstest.ClearAssignments();
+
+    localDueDate := common.MustTimestampFromString("2023-10-15T21:44:30Z");
+    lmsDueDate := common.MustTimestampFromString("2023-10-15T21:44:20Z").MustTime();
+
+    lmstest.SetAssignment(&lmstypes.Assignment{ID: "lms-hw0", DueDate: &lmsDueDate});
+
+    testCases := []struct{strategy model.ScoringStrategy; dueDate common.Timestamp; lmsID string; expected string}{
+        {model.LastSubmissionStrategy, localDueDate, "lms-hw0", ""},
+        {model.BestBeforeDeadlineSubmissionStrategy, "", "", ""},
+        {model.BestBeforeDeadlineSubmissionStrategy, localDueDate, "", "2023-10-15T21:44:30Z"},
+        {model.BestBeforeDeadlineSubmissionStrategy, localDueDate, "lms-hw0", "2023-10-15T21:44:20Z"},
+        {model.BestBeforeDeadlineSubmissionStrategy, "", "lms-hw0", "2023-10-15T21:44:20Z"},
+    };
+
+    assignment := db.MustGetTestAssignment();
+
+    for i, testCase := range testCases {
+        assignment.ScoringStrategy = testCase.strategy;
+        assignment.DueDate = testCase.dueDate;
+        assignment.LMSID = testCase.lmsID;
+
+        dueDate, err := GetScoringDueDate(assignment);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to get due date: '%v'.", i, err);
+            continue;
+        }
+
+        actual := "";
+        if (dueDate != nil) {
+            actual = common.TimestampFromTime(*dueDate).String();
+        }
+
+        if (testCase.expected != actual) {
+            test.Errorf("Case %d: Unexpected due date. Expected: '%s', Actual: '%s'.", i, testCase.expected, actual);
+            continue;
+        }
+
+        // The LMS due date (before the second submission) is used to choose the scored submission.
+        if ((testCase.lmsID != "") && (testCase.strategy == model.BestBeforeDeadlineSubmissionStrategy)) {
+            submissions, err := db.GetScoredSubmissions(assignment, model.RoleStudent, dueDate);
+            if (err != nil) {
+                test.Errorf("Case %d: Failed to get scored submissions: '%v'.", i, err);
+                continue;
+            }
+
+            if ((submissions["student@test.com"] == nil) || (submissions["student@test.com"].ShortID != "1697406256")) {
+                test.Errorf("Case %d: Unexpected scored submission: '%s'.", i, util.MustToJSONIndent(submissions["student@test.com"]));
+                continue;
+            }
+        }
+    }
+}
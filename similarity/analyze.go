@@ -0,0 +1,393 @@
+package similarity
+
+import (
+    "fmt"
+    "slices"
+    "sort"
+    "strings"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    DEFAULT_KGRAM_SIZE = 8
+    DEFAULT_WINDOW_SIZE = 4
+)
+
+type Options struct {
+    // Only compare files of this language (all known languages when empty).
+    Language string `json:"language,omitempty"`
+    // The number of consecutive tokens hashed together.
+    KGramSize int `json:"kgram-size"`
+    // The number of consecutive k-grams that winnowing picks a fingerprint from.
+    WindowSize int `json:"window-size"`
+    // Only report pairs with at least this similarity ([0, 1]).
+    MinSimilarity float64 `json:"min-similarity"`
+}
+
+// A single submission to compare.
+// Files are keyed by their path relative to the submission.
+type Submission struct {
+    ID string `json:"id"`
+    ShortID string `json:"short-id"`
+    // Everyone that shares this submission (e.g., a group).
+    Users []string `json:"users"`
+    Files map[string]string `json:"-"`
+}
+
+type document struct {
+    submission *Submission
+    fingerprints map[uint64][]*Fingerprint
+}
+
+func GetDefaultOptions() Options {
+    return Options{
+        KGramSize: DEFAULT_KGRAM_SIZE,
+        WindowSize: DEFAULT_WINDOW_SIZE,
+    };
+}
+
+func (this *Options) Validate() error {
+    if ((this.Language != "") && (GetLanguage(this.Language) == nil)) {
+        return fmt.Errorf("Unknown language '%s', known languages: '%s'.", this.Language, strings.Join(GetLanguageNames(), "', '"));
+    }
+
+    if (this.KGramSize <= 0) {
+        return fmt.Errorf("K-gram size must be positive, found %d.", this.KGramSize);
+    }
+
+    if (this.WindowSize <= 0) {
+        return fmt.Errorf("Window size must be positive, found %d.", this.WindowSize);
+    }
+
+    if ((this.MinSimilarity < 0.0) || (this.MinSimilarity > 1.0)) {
+        return fmt.Errorf("Minimum similarity must be in [0, 1], found %f.", this.MinSimilarity);
+    }
+
+    return nil;
+}
+
+// Compare the most recent submission of every student in an assignment.
+// Code that appears in the assignment's static files (starter code) is not counted as a match.
+func AnalyzeAssignment(assignment *model.Assignment, options Options) (*SimilarityReport, error) {
+    submissions, err := getRecentSubmissions(assignment);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    starterFiles, err := getStarterFiles(assignment);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get starter code: '%w'.", err);
+    }
+
+    report, err := Analyze(submissions, starterFiles, options);
+    if (err != nil) {
+        return nil, err;
+    }
+
+    report.CourseID = assignment.GetCourse().GetID();
+    report.AssignmentID = assignment.GetID();
+    report.AssignmentName = assignment.GetName();
+
+    return report, nil;
+}
+
+// Compare every pair of submissions.
+// Any k-gram that appears in the starter files is ignored.
+func Analyze(submissions []*Submission, starterFiles map[string]string, options Options) (*SimilarityReport, error) {
+    err := options.Validate();
+    if (err != nil) {
+        return nil, err;
+    }
+
+    var onlyLanguage *Language = nil;
+    if (options.Language != "") {
+        onlyLanguage = GetLanguage(options.Language);
+        options.Language = onlyLanguage.Name;
+    }
+
+    starterHashes := make(map[uint64]bool);
+    for path, text := range starterFiles {
+        language := getComparisonLanguage(path, onlyLanguage);
+        if (language == nil) {
+            continue;
+        }
+
+        for _, kgram := range hashKGrams(Tokenize(text, language), path, options.KGramSize) {
+            starterHashes[kgram.Hash] = true;
+        }
+    }
+
+    documents := make([]*document, 0, len(submissions));
+    for _, submission := range submissions {
+        documents = append(documents, fingerprintSubmission(submission, starterHashes, onlyLanguage, options));
+    }
+
+    pairs := make([]*SimilarityPair, 0);
+    for i := 0; i < len(documents); i++ {
+        for j := (i + 1); j < len(documents); j++ {
+            pair := comparePair(documents[i], documents[j]);
+            if ((pair == nil) || (pair.Similarity < options.MinSimilarity)) {
+                continue;
+            }
+
+            pairs = append(pairs, pair);
+        }
+    }
+
+    sort.SliceStable(pairs, func(i int, j int) bool {
+        return pairs[i].Similarity > pairs[j].Similarity;
+    });
+
+    for i, pair := range pairs {
+        pair.Rank = i + 1;
+    }
+
+    return &SimilarityReport{
+        Options: options,
+        NumberOfSubmissions: len(submissions),
+        Pairs: pairs,
+    }, nil;
+}
+
+// Get the language a file should be compared as (nil if it should not be compared).
+func getComparisonLanguage(path string, onlyLanguage *Language) *Language {
+    language := GetFileLanguage(path);
+    if ((language == nil) || ((onlyLanguage != nil) && (language != onlyLanguage))) {
+        return nil;
+    }
+
+    return language;
+}
+
+func fingerprintSubmission(submission *Submission, starterHashes map[uint64]bool, onlyLanguage *Language, options Options) *document {
+    fingerprints := make(map[uint64][]*Fingerprint);
+
+    for path, text := range submission.Files {
+        language := getComparisonLanguage(path, onlyLanguage);
+        if (language == nil) {
+            continue;
+        }
+
+        kgrams := hashKGrams(Tokenize(text, language), path, options.KGramSize);
+        for _, fingerprint := range winnow(kgrams, options.WindowSize) {
+            if (starterHashes[fingerprint.Hash]) {
+                continue;
+            }
+
+            fingerprints[fingerprint.Hash] = append(fingerprints[fingerprint.Hash], fingerprint);
+        }
+    }
+
+    return &document{
+        submission: submission,
+        fingerprints: fingerprints,
+    };
+}
+
+// Returns nil if the documents do not share any fingerprints.
+func comparePair(a *document, b *document) *SimilarityPair {
+    matches := make([]*MatchedRegion, 0);
+    shared := 0;
+
+    for hash, fingerprintsA := range a.fingerprints {
+        fingerprintsB, ok := b.fingerprints[hash];
+        if (!ok) {
+            continue;
+        }
+
+        shared++;
+
+        for _, fingerprintA := range fingerprintsA {
+            for _, fingerprintB := range fingerprintsB {
+                matches = append(matches, &MatchedRegion{
+                    FileA: fingerprintA.File,
+                    StartLineA: fingerprintA.StartLine,
+                    EndLineA: fingerprintA.EndLine,
+                    FileB: fingerprintB.File,
+                    StartLineB: fingerprintB.StartLine,
+                    EndLineB: fingerprintB.EndLine,
+                });
+            }
+        }
+    }
+
+    if (shared == 0) {
+        return nil;
+    }
+
+    similarityA := float64(shared) / float64(len(a.fingerprints));
+    similarityB := float64(shared) / float64(len(b.fingerprints));
+
+    matches = mergeMatches(matches);
+    for _, match := range matches {
+        match.TextA = getLines(a.submission.Files[match.FileA], match.StartLineA, match.EndLineA);
+        match.TextB = getLines(b.submission.Files[match.FileB], match.StartLineB, match.EndLineB);
+    }
+
+    return &SimilarityPair{
+        SubmissionA: a.submission,
+        SubmissionB: b.submission,
+        Similarity: (similarityA + similarityB) / 2.0,
+        SimilarityA: similarityA,
+        SimilarityB: similarityB,
+        SharedFingerprints: shared,
+        Matches: matches,
+    };
+}
+
+// Merge matches that overlap (or are adjacent) in both files.
+func mergeMatches(matches []*MatchedRegion) []*MatchedRegion {
+    sort.Slice(matches, func(i int, j int) bool {
+        return compareMatches(matches[i], matches[j]) < 0;
+    });
+
+    merged := make([]*MatchedRegion, 0, len(matches));
+    for _, match := range matches {
+        mergedInto := false;
+
+        for _, current := range merged {
+            if ((current.FileA != match.FileA) || (current.FileB != match.FileB)) {
+                continue;
+            }
+
+            if ((match.StartLineA > (current.EndLineA + 1)) || (match.StartLineB > (current.EndLineB + 1)) ||
+                    (match.EndLineB < (current.StartLineB - 1))) {
+                continue;
+            }
+
+            current.EndLineA = max(current.EndLineA, match.EndLineA);
+            current.StartLineB = min(current.StartLineB, match.StartLineB);
+            current.EndLineB = max(current.EndLineB, match.EndLineB);
+            mergedInto = true;
+            break;
+        }
+
+        if (!mergedInto) {
+            merged = append(merged, match);
+        }
+    }
+
+    return merged;
+}
+
+func compareMatches(a *MatchedRegion, b *MatchedRegion) int {
+    if (a.FileA != b.FileA) {
+        return strings.Compare(a.FileA, b.FileA);
+    }
+
+    if (a.StartLineA != b.StartLineA) {
+        return a.StartLineA - b.StartLineA;
+    }
+
+    if (a.FileB != b.FileB) {
+        return strings.Compare(a.FileB, b.FileB);
+    }
+
+    return a.StartLineB - b.StartLineB;
+}
+
+// Get the text of the given (1-indexed, inclusive) lines.
+func getLines(text string, startLine int, endLine int) string {
+    lines := strings.Split(text, "\n");
+
+    startLine = max(1, startLine);
+    endLine = min(len(lines), endLine);
+    if (startLine > endLine) {
+        return "";
+    }
+
+    return strings.Join(lines[(startLine - 1):endLine], "\n");
+}
+
+// Get the most recent submission of each student.
+// Group members that share a submission are combined into a single submission.
+func getRecentSubmissions(assignment *model.Assignment) ([]*Submission, error) {
+    results, err := db.GetRecentSubmissionContents(assignment, model.RoleStudent);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to get recent submissions: '%w'.", err);
+    }
+
+    emails := make([]string, 0, len(results));
+    for email := range results {
+        emails = append(emails, email);
+    }
+    slices.Sort(emails);
+
+    submissions := make([]*Submission, 0, len(results));
+    seen := make(map[string]*Submission);
+
+    for _, email := range emails {
+        result := results[email];
+        if ((result == nil) || (result.Info == nil)) {
+            continue;
+        }
+
+        submission, ok := seen[result.Info.ID];
+        if (ok) {
+            submission.Users = append(submission.Users, email);
+            continue;
+        }
+
+        files := make(map[string]string, len(result.InputFilesGZip));
+        for path, data := range result.InputFilesGZip {
+            contents, err := util.GunzipBytes(data);
+            if (err != nil) {
+                return nil, fmt.Errorf("Failed to decompress file '%s' from submission '%s': '%w'.", path, result.Info.ID, err);
+            }
+
+            files[path] = string(contents);
+        }
+
+        submission = &Submission{
+            ID: result.Info.ID,
+            ShortID: result.Info.ShortID,
+            Users: []string{email},
+            Files: files,
+        };
+
+        seen[submission.ID] = submission;
+        submissions = append(submissions, submission);
+    }
+
+    return submissions, nil;
+}
+
+// Get the contents of the assignment's static files (keyed by relative path).
+func getStarterFiles(assignment *model.Assignment) (map[string]string, error) {
+    imageInfo := assignment.GetImageInfo();
+    if ((imageInfo == nil) || (len(imageInfo.StaticFiles) == 0)) {
+        return map[string]string{}, nil;
+    }
+
+    tempDir, err := util.MkDirTemp("autograder-similarity-starter-");
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to create temp dir: '%w'.", err);
+    }
+    defer util.RemoveDirent(tempDir);
+
+    err = common.CopyFileSpecs(assignment.GetSourceDir(), tempDir, tempDir, imageInfo.StaticFiles, false, nil, nil);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to copy static files: '%w'.", err);
+    }
+
+    paths, err := util.FindFiles("", tempDir);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to find static files: '%w'.", err);
+    }
+
+    files := make(map[string]string, len(paths));
+    for _, path := range paths {
+        contents, err := util.ReadFile(path);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to read static file '%s': '%w'.", path, err);
+        }
+
+        files[util.RelPath(path, tempDir)] = contents;
+    }
+
+    return files, nil;
+}
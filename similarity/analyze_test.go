@@ -0,0 +1,175 @@
+package similarity
+
+import (
+    "strings"
+    "testing"
+
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/util"
+)
+
+var testStarterCode string = `
+def read_input(path):
+    with open(path, 'r') as file:
+        lines = file.readlines()
+
+    return [line.strip() for line in lines if len(line.strip()) > 0]
+`;
+
+var testCopiedCode string = `
+def count_words(lines):
+    counts = {}
+    for line in lines:
+        for word in line.split():
+            counts[word] = counts.get(word, 0) + 1
+
+    return sorted(counts.items(), key = lambda item: (-item[1], item[0]))
+`;
+
+// The same as testCopiedCode, but with renamed variables and comments.
+var testRenamedCode string = `
+def tally(rows):
+    # Count each word.
+    result = {}
+    for row in rows:
+        for token in row.split():
+            result[token] = result.get(token, 0) + 1
+
+    return sorted(result.items(), key = lambda pair: (-pair[1], pair[0]))
+`;
+
+var testOriginalCode string = `
+def count_words(lines):
+    import collections
+
+    counter = collections.Counter()
+    while len(lines) > 0:
+        counter.update(lines.pop().split())
+
+    return counter.most_common()
+`;
+
+func TestAnalyzeBase(test *testing.T) {
+    submissions := []*Submission{
+        &Submission{ID: "a", Users: []string{"a@test.com"}, Files: map[string]string{
+            "io.py": testStarterCode,
+            "main.py": testCopiedCode,
+            "notes.txt": testCopiedCode,
+        }},
+        &Submission{ID: "b", Users: []string{"b@test.com"}, Files: map[string]string{
+            "io.py": testStarterCode,
+            "main.py": testRenamedCode,
+        }},
+        &Submission{ID: "c", Users: []string{"c@test.com"}, Files: map[string]string{
+            "io.py": testStarterCode,
+            "main.py": testOriginalCode,
+        }},
+    };
+
+    starterFiles := map[string]string{"io.py": testStarterCode};
+
+    report, err := Analyze(submissions, starterFiles, GetDefaultOptions());
+    if (err != nil) {
+        test.Fatalf("Failed to analyze: '%v'.", err);
+    }
+
+    if (report.NumberOfSubmissions != 3) {
+        test.Fatalf("Unexpected number of submissions. Expected: 3, Actual: %d.", report.NumberOfSubmissions);
+    }
+
+    // Only the copied pair shares code once the starter code is removed.
+    if (len(report.Pairs) != 1) {
+        test.Fatalf("Unexpected number of pairs. Expected: 1, Actual: %d. Report: '%s'.", len(report.Pairs), util.MustToJSONIndent(report));
+    }
+
+    pair := report.Pairs[0];
+    if ((pair.SubmissionA.ID != "a") || (pair.SubmissionB.ID != "b") || (pair.Rank != 1)) {
+        test.Fatalf("Unexpected pair: '%s'.", util.MustToJSONIndent(pair));
+    }
+
+    if ((pair.SimilarityA != 1.0) || (pair.SimilarityB != 1.0)) {
+        test.Fatalf("Renamed code should be a full match, found: '%s'.", util.MustToJSONIndent(pair));
+    }
+
+    if (len(pair.Matches) != 1) {
+        test.Fatalf("Unexpected number of matched regions. Expected: 1, Actual: %d.", len(pair.Matches));
+    }
+
+    match := pair.Matches[0];
+    if ((match.FileA != "main.py") || (match.FileB != "main.py")) {
+        test.Fatalf("Unexpected matched files: '%s'.", util.MustToJSONIndent(match));
+    }
+
+    if (!strings.Contains(match.TextA, "counts[word]") || !strings.Contains(match.TextB, "result[token]")) {
+        test.Fatalf("Matched regions do not contain the copied code: '%s'.", util.MustToJSONIndent(match));
+    }
+
+    if (strings.Contains(match.TextA, "read_input")) {
+        test.Fatalf("Matched region contains starter code: '%s'.", util.MustToJSONIndent(match));
+    }
+
+    html, err := report.ToHTML();
+    if (err != nil) {
+        test.Fatalf("Failed to generate HTML: '%v'.", err);
+    }
+
+    if (!strings.Contains(html, "a@test.com") || !strings.Contains(html, "counts[word]")) {
+        test.Fatalf("HTML report is missing expected content.");
+    }
+}
+
+func TestAnalyzeOptions(test *testing.T) {
+    submissions := []*Submission{
+        &Submission{ID: "a", Users: []string{"a@test.com"}, Files: map[string]string{"main.py": testCopiedCode}},
+        &Submission{ID: "b", Users: []string{"b@test.com"}, Files: map[string]string{"main.py": testRenamedCode}},
+    };
+
+    options := GetDefaultOptions();
+    options.Language = "java";
+
+    report, err := Analyze(submissions, nil, options);
+    if (err != nil) {
+        test.Fatalf("Failed to analyze: '%v'.", err);
+    }
+
+    if (len(report.Pairs) != 0) {
+        test.Fatalf("Python files should not be compared when only comparing java.");
+    }
+
+    invalidOptions := []Options{
+        Options{Language: "zzz", KGramSize: 1, WindowSize: 1},
+        Options{KGramSize: 0, WindowSize: 1},
+        Options{KGramSize: 1, WindowSize: 0},
+        Options{KGramSize: 1, WindowSize: 1, MinSimilarity: 1.5},
+    };
+
+    for i, options := range invalidOptions {
+        _, err = Analyze(submissions, nil, options);
+        if (err == nil) {
+            test.Errorf("Case %d: Did not get an error on invalid options.", i);
+        }
+    }
+}
+
+func TestAnalyzeAssignment(test *testing.T) {
+    assignment := db.MustGetTestAssignment();
+
+    report, err := AnalyzeAssignment(assignment, GetDefaultOptions());
+    if (err != nil) {
+        test.Fatalf("Failed to analyze assignment: '%v'.", err);
+    }
+
+    if ((report.AssignmentID != "hw0") || (report.NumberOfSubmissions != 1) || (len(report.Pairs) != 0)) {
+        test.Fatalf("Unexpected report: '%s'.", util.MustToJSONIndent(report));
+    }
+
+    starterFiles, err := getStarterFiles(assignment);
+    if (err != nil) {
+        test.Fatalf("Failed to get starter files: '%v'.", err);
+    }
+
+    _, ok := starterFiles["grader.py"];
+    if (!ok) {
+        test.Fatalf("Could not find starter file 'grader.py' in: '%v'.", starterFiles);
+    }
+}
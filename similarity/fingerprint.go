@@ -0,0 +1,69 @@
+package similarity
+
+import (
+    "hash/fnv"
+)
+
+// A hashed k-gram of tokens and the lines it covers.
+type Fingerprint struct {
+    Hash uint64
+    File string
+    StartLine int
+    EndLine int
+}
+
+// Hash every k-gram of (normalized) tokens.
+func hashKGrams(tokens []*Token, file string, kgramSize int) []*Fingerprint {
+    if (len(tokens) < kgramSize) {
+        return []*Fingerprint{};
+    }
+
+    fingerprints := make([]*Fingerprint, 0, len(tokens) - kgramSize + 1);
+    for i := 0; i <= (len(tokens) - kgramSize); i++ {
+        hasher := fnv.New64a();
+        for _, token := range tokens[i:(i + kgramSize)] {
+            hasher.Write([]byte(token.Text));
+            hasher.Write([]byte{0});
+        }
+
+        fingerprints = append(fingerprints, &Fingerprint{
+            Hash: hasher.Sum64(),
+            File: file,
+            StartLine: tokens[i].Line,
+            EndLine: tokens[i + kgramSize - 1].Line,
+        });
+    }
+
+    return fingerprints;
+}
+
+// Select a representative subset of k-gram hashes using winnowing
+// (Schleimer, Wilkerson, and Aiken; "Winnowing: Local Algorithms for Document Fingerprinting").
+// The rightmost minimum hash of every window is selected (once).
+// This guarantees that any match at least (windowSize + kgramSize - 1) tokens long will be found.
+func winnow(kgrams []*Fingerprint, windowSize int) []*Fingerprint {
+    if (len(kgrams) == 0) {
+        return []*Fingerprint{};
+    }
+
+    windowSize = min(windowSize, len(kgrams));
+
+    selected := make([]*Fingerprint, 0);
+    lastSelected := -1;
+
+    for start := 0; start <= (len(kgrams) - windowSize); start++ {
+        minIndex := start;
+        for i := start; i < (start + windowSize); i++ {
+            if (kgrams[i].Hash <= kgrams[minIndex].Hash) {
+                minIndex = i;
+            }
+        }
+
+        if (minIndex != lastSelected) {
+            selected = append(selected, kgrams[minIndex]);
+            lastSelected = minIndex;
+        }
+    }
+
+    return selected;
+}
@@ -0,0 +1,142 @@
+package similarity
+
+import (
+    "path/filepath"
+    "slices"
+    "strings"
+)
+
+// Languages that submitted files can be tokenized as.
+// Files with an extension that does not match any language are ignored.
+type Language struct {
+    Name string
+    Extensions []string
+    LineComments []string
+    // Pairs of [start, end].
+    BlockComments [][2]string
+    // Quote sequences that start (and end) a string literal. Longer sequences should be listed first.
+    StringQuotes []string
+    Keywords []string
+}
+
+var languages []*Language = []*Language{
+    &Language{
+        Name: "c",
+        Extensions: []string{".c", ".h"},
+        LineComments: []string{"//"},
+        BlockComments: [][2]string{{"/*", "*/"}},
+        StringQuotes: []string{`"`, `'`},
+        Keywords: []string{
+            "auto", "break", "case", "char", "const", "continue", "default", "do", "double", "else", "enum",
+            "extern", "float", "for", "goto", "if", "int", "long", "register", "return", "short", "signed",
+            "sizeof", "static", "struct", "switch", "typedef", "union", "unsigned", "void", "volatile", "while",
+        },
+    },
+    &Language{
+        Name: "cpp",
+        Extensions: []string{".cpp", ".cc", ".cxx", ".hpp", ".hh"},
+        LineComments: []string{"//"},
+        BlockComments: [][2]string{{"/*", "*/"}},
+        StringQuotes: []string{`"`, `'`},
+        Keywords: []string{
+            "auto", "bool", "break", "case", "catch", "char", "class", "const", "continue", "default", "delete",
+            "do", "double", "else", "enum", "false", "float", "for", "if", "int", "long", "namespace", "new",
+            "nullptr", "private", "protected", "public", "return", "short", "sizeof", "static", "struct",
+            "switch", "template", "this", "throw", "true", "try", "typedef", "typename", "unsigned", "using",
+            "virtual", "void", "while",
+        },
+    },
+    &Language{
+        Name: "go",
+        Extensions: []string{".go"},
+        LineComments: []string{"//"},
+        BlockComments: [][2]string{{"/*", "*/"}},
+        StringQuotes: []string{`"`, `'`, "`"},
+        Keywords: []string{
+            "break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for",
+            "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select",
+            "struct", "switch", "type", "var",
+        },
+    },
+    &Language{
+        Name: "java",
+        Extensions: []string{".java"},
+        LineComments: []string{"//"},
+        BlockComments: [][2]string{{"/*", "*/"}},
+        StringQuotes: []string{`"""`, `"`, `'`},
+        Keywords: []string{
+            "abstract", "boolean", "break", "byte", "case", "catch", "char", "class", "continue", "default",
+            "do", "double", "else", "enum", "extends", "false", "final", "finally", "float", "for", "if",
+            "implements", "import", "instanceof", "int", "interface", "long", "new", "null", "package",
+            "private", "protected", "public", "return", "short", "static", "super", "switch", "this", "throw",
+            "throws", "true", "try", "void", "while",
+        },
+    },
+    &Language{
+        Name: "javascript",
+        Extensions: []string{".js", ".mjs", ".ts"},
+        LineComments: []string{"//"},
+        BlockComments: [][2]string{{"/*", "*/"}},
+        StringQuotes: []string{`"`, `'`, "`"},
+        Keywords: []string{
+            "async", "await", "break", "case", "catch", "class", "const", "continue", "default", "delete", "do",
+            "else", "export", "extends", "false", "finally", "for", "function", "if", "import", "in",
+            "instanceof", "let", "new", "null", "return", "super", "switch", "this", "throw", "true", "try",
+            "typeof", "undefined", "var", "void", "while", "yield",
+        },
+    },
+    &Language{
+        Name: "python",
+        Extensions: []string{".py"},
+        LineComments: []string{"#"},
+        BlockComments: [][2]string{},
+        StringQuotes: []string{`"""`, `'''`, `"`, `'`},
+        Keywords: []string{
+            "False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue",
+            "def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in",
+            "is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield",
+        },
+    },
+};
+
+func GetLanguage(name string) *Language {
+    name = strings.ToLower(strings.TrimSpace(name));
+
+    for _, language := range languages {
+        if (language.Name == name) {
+            return language;
+        }
+    }
+
+    return nil;
+}
+
+// Get the language for a file based on its extension.
+// Returns nil if the file does not match a known language.
+func GetFileLanguage(path string) *Language {
+    extension := strings.ToLower(filepath.Ext(path));
+    if (extension == "") {
+        return nil;
+    }
+
+    for _, language := range languages {
+        if (slices.Contains(language.Extensions, extension)) {
+            return language;
+        }
+    }
+
+    return nil;
+}
+
+func GetLanguageNames() []string {
+    names := make([]string, 0, len(languages));
+    for _, language := range languages {
+        names = append(names, language.Name);
+    }
+
+    return names;
+}
+
+func (this *Language) isKeyword(text string) bool {
+    return slices.Contains(this.Keywords, text);
+}
@@ -0,0 +1,197 @@
+package similarity
+
+import (
+    "fmt"
+    "html/template"
+    "strings"
+)
+
+type SimilarityReport struct {
+    CourseID string `json:"course-id,omitempty"`
+    AssignmentID string `json:"assignment-id,omitempty"`
+    AssignmentName string `json:"assignment-name,omitempty"`
+
+    Options Options `json:"options"`
+    NumberOfSubmissions int `json:"number-of-submissions"`
+
+    // Pairs that share code, most similar first.
+    Pairs []*SimilarityPair `json:"pairs"`
+}
+
+type SimilarityPair struct {
+    // 1-indexed position in the report.
+    Rank int `json:"rank"`
+
+    SubmissionA *Submission `json:"submission-a"`
+    SubmissionB *Submission `json:"submission-b"`
+
+    // The mean of SimilarityA and SimilarityB.
+    Similarity float64 `json:"similarity"`
+    // The fraction of A's fingerprints that are also in B.
+    SimilarityA float64 `json:"similarity-a"`
+    // The fraction of B's fingerprints that are also in A.
+    SimilarityB float64 `json:"similarity-b"`
+    SharedFingerprints int `json:"shared-fingerprints"`
+
+    Matches []*MatchedRegion `json:"matches"`
+}
+
+// A region (by 1-indexed, inclusive lines) of A's code that matches a region of B's code.
+type MatchedRegion struct {
+    FileA string `json:"file-a"`
+    StartLineA int `json:"start-line-a"`
+    EndLineA int `json:"end-line-a"`
+    TextA string `json:"text-a"`
+
+    FileB string `json:"file-b"`
+    StartLineB int `json:"start-line-b"`
+    EndLineB int `json:"end-line-b"`
+    TextB string `json:"text-b"`
+}
+
+func (this *SimilarityPair) SimilarityString() string {
+    return formatPercent(this.Similarity);
+}
+
+func (this *SimilarityPair) SimilarityAString() string {
+    return formatPercent(this.SimilarityA);
+}
+
+func (this *SimilarityPair) SimilarityBString() string {
+    return formatPercent(this.SimilarityB);
+}
+
+func (this *Submission) UsersString() string {
+    return strings.Join(this.Users, ", ");
+}
+
+func formatPercent(value float64) string {
+    return fmt.Sprintf("%0.1f%%", value * 100.0);
+}
+
+func (this *SimilarityReport) ToHTML() (string, error) {
+    title := fmt.Sprintf("Similarity Report for %s", this.AssignmentName);
+    templateHTML := fmt.Sprintf(outterShell, title, style, reportTemplate);
+
+    tmpl, err := template.New("similarity-report").Parse(templateHTML);
+    if (err != nil) {
+        return "", fmt.Errorf("Could not parse similarity report template: '%w'.", err);
+    }
+
+    var builder strings.Builder;
+    err = tmpl.Execute(&builder, this);
+    if (err != nil) {
+        return "", fmt.Errorf("Failed to execute similarity report template: '%w'.", err);
+    }
+
+    return builder.String(), nil;
+}
+
+// Replacements: [title, head, body]
+var outterShell string = `
+    <html>
+        <head>
+            <meta charset="utf-8"/>
+            <meta name="viewport" content="width=device-width, initial-scale=1.0">
+
+            <title>%s</title>
+
+            %s
+        </head>
+        <body>
+            %s
+        </body>
+    </html>
+`
+
+var reportTemplate string = `
+    <div class='autograder autograder-similarity-report'>
+        <div class='ag-header'>
+            <h1>Similarity Report: {{ .AssignmentName }}</h1>
+            <p>Number of Submissions: {{ .NumberOfSubmissions }}</p>
+            <p>Number of Similar Pairs: {{ len .Pairs }}</p>
+        </div>
+        <div class='ag-body'>
+            <table class='ag-pairs'>
+                <thead>
+                    <tr>
+                        <th>Rank</th>
+                        <th>Submission A</th>
+                        <th>Submission B</th>
+                        <th>Similarity</th>
+                        <th>A in B</th>
+                        <th>B in A</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{ range .Pairs }}
+                        <tr>
+                            <td class='numeric'><a href='#pair-{{ .Rank }}'>{{ .Rank }}</a></td>
+                            <td class='text'>{{ .SubmissionA.UsersString }}</td>
+                            <td class='text'>{{ .SubmissionB.UsersString }}</td>
+                            <td class='numeric'>{{ .SimilarityString }}</td>
+                            <td class='numeric'>{{ .SimilarityAString }}</td>
+                            <td class='numeric'>{{ .SimilarityBString }}</td>
+                        </tr>
+                    {{ end }}
+                </tbody>
+            </table>
+
+            {{ range .Pairs }}
+                <div class='ag-pair' id='pair-{{ .Rank }}'>
+                    <h2>#{{ .Rank }}: {{ .SubmissionA.UsersString }} / {{ .SubmissionB.UsersString }} ({{ .SimilarityString }})</h2>
+                    <p>Submissions: {{ .SubmissionA.ID }} / {{ .SubmissionB.ID }}</p>
+
+                    {{ range .Matches }}
+                        <div class='ag-match'>
+                            <div class='ag-side'>
+                                <p>{{ .FileA }} (lines {{ .StartLineA }} - {{ .EndLineA }})</p>
+                                <pre>{{ .TextA }}</pre>
+                            </div>
+                            <div class='ag-side'>
+                                <p>{{ .FileB }} (lines {{ .StartLineB }} - {{ .EndLineB }})</p>
+                                <pre>{{ .TextB }}</pre>
+                            </div>
+                        </div>
+                    {{ end }}
+                </div>
+            {{ end }}
+        </div>
+    </div>
+`
+
+var style string = `
+    <style>
+        .autograder-similarity-report table th,
+        .autograder-similarity-report table .text {
+            text-align: left;
+        }
+
+        .autograder-similarity-report table .numeric {
+            text-align: right;
+        }
+
+        .autograder-similarity-report table th,
+        .autograder-similarity-report table td {
+            padding: 5px;
+            padding-right: 10px;
+        }
+
+        .autograder-similarity-report .ag-match {
+            display: flex;
+            gap: 10px;
+            margin-bottom: 10px;
+        }
+
+        .autograder-similarity-report .ag-side {
+            flex: 1;
+            min-width: 0;
+        }
+
+        .autograder-similarity-report .ag-side pre {
+            background-color: #f4f4f4;
+            overflow-x: auto;
+            padding: 5px;
+        }
+    </style>
+`
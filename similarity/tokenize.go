@@ -0,0 +1,155 @@
+package similarity
+
+import (
+    "strings"
+    "unicode"
+)
+
+// Identifiers and literals are normalized so that renaming variables or changing constants does not hide a match.
+const (
+    TOKEN_IDENTIFIER = "<id>"
+    TOKEN_NUMBER = "<num>"
+    TOKEN_STRING = "<str>"
+)
+
+type Token struct {
+    Text string
+    // 1-indexed line the token starts on.
+    Line int
+}
+
+// Split source code into normalized tokens.
+// Whitespace and comments are dropped.
+func Tokenize(text string, language *Language) []*Token {
+    tokens := make([]*Token, 0);
+    runes := []rune(text);
+    line := 1;
+
+    for i := 0; i < len(runes); {
+        char := runes[i];
+
+        if (char == '\n') {
+            line++;
+            i++;
+            continue;
+        }
+
+        if (unicode.IsSpace(char)) {
+            i++;
+            continue;
+        }
+
+        if (hasAnyPrefix(runes, i, language.LineComments) != "") {
+            for ((i < len(runes)) && (runes[i] != '\n')) {
+                i++;
+            }
+
+            continue;
+        }
+
+        matchedBlock := false;
+        for _, delimiters := range language.BlockComments {
+            if (!hasPrefix(runes, i, delimiters[0])) {
+                continue;
+            }
+
+            matchedBlock = true;
+            i, line = skipPast(runes, i + len([]rune(delimiters[0])), line, delimiters[1], false, false);
+            break;
+        }
+
+        if (matchedBlock) {
+            continue;
+        }
+
+        quote := hasAnyPrefix(runes, i, language.StringQuotes);
+        if (quote != "") {
+            // Only multi-character and backtick quotes may span lines.
+            singleLine := ((quote == `"`) || (quote == `'`));
+
+            tokens = append(tokens, &Token{Text: TOKEN_STRING, Line: line});
+            i, line = skipPast(runes, i + len([]rune(quote)), line, quote, true, singleLine);
+            continue;
+        }
+
+        if ((char == '_') || unicode.IsLetter(char)) {
+            start := i;
+            for ((i < len(runes)) && ((runes[i] == '_') || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))) {
+                i++;
+            }
+
+            word := string(runes[start:i]);
+            if (!language.isKeyword(word)) {
+                word = TOKEN_IDENTIFIER;
+            }
+
+            tokens = append(tokens, &Token{Text: word, Line: line});
+            continue;
+        }
+
+        if (unicode.IsDigit(char)) {
+            for ((i < len(runes)) && ((runes[i] == '_') || (runes[i] == '.') || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))) {
+                i++;
+            }
+
+            tokens = append(tokens, &Token{Text: TOKEN_NUMBER, Line: line});
+            continue;
+        }
+
+        tokens = append(tokens, &Token{Text: string(char), Line: line});
+        i++;
+    }
+
+    return tokens;
+}
+
+// Advance past the next occurrence of end (or to the end of the input), counting lines along the way.
+// If stopAtNewline is set, then stop just before an unescaped newline (unterminated single-line strings).
+// Returns the new index and line.
+func skipPast(runes []rune, i int, line int, end string, allowEscapes bool, stopAtNewline bool) (int, int) {
+    for (i < len(runes)) {
+        if (stopAtNewline && (runes[i] == '\n')) {
+            return i, line;
+        }
+
+        if (allowEscapes && (runes[i] == '\\')) {
+            if (((i + 1) < len(runes)) && (runes[i + 1] == '\n')) {
+                line++;
+            }
+
+            i += 2;
+            continue;
+        }
+
+        if (hasPrefix(runes, i, end)) {
+            return i + len([]rune(end)), line;
+        }
+
+        if (runes[i] == '\n') {
+            line++;
+        }
+
+        i++;
+    }
+
+    return i, line;
+}
+
+func hasPrefix(runes []rune, i int, prefix string) bool {
+    if (prefix == "") {
+        return false;
+    }
+
+    return strings.HasPrefix(string(runes[i:min(len(runes), i + len(prefix))]), prefix);
+}
+
+// Return the first of the prefixes that matches at i (or an empty string).
+func hasAnyPrefix(runes []rune, i int, prefixes []string) string {
+    for _, prefix := range prefixes {
+        if (hasPrefix(runes, i, prefix)) {
+            return prefix;
+        }
+    }
+
+    return "";
+}
@@ -0,0 +1,80 @@
+package similarity
+
+import (
+    "slices"
+    "testing"
+)
+
+func TestTokenizeBase(test *testing.T) {
+    testCases := []struct{ language string; text string; expectedTexts []string; expectedLines []int }{
+        {
+            "python",
+            "def foo(x):  # comment\n    return x + 1\n",
+            []string{"def", TOKEN_IDENTIFIER, "(", TOKEN_IDENTIFIER, ")", ":", "return", TOKEN_IDENTIFIER, "+", TOKEN_NUMBER},
+            []int{1, 1, 1, 1, 1, 1, 2, 2, 2, 2},
+        },
+        {
+            "python",
+            "'''\nDocs.\n'''\nx = \"a # b\"\n",
+            []string{TOKEN_STRING, TOKEN_IDENTIFIER, "=", TOKEN_STRING},
+            []int{1, 4, 4, 4},
+        },
+        {
+            "java",
+            "/* block\n comment */ int count = 0; // line\nreturn count;",
+            []string{"int", TOKEN_IDENTIFIER, "=", TOKEN_NUMBER, ";", "return", TOKEN_IDENTIFIER, ";"},
+            []int{2, 2, 2, 2, 2, 3, 3, 3},
+        },
+        {
+            "c",
+            "char c = '\\'';\nputs(\"unterminated);\nx;",
+            []string{"char", TOKEN_IDENTIFIER, "=", TOKEN_STRING, ";", TOKEN_IDENTIFIER, "(", TOKEN_STRING, TOKEN_IDENTIFIER, ";"},
+            []int{1, 1, 1, 1, 1, 2, 2, 2, 3, 3},
+        },
+    };
+
+    for i, testCase := range testCases {
+        tokens := Tokenize(testCase.text, GetLanguage(testCase.language));
+
+        texts := make([]string, 0, len(tokens));
+        lines := make([]int, 0, len(tokens));
+        for _, token := range tokens {
+            texts = append(texts, token.Text);
+            lines = append(lines, token.Line);
+        }
+
+        if (!slices.Equal(testCase.expectedTexts, texts)) {
+            test.Errorf("Case %d: Unexpected tokens. Expected: '%v', Actual: '%v'.", i, testCase.expectedTexts, texts);
+            continue;
+        }
+
+        if (!slices.Equal(testCase.expectedLines, lines)) {
+            test.Errorf("Case %d: Unexpected token lines. Expected: '%v', Actual: '%v'.", i, testCase.expectedLines, lines);
+            continue;
+        }
+    }
+}
+
+func TestGetFileLanguage(test *testing.T) {
+    testCases := []struct{ path string; expected string }{
+        {"submission.py", "python"},
+        {"src/Main.java", "java"},
+        {"main.CPP", "cpp"},
+        {"main.c", "c"},
+        {"README.md", ""},
+        {"Makefile", ""},
+    };
+
+    for i, testCase := range testCases {
+        language := GetFileLanguage(testCase.path);
+
+        name := "";
+        if (language != nil) {
+            name = language.Name;
+        }
+
+        if (testCase.expected != name) {
+            test.Errorf("Case %d: Unexpected language for '%s'. Expected: '%s', Actual: '%s'.", i, testCase.path, testCase.expected, name);
+        }
+    }
+}
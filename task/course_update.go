@@ -10,6 +10,7 @@ import (
     "github.com/edulinq/autograder/log"
     "github.com/edulinq/autograder/model"
     "github.com/edulinq/autograder/model/tasks"
+    "github.com/edulinq/autograder/webhook"
 )
 
 func RunCourseUpdateTask(course *model.Course, rawTask tasks.ScheduledTask) (bool, error) {
@@ -45,6 +46,10 @@ func updateCourse(course *model.Course) (bool, error) {
     } else {
         // On success, use the new course.
         course = newCourse;
+
+        if (updated) {
+            webhook.EmitCourseUpdated(course);
+        }
     }
 
     // Sync the course.
@@ -64,7 +64,13 @@ func RunEmailLogs(rawQuery common.RawLogQuery, course *model.Course, to []string
 
     subject := fmt.Sprintf("Autograder Logs for %s", course.GetName());
 
-    err = email.Send(to, subject, content.String(), false);
+    err = email.SendMessage(&email.Message{
+        To: to,
+        Subject: subject,
+        Body: content.String(),
+        HTML: false,
+        CourseID: course.GetID(),
+    });
     if (err != nil) {
         return fmt.Errorf("Failed to send logs for course '%s': '%w'.", course.GetName(), err);
     }
@@ -36,7 +36,13 @@ func RunReport(course *model.Course, to []string) error {
 
     subject := fmt.Sprintf("Autograder Scoring Report for %s", course.GetName());
 
-    err = email.Send(to, subject, html, true);
+    err = email.SendMessage(&email.Message{
+        To: to,
+        Subject: subject,
+        Body: html,
+        HTML: true,
+        CourseID: course.GetID(),
+    });
     if (err != nil) {
         return fmt.Errorf("Failed to send scoring report for course '%s': '%w'.", course.GetName(), err);
     }
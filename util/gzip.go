@@ -34,15 +34,24 @@ func GzipFileToBytes(path string) ([]byte, error) {
     return buffer.Bytes(), nil;
 }
 
-func GzipBytesToFile(data []byte, path string) error {
+func GunzipBytes(data []byte) ([]byte, error) {
     reader, err := gzip.NewReader(bytes.NewBuffer(bytes.Clone(data)));
     if (err != nil) {
-        return fmt.Errorf("Failed to create gzip read for data to go in '%s': '%w'.", path, err);
+        return nil, fmt.Errorf("Failed to create gzip reader: '%w'.", err);
     }
 
     clearData, err := io.ReadAll(reader);
     if (err != nil) {
-        return fmt.Errorf("Failed to read gzip contents to go in '%s': '%w'.", path, err);
+        return nil, fmt.Errorf("Failed to read gzip contents: '%w'.", err);
+    }
+
+    return clearData, nil;
+}
+
+func GzipBytesToFile(data []byte, path string) error {
+    clearData, err := GunzipBytes(data);
+    if (err != nil) {
+        return fmt.Errorf("Failed to gunzip data to go in '%s': '%w'.", path, err);
     }
 
     return WriteBinaryFile(clearData, path);
@@ -0,0 +1,51 @@
+package util
+
+import (
+    "bytes"
+    "sync"
+)
+
+// A writer that calls a function with each complete line written to it (without the newline).
+// Call Flush() after the last write to get any final partial line.
+type LineWriter struct {
+    lock sync.Mutex
+    buffer bytes.Buffer
+    callback func(string)
+}
+
+func NewLineWriter(callback func(string)) *LineWriter {
+    return &LineWriter{callback: callback};
+}
+
+func (this *LineWriter) Write(data []byte) (int, error) {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    this.buffer.Write(data);
+
+    for {
+        index := bytes.IndexByte(this.buffer.Bytes(), '\n');
+        if (index < 0) {
+            break;
+        }
+
+        line := string(this.buffer.Next(index + 1));
+        this.callback(line[:index]);
+    }
+
+    return len(data), nil;
+}
+
+func (this *LineWriter) Flush() {
+    this.lock.Lock();
+    defer this.lock.Unlock();
+
+    if (this.buffer.Len() == 0) {
+        return;
+    }
+
+    line := this.buffer.String();
+    this.buffer.Reset();
+
+    this.callback(line);
+}
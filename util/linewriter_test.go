@@ -0,0 +1,34 @@
+package util
+
+import (
+    "fmt"
+    "slices"
+    "testing"
+)
+
+func TestLineWriter(test *testing.T) {
+    testCases := []struct{ writes []string; expected []string }{
+        {[]string{}, []string{}},
+        {[]string{"a\n"}, []string{"a"}},
+        {[]string{"a"}, []string{"a"}},
+        {[]string{"a", "b\nc", "\n"}, []string{"ab", "c"}},
+        {[]string{"a\n\nb\n", "c"}, []string{"a", "", "b", "c"}},
+    };
+
+    for i, testCase := range testCases {
+        lines := make([]string, 0);
+        writer := NewLineWriter(func(line string) {
+            lines = append(lines, line);
+        });
+
+        for _, text := range testCase.writes {
+            fmt.Fprint(writer, text);
+        }
+
+        writer.Flush();
+
+        if (!slices.Equal(testCase.expected, lines)) {
+            test.Errorf("Case %d: Unexpected lines. Expected: '%v', Actual: '%v'.", i, testCase.expected, lines);
+        }
+    }
+}
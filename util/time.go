@@ -68,3 +68,16 @@ func GuessTime(text string) (time.Time, error) {
 
     return time.Time{}, fmt.Errorf("Could not guess time '%s'.", text);
 }
+
+// Get the time to wait after the given number of failed attempts,
+// starting at |base| (after the first failure) and doubling after each additional failure (up to |maxBackoff|).
+// A negative base is treated as zero.
+func ExponentialBackoff(base time.Duration, attempts int, maxBackoff time.Duration) time.Duration {
+    backoff := max(0, base);
+
+    for i := 1; (i < attempts) && (backoff < maxBackoff); i++ {
+        backoff *= 2;
+    }
+
+    return min(backoff, maxBackoff);
+}
@@ -0,0 +1,65 @@
+package webhook
+
+// The data sent with each type of event.
+// Events that are not listed here send an existing type:
+// model.WEBHOOK_EVENT_SUBMISSION_GRADED sends a model.GradingInfo,
+// and model.WEBHOOK_EVENT_LMS_SYNC sends a model.LMSSyncResult.
+
+import (
+    "github.com/edulinq/autograder/model"
+)
+
+type SubmissionRejectedData struct {
+    AssignmentID string `json:"assignment-id"`
+    User string `json:"user"`
+    Reason string `json:"reason"`
+}
+
+type CourseUpdatedData struct {
+    AssignmentIDs []string `json:"assignment-ids"`
+}
+
+type ScoringUploadData struct {
+    AssignmentID string `json:"assignment-id"`
+    Scores map[string]*model.ScoringInfo `json:"scores"`
+}
+
+func EmitSubmissionGraded(assignment *model.Assignment, info *model.GradingInfo) {
+    Emit(assignment.GetCourse(), model.WEBHOOK_EVENT_SUBMISSION_GRADED, info);
+}
+
+func EmitSubmissionRejected(assignment *model.Assignment, user string, reason string) {
+    data := SubmissionRejectedData{
+        AssignmentID: assignment.GetID(),
+        User: user,
+        Reason: reason,
+    };
+
+    Emit(assignment.GetCourse(), model.WEBHOOK_EVENT_SUBMISSION_REJECTED, &data);
+}
+
+func EmitCourseUpdated(course *model.Course) {
+    assignmentIDs := make([]string, 0, len(course.Assignments));
+    for _, assignment := range course.GetSortedAssignments() {
+        assignmentIDs = append(assignmentIDs, assignment.GetID());
+    }
+
+    data := CourseUpdatedData{
+        AssignmentIDs: assignmentIDs,
+    };
+
+    Emit(course, model.WEBHOOK_EVENT_COURSE_UPDATED, &data);
+}
+
+func EmitLMSSync(course *model.Course, result *model.LMSSyncResult) {
+    Emit(course, model.WEBHOOK_EVENT_LMS_SYNC, result);
+}
+
+func EmitScoringUpload(assignment *model.Assignment, scores map[string]*model.ScoringInfo) {
+    data := ScoringUploadData{
+        AssignmentID: assignment.GetID(),
+        Scores: scores,
+    };
+
+    Emit(assignment.GetCourse(), model.WEBHOOK_EVENT_SCORING_UPLOAD, &data);
+}
@@ -0,0 +1,291 @@
+// Outgoing webhooks (see model.Webhook).
+// Events are recorded as deliveries (one per matching webhook) before they are sent,
+// so failed deliveries can be retried (with exponential backoff) even across server restarts.
+//
+// Each event is POSTed as JSON with the following headers:
+// X-Autograder-Event (the event type), X-Autograder-Delivery (the delivery ID),
+// and X-Autograder-Signature ("sha256=" followed by the hex HMAC-SHA256 of the body, keyed by the webhook's secret).
+package webhook
+
+import (
+    "bytes"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/edulinq/autograder/common"
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/log"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const (
+    HEADER_EVENT = "X-Autograder-Event";
+    HEADER_DELIVERY = "X-Autograder-Delivery";
+    HEADER_SIGNATURE = "X-Autograder-Signature";
+    SIGNATURE_PREFIX = "sha256=";
+
+    // How often to look for deliveries that need to be retried.
+    RETRY_INTERVAL = time.Minute;
+    // The longest to ever wait between attempts.
+    MAX_BACKOFF = 6 * time.Hour;
+    // How much of a failed response to keep in the delivery.
+    MAX_ERROR_BODY_LENGTH = 512;
+)
+
+// The body that is sent to a webhook.
+type Event struct {
+    ID string `json:"id"`
+    Event string `json:"event"`
+    CourseID string `json:"course-id"`
+    Time common.Timestamp `json:"time"`
+    Data any `json:"data"`
+}
+
+// Deliveries that are currently being attempted (keyed by ID).
+var activeDeliveries sync.Map;
+
+var startRetriesOnce sync.Once;
+
+// Send an event to all of a course's webhooks that accept it.
+// Deliveries are saved and then attempted in the background.
+// Errors are logged instead of returned, since webhooks should never interfere with the operation that triggered the event.
+func Emit(course *model.Course, event string, data any) {
+    deliveries, err := createDeliveries(course, event, data);
+    if (err != nil) {
+        log.Error("Failed to create webhook deliveries.", err, course, log.NewAttr("event", event));
+        return;
+    }
+
+    for _, delivery := range deliveries {
+        go func(delivery *model.WebhookDelivery) {
+            err := Deliver(course, delivery);
+            if (err != nil) {
+                log.Error("Failed to deliver webhook event.", err, course, delivery);
+            }
+        }(delivery);
+    }
+}
+
+// Make one attempt at sending a delivery and save the outcome.
+// The returned error is only for failures to handle the delivery,
+// a webhook that cannot be reached or rejects the event is recorded in the delivery.
+func Deliver(course *model.Course, delivery *model.WebhookDelivery) error {
+    if (!delivery.IsPending()) {
+        return nil;
+    }
+
+    // Another attempt for this delivery is already running.
+    _, active := activeDeliveries.LoadOrStore(delivery.ID, true);
+    if (active) {
+        return nil;
+    }
+    defer activeDeliveries.Delete(delivery.ID);
+
+    webhook := findWebhook(course, delivery.URL);
+    if (webhook == nil) {
+        delivery.Status = model.WEBHOOK_DELIVERY_FAILED;
+        delivery.NextAttemptTime = "";
+        delivery.LastError = "Webhook is no longer configured for this course.";
+        return db.SaveWebhookDelivery(course, delivery);
+    }
+
+    delivery.Attempts++;
+    delivery.LastAttemptTime = common.NowTimestamp();
+
+    responseCode, err := send(webhook, delivery);
+    delivery.LastResponseCode = responseCode;
+
+    if (err == nil) {
+        delivery.Status = model.WEBHOOK_DELIVERY_DELIVERED;
+        delivery.NextAttemptTime = "";
+        delivery.LastError = "";
+
+        log.Debug("Delivered webhook event.", course, delivery);
+    } else if (delivery.Attempts >= config.WEBHOOK_MAX_ATTEMPTS.Get()) {
+        delivery.Status = model.WEBHOOK_DELIVERY_FAILED;
+        delivery.NextAttemptTime = "";
+        delivery.LastError = err.Error();
+
+        log.Warn("Webhook delivery failed too many times, giving up.", err, course, delivery, log.NewAttr("attempts", delivery.Attempts));
+    } else {
+        delivery.NextAttemptTime = common.TimestampFromTime(time.Now().Add(getBackoff(delivery.Attempts)));
+        delivery.LastError = err.Error();
+
+        log.Debug("Webhook delivery failed, will retry.", err, course, delivery, log.NewAttr("attempts", delivery.Attempts));
+    }
+
+    err = db.SaveWebhookDelivery(course, delivery);
+    if (err != nil) {
+        return fmt.Errorf("Failed to save webhook delivery '%s': '%w'.", delivery.ID, err);
+    }
+
+    return nil;
+}
+
+// Make one pass over the deliveries for all courses:
+// attempt all pending deliveries that are due and remove finished deliveries older than the retention period.
+// A failure with one course or delivery is logged and does not stop the rest of the pass.
+func RetryPending() error {
+    courses, err := db.GetCourses();
+    if (err != nil) {
+        return fmt.Errorf("Failed to get courses: '%w'.", err);
+    }
+
+    now := time.Now();
+    cutoff := now.Add(-time.Duration(config.WEBHOOK_RETENTION_HOURS.Get()) * time.Hour);
+
+    for _, course := range courses {
+        deliveries, err := db.GetDueWebhookDeliveries(course, now);
+        if (err != nil) {
+            log.Error("Failed to get due webhook deliveries.", err, course);
+        }
+
+        for _, delivery := range deliveries {
+            err = Deliver(course, delivery);
+            if (err != nil) {
+                log.Error("Failed to retry webhook delivery.", err, course, delivery);
+            }
+        }
+
+        count, err := db.RemoveFinishedWebhookDeliveries(course, cutoff);
+        if (err != nil) {
+            log.Error("Failed to remove old webhook deliveries.", err, course);
+        } else if (count > 0) {
+            log.Debug("Removed old webhook deliveries.", course, log.NewAttr("count", count));
+        }
+    }
+
+    return nil;
+}
+
+// Start periodically retrying pending deliveries (in the background).
+// Calling this more than once has no effect.
+func StartRetries() {
+    startRetriesOnce.Do(func() {
+        go func() {
+            for {
+                err := RetryPending();
+                if (err != nil) {
+                    log.Error("Failed to retry webhook deliveries.", err);
+                }
+
+                time.Sleep(RETRY_INTERVAL);
+            }
+        }();
+    });
+}
+
+// Compute the signature header value for a body.
+func Sign(secret string, body []byte) string {
+    mac := hmac.New(sha256.New, []byte(secret));
+    mac.Write(body);
+    return SIGNATURE_PREFIX + hex.EncodeToString(mac.Sum(nil));
+}
+
+// Check a signature header value (for webhook receivers).
+func VerifySignature(secret string, body []byte, signature string) bool {
+    return hmac.Equal([]byte(Sign(secret, body)), []byte(signature));
+}
+
+func createDeliveries(course *model.Course, event string, data any) ([]*model.WebhookDelivery, error) {
+    deliveries := make([]*model.WebhookDelivery, 0);
+
+    for _, webhook := range course.Webhooks {
+        if (!webhook.Accepts(event)) {
+            continue;
+        }
+
+        id := util.UUID();
+        now := time.Now();
+        body := Event{
+            ID: id,
+            Event: event,
+            CourseID: course.GetID(),
+            Time: common.TimestampFromTime(now),
+            Data: data,
+        };
+
+        payload, err := util.ToJSON(body);
+        if (err != nil) {
+            return nil, fmt.Errorf("Failed to serialize webhook event: '%w'.", err);
+        }
+
+        deliveries = append(deliveries, &model.WebhookDelivery{
+            ID: id,
+            URL: webhook.URL,
+            Event: event,
+            Payload: payload,
+            Status: model.WEBHOOK_DELIVERY_PENDING,
+            CreatedTime: body.Time,
+            // The first attempt is made right away (see Emit()),
+            // this only matters if that attempt never finishes (e.g. the server stops).
+            NextAttemptTime: common.TimestampFromTime(now.Add(getBackoff(1))),
+        });
+    }
+
+    if (len(deliveries) == 0) {
+        return deliveries, nil;
+    }
+
+    err := db.SaveWebhookDeliveries(course, deliveries);
+    if (err != nil) {
+        return nil, fmt.Errorf("Failed to save webhook deliveries: '%w'.", err);
+    }
+
+    return deliveries, nil;
+}
+
+// Returns: (response code (zero if there was no response), error (nil when the webhook accepted the event)).
+func send(webhook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+    body := []byte(delivery.Payload);
+
+    request, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body));
+    if (err != nil) {
+        return 0, fmt.Errorf("Failed to create webhook request: '%w'.", err);
+    }
+
+    request.Header.Set("Content-Type", "application/json");
+    request.Header.Set(HEADER_EVENT, delivery.Event);
+    request.Header.Set(HEADER_DELIVERY, delivery.ID);
+    request.Header.Set(HEADER_SIGNATURE, Sign(webhook.Secret, body));
+
+    client := http.Client{
+        Timeout: time.Duration(config.WEBHOOK_TIMEOUT_SECS.Get()) * time.Second,
+    };
+
+    response, err := client.Do(request);
+    if (err != nil) {
+        return 0, fmt.Errorf("Failed to send webhook request: '%w'.", err);
+    }
+    defer response.Body.Close();
+
+    if ((response.StatusCode < 200) || (response.StatusCode >= 300)) {
+        responseBody, _ := io.ReadAll(io.LimitReader(response.Body, MAX_ERROR_BODY_LENGTH));
+        return response.StatusCode, fmt.Errorf("Webhook responded with a non-success status (%d): '%s'.", response.StatusCode, string(responseBody));
+    }
+
+    return response.StatusCode, nil;
+}
+
+func findWebhook(course *model.Course, url string) *model.Webhook {
+    for _, webhook := range course.Webhooks {
+        if (webhook.URL == url) {
+            return webhook;
+        }
+    }
+
+    return nil;
+}
+
+// The time to wait after the given number of failed attempts.
+func getBackoff(attempts int) time.Duration {
+    return util.ExponentialBackoff(time.Duration(config.WEBHOOK_BACKOFF_SECS.Get()) * time.Second, attempts, MAX_BACKOFF);
+}
@@ -0,0 +1,281 @@
+package webhook
+
+import (
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+
+    "github.com/edulinq/autograder/config"
+    "github.com/edulinq/autograder/db"
+    "github.com/edulinq/autograder/model"
+    "github.com/edulinq/autograder/util"
+)
+
+const TEST_SECRET = "test-secret";
+
+type testReceiver struct {
+    server *httptest.Server
+    lock sync.Mutex
+    // The status to respond with.
+    status int
+    requests []*http.Request
+    bodies []string
+}
+
+func TestDeliverBase(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    receiver := newTestReceiver(http.StatusOK);
+    defer receiver.server.Close();
+
+    course := setTestWebhooks(test, &model.Webhook{URL: receiver.server.URL, Secret: TEST_SECRET});
+
+    deliveries, err := createDeliveries(course, model.WEBHOOK_EVENT_COURSE_UPDATED, &CourseUpdatedData{AssignmentIDs: []string{"hw0"}});
+    if (err != nil) {
+        test.Fatalf("Failed to create deliveries: '%v'.", err);
+    }
+
+    if (len(deliveries) != 1) {
+        test.Fatalf("Unexpected number of deliveries. Expected: 1, Actual: %d.", len(deliveries));
+    }
+
+    err = Deliver(course, deliveries[0]);
+    if (err != nil) {
+        test.Fatalf("Failed to deliver: '%v'.", err);
+    }
+
+    if (len(receiver.requests) != 1) {
+        test.Fatalf("Unexpected number of requests. Expected: 1, Actual: %d.", len(receiver.requests));
+    }
+
+    request := receiver.requests[0];
+    body := receiver.bodies[0];
+
+    if (!VerifySignature(TEST_SECRET, []byte(body), request.Header.Get(HEADER_SIGNATURE))) {
+        test.Fatalf("Bad signature: '%s'.", request.Header.Get(HEADER_SIGNATURE));
+    }
+
+    if (VerifySignature("ZZZ", []byte(body), request.Header.Get(HEADER_SIGNATURE))) {
+        test.Fatalf("Signature verified with the wrong secret.");
+    }
+
+    if ((request.Header.Get(HEADER_EVENT) != model.WEBHOOK_EVENT_COURSE_UPDATED) || (request.Header.Get(HEADER_DELIVERY) != deliveries[0].ID)) {
+        test.Fatalf("Unexpected headers: '%v'.", request.Header);
+    }
+
+    var event Event;
+    util.MustJSONFromString(body, &event);
+
+    if ((event.ID != deliveries[0].ID) || (event.Event != model.WEBHOOK_EVENT_COURSE_UPDATED) || (event.CourseID != course.GetID())) {
+        test.Fatalf("Unexpected event: '%s'.", body);
+    }
+
+    savedDeliveries, err := db.GetWebhookDeliveries(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get deliveries: '%v'.", err);
+    }
+
+    if ((len(savedDeliveries) != 1) || (savedDeliveries[0].Status != model.WEBHOOK_DELIVERY_DELIVERED) ||
+            (savedDeliveries[0].Attempts != 1) || (savedDeliveries[0].LastResponseCode != http.StatusOK)) {
+        test.Fatalf("Unexpected saved deliveries: '%s'.", util.MustToJSONIndent(savedDeliveries));
+    }
+
+    // A delivered event is not sent again.
+    err = Deliver(course, savedDeliveries[0]);
+    if (err != nil) {
+        test.Fatalf("Failed to redeliver: '%v'.", err);
+    }
+
+    if (len(receiver.requests) != 1) {
+        test.Fatalf("Delivered event was sent again.");
+    }
+}
+
+func TestDeliverRetry(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    defer config.WEBHOOK_MAX_ATTEMPTS.Set(config.WEBHOOK_MAX_ATTEMPTS.Get());
+    config.WEBHOOK_MAX_ATTEMPTS.Set(2);
+
+    receiver := newTestReceiver(http.StatusInternalServerError);
+    defer receiver.server.Close();
+
+    course := setTestWebhooks(test, &model.Webhook{URL: receiver.server.URL, Secret: TEST_SECRET});
+
+    deliveries, err := createDeliveries(course, model.WEBHOOK_EVENT_LMS_SYNC, &model.LMSSyncResult{});
+    if (err != nil) {
+        test.Fatalf("Failed to create deliveries: '%v'.", err);
+    }
+
+    delivery := deliveries[0];
+
+    err = Deliver(course, delivery);
+    if (err != nil) {
+        test.Fatalf("Failed to deliver: '%v'.", err);
+    }
+
+    if ((delivery.Status != model.WEBHOOK_DELIVERY_PENDING) || (delivery.LastResponseCode != http.StatusInternalServerError) ||
+            delivery.NextAttemptTime.IsZero() || (delivery.LastError == "")) {
+        test.Fatalf("Unexpected delivery after a failed attempt: '%s'.", util.MustToJSONIndent(delivery));
+    }
+
+    err = Deliver(course, delivery);
+    if (err != nil) {
+        test.Fatalf("Failed to deliver: '%v'.", err);
+    }
+
+    if ((delivery.Status != model.WEBHOOK_DELIVERY_FAILED) || (delivery.Attempts != 2)) {
+        test.Fatalf("Unexpected delivery after running out of attempts: '%s'.", util.MustToJSONIndent(delivery));
+    }
+
+    if (len(receiver.requests) != 2) {
+        test.Fatalf("Unexpected number of requests. Expected: 2, Actual: %d.", len(receiver.requests));
+    }
+}
+
+func TestRetryPending(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    receiver := newTestReceiver(http.StatusOK);
+    defer receiver.server.Close();
+
+    course := setTestWebhooks(test, &model.Webhook{URL: receiver.server.URL, Secret: TEST_SECRET});
+
+    err := db.SaveWebhookDeliveries(course, []*model.WebhookDelivery{
+        // Due.
+        &model.WebhookDelivery{ID: "due", URL: receiver.server.URL, Event: model.WEBHOOK_EVENT_SCORING_UPLOAD, Payload: "{}", Status: model.WEBHOOK_DELIVERY_PENDING},
+        // Not due yet.
+        &model.WebhookDelivery{ID: "later", URL: receiver.server.URL, Event: model.WEBHOOK_EVENT_SCORING_UPLOAD, Payload: "{}",
+                Status: model.WEBHOOK_DELIVERY_PENDING, NextAttemptTime: "9999-01-01T00:00:00Z"},
+        // Already failed.
+        &model.WebhookDelivery{ID: "failed", URL: receiver.server.URL, Event: model.WEBHOOK_EVENT_SCORING_UPLOAD, Payload: "{}", Status: model.WEBHOOK_DELIVERY_FAILED},
+        // The webhook was removed from the course.
+        &model.WebhookDelivery{ID: "removed", URL: "http://localhost/removed", Event: model.WEBHOOK_EVENT_SCORING_UPLOAD, Payload: "{}", Status: model.WEBHOOK_DELIVERY_PENDING},
+        // Delivered long ago (past the retention period).
+        &model.WebhookDelivery{ID: "old", URL: receiver.server.URL, Event: model.WEBHOOK_EVENT_SCORING_UPLOAD, Payload: "{}",
+                Status: model.WEBHOOK_DELIVERY_DELIVERED, CreatedTime: "2000-01-01T00:00:00Z", LastAttemptTime: "2000-01-01T00:00:00Z"},
+    });
+    if (err != nil) {
+        test.Fatalf("Failed to save deliveries: '%v'.", err);
+    }
+
+    err = RetryPending();
+    if (err != nil) {
+        test.Fatalf("Failed to retry pending deliveries: '%v'.", err);
+    }
+
+    if ((len(receiver.requests) != 1) || (receiver.requests[0].Header.Get(HEADER_DELIVERY) != "due")) {
+        test.Fatalf("Unexpected requests: %d.", len(receiver.requests));
+    }
+
+    deliveries, err := db.GetWebhookDeliveries(course);
+    if (err != nil) {
+        test.Fatalf("Failed to get deliveries: '%v'.", err);
+    }
+
+    // The old delivery was removed.
+    expected := []string{model.WEBHOOK_DELIVERY_DELIVERED, model.WEBHOOK_DELIVERY_PENDING, model.WEBHOOK_DELIVERY_FAILED, model.WEBHOOK_DELIVERY_FAILED};
+    if (len(deliveries) != len(expected)) {
+        test.Fatalf("Unexpected number of deliveries. Expected: %d, Actual: %d.", len(expected), len(deliveries));
+    }
+
+    for i, delivery := range deliveries {
+        if (delivery.Status != expected[i]) {
+            test.Errorf("Delivery '%s' has an unexpected status. Expected: '%s', Actual: '%s'.", delivery.ID, expected[i], delivery.Status);
+        }
+    }
+}
+
+func TestCreateDeliveriesEvents(test *testing.T) {
+    defer db.ResetForTesting();
+    db.ResetForTesting();
+
+    course := setTestWebhooks(test,
+        &model.Webhook{URL: "http://localhost/all", Secret: TEST_SECRET},
+        &model.Webhook{URL: "http://localhost/graded", Secret: TEST_SECRET, Events: []string{"Submission.Graded"}},
+    );
+
+    testCases := []struct{ event string; expected []string }{
+        {model.WEBHOOK_EVENT_SUBMISSION_GRADED, []string{"http://localhost/all", "http://localhost/graded"}},
+        {model.WEBHOOK_EVENT_SUBMISSION_REJECTED, []string{"http://localhost/all"}},
+    };
+
+    for i, testCase := range testCases {
+        deliveries, err := createDeliveries(course, testCase.event, nil);
+        if (err != nil) {
+            test.Errorf("Case %d: Failed to create deliveries: '%v'.", i, err);
+            continue;
+        }
+
+        urls := make([]string, 0, len(deliveries));
+        for _, delivery := range deliveries {
+            urls = append(urls, delivery.URL);
+        }
+
+        if (util.MustToJSON(testCase.expected) != util.MustToJSON(urls)) {
+            test.Errorf("Case %d: Unexpected webhooks. Expected: '%v', Actual: '%v'.", i, testCase.expected, urls);
+        }
+    }
+}
+
+func TestGetBackoff(test *testing.T) {
+    defer config.WEBHOOK_BACKOFF_SECS.Set(config.WEBHOOK_BACKOFF_SECS.Get());
+    config.WEBHOOK_BACKOFF_SECS.Set(30);
+
+    testCases := []struct{ attempts int; expectedSecs int }{
+        {1, 30},
+        {2, 60},
+        {3, 120},
+        {100, int(MAX_BACKOFF.Seconds())},
+    };
+
+    for i, testCase := range testCases {
+        backoff := getBackoff(testCase.attempts);
+        if (int(backoff.Seconds()) != testCase.expectedSecs) {
+            test.Errorf("Case %d: Unexpected backoff. Expected: %ds, Actual: %s.", i, testCase.expectedSecs, backoff.String());
+        }
+    }
+}
+
+func newTestReceiver(status int) *testReceiver {
+    receiver := &testReceiver{
+        status: status,
+        requests: make([]*http.Request, 0),
+        bodies: make([]string, 0),
+    };
+
+    receiver.server = httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+        body, _ := io.ReadAll(request.Body);
+
+        receiver.lock.Lock();
+        receiver.requests = append(receiver.requests, request);
+        receiver.bodies = append(receiver.bodies, string(body));
+        receiver.lock.Unlock();
+
+        response.WriteHeader(receiver.status);
+    }));
+
+    return receiver;
+}
+
+func setTestWebhooks(test *testing.T, webhooks ...*model.Webhook) *model.Course {
+    course := db.MustGetTestCourse();
+    course.Webhooks = webhooks;
+
+    err := course.Validate();
+    if (err != nil) {
+        test.Fatalf("Failed to validate course: '%v'.", err);
+    }
+
+    err = db.SaveCourse(course);
+    if (err != nil) {
+        test.Fatalf("Failed to save course: '%v'.", err);
+    }
+
+    return course;
+}